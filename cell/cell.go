@@ -0,0 +1,322 @@
+// Package cell divides the sphere into a hierarchy of cells identified
+// by 64-bit IDs, in the style of Google's S2 geometry library.
+//
+// The sphere is projected onto the six faces of a circumscribed cube,
+// and each face is divided by a Hilbert curve of order MaxLevel from
+// package hilbert into 4^MaxLevel leaf cells. A cell at level k is the
+// union of four cells at level k+1, so the IDs of all the descendants
+// of a cell form a contiguous range, and nearby points usually have
+// nearby IDs.
+//
+// Although the layout of the IDs and the cube projection follow S2,
+// the Hilbert curve on each face is the one used throughout this
+// module rather than S2's face-dependent orientation, so the IDs are
+// not interchangeable with S2 cell IDs.
+package cell
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+
+	"github.com/gogama/geospat/hilbert"
+)
+
+// MaxLevel is the level of the smallest, leaf, cells. Leaf cells are
+// roughly one square centimeter in area.
+const MaxLevel = 30
+
+const (
+	faceBits = 3
+	numFaces = 6
+	posBits  = 2*MaxLevel + 1
+	maxSize  = 1 << MaxLevel
+)
+
+// faceCurve is the Hilbert curve which orders the leaf cells on each
+// face of the cube.
+var faceCurve, _ = hilbert.New(MaxLevel)
+
+// ID identifies a cell. The three most significant bits are the cube
+// face, from 0 to 5. They are followed by 2k bits giving the distance
+// of the cell along the order-k Hilbert curve through the face, where k
+// is the level of the cell, then by a single 1 bit, and the remaining
+// bits are zero. The zero value is not a valid ID.
+type ID uint64
+
+// FromFace returns the level-0 cell which covers an entire cube face.
+// The face must be in the range [0, 5].
+func FromFace(face int) ID {
+	return ID(uint64(face)<<posBits + lsbForLevel(0))
+}
+
+// FromLatLng returns the leaf cell containing the point with the given
+// latitude and longitude, in degrees. Use Parent to obtain the cell
+// containing the point at a coarser level.
+func FromLatLng(lat, lng float64) ID {
+	x, y, z := latLngToXYZ(lat, lng)
+	face, u, v := xyzToFaceUV(x, y, z)
+	return fromFaceIJ(face, stToIJ(uvToST(u)), stToIJ(uvToST(v)))
+}
+
+// fromFaceIJ returns the leaf cell with the given face and leaf cell
+// coordinates, each of which must be in the range [0, maxSize-1].
+func fromFaceIJ(face, i, j int) ID {
+	d := faceCurve.XYToD(uint32(i), uint32(j))
+	return ID(uint64(face)<<posBits | d<<1 | 1)
+}
+
+// IsValid reports whether id is a valid cell ID.
+func (id ID) IsValid() bool {
+	return id.Face() < numFaces && id.lsb()&0x1555555555555555 != 0
+}
+
+// Face returns the cube face of the cell, in the range [0, 5].
+func (id ID) Face() int {
+	return int(uint64(id) >> posBits)
+}
+
+// Level returns the level of the cell, in the range [0, MaxLevel].
+func (id ID) Level() int {
+	return MaxLevel - bits.TrailingZeros64(uint64(id))/2
+}
+
+// IsLeaf reports whether the cell is a leaf cell.
+func (id ID) IsLeaf() bool {
+	return uint64(id)&1 != 0
+}
+
+// Pos returns the distance of the cell along the Hilbert curve through
+// its face, scaled as the distance of its first leaf cell.
+func (id ID) Pos() uint64 {
+	return uint64(id) & (1<<posBits - 1) >> 1
+}
+
+// lsb returns the least significant set bit of the ID.
+func (id ID) lsb() uint64 {
+	return uint64(id) & -uint64(id)
+}
+
+// lsbForLevel returns the least significant set bit of the IDs of the
+// cells at the given level.
+func lsbForLevel(level int) uint64 {
+	return 1 << uint(2*(MaxLevel-level))
+}
+
+// Parent returns the cell at the given level which contains id. The
+// level must be in the range [0, id.Level()].
+func (id ID) Parent(level int) ID {
+	lsb := lsbForLevel(level)
+	return ID(uint64(id)&-lsb | lsb)
+}
+
+// Children returns the four cells at the next level which make up id,
+// in Hilbert curve order. The cell must not be a leaf cell.
+func (id ID) Children() [4]ID {
+	lsb := id.lsb()
+	first := uint64(id) - lsb + lsb>>2
+	var children [4]ID
+	for k := range children {
+		children[k] = ID(first + uint64(k)*(lsb>>1))
+	}
+	return children
+}
+
+// Contains reports whether the cell id contains the cell other. Every
+// cell contains itself.
+func (id ID) Contains(other ID) bool {
+	return id.RangeMin() <= other && other <= id.RangeMax()
+}
+
+// RangeMin returns the smallest leaf cell ID contained in id. Together
+// with RangeMax it bounds the range of IDs of all the descendants of
+// id, which is useful for range scans over a sorted index of cell IDs.
+func (id ID) RangeMin() ID {
+	return ID(uint64(id) - (id.lsb() - 1))
+}
+
+// RangeMax returns the largest leaf cell ID contained in id.
+func (id ID) RangeMax() ID {
+	return ID(uint64(id) + (id.lsb() - 1))
+}
+
+// faceIJ returns the face of the cell, the coordinates of its
+// lower left-hand leaf cell on the face, and its size in leaf cells.
+func (id ID) faceIJ() (face, i, j, size int) {
+	level := id.Level()
+	shift := uint(2 * (MaxLevel - level))
+	ci, cj := hilbert.DToXY64(1<<uint(level), id.Pos()>>shift)
+	size = 1 << uint(MaxLevel-level)
+	return id.Face(), int(ci) * size, int(cj) * size, size
+}
+
+// Neighbors returns the four cells at the same level which share an
+// edge with id, in the order bottom, right, top, left as seen on the
+// face of id. Neighbors across a cube edge are found on the adjacent
+// face.
+func (id ID) Neighbors() [4]ID {
+	level := id.Level()
+	face, i, j, size := id.faceIJ()
+	return [4]ID{
+		fromFaceIJWrap(face, i, j-size).Parent(level),
+		fromFaceIJWrap(face, i+size, j).Parent(level),
+		fromFaceIJWrap(face, i, j+size).Parent(level),
+		fromFaceIJWrap(face, i-size, j).Parent(level),
+	}
+}
+
+// fromFaceIJWrap returns the leaf cell with the given leaf cell
+// coordinates, which may lie just beyond an edge of the face, in which
+// case the leaf cell on the adjacent face is returned.
+func fromFaceIJWrap(face, i, j int) ID {
+	if i >= 0 && i < maxSize && j >= 0 && j < maxSize {
+		return fromFaceIJ(face, i, j)
+	}
+	// Project the center of a leaf cell just beyond the boundary back
+	// onto the sphere, and from there onto the correct face. Neither
+	// coordinate may be more than one leaf cell outside the face.
+	i = max(-1, min(maxSize, i))
+	j = max(-1, min(maxSize, j))
+	limit := math.Nextafter(1, 2)
+	u := max(-limit, min(limit, (2*float64(i)+1)/maxSize-1))
+	v := max(-limit, min(limit, (2*float64(j)+1)/maxSize-1))
+	face, u, v = xyzToFaceUV(faceUVToXYZ(face, u, v))
+	return fromFaceIJ(face, stToIJ(0.5*(u+1)), stToIJ(0.5*(v+1)))
+}
+
+// LatLng returns the latitude and longitude, in degrees, of the center
+// of the cell.
+func (id ID) LatLng() (lat, lng float64) {
+	face, i, j, size := id.faceIJ()
+	u := stToUV((float64(i) + float64(size)/2) / maxSize)
+	v := stToUV((float64(j) + float64(size)/2) / maxSize)
+	return xyzToLatLng(faceUVToXYZ(face, u, v))
+}
+
+// Vertices returns the latitudes and longitudes, in degrees, of the
+// four corners of the cell, counter-clockwise from the lower left-hand
+// corner as seen on its face. The edges of a cell are geodesics.
+func (id ID) Vertices() [4][2]float64 {
+	face, i, j, size := id.faceIJ()
+	u0, u1 := stToUV(float64(i)/maxSize), stToUV(float64(i+size)/maxSize)
+	v0, v1 := stToUV(float64(j)/maxSize), stToUV(float64(j+size)/maxSize)
+	var vs [4][2]float64
+	for k, uv := range [4][2]float64{{u0, v0}, {u1, v0}, {u1, v1}, {u0, v1}} {
+		vs[k][0], vs[k][1] = xyzToLatLng(faceUVToXYZ(face, uv[0], uv[1]))
+	}
+	return vs
+}
+
+// String returns the cell as its face number followed by a slash and
+// the base-4 digits of its distance along the Hilbert curve through the
+// face, one digit per level. For example "3/" is face 3 and "3/021" is
+// a level 3 cell on it.
+func (id ID) String() string {
+	if !id.IsValid() {
+		return fmt.Sprintf("Invalid: %016x", uint64(id))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d/", id.Face())
+	level := id.Level()
+	pos := id.Pos()
+	for k := 1; k <= level; k++ {
+		b.WriteByte(byte('0' + pos>>uint(2*(MaxLevel-k))&3))
+	}
+	return b.String()
+}
+
+// latLngToXYZ returns the unit vector for a latitude and longitude in
+// degrees.
+func latLngToXYZ(lat, lng float64) (x, y, z float64) {
+	phi, lambda := lat*math.Pi/180, lng*math.Pi/180
+	return math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)
+}
+
+// xyzToLatLng returns the latitude and longitude, in degrees, of the
+// direction of a vector.
+func xyzToLatLng(x, y, z float64) (lat, lng float64) {
+	lat = math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi
+	lng = math.Atan2(y, x) * 180 / math.Pi
+	return
+}
+
+// xyzToFaceUV returns the cube face which the direction of a vector
+// intersects, and the (u, v) coordinates of the intersection on that
+// face, each in the range [-1, 1].
+func xyzToFaceUV(x, y, z float64) (face int, u, v float64) {
+	ax, ay, az := math.Abs(x), math.Abs(y), math.Abs(z)
+	switch {
+	case ax >= ay && ax >= az:
+		face = 0
+		if x < 0 {
+			face = 3
+		}
+	case ay >= az:
+		face = 1
+		if y < 0 {
+			face = 4
+		}
+	default:
+		face = 2
+		if z < 0 {
+			face = 5
+		}
+	}
+	switch face {
+	case 0:
+		u, v = y/x, z/x
+	case 1:
+		u, v = -x/y, z/y
+	case 2:
+		u, v = -x/z, -y/z
+	case 3:
+		u, v = z/x, y/x
+	case 4:
+		u, v = z/y, -x/y
+	default:
+		u, v = -y/z, -x/z
+	}
+	return
+}
+
+// faceUVToXYZ returns a vector, not necessarily of unit length, in the
+// direction of the point with coordinates (u, v) on the given face.
+func faceUVToXYZ(face int, u, v float64) (x, y, z float64) {
+	switch face {
+	case 0:
+		return 1, u, v
+	case 1:
+		return -u, 1, v
+	case 2:
+		return -u, -v, 1
+	case 3:
+		return -1, -v, -u
+	case 4:
+		return v, -1, -u
+	default:
+		return v, u, -1
+	}
+}
+
+// uvToST applies the quadratic transformation which makes the cells on
+// a face closer to equal in area, mapping u in [-1, 1] to s in [0, 1].
+func uvToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+// stToUV is the inverse of uvToST.
+func stToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (4*s*s - 1) / 3
+	}
+	return (1 - 4*(1-s)*(1-s)) / 3
+}
+
+// stToIJ returns the leaf cell coordinate containing s in [0, 1].
+func stToIJ(s float64) int {
+	return max(0, min(maxSize-1, int(math.Floor(maxSize*s))))
+}
@@ -0,0 +1,138 @@
+package cell
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFromFace(t *testing.T) {
+	tests := []struct {
+		face      int
+		id        ID
+		lat, lng  float64
+		neighbors [4]int
+	}{
+		{0, 0x1000000000000000, 0, 0, [4]int{5, 1, 2, 4}},
+		{1, 0x3000000000000000, 0, 90, [4]int{5, 3, 2, 0}},
+		{2, 0x5000000000000000, 90, 0, [4]int{1, 3, 4, 0}},
+		{3, 0x7000000000000000, 0, 180, [4]int{1, 5, 4, 2}},
+		{4, 0x9000000000000000, 0, -90, [4]int{3, 5, 0, 2}},
+		{5, 0xb000000000000000, -90, 0, [4]int{3, 1, 0, 4}},
+	}
+	for _, tt := range tests {
+		id := FromFace(tt.face)
+		if id != tt.id || !id.IsValid() || id.Face() != tt.face || id.Level() != 0 || id.IsLeaf() {
+			t.Errorf("FromFace(%d) = %#x, level %d, want %#x", tt.face, uint64(id), id.Level(), uint64(tt.id))
+		}
+		if lat, lng := id.LatLng(); math.Abs(lat-tt.lat) > 1e-9 || math.Abs(lat) < 90 && math.Abs(math.Remainder(lng-tt.lng, 360)) > 1e-9 {
+			t.Errorf("FromFace(%d).LatLng() = (%v, %v), want (%v, %v)", tt.face, lat, lng, tt.lat, tt.lng)
+		}
+		if f := FromLatLng(tt.lat, tt.lng).Face(); f != tt.face {
+			t.Errorf("FromLatLng(%v, %v).Face() = %d, want %d", tt.lat, tt.lng, f, tt.face)
+		}
+		for k, n := range id.Neighbors() {
+			if n != FromFace(tt.neighbors[k]) {
+				t.Errorf("FromFace(%d).Neighbors()[%d] = %v, want face %d", tt.face, k, n, tt.neighbors[k])
+			}
+		}
+	}
+}
+
+func TestID(t *testing.T) {
+	face := FromFace(0)
+	if face.RangeMin() != 1 || face.RangeMax() != 0x1fffffffffffffff {
+		t.Errorf("face 0 ranges over [%#x, %#x]", uint64(face.RangeMin()), uint64(face.RangeMax()))
+	}
+	if s := FromFace(3).String(); s != "3/" {
+		t.Errorf("FromFace(3).String() = %q, want 3/", s)
+	}
+	id := FromLatLng(47.6, -122.3)
+	if s := id.String(); s != "2/221020031113103112320020220022" {
+		t.Errorf("FromLatLng(47.6, -122.3) = %s", s)
+	}
+	p := id.Parent(5)
+	if s := p.String(); s != "2/22102" {
+		t.Errorf("Parent(5) = %s, want 2/22102", s)
+	}
+	for k, c := range p.Children() {
+		if want := "2/22102" + string(rune('0'+k)); c.String() != want {
+			t.Errorf("Children()[%d] = %s, want %s", k, c, want)
+		}
+	}
+	for _, tt := range []ID{0, 7<<61 | 1, 0x1000000000000002} {
+		if tt.IsValid() {
+			t.Errorf("ID(%#x).IsValid() = true", uint64(tt))
+		}
+	}
+	if s := ID(0).String(); s != "Invalid: 0000000000000000" {
+		t.Errorf("ID(0).String() = %q", s)
+	}
+	vs := face.Vertices()
+	c := math.Atan(1/math.Sqrt2) * 180 / math.Pi
+	want := [4][2]float64{{-c, -45}, {-c, 45}, {c, 45}, {c, -45}}
+	for k := range vs {
+		if math.Abs(vs[k][0]-want[k][0]) > 1e-12 || math.Abs(vs[k][1]-want[k][1]) > 1e-12 {
+			t.Errorf("face 0 Vertices() = %v, want %v", vs, want)
+			break
+		}
+	}
+}
+
+// angle returns the angle in radians between two points on the sphere.
+func angle(lat1, lng1, lat2, lng2 float64) float64 {
+	x1, y1, z1 := latLngToXYZ(lat1, lng1)
+	x2, y2, z2 := latLngToXYZ(lat2, lng2)
+	cx, cy, cz := y1*z2-z1*y2, z1*x2-x1*z2, x1*y2-y1*x2
+	return math.Atan2(math.Sqrt(cx*cx+cy*cy+cz*cz), x1*x2+y1*y2+z1*z2)
+}
+
+func TestFromLatLngRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(37))
+	for range 10000 {
+		lat, lng := math.Asin(2*r.Float64()-1)*180/math.Pi, r.Float64()*360-180
+		id := FromLatLng(lat, lng)
+		if !id.IsValid() || !id.IsLeaf() || id.Level() != MaxLevel {
+			t.Fatalf("FromLatLng(%v, %v) = %v, not a valid leaf", lat, lng, id)
+		}
+		// Leaf cells are about a centimeter across.
+		clat, clng := id.LatLng()
+		if a := angle(lat, lng, clat, clng) * 6371e3; a > 0.02 {
+			t.Fatalf("FromLatLng(%v, %v) has its center %v m away", lat, lng, a)
+		}
+		level := r.Intn(MaxLevel + 1)
+		p := id.Parent(level)
+		if p.Level() != level || !p.IsValid() || !p.Contains(id) || id.Contains(p) && level < MaxLevel {
+			t.Fatalf("%v.Parent(%d) = %v", id, level, p)
+		}
+		if FromLatLng(p.LatLng()).Parent(level) != p {
+			t.Fatalf("the center of %v is not in it", p)
+		}
+		if level < MaxLevel {
+			n := 0
+			for _, c := range p.Children() {
+				if c.Parent(level) != p || c.Level() != level+1 {
+					t.Fatalf("%v.Children() has %v", p, c)
+				}
+				if c.Contains(id) {
+					n++
+				}
+			}
+			if n != 1 {
+				t.Fatalf("%d children of %v contain %v", n, p, id)
+			}
+		}
+		for _, nb := range p.Neighbors() {
+			if nb.Level() != level || nb == p || !nb.IsValid() {
+				t.Fatalf("%v.Neighbors() has %v", p, nb)
+			}
+			back := false
+			for _, nn := range nb.Neighbors() {
+				back = back || nn == p
+			}
+			if !back {
+				t.Fatalf("%v is a neighbor of %v, but not the reverse", nb, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Package crs identifies coordinate reference systems by their codes in
+// the EPSG dataset and transforms coordinates between them.
+//
+// A coordinate reference system, or CRS, is a datum together with, for
+// a projected CRS, the map projection of positions on the datum to
+// planar coordinates. Coordinates are given as Coord values. Those of a
+// projected CRS are its easting and northing in meters, and those of a
+// geographic CRS, such as EPSG:4326, are a longitude and latitude in
+// degrees, in that order, as in GeoJSON, rather than in the latitude
+// first order of the EPSG definition.
+//
+// Transform converts coordinates between two CRSs given by their codes,
+// inverting the projection of the first, transforming between the
+// datums if they differ, and applying the projection of the second, so
+// that, for example, British National Grid coordinates may be converted
+// to Web Mercator without knowledge of the parameters of either. CRSs
+// which are not built in may be described by PROJ strings, as found in
+// the metadata of many datasets, and parsed by ParseProj.
+package crs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gogama/geospat/datum"
+	"github.com/gogama/geospat/geodesy"
+	"github.com/gogama/geospat/proj"
+	"github.com/gogama/geospat/utm"
+)
+
+// ErrUnknown is returned, wrapped, by Transform when an EPSG code is not
+// that of a built in CRS.
+var ErrUnknown = errors.New("crs: unknown EPSG code")
+
+// Coord is a pair of coordinates in a CRS: an easting and northing in
+// meters in a projected CRS, or a longitude and latitude in degrees in a
+// geographic CRS.
+type Coord struct {
+	X, Y float64
+}
+
+// CRS is a coordinate reference system.
+type CRS struct {
+	// Code is the EPSG code of the CRS, or 0 for a CRS which has none.
+	Code int
+	// Name is the name of the CRS, such as "OSGB36 / British National
+	// Grid".
+	Name string
+	// Datum is the datum of the CRS.
+	Datum datum.Datum
+	// Projection is the map projection of the CRS, or nil for a
+	// geographic CRS.
+	Projection proj.Projection
+}
+
+// String returns the name of the CRS, preceded by its EPSG code, as in
+// "EPSG:27700 OSGB36 / British National Grid".
+func (c CRS) String() string {
+	if c.Code == 0 {
+		return c.Name
+	}
+	return fmt.Sprintf("EPSG:%d %s", c.Code, c.Name)
+}
+
+// Geographic reports whether the CRS is a geographic CRS, whose
+// coordinates are a longitude and latitude rather than projected.
+func (c CRS) Geographic() bool {
+	return c.Projection == nil
+}
+
+// LatLng returns the position on the datum of the CRS of the point with
+// the given coordinates.
+//
+// The complementary method Coord performs the inverse mapping.
+func (c CRS) LatLng(xy Coord) geodesy.LatLng {
+	if c.Projection == nil {
+		return geodesy.LatLng{Lat: xy.Y, Lng: xy.X}
+	}
+	return c.Projection.Inverse(xy.X, xy.Y)
+}
+
+// Coord returns the coordinates in the CRS of a position on its datum.
+//
+// The complementary method LatLng performs the inverse mapping.
+func (c CRS) Coord(p geodesy.LatLng) Coord {
+	if c.Projection == nil {
+		return Coord{p.Lng, p.Lat}
+	}
+	x, y := c.Projection.Forward(p)
+	return Coord{x, y}
+}
+
+// To returns the coordinates in the CRS dst of the point with the given
+// coordinates in the CRS c. The point is taken to lie on the ellipsoid
+// of the datum of c, so a transformation between datums is accurate
+// horizontally but discards the height.
+func (c CRS) To(dst CRS, xy Coord) Coord {
+	p := c.LatLng(xy)
+	if c.Datum != dst.Datum {
+		p, _ = datum.Transform(p, 0, c.Datum, dst.Datum)
+	}
+	return dst.Coord(p)
+}
+
+// Transform returns the coordinates in the CRS with the EPSG code dst of
+// the points with the given coordinates in the CRS with the EPSG code
+// src. It returns an error wrapping ErrUnknown if either of the codes is
+// not that of a built in CRS.
+func Transform(src, dst int, coords []Coord) ([]Coord, error) {
+	from, ok := Lookup(src)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknown, src)
+	}
+	to, ok := Lookup(dst)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknown, dst)
+	}
+	out := make([]Coord, len(coords))
+	for i, xy := range coords {
+		out[i] = from.To(to, xy)
+	}
+	return out, nil
+}
+
+// Lookup returns the built in CRS with the given EPSG code. It returns
+// false if there is no such CRS.
+//
+// The built in CRSs are the geographic CRSs of the built in datums of
+// package datum, Web Mercator, EPSG:3857, the UTM zones on WGS84,
+// EPSG:32601 to 32660 in the north and 32701 to 32760 in the south, and
+// on ETRS89, NAD83, NAD27 and ED50, the Universal Polar Stereographic
+// and other polar stereographic CRSs, and the national grids listed by
+// Codes.
+func Lookup(code int) (CRS, bool) {
+	if c, ok := registry[code]; ok {
+		return c, true
+	}
+	for _, r := range utmRanges {
+		if zone := code - r.base; zone >= r.first && zone <= r.last {
+			return utmCRS(code, r.datum, zone, r.north), true
+		}
+	}
+	return CRS{}, false
+}
+
+// Codes returns the EPSG codes of the built in CRSs in increasing order.
+func Codes() []int {
+	var codes []int
+	for code := range registry {
+		codes = append(codes, code)
+	}
+	for _, r := range utmRanges {
+		for zone := r.first; zone <= r.last; zone++ {
+			codes = append(codes, r.base+zone)
+		}
+	}
+	sort.Ints(codes)
+	return codes
+}
+
+// utmRanges lists the ranges of codes of the UTM CRSs of the built in
+// datums. The code of zone z is base+z.
+var utmRanges = []struct {
+	datum       datum.Datum
+	base        int
+	first, last int
+	north       bool
+}{
+	{datum.WGS84, 32600, 1, 60, true},
+	{datum.WGS84, 32700, 1, 60, false},
+	{datum.ETRS89, 25800, 28, 38, true},
+	{datum.NAD83, 26900, 1, 23, true},
+	{datum.NAD27, 26700, 1, 22, true},
+	{datum.ED50, 23000, 28, 38, true},
+}
+
+// utmCRS returns the UTM CRS of the given zone on a datum.
+func utmCRS(code int, d datum.Datum, zone int, north bool) CRS {
+	h := 'N'
+	if !north {
+		h = 'S'
+	}
+	t := proj.TransverseMercator{
+		Ellipsoid:       d.Ellipsoid,
+		CentralMeridian: utm.CentralMeridian(zone),
+		ScaleFactor:     utm.ScaleFactor,
+		FalseEasting:    utm.FalseEasting,
+	}
+	if !north {
+		t.FalseNorthing = utm.FalseNorthing
+	}
+	return CRS{code, fmt.Sprintf("%s / UTM zone %d%c", d.Name, zone, h), d, t}
+}
+
+// registry holds the built in CRSs other than the UTM zones, by code.
+var registry = map[int]CRS{}
+
+func init() {
+	for _, c := range []CRS{
+		{4326, "WGS84", datum.WGS84, nil},
+		{4322, "WGS72", datum.WGS72, nil},
+		{4258, "ETRS89", datum.ETRS89, nil},
+		{4269, "NAD83", datum.NAD83, nil},
+		{4267, "NAD27", datum.NAD27, nil},
+		{4230, "ED50", datum.ED50, nil},
+		{4277, "OSGB36", datum.OSGB36, nil},
+		{4299, "TM65", datum.Ireland1965, nil},
+		{4314, "DHDN", datum.DHDN, nil},
+		{4301, "Tokyo", datum.Tokyo, nil},
+		{3857, "WGS84 / Pseudo-Mercator", datum.WGS84, proj.WebMercator{}},
+		{32661, "WGS84 / UPS North", datum.WGS84, proj.PolarStereographic{
+			LatitudeOfTrueScale: 90, ScaleFactor: 0.994,
+			FalseEasting: 2000000, FalseNorthing: 2000000,
+		}},
+		{32761, "WGS84 / UPS South", datum.WGS84, proj.PolarStereographic{
+			LatitudeOfTrueScale: -90, ScaleFactor: 0.994,
+			FalseEasting: 2000000, FalseNorthing: 2000000,
+		}},
+		{3413, "WGS84 / NSIDC Sea Ice Polar Stereographic North", datum.WGS84, proj.PolarStereographic{
+			CentralMeridian: -45, LatitudeOfTrueScale: 70,
+		}},
+		{3995, "WGS84 / Arctic Polar Stereographic", datum.WGS84, proj.PolarStereographic{
+			LatitudeOfTrueScale: 71,
+		}},
+		{3031, "WGS84 / Antarctic Polar Stereographic", datum.WGS84, proj.PolarStereographic{
+			LatitudeOfTrueScale: -71,
+		}},
+		{27700, "OSGB36 / British National Grid", datum.OSGB36, proj.TransverseMercator{
+			Ellipsoid:       geodesy.Airy1830,
+			CentralMeridian: -2, LatitudeOfOrigin: 49,
+			ScaleFactor:  0.9996012717,
+			FalseEasting: 400000, FalseNorthing: -100000,
+		}},
+		{29902, "TM65 / Irish Grid", datum.Ireland1965, proj.TransverseMercator{
+			Ellipsoid:       geodesy.AiryModified,
+			CentralMeridian: -8, LatitudeOfOrigin: 53.5,
+			ScaleFactor:  1.000035,
+			FalseEasting: 200000, FalseNorthing: 250000,
+		}},
+		{2154, "RGF93 / Lambert-93", datum.ETRS89, proj.LambertConformalConic{
+			Ellipsoid:       geodesy.GRS80,
+			CentralMeridian: 3, LatitudeOfOrigin: 46.5,
+			StandardParallel1: 49, StandardParallel2: 44,
+			FalseEasting: 700000, FalseNorthing: 6600000,
+		}},
+		{5070, "NAD83 / Conus Albers", datum.NAD83, proj.AlbersEqualArea{
+			Ellipsoid:       geodesy.GRS80,
+			CentralMeridian: -96, LatitudeOfOrigin: 23,
+			StandardParallel1: 29.5, StandardParallel2: 45.5,
+		}},
+	} {
+		registry[c.Code] = c
+	}
+	// The Gauss-Krüger zones 2 to 5 of Germany, each 3 degrees wide.
+	for zone := 2; zone <= 5; zone++ {
+		registry[31464+zone] = CRS{31464 + zone, fmt.Sprintf("DHDN / 3-degree Gauss-Kruger zone %d", zone), datum.DHDN, proj.TransverseMercator{
+			Ellipsoid:       geodesy.Bessel1841,
+			CentralMeridian: float64(3 * zone),
+			ScaleFactor:     1,
+			FalseEasting:    float64(zone)*1e6 + 500000,
+		}}
+	}
+}
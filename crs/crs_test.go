@@ -0,0 +1,145 @@
+package crs
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// dms returns an angle in degrees given in degrees, minutes and seconds.
+func dms(d, m, s float64) float64 {
+	return math.Copysign(math.Abs(d)+m/60+s/3600, d)
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		src, dst int
+		in, want Coord
+		tol      float64
+	}{
+		// EPSG Guidance Note 7-2, methods 1024 and 9810.
+		{4326, 3857, Coord{dms(-100, 20, 0), dms(24, 22, 54.433)}, Coord{-11169055.58, 2800000.00}, 0.01},
+		{4326, 32661, Coord{44, 73}, Coord{3320416.75, 632668.43}, 0.01},
+		// The worked example of the Ordnance Survey, on OSGB36.
+		{4277, 27700, Coord{dms(1, 43, 4.5177), dms(52, 39, 27.2531)}, Coord{651409.903, 313177.270}, 0.001},
+		{4326, 32638, Coord{44.4, 33.3}, Coord{444140.545, 3684706.356}, 0.001},
+		// Paris on Lambert-93.
+		{4326, 2154, Coord{2.3522, 48.8566}, Coord{652469.02, 6862035.26}, 0.01},
+		{4326, 4326, Coord{2.3522, 48.8566}, Coord{2.3522, 48.8566}, 0},
+		// Between datums, which moves the point by about 100 meters.
+		{4326, 4277, Coord{-0.001619639, 51.477815849}, Coord{0, 51.4773}, 1e-6},
+	}
+	for _, tt := range tests {
+		out, err := Transform(tt.src, tt.dst, []Coord{tt.in})
+		if err != nil || len(out) != 1 || math.Abs(out[0].X-tt.want.X) > tt.tol || math.Abs(out[0].Y-tt.want.Y) > tt.tol {
+			t.Errorf("Transform(%d, %d, %v) = %v, %v, want %v", tt.src, tt.dst, tt.in, out, err, tt.want)
+			continue
+		}
+		back, err := Transform(tt.dst, tt.src, out)
+		if err != nil || math.Abs(back[0].X-tt.in.X) > 1e-6 || math.Abs(back[0].Y-tt.in.Y) > 1e-6 {
+			t.Errorf("Transform(%d, %d, %v) = %v, %v, want %v", tt.dst, tt.src, out, back, err, tt.in)
+		}
+	}
+}
+
+func TestTransformRoundTrip(t *testing.T) {
+	// Each projected CRS about a point within it, to WGS84 and back.
+	points := map[int]Coord{
+		27700: {-1.5, 52.5}, 29902: {-8, 53.5}, 2154: {2.35, 48.86}, 5070: {-75, 35},
+		3413: {10, 85}, 3995: {-100, 80}, 3031: {100, -80}, 32761: {0, -85},
+		32631: {2.35, 48.86}, 32733: {15, -10}, 25832: {9, 50}, 26915: {-93, 45},
+		26715: {-93, 45}, 23031: {3, 45}, 31467: {9, 50},
+	}
+	for code, p := range points {
+		c, ok := Lookup(code)
+		if !ok || c.Geographic() {
+			t.Fatalf("Lookup(%d) = %v, %v", code, c, ok)
+		}
+		out, err := Transform(4326, code, []Coord{p})
+		if err != nil {
+			t.Fatalf("Transform(4326, %d) error = %v", code, err)
+		}
+		back, _ := Transform(code, 4326, out)
+		// The reversal of the Helmert transformation is accurate to
+		// about a centimeter, or 1e-7 degrees.
+		if math.Abs(back[0].X-p.X) > 1e-6 || math.Abs(back[0].Y-p.Y) > 1e-6 {
+			t.Errorf("Transform(%d, 4326, Transform(4326, %d, %v)) = %v", code, code, p, back[0])
+		}
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	tests := []struct{ src, dst int }{{1, 4326}, {4326, 1}, {32600, 4326}, {4326, 32761 + 1}}
+	for _, tt := range tests {
+		if out, err := Transform(tt.src, tt.dst, []Coord{{}}); !errors.Is(err, ErrUnknown) || out != nil {
+			t.Errorf("Transform(%d, %d) = %v, %v, want %v", tt.src, tt.dst, out, err, ErrUnknown)
+		}
+	}
+	if out, err := Transform(4326, 3857, nil); err != nil || len(out) != 0 {
+		t.Errorf("Transform(4326, 3857, nil) = %v, %v", out, err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code       int
+		name       string
+		geographic bool
+	}{
+		{4326, "EPSG:4326 WGS84", true},
+		{4277, "EPSG:4277 OSGB36", true},
+		{3857, "EPSG:3857 WGS84 / Pseudo-Mercator", false},
+		{27700, "EPSG:27700 OSGB36 / British National Grid", false},
+		{32601, "EPSG:32601 WGS84 / UTM zone 1N", false},
+		{32760, "EPSG:32760 WGS84 / UTM zone 60S", false},
+		{25833, "EPSG:25833 ETRS89 / UTM zone 33N", false},
+		{26722, "EPSG:26722 NAD27 / UTM zone 22N", false},
+		{31469, "EPSG:31469 DHDN / 3-degree Gauss-Kruger zone 5", false},
+	}
+	for _, tt := range tests {
+		c, ok := Lookup(tt.code)
+		if !ok || c.String() != tt.name || c.Geographic() != tt.geographic || c.Code != tt.code {
+			t.Errorf("Lookup(%d) = %v, %v, geographic %v, want %q, geographic %v", tt.code, c, ok, c.Geographic(), tt.name, tt.geographic)
+		}
+	}
+	for _, code := range []int{0, 32600, 32661 - 100, 25827, 26724, 31465, 31470} {
+		if c, ok := Lookup(code); ok {
+			t.Errorf("Lookup(%d) = %v, want none", code, c)
+		}
+	}
+	if s := (CRS{Name: "local"}).String(); s != "local" {
+		t.Errorf("String() = %q, want %q", s, "local")
+	}
+}
+
+func TestCodes(t *testing.T) {
+	codes := Codes()
+	if !slices.IsSorted(codes) || len(slices.Compact(slices.Clone(codes))) != len(codes) {
+		t.Fatalf("Codes() = %v, not increasing", codes)
+	}
+	// 60 + 60 WGS84 UTM zones, 11 each on ETRS89 and ED50, 23 on NAD83
+	// and 22 on NAD27, and 24 others.
+	if len(codes) != 60+60+11+11+23+22+24 {
+		t.Errorf("len(Codes()) = %d", len(codes))
+	}
+	for _, code := range codes {
+		if c, ok := Lookup(code); !ok || c.Code != code {
+			t.Errorf("Lookup(%d) = %v, %v", code, c, ok)
+		}
+	}
+}
+
+func TestCRSCoord(t *testing.T) {
+	c, _ := Lookup(4326)
+	if xy := c.Coord(c.LatLng(Coord{10, 20})); xy != (Coord{10, 20}) {
+		t.Errorf("Coord(LatLng({10 20})) = %v", xy)
+	}
+	if p := c.LatLng(Coord{10, 20}); p.Lat != 20 || p.Lng != 10 {
+		t.Errorf("LatLng({10 20}) = %v, want (20, 10)", p)
+	}
+	m, _ := Lookup(3857)
+	if xy := m.Coord(c.LatLng(Coord{0, 0})); xy != (Coord{0, 0}) {
+		t.Errorf("3857 Coord(0, 0) = %v", xy)
+	}
+}
@@ -0,0 +1,438 @@
+package crs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/geospat/datum"
+	"github.com/gogama/geospat/geodesy"
+	"github.com/gogama/geospat/proj"
+	"github.com/gogama/geospat/utm"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, by ParseProj when a PROJ string is
+	// malformed or its parameters are inconsistent.
+	ErrInvalid = errors.New("crs: invalid PROJ string")
+
+	// ErrUnsupported is returned, wrapped, by ParseProj when a PROJ
+	// string is well formed but uses a projection, parameter or value
+	// which this package does not implement.
+	ErrUnsupported = errors.New("crs: unsupported PROJ string")
+)
+
+// projEllipsoids maps the names of ellipsoids in PROJ strings, as in
+// "+ellps=intl", to the built in ellipsoids.
+var projEllipsoids = map[string]geodesy.Ellipsoid{
+	"WGS84":    geodesy.WGS84,
+	"GRS80":    geodesy.GRS80,
+	"WGS72":    geodesy.WGS72,
+	"GRS67":    geodesy.GRS67,
+	"airy":     geodesy.Airy1830,
+	"mod_airy": geodesy.AiryModified,
+	"bessel":   geodesy.Bessel1841,
+	"clrk66":   geodesy.Clarke1866,
+	"clrk80":   geodesy.Clarke1880,
+	"evrst30":  geodesy.Everest1830,
+	"intl":     geodesy.International1924,
+	"krass":    geodesy.Krassovsky1940,
+}
+
+// projDatums maps the names of datums in PROJ strings, as in
+// "+datum=OSGB36", to the built in datums.
+var projDatums = map[string]datum.Datum{
+	"WGS84":   datum.WGS84,
+	"NAD83":   datum.NAD83,
+	"NAD27":   datum.NAD27,
+	"OSGB36":  datum.OSGB36,
+	"potsdam": datum.DHDN,
+	"ire65":   datum.Ireland1965,
+}
+
+// projParams holds the parameters of a PROJ string, and records which
+// of them have been used, so that any left over may be rejected.
+type projParams struct {
+	values map[string]string
+	used   map[string]bool
+}
+
+// has reports whether the parameter is present, and marks it used.
+func (p *projParams) has(key string) bool {
+	_, ok := p.values[key]
+	p.used[key] = true
+	return ok
+}
+
+// str returns the value of a parameter, or the empty string if it is
+// absent.
+func (p *projParams) str(key string) string {
+	p.used[key] = true
+	return p.values[key]
+}
+
+// float returns the value of a numeric parameter, or def if it is
+// absent.
+func (p *projParams) float(key string, def float64) (float64, error) {
+	if !p.has(key) {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(p.values[key], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: +%s=%s is not a number", ErrInvalid, key, p.values[key])
+	}
+	return v, nil
+}
+
+// floats returns the values of the numeric parameters in order, each
+// defaulting to 0, stopping at the first error.
+func (p *projParams) floats(keys ...string) ([]float64, error) {
+	vs := make([]float64, len(keys))
+	for i, key := range keys {
+		var err error
+		if vs[i], err = p.float(key, 0); err != nil {
+			return nil, err
+		}
+	}
+	return vs, nil
+}
+
+// ParseProj returns the CRS described by a PROJ string, such as
+// "+proj=utm +zone=33 +datum=WGS84 +units=m +no_defs".
+//
+// The projections supported are longlat, merc in the spherical form of
+// Web Mercator, tmerc, utm, lcc, aea, stere centered on a pole, and ups.
+// The ellipsoid may be given by +ellps, by +a together with +b, +rf or
+// +f, or by +R for a sphere, and the datum by +datum or by +towgs84 with
+// three or seven parameters in the position vector convention. A CRS
+// with neither +datum nor +towgs84 is taken to be on the WGS84 datum,
+// its positions being projected with the ellipsoid given, or with WGS84
+// if there is none, as PROJ does. A string of the form
+// "+init=epsg:27700" gives the built in CRS with that code. The only
+// unit supported is the meter.
+//
+// It returns an error wrapping ErrInvalid if the string is malformed,
+// and one wrapping ErrUnsupported, naming the culprit, if it uses a
+// projection, parameter or value which is not supported.
+func ParseProj(s string) (CRS, error) {
+	p := projParams{values: map[string]string{}, used: map[string]bool{}}
+	for _, term := range strings.Fields(s) {
+		key, value, _ := strings.Cut(strings.TrimPrefix(term, "+"), "=")
+		if key == "" {
+			return CRS{}, fmt.Errorf("%w: %q has an empty term %q", ErrInvalid, s, term)
+		}
+		if _, dup := p.values[key]; dup {
+			return CRS{}, fmt.Errorf("%w: %q repeats +%s", ErrInvalid, s, key)
+		}
+		p.values[key] = value
+	}
+	if p.has("init") {
+		if len(p.values) > 1 {
+			return CRS{}, fmt.Errorf("%w: +init may not be combined with other parameters", ErrUnsupported)
+		}
+		return parseInit(p.str("init"))
+	}
+	d, e, err := parseDatum(&p)
+	if err != nil {
+		return CRS{}, err
+	}
+	c := CRS{Name: strings.Join(strings.Fields(s), " "), Datum: d}
+	if c.Projection, err = parseProjection(&p, e); err != nil {
+		return CRS{}, err
+	}
+	p.used["no_defs"], p.used["wktext"] = true, true
+	if t := p.str("type"); t != "" && t != "crs" {
+		return CRS{}, fmt.Errorf("%w: +type=%s", ErrUnsupported, t)
+	}
+	if units := p.str("units"); units != "" && units != "m" {
+		return CRS{}, fmt.Errorf("%w: +units=%s, only meters are supported", ErrUnsupported, units)
+	}
+	if toMeter, err := p.float("to_meter", 1); err != nil {
+		return CRS{}, err
+	} else if toMeter != 1 {
+		return CRS{}, fmt.Errorf("%w: +to_meter=%g, only meters are supported", ErrUnsupported, toMeter)
+	}
+	for _, term := range strings.Fields(s) {
+		key, _, _ := strings.Cut(strings.TrimPrefix(term, "+"), "=")
+		if !p.used[key] {
+			return CRS{}, fmt.Errorf("%w: +%s is not supported for +proj=%s", ErrUnsupported, key, p.values["proj"])
+		}
+	}
+	return c, nil
+}
+
+// parseInit returns the CRS named by the value of an +init parameter,
+// such as "epsg:27700".
+func parseInit(init string) (CRS, error) {
+	authority, code, ok := strings.Cut(init, ":")
+	if !ok || !strings.EqualFold(authority, "epsg") {
+		return CRS{}, fmt.Errorf("%w: +init=%s, only EPSG codes are supported", ErrUnsupported, init)
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return CRS{}, fmt.Errorf("%w: +init=%s has an invalid code", ErrInvalid, init)
+	}
+	c, ok := Lookup(n)
+	if !ok {
+		return CRS{}, fmt.Errorf("%w: %d", ErrUnknown, n)
+	}
+	return c, nil
+}
+
+// parseDatum returns the datum given by the +datum and +towgs84
+// parameters, and the ellipsoid of the projection given by them or by
+// +ellps and the ellipsoid size parameters.
+func parseDatum(p *projParams) (datum.Datum, geodesy.Ellipsoid, error) {
+	d := datum.WGS84
+	explicit := false
+	if name := p.str("datum"); name != "" {
+		var ok bool
+		if d, ok = projDatums[name]; !ok {
+			return datum.Datum{}, geodesy.Ellipsoid{}, fmt.Errorf("%w: +datum=%s", ErrUnsupported, name)
+		}
+		explicit = true
+	}
+	e := d.Ellipsoid
+	if name := p.str("ellps"); name != "" {
+		var ok bool
+		if e, ok = projEllipsoids[name]; !ok {
+			return datum.Datum{}, geodesy.Ellipsoid{}, fmt.Errorf("%w: +ellps=%s", ErrUnsupported, name)
+		}
+		if explicit && e != d.Ellipsoid {
+			return datum.Datum{}, geodesy.Ellipsoid{}, fmt.Errorf("%w: +ellps=%s contradicts +datum=%s", ErrInvalid, name, d.Name)
+		}
+	}
+	if p.has("R") || p.has("a") {
+		if explicit {
+			return datum.Datum{}, geodesy.Ellipsoid{}, fmt.Errorf("%w: the ellipsoid may not be given with +datum", ErrInvalid)
+		}
+		var err error
+		if e, err = parseEllipsoid(p); err != nil {
+			return datum.Datum{}, geodesy.Ellipsoid{}, err
+		}
+	}
+	if p.has("towgs84") {
+		if explicit {
+			return datum.Datum{}, geodesy.Ellipsoid{}, fmt.Errorf("%w: +towgs84 may not be given with +datum", ErrInvalid)
+		}
+		fields := strings.Split(p.str("towgs84"), ",")
+		if len(fields) != 3 && len(fields) != 7 {
+			return datum.Datum{}, geodesy.Ellipsoid{}, fmt.Errorf("%w: +towgs84 must have 3 or 7 parameters", ErrInvalid)
+		}
+		var v [7]float64
+		for i, f := range fields {
+			var err error
+			if v[i], err = strconv.ParseFloat(f, 64); err != nil {
+				return datum.Datum{}, geodesy.Ellipsoid{}, fmt.Errorf("%w: +towgs84 parameter %q is not a number", ErrInvalid, f)
+			}
+		}
+		d = datum.Datum{Ellipsoid: e, Helmert: datum.Helmert{
+			TX: v[0], TY: v[1], TZ: v[2],
+			RX: v[3], RY: v[4], RZ: v[5],
+			S:          v[6],
+			Convention: datum.PositionVector,
+		}}
+	}
+	return d, e, nil
+}
+
+// parseEllipsoid returns the ellipsoid given by +R, or by +a together
+// with +b, +rf or +f.
+func parseEllipsoid(p *projParams) (geodesy.Ellipsoid, error) {
+	if p.has("R") {
+		if p.has("a") {
+			return geodesy.Ellipsoid{}, fmt.Errorf("%w: +R may not be given with +a", ErrInvalid)
+		}
+		r, err := p.float("R", 0)
+		if err != nil {
+			return geodesy.Ellipsoid{}, err
+		}
+		if !(r > 0) {
+			return geodesy.Ellipsoid{}, fmt.Errorf("%w: +R=%g is not positive", ErrInvalid, r)
+		}
+		return geodesy.Ellipsoid{A: r}, nil
+	}
+	a, err := p.float("a", 0)
+	if err != nil {
+		return geodesy.Ellipsoid{}, err
+	}
+	if !(a > 0) {
+		return geodesy.Ellipsoid{}, fmt.Errorf("%w: +a=%g is not positive", ErrInvalid, a)
+	}
+	var f float64
+	switch {
+	case p.has("b"):
+		b, err := p.float("b", 0)
+		if err != nil {
+			return geodesy.Ellipsoid{}, err
+		}
+		f = 1 - b/a
+	case p.has("rf"):
+		rf, err := p.float("rf", 0)
+		if err != nil {
+			return geodesy.Ellipsoid{}, err
+		}
+		f = 1 / rf
+	case p.has("f"):
+		if f, err = p.float("f", 0); err != nil {
+			return geodesy.Ellipsoid{}, err
+		}
+	default:
+		return geodesy.Ellipsoid{}, fmt.Errorf("%w: +a must be given with +b, +rf or +f", ErrInvalid)
+	}
+	if !(f >= 0 && f < 1) {
+		return geodesy.Ellipsoid{}, fmt.Errorf("%w: the flattening %g is not in [0, 1)", ErrInvalid, f)
+	}
+	return geodesy.Ellipsoid{A: a, F: f}, nil
+}
+
+// parseProjection returns the projection given by +proj and its
+// parameters, or nil for a geographic CRS.
+func parseProjection(p *projParams, e geodesy.Ellipsoid) (proj.Projection, error) {
+	name := p.str("proj")
+	switch name {
+	case "":
+		return nil, fmt.Errorf("%w: +proj is missing", ErrInvalid)
+	case "longlat", "latlong", "lonlat", "latlon":
+		return nil, nil
+	case "merc":
+		v, err := p.floats("lon_0", "lat_ts", "x_0", "y_0")
+		if err != nil {
+			return nil, err
+		}
+		k, err := p.float("k", 1)
+		if err != nil {
+			return nil, err
+		}
+		if grids := p.str("nadgrids"); grids != "" && grids != "@null" {
+			return nil, fmt.Errorf("%w: +nadgrids=%s", ErrUnsupported, grids)
+		}
+		if e.F != 0 || e.A != proj.WebMercatorRadius || k != 1 || v[0] != 0 || v[1] != 0 || v[2] != 0 || v[3] != 0 {
+			return nil, fmt.Errorf("%w: +proj=merc is supported only as Web Mercator", ErrUnsupported)
+		}
+		return proj.WebMercator{}, nil
+	case "tmerc":
+		v, err := p.floats("lon_0", "lat_0", "x_0", "y_0")
+		if err != nil {
+			return nil, err
+		}
+		k, err := scale(p)
+		if err != nil {
+			return nil, err
+		}
+		return proj.TransverseMercator{
+			Ellipsoid:       e,
+			CentralMeridian: v[0], LatitudeOfOrigin: v[1],
+			ScaleFactor:  k,
+			FalseEasting: v[2], FalseNorthing: v[3],
+		}, nil
+	case "utm":
+		zone, err := strconv.Atoi(p.str("zone"))
+		if err != nil || zone < 1 || zone > 60 {
+			return nil, fmt.Errorf("%w: +zone=%s is not a UTM zone", ErrInvalid, p.values["zone"])
+		}
+		t := proj.TransverseMercator{
+			Ellipsoid:       e,
+			CentralMeridian: utm.CentralMeridian(zone),
+			ScaleFactor:     utm.ScaleFactor,
+			FalseEasting:    utm.FalseEasting,
+		}
+		if p.has("south") {
+			t.FalseNorthing = utm.FalseNorthing
+		}
+		return t, nil
+	case "lcc":
+		if !p.has("lat_1") {
+			return nil, fmt.Errorf("%w: +proj=lcc requires +lat_1", ErrInvalid)
+		}
+		v, err := p.floats("lon_0", "lat_0", "lat_1", "x_0", "y_0")
+		if err != nil {
+			return nil, err
+		}
+		lat2, err := p.float("lat_2", v[2])
+		if err != nil {
+			return nil, err
+		}
+		k, err := scale(p)
+		if err != nil {
+			return nil, err
+		}
+		return proj.LambertConformalConic{
+			Ellipsoid:       e,
+			CentralMeridian: v[0], LatitudeOfOrigin: v[1],
+			StandardParallel1: v[2], StandardParallel2: lat2,
+			ScaleFactor:  k,
+			FalseEasting: v[3], FalseNorthing: v[4],
+		}, nil
+	case "aea":
+		if !p.has("lat_1") {
+			return nil, fmt.Errorf("%w: +proj=aea requires +lat_1", ErrInvalid)
+		}
+		v, err := p.floats("lon_0", "lat_0", "lat_1", "x_0", "y_0")
+		if err != nil {
+			return nil, err
+		}
+		lat2, err := p.float("lat_2", v[2])
+		if err != nil {
+			return nil, err
+		}
+		return proj.AlbersEqualArea{
+			Ellipsoid:       e,
+			CentralMeridian: v[0], LatitudeOfOrigin: v[1],
+			StandardParallel1: v[2], StandardParallel2: lat2,
+			FalseEasting: v[3], FalseNorthing: v[4],
+		}, nil
+	case "stere":
+		v, err := p.floats("lon_0", "lat_0", "x_0", "y_0")
+		if err != nil {
+			return nil, err
+		}
+		if math.Abs(v[1]) != 90 {
+			return nil, fmt.Errorf("%w: +proj=stere is supported only with +lat_0=90 or +lat_0=-90", ErrUnsupported)
+		}
+		latTS, err := p.float("lat_ts", v[1])
+		if err != nil {
+			return nil, err
+		}
+		if latTS == 0 || (latTS < 0) != (v[1] < 0) {
+			return nil, fmt.Errorf("%w: +lat_ts=%g is not in the hemisphere of +lat_0=%g", ErrInvalid, latTS, v[1])
+		}
+		k, err := scale(p)
+		if err != nil {
+			return nil, err
+		}
+		return proj.PolarStereographic{
+			Ellipsoid:           e,
+			CentralMeridian:     v[0],
+			LatitudeOfTrueScale: latTS,
+			ScaleFactor:         k,
+			FalseEasting:        v[2], FalseNorthing: v[3],
+		}, nil
+	case "ups":
+		s := proj.PolarStereographic{
+			Ellipsoid:           e,
+			LatitudeOfTrueScale: 90,
+			ScaleFactor:         0.994,
+			FalseEasting:        2000000, FalseNorthing: 2000000,
+		}
+		if p.has("south") {
+			s.LatitudeOfTrueScale = -90
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("%w: +proj=%s", ErrUnsupported, name)
+}
+
+// scale returns the scale factor given by +k or its synonym +k_0,
+// which defaults to 1.
+func scale(p *projParams) (float64, error) {
+	if p.has("k") {
+		if p.has("k_0") {
+			return 0, fmt.Errorf("%w: +k may not be given with +k_0", ErrInvalid)
+		}
+		return p.float("k", 1)
+	}
+	return p.float("k_0", 1)
+}
@@ -0,0 +1,143 @@
+package crs
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gogama/geospat/datum"
+	"github.com/gogama/geospat/geodesy"
+	"github.com/gogama/geospat/proj"
+)
+
+func TestParseProj(t *testing.T) {
+	wgs84 := datum.WGS84
+	// The flattening is computed at run time from +rf.
+	rf := 298.257223563
+	tests := []struct {
+		s    string
+		d    datum.Datum
+		proj proj.Projection
+	}{
+		{"+proj=longlat +datum=WGS84 +no_defs", wgs84, nil},
+		{"+proj=latlong +ellps=clrk66", wgs84, nil},
+		{"+proj=longlat +datum=NAD27", datum.NAD27, nil},
+		{"+proj=utm +zone=33 +datum=WGS84 +units=m +no_defs", wgs84,
+			proj.TransverseMercator{Ellipsoid: geodesy.WGS84, CentralMeridian: 15, ScaleFactor: 0.9996, FalseEasting: 500000}},
+		{"+proj=utm +zone=33 +south +ellps=WGS84 +towgs84=0,0,0,0,0,0,0 +units=m +no_defs +type=crs",
+			datum.Datum{Ellipsoid: geodesy.WGS84},
+			proj.TransverseMercator{Ellipsoid: geodesy.WGS84, CentralMeridian: 15, ScaleFactor: 0.9996, FalseEasting: 500000, FalseNorthing: 10000000}},
+		// The PROJ string of EPSG:3857.
+		{"+proj=merc +a=6378137 +b=6378137 +lat_ts=0 +lon_0=0 +x_0=0 +y_0=0 +k=1 +units=m +nadgrids=@null +wktext +no_defs",
+			wgs84, proj.WebMercator{}},
+		{"+proj=tmerc +lat_0=49 +lon_0=-2 +k=0.9996012717 +x_0=400000 +y_0=-100000 +ellps=airy +towgs84=446.448,-125.157,542.06,0.15,0.247,0.842,-20.489 +units=m +no_defs",
+			datum.Datum{Ellipsoid: geodesy.Airy1830, Helmert: datum.Helmert{TX: 446.448, TY: -125.157, TZ: 542.06, RX: 0.15, RY: 0.247, RZ: 0.842, S: -20.489}},
+			proj.TransverseMercator{Ellipsoid: geodesy.Airy1830, CentralMeridian: -2, LatitudeOfOrigin: 49, ScaleFactor: 0.9996012717, FalseEasting: 400000, FalseNorthing: -100000}},
+		{"+proj=tmerc +lon_0=9 +k_0=1 +x_0=3500000 +datum=potsdam", datum.DHDN,
+			proj.TransverseMercator{Ellipsoid: geodesy.Bessel1841, CentralMeridian: 9, ScaleFactor: 1, FalseEasting: 3500000}},
+		{"+proj=tmerc +R=6371000", wgs84,
+			proj.TransverseMercator{Ellipsoid: geodesy.Ellipsoid{A: 6371000}, ScaleFactor: 1}},
+		{"+proj=lcc +lat_0=46.5 +lon_0=3 +lat_1=49 +lat_2=44 +x_0=700000 +y_0=6600000 +ellps=GRS80 +towgs84=0,0,0 +units=m +no_defs +type=crs",
+			datum.Datum{Ellipsoid: geodesy.GRS80},
+			proj.LambertConformalConic{Ellipsoid: geodesy.GRS80, CentralMeridian: 3, LatitudeOfOrigin: 46.5, StandardParallel1: 49, StandardParallel2: 44, ScaleFactor: 1, FalseEasting: 700000, FalseNorthing: 6600000}},
+		{"+proj=lcc +lat_1=18 +lat_0=18 +lon_0=-77 +k_0=1 +x_0=250000 +y_0=150000 +ellps=clrk66", wgs84,
+			proj.LambertConformalConic{Ellipsoid: geodesy.Clarke1866, CentralMeridian: -77, LatitudeOfOrigin: 18, StandardParallel1: 18, StandardParallel2: 18, ScaleFactor: 1, FalseEasting: 250000, FalseNorthing: 150000}},
+		{"+proj=aea +lat_0=23 +lon_0=-96 +lat_1=29.5 +lat_2=45.5 +x_0=0 +y_0=0 +datum=NAD83 +units=m +no_defs", datum.NAD83,
+			proj.AlbersEqualArea{Ellipsoid: geodesy.GRS80, CentralMeridian: -96, LatitudeOfOrigin: 23, StandardParallel1: 29.5, StandardParallel2: 45.5}},
+		{"+proj=stere +lat_0=-90 +lat_ts=-71 +lon_0=0 +x_0=0 +y_0=0 +datum=WGS84 +units=m +no_defs", wgs84,
+			proj.PolarStereographic{Ellipsoid: geodesy.WGS84, LatitudeOfTrueScale: -71, ScaleFactor: 1}},
+		{"+proj=stere +lat_0=90 +k=0.994 +x_0=2000000 +y_0=2000000 +a=6378137 +rf=298.257223563", wgs84,
+			proj.PolarStereographic{Ellipsoid: geodesy.Ellipsoid{A: 6378137, F: 1 / rf}, LatitudeOfTrueScale: 90, ScaleFactor: 0.994, FalseEasting: 2000000, FalseNorthing: 2000000}},
+		{"+proj=ups +south +datum=WGS84", wgs84,
+			proj.PolarStereographic{Ellipsoid: geodesy.WGS84, LatitudeOfTrueScale: -90, ScaleFactor: 0.994, FalseEasting: 2000000, FalseNorthing: 2000000}},
+		{"+proj=longlat +a=6378137 +f=0", wgs84, nil},
+		{"+proj=longlat +a=6378137 +b=6356752.314245", wgs84, nil},
+	}
+	for _, tt := range tests {
+		c, err := ParseProj(tt.s)
+		if err != nil {
+			t.Errorf("ParseProj(%q) error = %v", tt.s, err)
+			continue
+		}
+		if c.Datum.Ellipsoid != tt.d.Ellipsoid || c.Datum.Helmert != tt.d.Helmert || c.Projection != tt.proj {
+			t.Errorf("ParseProj(%q) = %v, %+v, want %v, %+v", tt.s, c.Datum, c.Projection, tt.d, tt.proj)
+		}
+		if c.Code != 0 || c.Name == "" {
+			t.Errorf("ParseProj(%q) = code %d, name %q", tt.s, c.Code, c.Name)
+		}
+	}
+}
+
+func TestParseProjInit(t *testing.T) {
+	for _, s := range []string{"+init=epsg:27700", "init=EPSG:27700"} {
+		c, err := ParseProj(s)
+		if want, _ := Lookup(27700); err != nil || c != want {
+			t.Errorf("ParseProj(%q) = %v, %v, want %v", s, c, err, want)
+		}
+	}
+}
+
+func TestParseProjTransform(t *testing.T) {
+	// The PROJ string of the British National Grid, with its rounded
+	// Helmert parameters, agrees with EPSG:27700 to a decimeter.
+	c, err := ParseProj("+proj=tmerc +lat_0=49 +lon_0=-2 +k=0.9996012717 +x_0=400000 +y_0=-100000 +ellps=airy +towgs84=446.448,-125.157,542.06,0.15,0.247,0.842,-20.489 +units=m +no_defs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wgs84, _ := Lookup(4326)
+	bng, _ := Lookup(27700)
+	for _, p := range []Coord{{-1.5, 52.5}, {-5.7, 50.07}, {-3.1, 58.6}} {
+		got, want := wgs84.To(c, p), wgs84.To(bng, p)
+		if math.Hypot(got.X-want.X, got.Y-want.Y) > 0.1 {
+			t.Errorf("To(%v) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestParseProjErrors(t *testing.T) {
+	tests := []struct {
+		s   string
+		err error
+	}{
+		{"", ErrInvalid},
+		{"+proj=longlat +", ErrInvalid},
+		{"+proj=longlat +=3", ErrInvalid},
+		{"+proj=utm +zone=33 +zone=34", ErrInvalid},
+		{"+proj=utm +zone=99", ErrInvalid},
+		{"+proj=utm", ErrInvalid},
+		{"+proj=tmerc +lat_0=4d30", ErrInvalid},
+		{"+proj=tmerc +k=1 +k_0=1", ErrInvalid},
+		{"+proj=lcc +lat_0=45", ErrInvalid},
+		{"+proj=aea +lon_0=3", ErrInvalid},
+		{"+proj=stere +lat_0=90 +lat_ts=-71", ErrInvalid},
+		{"+proj=longlat +datum=WGS84 +ellps=airy", ErrInvalid},
+		{"+proj=longlat +datum=WGS84 +a=6378137 +rf=298", ErrInvalid},
+		{"+proj=longlat +datum=WGS84 +towgs84=0,0,0", ErrInvalid},
+		{"+proj=longlat +towgs84=0,0", ErrInvalid},
+		{"+proj=longlat +towgs84=0,0,x", ErrInvalid},
+		{"+proj=longlat +R=-1", ErrInvalid},
+		{"+proj=longlat +R=1 +a=1", ErrInvalid},
+		{"+proj=longlat +a=6378137", ErrInvalid},
+		{"+proj=longlat +a=0 +f=0", ErrInvalid},
+		{"+proj=longlat +a=1 +f=1", ErrInvalid},
+		{"+init=epsg:x", ErrInvalid},
+		{"+proj=robin", ErrUnsupported},
+		{"+proj=merc +ellps=WGS84", ErrUnsupported},
+		{"+proj=merc +a=6378137 +b=6378137 +nadgrids=ntv2.gsb", ErrUnsupported},
+		{"+proj=stere +lat_0=45", ErrUnsupported},
+		{"+proj=utm +zone=33 +units=us-ft", ErrUnsupported},
+		{"+proj=utm +zone=33 +to_meter=0.3048", ErrUnsupported},
+		{"+proj=longlat +zone=3", ErrUnsupported},
+		{"+proj=longlat +datum=GDA94", ErrUnsupported},
+		{"+proj=longlat +ellps=sphere", ErrUnsupported},
+		{"+proj=longlat +type=coordinate_metadata", ErrUnsupported},
+		{"+init=epsg:27700 +units=m", ErrUnsupported},
+		{"+init=esri:102100", ErrUnsupported},
+		{"+init=epsg:1", ErrUnknown},
+	}
+	for _, tt := range tests {
+		if _, err := ParseProj(tt.s); !errors.Is(err, tt.err) {
+			t.Errorf("ParseProj(%q) error = %v, want %v", tt.s, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package geospat is the root of a collection of packages for working
+// with geospatial data. The subpackages are independent of each other
+// wherever possible; this package only declares the interfaces they
+// have in common.
+package geospat
+
+import (
+	"github.com/gogama/geospat/hilbert"
+	"github.com/gogama/geospat/morton"
+)
+
+// SpaceFillingCurve is a discrete two-dimensional space-filling curve
+// through a square divided into N() X N() cells. It maps each cell to a
+// unique distance along the curve, and back.
+//
+// Both hilbert.Curve and morton.Curve implement SpaceFillingCurve, so
+// code written against the interface can switch between them, for
+// example to compare the locality of either curve on real data.
+type SpaceFillingCurve interface {
+	// N returns the number of cells along each side of the square.
+	N() uint64
+
+	// XYToD converts a cell position with coordinates (x, y), each in
+	// the range [0, N()-1], to a distance along the curve in the range
+	// [0, N()^2-1].
+	XYToD(x, y uint32) uint64
+
+	// DToXY converts a distance along the curve, in the range
+	// [0, N()^2-1], to a cell position with coordinates (x, y).
+	DToXY(d uint64) (x, y uint32)
+}
+
+var (
+	_ SpaceFillingCurve = hilbert.Curve{}
+	_ SpaceFillingCurve = morton.Curve{}
+)
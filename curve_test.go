@@ -0,0 +1,31 @@
+package geospat
+
+import (
+	"testing"
+
+	"github.com/gogama/geospat/hilbert"
+	"github.com/gogama/geospat/morton"
+)
+
+func TestSpaceFillingCurve(t *testing.T) {
+	h, _ := hilbert.New(3)
+	m, _ := morton.New(3)
+	for _, c := range []SpaceFillingCurve{h, m} {
+		if c.N() != 8 {
+			t.Errorf("%T: N() = %d, want 8", c, c.N())
+		}
+		seen := make(map[uint64]bool)
+		for x := range uint32(8) {
+			for y := range uint32(8) {
+				d := c.XYToD(x, y)
+				if d >= 64 || seen[d] {
+					t.Fatalf("%T: XYToD(%d, %d) = %d, outside or taken", c, x, y, d)
+				}
+				seen[d] = true
+				if x2, y2 := c.DToXY(d); x2 != x || y2 != y {
+					t.Fatalf("%T: DToXY(%d) = (%d, %d), want (%d, %d)", c, d, x2, y2, x, y)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package datum transforms positions between geodetic datums by means of
+// Helmert transformations of their Earth-Centered, Earth-Fixed (ECEF)
+// coordinates.
+//
+// A datum is given by its reference ellipsoid and the transformation of
+// its ECEF coordinates to those of WGS84. To transform a position from
+// one datum to another, the position is converted to ECEF coordinates on
+// the ellipsoid of the first datum, transformed to WGS84 and from there
+// to the second datum, and converted back to a latitude, longitude and
+// height on the ellipsoid of the second datum. Coordinates from legacy
+// datasets, such as those on the OSGB36 datum of British maps, differ by
+// up to hundreds of meters from WGS84 coordinates of the same point.
+//
+// The built in datums use the published transformations of the EPSG
+// dataset, many of which are averages over the region of the datum
+// accurate to a few meters. Surveying applications may need to supply
+// more precise local transformations.
+package datum
+
+import (
+	"math"
+	"strings"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// Convention is the sign convention of the rotations of a Helmert
+// transformation. The two conventions differ only in the signs of the
+// rotations, and confusing them is a common source of errors of a few
+// meters.
+type Convention int
+
+const (
+	// PositionVector rotates the position vector of the point about
+	// the axes of the coordinate system, counter clockwise as seen from
+	// the positive end of each axis. It is the convention of the EPSG
+	// method 9606 and of the towgs84 parameter of PROJ.
+	PositionVector Convention = iota
+	// CoordinateFrame rotates the axes of the coordinate system about
+	// the point, the opposite of PositionVector. It is the convention of
+	// the EPSG method 9607, used for example in the United States and
+	// Australia.
+	CoordinateFrame
+)
+
+// arcsecond is one second of arc in radians.
+const arcsecond = math.Pi / 180 / 3600
+
+// Helmert is a seven parameter Helmert transformation, a similarity
+// transformation of ECEF coordinates for small rotations: the
+// coordinates are scaled by 1+S/1e6, rotated by RX, RY and RZ and
+// translated by TX, TY and TZ. A three parameter transformation is one
+// whose rotations and scale are zero.
+type Helmert struct {
+	TX, TY, TZ float64 // The translations in meters
+	RX, RY, RZ float64 // The rotations in seconds of arc
+	S          float64 // The scale change in parts per million
+	Convention Convention
+}
+
+// Apply returns the transformation of ECEF coordinates.
+func (h Helmert) Apply(c geodesy.ECEF) geodesy.ECEF {
+	rx, ry, rz := h.RX*arcsecond, h.RY*arcsecond, h.RZ*arcsecond
+	if h.Convention == CoordinateFrame {
+		rx, ry, rz = -rx, -ry, -rz
+	}
+	s := 1 + h.S*1e-6
+	return geodesy.ECEF{
+		X: h.TX + s*(c.X-rz*c.Y+ry*c.Z),
+		Y: h.TY + s*(rz*c.X+c.Y-rx*c.Z),
+		Z: h.TZ + s*(-ry*c.X+rx*c.Y+c.Z),
+	}
+}
+
+// Inverse returns the reverse transformation, with the signs of the
+// parameters changed. This is the reversal adopted by EPSG for Helmert
+// transformations, and it agrees with the exact inverse to within about
+// a centimeter for the small rotations and scale changes between
+// datums, which is well within the accuracy of the parameters.
+func (h Helmert) Inverse() Helmert {
+	return Helmert{-h.TX, -h.TY, -h.TZ, -h.RX, -h.RY, -h.RZ, -h.S, h.Convention}
+}
+
+// Datum is a geodetic datum, given by its reference ellipsoid and the
+// Helmert transformation of its ECEF coordinates to those of WGS84.
+type Datum struct {
+	Name      string
+	Ellipsoid geodesy.Ellipsoid
+	Helmert   Helmert
+}
+
+// String returns the name of the datum.
+func (d Datum) String() string {
+	return d.Name
+}
+
+// The built in datums.
+var (
+	WGS84 = Datum{"WGS84", geodesy.WGS84, Helmert{}}
+	// WGS72 is the predecessor of WGS84.
+	WGS72 = Datum{"WGS72", geodesy.WGS72, Helmert{TZ: 4.5, RZ: 0.554, S: 0.2263}}
+	// ETRS89 is the European Terrestrial Reference System 1989, which
+	// agrees with WGS84 to within a meter or so.
+	ETRS89 = Datum{"ETRS89", geodesy.GRS80, Helmert{}}
+	// NAD83 is the North American Datum of 1983, which agrees with
+	// WGS84 to within a couple of meters.
+	NAD83 = Datum{"NAD83", geodesy.GRS80, Helmert{}}
+	// NAD27 is the North American Datum of 1927, transformed with the
+	// mean parameters for the contiguous United States (EPSG:1173).
+	NAD27 = Datum{"NAD27", geodesy.Clarke1866, Helmert{TX: -8, TY: 160, TZ: 176}}
+	// ED50 is the European Datum 1950, transformed with the mean
+	// parameters for western Europe (EPSG:1133).
+	ED50 = Datum{"ED50", geodesy.International1924, Helmert{TX: -87, TY: -98, TZ: -121}}
+	// OSGB36 is the datum of the Ordnance Survey National Grid of Great
+	// Britain (EPSG:1314), accurate to a few meters.
+	OSGB36 = Datum{"OSGB36", geodesy.Airy1830, Helmert{
+		TX: 446.448, TY: -125.157, TZ: 542.060,
+		RX: 0.1502, RY: 0.2470, RZ: 0.8421,
+		S: -20.4894,
+	}}
+	// Ireland1965 is the datum of the Irish Grid.
+	Ireland1965 = Datum{"Ireland 1965", geodesy.AiryModified, Helmert{
+		TX: 482.5, TY: -130.6, TZ: 564.6,
+		RX: -1.042, RY: -0.214, RZ: -0.631,
+		S: 8.15,
+	}}
+	// DHDN is the Deutsches Hauptdreiecksnetz of Germany.
+	DHDN = Datum{"DHDN", geodesy.Bessel1841, Helmert{
+		TX: 598.1, TY: 73.7, TZ: 418.2,
+		RX: 0.202, RY: 0.045, RZ: -2.455,
+		S: 6.7,
+	}}
+	// Tokyo is the Tokyo datum of Japan (EPSG:15484).
+	Tokyo = Datum{"Tokyo", geodesy.Bessel1841, Helmert{TX: -146.414, TY: 507.337, TZ: 680.507}}
+)
+
+// datums lists the built in datums.
+var datums = []Datum{WGS84, WGS72, ETRS89, NAD83, NAD27, ED50, OSGB36, Ireland1965, DHDN, Tokyo}
+
+// Datums returns the built in datums.
+func Datums() []Datum {
+	return append([]Datum(nil), datums...)
+}
+
+// Lookup returns the built in datum with the given name, such as "ED50"
+// or "OSGB36", ignoring case. It returns false if there is no such
+// datum.
+func Lookup(name string) (Datum, bool) {
+	for _, d := range datums {
+		if strings.EqualFold(d.Name, name) {
+			return d, true
+		}
+	}
+	return Datum{}, false
+}
+
+// ToWGS84 returns the position and height on WGS84 of the point at the
+// given position and height in meters on the datum.
+//
+// The complementary method FromWGS84 performs the inverse mapping.
+func (d Datum) ToWGS84(p geodesy.LatLng, height float64) (geodesy.LatLng, float64) {
+	return geodesy.WGS84.FromECEF(d.Helmert.Apply(d.Ellipsoid.ToECEF(p, height)))
+}
+
+// FromWGS84 returns the position and height on the datum of the point
+// at the given position and height in meters on WGS84.
+//
+// The complementary method ToWGS84 performs the inverse mapping.
+func (d Datum) FromWGS84(p geodesy.LatLng, height float64) (geodesy.LatLng, float64) {
+	return d.Ellipsoid.FromECEF(d.Helmert.Inverse().Apply(geodesy.WGS84.ToECEF(p, height)))
+}
+
+// Transform returns the position and height on the datum to of the point
+// at the given position and height in meters on the datum from.
+func Transform(p geodesy.LatLng, height float64, from, to Datum) (geodesy.LatLng, float64) {
+	q, h := from.ToWGS84(p, height)
+	return to.FromWGS84(q, h)
+}
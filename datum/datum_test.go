@@ -0,0 +1,135 @@
+package datum
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+func near(a, b geodesy.ECEF, tol float64) bool {
+	return math.Abs(a.X-b.X) <= tol && math.Abs(a.Y-b.Y) <= tol && math.Abs(a.Z-b.Z) <= tol
+}
+
+func TestHelmertApply(t *testing.T) {
+	// EPSG Guidance Note 7-2, the transformation from WGS72 to WGS84 in
+	// both conventions.
+	wgs72 := geodesy.ECEF{X: 3657660.66, Y: 255768.55, Z: 5201382.11}
+	wgs84 := geodesy.ECEF{X: 3657660.78, Y: 255778.43, Z: 5201387.75}
+	tests := []struct {
+		h    Helmert
+		c    geodesy.ECEF
+		want geodesy.ECEF
+	}{
+		{Helmert{TZ: 4.5, RZ: 0.554, S: 0.219}, wgs72, wgs84},
+		{Helmert{TZ: 4.5, RZ: -0.554, S: 0.219, Convention: CoordinateFrame}, wgs72, wgs84},
+		{Helmert{}, wgs72, wgs72},
+		{Helmert{TX: 1, TY: -2, TZ: 3}, geodesy.ECEF{}, geodesy.ECEF{X: 1, Y: -2, Z: 3}},
+		{Helmert{S: 1}, geodesy.ECEF{X: 1e6}, geodesy.ECEF{X: 1e6 + 1}},
+		// A rotation of a milliradian, 206.265 seconds, about the Z axis.
+		{Helmert{RZ: 206.264806}, geodesy.ECEF{X: 1e6}, geodesy.ECEF{X: 1e6, Y: 1000}},
+	}
+	for _, tt := range tests {
+		if c := tt.h.Apply(tt.c); !near(c, tt.want, 0.01) {
+			t.Errorf("%+v.Apply(%v) = %v, want %v", tt.h, tt.c, c, tt.want)
+		}
+	}
+}
+
+func TestHelmertInverse(t *testing.T) {
+	// The reversal agrees with the exact inverse to about a centimeter.
+	r := rand.New(rand.NewSource(49))
+	for _, d := range datums {
+		for range 100 {
+			c := geodesy.WGS84.ToECEF(geodesy.LatLng{Lat: r.Float64()*180 - 90, Lng: r.Float64()*360 - 180}, 0)
+			if c2 := d.Helmert.Inverse().Apply(d.Helmert.Apply(c)); !near(c, c2, 0.05) {
+				t.Fatalf("%v: Inverse().Apply(Apply(%v)) = %v", d, c, c2)
+			}
+		}
+	}
+	h := Helmert{1, 2, 3, 4, 5, 6, 7, CoordinateFrame}
+	if i := h.Inverse(); i != (Helmert{-1, -2, -3, -4, -5, -6, -7, CoordinateFrame}) {
+		t.Errorf("%+v.Inverse() = %+v", h, i)
+	}
+}
+
+func TestDatumWGS84(t *testing.T) {
+	tests := []struct {
+		d      Datum
+		p      geodesy.LatLng
+		want   geodesy.LatLng
+		height float64
+	}{
+		// The Airy transit circle at Greenwich, on the prime meridian of
+		// OSGB36, is 5.8 seconds west of the WGS84 meridian.
+		{OSGB36, geodesy.LatLng{Lat: 51.4773, Lng: 0}, geodesy.LatLng{Lat: 51.47782, Lng: -0.00162}, 45.9},
+		{NAD27, geodesy.LatLng{Lat: 40, Lng: -100}, geodesy.LatLng{Lat: 40.00001, Lng: -100.00042}, -35.2},
+		{WGS84, geodesy.LatLng{Lat: 40, Lng: -100}, geodesy.LatLng{Lat: 40, Lng: -100}, 0},
+	}
+	for _, tt := range tests {
+		q, h := tt.d.ToWGS84(tt.p, 0)
+		if math.Abs(q.Lat-tt.want.Lat) > 1e-5 || math.Abs(q.Lng-tt.want.Lng) > 1e-5 || math.Abs(h-tt.height) > 0.1 {
+			t.Errorf("%v.ToWGS84(%v, 0) = %v, %v, want %v, %v", tt.d, tt.p, q, h, tt.want, tt.height)
+		}
+		p, h := tt.d.FromWGS84(q, h)
+		if math.Abs(p.Lat-tt.p.Lat) > 1e-6 || math.Abs(p.Lng-tt.p.Lng) > 1e-6 || math.Abs(h) > 0.05 {
+			t.Errorf("%v.FromWGS84(%v) = %v, %v, want %v, 0", tt.d, q, p, h, tt.p)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	r := rand.New(rand.NewSource(49))
+	for range 1000 {
+		p := geodesy.LatLng{Lat: r.Float64()*160 - 80, Lng: r.Float64()*360 - 180}
+		h := r.Float64() * 1000
+		from, to := datums[r.Intn(len(datums))], datums[r.Intn(len(datums))]
+		q, h2 := Transform(p, h, from, to)
+		// The datums differ by at most a kilometer or so, and heights
+		// also by the differences between the ellipsoids.
+		if d := geodesy.Haversine(p, q); d > 1500 || math.Abs(h2-h) > 2500 {
+			t.Fatalf("Transform(%v, %v, %v, %v) = %v, %v, %v m away", p, h, from, to, q, h2, d)
+		}
+		p2, h3 := Transform(q, h2, to, from)
+		if d := geodesy.Haversine(p, p2); d > 0.1 || math.Abs(h3-h) > 0.1 {
+			t.Fatalf("Transform(Transform(%v, %v, %v, %v)) = %v, %v, %v m away", p, h, from, to, p2, h3, d)
+		}
+		if from == to && (geodesy.Haversine(p, q) > 0.05 || math.Abs(h2-h) > 0.05) {
+			t.Fatalf("Transform(%v, %v, %v, %v) = %v, %v, want the same", p, h, from, to, q, h2)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name string
+		want Datum
+		ok   bool
+	}{
+		{"WGS84", WGS84, true},
+		{"osgb36", OSGB36, true},
+		{"Ireland 1965", Ireland1965, true},
+		{"ed50", ED50, true},
+		{"NAD 27", Datum{}, false},
+		{"", Datum{}, false},
+	}
+	for _, tt := range tests {
+		if d, ok := Lookup(tt.name); d != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %v, %v, want %v, %v", tt.name, d, ok, tt.want, tt.ok)
+		}
+	}
+	ds := Datums()
+	if len(ds) != len(datums) {
+		t.Fatalf("Datums() = %v", ds)
+	}
+	for _, d := range ds {
+		if got, ok := Lookup(d.String()); !ok || got != d {
+			t.Errorf("Lookup(%q) = %v, %v, want %v", d.String(), got, ok, d)
+		}
+	}
+	ds[0] = Datum{}
+	if d := Datums()[0]; d != WGS84 {
+		t.Errorf("Datums()[0] = %v after modification, want %v", d, WGS84)
+	}
+}
@@ -0,0 +1,169 @@
+// Package fgb reads and writes FlatGeobuf, a binary encoding of
+// geographic features, in FlatBuffers, which may be streamed and may be
+// searched through a spatial index by a reader which reads only the
+// parts of the file which it needs, such as one reading ranges of a file
+// on an HTTP server.
+//
+// A FlatGeobuf file is a magic number, a header describing the features
+// and their columns of attributes, an optional index, and the features.
+// The index is a packed Hilbert R-tree: the features are sorted along a
+// Hilbert curve by the centers of their bounding boxes, and the tree of
+// their bounding boxes is packed into an array of nodes, level by level
+// from the root. Reader reads a file as a stream, skipping the index,
+// and File searches a file through its index.
+//
+// The format is described at https://flatgeobuf.org.
+package fgb
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// magic is the magic number of FlatGeobuf files of version 3, whose last
+// byte is the patch version, so that readers ignore it.
+var magic = [8]byte{'f', 'g', 'b', 3, 'f', 'g', 'b', 0}
+
+var (
+	// ErrInvalid is returned, wrapped, when a file is not valid
+	// FlatGeobuf.
+	ErrInvalid = errors.New("fgb: invalid FlatGeobuf")
+
+	// ErrUnsupported is returned, wrapped, when a file has curves, which
+	// this package does not read, or a value cannot be written, such as a
+	// property of the wrong type for its column.
+	ErrUnsupported = errors.New("fgb: unsupported value")
+)
+
+// Header is the header of a FlatGeobuf file.
+type Header struct {
+	// Name is the name of the dataset.
+	Name string
+
+	// Envelope is the bounding box of the features, which may be empty
+	// if it is unknown. Write computes it if it is empty or the zero
+	// Rect.
+	Envelope geom.Rect
+
+	// GeometryType is the type of the geometries of the features, or 0
+	// if they may be of any type.
+	GeometryType geom.Type
+
+	// Layout is the layout of the geometries of the features.
+	Layout geom.Layout
+
+	// Columns are the columns of the attributes of the features.
+	Columns []Column
+
+	// Count is the number of features, which may be 0 if it is unknown
+	// and there is no index. Write sets it.
+	Count int
+
+	// IndexNodeSize is the number of children of each node of the index,
+	// at least 2, or 0 if there is no index. FlatGeobuf files usually
+	// have an index with nodes of 16.
+	IndexNodeSize int
+
+	// CRS is the coordinate system of the geometries.
+	CRS CRS
+
+	// Title, Description and Metadata are the title and description of
+	// the dataset, and its metadata in JSON.
+	Title, Description, Metadata string
+}
+
+// CRS is the coordinate system of the geometries of a FlatGeobuf file.
+type CRS struct {
+	// Org and Code are the organization which defines the coordinate
+	// system, such as "EPSG", and its code, or 0 if it is unknown.
+	// CodeString is its code if the code is not an integer.
+	Org        string
+	Code       int
+	CodeString string
+
+	// Name, Description and WKT are its name, description and WKT.
+	Name, Description, WKT string
+}
+
+// ColumnType is the type of the values of a column.
+type ColumnType byte
+
+// The column types, and the types of the values of properties which have
+// them.
+const (
+	Byte     ColumnType = iota // int8
+	UByte                      // uint8
+	Bool                       // bool
+	Short                      // int16
+	UShort                     // uint16
+	Int                        // int32
+	UInt                       // uint32
+	Long                       // int64
+	ULong                      // uint64
+	Float                      // float32
+	Double                     // float64
+	String                     // string
+	JSON                       // string
+	DateTime                   // time.Time, or string if it is not RFC 3339
+	Binary                     // []byte
+)
+
+// columnTypeNames holds the names of the column types, by type.
+var columnTypeNames = [...]string{
+	Byte: "Byte", UByte: "UByte", Bool: "Bool", Short: "Short", UShort: "UShort",
+	Int: "Int", UInt: "UInt", Long: "Long", ULong: "ULong", Float: "Float",
+	Double: "Double", String: "String", JSON: "Json", DateTime: "DateTime",
+	Binary: "Binary",
+}
+
+// String returns the name of the type, such as "Double", or
+// "ColumnType(n)" for an invalid type.
+func (t ColumnType) String() string {
+	if int(t) < len(columnTypeNames) {
+		return columnTypeNames[t]
+	}
+	return "ColumnType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Column is a column of the attributes of the features of a FlatGeobuf
+// file.
+type Column struct {
+	// Name and Type are the name of the column, which must not be empty,
+	// and the type of its values.
+	Name string
+	Type ColumnType
+
+	// Title and Description are the title and the description of the
+	// column.
+	Title, Description string
+
+	// Width, Precision and Scale are the width of the values of the
+	// column, and for numbers their numbers of digits and of digits
+	// after the decimal point, or 0 if they are unknown.
+	Width, Precision, Scale int
+
+	// Nullable, Unique and PrimaryKey report whether the values of the
+	// column may be null, are unique, and are the primary key.
+	Nullable, Unique, PrimaryKey bool
+
+	// Metadata is the metadata of the column in JSON.
+	Metadata string
+}
+
+// Feature is a feature of a FlatGeobuf file.
+type Feature struct {
+	// Geometry is the geometry of the feature, of the layout of the
+	// header, or nil if it has none.
+	Geometry geom.Geometry
+
+	// Properties are the values of the attributes of the feature by the
+	// names of their columns, of the types of their columns. A property
+	// which is absent or nil is null.
+	Properties map[string]any
+}
+
+// timeLayout is the layout of DateTime values as written.
+const timeLayout = time.RFC3339Nano
@@ -0,0 +1,399 @@
+package fgb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// pointFile is a FlatGeobuf file of the point (1, 2), built by hand in
+// the layout of the FlatBuffers compiler, with a vtable after its table,
+// and a patch version of 1.
+var pointFile = unhex(`
+	66676203 66676201
+	40000000
+		1C000000
+		1800 1800 0400 0000 1600 0000 0000 0000 0000 0000 0C00 1400
+		18000000 14000000 00000000 0100000000000000 0000 01 00
+		04000000 74657374 00000000
+	38000000
+		0C000000
+		0600 0800 0400 0000
+		08000000 04000000
+		F8FFFFFF 0C000000
+		0800 0800 0000 0400
+		02000000 000000000000F03F 0000000000000040`)
+
+func TestReadPointFile(t *testing.T) {
+	r, err := NewReader(bytes.NewReader(pointFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Header{Name: "test", Envelope: geom.EmptyRect(), GeometryType: geom.TypePoint, Count: 1}
+	if !reflect.DeepEqual(r.Header(), want) {
+		t.Errorf("Header() = %+v, want %+v", r.Header(), want)
+	}
+	f, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f, &Feature{Geometry: geom.Point{X: 1, Y: 2}, Properties: map[string]any{}}) {
+		t.Errorf("Next() = %+v, want POINT (1 2)", f)
+	}
+	if f, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() after the last = %v, %v, want EOF", f, err)
+	}
+}
+
+func TestColumnTypeString(t *testing.T) {
+	tests := []struct {
+		t    ColumnType
+		want string
+	}{
+		{Byte, "Byte"},
+		{Double, "Double"},
+		{JSON, "Json"},
+		{Binary, "Binary"},
+		{15, "ColumnType(15)"},
+	}
+	for _, tt := range tests {
+		if s := tt.t.String(); s != tt.want {
+			t.Errorf("ColumnType(%d).String() = %q, want %q", byte(tt.t), s, tt.want)
+		}
+	}
+}
+
+// write returns the file of a header and features written by Write.
+func write(t *testing.T, h Header, fs []*Feature) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := Write(&b, h, fs); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// readAll returns the header and features of a file read by a Reader.
+func readAll(t *testing.T, b []byte) (*Header, []*Feature) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fs []*Feature
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			return r.Header(), fs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, f)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	h := Header{
+		Name:   "places",
+		Layout: geom.XYZ,
+		Columns: []Column{
+			{Name: "name", Type: String, Title: "Name", Description: "The name", Width: 40, Nullable: true},
+			{Name: "pop", Type: Long, Unique: true},
+			{Name: "id", Type: UInt, PrimaryKey: true, Metadata: `{"a":1}`},
+		},
+		CRS:         CRS{Org: "EPSG", Code: 4326, Name: "WGS 84", WKT: `GEOGCS["WGS 84"]`},
+		Title:       "Places",
+		Description: "Some places",
+		Metadata:    `{"source":"test"}`,
+	}
+	fs := []*Feature{
+		{Geometry: geom.Point{X: 1, Y: 2, Z: 3}, Properties: map[string]any{"name": "A", "pop": int64(10), "id": uint32(1)}},
+		{Geometry: geom.LineString{{X: 0, Y: 0, Z: 1}, {X: 4, Y: -1, Z: 2}}, Properties: map[string]any{"name": "B"}},
+		{Geometry: geom.Polygon{{{}, {X: 1}, {X: 1, Y: 1}, {}}, {{X: 0.2, Y: 0.1}, {X: 0.8, Y: 0.1}, {X: 0.8, Y: 0.2}, {X: 0.2, Y: 0.1}}}, Properties: map[string]any{}},
+		{Geometry: geom.MultiPoint{{X: 5, Y: 5, Z: 5}, {X: 6, Y: 6, Z: 6}}, Properties: map[string]any{"id": uint32(4)}},
+		{Geometry: geom.MultiLineString{{{X: 1, Y: 1}, {X: 2, Y: 2}}, {{X: 3, Y: 3}, {X: 4, Y: 4}, {X: 5, Y: 3}}}, Properties: map[string]any{}},
+		{Geometry: geom.MultiLineString{{{X: 1, Y: 1}, {X: 2, Y: 2}}}, Properties: map[string]any{}},
+		{Geometry: geom.MultiPolygon{{{{}, {X: 1}, {X: 1, Y: 1}, {}}}, {{{X: 2}, {X: 3}, {X: 3, Y: 1}, {X: 2}}}}, Properties: map[string]any{}},
+		{Geometry: geom.GeometryCollection{geom.Point{X: 9, Y: 9}, geom.LineString{{}, {X: 1, Y: 1}}, geom.MultiPolygon{{{{}, {X: 1}, {X: 1, Y: 1}, {}}}}}, Properties: map[string]any{}},
+		{Properties: map[string]any{"name": "no geometry"}},
+	}
+	for _, nodeSize := range []int{0, 2, 16} {
+		h.IndexNodeSize = nodeSize
+		got, gotFs := readAll(t, write(t, h, fs))
+		wantH := h
+		wantH.Count = len(fs)
+		wantH.Envelope = geom.Rect{MinX: 0, MinY: -1, MaxX: 9, MaxY: 9}
+		if !reflect.DeepEqual(got, &wantH) {
+			t.Errorf("node size %d: Header = %+v, want %+v", nodeSize, got, &wantH)
+		}
+		if nodeSize == 0 {
+			if !reflect.DeepEqual(gotFs, fs) {
+				t.Errorf("node size %d: features = %v, want %v", nodeSize, gotFs, fs)
+			}
+			continue
+		}
+		// The features are sorted along the Hilbert curve.
+		if len(gotFs) != len(fs) {
+			t.Fatalf("node size %d: %d features, want %d", nodeSize, len(gotFs), len(fs))
+		}
+		for _, f := range fs {
+			found := false
+			for _, g := range gotFs {
+				found = found || reflect.DeepEqual(f, g)
+			}
+			if !found {
+				t.Errorf("node size %d: feature %v not read", nodeSize, f)
+			}
+		}
+	}
+}
+
+func TestWriteReadEmpty(t *testing.T) {
+	// Empty geometries are written without coordinates.
+	fs := []*Feature{
+		{Geometry: geom.EmptyPoint()},
+		{Geometry: geom.LineString{}},
+		{Geometry: geom.Polygon{}},
+		{Geometry: geom.MultiPolygon{}},
+		{Geometry: geom.GeometryCollection{}},
+	}
+	_, got := readAll(t, write(t, Header{IndexNodeSize: 16}, fs))
+	if len(got) != len(fs) {
+		t.Fatalf("%d features, want %d", len(got), len(fs))
+	}
+	for i, f := range got {
+		if f.Geometry.Type() != fs[i].Geometry.Type() || !f.Geometry.IsEmpty() {
+			t.Errorf("feature %d = %v, want empty %v", i, f.Geometry, fs[i].Geometry.Type())
+		}
+	}
+	h, got := readAll(t, write(t, Header{Name: "none", IndexNodeSize: 16}, nil))
+	if len(got) != 0 || h.Count != 0 || h.IndexNodeSize != 0 || !h.Envelope.IsEmpty() {
+		t.Errorf("file of no features = %+v, %v", h, got)
+	}
+}
+
+func TestWriteEnvelope(t *testing.T) {
+	fs := []*Feature{{Geometry: geom.Point{X: 10, Y: 20}}, {Geometry: geom.Point{X: 12, Y: 25}}}
+	tests := []struct {
+		envelope, want geom.Rect
+	}{
+		{geom.Rect{}, geom.Rect{MinX: 10, MinY: 20, MaxX: 12, MaxY: 25}},
+		{geom.EmptyRect(), geom.Rect{MinX: 10, MinY: 20, MaxX: 12, MaxY: 25}},
+		{geom.Rect{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}, geom.Rect{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}},
+	}
+	for _, tt := range tests {
+		h, _ := readAll(t, write(t, Header{Envelope: tt.envelope}, fs))
+		if h.Envelope != tt.want {
+			t.Errorf("Write of envelope %v = %v, want %v", tt.envelope, h.Envelope, tt.want)
+		}
+	}
+}
+
+func TestWriteReadLayouts(t *testing.T) {
+	l := geom.LineString{{X: 1, Y: 2, Z: 3, M: 4}, {X: 5, Y: 6, Z: 7, M: 8}}
+	tests := []struct {
+		layout geom.Layout
+		want   geom.LineString
+	}{
+		{geom.XY, geom.LineString{{X: 1, Y: 2}, {X: 5, Y: 6}}},
+		{geom.XYZ, geom.LineString{{X: 1, Y: 2, Z: 3}, {X: 5, Y: 6, Z: 7}}},
+		{geom.XYM, geom.LineString{{X: 1, Y: 2, M: 4}, {X: 5, Y: 6, M: 8}}},
+		{geom.XYZM, l},
+	}
+	for _, tt := range tests {
+		h, fs := readAll(t, write(t, Header{GeometryType: geom.TypeLineString, Layout: tt.layout}, []*Feature{{Geometry: l}}))
+		if h.Layout != tt.layout || h.GeometryType != geom.TypeLineString || !reflect.DeepEqual(fs[0].Geometry, tt.want) {
+			t.Errorf("layout %v: read %v, %v, %v, want %v", tt.layout, h.Layout, h.GeometryType, fs[0].Geometry, tt.want)
+		}
+	}
+}
+
+func TestWriteReadProperties(t *testing.T) {
+	columns := []Column{
+		{Name: "byte", Type: Byte}, {Name: "ubyte", Type: UByte}, {Name: "bool", Type: Bool},
+		{Name: "short", Type: Short}, {Name: "ushort", Type: UShort}, {Name: "int", Type: Int},
+		{Name: "uint", Type: UInt}, {Name: "long", Type: Long}, {Name: "ulong", Type: ULong},
+		{Name: "float", Type: Float}, {Name: "double", Type: Double}, {Name: "string", Type: String},
+		{Name: "json", Type: JSON}, {Name: "time", Type: DateTime}, {Name: "binary", Type: Binary},
+	}
+	when := time.Date(2024, 5, 1, 6, 30, 0, 123000000, time.FixedZone("", 2*3600))
+	tests := []struct {
+		in, want map[string]any
+	}{
+		{
+			map[string]any{
+				"byte": int8(-128), "ubyte": uint8(255), "bool": true, "short": int16(-32768),
+				"ushort": uint16(65535), "int": int32(math.MinInt32), "uint": uint32(math.MaxUint32),
+				"long": int64(math.MinInt64), "ulong": uint64(math.MaxUint64), "float": float32(1.5),
+				"double": math.Pi, "string": "héllo", "json": `{"a":[1,2]}`, "time": when,
+				"binary": []byte{0, 1, 2},
+			},
+			map[string]any{
+				"byte": int8(-128), "ubyte": uint8(255), "bool": true, "short": int16(-32768),
+				"ushort": uint16(65535), "int": int32(math.MinInt32), "uint": uint32(math.MaxUint32),
+				"long": int64(math.MinInt64), "ulong": uint64(math.MaxUint64), "float": float32(1.5),
+				"double": math.Pi, "string": "héllo", "json": `{"a":[1,2]}`, "time": when,
+				"binary": []byte{0, 1, 2},
+			},
+		},
+		{
+			// Numbers are converted to the types of the columns.
+			map[string]any{
+				"byte": 7, "ubyte": int64(8), "short": 9.0, "ushort": uint(10), "int": -11, "uint": 12.0,
+				"long": uint8(13), "ulong": 14, "float": 0.1, "double": 16, "bool": false,
+			},
+			map[string]any{
+				"byte": int8(7), "ubyte": uint8(8), "short": int16(9), "ushort": uint16(10), "int": int32(-11),
+				"uint": uint32(12), "long": int64(13), "ulong": uint64(14), "float": float32(0.1), "double": 16.0,
+				"bool": false,
+			},
+		},
+		{
+			// Nulls and properties of no column are not written.
+			map[string]any{"string": nil, "other": 1, "binary": []byte{}, "json": ""},
+			map[string]any{"binary": []byte{}, "json": ""},
+		},
+		{
+			// DateTimes which are not RFC 3339 are read as strings.
+			map[string]any{"time": "yesterday", "json": []byte("[]")},
+			map[string]any{"time": "yesterday", "json": "[]"},
+		},
+	}
+	for _, tt := range tests {
+		_, fs := readAll(t, write(t, Header{Columns: columns}, []*Feature{{Properties: tt.in}}))
+		got := fs[0].Properties
+		if gt, ok := got["time"].(time.Time); ok && gt.Equal(when) {
+			got["time"] = when
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("properties %v read as %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+type otherGeometry struct{ geom.Point }
+
+func TestWriteInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		h    Header
+		fs   []*Feature
+		err  string
+	}{
+		{"node size 1", Header{IndexNodeSize: 1}, nil, "fgb: unsupported value: index node size 1"},
+		{"node size", Header{IndexNodeSize: 1 << 16}, nil, "fgb: unsupported value: index node size 65536"},
+		{"negative node size", Header{IndexNodeSize: -2}, nil, "fgb: unsupported value: index node size -2"},
+		{"unnamed column", Header{Columns: []Column{{Type: Int}}}, nil, `fgb: unsupported value: column "" of type Int`},
+		{"column type", Header{Columns: []Column{{Name: "a", Type: 15}}}, nil, `fgb: unsupported value: column "a" of type ColumnType(15)`},
+		{"geometry type", Header{GeometryType: geom.TypePoint}, []*Feature{{Geometry: geom.Point{}}, {Geometry: geom.LineString{}}},
+			"fgb: unsupported value: LineString in a file of Point: feature 1"},
+		{"geometry", Header{}, []*Feature{{Geometry: otherGeometry{}}},
+			"fgb: unsupported value: geometry of fgb.otherGeometry: feature 0"},
+		{"member", Header{}, []*Feature{{Geometry: geom.GeometryCollection{otherGeometry{}}}},
+			"fgb: unsupported value: geometry of fgb.otherGeometry: feature 0"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		err := Write(&b, tt.h, tt.fs)
+		if err == nil || err.Error() != tt.err || !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: Write = %v, want error %q", tt.name, err, tt.err)
+		}
+		if b.Len() != 0 {
+			t.Errorf("%s: Write wrote %d bytes before its error", tt.name, b.Len())
+		}
+	}
+}
+
+func TestWriteInvalidProperty(t *testing.T) {
+	tests := []struct {
+		c   Column
+		v   any
+		err string
+	}{
+		{Column{Name: "a", Type: Byte}, 128, "property a of int for a column of Byte"},
+		{Column{Name: "a", Type: Byte}, -129, "property a of int for a column of Byte"},
+		{Column{Name: "a", Type: Int}, 1.5, "property a of float64 for a column of Int"},
+		{Column{Name: "a", Type: Long}, uint64(math.MaxUint64), "property a of uint64 for a column of Long"},
+		{Column{Name: "a", Type: UByte}, 256, "property a of int for a column of UByte"},
+		{Column{Name: "a", Type: UInt}, -1, "property a of int for a column of UInt"},
+		{Column{Name: "a", Type: ULong}, -1.0, "property a of float64 for a column of ULong"},
+		{Column{Name: "a", Type: Bool}, 1, "property a of int for a column of Bool"},
+		{Column{Name: "a", Type: Double}, "1", "property a of string for a column of Double"},
+		{Column{Name: "a", Type: String}, 1, "property a of int for a column of String"},
+		{Column{Name: "a", Type: String}, time.Time{}, "property a of time.Time for a column of String"},
+		{Column{Name: "a", Type: Binary}, "1", "property a of string for a column of Binary"},
+		{Column{Name: "a", Type: DateTime}, true, "property a of bool for a column of DateTime"},
+	}
+	for _, tt := range tests {
+		err := Write(io.Discard, Header{Columns: []Column{tt.c}}, []*Feature{{Properties: map[string]any{"a": tt.v}}})
+		want := "fgb: unsupported value: " + tt.err + ": feature 0"
+		if err == nil || err.Error() != want || !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Write of %T %v for %v = %v, want error %q", tt.v, tt.v, tt.c.Type, err, want)
+		}
+	}
+}
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if w.n -= len(p); w.n < 0 {
+		return 0, errors.New("full")
+	}
+	return len(p), nil
+}
+
+func TestWriteError(t *testing.T) {
+	fs := []*Feature{{Geometry: geom.Point{X: 1, Y: 2}}, {Geometry: geom.Point{X: 3, Y: 4}}}
+	n := len(write(t, Header{}, fs))
+	for _, m := range []int{0, n - 1} {
+		if err := Write(&errWriter{m}, Header{}, fs); err == nil || err.Error() != "full" {
+			t.Errorf("Write to a writer of %d bytes = %v, want full", m, err)
+		}
+	}
+}
+
+func TestWriteReadRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(90))
+	for range 100 {
+		var fs []*Feature
+		for range rnd.Intn(50) {
+			var l geom.LineString
+			for range 1 + rnd.Intn(10) {
+				l = append(l, geom.Point{X: rnd.NormFloat64() * 100, Y: rnd.NormFloat64() * 50, M: rnd.Float64()})
+			}
+			var g geom.Geometry = l
+			if rnd.Intn(2) == 0 {
+				g = geom.MultiPoint(l)
+			}
+			fs = append(fs, &Feature{Geometry: g, Properties: map[string]any{"v": rnd.Int63()}})
+		}
+		h := Header{Layout: geom.XYM, Columns: []Column{{Name: "v", Type: Long}}}
+		_, got := readAll(t, write(t, h, fs))
+		if len(got) != len(fs) {
+			t.Fatalf("%d features read, want %d", len(got), len(fs))
+		}
+		for i := range got {
+			if !reflect.DeepEqual(got[i], fs[i]) {
+				t.Fatalf("feature %d = %v, want %v", i, got[i], fs[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package fgb
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// This file reads and builds the FlatBuffers of FlatGeobuf headers and
+// features, which need only a small part of FlatBuffers: tables of
+// scalars, strings, vectors of scalars and vectors of tables, all little
+// endian. See https://flatbuffers.dev/internals/.
+
+// errBounds is panicked by the readers of flatbuffers when an offset or
+// length is outside the buffer, and recovered by decode.
+type errBounds struct{}
+
+// need panics if the n bytes at pos are not all in b.
+func need(b []byte, pos, n int) {
+	if pos < 0 || n < 0 || pos > len(b)-n {
+		panic(errBounds{})
+	}
+}
+
+// decode calls f, returning false if it reads outside its buffer.
+func decode(f func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(errBounds); !ok {
+				panic(r)
+			}
+		}
+	}()
+	f()
+	return true
+}
+
+func u16(b []byte, pos int) uint16 {
+	need(b, pos, 2)
+	return binary.LittleEndian.Uint16(b[pos:])
+}
+
+func u32(b []byte, pos int) uint32 {
+	need(b, pos, 4)
+	return binary.LittleEndian.Uint32(b[pos:])
+}
+
+func u64(b []byte, pos int) uint64 {
+	need(b, pos, 8)
+	return binary.LittleEndian.Uint64(b[pos:])
+}
+
+// table is a table of a flatbuffer.
+type table struct {
+	b []byte
+	// pos, vtable and vlen are the positions of the table and its vtable
+	// and the length of the vtable in bytes.
+	pos, vtable, vlen int
+}
+
+// root returns the root table of a flatbuffer.
+func root(b []byte) table {
+	return tableAt(b, int(u32(b, 0)))
+}
+
+// tableAt returns the table at a position.
+func tableAt(b []byte, pos int) table {
+	t := table{b: b, pos: pos, vtable: pos - int(int32(u32(b, pos)))}
+	t.vlen = int(u16(b, t.vtable))
+	need(b, t.vtable, t.vlen)
+	return t
+}
+
+// field returns the position of field i of the table, or 0 if it is
+// absent.
+func (t table) field(i int) int {
+	o := 4 + 2*i
+	if o+2 > t.vlen {
+		return 0
+	}
+	if off := u16(t.b, t.vtable+o); off != 0 {
+		return t.pos + int(off)
+	}
+	return 0
+}
+
+// uint returns the unsigned integer of size bytes of field i, or def if
+// it is absent.
+func (t table) uint(i, size int, def uint64) uint64 {
+	p := t.field(i)
+	if p == 0 {
+		return def
+	}
+	need(t.b, p, size)
+	var v uint64
+	for j := size - 1; j >= 0; j-- {
+		v = v<<8 | uint64(t.b[p+j])
+	}
+	return v
+}
+
+// ref returns the position of the object which field i refers to, or 0
+// if it is absent.
+func (t table) ref(i int) int {
+	p := t.field(i)
+	if p == 0 {
+		return 0
+	}
+	return p + int(u32(t.b, p))
+}
+
+// vector returns the position and the length of the vector of field i,
+// whose elements are of size bytes, or 0 and 0 if it is absent.
+func (t table) vector(i, size int) (int, int) {
+	p := t.ref(i)
+	if p == 0 {
+		return 0, 0
+	}
+	n := int(u32(t.b, p))
+	need(t.b, p+4, n*size)
+	return p + 4, n
+}
+
+// string returns the string of field i, or "" if it is absent.
+func (t table) string(i int) string {
+	p, n := t.vector(i, 1)
+	return string(t.b[p : p+n])
+}
+
+// bytes returns the bytes of field i, or nil if it is absent.
+func (t table) bytes(i int) []byte {
+	p, n := t.vector(i, 1)
+	if n == 0 {
+		return nil
+	}
+	return t.b[p : p+n]
+}
+
+// float64s returns the vector of doubles of field i.
+func (t table) float64s(i int) []float64 {
+	p, n := t.vector(i, 8)
+	if n == 0 {
+		return nil
+	}
+	vs := make([]float64, n)
+	for j := range vs {
+		vs[j] = math.Float64frombits(binary.LittleEndian.Uint64(t.b[p+8*j:]))
+	}
+	return vs
+}
+
+// uint32s returns the vector of unsigned integers of field i.
+func (t table) uint32s(i int) []uint32 {
+	p, n := t.vector(i, 4)
+	if n == 0 {
+		return nil
+	}
+	vs := make([]uint32, n)
+	for j := range vs {
+		vs[j] = binary.LittleEndian.Uint32(t.b[p+4*j:])
+	}
+	return vs
+}
+
+// table returns the table of field i, and whether it is present.
+func (t table) table(i int) (table, bool) {
+	p := t.ref(i)
+	if p == 0 {
+		return table{}, false
+	}
+	return tableAt(t.b, p), true
+}
+
+// tables returns the vector of tables of field i.
+func (t table) tables(i int) []table {
+	p, n := t.vector(i, 4)
+	if n == 0 {
+		return nil
+	}
+	ts := make([]table, n)
+	for j := range ts {
+		q := p + 4*j
+		ts[j] = tableAt(t.b, q+int(u32(t.b, q)))
+	}
+	return ts
+}
+
+// field is a field of a table to build: a scalar of size bytes, or an
+// object to which the table refers, which write writes.
+type field struct {
+	size  int
+	value uint64
+	write func(b *builder) int
+}
+
+// The fields of the types of FlatGeobuf.
+
+func scalar(size int, v uint64) *field { return &field{size: size, value: v} }
+
+func boolField(v bool) *field {
+	if v {
+		return scalar(1, 1)
+	}
+	return scalar(1, 0)
+}
+
+func stringField(s string) *field {
+	if s == "" {
+		return nil
+	}
+	return &field{write: func(b *builder) int {
+		p := b.vector(len(s), 1)
+		b.b = append(append(b.b, s...), 0)
+		return p
+	}}
+}
+
+func bytesField(v []byte) *field {
+	if len(v) == 0 {
+		return nil
+	}
+	return &field{write: func(b *builder) int {
+		p := b.vector(len(v), 1)
+		b.b = append(b.b, v...)
+		return p
+	}}
+}
+
+func float64sField(vs []float64) *field {
+	if len(vs) == 0 {
+		return nil
+	}
+	return &field{write: func(b *builder) int {
+		p := b.vector(len(vs), 8)
+		for _, v := range vs {
+			b.b = binary.LittleEndian.AppendUint64(b.b, math.Float64bits(v))
+		}
+		return p
+	}}
+}
+
+func uint32sField(vs []uint32) *field {
+	if len(vs) == 0 {
+		return nil
+	}
+	return &field{write: func(b *builder) int {
+		p := b.vector(len(vs), 4)
+		for _, v := range vs {
+			b.b = binary.LittleEndian.AppendUint32(b.b, v)
+		}
+		return p
+	}}
+}
+
+func tableField(fs []*field) *field {
+	return &field{write: func(b *builder) int { return b.table(fs) }}
+}
+
+func tablesField(ts [][]*field) *field {
+	if len(ts) == 0 {
+		return nil
+	}
+	return &field{write: func(b *builder) int {
+		p := b.vector(len(ts), 4)
+		refs := len(b.b)
+		b.b = append(b.b, make([]byte, 4*len(ts))...)
+		for i, fs := range ts {
+			q := refs + 4*i
+			t := b.table(fs)
+			binary.LittleEndian.PutUint32(b.b[q:], uint32(t-q))
+		}
+		return p
+	}}
+}
+
+// builder builds a flatbuffer front to back, each table being followed
+// by the objects it refers to. Everything is aligned to its size from
+// the start of the buffer.
+type builder struct {
+	b []byte
+}
+
+// build returns the flatbuffer of a root table.
+func build(fs []*field) []byte {
+	b := &builder{b: make([]byte, 4)}
+	binary.LittleEndian.PutUint32(b.b, uint32(b.table(fs)))
+	return b.b
+}
+
+// pad pads the buffer to a multiple of align bytes.
+func (b *builder) pad(align int) {
+	for len(b.b)%align != 0 {
+		b.b = append(b.b, 0)
+	}
+}
+
+// vector writes the length of a vector of n elements of size bytes,
+// so that its elements are aligned, and returns its position.
+func (b *builder) vector(n, size int) int {
+	for (len(b.b)+4)%max(size, 4) != 0 {
+		b.b = append(b.b, 0)
+	}
+	p := len(b.b)
+	b.b = binary.LittleEndian.AppendUint32(b.b, uint32(n))
+	return p
+}
+
+// table writes a table of fields, which are absent if nil, and then the
+// objects to which it refers, and returns its position.
+func (b *builder) table(fs []*field) int {
+	for len(fs) > 0 && fs[len(fs)-1] == nil {
+		fs = fs[:len(fs)-1]
+	}
+	b.pad(2)
+	vt := len(b.b)
+	b.b = binary.LittleEndian.AppendUint16(b.b, uint16(4+2*len(fs)))
+	b.b = append(b.b, make([]byte, 2+2*len(fs))...)
+	b.pad(8)
+	t := len(b.b)
+	b.b = binary.LittleEndian.AppendUint32(b.b, uint32(t-vt))
+	type ref struct {
+		pos int
+		f   *field
+	}
+	var refs []ref
+	// The fields in order of size, for the least padding.
+	for _, size := range [...]int{4, 8, 2, 1} {
+		for i, f := range fs {
+			if f == nil || f.width() != size {
+				continue
+			}
+			b.pad(size)
+			binary.LittleEndian.PutUint16(b.b[vt+4+2*i:], uint16(len(b.b)-t))
+			if f.write != nil {
+				refs = append(refs, ref{len(b.b), f})
+			}
+			for j := range size {
+				b.b = append(b.b, byte(f.value>>(8*j)))
+			}
+		}
+	}
+	binary.LittleEndian.PutUint16(b.b[vt+2:], uint16(len(b.b)-t))
+	for _, r := range refs {
+		p := r.f.write(b) // Before indexing b.b, which write may grow
+		binary.LittleEndian.PutUint32(b.b[r.pos:], uint32(p-r.pos))
+	}
+	return t
+}
+
+// width returns the size of the field in its table.
+func (f *field) width() int {
+	if f.write != nil {
+		return 4 // An offset
+	}
+	return f.size
+}
@@ -0,0 +1,63 @@
+package fgb
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// HTTPReaderAt reads a file on an HTTP server with range requests, so
+// that a File may search a FlatGeobuf file on a server, such as a bucket
+// of an object store, without downloading all of it.
+type HTTPReaderAt struct {
+	// Client is the client which makes the requests, or nil for
+	// http.DefaultClient.
+	Client *http.Client
+
+	// URL is the URL of the file.
+	URL string
+
+	// Header holds the headers to add to each request, such as
+	// Authorization, or nil.
+	Header http.Header
+}
+
+// ReadAt reads the len(p) bytes of the file at offset off with a range
+// request, or fewer and io.EOF at the end of the file. It returns an
+// error if the server does not respond with the range, as when it does
+// not support range requests.
+func (h *HTTPReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, h.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	for k, vs := range h.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(off, 10)+"-"+strconv.FormatInt(off+int64(len(p))-1, 10))
+	c := h.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, io.EOF
+	default:
+		return 0, fmt.Errorf("fgb: range request of %s: %s", h.URL, resp.Status)
+	}
+	n, err := io.ReadFull(resp.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF // The range ends at the end of the file.
+	}
+	return n, err
+}
@@ -0,0 +1,110 @@
+package fgb
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// newServer returns a server of a file which supports range requests,
+// and records the Authorization header of each request.
+func newServer(b []byte, auth *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*auth = append(*auth, r.Header.Get("Authorization"))
+		http.ServeContent(w, r, "a.fgb", time.Time{}, bytes.NewReader(b))
+	}))
+}
+
+func TestHTTPReaderAt(t *testing.T) {
+	b := []byte("0123456789")
+	var auth []string
+	s := newServer(b, &auth)
+	defer s.Close()
+	h := &HTTPReaderAt{URL: s.URL, Header: http.Header{"Authorization": {"Bearer x"}}}
+	tests := []struct {
+		off, n int
+		want   string
+		err    error
+	}{
+		{0, 4, "0123", nil},
+		{3, 7, "3456789", nil},
+		{9, 1, "9", nil},
+		{8, 4, "89", io.EOF},
+		{10, 1, "", io.EOF},
+		{20, 5, "", io.EOF},
+		{5, 0, "", nil},
+	}
+	for _, tt := range tests {
+		p := make([]byte, tt.n)
+		n, err := h.ReadAt(p, int64(tt.off))
+		if got := string(p[:n]); got != tt.want || err != tt.err {
+			t.Errorf("ReadAt(%d bytes, %d) = %q, %v, want %q, %v", tt.n, tt.off, got, err, tt.want, tt.err)
+		}
+	}
+	// There was no request for no bytes.
+	if want := slices.Repeat([]string{"Bearer x"}, len(tests)-1); !slices.Equal(auth, want) {
+		t.Errorf("Authorization headers = %q, want %q", auth, want)
+	}
+}
+
+func TestHTTPReaderAtNoRanges(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	}))
+	defer s.Close()
+	h := &HTTPReaderAt{Client: s.Client(), URL: s.URL}
+	want := "fgb: range request of " + s.URL + ": 200 OK"
+	if n, err := h.ReadAt(make([]byte, 4), 2); n != 0 || err == nil || err.Error() != want {
+		t.Errorf("ReadAt of a server without ranges = %d, %v, want error %q", n, err, want)
+	}
+	h.URL = "http://a b"
+	if _, err := h.ReadAt(make([]byte, 4), 2); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("ReadAt of a bad URL = %v, want an error", err)
+	}
+}
+
+func TestHTTPSearch(t *testing.T) {
+	// A search of a file on a server reads only part of it.
+	rnd := rand.New(rand.NewSource(90))
+	b := write(t, Header{Columns: []Column{{Name: "i", Type: Int}}, IndexNodeSize: 16}, randomFeatures(rnd, 10000))
+	var served atomic.Int64
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &countingWriter{ResponseWriter: w}
+		http.ServeContent(cw, r, "a.fgb", time.Time{}, bytes.NewReader(b))
+		served.Add(int64(cw.n))
+	}))
+	defer s.Close()
+	f, err := NewFile(&HTTPReaderAt{URL: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := geom.Rect{MinX: 10, MinY: 10, MaxX: 12, MaxY: 12}
+	local, _ := NewFile(bytes.NewReader(b))
+	if got, want := searchIndexes(t, f, r), searchIndexes(t, local, r); !slices.Equal(got, want) || len(want) == 0 {
+		t.Errorf("Search over HTTP = %v, want %v", got, want)
+	}
+	if n := served.Load(); n > int64(len(b)/10) {
+		t.Errorf("Search over HTTP served %d bytes of %d", n, len(b))
+	}
+}
+
+// countingWriter counts the bytes of a response.
+type countingWriter struct {
+	http.ResponseWriter
+	n int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += n
+	return n, err
+}
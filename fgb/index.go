@@ -0,0 +1,168 @@
+package fgb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/hilbert"
+)
+
+// nodeLen is the size in bytes of a node of the index: its bounding box
+// and the offset of its first child, or for a leaf of its feature.
+const nodeLen = 40
+
+// node is a node of the index.
+type node struct {
+	rect   geom.Rect
+	offset uint64
+}
+
+// levelBounds returns the indexes in nodes [start, end) of the levels of
+// the packed R-tree of n items with nodes of nodeSize children, from the
+// leaves to the root, which is node 0.
+func levelBounds(n, nodeSize int) [][2]int {
+	counts := []int{n}
+	total := n
+	for m := n; ; {
+		m = (m + nodeSize - 1) / nodeSize
+		counts = append(counts, m)
+		total += m
+		if m == 1 {
+			break
+		}
+	}
+	bounds := make([][2]int, len(counts))
+	for i, c := range counts {
+		total -= c
+		bounds[i] = [2]int{total, total + c}
+	}
+	return bounds
+}
+
+// hilbertOrder is the order of the Hilbert curve along which features
+// are sorted, as in the reference implementation.
+const hilbertOrder = 16
+
+// sortHilbert sorts items along a Hilbert curve through their extent, by
+// the centers of their rectangles, which rect returns. Items with empty
+// rectangles go first.
+func sortHilbert[E any](items []E, rect func(E) geom.Rect) {
+	extent := geom.EmptyRect()
+	for _, it := range items {
+		extent = extent.Union(rect(it))
+	}
+	c, _ := hilbert.New(hilbertOrder)
+	scale := func(v, min, width float64) uint32 {
+		if width == 0 {
+			return 0
+		}
+		return uint32(math.Floor(float64(c.N()-1) * (v - min) / width))
+	}
+	hilbert.Sort(c, items, func(it E) (uint32, uint32) {
+		r := rect(it)
+		if r.IsEmpty() {
+			return 0, 0
+		}
+		return scale((r.MinX+r.MaxX)/2, extent.MinX, extent.Width()),
+			scale((r.MinY+r.MaxY)/2, extent.MinY, extent.Height())
+	})
+}
+
+// appendIndex appends the index of the leaves, in the order of the
+// features, with nodes of nodeSize children.
+func appendIndex(b []byte, leaves []node, nodeSize int) []byte {
+	levels := levelBounds(len(leaves), nodeSize)
+	nodes := make([]node, levels[0][1])
+	copy(nodes[levels[0][0]:], leaves)
+	for l := 1; l < len(levels); l++ {
+		children := levels[l-1]
+		for i := levels[l][0]; i < levels[l][1]; i++ {
+			first := children[0] + (i-levels[l][0])*nodeSize
+			r := geom.EmptyRect()
+			for _, c := range nodes[first:min(first+nodeSize, children[1])] {
+				r = r.Union(c.rect)
+			}
+			nodes[i] = node{r, uint64(first)}
+		}
+	}
+	for _, n := range nodes {
+		for _, v := range [...]float64{n.rect.MinX, n.rect.MinY, n.rect.MaxX, n.rect.MaxY} {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+		}
+		b = binary.LittleEndian.AppendUint64(b, n.offset)
+	}
+	return b
+}
+
+// hit is a feature found by a search, at offsets [start, end) from the
+// start of the features, end being -1 for the last feature.
+type hit struct {
+	start, end int64
+}
+
+// search returns the features of the index at offset off in r, of count
+// features with nodes of nodeSize children, whose bounding boxes
+// intersect a rectangle, in the order of the file. It searches the tree
+// level by level, reading each run of nodes which are together at once.
+func search(r io.ReaderAt, off int64, count, nodeSize int, rect geom.Rect) ([]hit, error) {
+	levels := levelBounds(count, nodeSize)
+	leaves := levels[0]
+	// starts are the first nodes of the groups of siblings to search on
+	// the current level, in order.
+	starts := []int{0}
+	var hits []hit
+	for l := len(levels) - 1; l >= 0 && len(starts) > 0; l-- {
+		var next []int
+		for len(starts) > 0 {
+			// Read the groups of siblings which are together at once.
+			n := 1
+			for n < len(starts) && starts[n] == starts[n-1]+nodeSize {
+				n++
+			}
+			first, end := starts[0], min(starts[n-1]+nodeSize, levels[l][1])
+			// A leaf is followed by the next, whose offset is the end of the
+			// feature, except for the last.
+			last := end
+			if l == 0 && last < leaves[1] {
+				last++
+			}
+			b := make([]byte, (last-first)*nodeLen)
+			if m, err := r.ReadAt(b, off+int64(first)*nodeLen); m < len(b) {
+				return nil, fmt.Errorf("%w: index nodes %d to %d: %w", ErrInvalid, first, last, err)
+			}
+			for i := first; i < end; i++ {
+				nd := readNode(b[(i-first)*nodeLen:])
+				if !rect.Intersects(nd.rect) {
+					continue
+				}
+				if l > 0 {
+					if nd.offset < uint64(levels[l-1][0]) || nd.offset >= uint64(levels[l-1][1]) {
+						return nil, fmt.Errorf("%w: index node %d has child %d outside its level", ErrInvalid, i, nd.offset)
+					}
+					next = append(next, int(nd.offset))
+					continue
+				}
+				h := hit{int64(nd.offset), -1}
+				if i+1 < leaves[1] {
+					h.end = int64(readNode(b[(i+1-first)*nodeLen:]).offset)
+				}
+				if h.start < 0 || h.end >= 0 && h.end < h.start {
+					return nil, fmt.Errorf("%w: index leaf %d has features out of order", ErrInvalid, i)
+				}
+				hits = append(hits, h)
+			}
+			starts = starts[n:]
+		}
+		starts = next
+	}
+	return hits, nil
+}
+
+// readNode reads a node.
+func readNode(b []byte) node {
+	f := func(i int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:])) }
+	return node{geom.Rect{MinX: f(0), MinY: f(1), MaxX: f(2), MaxY: f(3)}, binary.LittleEndian.Uint64(b[32:])}
+}
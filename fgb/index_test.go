@@ -0,0 +1,244 @@
+package fgb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestLevelBounds(t *testing.T) {
+	tests := []struct {
+		n, nodeSize int
+		want        [][2]int
+	}{
+		{1, 16, [][2]int{{1, 2}, {0, 1}}},
+		{3, 16, [][2]int{{1, 4}, {0, 1}}},
+		{16, 16, [][2]int{{1, 17}, {0, 1}}},
+		{17, 16, [][2]int{{3, 20}, {1, 3}, {0, 1}}},
+		{10, 2, [][2]int{{11, 21}, {6, 11}, {3, 6}, {1, 3}, {0, 1}}},
+		{256, 16, [][2]int{{17, 273}, {1, 17}, {0, 1}}},
+	}
+	for _, tt := range tests {
+		if got := levelBounds(tt.n, tt.nodeSize); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("levelBounds(%d, %d) = %v, want %v", tt.n, tt.nodeSize, got, tt.want)
+		}
+		if got, want := indexSize(tt.n, tt.nodeSize), int64(nodeLen*tt.want[0][1]); got != want {
+			t.Errorf("indexSize(%d, %d) = %d, want %d", tt.n, tt.nodeSize, got, want)
+		}
+	}
+	if s := indexSize(0, 16); s != 0 {
+		t.Errorf("indexSize(0, 16) = %d, want 0", s)
+	}
+	if s := indexSize(10, 0); s != 0 {
+		t.Errorf("indexSize(10, 0) = %d, want 0", s)
+	}
+}
+
+func TestSortHilbert(t *testing.T) {
+	// The centers of the corners are in the order of the curve: (0, 0),
+	// (0, 1), (1, 1), (1, 0), after the empty rectangles.
+	pt := func(x, y float64) geom.Rect { return geom.Rect{MinX: x, MinY: y, MaxX: x, MaxY: y} }
+	rects := []geom.Rect{
+		pt(10, 0), geom.EmptyRect(), pt(10, 10), {MinX: -1, MinY: -1, MaxX: 1, MaxY: 1}, pt(0, 10),
+	}
+	want := []geom.Rect{geom.EmptyRect(), rects[3], pt(0, 10), pt(10, 10), pt(10, 0)}
+	sortHilbert(rects, func(r geom.Rect) geom.Rect { return r })
+	if !reflect.DeepEqual(rects, want) {
+		t.Errorf("sortHilbert = %v, want %v", rects, want)
+	}
+}
+
+func TestAppendIndex(t *testing.T) {
+	// Three leaves of nodes of 2, under two nodes and the root.
+	leaves := []node{
+		{geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, 0},
+		{geom.Rect{MinX: 2, MinY: 0, MaxX: 3, MaxY: 1}, 100},
+		{geom.Rect{MinX: 5, MinY: 5, MaxX: 6, MaxY: 7}, 250},
+	}
+	b := appendIndex(nil, leaves, 2)
+	want := []node{
+		{geom.Rect{MinX: 0, MinY: 0, MaxX: 6, MaxY: 7}, 1},
+		{geom.Rect{MinX: 0, MinY: 0, MaxX: 3, MaxY: 1}, 3},
+		{geom.Rect{MinX: 5, MinY: 5, MaxX: 6, MaxY: 7}, 5},
+		leaves[0], leaves[1], leaves[2],
+	}
+	if len(b) != nodeLen*len(want) {
+		t.Fatalf("appendIndex = %d bytes, want %d", len(b), nodeLen*len(want))
+	}
+	for i, w := range want {
+		if n := readNode(b[nodeLen*i:]); n != w {
+			t.Errorf("node %d = %v, want %v", i, n, w)
+		}
+	}
+}
+
+// countingReaderAt counts the reads and bytes read of a reader.
+type countingReaderAt struct {
+	b            []byte
+	reads, bytes int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.reads++
+	m, err := bytes.NewReader(r.b).ReadAt(p, off)
+	r.bytes += m
+	return m, err
+}
+
+// randomFeatures returns features of random points, each with its index
+// as the property "i".
+func randomFeatures(rnd *rand.Rand, n int) []*Feature {
+	fs := make([]*Feature, n)
+	for i := range fs {
+		fs[i] = &Feature{
+			Geometry:   geom.Point{X: rnd.Float64() * 100, Y: rnd.Float64() * 100},
+			Properties: map[string]any{"i": int32(i)},
+		}
+	}
+	return fs
+}
+
+// search returns the indexes of the features of a search, in order.
+func searchIndexes(t *testing.T, f *File, r geom.Rect) []int {
+	t.Helper()
+	var is []int
+	for ft, err := range f.Search(r) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		is = append(is, int(ft.Properties["i"].(int32)))
+	}
+	slices.Sort(is)
+	return is
+}
+
+func TestSearch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(90))
+	h := Header{Columns: []Column{{Name: "i", Type: Int}}}
+	for _, nodeSize := range []int{0, 2, 16} {
+		for _, n := range []int{1, 5, 100, 2000} {
+			fs := randomFeatures(rnd, n)
+			h.IndexNodeSize = nodeSize
+			b := write(t, h, fs)
+			f, err := NewFile(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 20 {
+				x, y := rnd.Float64()*100, rnd.Float64()*100
+				r := geom.Rect{MinX: x, MinY: y, MaxX: x + rnd.Float64()*30, MaxY: y + rnd.Float64()*30}
+				var want []int
+				for i, ft := range fs {
+					if r.Intersects(geom.Bounds(ft.Geometry)) {
+						want = append(want, i)
+					}
+				}
+				if got := searchIndexes(t, f, r); !slices.Equal(got, want) {
+					t.Fatalf("node size %d, %d features: Search(%v) = %v, want %v", nodeSize, n, r, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchReads(t *testing.T) {
+	// A small search reads a small part of a large file, in few reads.
+	rnd := rand.New(rand.NewSource(90))
+	b := write(t, Header{Columns: []Column{{Name: "i", Type: Int}}, IndexNodeSize: 16}, randomFeatures(rnd, 100000))
+	r := &countingReaderAt{b: b}
+	f, err := NewFile(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.reads, r.bytes = 0, 0
+	if is := searchIndexes(t, f, geom.Rect{MinX: 50, MinY: 50, MaxX: 50.5, MaxY: 50.5}); len(is) == 0 {
+		t.Fatal("Search found no features")
+	}
+	if r.bytes > len(b)/100 || r.reads > 20 {
+		t.Errorf("Search read %d bytes of %d in %d reads", r.bytes, len(b), r.reads)
+	}
+	// A search of everything reads the features at once.
+	r.reads = 0
+	if is := searchIndexes(t, f, geom.Rect{MinX: -1, MinY: -1, MaxX: 101, MaxY: 101}); len(is) != 100000 {
+		t.Fatalf("Search of everything found %d features", len(is))
+	}
+	if r.reads > 20 {
+		t.Errorf("Search of everything read in %d reads", r.reads)
+	}
+}
+
+func TestSearchStop(t *testing.T) {
+	rnd := rand.New(rand.NewSource(90))
+	for _, nodeSize := range []int{0, 16} {
+		b := write(t, Header{IndexNodeSize: nodeSize}, randomFeatures(rnd, 100))
+		f, _ := NewFile(bytes.NewReader(b))
+		n := 0
+		for range f.Search(geom.Rect{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100}) {
+			if n++; n == 3 {
+				break
+			}
+		}
+		if n != 3 {
+			t.Errorf("node size %d: Search yielded %d features before the break, want 3", nodeSize, n)
+		}
+	}
+}
+
+func TestSearchInvalid(t *testing.T) {
+	rnd := rand.New(rand.NewSource(90))
+	b := write(t, Header{IndexNodeSize: 2}, randomFeatures(rnd, 4))
+	f, err := NewFile(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := geom.Rect{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100}
+	tests := []struct {
+		name   string
+		change func(b []byte)
+		last   bool // Whether to search the last leaf alone
+		err    string
+	}{
+		{"child outside its level", func(b []byte) {
+			binary.LittleEndian.PutUint64(b[f.index+32:], 5)
+		}, false, "fgb: invalid FlatGeobuf: index node 0 has child 5 outside its level"},
+		{"leaves out of order", func(b []byte) {
+			binary.LittleEndian.PutUint64(b[f.index+4*nodeLen+32:], 1<<40)
+		}, false, "fgb: invalid FlatGeobuf: index leaf 4 has features out of order"},
+		{"truncated index", nil, false, "fgb: invalid FlatGeobuf: index nodes 3 to 7: EOF"},
+		{"feature beyond the end", func(b []byte) {
+			binary.LittleEndian.PutUint64(b[f.index+6*nodeLen+32:], 1<<20)
+		}, true, "fgb: invalid FlatGeobuf: feature of the index beyond the end of the file"},
+	}
+	// A search of the last leaf alone reads its feature at its offset,
+	// rather than after the others.
+	last := readNode(b[f.index+6*nodeLen:]).rect
+	for _, tt := range tests {
+		c := bytes.Clone(b)
+		if tt.change != nil {
+			tt.change(c)
+		} else {
+			c = c[:f.index+4*nodeLen]
+		}
+		f, err := NewFile(bytes.NewReader(c))
+		if err != nil {
+			t.Fatalf("%s: NewFile: %v", tt.name, err)
+		}
+		r := all
+		if tt.last {
+			r = last
+		}
+		var e error
+		for _, err := range f.Search(r) {
+			e = err
+		}
+		if e == nil || e.Error() != tt.err || !errors.Is(e, ErrInvalid) {
+			t.Errorf("%s: Search = %v, want error %q", tt.name, e, tt.err)
+		}
+	}
+}
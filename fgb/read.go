@@ -0,0 +1,490 @@
+package fgb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Reader reads the features of a FlatGeobuf file as a stream.
+type Reader struct {
+	r   *bufio.Reader
+	h   *Header
+	err error
+}
+
+// NewReader returns a reader of a FlatGeobuf file, having read its
+// header and skipped its index. It returns an error wrapping ErrInvalid
+// if the file does not start with a valid header.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	h, n, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if size := indexSize(h.Count, h.IndexNodeSize); size > 0 {
+		if _, err := br.Discard(int(size)); err != nil {
+			return nil, fmt.Errorf("%w: index of %d bytes at offset %d: %w", ErrInvalid, size, n, err)
+		}
+	}
+	return &Reader{r: br, h: h}, nil
+}
+
+// Header returns the header of the file.
+func (r *Reader) Header() *Header {
+	return r.h
+}
+
+// Next returns the next feature, or io.EOF after the last. It returns an
+// error wrapping ErrInvalid if a feature is malformed, and returns the
+// same error on every call after an error.
+func (r *Reader) Next() (*Feature, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	b, err := readSized(r.r, "feature")
+	if err == nil {
+		var f *Feature
+		if f, err = decodeFeature(b, r.h); err == nil {
+			return f, nil
+		}
+	}
+	r.err = err
+	return nil, err
+}
+
+// readHeader reads the magic number and the header of a file, returning
+// the header and the number of bytes read.
+func readHeader(r io.Reader) (*Header, int64, error) {
+	var m [8]byte
+	if _, err := io.ReadFull(r, m[:]); err != nil {
+		return nil, 0, fmt.Errorf("%w: magic number: %w", ErrInvalid, err)
+	}
+	if !bytes.Equal(m[:7], magic[:7]) {
+		return nil, 0, fmt.Errorf("%w: magic number %q", ErrInvalid, m[:])
+	}
+	b, err := readSized(r, "header")
+	if err == io.EOF {
+		// A file of only a magic number has no header, unlike one of no
+		// features.
+		err = fmt.Errorf("%w: size of header: %w", ErrInvalid, err)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	h, err := decodeHeader(b)
+	return h, 12 + int64(len(b)), err
+}
+
+// readSized reads a flatbuffer prefixed with its size, of which what is
+// a name for errors. It returns io.EOF if r is at its end.
+func readSized(r io.Reader, what string) ([]byte, error) {
+	var s [4]byte
+	if _, err := io.ReadFull(r, s[:]); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: size of %s: %w", ErrInvalid, what, err)
+	}
+	n := int64(binary.LittleEndian.Uint32(s[:]))
+	// Copy rather than allocate n bytes, n being untrusted.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, n); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("%w: %s of %d bytes: %w", ErrInvalid, what, n, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// indexSize returns the size in bytes of the index of count features
+// with nodes of nodeSize children, or 0 if there is no index.
+func indexSize(count, nodeSize int) int64 {
+	if nodeSize == 0 || count == 0 {
+		return 0
+	}
+	levels := levelBounds(count, nodeSize)
+	return nodeLen * int64(levels[0][1])
+}
+
+// decodeHeader decodes the flatbuffer of a header.
+func decodeHeader(b []byte) (*Header, error) {
+	h := &Header{Envelope: geom.EmptyRect()}
+	var count uint64
+	ok := decode(func() {
+		t := root(b)
+		h.Name = t.string(0)
+		if e := t.float64s(1); len(e) >= 4 {
+			h.Envelope = geom.Rect{MinX: e[0], MinY: e[1], MaxX: e[2], MaxY: e[3]}
+		}
+		h.GeometryType = geom.Type(t.uint(2, 1, 0))
+		if t.uint(3, 1, 0) != 0 {
+			h.Layout |= geom.XYZ
+		}
+		if t.uint(4, 1, 0) != 0 {
+			h.Layout |= geom.XYM
+		}
+		for _, c := range t.tables(7) {
+			h.Columns = append(h.Columns, Column{
+				Name:        c.string(0),
+				Type:        ColumnType(c.uint(1, 1, 0)),
+				Title:       c.string(2),
+				Description: c.string(3),
+				Width:       max(int(int32(c.uint(4, 4, 0))), 0),
+				Precision:   max(int(int32(c.uint(5, 4, 0))), 0),
+				Scale:       max(int(int32(c.uint(6, 4, 0))), 0),
+				Nullable:    c.uint(7, 1, 1) != 0,
+				Unique:      c.uint(8, 1, 0) != 0,
+				PrimaryKey:  c.uint(9, 1, 0) != 0,
+				Metadata:    c.string(10),
+			})
+		}
+		count = t.uint(8, 8, 0)
+		h.IndexNodeSize = int(t.uint(9, 2, 16))
+		if crs, ok := t.table(10); ok {
+			h.CRS = CRS{
+				Org:         crs.string(0),
+				Code:        int(int32(crs.uint(1, 4, 0))),
+				Name:        crs.string(2),
+				Description: crs.string(3),
+				WKT:         crs.string(4),
+				CodeString:  crs.string(5),
+			}
+		}
+		h.Title, h.Description, h.Metadata = t.string(11), t.string(12), t.string(13)
+	})
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalid)
+	case h.GeometryType < 0 || h.GeometryType > geom.TypeGeometryCollection:
+		return nil, fmt.Errorf("%w: geometry type %d", ErrUnsupported, h.GeometryType)
+	case h.IndexNodeSize == 1:
+		return nil, fmt.Errorf("%w: index node size 1", ErrInvalid)
+	case count > math.MaxInt64/(2*nodeLen):
+		return nil, fmt.Errorf("%w: feature count %d", ErrInvalid, count)
+	}
+	for _, c := range h.Columns {
+		if int(c.Type) >= len(columnTypeNames) {
+			return nil, fmt.Errorf("%w: column %s of type %v", ErrInvalid, c.Name, c.Type)
+		}
+	}
+	h.Count = int(count)
+	if h.Count == 0 {
+		h.IndexNodeSize = 0 // There is no index without a count.
+	}
+	return h, nil
+}
+
+// decodeFeature decodes the flatbuffer of a feature.
+func decodeFeature(b []byte, h *Header) (*Feature, error) {
+	f := &Feature{}
+	var err error
+	ok := decode(func() {
+		t := root(b)
+		columns := h.Columns
+		if cs := t.tables(2); cs != nil {
+			columns = make([]Column, len(cs))
+			for i, c := range cs {
+				columns[i] = Column{Name: c.string(0), Type: ColumnType(c.uint(1, 1, 0))}
+			}
+		}
+		if f.Properties, err = properties(t.bytes(1), columns); err != nil {
+			return
+		}
+		if g, ok := t.table(0); ok {
+			f.Geometry, err = geometry(g, h.GeometryType, h.Layout)
+		}
+	})
+	if !ok {
+		err = fmt.Errorf("%w: malformed feature", ErrInvalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// propertySizes holds the sizes of the properties of the numeric and
+// logical column types, by type.
+var propertySizes = [...]int{
+	Byte: 1, UByte: 1, Bool: 1, Short: 2, UShort: 2, Int: 4, UInt: 4,
+	Long: 8, ULong: 8, Float: 4, Double: 8,
+}
+
+// properties decodes the properties of a feature.
+func properties(b []byte, columns []Column) (map[string]any, error) {
+	ps := make(map[string]any, len(columns))
+	for len(b) > 0 {
+		i := int(u16(b, 0))
+		if i >= len(columns) {
+			return nil, fmt.Errorf("%w: property of column %d of %d", ErrInvalid, i, len(columns))
+		}
+		c := columns[i]
+		b = b[2:]
+		n := 4 // The length of a string or bytes
+		if int(c.Type) < len(propertySizes) {
+			n = propertySizes[c.Type]
+		}
+		need(b, 0, n)
+		var u uint64
+		for j := n - 1; j >= 0; j-- {
+			u = u<<8 | uint64(b[j])
+		}
+		var v any
+		switch c.Type {
+		case Byte:
+			v = int8(u)
+		case UByte:
+			v = uint8(u)
+		case Bool:
+			v = u != 0
+		case Short:
+			v = int16(u)
+		case UShort:
+			v = uint16(u)
+		case Int:
+			v = int32(u)
+		case UInt:
+			v = uint32(u)
+		case Long:
+			v = int64(u)
+		case ULong:
+			v = u
+		case Float:
+			v = math.Float32frombits(uint32(u))
+		case Double:
+			v = math.Float64frombits(u)
+		default:
+			need(b, 4, int(u))
+			s := b[4 : 4+u]
+			n += int(u)
+			switch c.Type {
+			case Binary:
+				v = bytes.Clone(s)
+			case DateTime:
+				if t, err := time.Parse(time.RFC3339Nano, string(s)); err == nil {
+					v = t
+				} else {
+					v = string(s)
+				}
+			default:
+				v = string(s)
+			}
+		}
+		ps[c.Name] = v
+		b = b[n:]
+	}
+	return ps, nil
+}
+
+// geometry decodes a geometry, of type typ unless it has its own type.
+func geometry(t table, typ geom.Type, layout geom.Layout) (geom.Geometry, error) {
+	if tt := geom.Type(t.uint(6, 1, 0)); tt != 0 {
+		typ = tt
+	}
+	switch typ {
+	case geom.TypeMultiPolygon, geom.TypeGeometryCollection:
+		parts := t.tables(7)
+		var m geom.MultiPolygon
+		var c geom.GeometryCollection
+		for _, p := range parts {
+			pt := geom.TypePolygon
+			if typ == geom.TypeGeometryCollection {
+				pt = 0
+			}
+			g, err := geometry(p, pt, layout)
+			if err != nil {
+				return nil, err
+			}
+			if typ == geom.TypeMultiPolygon {
+				poly, ok := g.(geom.Polygon)
+				if !ok {
+					return nil, fmt.Errorf("%w: %v in a MultiPolygon", ErrInvalid, g.Type())
+				}
+				m = append(m, poly)
+			} else {
+				c = append(c, g)
+			}
+		}
+		if typ == geom.TypeMultiPolygon {
+			return m, nil
+		}
+		return c, nil
+	case 0:
+		return nil, fmt.Errorf("%w: geometry of unknown type", ErrInvalid)
+	}
+	if typ > geom.TypeGeometryCollection {
+		return nil, fmt.Errorf("%w: geometry type %d", ErrUnsupported, typ)
+	}
+	xy, z, m := t.float64s(1), t.float64s(2), t.float64s(3)
+	n := len(xy) / 2
+	if len(xy)%2 != 0 || len(z) != 0 && len(z) != n || len(m) != 0 && len(m) != n {
+		return nil, fmt.Errorf("%w: %v of %d coordinates, %d Z and %d M", ErrInvalid, typ, len(xy), len(z), len(m))
+	}
+	ps := make([]geom.Point, n)
+	for i := range ps {
+		ps[i] = geom.Point{X: xy[2*i], Y: xy[2*i+1]}
+		if layout.HasZ() && len(z) > 0 {
+			ps[i].Z = z[i]
+		}
+		if layout.HasM() && len(m) > 0 {
+			ps[i].M = m[i]
+		}
+	}
+	// parts returns the points divided by the ends.
+	parts := func() ([][]geom.Point, error) {
+		ends := t.uint32s(0)
+		if len(ends) == 0 {
+			if n == 0 {
+				return nil, nil
+			}
+			return [][]geom.Point{ps}, nil
+		}
+		parts := make([][]geom.Point, len(ends))
+		start := 0
+		for i, e := range ends {
+			if int(e) < start || int(e) > n || i == len(ends)-1 && int(e) != n {
+				return nil, fmt.Errorf("%w: %v of %d points has part end %d", ErrInvalid, typ, n, e)
+			}
+			parts[i] = ps[start:e]
+			start = int(e)
+		}
+		return parts, nil
+	}
+	switch typ {
+	case geom.TypePoint:
+		switch n {
+		case 0:
+			return geom.EmptyPoint(), nil
+		case 1:
+			return ps[0], nil
+		}
+		return nil, fmt.Errorf("%w: Point of %d points", ErrInvalid, n)
+	case geom.TypeLineString:
+		return geom.LineString(ps), nil
+	case geom.TypeMultiPoint:
+		return geom.MultiPoint(ps), nil
+	}
+	ss, err := parts()
+	if err != nil {
+		return nil, err
+	}
+	if typ == geom.TypePolygon {
+		p := make(geom.Polygon, len(ss))
+		for i, s := range ss {
+			p[i] = geom.Ring(s)
+		}
+		return p, nil
+	}
+	ml := make(geom.MultiLineString, len(ss))
+	for i, s := range ss {
+		ml[i] = geom.LineString(s)
+	}
+	return ml, nil
+}
+
+// File searches the features of a FlatGeobuf file through its index,
+// reading only the nodes of the index and the features which it needs.
+type File struct {
+	r io.ReaderAt
+	h *Header
+	// index is the offset of the index, and features that of the
+	// features.
+	index, features int64
+}
+
+// NewFile returns a FlatGeobuf file read from r, having read its header.
+// Each read of r is of a contiguous range of bytes, so that r may be a
+// HTTPReaderAt with a read for each request. It returns an error
+// wrapping ErrInvalid if the file does not start with a valid header.
+func NewFile(r io.ReaderAt) (*File, error) {
+	br := bufio.NewReader(io.NewSectionReader(r, 0, math.MaxInt64))
+	h, n, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	return &File{r: r, h: h, index: n, features: n + indexSize(h.Count, h.IndexNodeSize)}, nil
+}
+
+// Header returns the header of the file.
+func (f *File) Header() *Header {
+	return f.h
+}
+
+// maxRead is the number of bytes up to which reads of features which
+// are close together are combined.
+const maxRead = 1 << 20
+
+// Search returns an iterator over the features whose bounding boxes
+// intersect a rectangle, in the order of the file, which stops after
+// the first error. If the file has no index, it reads every feature. A
+// HTTPReaderAt is read with a request for each group of nodes of a
+// level of the index which are together, and for each group of features
+// which are together.
+func (f *File) Search(r geom.Rect) iter.Seq2[*Feature, error] {
+	return func(yield func(*Feature, error) bool) {
+		if f.h.IndexNodeSize == 0 {
+			sr := &Reader{r: bufio.NewReader(io.NewSectionReader(f.r, f.features, math.MaxInt64-f.features)), h: f.h}
+			for {
+				ft, err := sr.Next()
+				if err == io.EOF {
+					return
+				}
+				if err == nil && (ft.Geometry == nil || !r.Intersects(geom.Bounds(ft.Geometry))) {
+					continue
+				}
+				if !yield(ft, err) || err != nil {
+					return
+				}
+			}
+		}
+		hits, err := search(f.r, f.index, f.h.Count, f.h.IndexNodeSize, r)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for len(hits) > 0 {
+			// Read the features which are together at once.
+			n := 1
+			for n < len(hits) && hits[n].start == hits[n-1].end && hits[n].end-hits[0].start <= maxRead {
+				n++
+			}
+			start, end := hits[0].start, hits[n-1].end
+			var br io.Reader
+			if end >= 0 && end-start <= maxRead {
+				buf := make([]byte, end-start)
+				if m, err := f.r.ReadAt(buf, f.features+start); m < len(buf) {
+					yield(nil, fmt.Errorf("%w: features at offset %d: %w", ErrInvalid, f.features+start, err))
+					return
+				}
+				br = bytes.NewReader(buf)
+			} else {
+				// The last feature, or a large one, is read in parts.
+				off := f.features + start
+				br = bufio.NewReaderSize(io.NewSectionReader(f.r, off, math.MaxInt64-off), maxRead)
+			}
+			for range n {
+				b, err := readSized(br, "feature")
+				if err == io.EOF {
+					err = fmt.Errorf("%w: feature of the index beyond the end of the file", ErrInvalid)
+				}
+				var ft *Feature
+				if err == nil {
+					ft, err = decodeFeature(b, f.h)
+				}
+				if !yield(ft, err) || err != nil {
+					return
+				}
+			}
+			hits = hits[n:]
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package fgb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// sized returns a flatbuffer prefixed with its size.
+func sized(b []byte) []byte {
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(b))), b...)
+}
+
+// file returns a FlatGeobuf file of the fields of a header, without an
+// index, and encoded features.
+func file(header []*field, features ...[]byte) []byte {
+	b := append(magic[:], sized(build(header))...)
+	for _, f := range features {
+		b = append(b, sized(f)...)
+	}
+	return b
+}
+
+// pointHeader returns the fields of the header of a file of points of
+// columns, without an index.
+func pointHeader(columns ...[]*field) []*field {
+	return []*field{
+		nil, nil, scalar(1, uint64(geom.TypePoint)), nil, nil, nil, nil,
+		tablesField(columns), nil, scalar(2, 0),
+	}
+}
+
+// geometryFeature returns a feature of a geometry of fields.
+func geometryFeature(g ...*field) []byte {
+	return build([]*field{tableField(g)})
+}
+
+func f64s(vs ...float64) *field { return float64sField(vs) }
+
+func TestReadInvalid(t *testing.T) {
+	point := geometryFeature(nil, f64s(1, 2))
+	tests := []struct {
+		name string
+		b    []byte
+		err  error
+		msg  string
+	}{
+		{"empty", nil, ErrInvalid, "fgb: invalid FlatGeobuf: magic number: EOF"},
+		{"short magic", []byte("fgb\x03"), ErrInvalid, "fgb: invalid FlatGeobuf: magic number: unexpected EOF"},
+		{"bad magic", []byte("fgb\x02fgb\x00\x00\x00\x00\x00"), ErrInvalid, `fgb: invalid FlatGeobuf: magic number "fgb\x02fgb\x00"`},
+		{"no header", magic[:], ErrInvalid, "fgb: invalid FlatGeobuf: size of header: EOF"},
+		{"truncated header", file(pointHeader())[:20], ErrInvalid, "fgb: invalid FlatGeobuf: header of 39 bytes: unexpected EOF"},
+		{"malformed header", append(magic[:], sized([]byte{0xff, 0, 0, 0})...), ErrInvalid, "fgb: invalid FlatGeobuf: malformed header"},
+		{"geometry type", file([]*field{nil, nil, scalar(1, 20)}), ErrUnsupported, "fgb: unsupported value: geometry type 20"},
+		{"index node size 1", file([]*field{nil, nil, nil, nil, nil, nil, nil, nil, scalar(8, 1), scalar(2, 1)}), ErrInvalid, "fgb: invalid FlatGeobuf: index node size 1"},
+		{"feature count", file([]*field{nil, nil, nil, nil, nil, nil, nil, nil, scalar(8, 1<<62)}), ErrInvalid, "fgb: invalid FlatGeobuf: feature count 4611686018427387904"},
+		{"column type", file(pointHeader([]*field{stringField("a"), scalar(1, 15)})), ErrInvalid, "fgb: invalid FlatGeobuf: column a of type ColumnType(15)"},
+		{"truncated index", file([]*field{nil, nil, nil, nil, nil, nil, nil, nil, scalar(8, 3)}), ErrInvalid, "fgb: invalid FlatGeobuf: index of 160 bytes at offset 60: EOF"},
+		{"truncated feature", file(pointHeader(), point)[:60], ErrInvalid, "fgb: invalid FlatGeobuf: feature of 64 bytes: unexpected EOF"},
+		{"malformed feature", file(pointHeader(), []byte{0xff, 0xff, 0, 0}), ErrInvalid, "fgb: invalid FlatGeobuf: malformed feature"},
+		{
+			"property column",
+			file(pointHeader([]*field{stringField("a"), scalar(1, uint64(Int))}), build([]*field{nil, bytesField([]byte{1, 0, 7, 0, 0, 0})})),
+			ErrInvalid, "fgb: invalid FlatGeobuf: property of column 1 of 1",
+		},
+		{
+			"truncated property",
+			file(pointHeader([]*field{stringField("a"), scalar(1, uint64(Int))}), build([]*field{nil, bytesField([]byte{0, 0, 7, 0})})),
+			ErrInvalid, "fgb: invalid FlatGeobuf: malformed feature",
+		},
+		{
+			"truncated string",
+			file(pointHeader([]*field{stringField("a"), scalar(1, uint64(String))}), build([]*field{nil, bytesField([]byte{0, 0, 9, 0, 0, 0, 'a'})})),
+			ErrInvalid, "fgb: invalid FlatGeobuf: malformed feature",
+		},
+		{"Point of 2 points", file(pointHeader(), geometryFeature(nil, f64s(1, 2, 3, 4))), ErrInvalid, "fgb: invalid FlatGeobuf: Point of 2 points"},
+		{"odd coordinates", file(pointHeader(), geometryFeature(nil, f64s(1, 2, 3))), ErrInvalid, "fgb: invalid FlatGeobuf: Point of 3 coordinates, 0 Z and 0 M"},
+		{"Z of other points", file(pointHeader(), geometryFeature(nil, f64s(1, 2), f64s(3, 4))), ErrInvalid, "fgb: invalid FlatGeobuf: Point of 2 coordinates, 2 Z and 0 M"},
+		{
+			"part end beyond the points",
+			file(pointHeader(), geometryFeature(uint32sField([]uint32{1, 3}), f64s(1, 2, 3, 4), nil, nil, nil, nil, scalar(1, uint64(geom.TypeMultiLineString)))),
+			ErrInvalid, "fgb: invalid FlatGeobuf: MultiLineString of 2 points has part end 3",
+		},
+		{
+			"part ends out of order",
+			file(pointHeader(), geometryFeature(uint32sField([]uint32{2, 1}), f64s(1, 2, 3, 4), nil, nil, nil, nil, scalar(1, uint64(geom.TypePolygon)))),
+			ErrInvalid, "fgb: invalid FlatGeobuf: Polygon of 2 points has part end 1",
+		},
+		{
+			"last part end short",
+			file(pointHeader(), geometryFeature(uint32sField([]uint32{1}), f64s(1, 2, 3, 4), nil, nil, nil, nil, scalar(1, uint64(geom.TypePolygon)))),
+			ErrInvalid, "fgb: invalid FlatGeobuf: Polygon of 2 points has part end 1",
+		},
+		{
+			"unknown type",
+			file([]*field{}, geometryFeature(nil, f64s(1, 2))),
+			ErrInvalid, "fgb: invalid FlatGeobuf: geometry of unknown type",
+		},
+		{
+			"curve",
+			file([]*field{}, geometryFeature(nil, f64s(1, 2), nil, nil, nil, nil, scalar(1, 8))),
+			ErrUnsupported, "fgb: unsupported value: geometry type 8",
+		},
+		{
+			"point in a MultiPolygon",
+			file([]*field{nil, nil, scalar(1, uint64(geom.TypeMultiPolygon))}, geometryFeature(
+				nil, nil, nil, nil, nil, nil, nil, tablesField([][]*field{{nil, f64s(1, 2), nil, nil, nil, nil, scalar(1, uint64(geom.TypePoint))}}),
+			)),
+			ErrInvalid, "fgb: invalid FlatGeobuf: Point in a MultiPolygon",
+		},
+	}
+	for _, tt := range tests {
+		_, err := readFile(tt.b)
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, tt.err) {
+			t.Errorf("%s: read = %v, want error %q", tt.name, err, tt.msg)
+		}
+	}
+}
+
+// readFile reads the header and the features of a file, returning the
+// features and the first error.
+func readFile(b []byte) ([]*Feature, error) {
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	var fs []*Feature
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			return fs, nil
+		}
+		if err != nil {
+			// The error is sticky.
+			if _, err2 := r.Next(); err2 != err {
+				return nil, errors.New("error not repeated")
+			}
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+}
+
+func TestReadDefaults(t *testing.T) {
+	// A header of no fields is of features of any type, and a feature of
+	// no fields has neither a geometry nor properties.
+	b := file([]*field{}, build([]*field{}))
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := r.Header(); h.GeometryType != 0 || h.Layout != geom.XY || !h.Envelope.IsEmpty() || h.IndexNodeSize != 0 {
+		t.Errorf("Header = %+v, want the defaults", h)
+	}
+	f, err := r.Next()
+	if err != nil || f.Geometry != nil || len(f.Properties) != 0 {
+		t.Errorf("Next = %+v, %v, want a feature of nothing", f, err)
+	}
+	if f, err := r.Next(); f != nil || err != io.EOF {
+		t.Errorf("Next after the last = %v, %v, want io.EOF", f, err)
+	}
+}
+
+func TestReadFeatureColumns(t *testing.T) {
+	// A feature may have its own columns, overriding those of the header.
+	b := file(pointHeader([]*field{stringField("a"), scalar(1, uint64(Int))}), build([]*field{
+		tableField([]*field{nil, f64s(1, 2)}),
+		bytesField([]byte{0, 0, 1}),
+		tablesField([][]*field{{stringField("b"), scalar(1, uint64(Bool))}}),
+	}))
+	fs, err := readFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 1 || fs[0].Properties["b"] != true || len(fs[0].Properties) != 1 {
+		t.Errorf("features = %+v, want b true", fs)
+	}
+}
+
+func TestReadNoZ(t *testing.T) {
+	// A geometry of a file of Z may lack Z, which is then 0.
+	b := file([]*field{nil, nil, scalar(1, uint64(geom.TypeLineString)), scalar(1, 1)}, geometryFeature(nil, f64s(1, 2, 3, 4)))
+	fs, err := readFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geom.LineString{{X: 1, Y: 2}, {X: 3, Y: 4}}
+	if len(fs) != 1 || !reflect.DeepEqual(fs[0].Geometry, want) {
+		t.Errorf("features = %+v, want %v", fs, want)
+	}
+}
+
+func TestReadCorrupt(t *testing.T) {
+	// Corrupted files are errors, or features, but never panics.
+	rnd := rand.New(rand.NewSource(90))
+	var fs []*Feature
+	for i := range 20 {
+		fs = append(fs, &Feature{
+			Geometry:   geom.Polygon{{{X: 0, Y: 0}, {X: float64(i), Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+			Properties: map[string]any{"name": "a", "n": int64(i)},
+		})
+	}
+	valid := write(t, Header{
+		GeometryType:  geom.TypePolygon,
+		Columns:       []Column{{Name: "name", Type: String}, {Name: "n", Type: Long}},
+		IndexNodeSize: 4,
+	}, fs)
+	for range 5000 {
+		b := bytes.Clone(valid[:rnd.Intn(len(valid)+1)])
+		for range 1 + rnd.Intn(3) {
+			if len(b) > 0 {
+				b[rnd.Intn(len(b))] = byte(rnd.Intn(256))
+			}
+		}
+		if _, err := readFile(b); err != nil && !errors.Is(err, ErrInvalid) && !errors.Is(err, ErrUnsupported) {
+			t.Fatalf("read(%x) = %v, want ErrInvalid or ErrUnsupported", b, err)
+		}
+		f, err := NewFile(bytes.NewReader(b))
+		if err != nil {
+			continue
+		}
+		for _, err := range f.Search(geom.Rect{MinX: math.Inf(-1), MinY: math.Inf(-1), MaxX: math.Inf(1), MaxY: math.Inf(1)}) {
+			if err != nil && !errors.Is(err, ErrInvalid) && !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("Search of %x = %v, want ErrInvalid or ErrUnsupported", b, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package fgb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Write writes a FlatGeobuf file of a header and features, setting the
+// count of the header, and its envelope if it is empty or zero. If the
+// header has an index node size, the features are sorted along a
+// Hilbert curve and written with an index. Properties whose names are
+// not those of columns are not written.
+//
+// It returns an error wrapping ErrUnsupported if a property is not of
+// the type of its column, or if the header has a geometry type and a
+// feature has a geometry of another type.
+func Write(w io.Writer, h Header, fs []*Feature) error {
+	if h.IndexNodeSize == 1 || h.IndexNodeSize < 0 || h.IndexNodeSize > math.MaxUint16 {
+		return fmt.Errorf("%w: index node size %d", ErrUnsupported, h.IndexNodeSize)
+	}
+	for _, c := range h.Columns {
+		if c.Name == "" || int(c.Type) >= len(columnTypeNames) {
+			return fmt.Errorf("%w: column %q of type %v", ErrUnsupported, c.Name, c.Type)
+		}
+	}
+	type encoded struct {
+		b    []byte
+		rect geom.Rect
+	}
+	es := make([]encoded, len(fs))
+	envelope := geom.EmptyRect()
+	for i, f := range fs {
+		b, err := encodeFeature(f, &h)
+		if err != nil {
+			return fmt.Errorf("%w: feature %d", err, i)
+		}
+		es[i] = encoded{b, geom.EmptyRect()}
+		if f.Geometry != nil {
+			es[i].rect = geom.Bounds(f.Geometry)
+			envelope = envelope.Union(es[i].rect)
+		}
+	}
+	if h.Envelope.IsEmpty() || h.Envelope == (geom.Rect{}) {
+		h.Envelope = envelope
+	}
+	h.Count = len(fs)
+	if h.Count == 0 {
+		h.IndexNodeSize = 0
+	}
+	b := make([]byte, 12)
+	copy(b, magic[:])
+	header := encodeHeader(&h)
+	binary.LittleEndian.PutUint32(b[8:], uint32(len(header)))
+	b = append(b, header...)
+	if h.IndexNodeSize > 0 {
+		sortHilbert(es, func(e encoded) geom.Rect { return e.rect })
+		leaves := make([]node, len(es))
+		var off uint64
+		for i, e := range es {
+			leaves[i] = node{e.rect, off}
+			off += uint64(len(e.b))
+		}
+		b = appendIndex(b, leaves, h.IndexNodeSize)
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	for _, e := range es {
+		if _, err := w.Write(e.b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeHeader returns the flatbuffer of a header.
+func encodeHeader(h *Header) []byte {
+	var envelope []float64
+	if !h.Envelope.IsEmpty() {
+		envelope = []float64{h.Envelope.MinX, h.Envelope.MinY, h.Envelope.MaxX, h.Envelope.MaxY}
+	}
+	columns := make([][]*field, len(h.Columns))
+	for i, c := range h.Columns {
+		columns[i] = []*field{
+			stringField(c.Name),
+			scalar(1, uint64(c.Type)),
+			stringField(c.Title),
+			stringField(c.Description),
+			intField(c.Width),
+			intField(c.Precision),
+			intField(c.Scale),
+			boolField(c.Nullable),
+			boolField(c.Unique),
+			boolField(c.PrimaryKey),
+			stringField(c.Metadata),
+		}
+	}
+	var crs *field
+	if h.CRS != (CRS{}) {
+		crs = tableField([]*field{
+			stringField(h.CRS.Org),
+			scalar(4, uint64(uint32(h.CRS.Code))),
+			stringField(h.CRS.Name),
+			stringField(h.CRS.Description),
+			stringField(h.CRS.WKT),
+			stringField(h.CRS.CodeString),
+		})
+	}
+	return build([]*field{
+		stringField(h.Name),
+		float64sField(envelope),
+		scalar(1, uint64(h.GeometryType)),
+		boolField(h.Layout.HasZ()),
+		boolField(h.Layout.HasM()),
+		nil, // has_t
+		nil, // has_tm
+		tablesField(columns),
+		scalar(8, uint64(h.Count)),
+		scalar(2, uint64(h.IndexNodeSize)),
+		crs,
+		stringField(h.Title),
+		stringField(h.Description),
+		stringField(h.Metadata),
+	})
+}
+
+// intField returns the field of a width, precision or scale, which is
+// absent if it is unknown.
+func intField(v int) *field {
+	if v <= 0 {
+		return nil
+	}
+	return scalar(4, uint64(v))
+}
+
+// encodeFeature returns the flatbuffer of a feature, prefixed with its
+// size.
+func encodeFeature(f *Feature, h *Header) ([]byte, error) {
+	var geometry *field
+	if f.Geometry != nil {
+		if h.GeometryType != 0 && f.Geometry.Type() != h.GeometryType {
+			return nil, fmt.Errorf("%w: %v in a file of %v", ErrUnsupported, f.Geometry.Type(), h.GeometryType)
+		}
+		g, err := encodeGeometry(f.Geometry, h.Layout)
+		if err != nil {
+			return nil, err
+		}
+		geometry = tableField(g)
+	}
+	var ps []byte
+	for i, c := range h.Columns {
+		v, ok := f.Properties[c.Name]
+		if !ok || v == nil {
+			continue
+		}
+		ps = binary.LittleEndian.AppendUint16(ps, uint16(i))
+		var err error
+		if ps, err = appendProperty(ps, c, v); err != nil {
+			return nil, err
+		}
+	}
+	b := build([]*field{geometry, bytesField(ps)})
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(b))), b...), nil
+}
+
+// appendProperty appends the encoding of a property of a column.
+func appendProperty(b []byte, c Column, v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	var u uint64
+	ok := false
+	// Convert any number which the column can hold exactly.
+	switch c.Type {
+	case Bool:
+		if x, isBool := v.(bool); isBool {
+			ok = true
+			if x {
+				u = 1
+			}
+		}
+	case Byte, Short, Int, Long:
+		var n int64
+		switch {
+		case rv.CanInt():
+			n, ok = rv.Int(), true
+		case rv.CanUint():
+			n, ok = int64(rv.Uint()), rv.Uint() <= math.MaxInt64
+		case rv.CanFloat():
+			n, ok = int64(rv.Float()), float64(int64(rv.Float())) == rv.Float()
+		}
+		bits := 8 * propertySizes[c.Type]
+		ok = ok && (bits == 64 || n >= -1<<(bits-1) && n < 1<<(bits-1))
+		u = uint64(n)
+	case UByte, UShort, UInt, ULong:
+		switch {
+		case rv.CanUint():
+			u, ok = rv.Uint(), true
+		case rv.CanInt():
+			u, ok = uint64(rv.Int()), rv.Int() >= 0
+		case rv.CanFloat():
+			u, ok = uint64(rv.Float()), rv.Float() >= 0 && float64(uint64(rv.Float())) == rv.Float()
+		}
+		bits := 8 * propertySizes[c.Type]
+		ok = ok && (bits == 64 || u < 1<<bits)
+	case Float, Double:
+		var x float64
+		switch {
+		case rv.CanFloat():
+			x, ok = rv.Float(), true
+		case rv.CanInt():
+			x, ok = float64(rv.Int()), true
+		case rv.CanUint():
+			x, ok = float64(rv.Uint()), true
+		}
+		u = math.Float64bits(x)
+		if c.Type == Float {
+			u = uint64(math.Float32bits(float32(x)))
+		}
+	default:
+		var s []byte
+		switch x := v.(type) {
+		case string:
+			s, ok = []byte(x), c.Type != Binary
+		case []byte:
+			s, ok = x, true
+		case time.Time:
+			s, ok = []byte(x.Format(timeLayout)), c.Type == DateTime
+		}
+		if !ok {
+			break
+		}
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+		return append(b, s...), nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: property %s of %T for a column of %v", ErrUnsupported, c.Name, v, c.Type)
+	}
+	for j := range propertySizes[c.Type] {
+		b = append(b, byte(u>>(8*j)))
+	}
+	return b, nil
+}
+
+// encodeGeometry returns the fields of a geometry.
+func encodeGeometry(g geom.Geometry, layout geom.Layout) ([]*field, error) {
+	var ps []geom.Point
+	var ends []uint32
+	var parts [][]*field
+	switch g := g.(type) {
+	case geom.Point:
+		if !g.IsEmpty() {
+			ps = []geom.Point{g}
+		}
+	case geom.LineString:
+		ps = g
+	case geom.MultiPoint:
+		ps = g
+	case geom.Polygon:
+		for _, r := range g {
+			ps = append(ps, r...)
+			ends = append(ends, uint32(len(ps)))
+		}
+	case geom.MultiLineString:
+		for _, l := range g {
+			ps = append(ps, l...)
+			ends = append(ends, uint32(len(ps)))
+		}
+	case geom.MultiPolygon:
+		for _, p := range g {
+			fs, err := encodeGeometry(p, layout)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, fs)
+		}
+	case geom.GeometryCollection:
+		for _, m := range g {
+			fs, err := encodeGeometry(m, layout)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, fs)
+		}
+	default:
+		return nil, fmt.Errorf("%w: geometry of %T", ErrUnsupported, g)
+	}
+	if len(ends) == 1 {
+		ends = nil // A single part needs no ends.
+	}
+	xy := make([]float64, 0, 2*len(ps))
+	var z, m []float64
+	for _, p := range ps {
+		xy = append(xy, p.X, p.Y)
+		if layout.HasZ() {
+			z = append(z, p.Z)
+		}
+		if layout.HasM() {
+			m = append(m, p.M)
+		}
+	}
+	return []*field{
+		uint32sField(ends),
+		float64sField(xy),
+		float64sField(z),
+		float64sField(m),
+		nil, // t
+		nil, // tm
+		scalar(1, uint64(g.Type())),
+		tablesField(parts),
+	}, nil
+}
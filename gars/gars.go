@@ -0,0 +1,140 @@
+// Package gars encodes and decodes cells of the Global Area Reference
+// System (GARS), the standard grid of the United States National
+// Geospatial-Intelligence Agency for operational areas, used for
+// example in airspace coordination and search and rescue.
+//
+// A GARS cell of 30 by 30 minutes of arc is identified by a three digit
+// longitude band, numbered from 001 at 180 degrees west eastward to 720,
+// followed by a pair of letters for the latitude band, numbered from AA
+// at 90 degrees south northward to QZ, omitting the letters I and O. An
+// optional digit from 1 to 4 selects a 15 minute quadrant of the cell,
+// numbered from the north-west corner as 1 2 over 3 4, and a further
+// optional digit from 1 to 9 selects a 5 minute square of the quadrant,
+// numbered as on a telephone keypad from the north-west corner, giving
+// references such as "006AG39".
+package gars
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Precision selects the size of a GARS cell.
+type Precision int
+
+// The supported precisions.
+const (
+	Precision30Min Precision = iota // A 30 by 30 minute cell, such as "006AG"
+	Precision15Min                  // A 15 minute quadrant, such as "006AG3"
+	Precision5Min                   // A 5 minute square, such as "006AG39"
+)
+
+// letters are the letters of the latitude band designations.
+const letters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// ErrInvalid is returned, wrapped, by Decode when its argument is not a
+// valid GARS cell.
+var ErrInvalid = errors.New("gars: invalid cell")
+
+// Box is the rectangle covered by a cell, bounded by lines of latitude
+// and longitude given in degrees.
+type Box struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// Center returns the latitude and longitude of the center of the box.
+func (b Box) Center() (lat, lng float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
+
+// Encode returns the GARS cell with the given precision containing the
+// point with the given latitude and longitude, in degrees. Latitudes
+// beyond the poles are clamped and longitudes are wrapped. The
+// precision must be in the range [Precision30Min, Precision5Min].
+//
+// The complementary function Decode performs the inverse mapping.
+func Encode(lat, lng float64, p Precision) string {
+	lat = max(-90, min(90, lat))
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	// Count in units of 5 minutes from the south-west corner.
+	x := min(360*12-1, int(lng*12))
+	y := min(180*12-1, int((lat+90)*12))
+	band := y / 6
+	var b strings.Builder
+	fmt.Fprintf(&b, "%03d%c%c", x/6+1, letters[band/len(letters)], letters[band%len(letters)])
+	if p >= Precision15Min {
+		// Quadrants are numbered from the north-west, so the upper
+		// half of the cell comes first.
+		qx, qy := x%6/3, 1-y%6/3
+		b.WriteByte(byte('1' + 2*qy + qx))
+	}
+	if p >= Precision5Min {
+		kx, ky := x%3, 2-y%3
+		b.WriteByte(byte('1' + 3*ky + kx))
+	}
+	return b.String()
+}
+
+// Decode returns the rectangle covered by a GARS cell and its
+// precision. Letters may be in either case. It returns an error wrapping
+// ErrInvalid if cell is not a valid GARS cell.
+//
+// The complementary function Encode performs the inverse mapping.
+func Decode(cell string) (Box, Precision, error) {
+	if len(cell) < 5 || len(cell) > 7 {
+		return Box{}, 0, fmt.Errorf("%w: %q must have from 5 to 7 characters", ErrInvalid, cell)
+	}
+	lngBand, err := strconv.Atoi(cell[:3])
+	if err != nil || cell[0] == '+' || cell[0] == '-' || lngBand < 1 || lngBand > 720 {
+		return Box{}, 0, fmt.Errorf("%w: %q has invalid longitude band %q", ErrInvalid, cell, cell[:3])
+	}
+	l1 := strings.IndexByte(letters, upper(cell[3]))
+	l2 := strings.IndexByte(letters, upper(cell[4]))
+	latBand := l1*len(letters) + l2
+	if l1 < 0 || l2 < 0 || latBand >= 360 {
+		return Box{}, 0, fmt.Errorf("%w: %q has invalid latitude band %q", ErrInvalid, cell, cell[3:5])
+	}
+	// Work in units of 5 minutes from the south-west corner.
+	x, y, size := 6*(lngBand-1), 6*latBand, 6
+	p := Precision(len(cell) - 5)
+	if p >= Precision15Min {
+		q := int(cell[5]) - '1'
+		if q < 0 || q > 3 {
+			return Box{}, 0, fmt.Errorf("%w: %q has invalid quadrant %q", ErrInvalid, cell, cell[5])
+		}
+		size = 3
+		x += 3 * (q % 2)
+		y += 3 * (1 - q/2)
+	}
+	if p >= Precision5Min {
+		k := int(cell[6]) - '1'
+		if k < 0 || k > 8 {
+			return Box{}, 0, fmt.Errorf("%w: %q has invalid keypad digit %q", ErrInvalid, cell, cell[6])
+		}
+		size = 1
+		x += k % 3
+		y += 2 - k/3
+	}
+	const unit = 1.0 / 12
+	return Box{
+		MinLat: float64(y)*unit - 90,
+		MaxLat: float64(y+size)*unit - 90,
+		MinLng: float64(x)*unit - 180,
+		MaxLng: float64(x+size)*unit - 180,
+	}, p, nil
+}
+
+// upper returns the upper case form of an ASCII letter.
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,71 @@
+package gars
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		p        Precision
+		want     string
+	}{
+		{-86.95, -177.3, Precision5Min, "006AG39"},
+		{38.8895, -77.0352, Precision30Min, "206LT"},
+		{38.8895, -77.0352, Precision15Min, "206LT2"},
+		{38.8895, -77.0352, Precision5Min, "206LT26"},
+		{-90, -180, Precision30Min, "001AA"},
+		{90, 179.99, Precision5Min, "720QZ23"},
+		{90, 180, Precision5Min, "001QZ11"},
+		{0, 0, Precision15Min, "361HN3"},
+	}
+	for _, tt := range tests {
+		if got := Encode(tt.lat, tt.lng, tt.p); got != tt.want {
+			t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		cell string
+		want Box
+		p    Precision
+	}{
+		{"006AG", Box{-87, -86.5, -177.5, -177}, Precision30Min},
+		{"006ag3", Box{-87, -86.75, -177.5, -177.25}, Precision15Min},
+		{"006AG39", Box{-87, -86 - 11.0/12, -177 - 1.0/3, -177.25}, Precision5Min},
+		{"720QZ", Box{89.5, 90, 179.5, 180}, Precision30Min},
+	}
+	for _, tt := range tests {
+		b, p, err := Decode(tt.cell)
+		if err != nil || p != tt.p || math.Abs(b.MinLat-tt.want.MinLat) > 1e-12 || math.Abs(b.MaxLat-tt.want.MaxLat) > 1e-12 ||
+			math.Abs(b.MinLng-tt.want.MinLng) > 1e-12 || math.Abs(b.MaxLng-tt.want.MaxLng) > 1e-12 {
+			t.Errorf("Decode(%q) = (%v, %d, %v), want (%v, %d)", tt.cell, b, p, err, tt.want, tt.p)
+		}
+	}
+	for _, cell := range []string{"", "006A", "006AG399", "000AA", "721AA", "-01AA", "+01AA", "0x1AA", "001RA", "001AI", "001AO", "001AA0", "001AA5", "001AA10"} {
+		if _, _, err := Decode(cell); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) error = %v, want %v", cell, err, ErrInvalid)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(36))
+	for range 100000 {
+		lat, lng := r.Float64()*180-90, r.Float64()*360-180
+		p := Precision(r.Intn(int(Precision5Min) + 1))
+		cell := Encode(lat, lng, p)
+		b, dp, err := Decode(cell)
+		if err != nil || dp != p || lat < b.MinLat || lat > b.MaxLat || lng < b.MinLng || lng > b.MaxLng {
+			t.Fatalf("Decode(Encode(%v, %v, %d) = %q) = (%v, %d, %v)", lat, lng, p, cell, b, dp, err)
+		}
+		if clat, clng := b.Center(); Encode(clat, clng, p) != cell {
+			t.Fatalf("the center of %q is not in it", cell)
+		}
+	}
+}
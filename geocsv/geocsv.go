@@ -0,0 +1,343 @@
+// Package geocsv reads and writes points in delimited text, CSV or TSV,
+// with the coordinates of each point in two columns, or three with
+// elevations, and its attributes in the others.
+//
+// A Format names the columns of the coordinates and the delimiter. Its
+// zero value reads most files as they are: it detects the delimiter from
+// the first line, whether that line is a header, and which columns of
+// the header hold the coordinates, by their usual names such as "lon"
+// and "lat" or "x" and "y".
+package geocsv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// ErrInvalid is returned, wrapped, when a file has no columns of
+// coordinates, or a coordinate is not a number.
+var ErrInvalid = errors.New("geocsv: invalid CSV")
+
+// Format is a format of delimited text with points.
+type Format struct {
+	// Comma is the delimiter of the fields, or 0 to detect it among the
+	// comma, tab, semicolon and vertical bar for reading by which the
+	// first line has most of, and the comma for writing.
+	//
+	// In reading, a number may have a decimal comma in place of the
+	// point if the delimiter is a semicolon, as in the files which
+	// spreadsheets write in the locales with decimal commas. With any
+	// other delimiter a comma in a number is an error, as it may as well
+	// separate thousands.
+	Comma rune
+
+	// X and Y are the names of the columns of the X, or longitude, and Y,
+	// or latitude, of the points. In reading, if they are empty they are
+	// found in the header by the usual names, and in a file without a
+	// header the columns are named by their numbers from 1, X and Y
+	// being "1" and "2" if they are empty. In writing, they are "x" and
+	// "y" if they are empty.
+	X, Y string
+
+	// Z is the name of the column of the Z, or elevation, of the points,
+	// or "" for none.
+	Z string
+}
+
+// The usual names of the columns of X and Y, in lower case.
+var (
+	xNames = []string{"x", "lon", "lng", "long", "longitude", "easting"}
+	yNames = []string{"y", "lat", "latitude", "northing"}
+)
+
+// Record is a record of a file.
+type Record struct {
+	// Line is the line of the file on which the record starts, from 1.
+	Line int
+
+	// Point is the point of the record, whose Z is 0 unless the format
+	// has a Z column and M is 0. It is empty if its X or Y is blank.
+	Point geom.Point
+
+	// Attributes are the values of the other columns of the record, by
+	// name.
+	Attributes map[string]string
+}
+
+// Reader reads the records of delimited text.
+type Reader struct {
+	r       *csv.Reader
+	columns []string
+	// x, y and z are the indexes of the columns of the coordinates, z
+	// being -1 if there is none.
+	x, y, z int
+	// decimalComma reports whether a number may have a decimal comma,
+	// the delimiter being a semicolon.
+	decimalComma bool
+	// first is the first record, if it is not a header and has not
+	// been read.
+	first []string
+	err   error
+}
+
+// NewReader returns a reader of delimited text in the zero Format.
+func NewReader(r io.Reader) (*Reader, error) {
+	return Format{}.NewReader(r)
+}
+
+// NewReader returns a reader of delimited text in the format, which
+// reads its first line to find its delimiter and header. It returns an
+// error wrapping ErrInvalid if the columns of the coordinates are not
+// found.
+func (f Format) NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(3); bytes.Equal(b, []byte("\xef\xbb\xbf")) {
+		br.Discard(3) // The byte order mark of spreadsheets
+	}
+	comma := f.Comma
+	if comma == 0 {
+		comma = detectComma(br)
+	}
+	cr := csv.NewReader(br)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	rd := &Reader{r: cr, z: -1, decimalComma: comma == ';'}
+	first, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf("%w: no header", ErrInvalid)
+		}
+		return nil, err
+	}
+	rd.x, rd.y = find(first, f.X, xNames), find(first, f.Y, yNames)
+	if f.Z != "" {
+		rd.z = find(first, f.Z, nil)
+	}
+	if rd.x >= 0 && rd.y >= 0 && (f.Z == "" || rd.z >= 0) && !rd.numeric(first) {
+		rd.columns = first
+		return rd, nil
+	}
+	// The first line is not a header, but a record.
+	rd.columns = make([]string, len(first))
+	for i := range first {
+		rd.columns[i] = strconv.Itoa(i + 1)
+	}
+	rd.x, rd.y = find(rd.columns, orDefault(f.X, "1"), nil), find(rd.columns, orDefault(f.Y, "2"), nil)
+	if f.Z != "" {
+		rd.z = find(rd.columns, f.Z, nil)
+	}
+	if rd.x < 0 || rd.y < 0 || (f.Z != "" && rd.z < 0) || !rd.numeric(first) {
+		return nil, fmt.Errorf("%w: no columns of coordinates in %q", ErrInvalid, first)
+	}
+	rd.first = first
+	return rd, nil
+}
+
+// orDefault returns s, or def if s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// detectComma returns the delimiter of which the first line has most.
+func detectComma(br *bufio.Reader) rune {
+	line, _ := br.Peek(br.Size())
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	comma, most := ',', 0
+	for _, c := range []rune{',', '\t', ';', '|'} {
+		if n := bytes.Count(line, []byte(string(c))); n > most {
+			comma, most = c, n
+		}
+	}
+	return comma
+}
+
+// find returns the index of a column by name, ignoring case and spaces,
+// or if the name is empty by the first of the usual names which it
+// finds, or -1.
+func find(columns []string, name string, usual []string) int {
+	names := usual
+	if name != "" {
+		names = []string{name}
+	}
+	for _, n := range names {
+		for i, c := range columns {
+			if strings.EqualFold(strings.TrimSpace(c), n) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// numeric reports whether the coordinates of a record are numbers,
+// which a header's names are not.
+func (r *Reader) numeric(record []string) bool {
+	_, errX := r.number(record[r.x])
+	_, errY := r.number(record[r.y])
+	return errX == nil && errY == nil
+}
+
+// number parses a coordinate.
+func (r *Reader) number(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil && r.decimalComma {
+		v, err = strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	}
+	return v, err
+}
+
+// Columns returns the names of the columns, from the header or, if there
+// is none, the numbers of the columns from 1.
+func (r *Reader) Columns() []string {
+	return r.columns
+}
+
+// Next returns the next record, or io.EOF after the last. A record with
+// too few fields for its coordinates is an error, and one with more
+// fields than the header has the extra fields dropped. It returns an
+// error wrapping ErrInvalid if a coordinate is not a number, and returns
+// the same error on every call after an error.
+func (r *Reader) Next() (*Record, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	rec, err := r.next()
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	return rec, nil
+}
+
+// next reads the next record.
+func (r *Reader) next() (*Record, error) {
+	fields := r.first
+	r.first = nil
+	if fields == nil {
+		var err error
+		if fields, err = r.r.Read(); err != nil {
+			return nil, err
+		}
+	}
+	line, _ := r.r.FieldPos(0)
+	rec := &Record{Line: line, Attributes: make(map[string]string, len(r.columns))}
+	if n := max(r.x, r.y, r.z); n >= len(fields) {
+		return nil, fmt.Errorf("%w: line %d has %d fields, not the %d of its coordinates", ErrInvalid, line, len(fields), n+1)
+	}
+	for i, v := range fields {
+		if i < len(r.columns) && i != r.x && i != r.y && i != r.z {
+			rec.Attributes[r.columns[i]] = v
+		}
+	}
+	if strings.TrimSpace(fields[r.x]) == "" || strings.TrimSpace(fields[r.y]) == "" {
+		rec.Point = geom.EmptyPoint()
+		return rec, nil
+	}
+	var err error
+	if rec.Point.X, err = r.coordinate(fields, r.x, line); err != nil {
+		return nil, err
+	}
+	if rec.Point.Y, err = r.coordinate(fields, r.y, line); err != nil {
+		return nil, err
+	}
+	if r.z >= 0 && strings.TrimSpace(fields[r.z]) != "" {
+		if rec.Point.Z, err = r.coordinate(fields, r.z, line); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// coordinate parses the coordinate of column i of the fields of a line.
+func (r *Reader) coordinate(fields []string, i, line int) (float64, error) {
+	v, err := r.number(fields[i])
+	if err != nil {
+		return 0, fmt.Errorf("%w: line %d: %s %q is not a number", ErrInvalid, line, r.columns[i], fields[i])
+	}
+	return v, nil
+}
+
+// Writer writes points as delimited text.
+type Writer struct {
+	w       *csv.Writer
+	f       Format
+	columns []string
+	header  bool
+}
+
+// NewWriter returns a writer of points as delimited text in the zero
+// Format, with columns of attributes.
+func NewWriter(w io.Writer, columns []string) *Writer {
+	return Format{}.NewWriter(w, columns)
+}
+
+// NewWriter returns a writer of points as delimited text in the format,
+// with columns of attributes, which writes a header of the columns of
+// the coordinates and then those of the attributes before the first
+// point.
+func (f Format) NewWriter(w io.Writer, columns []string) *Writer {
+	cw := csv.NewWriter(w)
+	if f.Comma != 0 {
+		cw.Comma = f.Comma
+	}
+	f.X, f.Y = orDefault(f.X, "x"), orDefault(f.Y, "y")
+	return &Writer{w: cw, f: f, columns: columns}
+}
+
+// Write writes a point and its attributes, those not of the columns of
+// the writer being dropped. An empty point is written with blank
+// coordinates.
+func (w *Writer) Write(p geom.Point, attrs map[string]string) error {
+	if !w.header {
+		header := []string{w.f.X, w.f.Y}
+		if w.f.Z != "" {
+			header = append(header, w.f.Z)
+		}
+		if err := w.w.Write(append(header, w.columns...)); err != nil {
+			return err
+		}
+		w.header = true
+	}
+	var record []string
+	if p.IsEmpty() {
+		record = []string{"", ""}
+		if w.f.Z != "" {
+			record = append(record, "")
+		}
+	} else {
+		record = []string{format(p.X), format(p.Y)}
+		if w.f.Z != "" {
+			record = append(record, format(p.Z))
+		}
+	}
+	for _, c := range w.columns {
+		record = append(record, attrs[c])
+	}
+	return w.w.Write(record)
+}
+
+// format formats a coordinate.
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Flush writes any buffered data, and returns any error of writing.
+func (w *Writer) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,332 @@
+package geocsv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// readAll reads the columns and records of a file.
+func readAll(t *testing.T, f Format, s string) ([]string, []*Record) {
+	t.Helper()
+	r, err := f.NewReader(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("NewReader(%q): %v", s, err)
+	}
+	var recs []*Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return r.Columns(), recs
+		}
+		if err != nil {
+			t.Fatalf("Next of %q: %v", s, err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// equalRecords reports whether records are equal, empty points being
+// equal.
+func equalRecords(a, b []*Record) bool {
+	return slices.EqualFunc(a, b, func(a, b *Record) bool {
+		if a.Point.IsEmpty() || b.Point.IsEmpty() {
+			return a.Point.IsEmpty() == b.Point.IsEmpty() && a.Line == b.Line && reflect.DeepEqual(a.Attributes, b.Attributes)
+		}
+		return reflect.DeepEqual(a, b)
+	})
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		in      string
+		columns []string
+		want    []*Record
+	}{
+		{
+			"CSV", Format{},
+			"name,lat,lon\nParis,48.8566,2.3522\nLondon,51.5072,-0.1276\n",
+			[]string{"name", "lat", "lon"},
+			[]*Record{
+				{2, geom.Point{X: 2.3522, Y: 48.8566}, map[string]string{"name": "Paris"}},
+				{3, geom.Point{X: -0.1276, Y: 51.5072}, map[string]string{"name": "London"}},
+			},
+		},
+		{
+			"TSV", Format{},
+			"Longitude\tLatitude\tnote\n-122.4194\t37.7749\tfog, often\n",
+			[]string{"Longitude", "Latitude", "note"},
+			[]*Record{{2, geom.Point{X: -122.4194, Y: 37.7749}, map[string]string{"note": "fog, often"}}},
+		},
+		{
+			"semicolons and decimal commas", Format{},
+			"id;X;Y\r\n1;2,5;-3,25\r\n2;4.5;6\r\n",
+			[]string{"id", "X", "Y"},
+			[]*Record{
+				{2, geom.Point{X: 2.5, Y: -3.25}, map[string]string{"id": "1"}},
+				{3, geom.Point{X: 4.5, Y: 6}, map[string]string{"id": "2"}},
+			},
+		},
+		{
+			"vertical bars", Format{},
+			"lng|lat\n1|2\n",
+			[]string{"lng", "lat"},
+			[]*Record{{2, geom.Point{X: 1, Y: 2}, map[string]string{}}},
+		},
+		{
+			"no header", Format{},
+			"1.5,2.5,a\n3,4,b\n",
+			[]string{"1", "2", "3"},
+			[]*Record{
+				{1, geom.Point{X: 1.5, Y: 2.5}, map[string]string{"3": "a"}},
+				{2, geom.Point{X: 3, Y: 4}, map[string]string{"3": "b"}},
+			},
+		},
+		{
+			"no header, other columns", Format{X: "3", Y: "2", Z: "1"},
+			"10,20,30\n",
+			[]string{"1", "2", "3"},
+			[]*Record{{1, geom.Point{X: 30, Y: 20, Z: 10}, map[string]string{}}},
+		},
+		{
+			"byte order mark and spaces", Format{},
+			"\ufeff\" Lat \", LONG ,Name\n 1 , 2 ,a\n",
+			[]string{" Lat ", " LONG ", "Name"},
+			[]*Record{{2, geom.Point{X: 2, Y: 1}, map[string]string{"Name": "a"}}},
+		},
+		{
+			"Z", Format{Z: "elevation"},
+			"x,y,elevation\n1,2,3\n4,5,\n",
+			[]string{"x", "y", "elevation"},
+			[]*Record{
+				{2, geom.Point{X: 1, Y: 2, Z: 3}, map[string]string{}},
+				{3, geom.Point{X: 4, Y: 5}, map[string]string{}},
+			},
+		},
+		{
+			"named columns", Format{X: "E", Y: "N"},
+			"x,y,e,n\n1,2,3,4\n",
+			[]string{"x", "y", "e", "n"},
+			[]*Record{{2, geom.Point{X: 3, Y: 4}, map[string]string{"x": "1", "y": "2"}}},
+		},
+		{
+			"named delimiter", Format{Comma: ';'},
+			"x;y;a,b\n1;2;3,4\n",
+			[]string{"x", "y", "a,b"},
+			[]*Record{{2, geom.Point{X: 1, Y: 2}, map[string]string{"a,b": "3,4"}}},
+		},
+		{
+			"blank coordinates", Format{},
+			"x,y,a\n,2,first\n1, ,second\n",
+			[]string{"x", "y", "a"},
+			[]*Record{
+				{2, geom.EmptyPoint(), map[string]string{"a": "first"}},
+				{3, geom.EmptyPoint(), map[string]string{"a": "second"}},
+			},
+		},
+		{
+			"ragged", Format{},
+			"x,y,a\n1,2\n3,4,5,6\n",
+			[]string{"x", "y", "a"},
+			[]*Record{
+				{2, geom.Point{X: 1, Y: 2}, map[string]string{}},
+				{3, geom.Point{X: 3, Y: 4}, map[string]string{"a": "5"}},
+			},
+		},
+		{
+			"quoted lines", Format{},
+			"x,y,a\n1,2,\"one\ntwo\"\n3,4,\"bad \"quote\"\n",
+			[]string{"x", "y", "a"},
+			[]*Record{
+				{2, geom.Point{X: 1, Y: 2}, map[string]string{"a": "one\ntwo"}},
+				{4, geom.Point{X: 3, Y: 4}, map[string]string{"a": "bad \"quote"}},
+			},
+		},
+		{"header only", Format{}, "lon,lat\n", []string{"lon", "lat"}, nil},
+	}
+	for _, tt := range tests {
+		columns, recs := readAll(t, tt.format, tt.in)
+		if !slices.Equal(columns, tt.columns) {
+			t.Errorf("%s: Columns = %q, want %q", tt.name, columns, tt.columns)
+		}
+		if !equalRecords(recs, tt.want) {
+			t.Errorf("%s: records = %v, want %v", tt.name, recs, tt.want)
+		}
+	}
+}
+
+func TestDetectComma(t *testing.T) {
+	tests := []struct {
+		in   string
+		want rune
+	}{
+		{"", ','},
+		{"a", ','},
+		{"a,b;c;d\n,,,,,,", ';'},
+		{"a\tb,c\td", '\t'},
+		{"a|b|c", '|'},
+		{"a,b;c", ','},
+	}
+	for _, tt := range tests {
+		if got := detectComma(bufio.NewReader(strings.NewReader(tt.in))); got != tt.want {
+			t.Errorf("detectComma(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewReaderInvalid(t *testing.T) {
+	tests := []struct {
+		format Format
+		in     string
+		msg    string
+	}{
+		{Format{}, "", "geocsv: invalid CSV: no header"},
+		{Format{}, "\ufeff", "geocsv: invalid CSV: no header"},
+		{Format{}, "a,b\n1,2\n", `geocsv: invalid CSV: no columns of coordinates in ["a" "b"]`},
+		{Format{}, "lat\n1\n", `geocsv: invalid CSV: no columns of coordinates in ["lat"]`},
+		{Format{Z: "z"}, "x,y\n1,2\n", `geocsv: invalid CSV: no columns of coordinates in ["x" "y"]`},
+		{Format{X: "e"}, "x,y\n1,2\n", `geocsv: invalid CSV: no columns of coordinates in ["x" "y"]`},
+		{Format{}, "1,a\n", `geocsv: invalid CSV: no columns of coordinates in ["1" "a"]`},
+	}
+	for _, tt := range tests {
+		r, err := tt.format.NewReader(strings.NewReader(tt.in))
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v.NewReader(%q) = %v, %v, want error %q", tt.format, tt.in, r, err, tt.msg)
+		}
+	}
+}
+
+func TestNextInvalid(t *testing.T) {
+	tests := []struct {
+		format Format
+		in     string
+		n      int // The number of records before the error
+		msg    string
+	}{
+		{Format{}, "x,y\n1,2\n1,a\n3,4\n", 1, `geocsv: invalid CSV: line 3: y "a" is not a number`},
+		{Format{}, "lat,lon,name\n1\n", 0, "geocsv: invalid CSV: line 2 has 1 fields, not the 2 of its coordinates"},
+		{Format{Z: "z"}, "x,y,z\n1,2,high\n", 0, `geocsv: invalid CSV: line 2: z "high" is not a number`},
+		{Format{}, "x,y\n1e999,2\n", 0, `geocsv: invalid CSV: line 2: x "1e999" is not a number`},
+		{Format{}, "x\ty\n1,5\t2\n500,000\t2\n", 0, `geocsv: invalid CSV: line 2: x "1,5" is not a number`},
+		{Format{Comma: '|'}, "x|y\n1|2\n3|500,000\n", 1, `geocsv: invalid CSV: line 3: y "500,000" is not a number`},
+		{Format{}, "x;y\n1,5;2\n1.000,5;2\n", 1, `geocsv: invalid CSV: line 3: x "1.000,5" is not a number`},
+	}
+	for _, tt := range tests {
+		r, err := tt.format.NewReader(strings.NewReader(tt.in))
+		if err != nil {
+			t.Fatalf("NewReader(%q): %v", tt.in, err)
+		}
+		n := 0
+		for {
+			_, err = r.Next()
+			if err != nil {
+				break
+			}
+			n++
+		}
+		if n != tt.n || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("Next of %q = %d records, %v, want %d, %q", tt.in, n, err, tt.n, tt.msg)
+		}
+		if rec, err2 := r.Next(); rec != nil || err2 != err {
+			t.Errorf("Next of %q after an error = %v, %v, want %v", tt.in, rec, err2, err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format  Format
+		columns []string
+		want    string
+	}{
+		{Format{}, []string{"name", "note"}, "x,y,name,note\n2.3522,48.8566,Paris,\"capital, of France\"\n,,nowhere,\n-0.5,0.0000001,,\"say \"\"hi\"\"\"\n"},
+		{Format{Comma: '\t', X: "lon", Y: "lat", Z: "ele"}, []string{"name"}, "lon\tlat\tele\tname\n2.3522\t48.8566\t35\tParis\n\t\t\tnowhere\n-0.5\t0.0000001\t0\t\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		w := tt.format.NewWriter(&b, tt.columns)
+		points := []geom.Point{{X: 2.3522, Y: 48.8566, Z: 35}, geom.EmptyPoint(), {X: -0.5, Y: 1e-7}}
+		attrs := []map[string]string{
+			{"name": "Paris", "note": "capital, of France", "other": "dropped"},
+			{"name": "nowhere"},
+			{"note": `say "hi"`},
+		}
+		for i, p := range points {
+			if err := w.Write(p, attrs[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%+v: Write = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestWriteNothing(t *testing.T) {
+	// A writer of no points writes no header.
+	var b bytes.Buffer
+	w := NewWriter(&b, []string{"a"})
+	if err := w.Flush(); err != nil || b.Len() != 0 {
+		t.Errorf("Flush of no points = %q, %v, want nothing", b.String(), err)
+	}
+}
+
+// errWriter is a writer which fails.
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("full") }
+
+func TestWriteError(t *testing.T) {
+	w := NewWriter(errWriter{}, nil)
+	w.Write(geom.Point{X: 1, Y: 2}, nil)
+	if err := w.Flush(); err == nil || err.Error() != "full" {
+		t.Errorf("Flush = %v, want full", err)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	rnd := rand.New(rand.NewSource(92))
+	for _, f := range []Format{{}, {Comma: '\t'}, {Comma: ';', X: "E", Y: "N", Z: "H"}} {
+		var b bytes.Buffer
+		w := f.NewWriter(&b, []string{"id", "text"})
+		var want []*Record
+		for i := range 100 {
+			p := geom.Point{X: rnd.NormFloat64() * 100, Y: rnd.NormFloat64() * 1e-3}
+			if f.Z != "" {
+				p.Z = float64(rnd.Intn(1000))
+			}
+			if i%10 == 0 {
+				p = geom.EmptyPoint()
+			}
+			attrs := map[string]string{"id": strconv.Itoa(i), "text": string(rune('a'+rnd.Intn(26))) + ",;\t\"\n"[rnd.Intn(5):]}
+			if err := w.Write(p, attrs); err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, &Record{Point: p, Attributes: attrs})
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		_, recs := readAll(t, f, b.String())
+		for _, rec := range recs {
+			rec.Line = 0
+		}
+		if !equalRecords(recs, want) {
+			t.Errorf("%+v: read = %v, want %v", f, recs, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package geodesy
+
+import "math"
+
+// The area of a ring is computed by summing, for each edge, the signed
+// area between the edge and the equator. Edges crossing the prime
+// meridian are counted, since a ring which encircles a pole crosses it
+// an odd number of times and its sum is then off by half the area of
+// the whole surface. The method is that of Karney, "Algorithms for
+// geodesics", section 6.
+
+// RingArea returns the area in square meters enclosed by a ring of
+// points on the ellipsoid joined by geodesics, and the length of the
+// ring in meters. The ring is closed implicitly, so the last point need
+// not repeat the first. The area is positive if the ring runs counter
+// clockwise around the area it encloses, as seen from above, and
+// negative if it runs clockwise; since a ring divides the surface in
+// two, the area returned is that of the part smaller than half the
+// surface, with the sign giving the direction of the ring around it.
+func (g *Geodesic) RingArea(ring []LatLng) (area, perimeter float64) {
+	return ringArea(ring, 4*math.Pi*g.c2, func(a, b LatLng) (float64, float64) {
+		r := g.inverse(a.Lat, a.Lng, b.Lat, b.Lng, true)
+		return r.area, r.s12
+	})
+}
+
+// PolygonArea returns the area in square meters of a polygon on the
+// ellipsoid, given by an outer ring followed by any number of rings of
+// holes, and the total length in meters of all its rings. The edges of
+// the rings are geodesics. The area is that of the outer ring less the
+// areas of the holes, whatever the directions of the rings, so each ring
+// must enclose less than half the surface.
+func (g *Geodesic) PolygonArea(rings [][]LatLng) (area, perimeter float64) {
+	return polygonArea(rings, g.RingArea)
+}
+
+// RingArea returns the area in square meters enclosed by a ring of
+// points on the sphere joined by great circles, and the length of the
+// ring in meters, with the same conventions as Geodesic.RingArea.
+func (s Sphere) RingArea(ring []LatLng) (area, perimeter float64) {
+	r2 := s.Radius * s.Radius
+	return ringArea(ring, 4*math.Pi*r2, func(a, b LatLng) (float64, float64) {
+		// The spherical excess of the quadrilateral between the edge
+		// and the equator.
+		dLambda, _ := angDiff(a.Lng, b.Lng)
+		t1 := math.Tan(a.Lat * degToRad / 2)
+		t2 := math.Tan(b.Lat * degToRad / 2)
+		e := 2 * math.Atan2(math.Tan(dLambda*degToRad/2)*(t1+t2), 1+t1*t2)
+		return r2 * e, s.Haversine(a, b)
+	})
+}
+
+// PolygonArea returns the area in square meters of a polygon on the
+// sphere, given by an outer ring followed by any number of rings of
+// holes, and the total length in meters of all its rings, with the same
+// conventions as Geodesic.PolygonArea.
+func (s Sphere) PolygonArea(rings [][]LatLng) (area, perimeter float64) {
+	return polygonArea(rings, s.RingArea)
+}
+
+// RingArea returns the area in square meters enclosed by a ring of
+// points on the Sphere Earth, and the length of the ring in meters.
+func RingArea(ring []LatLng) (area, perimeter float64) {
+	return Earth.RingArea(ring)
+}
+
+// PolygonArea returns the area in square meters of a polygon with holes
+// on the Sphere Earth, and the total length of its rings in meters.
+func PolygonArea(rings [][]LatLng) (area, perimeter float64) {
+	return Earth.PolygonArea(rings)
+}
+
+// ringArea returns the signed area and the perimeter of a ring on a
+// surface with total area area0, given a function returning the signed
+// area between an edge and the equator, and the length of the edge.
+func ringArea(ring []LatLng, area0 float64, edge func(a, b LatLng) (area, length float64)) (area, perimeter float64) {
+	if len(ring) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	crossings := 0
+	for k, a := range ring {
+		b := ring[(k+1)%len(ring)]
+		s, l := edge(a, b)
+		sum += s
+		perimeter += l
+		crossings += transit(a.Lng, b.Lng)
+	}
+	sum = math.Remainder(sum, area0)
+	if crossings%2 != 0 {
+		if sum < 0 {
+			sum += area0 / 2
+		} else {
+			sum -= area0 / 2
+		}
+	}
+	// The sum is positive for clockwise rings, so reverse it, and
+	// reduce it to the range (-area0/2, area0/2].
+	sum = -sum
+	if sum > area0/2 {
+		sum -= area0
+	} else if sum <= -area0/2 {
+		sum += area0
+	}
+	return sum + 0, perimeter
+}
+
+// polygonArea returns the area of an outer ring less the areas of its
+// holes, and the total perimeter, given the function computing the area
+// and perimeter of a ring.
+func polygonArea(rings [][]LatLng, ringArea func([]LatLng) (float64, float64)) (area, perimeter float64) {
+	for k, ring := range rings {
+		a, p := ringArea(ring)
+		if k == 0 {
+			area += math.Abs(a)
+		} else {
+			area -= math.Abs(a)
+		}
+		perimeter += p
+	}
+	return area, perimeter
+}
+
+// transit returns 1 if the edge from longitude lng1 to lng2 crosses the
+// prime meridian eastwards, -1 if it crosses it westwards, and 0
+// otherwise.
+func transit(lng1, lng2 float64) int {
+	lng12, _ := angDiff(lng1, lng2)
+	lng1, lng2 = angNormalize(lng1), angNormalize(lng2)
+	switch {
+	case lng12 > 0 && (lng1 < 0 && lng2 >= 0 || lng1 > 0 && lng2 == 0):
+		return 1
+	case lng12 < 0 && lng1 >= 0 && lng2 < 0:
+		return -1
+	}
+	return 0
+}
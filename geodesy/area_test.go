@@ -0,0 +1,146 @@
+package geodesy
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// antarctica is the outline of Antarctica used as an example in
+// GeographicLib, running counter clockwise around the continent.
+var antarctica = []LatLng{
+	{-63.1, -58}, {-72.9, -74}, {-71.9, -102}, {-74.9, -102}, {-74.3, -131},
+	{-77.5, -163}, {-77.4, 163}, {-71.7, 172}, {-65.9, 140}, {-65.7, 113},
+	{-66.6, 88}, {-66.9, 59}, {-69.8, 25}, {-70.0, -4}, {-71.0, -14},
+	{-77.3, -33}, {-77.9, -46}, {-74.7, -61},
+}
+
+func TestGeodesicRingArea(t *testing.T) {
+	g := WGS84Geodesic
+	// The area of the WGS84 ellipsoid is 5.10065621724e14 square meters.
+	octant := 5.10065621724088e14 / 8
+	// Two meridian quadrants and a quarter of the equator.
+	quadrants := 2*10001965.729 + math.Pi/2*WGS84.A
+	tests := []struct {
+		ring            []LatLng
+		area, perimeter float64
+	}{
+		// GeographicLib, 13662703680020.1 square meters and 16831067.893 m.
+		{antarctica, 13662703680020.1, 16831067.893},
+		{[]LatLng{{0, 0}, {0, 90}, {90, 0}}, octant, quadrants},
+		{[]LatLng{{90, 0}, {0, 90}, {0, 0}}, -octant, quadrants},
+		{[]LatLng{{0, 0}}, 0, 0},
+		{nil, 0, 0},
+	}
+	for _, tt := range tests {
+		a, p := g.RingArea(tt.ring)
+		if math.Abs(a-tt.area) > 0.1 || math.Abs(p-tt.perimeter) > 0.01 {
+			t.Errorf("RingArea(%v) = %v, %v, want %v, %v", tt.ring, a, p, tt.area, tt.perimeter)
+		}
+	}
+}
+
+func TestGeodesicRingAreaDirection(t *testing.T) {
+	// Reversing a ring, or starting it at another point, changes only
+	// the sign of its area.
+	g := WGS84Geodesic
+	want, _ := g.RingArea(antarctica)
+	rev := slices.Clone(antarctica)
+	slices.Reverse(rev)
+	if a, _ := g.RingArea(rev); math.Abs(a+want) > 0.1 {
+		t.Errorf("RingArea(reversed) = %v, want %v", a, -want)
+	}
+	rot := append(slices.Clone(antarctica[7:]), antarctica[:7]...)
+	if a, _ := g.RingArea(rot); math.Abs(a-want) > 0.1 {
+		t.Errorf("RingArea(rotated) = %v, want %v", a, want)
+	}
+}
+
+func TestSphereRingArea(t *testing.T) {
+	u := Sphere{1}
+	tests := []struct {
+		ring            []LatLng
+		area, perimeter float64
+	}{
+		{[]LatLng{{0, 0}, {0, 90}, {90, 0}}, math.Pi / 2, 3 * math.Pi / 2},
+		{[]LatLng{{90, 0}, {0, 90}, {0, 0}}, -math.Pi / 2, 3 * math.Pi / 2},
+		{[]LatLng{{0, 0}, {0, 60}, {90, 0}}, math.Pi / 3, 4 * math.Pi / 3},
+		// Across the antimeridian.
+		{[]LatLng{{0, 170}, {0, -170}, {90, 0}}, math.Pi / 9, math.Pi/9 + math.Pi},
+		// The equator divides the sphere in two.
+		{[]LatLng{{0, 0}, {0, 120}, {0, -120}}, 2 * math.Pi, 2 * math.Pi},
+		{[]LatLng{{0, 0}, {0, -120}, {0, 120}}, 2 * math.Pi, 2 * math.Pi},
+		{nil, 0, 0},
+	}
+	for _, tt := range tests {
+		a, p := u.RingArea(tt.ring)
+		if math.Abs(a-tt.area) > 1e-14 || math.Abs(p-tt.perimeter) > 1e-14 {
+			t.Errorf("RingArea(%v) = %v, %v, want %v, %v", tt.ring, a, p, tt.area, tt.perimeter)
+		}
+	}
+	if a, _ := RingArea([]LatLng{{0, 0}, {0, 90}, {90, 0}}); math.Abs(a-math.Pi/2*MeanRadius*MeanRadius) > 1 {
+		t.Errorf("RingArea(octant) = %v, want %v", a, math.Pi/2*MeanRadius*MeanRadius)
+	}
+}
+
+func TestSphereRingAreaPole(t *testing.T) {
+	// A ring around the pole is made of triangles meeting at the pole.
+	u := Sphere{1}
+	tri, _ := u.RingArea([]LatLng{{45, 0}, {45, 90}, {90, 0}})
+	if a, _ := u.RingArea([]LatLng{{45, 0}, {45, 90}, {45, 180}, {45, -90}}); math.Abs(a-4*tri) > 1e-14 {
+		t.Errorf("RingArea(north ring) = %v, want %v", a, 4*tri)
+	}
+	if a, _ := u.RingArea([]LatLng{{-45, 0}, {-45, 90}, {-45, 180}, {-45, -90}}); math.Abs(a+4*tri) > 1e-14 {
+		t.Errorf("RingArea(south ring) = %v, want %v", a, -4*tri)
+	}
+	g := WGS84Geodesic
+	n, _ := g.RingArea([]LatLng{{45, 0}, {45, 90}, {45, 180}, {45, -90}})
+	s, _ := g.RingArea([]LatLng{{-45, 0}, {-45, 90}, {-45, 180}, {-45, -90}})
+	if math.Abs(n+s) > 0.1 {
+		t.Errorf("geodesic RingArea of rings around the poles = %v, %v, want opposite", n, s)
+	}
+}
+
+func TestSphereRingAreaRandom(t *testing.T) {
+	// L'Huilier's theorem gives the area of a triangle from its sides.
+	r := rand.New(rand.NewSource(45))
+	u := Sphere{1}
+	for range 1000 {
+		p := []LatLng{randomLatLng(r), randomLatLng(r), randomLatLng(r)}
+		a, b, c := u.Haversine(p[1], p[2]), u.Haversine(p[2], p[0]), u.Haversine(p[0], p[1])
+		s := (a + b + c) / 2
+		want := 4 * math.Atan(math.Sqrt(max(0, math.Tan(s/2)*math.Tan((s-a)/2)*math.Tan((s-b)/2)*math.Tan((s-c)/2))))
+		area, perimeter := u.RingArea(p)
+		if math.Abs(math.Abs(area)-want) > 1e-9 || math.Abs(perimeter-2*s) > 1e-12 {
+			t.Fatalf("RingArea(%v) = %v, %v, want ±%v, %v", p, area, perimeter, want, 2*s)
+		}
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	outer := []LatLng{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+	hole := []LatLng{{0.25, 0.25}, {0.25, 0.75}, {0.75, 0.75}, {0.75, 0.25}}
+	rev := slices.Clone(hole)
+	slices.Reverse(rev)
+	for _, s := range []interface {
+		RingArea([]LatLng) (float64, float64)
+		PolygonArea([][]LatLng) (float64, float64)
+	}{WGS84Geodesic, Earth} {
+		ao, po := s.RingArea(outer)
+		ah, ph := s.RingArea(hole)
+		want := math.Abs(ao) - math.Abs(ah)
+		for _, rings := range [][][]LatLng{{outer, hole}, {outer, rev}} {
+			if a, p := s.PolygonArea(rings); math.Abs(a-want) > 1e-3 || math.Abs(p-po-ph) > 1e-6 {
+				t.Errorf("%T PolygonArea(%v) = %v, %v, want %v, %v", s, rings, a, p, want, po+ph)
+			}
+		}
+		if a, p := s.PolygonArea(nil); a != 0 || p != 0 {
+			t.Errorf("%T PolygonArea(nil) = %v, %v, want 0, 0", s, a, p)
+		}
+	}
+	a, _ := PolygonArea([][]LatLng{outer, hole})
+	if b, _ := Earth.PolygonArea([][]LatLng{outer, hole}); a != b {
+		t.Errorf("PolygonArea = %v, want %v", a, b)
+	}
+}
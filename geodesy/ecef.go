@@ -0,0 +1,140 @@
+package geodesy
+
+import "math"
+
+// ECEF is a position in the Earth-Centered, Earth-Fixed Cartesian
+// coordinate system, in meters, with its origin at the center of the
+// ellipsoid, the Z axis through the north pole, the X axis through the
+// intersection of the equator and the prime meridian, and the Y axis
+// through the equator at 90 degrees east.
+type ECEF struct {
+	X, Y, Z float64
+}
+
+// ToECEF returns the ECEF coordinates of the point at the given
+// position and height in meters above the WGS84 ellipsoid.
+//
+// The complementary function FromECEF performs the inverse mapping.
+func ToECEF(p LatLng, height float64) ECEF {
+	return WGS84.ToECEF(p, height)
+}
+
+// FromECEF returns the position and height in meters above the WGS84
+// ellipsoid of a point given by its ECEF coordinates.
+//
+// The complementary function ToECEF performs the inverse mapping.
+func FromECEF(c ECEF) (p LatLng, height float64) {
+	return WGS84.FromECEF(c)
+}
+
+// ToECEF returns the ECEF coordinates of the point at the given
+// position and height in meters above the ellipsoid.
+//
+// The complementary method FromECEF performs the inverse mapping.
+func (e Ellipsoid) ToECEF(p LatLng, height float64) ECEF {
+	a, e2, h := e.A, e.E2(), height
+	sinPhi, cosPhi := sincosd(p.Lat)
+	sinLambda, cosLambda := sincosd(p.Lng)
+	n := a / math.Sqrt(1-e2*sinPhi*sinPhi) // The radius of curvature in the prime vertical
+	r := (n + h) * cosPhi
+	return ECEF{r * cosLambda, r * sinLambda, (n*(1-e2) + h) * sinPhi}
+}
+
+// FromECEF returns the position and height in meters above the
+// ellipsoid of a point given by its ECEF coordinates. The conversion is
+// exact to round-off for all points, including those near the center of
+// the ellipsoid; the longitude of a point on the Z axis is 0.
+//
+// The point on the ellipsoid nearest c is found in closed form as in
+// Karney's GeographicLib, which reduces the problem to the same quartic
+// as the starting guess for nearly antipodal geodesics.
+//
+// The complementary method ToECEF performs the inverse mapping.
+func (e Ellipsoid) FromECEF(c ECEF) (LatLng, float64) {
+	a, f, e2 := e.A, e.F, e.E2()
+	e2m := 1 - e2
+	e2a := math.Abs(e2)
+	e4a := e2 * e2
+	r := math.Hypot(c.X, c.Y)
+	lng := 0.0
+	if r != 0 {
+		lng = math.Atan2(c.Y, c.X) / degToRad
+	}
+	var sinPhi, cosPhi, h float64
+	if e4a == 0 {
+		// A sphere.
+		hh := math.Hypot(r, c.Z)
+		sinPhi, cosPhi = c.Z/hh, r/hh
+		if hh == 0 {
+			sinPhi, cosPhi = 0, 1
+		}
+		h = hh - a
+	} else {
+		p := (r / a) * (r / a)
+		q := e2m * (c.Z / a) * (c.Z / a)
+		rr := (p + q - e4a) / 6
+		if f < 0 {
+			p, q = q, p
+		}
+		if !(e4a*q == 0 && rr <= 0) {
+			s := e4a * p * q / 4
+			r2 := rr * rr
+			r3 := rr * r2
+			disc := s * (2*r3 + s)
+			u := rr
+			if disc >= 0 {
+				t3 := s + r3
+				if t3 < 0 {
+					t3 -= math.Sqrt(disc)
+				} else {
+					t3 += math.Sqrt(disc)
+				}
+				t := math.Cbrt(t3)
+				if t != 0 {
+					u += t + r2/t
+				}
+			} else {
+				ang := math.Atan2(math.Sqrt(-disc), -(s + r3))
+				u += 2 * rr * math.Cos(ang/3)
+			}
+			v := math.Sqrt(u*u + e4a*q)
+			var uv float64
+			if u < 0 {
+				uv = e4a * q / (v - u)
+			} else {
+				uv = u + v
+			}
+			w := max(0, e2a*(uv-q)/(2*v))
+			k := uv / (math.Sqrt(uv+w*w) + w)
+			k1, k2 := k, k+e2
+			if f < 0 {
+				k1, k2 = k-e2, k
+			}
+			d := k1 * r / k2
+			hh := math.Hypot(c.Z/k1, r/k2)
+			sinPhi, cosPhi = (c.Z/k1)/hh, (r/k2)/hh
+			h = (1 - e2m/k1) * math.Hypot(d, c.Z)
+		} else {
+			// The point is inside the evolute of the ellipsoid on its
+			// equatorial plane, or on its axis for a prolate ellipsoid,
+			// and so has two nearest points on the ellipsoid.
+			var zz, xx float64
+			if f >= 0 {
+				zz, xx = math.Sqrt((e4a-p)/e2m), math.Sqrt(p)
+			} else {
+				zz, xx = math.Sqrt(p/e2m), math.Sqrt(e4a-p)
+			}
+			hh := math.Hypot(zz, xx)
+			sinPhi, cosPhi = zz/hh, xx/hh
+			if c.Z < 0 {
+				sinPhi = -sinPhi
+			}
+			scale := 1.0
+			if f >= 0 {
+				scale = e2m
+			}
+			h = -a * scale * hh / e2a
+		}
+	}
+	return LatLng{math.Atan2(sinPhi, cosPhi) / degToRad, lng}, h
+}
@@ -0,0 +1,85 @@
+package geodesy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestToECEF(t *testing.T) {
+	b := WGS84.B()
+	tests := []struct {
+		p      LatLng
+		height float64
+		want   ECEF
+	}{
+		// Mount Everest, as in the GeographicLib Geocentric example.
+		{LatLng{27.99, 86.93}, 8820, ECEF{302271.43, 5635928.37, 2979666.13}},
+		{LatLng{0, 0}, 0, ECEF{WGS84.A, 0, 0}},
+		{LatLng{0, 90}, 100, ECEF{0, WGS84.A + 100, 0}},
+		{LatLng{0, 180}, 0, ECEF{-WGS84.A, 0, 0}},
+		{LatLng{90, 0}, 0, ECEF{0, 0, b}},
+		{LatLng{-90, 0}, 10, ECEF{0, 0, -b - 10}},
+	}
+	for _, tt := range tests {
+		c := ToECEF(tt.p, tt.height)
+		if math.Abs(c.X-tt.want.X) > 0.01 || math.Abs(c.Y-tt.want.Y) > 0.01 || math.Abs(c.Z-tt.want.Z) > 0.01 {
+			t.Errorf("ToECEF(%v, %v) = %v, want %v", tt.p, tt.height, c, tt.want)
+		}
+		p, h := FromECEF(tt.want)
+		if math.Abs(p.Lat-tt.p.Lat) > 1e-7 || math.Abs(h-tt.height) > 0.01 ||
+			math.Abs(math.Cos(p.Lat*degToRad)*(p.Lng-tt.p.Lng)) > 1e-7 {
+			t.Errorf("FromECEF(%v) = %v, %v, want %v, %v", tt.want, p, h, tt.p, tt.height)
+		}
+	}
+}
+
+func TestFromECEFCenter(t *testing.T) {
+	// The points near the center are nearest to the poles, or on the
+	// equatorial plane, to two points on the ellipsoid.
+	b := WGS84.B()
+	tests := []struct {
+		c      ECEF
+		p      LatLng
+		height float64
+	}{
+		{ECEF{0, 0, 0}, LatLng{90, 0}, -b},
+		{ECEF{0, 0, 100}, LatLng{90, 0}, 100 - b},
+		{ECEF{0, 0, -100}, LatLng{-90, 0}, 100 - b},
+		{ECEF{0, 0, -b}, LatLng{-90, 0}, 0},
+	}
+	for _, tt := range tests {
+		p, h := FromECEF(tt.c)
+		if math.Abs(p.Lat-tt.p.Lat) > 1e-9 || p.Lng != tt.p.Lng || math.Abs(h-tt.height) > 1e-6 {
+			t.Errorf("FromECEF(%v) = %v, %v, want %v, %v", tt.c, p, h, tt.p, tt.height)
+		}
+	}
+	// Off the axis inside the evolute, the nearest point is still on
+	// the surface at the height returned.
+	c := ECEF{1000, 0, 0}
+	p, h := FromECEF(c)
+	if s := ToECEF(p, h); math.Abs(s.X-c.X) > 1e-6 || math.Abs(s.Z-c.Z) > 1e-6 {
+		t.Errorf("ToECEF(FromECEF(%v)) = %v", c, s)
+	}
+}
+
+func TestECEFRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(46))
+	for _, e := range []Ellipsoid{WGS84, Airy1830, {"sphere", 6371e3, 0}, {"prolate", 6371e3, -1.0 / 300}} {
+		for k := range 10000 {
+			p := randomLatLng(r)
+			h := r.Float64()*2e4 - 1e4
+			if k%2 == 0 {
+				h = r.Float64()*4e7 - 6e6
+			}
+			c := e.ToECEF(p, h)
+			q, h2 := e.FromECEF(c)
+			if c2 := e.ToECEF(q, h2); math.Abs(c.X-c2.X) > 1e-6 || math.Abs(c.Y-c2.Y) > 1e-6 || math.Abs(c.Z-c2.Z) > 1e-6 {
+				t.Fatalf("%v: ToECEF(FromECEF(%v)) = %v", e, c, c2)
+			}
+			if h > -6e6 && (math.Abs(h2-h) > 1e-6 || math.Abs(q.Lat-p.Lat) > 1e-9) {
+				t.Fatalf("%v: FromECEF(ToECEF(%v, %v)) = %v, %v", e, p, h, q, h2)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package geodesy
+
+import "strings"
+
+// Ellipsoid is an ellipsoid of revolution, the reference surface of a
+// geodetic datum, given by its equatorial radius, or semi-major axis, A
+// in meters and its flattening F = (A-B)/A, where B is the polar radius.
+// A flattening of zero gives a sphere.
+type Ellipsoid struct {
+	Name string
+	A, F float64
+}
+
+// The built in ellipsoids.
+var (
+	WGS84             = Ellipsoid{"WGS84", 6378137, 1 / 298.257223563}
+	GRS80             = Ellipsoid{"GRS80", 6378137, 1 / 298.257222101}
+	WGS72             = Ellipsoid{"WGS72", 6378135, 1 / 298.26}
+	GRS67             = Ellipsoid{"GRS67", 6378160, 1 / 298.247167427}
+	Airy1830          = Ellipsoid{"Airy 1830", 6377563.396, 1 - 6356256.909/6377563.396}
+	AiryModified      = Ellipsoid{"Airy Modified 1849", 6377340.189, 1 - 6356034.448/6377340.189}
+	Bessel1841        = Ellipsoid{"Bessel 1841", 6377397.155, 1 / 299.1528128}
+	Clarke1866        = Ellipsoid{"Clarke 1866", 6378206.4, 1 - 6356583.8/6378206.4}
+	Clarke1880        = Ellipsoid{"Clarke 1880", 6378249.145, 1 / 293.465}
+	Everest1830       = Ellipsoid{"Everest 1830", 6377276.345, 1 / 300.8017}
+	International1924 = Ellipsoid{"International 1924", 6378388, 1 / 297.0}
+	Krassovsky1940    = Ellipsoid{"Krassovsky 1940", 6378245, 1 / 298.3}
+)
+
+// ellipsoids lists the built in ellipsoids.
+var ellipsoids = []Ellipsoid{
+	WGS84, GRS80, WGS72, GRS67, Airy1830, AiryModified, Bessel1841,
+	Clarke1866, Clarke1880, Everest1830, International1924, Krassovsky1940,
+}
+
+// Ellipsoids returns the built in ellipsoids.
+func Ellipsoids() []Ellipsoid {
+	return append([]Ellipsoid(nil), ellipsoids...)
+}
+
+// LookupEllipsoid returns the built in ellipsoid with the given name,
+// such as "WGS84" or "Airy 1830", ignoring case. It returns false if
+// there is no such ellipsoid.
+func LookupEllipsoid(name string) (Ellipsoid, bool) {
+	for _, e := range ellipsoids {
+		if strings.EqualFold(e.Name, name) {
+			return e, true
+		}
+	}
+	return Ellipsoid{}, false
+}
+
+// String returns the name of the ellipsoid.
+func (e Ellipsoid) String() string {
+	return e.Name
+}
+
+// B returns the polar radius, or semi-minor axis, of the ellipsoid in
+// meters.
+func (e Ellipsoid) B() float64 {
+	return e.A * (1 - e.F)
+}
+
+// E2 returns the square of the eccentricity of the ellipsoid.
+func (e Ellipsoid) E2() float64 {
+	return e.F * (2 - e.F)
+}
+
+// MeanRadius returns the mean radius R1 = (2A+B)/3 of the ellipsoid in
+// meters.
+func (e Ellipsoid) MeanRadius() float64 {
+	return (2*e.A + e.B()) / 3
+}
+
+// Sphere returns the sphere with the mean radius of the ellipsoid.
+func (e Ellipsoid) Sphere() Sphere {
+	return Sphere{e.MeanRadius()}
+}
@@ -0,0 +1,95 @@
+package geodesy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEllipsoid(t *testing.T) {
+	tests := []struct {
+		e                 Ellipsoid
+		b, e2, meanRadius float64
+	}{
+		// The defining constants of WGS84 and GRS80, and the derived
+		// polar radius, eccentricity and mean radius, from NIMA TR8350.2;
+		// Airy 1830 is defined by its two axes.
+		{WGS84, 6356752.3142, 0.00669437999014, 6371008.7714},
+		{GRS80, 6356752.3141, 0.00669438002290, 6371008.7714},
+		{Airy1830, 6356256.909, 0.00667054007, 6370461.234},
+		{Ellipsoid{"unit", 1, 0}, 1, 0, 1},
+	}
+	for _, tt := range tests {
+		if b := tt.e.B(); math.Abs(b-tt.b) > 1e-3 {
+			t.Errorf("%v.B() = %v, want %v", tt.e, b, tt.b)
+		}
+		if e2 := tt.e.E2(); math.Abs(e2-tt.e2) > 1e-11 {
+			t.Errorf("%v.E2() = %v, want %v", tt.e, e2, tt.e2)
+		}
+		if r := tt.e.MeanRadius(); math.Abs(r-tt.meanRadius) > 1e-3 {
+			t.Errorf("%v.MeanRadius() = %v, want %v", tt.e, r, tt.meanRadius)
+		}
+		if s := tt.e.Sphere(); s.Radius != tt.e.MeanRadius() {
+			t.Errorf("%v.Sphere() = %v, want radius %v", tt.e, s, tt.e.MeanRadius())
+		}
+	}
+	if r := WGS84.MeanRadius(); math.Abs(r-MeanRadius) > 0.1 {
+		t.Errorf("WGS84.MeanRadius() = %v, want %v", r, MeanRadius)
+	}
+}
+
+func TestLookupEllipsoid(t *testing.T) {
+	tests := []struct {
+		name string
+		want Ellipsoid
+		ok   bool
+	}{
+		{"WGS84", WGS84, true},
+		{"wgs84", WGS84, true},
+		{"Airy 1830", Airy1830, true},
+		{"AIRY MODIFIED 1849", AiryModified, true},
+		{"clarke 1866", Clarke1866, true},
+		{"International 1924", International1924, true},
+		{"Airy1830", Ellipsoid{}, false},
+		{"", Ellipsoid{}, false},
+	}
+	for _, tt := range tests {
+		if e, ok := LookupEllipsoid(tt.name); e != tt.want || ok != tt.ok {
+			t.Errorf("LookupEllipsoid(%q) = %v, %v, want %v, %v", tt.name, e, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEllipsoids(t *testing.T) {
+	es := Ellipsoids()
+	if len(es) != 12 || es[0] != WGS84 {
+		t.Fatalf("Ellipsoids() = %v", es)
+	}
+	for _, e := range es {
+		if got, ok := LookupEllipsoid(e.String()); !ok || got != e {
+			t.Errorf("LookupEllipsoid(%q) = %v, %v, want %v", e.String(), got, ok, e)
+		}
+		if e.F <= 0 || e.F > 0.004 || e.A < 6377e3 || e.A > 6379e3 {
+			t.Errorf("%v = %v, %v, not the Earth", e, e.A, e.F)
+		}
+	}
+	// The slice returned is a copy.
+	es[0] = Ellipsoid{}
+	if e := Ellipsoids()[0]; e != WGS84 {
+		t.Errorf("Ellipsoids()[0] = %v after modification, want %v", e, WGS84)
+	}
+}
+
+func TestEllipsoidGeodesics(t *testing.T) {
+	// The same points are further apart on a larger ellipsoid.
+	from, to := LatLng{50, -5}, LatLng{58, 3}
+	d1, _, _, err1 := Airy1830.VincentyInverse(from, to)
+	d2, _, _, err2 := International1924.VincentyInverse(from, to)
+	if err1 != nil || err2 != nil || d1 >= d2 {
+		t.Errorf("VincentyInverse on Airy 1830, International 1924 = %v, %v, want increasing", d1, d2)
+	}
+	if g := NewGeodesic(Airy1830); g.Ellipsoid() != Airy1830 {
+		t.Errorf("NewGeodesic(Airy1830).Ellipsoid() = %v", g.Ellipsoid())
+	} else if d, _, _ := g.Inverse(from, to); math.Abs(d-d1) > 1e-3 {
+		t.Errorf("Airy1830 Inverse(%v, %v) = %v, want %v", from, to, d, d1)
+	}
+}
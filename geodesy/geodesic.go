@@ -0,0 +1,1046 @@
+package geodesy
+
+import (
+	"iter"
+	"math"
+)
+
+// Geodesic solves geodesic problems on an ellipsoid of revolution with
+// the algorithms of Charles F. F. Karney, "Algorithms for geodesics",
+// Journal of Geodesy 87 (2013), as implemented in GeographicLib. The
+// results are accurate to round-off, about 15 nanometers on the Earth,
+// and unlike Vincenty's formulae the inverse problem is solved for all
+// pairs of points, including antipodal ones.
+//
+// A Geodesic is immutable, so it may be used by several goroutines at
+// once.
+type Geodesic struct {
+	ellipsoid             Ellipsoid
+	a, f                  float64
+	f1, e2, ep2, n, b, c2 float64
+	etol2                 float64
+	a3x                   [nA3]float64
+	c3x                   [nC3x]float64
+	c4x                   [nC4x]float64
+}
+
+// Series orders. The series are carried to sixth order in the third
+// flattening and eps, which is enough for full double precision for
+// flattenings up to about 1/50.
+const (
+	nA1  = 6
+	nC1  = 6
+	nC1p = 6
+	nA2  = 6
+	nC2  = 6
+	nA3  = 6
+	nC3  = 6
+	nC3x = nC3 * (nC3 - 1) / 2
+	nC4  = 6
+	nC4x = nC4 * (nC4 + 1) / 2
+)
+
+// Tolerances of the iterative solution of the inverse problem.
+var (
+	tiny   = math.Sqrt(math.SmallestNonzeroFloat64 * (1 << 52))
+	tol0   = math.Nextafter(1, 2) - 1
+	tol1   = 200 * tol0
+	tol2   = math.Sqrt(tol0)
+	tolb   = tol0 * tol2
+	xthres = 1000 * tol2
+)
+
+const (
+	maxit1 = 20
+	maxit2 = maxit1 + 53 + 10
+)
+
+// WGS84Geodesic is the Geodesic of the WGS84 ellipsoid.
+var WGS84Geodesic = NewGeodesic(WGS84)
+
+// NewGeodesic returns the Geodesic of an ellipsoid. The equatorial
+// radius must be positive and the flattening less than 1; a negative
+// flattening gives a prolate ellipsoid. The series used by the
+// algorithms are most accurate for flattenings of magnitude below about
+// 1/50.
+func NewGeodesic(e Ellipsoid) *Geodesic {
+	a, f := e.A, e.F
+	g := &Geodesic{ellipsoid: e, a: a, f: f}
+	g.f1 = 1 - f
+	g.e2 = f * (2 - f)
+	g.ep2 = g.e2 / (g.f1 * g.f1)
+	g.n = f / (2 - f)
+	g.b = a * g.f1
+	// c2 is the square of the authalic radius.
+	var ratio float64
+	switch {
+	case g.e2 == 0:
+		ratio = 1
+	case g.e2 > 0:
+		ratio = math.Atanh(math.Sqrt(g.e2)) / math.Sqrt(g.e2)
+	default:
+		ratio = math.Atan(math.Sqrt(-g.e2)) / math.Sqrt(-g.e2)
+	}
+	g.c2 = (a*a + g.b*g.b*ratio) / 2
+	g.etol2 = 0.1 * tol2 / math.Sqrt(max(0.001, math.Abs(f))*min(1, 1-f/2)/2)
+	g.a3coeff()
+	g.c3coeff()
+	g.c4coeff()
+	return g
+}
+
+// Ellipsoid returns the ellipsoid on which g solves geodesic problems.
+func (g *Geodesic) Ellipsoid() Ellipsoid {
+	return g.ellipsoid
+}
+
+// Inverse solves the inverse geodesic problem: it returns the length in
+// meters of the shortest path on the ellipsoid between two points, and
+// the azimuths of the path at each end, in degrees clockwise from north
+// in the range [0, 360). The azimuth at the second point is the
+// direction of travel there, not the direction back to the first point.
+// When the points are antipodal or at opposite poles, there are many
+// shortest paths and Inverse picks one of them.
+func (g *Geodesic) Inverse(from, to LatLng) (distance, azi1, azi2 float64) {
+	r := g.inverse(from.Lat, from.Lng, to.Lat, to.Lng, false)
+	return r.s12, normalizeBearing(atan2d(r.salp1, r.calp1)), normalizeBearing(atan2d(r.salp2, r.calp2))
+}
+
+// Direct solves the direct geodesic problem: it returns the point
+// reached by following the geodesic from start with the initial
+// azimuth azi1, in degrees, for the given distance in meters, and the
+// azimuth of the geodesic at that point, in degrees in the range
+// [0, 360). The distance may be negative, or longer than half the
+// circumference of the ellipsoid, in which case the geodesic continues
+// around the ellipsoid.
+func (g *Geodesic) Direct(start LatLng, azi1, distance float64) (end LatLng, azi2 float64) {
+	return g.Line(start, azi1).Position(distance)
+}
+
+// inverseResult holds the solution of the inverse problem: the arc
+// length on the auxiliary sphere in degrees, the distance, the sines
+// and cosines of the azimuths at each end, and the area in square
+// meters between the geodesic and the equator, if requested.
+type inverseResult struct {
+	a12, s12                   float64
+	salp1, calp1, salp2, calp2 float64
+	area                       float64
+}
+
+func (g *Geodesic) inverse(lat1, lon1, lat2, lon2 float64, withArea bool) (r inverseResult) {
+	// Compute the longitude difference exactly, and reduce the problem
+	// by symmetries to one where lat1 <= 0, lat1 <= lat2 <= -lat1 and
+	// 0 <= lon12 <= 180.
+	lon12, lon12s := angDiff(lon1, lon2)
+	lonsign := math.Copysign(1, lon12)
+	lon12 = lonsign * angRound(lon12)
+	lon12s = angRound((180 - lon12) - lonsign*lon12s)
+	lam12 := lon12 * degToRad
+	var slam12, clam12 float64
+	if lon12 > 90 {
+		slam12, clam12 = sincosd(lon12s)
+		clam12 = -clam12
+	} else {
+		slam12, clam12 = sincosd(lon12)
+	}
+	lat1 = angRound(latFix(lat1))
+	lat2 = angRound(latFix(lat2))
+	swapp := 1.0
+	if math.Abs(lat1) < math.Abs(lat2) || math.IsNaN(lat2) {
+		swapp = -1
+		lonsign = -lonsign
+		lat1, lat2 = lat2, lat1
+	}
+	latsign := math.Copysign(1, -lat1)
+	lat1 *= latsign
+	lat2 *= latsign
+
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1, cbet1 = norm(g.f1*sbet1, cbet1)
+	cbet1 = max(tiny, cbet1)
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2, cbet2 = norm(g.f1*sbet2, cbet2)
+	cbet2 = max(tiny, cbet2)
+	// Make the reduced latitudes exactly symmetric when the latitudes
+	// are, which they may not be because of rounding.
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			sbet2 = math.Copysign(sbet1, sbet2)
+		}
+	} else if math.Abs(sbet2) == -sbet1 {
+		cbet2 = cbet1
+	}
+	dn1 := math.Sqrt(1 + g.ep2*sbet1*sbet1)
+	dn2 := math.Sqrt(1 + g.ep2*sbet2*sbet2)
+
+	var c1a [nC1 + 1]float64
+	var c2a [nC2 + 1]float64
+	var c3a [nC3]float64
+	var a12, s12x, sig12, salp1, calp1, salp2, calp2 float64
+	var omg12, somg12, comg12 float64
+	somg12 = math.NaN()
+
+	meridian := lat1 == -90 || slam12 == 0
+	if meridian {
+		// The endpoints are on a single full meridian, so the geodesic
+		// might lie along it.
+		calp1, salp1 = clam12, slam12
+		calp2, salp2 = 1, 0
+		ssig1, csig1 := sbet1, calp1*cbet1
+		ssig2, csig2 := sbet2, calp2*cbet2
+		sig12 = math.Atan2(max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+		var m12x float64
+		s12x, m12x = g.lengths(g.n, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, &c1a, &c2a)
+		// The meridian is the shortest path unless sig12 > pi and the
+		// reduced length is negative, which happens for a prolate
+		// ellipsoid or for points near opposite poles.
+		if sig12 < 1 || m12x >= 0 {
+			if sig12 < 3*tiny || (sig12 < tol0 && (s12x < 0 || m12x < 0)) {
+				sig12, s12x = 0, 0
+			}
+			s12x *= g.b
+			a12 = sig12 / degToRad
+		} else {
+			meridian = false
+		}
+	}
+
+	switch {
+	case meridian:
+	case sbet1 == 0 && (g.f <= 0 || lon12s >= g.f*180):
+		// The geodesic runs along the equator.
+		calp1, calp2 = 0, 0
+		salp1, salp2 = 1, 1
+		s12x = g.a * lam12
+		sig12 = lam12 / g.f1
+		omg12 = sig12
+		a12 = lon12 / g.f1
+	default:
+		var dnm float64
+		sig12, salp1, calp1, salp2, calp2, dnm = g.inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12)
+		if sig12 >= 0 {
+			// A short line, for which the spherical approximation is
+			// good enough.
+			s12x = sig12 * g.b * dnm
+			a12 = sig12 / degToRad
+			omg12 = lam12 / (g.f1 * dnm)
+			break
+		}
+		// Solve for the azimuth at the first point by Newton's method,
+		// falling back to bisection if Newton's method goes astray.
+		var ssig1, csig1, ssig2, csig2, eps, domg12 float64
+		tripn, tripb := false, false
+		salp1a, calp1a := tiny, 1.0
+		salp1b, calp1b := tiny, -1.0
+		for numit := 0; numit < maxit2; {
+			var v, dv float64
+			v, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dv = g.lambda12(
+				sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12, numit < maxit1, &c1a, &c2a, &c3a)
+			tol := tol0
+			if tripn {
+				tol *= 8
+			}
+			if tripb || !(math.Abs(v) >= tol) {
+				break
+			}
+			// Update the bracketing interval.
+			if v > 0 && (numit > maxit1 || calp1/salp1 > calp1b/salp1b) {
+				salp1b, calp1b = salp1, calp1
+			} else if v < 0 && (numit > maxit1 || calp1/salp1 < calp1a/salp1a) {
+				salp1a, calp1a = salp1, calp1
+			}
+			numit++
+			if numit < maxit1 && dv > 0 {
+				dalp1 := -v / dv
+				if math.Abs(dalp1) < math.Pi {
+					sdalp1, cdalp1 := math.Sincos(dalp1)
+					nsalp1 := salp1*cdalp1 + calp1*sdalp1
+					if nsalp1 > 0 {
+						calp1 = calp1*cdalp1 - salp1*sdalp1
+						salp1, calp1 = norm(nsalp1, calp1)
+						tripn = math.Abs(v) <= 16*tol0
+						continue
+					}
+				}
+			}
+			// Bisect the bracketing interval.
+			salp1, calp1 = norm((salp1a+salp1b)/2, (calp1a+calp1b)/2)
+			tripn = false
+			tripb = math.Abs(salp1a-salp1)+(calp1a-calp1) < tolb ||
+				math.Abs(salp1-salp1b)+(calp1-calp1b) < tolb
+		}
+		s12x, _ = g.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, &c1a, &c2a)
+		s12x *= g.b
+		a12 = sig12 / degToRad
+		sdomg12, cdomg12 := math.Sincos(domg12)
+		somg12 = slam12*cdomg12 - clam12*sdomg12
+		comg12 = clam12*cdomg12 + slam12*sdomg12
+	}
+
+	var area float64
+	if withArea {
+		salp0 := salp1 * cbet1
+		calp0 := math.Hypot(calp1, salp1*sbet1)
+		if calp0 != 0 && salp0 != 0 {
+			ssig1, csig1 := norm(sbet1, calp1*cbet1)
+			ssig2, csig2 := norm(sbet2, calp2*cbet2)
+			k2 := calp0 * calp0 * g.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			a4 := g.a * g.a * calp0 * salp0 * g.e2
+			var c4a [nC4]float64
+			g.c4f(eps, &c4a)
+			b41 := sinCosSeries(false, ssig1, csig1, c4a[:])
+			b42 := sinCosSeries(false, ssig2, csig2, c4a[:])
+			area = a4 * (b42 - b41)
+		}
+		if !meridian && math.IsNaN(somg12) {
+			somg12, comg12 = math.Sincos(omg12)
+		}
+		var alp12 float64
+		if !meridian && comg12 > -0.7071 && sbet2-sbet1 < 1.75 {
+			// Use the tan(alp/2) formula for short lines, which is more
+			// accurate.
+			domg12 := 1 + comg12
+			dbet1, dbet2 := 1+cbet1, 1+cbet2
+			alp12 = 2 * math.Atan2(somg12*(sbet1*dbet2+sbet2*dbet1), domg12*(sbet1*sbet2+dbet1*dbet2))
+		} else {
+			salp12 := salp2*calp1 - calp2*salp1
+			calp12 := calp2*calp1 + salp2*salp1
+			if salp12 == 0 && calp12 < 0 {
+				salp12, calp12 = tiny*calp1, -1
+			}
+			alp12 = math.Atan2(salp12, calp12)
+		}
+		area += g.c2 * alp12
+		area *= swapp * lonsign * latsign
+		area += 0
+	}
+
+	if swapp < 0 {
+		salp1, salp2 = salp2, salp1
+		calp1, calp2 = calp2, calp1
+	}
+	salp1 *= swapp * lonsign
+	calp1 *= swapp * latsign
+	salp2 *= swapp * lonsign
+	calp2 *= swapp * latsign
+	return inverseResult{a12: a12, s12: 0 + s12x, salp1: salp1, calp1: calp1, salp2: salp2, calp2: calp2, area: area}
+}
+
+// lengths returns the distance and reduced length, divided by b, of a
+// geodesic segment with arc length sig12 on the auxiliary sphere.
+func (g *Geodesic) lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64,
+	c1a *[nC1 + 1]float64, c2a *[nC2 + 1]float64) (s12b, m12b float64) {
+	a1 := a1m1f(eps)
+	c1f(eps, c1a)
+	a2 := a2m1f(eps)
+	c2f(eps, c2a)
+	m0x := a1 - a2
+	a1++
+	a2++
+	b1 := sinCosSeries(true, ssig2, csig2, c1a[:]) - sinCosSeries(true, ssig1, csig1, c1a[:])
+	s12b = a1 * (sig12 + b1)
+	b2 := sinCosSeries(true, ssig2, csig2, c2a[:]) - sinCosSeries(true, ssig1, csig1, c2a[:])
+	j12 := m0x*sig12 + (a1*b1 - a2*b2)
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+	return
+}
+
+// inverseStart returns a starting guess for the azimuth at the first
+// point of the inverse problem. For short lines it returns the solution
+// itself, with sig12 >= 0; otherwise sig12 is negative.
+func (g *Geodesic) inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64) (
+	sig12, salp1, calp1, salp2, calp2, dnm float64) {
+	sig12 = -1
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2*cbet1 + cbet2*sbet1
+	shortline := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+	var somg12, comg12 float64
+	if shortline {
+		sbetm2 := (sbet1 + sbet2) * (sbet1 + sbet2)
+		sbetm2 /= sbetm2 + (cbet1+cbet2)*(cbet1+cbet2)
+		dnm = math.Sqrt(1 + g.ep2*sbetm2)
+		omg12 := lam12 / (g.f1 * dnm)
+		somg12, comg12 = math.Sincos(omg12)
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+	salp1 = cbet2 * somg12
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*somg12*somg12/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+	}
+	ssig12 := math.Hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	switch {
+	case shortline && ssig12 < g.etol2:
+		// The points are very close, so solve on the sphere.
+		salp2 = cbet1 * somg12
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*(somg12*somg12/(1+comg12))
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+		salp2, calp2 = norm(salp2, calp2)
+		sig12 = math.Atan2(ssig12, csig12)
+	case math.Abs(g.n) >= 0.1 || csig12 >= 0 || ssig12 >= 6*math.Abs(g.n)*math.Pi*cbet1*cbet1:
+		// Nothing to do: the spherical guess is good enough.
+	default:
+		// The points are nearly antipodal, so use the solution of the
+		// astroid problem for the starting guess.
+		lam12x := math.Atan2(-slam12, -clam12)
+		var x, y, lamscale, betscale float64
+		if g.f >= 0 {
+			k2 := sbet1 * sbet1 * g.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			lamscale = g.f * cbet1 * g.a3f(eps) * math.Pi
+			betscale = lamscale * cbet1
+			x = lam12x / lamscale
+			y = sbet12a / betscale
+		} else {
+			cbet12a := cbet2*cbet1 - sbet2*sbet1
+			bet12a := math.Atan2(sbet12a, cbet12a)
+			var c1a [nC1 + 1]float64
+			var c2a [nC2 + 1]float64
+			_, m12b := g.lengths(g.n, math.Pi+bet12a, sbet1, -cbet1, dn1, sbet2, cbet2, dn2, &c1a, &c2a)
+			m0 := a1m1f(g.n) - a2m1f(g.n)
+			x = -1 + m12b/(cbet1*cbet2*m0*math.Pi)
+			if x < -0.01 {
+				betscale = sbet12a / x
+			} else {
+				betscale = -g.f * cbet1 * cbet1 * math.Pi
+			}
+			lamscale = betscale / cbet1
+			y = lam12x / lamscale
+		}
+		if y > -tol1 && x > -1-xthres {
+			if g.f >= 0 {
+				salp1 = min(1, -x)
+				calp1 = -math.Sqrt(1 - salp1*salp1)
+			} else {
+				if x > -tol1 {
+					calp1 = 0
+				} else {
+					calp1 = -1
+				}
+				calp1 = max(calp1, x)
+				salp1 = math.Sqrt(1 - calp1*calp1)
+			}
+		} else {
+			k := astroid(x, y)
+			var omg12a float64
+			if g.f >= 0 {
+				omg12a = lamscale * (-x * k / (1 + k))
+			} else {
+				omg12a = lamscale * (-y * (1 + k) / k)
+			}
+			somg12, comg12 = math.Sincos(omg12a)
+			comg12 = -comg12
+			salp1 = cbet2 * somg12
+			calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+		}
+	}
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+	return
+}
+
+// lambda12 returns the difference between the longitude difference of
+// the geodesic from the first point with azimuth alp1 to the latitude
+// of the second point and the longitude difference of the second point,
+// together with its derivative with respect to alp1 if diffp is true
+// and the intermediate quantities needed to finish the solution.
+func (g *Geodesic) lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64, diffp bool,
+	c1a *[nC1 + 1]float64, c2a *[nC2 + 1]float64, c3a *[nC3]float64) (
+	lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12 float64) {
+	if sbet1 == 0 && calp1 == 0 {
+		// Break the degeneracy of equatorial lines.
+		calp1 = -tiny
+	}
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+	ssig1 = sbet1
+	somg1 := salp0 * sbet1
+	csig1 = calp1 * cbet1
+	comg1 := csig1
+	ssig1, csig1 = norm(ssig1, csig1)
+
+	if cbet2 != cbet1 {
+		salp2 = salp0 / cbet2
+	} else {
+		salp2 = salp1
+	}
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var d float64
+		if cbet1 < -sbet1 {
+			d = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			d = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+		calp2 = math.Sqrt(calp1*cbet1*calp1*cbet1+d) / cbet2
+	} else {
+		calp2 = math.Abs(calp1)
+	}
+	ssig2 = sbet2
+	somg2 := salp0 * sbet2
+	csig2 = calp2 * cbet2
+	comg2 := csig2
+	ssig2, csig2 = norm(ssig2, csig2)
+
+	sig12 = math.Atan2(max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+	somg12 := max(0, comg1*somg2-somg1*comg2)
+	comg12 := comg1*comg2 + somg1*somg2
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+	k2 := calp0 * calp0 * g.ep2
+	eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	g.c3f(eps, c3a)
+	b312 := sinCosSeries(true, ssig2, csig2, c3a[:]) - sinCosSeries(true, ssig1, csig1, c3a[:])
+	domg12 = -g.f * g.a3f(eps) * salp0 * (sig12 + b312)
+	lam12 = eta + domg12
+	if diffp {
+		if calp2 == 0 {
+			dlam12 = -2 * g.f1 * dn1 / sbet1
+		} else {
+			_, dlam12 = g.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, c1a, c2a)
+			dlam12 *= g.f1 / (calp2 * cbet2)
+		}
+	} else {
+		dlam12 = math.NaN()
+	}
+	return
+}
+
+// astroid returns the positive root k of k^4 + 2k^3 - (x^2+y^2-1)k^2 -
+// 2y^2k - y^2 = 0, which gives the starting guess for nearly antipodal
+// points.
+func astroid(x, y float64) float64 {
+	p, q := x*x, y*y
+	r := (p + q - 1) / 6
+	if q == 0 && r <= 0 {
+		return 0
+	}
+	s := p * q / 4
+	r2 := r * r
+	r3 := r * r2
+	disc := s * (s + 2*r3)
+	u := r
+	if disc >= 0 {
+		t3 := s + r3
+		if t3 < 0 {
+			t3 -= math.Sqrt(disc)
+		} else {
+			t3 += math.Sqrt(disc)
+		}
+		t := math.Cbrt(t3)
+		if t != 0 {
+			u += t + r2/t
+		}
+	} else {
+		ang := math.Atan2(math.Sqrt(-disc), -(s + r3))
+		u += 2 * r * math.Cos(ang/3)
+	}
+	v := math.Sqrt(u*u + q)
+	var uv float64
+	if u < 0 {
+		uv = q / (v - u)
+	} else {
+		uv = u + v
+	}
+	w := (uv - q) / (2 * v)
+	return uv / (math.Sqrt(uv+w*w) + w)
+}
+
+// GeodesicLine is a geodesic through a point with a given azimuth,
+// along which positions can be computed efficiently, for example to
+// sample the geodesic between two points incrementally.
+type GeodesicLine struct {
+	g                          *Geodesic
+	lat1, lon1, azi1           float64
+	salp0, calp0, k2           float64
+	ssig1, csig1, somg1, comg1 float64
+	stau1, ctau1, dn1          float64
+	a1m1, b11, a3c, b31        float64
+	c1a                        [nC1 + 1]float64
+	c1pa                       [nC1p + 1]float64
+	c3a                        [nC3]float64
+	distance                   float64
+}
+
+// Line returns the geodesic through start with azimuth azi1, in
+// degrees. The line has no end, so its Distance is NaN.
+func (g *Geodesic) Line(start LatLng, azi1 float64) *GeodesicLine {
+	salp1, calp1 := sincosd(angRound(azi1))
+	l := g.line(start, azi1, salp1, calp1)
+	l.distance = math.NaN()
+	return l
+}
+
+// InverseLine returns the geodesic from one point to another, along the
+// shortest path as found by Inverse. Its Distance is the length of the
+// path, so that Position(l.Distance()) is the point to.
+func (g *Geodesic) InverseLine(from, to LatLng) *GeodesicLine {
+	r := g.inverse(from.Lat, from.Lng, to.Lat, to.Lng, false)
+	azi1 := atan2d(r.salp1, r.calp1)
+	l := g.line(from, azi1, r.salp1, r.calp1)
+	l.distance = r.s12
+	return l
+}
+
+func (g *Geodesic) line(start LatLng, azi1, salp1, calp1 float64) *GeodesicLine {
+	l := &GeodesicLine{g: g, lat1: latFix(start.Lat), lon1: start.Lng, azi1: angNormalize(azi1)}
+	sbet1, cbet1 := sincosd(angRound(l.lat1))
+	sbet1, cbet1 = norm(g.f1*sbet1, cbet1)
+	cbet1 = max(tiny, cbet1)
+	l.dn1 = math.Sqrt(1 + g.ep2*sbet1*sbet1)
+	l.salp0 = salp1 * cbet1
+	l.calp0 = math.Hypot(calp1, salp1*sbet1)
+	l.ssig1 = sbet1
+	l.somg1 = l.salp0 * sbet1
+	if sbet1 != 0 || calp1 != 0 {
+		l.csig1 = cbet1 * calp1
+	} else {
+		l.csig1 = 1
+	}
+	l.comg1 = l.csig1
+	l.ssig1, l.csig1 = norm(l.ssig1, l.csig1)
+	l.k2 = l.calp0 * l.calp0 * g.ep2
+	eps := l.k2 / (2*(1+math.Sqrt(1+l.k2)) + l.k2)
+
+	l.a1m1 = a1m1f(eps)
+	c1f(eps, &l.c1a)
+	l.b11 = sinCosSeries(true, l.ssig1, l.csig1, l.c1a[:])
+	s, c := math.Sincos(l.b11)
+	l.stau1 = l.ssig1*c + l.csig1*s
+	l.ctau1 = l.csig1*c - l.ssig1*s
+	c1pf(eps, &l.c1pa)
+	g.c3f(eps, &l.c3a)
+	l.a3c = -g.f * l.salp0 * g.a3f(eps)
+	l.b31 = sinCosSeries(true, l.ssig1, l.csig1, l.c3a[:])
+	return l
+}
+
+// Start returns the first point of the line.
+func (l *GeodesicLine) Start() LatLng {
+	return LatLng{l.lat1, l.lon1}
+}
+
+// Azimuth returns the azimuth of the line at its first point, in
+// degrees in the range [0, 360).
+func (l *GeodesicLine) Azimuth() float64 {
+	return normalizeBearing(l.azi1)
+}
+
+// Distance returns the distance in meters from the first point to the
+// last point of a line created by InverseLine, or NaN for a line created
+// by Line.
+func (l *GeodesicLine) Distance() float64 {
+	return l.distance
+}
+
+// Position returns the point at the given distance in meters along the
+// line from its first point, and the azimuth of the line there, in
+// degrees in the range [0, 360). The distance may be negative.
+func (l *GeodesicLine) Position(distance float64) (p LatLng, azi float64) {
+	g := l.g
+	tau12 := distance / (g.b * (1 + l.a1m1))
+	s, c := math.Sincos(tau12)
+	b12 := -sinCosSeries(true, l.stau1*c+l.ctau1*s, l.ctau1*c-l.stau1*s, l.c1pa[:])
+	sig12 := tau12 - (b12 - l.b11)
+	ssig12, csig12 := math.Sincos(sig12)
+	if math.Abs(g.f) > 0.01 {
+		// The series inversion is not accurate enough for large
+		// flattenings, so take a step of Newton's method.
+		ssig2 := l.ssig1*csig12 + l.csig1*ssig12
+		csig2 := l.csig1*csig12 - l.ssig1*ssig12
+		b12 = sinCosSeries(true, ssig2, csig2, l.c1a[:])
+		serr := (1+l.a1m1)*(sig12+(b12-l.b11)) - distance/g.b
+		sig12 -= serr / math.Sqrt(1+l.k2*ssig2*ssig2)
+		ssig12, csig12 = math.Sincos(sig12)
+	}
+	ssig2 := l.ssig1*csig12 + l.csig1*ssig12
+	csig2 := l.csig1*csig12 - l.ssig1*ssig12
+	sbet2 := l.calp0 * ssig2
+	cbet2 := math.Hypot(l.salp0, l.calp0*csig2)
+	if cbet2 == 0 {
+		cbet2, csig2 = tiny, tiny // The line reaches a pole
+	}
+	salp2, calp2 := l.salp0, l.calp0*csig2
+	somg2, comg2 := l.salp0*ssig2, csig2
+	e := math.Copysign(1, l.salp0)
+	omg12 := e * (sig12 - (math.Atan2(ssig2, csig2) - math.Atan2(l.ssig1, l.csig1)) +
+		(math.Atan2(e*somg2, comg2) - math.Atan2(e*l.somg1, l.comg1)))
+	lam12 := omg12 + l.a3c*(sig12+(sinCosSeries(true, ssig2, csig2, l.c3a[:])-l.b31))
+	lon2 := l.lon1 + lam12/degToRad
+	lat2 := atan2d(sbet2, g.f1*cbet2)
+	return LatLng{lat2, wrapLng(lon2)}, normalizeBearing(atan2d(salp2, calp2))
+}
+
+// Sample returns an iterator over points along the line spaced the given
+// distance in meters apart, starting from its first point, yielding the
+// distance of each point along the line together with the point. For a
+// line created by InverseLine the last point yielded is the end of the
+// line, which may be closer than spacing to the point before it. For a
+// line created by Line the sequence has no end. The spacing must be
+// positive.
+func (l *GeodesicLine) Sample(spacing float64) iter.Seq2[float64, LatLng] {
+	return func(yield func(float64, LatLng) bool) {
+		for k := 0; ; k++ {
+			s := float64(k) * spacing
+			if s >= l.distance {
+				p, _ := l.Position(l.distance)
+				yield(l.distance, p)
+				return
+			}
+			p, _ := l.Position(s)
+			if !yield(s, p) {
+				return
+			}
+		}
+	}
+}
+
+// a3coeff computes the coefficients of the polynomials in n of the
+// series for A3.
+func (g *Geodesic) a3coeff() {
+	n := g.n
+	g.a3x = [nA3]float64{
+		1,
+		(n - 1) / 2,
+		(n*(3*n-1) - 2) / 8,
+		(-n*(n+3) - 1) / 16,
+		(-2*n - 3) / 64,
+		-3.0 / 128,
+	}
+}
+
+// a3f returns A3 for the given eps.
+func (g *Geodesic) a3f(eps float64) float64 {
+	var y float64
+	for k := nA3 - 1; k >= 0; k-- {
+		y = y*eps + g.a3x[k]
+	}
+	return y
+}
+
+// c3coeff computes the coefficients of the polynomials in n of the
+// series for C3, stored by order in eps: c3x[0:5] are the coefficients
+// of eps for C3_1 to C3_5, and so on.
+func (g *Geodesic) c3coeff() {
+	n := g.n
+	g.c3x = [nC3x]float64{
+		// C3_1 for eps^1 to eps^5
+		(1 - n) / 4,
+		(1 - n*n) / 8,
+		(n*(3-n) + 3) / 64,
+		(2*n + 5) / 128,
+		3.0 / 128,
+		// C3_2 for eps^2 to eps^5
+		(n*(n-3) + 2) / 32,
+		(n*(-3*n-2) + 3) / 64,
+		(n + 3) / 128,
+		5.0 / 256,
+		// C3_3 for eps^3 to eps^5
+		(n*(5*n-9) + 5) / 192,
+		(9 - 10*n) / 384,
+		7.0 / 512,
+		// C3_4 for eps^4 and eps^5
+		(7 - 14*n) / 512,
+		7.0 / 512,
+		// C3_5 for eps^5
+		21.0 / 2560,
+	}
+}
+
+// c3f stores the coefficients C3_l(eps) in c[1:].
+func (g *Geodesic) c3f(eps float64, c *[nC3]float64) {
+	o := 0
+	mult := 1.0
+	for l := 1; l < nC3; l++ {
+		mult *= eps
+		m := nC3 - l // Number of terms, for eps^l to eps^(nC3-1)
+		var y float64
+		for k := m - 1; k >= 0; k-- {
+			y = y*eps + g.c3x[o+k]
+		}
+		c[l] = mult * y
+		o += m
+	}
+}
+
+// c4coeff computes the coefficients of the polynomials in n of the
+// series for C4, which gives the area between a geodesic and the
+// equator.
+func (g *Geodesic) c4coeff() {
+	coeff := [...]float64{
+		97, 15015,
+		1088, 156, 45045,
+		-224, -4784, 1573, 45045,
+		-10656, 14144, -4576, -858, 45045,
+		64, 624, -4576, 6864, -3003, 15015,
+		100, 208, 572, 3432, -12012, 30030, 45045,
+		1, 9009,
+		-2944, 468, 135135,
+		5792, 1040, -1287, 135135,
+		5952, -11648, 9152, -2574, 135135,
+		-64, -624, 4576, -6864, 3003, 135135,
+		8, 10725,
+		1856, -936, 225225,
+		-8448, 4992, -1144, 225225,
+		-1440, 4160, -4576, 1716, 225225,
+		-136, 63063,
+		1024, -208, 105105,
+		3584, -3328, 1144, 315315,
+		-128, 135135,
+		-2560, 832, 405405,
+		128, 99099,
+	}
+	o, k := 0, 0
+	for l := range nC4 {
+		for j := nC4 - 1; j >= l; j-- {
+			m := nC4 - j - 1
+			g.c4x[k] = polyval(coeff[o:o+m+1], g.n) / coeff[o+m+1]
+			o += m + 2
+			k++
+		}
+	}
+}
+
+// c4f stores the coefficients C4_l(eps) in c.
+func (g *Geodesic) c4f(eps float64, c *[nC4]float64) {
+	o := 0
+	mult := 1.0
+	for l := range nC4 {
+		m := nC4 - l - 1
+		c[l] = mult * polyval(g.c4x[o:o+m+1], eps)
+		o += m + 1
+		mult *= eps
+	}
+}
+
+// polyval evaluates the polynomial with the coefficients p, highest
+// degree first, at x.
+func polyval(p []float64, x float64) float64 {
+	var y float64
+	for _, c := range p {
+		y = y*x + c
+	}
+	return y
+}
+
+// a1m1f returns A1-1 for the given eps.
+func a1m1f(eps float64) float64 {
+	e2 := eps * eps
+	t := e2 * (e2*(e2+4) + 64) / 256
+	return (t + eps) / (1 - eps)
+}
+
+// c1f stores the coefficients C1_l(eps) in c[1:].
+func c1f(eps float64, c *[nC1 + 1]float64) {
+	e2 := eps * eps
+	d := eps
+	c[1] = d * (e2*(6-e2) - 16) / 32
+	d *= eps
+	c[2] = d * (e2*(64-9*e2) - 128) / 2048
+	d *= eps
+	c[3] = d * (9*e2 - 16) / 768
+	d *= eps
+	c[4] = d * (3*e2 - 5) / 512
+	d *= eps
+	c[5] = d * -7 / 1280
+	d *= eps
+	c[6] = d * -7 / 2048
+}
+
+// c1pf stores the coefficients C1'_l(eps) of the inverse series in
+// c[1:].
+func c1pf(eps float64, c *[nC1p + 1]float64) {
+	e2 := eps * eps
+	d := eps
+	c[1] = d * (e2*(205*e2-432) + 768) / 1536
+	d *= eps
+	c[2] = d * (e2*(4005*e2-4736) + 3840) / 12288
+	d *= eps
+	c[3] = d * (116 - 225*e2) / 384
+	d *= eps
+	c[4] = d * (2695 - 7173*e2) / 7680
+	d *= eps
+	c[5] = d * 3467 / 7680
+	d *= eps
+	c[6] = d * 38081 / 61440
+}
+
+// a2m1f returns A2-1 for the given eps.
+func a2m1f(eps float64) float64 {
+	e2 := eps * eps
+	t := e2 * (e2*(-11*e2-28) - 192) / 256
+	return (t - eps) / (1 + eps)
+}
+
+// c2f stores the coefficients C2_l(eps) in c[1:].
+func c2f(eps float64, c *[nC2 + 1]float64) {
+	e2 := eps * eps
+	d := eps
+	c[1] = d * (e2*(e2+2) + 16) / 32
+	d *= eps
+	c[2] = d * (e2*(35*e2+64) + 384) / 2048
+	d *= eps
+	c[3] = d * (15*e2 + 80) / 768
+	d *= eps
+	c[4] = d * (7*e2 + 35) / 512
+	d *= eps
+	c[5] = d * 63 / 1280
+	d *= eps
+	c[6] = d * 77 / 2048
+}
+
+// sinCosSeries evaluates the sum of c[l] sin(2lx) for l from 1, if sinp
+// is true, or of c[l] cos((2l+1)x) for l from 0 otherwise, by Clenshaw
+// summation, given sinx and cosx.
+func sinCosSeries(sinp bool, sinx, cosx float64, c []float64) float64 {
+	k := len(c)
+	n := k
+	if sinp {
+		n--
+	}
+	ar := 2 * (cosx - sinx) * (cosx + sinx)
+	var y0, y1 float64
+	if n&1 != 0 {
+		k--
+		y0 = c[k]
+	}
+	for n /= 2; n > 0; n-- {
+		k--
+		y1 = ar*y0 - y1 + c[k]
+		k--
+		y0 = ar*y1 - y0 + c[k]
+	}
+	if sinp {
+		return 2 * sinx * cosx * y0
+	}
+	return cosx * (y0 - y1)
+}
+
+// norm returns (x, y) scaled to unit length.
+func norm(x, y float64) (float64, float64) {
+	r := math.Hypot(x, y)
+	return x / r, y / r
+}
+
+// sumErr returns the sum of u and v and the rounding error in the sum.
+func sumErr(u, v float64) (s, t float64) {
+	s = u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	t = -(up + vpp)
+	if s == 0 {
+		t = s
+	}
+	return
+}
+
+// angNormalize reduces an angle in degrees to the range (-180, 180].
+func angNormalize(x float64) float64 {
+	y := math.Remainder(x, 360)
+	if y == -180 {
+		return 180
+	}
+	return y
+}
+
+// angDiff returns the exact difference lon2-lon1 of two angles in
+// degrees, reduced to the range [-180, 180], as a sum d+t of the
+// rounded difference and its rounding error.
+func angDiff(x, y float64) (d, t float64) {
+	d, t = sumErr(math.Remainder(-x, 360), math.Remainder(y, 360))
+	d, t = sumErr(math.Remainder(d, 360), t)
+	if d == 0 || math.Abs(d) == 180 {
+		if t == 0 {
+			d = math.Copysign(d, y-x)
+		} else {
+			d = math.Copysign(d, -t)
+		}
+	}
+	return
+}
+
+// angRound rounds tiny angles so that values within about 1/16 of a
+// degree of zero are represented with fewer significant bits, which
+// avoids problems with underflow in the inverse problem.
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+	y := math.Abs(x)
+	if w := z - y; w > 0 {
+		y = z - w
+	}
+	return math.Copysign(y, x)
+}
+
+// latFix returns NaN for latitudes beyond the poles.
+func latFix(lat float64) float64 {
+	if math.Abs(lat) > 90 {
+		return math.NaN()
+	}
+	return lat
+}
+
+// sincosd returns the sine and cosine of an angle in degrees, reducing
+// the angle exactly to the first octant first so that, for example, the
+// sine of 30 degrees is exactly one half.
+func sincosd(x float64) (s, c float64) {
+	r := math.Mod(x, 360)
+	q := 0
+	if !math.IsNaN(r) {
+		q = int(math.RoundToEven(r / 90))
+	}
+	r -= float64(90 * q)
+	s, c = math.Sincos(r * degToRad)
+	switch uint(q) % 4 {
+	case 1:
+		s, c = c, -s
+	case 2:
+		s, c = -s, -c
+	case 3:
+		s, c = -c, s
+	}
+	c += 0
+	if x == 0 {
+		s = x
+	}
+	return
+}
+
+// atan2d returns atan2(y, x) in degrees, in the range (-180, 180],
+// computed so that exact results are exact.
+func atan2d(y, x float64) float64 {
+	q := 0
+	if math.Abs(y) > math.Abs(x) {
+		x, y = y, x
+		q = 2
+	}
+	if x < 0 {
+		x = -x
+		q++
+	}
+	ang := math.Atan2(y, x) / degToRad
+	switch q {
+	case 1:
+		if y >= 0 {
+			ang = 180 - ang
+		} else {
+			ang = -180 - ang
+		}
+	case 2:
+		ang = 90 - ang
+	case 3:
+		ang = -90 + ang
+	}
+	return ang
+}
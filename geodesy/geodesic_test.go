@@ -0,0 +1,127 @@
+package geodesy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGeodesicInverse(t *testing.T) {
+	// The expected values are those of GeographicLib.
+	tests := []struct {
+		from, to         LatLng
+		dist, azi1, azi2 float64
+	}{
+		{LatLng{40.6, -73.8}, LatLng{51.6, -0.5}, 5551759.400319, 51.198882845579, 107.821776735514},
+		{LatLng{-41.32, 174.81}, LatLng{40.96, -5.50}, 19959679.267353, 161.067669986160, 18.825203391847},
+		{flindersPeak, buninyong, flindersDistance, flindersAzi1, flindersAzi2},
+		// Pole to pole along a meridian, and antipodal points on the
+		// equator, for which the shortest path also runs over a pole.
+		{LatLng{90, 0}, LatLng{-90, 0}, 20003931.458625, 180, 180},
+		{LatLng{0, 0}, LatLng{0, 180}, 20003931.458625, 0, 180},
+		// As in GeographicLib, coincident points on the equator are
+		// joined by a meridian heading south.
+		{LatLng{0, 0}, LatLng{0, 0}, 0, 180, 180},
+	}
+	for _, tt := range tests {
+		d, a1, a2 := WGS84Geodesic.Inverse(tt.from, tt.to)
+		if math.Abs(d-tt.dist) > 1e-3 || math.Abs(a1-tt.azi1) > 1e-5 || math.Abs(a2-tt.azi2) > 1e-5 {
+			t.Errorf("Inverse(%v, %v) = (%v, %v, %v), want (%v, %v, %v)", tt.from, tt.to, d, a1, a2, tt.dist, tt.azi1, tt.azi2)
+		}
+	}
+}
+
+func TestGeodesicDirect(t *testing.T) {
+	end, azi2 := WGS84Geodesic.Direct(LatLng{40.6, -73.8}, 45, 10000e3)
+	if math.Abs(end.Lat-32.642844328) > 1e-9 || math.Abs(end.Lng-49.011039583) > 1e-9 || math.Abs(azi2-140.366230465) > 1e-9 {
+		t.Errorf("Direct(JFK, 45, 10000 km) = (%v, %v), want ((32.642844328, 49.011039583), 140.366230465)", end, azi2)
+	}
+	end, azi2 = WGS84Geodesic.Direct(flindersPeak, flindersAzi1, flindersDistance)
+	if math.Abs(end.Lat-buninyong.Lat) > 1e-8 || math.Abs(end.Lng-buninyong.Lng) > 1e-8 || math.Abs(azi2-flindersAzi2) > 1e-5 {
+		t.Errorf("Direct(Flinders Peak) = (%v, %v), want (%v, %v)", end, azi2, buninyong, flindersAzi2)
+	}
+	// Going all the way around a meridian returns to the start.
+	end, azi2 = WGS84Geodesic.Direct(LatLng{0, 10}, 0, 4*20003931.458625/2)
+	if math.Abs(end.Lat) > 1e-9 || math.Abs(end.Lng-10) > 1e-9 || azi2 != 0 {
+		t.Errorf("Direct around a meridian = (%v, %v)", end, azi2)
+	}
+}
+
+func TestGeodesicRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(39))
+	for range 20000 {
+		a, b := randomLatLng(r), randomLatLng(r)
+		if r.Intn(10) == 0 {
+			// Nearly antipodal points, where Vincenty's formulae fail.
+			b = LatLng{-a.Lat + r.Float64() - 0.5, a.Lng + 180 + r.Float64() - 0.5}
+		}
+		d, azi1, azi2 := WGS84Geodesic.Inverse(a, b)
+		if vd, _, _, err := VincentyInverse(a, b); err == nil && math.Abs(vd-d) > 1e-3 {
+			t.Fatalf("Inverse(%v, %v) = %v, but Vincenty gives %v", a, b, d, vd)
+		}
+		end, endAzi := WGS84Geodesic.Direct(a, azi1, d)
+		if e := Haversine(end, b); e > 1e-6 {
+			t.Fatalf("Direct(%v, %v, %v) = %v, %v m from %v", a, azi1, d, end, e, b)
+		}
+		if math.Abs(b.Lat) < 89.9 && math.Abs(math.Remainder(endAzi-azi2, 360)) > 1e-6 {
+			t.Fatalf("Direct(%v, %v, %v) azimuth = %v, want %v", a, azi1, d, endAzi, azi2)
+		}
+		if back, _, _ := WGS84Geodesic.Inverse(b, a); math.Abs(back-d) > 1e-6 {
+			t.Fatalf("Inverse(%v, %v) = %v, but %v the other way", a, b, d, back)
+		}
+	}
+}
+
+func TestGeodesicLine(t *testing.T) {
+	from, to := LatLng{40.64, -73.78}, LatLng{1.36, 103.99}
+	l := WGS84Geodesic.InverseLine(from, to)
+	d, azi1, _ := WGS84Geodesic.Inverse(from, to)
+	if l.Distance() != d || math.Abs(l.Azimuth()-azi1) > 1e-12 || l.Start() != from {
+		t.Errorf("InverseLine = distance %v, azimuth %v, start %v, want %v, %v, %v", l.Distance(), l.Azimuth(), l.Start(), d, azi1, from)
+	}
+	if p, _ := l.Position(l.Distance()); Haversine(p, to) > 1e-6 {
+		t.Errorf("Position(Distance()) = %v, want %v", p, to)
+	}
+	n, last := 0, 0.0
+	for s, p := range l.Sample(1e6) {
+		if q, _ := WGS84Geodesic.Direct(from, azi1, s); Haversine(p, q) > 1e-6 {
+			t.Errorf("Sample yielded %v at %v, want %v", p, s, q)
+		}
+		n++
+		last = s
+	}
+	if want := int(math.Ceil(d/1e6)) + 1; n != want || last != d {
+		t.Errorf("Sample(1000 km) yielded %d points ending at %v, want %d ending at %v", n, last, want, d)
+	}
+	if !math.IsNaN(WGS84Geodesic.Line(from, 10).Distance()) {
+		t.Error("Line(...).Distance() is not NaN")
+	}
+	n = 0
+	for range WGS84Geodesic.Line(from, 10).Sample(1e7) {
+		if n++; n == 100 {
+			break
+		}
+	}
+	if n != 100 {
+		t.Errorf("Line(...).Sample ended after %d points", n)
+	}
+}
+
+func TestGeodesicSphere(t *testing.T) {
+	// On a sphere, geodesics are great circles.
+	g := NewGeodesic(Ellipsoid{A: MeanRadius})
+	r := rand.New(rand.NewSource(39))
+	for range 1000 {
+		a, b := randomLatLng(r), randomLatLng(r)
+		d, azi1, _ := g.Inverse(a, b)
+		if h := Haversine(a, b); math.Abs(d-h) > 1e-6 {
+			t.Fatalf("Inverse(%v, %v) on a sphere = %v, want %v", a, b, d, h)
+		}
+		if ib := InitialBearing(a, b); math.Abs(math.Remainder(azi1-ib, 360)) > 1e-6 {
+			t.Fatalf("Inverse(%v, %v) on a sphere azimuth = %v, want %v", a, b, azi1, ib)
+		}
+	}
+	if e := g.Ellipsoid(); e.A != MeanRadius || e.F != 0 {
+		t.Errorf("Ellipsoid() = %+v", e)
+	}
+}
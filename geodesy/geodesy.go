@@ -0,0 +1,50 @@
+// Package geodesy measures the Earth: distances, bearings and positions
+// on the sphere and on the ellipsoid.
+//
+// Positions are given as LatLng values holding a latitude and longitude
+// in degrees. Distances are in meters unless stated otherwise, and
+// bearings are in degrees clockwise from true north.
+//
+// Spherical computations are faster and simpler, but treat the Earth as
+// a sphere and so are in error by up to about 0.5%. They are methods of
+// Sphere, and the package-level functions of the same names compute on
+// the Sphere Earth. Computations on an Ellipsoid, such as the Vincenty
+// formulae, are accurate to a millimeter or better, and those of
+// Geodesic, which uses Karney's algorithms, are accurate to round-off and
+// work for all pairs of points. The package-level functions for the
+// ellipsoid, such as VincentyInverse and ToECEF, compute on WGS84, and
+// the other built in ellipsoids, such as Airy1830 and Clarke1866, may be
+// found by name with LookupEllipsoid.
+package geodesy
+
+import (
+	"fmt"
+	"math"
+)
+
+// LatLng is a position on the Earth given by its latitude and
+// longitude in degrees.
+type LatLng struct {
+	Lat, Lng float64
+}
+
+// String returns the position in the form "(lat, lng)".
+func (p LatLng) String() string {
+	return fmt.Sprintf("(%g, %g)", p.Lat, p.Lng)
+}
+
+// radians returns the latitude and longitude of p in radians.
+func (p LatLng) radians() (phi, lambda float64) {
+	return p.Lat * degToRad, p.Lng * degToRad
+}
+
+const degToRad = math.Pi / 180
+
+// wrapLng wraps a longitude in degrees into the range [-180, 180).
+func wrapLng(lng float64) float64 {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return lng - 180
+}
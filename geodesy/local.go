@@ -0,0 +1,135 @@
+package geodesy
+
+// ENU is a position in a local East-North-Up frame, in meters east and
+// north of the origin of the frame in its tangent plane and up along its
+// normal.
+type ENU struct {
+	East, North, Up float64
+}
+
+// NED returns the same position in the North-East-Down convention.
+func (e ENU) NED() NED {
+	return NED{e.North, e.East, -e.Up}
+}
+
+// NED is a position in a local North-East-Down frame, the convention
+// used in aviation and for vehicle dynamics, in meters north and east of
+// the origin of the frame in its tangent plane and down along its
+// normal.
+type NED struct {
+	North, East, Down float64
+}
+
+// ENU returns the same position in the East-North-Up convention.
+func (n NED) ENU() ENU {
+	return ENU{n.East, n.North, -n.Down}
+}
+
+// LocalFrame is a local tangent plane coordinate system, anchored at an
+// origin on or above an ellipsoid, whose axes point east, north
+// and up along the normal to the ellipsoid at the origin. Conversions
+// between the frame and ECEF coordinates are exact rotations and
+// translations, so positions far from the origin are converted
+// correctly, though the up axis then no longer points up at them.
+type LocalFrame struct {
+	e      Ellipsoid
+	origin LatLng
+	height float64
+	o      ECEF
+	// r holds the unit vectors of the east, north and up axes in ECEF
+	// coordinates, as the rows of the rotation from ECEF to ENU.
+	r [3][3]float64
+}
+
+// NewLocalFrame returns the local frame anchored at the given position
+// and height in meters above the WGS84 ellipsoid.
+func NewLocalFrame(origin LatLng, height float64) LocalFrame {
+	return WGS84.LocalFrame(origin, height)
+}
+
+// LocalFrame returns the local frame anchored at the given position and
+// height in meters above the ellipsoid.
+func (e Ellipsoid) LocalFrame(origin LatLng, height float64) LocalFrame {
+	sinPhi, cosPhi := sincosd(origin.Lat)
+	sinLambda, cosLambda := sincosd(origin.Lng)
+	return LocalFrame{
+		e:      e,
+		origin: origin,
+		height: height,
+		o:      e.ToECEF(origin, height),
+		r: [3][3]float64{
+			{-sinLambda, cosLambda, 0},
+			{-sinPhi * cosLambda, -sinPhi * sinLambda, cosPhi},
+			{cosPhi * cosLambda, cosPhi * sinLambda, sinPhi},
+		},
+	}
+}
+
+// Ellipsoid returns the ellipsoid of the frame.
+func (f LocalFrame) Ellipsoid() Ellipsoid {
+	return f.e
+}
+
+// Origin returns the position and height of the origin of the frame.
+func (f LocalFrame) Origin() (LatLng, float64) {
+	return f.origin, f.height
+}
+
+// ECEFToENU returns the coordinates in the frame of a point given by its
+// ECEF coordinates.
+//
+// The complementary method ENUToECEF performs the inverse mapping.
+func (f LocalFrame) ECEFToENU(c ECEF) ENU {
+	dx, dy, dz := c.X-f.o.X, c.Y-f.o.Y, c.Z-f.o.Z
+	return ENU{
+		f.r[0][0]*dx + f.r[0][1]*dy + f.r[0][2]*dz,
+		f.r[1][0]*dx + f.r[1][1]*dy + f.r[1][2]*dz,
+		f.r[2][0]*dx + f.r[2][1]*dy + f.r[2][2]*dz,
+	}
+}
+
+// ENUToECEF returns the ECEF coordinates of a point given by its
+// coordinates in the frame.
+//
+// The complementary method ECEFToENU performs the inverse mapping.
+func (f LocalFrame) ENUToECEF(e ENU) ECEF {
+	return ECEF{
+		f.o.X + f.r[0][0]*e.East + f.r[1][0]*e.North + f.r[2][0]*e.Up,
+		f.o.Y + f.r[0][1]*e.East + f.r[1][1]*e.North + f.r[2][1]*e.Up,
+		f.o.Z + f.r[0][2]*e.East + f.r[1][2]*e.North + f.r[2][2]*e.Up,
+	}
+}
+
+// ToENU returns the coordinates in the frame of the point at the given
+// position and height above the ellipsoid of the frame.
+//
+// The complementary method FromENU performs the inverse mapping.
+func (f LocalFrame) ToENU(p LatLng, height float64) ENU {
+	return f.ECEFToENU(f.e.ToECEF(p, height))
+}
+
+// FromENU returns the position and height above the ellipsoid of the
+// frame of a point given by its coordinates in the frame.
+//
+// The complementary method ToENU performs the inverse mapping.
+func (f LocalFrame) FromENU(e ENU) (LatLng, float64) {
+	return f.e.FromECEF(f.ENUToECEF(e))
+}
+
+// ToNED returns the coordinates in the North-East-Down convention of the
+// point at the given position and height above the ellipsoid of the
+// frame.
+//
+// The complementary method FromNED performs the inverse mapping.
+func (f LocalFrame) ToNED(p LatLng, height float64) NED {
+	return f.ToENU(p, height).NED()
+}
+
+// FromNED returns the position and height above the ellipsoid of the
+// frame of a point given by its coordinates in the North-East-Down
+// convention.
+//
+// The complementary method ToNED performs the inverse mapping.
+func (f LocalFrame) FromNED(n NED) (LatLng, float64) {
+	return f.FromENU(n.ENU())
+}
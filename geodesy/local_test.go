@@ -0,0 +1,85 @@
+package geodesy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLocalFrame(t *testing.T) {
+	// MATLAB's geodetic2enu example: the Matterhorn seen from Zermatt is
+	// 7134.8 m east, 4556.3 m north and 2852.4 m up.
+	f := NewLocalFrame(LatLng{46.017, 7.750}, 1673)
+	matterhorn := LatLng{45.976, 7.658}
+	want := ENU{-7134.757, -4556.322, 2852.390}
+	e := f.ToENU(matterhorn, 4531)
+	if math.Abs(e.East-want.East) > 1e-3 || math.Abs(e.North-want.North) > 1e-3 || math.Abs(e.Up-want.Up) > 1e-3 {
+		t.Errorf("ToENU(%v, 4531) = %v, want %v", matterhorn, e, want)
+	}
+	if n := f.ToNED(matterhorn, 4531); n != (NED{e.North, e.East, -e.Up}) {
+		t.Errorf("ToNED(%v, 4531) = %v, want %v", matterhorn, n, e.NED())
+	}
+	if p, h := f.FromENU(want); math.Abs(p.Lat-matterhorn.Lat) > 1e-8 || math.Abs(p.Lng-matterhorn.Lng) > 1e-8 || math.Abs(h-4531) > 1e-3 {
+		t.Errorf("FromENU(%v) = %v, %v, want %v, 4531", want, p, h, matterhorn)
+	}
+	if p, h := f.FromNED(want.NED()); math.Abs(p.Lat-matterhorn.Lat) > 1e-8 || math.Abs(h-4531) > 1e-3 {
+		t.Errorf("FromNED(%v) = %v, %v, want %v, 4531", want.NED(), p, h, matterhorn)
+	}
+	if o, h := f.Origin(); o != (LatLng{46.017, 7.750}) || h != 1673 || f.Ellipsoid() != WGS84 {
+		t.Errorf("Origin() = %v, %v, Ellipsoid() = %v", o, h, f.Ellipsoid())
+	}
+}
+
+func TestLocalFrameAxes(t *testing.T) {
+	tests := []struct {
+		origin      LatLng
+		east, north ECEF
+		up          ECEF
+	}{
+		{LatLng{0, 0}, ECEF{0, 1, 0}, ECEF{0, 0, 1}, ECEF{1, 0, 0}},
+		{LatLng{0, 90}, ECEF{-1, 0, 0}, ECEF{0, 0, 1}, ECEF{0, 1, 0}},
+		{LatLng{90, 0}, ECEF{0, 1, 0}, ECEF{-1, 0, 0}, ECEF{0, 0, 1}},
+		{LatLng{-90, 180}, ECEF{0, -1, 0}, ECEF{-1, 0, 0}, ECEF{0, 0, -1}},
+	}
+	near := func(a, b ECEF) bool {
+		return math.Abs(a.X-b.X) < 1e-6 && math.Abs(a.Y-b.Y) < 1e-6 && math.Abs(a.Z-b.Z) < 1e-6
+	}
+	for _, tt := range tests {
+		f := NewLocalFrame(tt.origin, 0)
+		o := ToECEF(tt.origin, 0)
+		for _, a := range []struct {
+			e    ENU
+			want ECEF
+		}{{ENU{1, 0, 0}, tt.east}, {ENU{0, 1, 0}, tt.north}, {ENU{0, 0, 1}, tt.up}} {
+			c := f.ENUToECEF(a.e)
+			if d := (ECEF{c.X - o.X, c.Y - o.Y, c.Z - o.Z}); !near(d, a.want) {
+				t.Errorf("%v: ENUToECEF(%v) - origin = %v, want %v", tt.origin, a.e, d, a.want)
+			}
+		}
+		if e := f.ToENU(tt.origin, 10); math.Abs(e.East) > 1e-6 || math.Abs(e.North) > 1e-6 || math.Abs(e.Up-10) > 1e-6 {
+			t.Errorf("%v: ToENU(origin, 10) = %v, want {0 0 10}", tt.origin, e)
+		}
+	}
+}
+
+func TestLocalFrameRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(47))
+	for range 1000 {
+		f := Airy1830.LocalFrame(randomLatLng(r), r.Float64()*1000)
+		c := ECEF{r.Float64()*2e7 - 1e7, r.Float64()*2e7 - 1e7, r.Float64()*2e7 - 1e7}
+		e := f.ECEFToENU(c)
+		// The frame is a rotation, so it preserves distances from the
+		// origin.
+		o := f.ENUToECEF(ENU{})
+		d := math.Sqrt((c.X-o.X)*(c.X-o.X) + (c.Y-o.Y)*(c.Y-o.Y) + (c.Z-o.Z)*(c.Z-o.Z))
+		if n := math.Sqrt(e.East*e.East + e.North*e.North + e.Up*e.Up); math.Abs(n-d) > 1e-6 {
+			t.Fatalf("|ECEFToENU(%v)| = %v, want %v", c, n, d)
+		}
+		if c2 := f.ENUToECEF(e); math.Abs(c2.X-c.X) > 1e-6 || math.Abs(c2.Y-c.Y) > 1e-6 || math.Abs(c2.Z-c.Z) > 1e-6 {
+			t.Fatalf("ENUToECEF(ECEFToENU(%v)) = %v", c, c2)
+		}
+		if e2 := e.NED().ENU(); e2 != e {
+			t.Fatalf("%v.NED().ENU() = %v", e, e2)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package geodesy
+
+import "math"
+
+// A rhumb line, or loxodrome, is a path of constant bearing, which
+// crosses every meridian at the same angle. It is longer than the great
+// circle between the same points, but is the course a navigator steers
+// with a compass. The rhumb line is a straight line on the Mercator
+// projection, and the functions here compute with the isometric
+// latitude psi of that projection.
+
+// isometricLat returns the isometric latitude of a latitude in radians
+// on the sphere, the northing of the Mercator projection.
+func isometricLat(phi float64) float64 {
+	return math.Log(math.Tan(math.Pi/4 + phi/2))
+}
+
+// rhumbDelta returns the differences in latitude, isometric latitude
+// and longitude, in radians, from one point to another, with the
+// longitude difference reduced to the range [-pi, pi] so that the rhumb
+// line takes the shorter way around the sphere.
+func rhumbDelta(from, to LatLng) (dPhi, dPsi, dLambda float64) {
+	phi1, lambda1 := from.radians()
+	phi2, lambda2 := to.radians()
+	dPhi = phi2 - phi1
+	dPsi = isometricLat(phi2) - isometricLat(phi1)
+	dLambda = math.Remainder(lambda2-lambda1, 2*math.Pi)
+	return
+}
+
+// rhumbStretch returns the ratio of the change in latitude to the change
+// in isometric latitude along a rhumb line, which is the cosine of the
+// latitude for an east-west line.
+func rhumbStretch(dPhi, dPsi, phi float64) float64 {
+	if math.Abs(dPsi) > 1e-12 {
+		return dPhi / dPsi
+	}
+	return math.Cos(phi)
+}
+
+// RhumbDistance returns the length in meters of the rhumb line between
+// two points on the sphere.
+func (s Sphere) RhumbDistance(from, to LatLng) float64 {
+	dPhi, dPsi, dLambda := rhumbDelta(from, to)
+	q := rhumbStretch(dPhi, dPsi, from.Lat*degToRad)
+	return s.Radius * math.Hypot(dPhi, q*dLambda)
+}
+
+// RhumbBearing returns the constant bearing, in degrees in the range
+// [0, 360), of the rhumb line from one point to another. The bearing
+// does not depend on the radius of the sphere.
+func RhumbBearing(from, to LatLng) float64 {
+	_, dPsi, dLambda := rhumbDelta(from, to)
+	return normalizeBearing(math.Atan2(dLambda, dPsi) / degToRad)
+}
+
+// RhumbDestination returns the point reached by travelling the given
+// distance in meters from start along the rhumb line with the given
+// bearing, in degrees. A path which would pass over a pole is reflected
+// back from it.
+func (s Sphere) RhumbDestination(start LatLng, bearing, distance float64) LatLng {
+	phi1, lambda1 := start.radians()
+	delta := distance / s.Radius
+	sinTheta, cosTheta := math.Sincos(bearing * degToRad)
+	dPhi := delta * cosTheta
+	phi2 := phi1 + dPhi
+	if math.Abs(phi2) > math.Pi/2 {
+		phi2 = math.Copysign(math.Pi, phi2) - phi2
+	}
+	dPsi := isometricLat(phi2) - isometricLat(phi1)
+	q := rhumbStretch(dPhi, dPsi, phi1)
+	lambda2 := lambda1 + delta*sinTheta/q
+	return LatLng{phi2 / degToRad, wrapLng(lambda2 / degToRad)}
+}
+
+// RhumbMidpoint returns the point half way along the rhumb line between
+// two points. The midpoint does not depend on the radius of the sphere.
+func RhumbMidpoint(from, to LatLng) LatLng {
+	phi1, lambda1 := from.radians()
+	phi2 := to.Lat * degToRad
+	_, _, dLambda := rhumbDelta(from, to)
+	lambda2 := lambda1 + dLambda
+	phiM := (phi1 + phi2) / 2
+	f1 := math.Tan(math.Pi/4 + phi1/2)
+	f2 := math.Tan(math.Pi/4 + phi2/2)
+	fM := math.Tan(math.Pi/4 + phiM/2)
+	lambdaM := ((lambda2-lambda1)*math.Log(fM) + lambda1*math.Log(f2) - lambda2*math.Log(f1)) / math.Log(f2/f1)
+	if math.IsNaN(lambdaM) || math.IsInf(lambdaM, 0) {
+		lambdaM = (lambda1 + lambda2) / 2 // An east-west line
+	}
+	return LatLng{phiM / degToRad, wrapLng(lambdaM / degToRad)}
+}
+
+// RhumbDistance returns the length in meters of the rhumb line between
+// two points on the Sphere Earth.
+func RhumbDistance(from, to LatLng) float64 {
+	return Earth.RhumbDistance(from, to)
+}
+
+// RhumbDestination returns the point reached by travelling the given
+// distance in meters from start along the rhumb line with the given
+// bearing, in degrees, on the Sphere Earth.
+func RhumbDestination(start LatLng, bearing, distance float64) LatLng {
+	return Earth.RhumbDestination(start, bearing, distance)
+}
@@ -0,0 +1,83 @@
+package geodesy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// The example of Chris Veness's "Movable Type Scripts", from Dover to
+// Calais.
+var (
+	dover  = LatLng{dms(51, 7, 32), dms(1, 20, 17)}
+	calais = LatLng{dms(50, 57, 48), dms(1, 51, 9)}
+)
+
+func TestRhumb(t *testing.T) {
+	if d := RhumbDistance(dover, calais); math.Abs(d-40.23e3) > 10 {
+		t.Errorf("RhumbDistance(Dover, Calais) = %v, want 40.23 km", d)
+	}
+	if b := RhumbBearing(dover, calais); math.Abs(b-dms(116, 38, 10)) > 1/3600.0 {
+		t.Errorf("RhumbBearing(Dover, Calais) = %v, want %v", b, dms(116, 38, 10))
+	}
+	if p := RhumbDestination(dover, dms(116, 38, 10), 40.23e3); Haversine(p, calais) > 30 {
+		t.Errorf("RhumbDestination(Dover) = %v, want %v", p, calais)
+	}
+	if m, want := RhumbMidpoint(dover, calais), (LatLng{dms(51, 2, 40), dms(1, 35, 43)}); Haversine(m, want) > 30 {
+		t.Errorf("RhumbMidpoint(Dover, Calais) = %v, want %v", m, want)
+	}
+}
+
+func TestRhumbSpecialCases(t *testing.T) {
+	tests := []struct {
+		from, to      LatLng
+		dist, bearing float64
+		mid           LatLng
+	}{
+		// Along the equator and a meridian, rhumb lines are great
+		// circles.
+		{LatLng{0, 0}, LatLng{0, 90}, MeanRadius * math.Pi / 2, 90, LatLng{0, 45}},
+		{LatLng{0, 0}, LatLng{60, 0}, MeanRadius * math.Pi / 3, 0, LatLng{30, 0}},
+		// Along a parallel the distance shrinks with the cosine of
+		// the latitude, and the line takes the shorter way around.
+		{LatLng{60, 170}, LatLng{60, -170}, MeanRadius * math.Pi / 9 / 2, 90, LatLng{60, -180}},
+		{LatLng{10, -170}, LatLng{10, 170}, MeanRadius * math.Pi / 9 * math.Cos(10*degToRad), 270, LatLng{10, -180}},
+	}
+	for _, tt := range tests {
+		if d := RhumbDistance(tt.from, tt.to); math.Abs(d-tt.dist) > 1e-6 {
+			t.Errorf("RhumbDistance(%v, %v) = %v, want %v", tt.from, tt.to, d, tt.dist)
+		}
+		if b := RhumbBearing(tt.from, tt.to); math.Abs(b-tt.bearing) > 1e-9 {
+			t.Errorf("RhumbBearing(%v, %v) = %v, want %v", tt.from, tt.to, b, tt.bearing)
+		}
+		if m := RhumbMidpoint(tt.from, tt.to); math.Abs(m.Lat-tt.mid.Lat) > 1e-9 || math.Abs(math.Remainder(m.Lng-tt.mid.Lng, 360)) > 1e-9 {
+			t.Errorf("RhumbMidpoint(%v, %v) = %v, want %v", tt.from, tt.to, m, tt.mid)
+		}
+	}
+	// A path over the pole is reflected back from it.
+	if p := RhumbDestination(LatLng{80, 0}, 0, MeanRadius*12*degToRad); math.Abs(p.Lat-88) > 1e-9 {
+		t.Errorf("RhumbDestination over the pole = %v, want latitude 88", p)
+	}
+}
+
+func TestRhumbRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for range 10000 {
+		a := LatLng{r.Float64()*170 - 85, r.Float64()*360 - 180}
+		b := LatLng{r.Float64()*170 - 85, r.Float64()*360 - 180}
+		if r.Intn(10) == 0 {
+			b.Lat = a.Lat
+		}
+		d, bearing := RhumbDistance(a, b), RhumbBearing(a, b)
+		if p := RhumbDestination(a, bearing, d); Haversine(p, b) > 1e-3 {
+			t.Fatalf("RhumbDestination(%v, %v, %v) = %v, want %v", a, bearing, d, p, b)
+		}
+		if m, h := RhumbMidpoint(a, b), RhumbDestination(a, bearing, d/2); Haversine(m, h) > 1e-3 {
+			t.Fatalf("RhumbMidpoint(%v, %v) = %v, want %v", a, b, m, h)
+		}
+		// The rhumb line is never shorter than the great circle.
+		if g := Haversine(a, b); d < g-1e-6 {
+			t.Fatalf("RhumbDistance(%v, %v) = %v, shorter than the great circle %v", a, b, d, g)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package geodesy
+
+import "math"
+
+// MeanRadius is the mean radius of the Earth in meters, the radius of
+// the sphere R1 = (2a+b)/3 of the WGS84 ellipsoid, as recommended by
+// the International Union of Geodesy and Geophysics.
+const MeanRadius = 6371008.8
+
+// Sphere is a sphere with the given radius in meters, on which distances
+// are computed along great circles.
+type Sphere struct {
+	Radius float64
+}
+
+// Earth is the sphere with the mean radius of the Earth.
+var Earth = Sphere{MeanRadius}
+
+// Haversine returns the great circle distance between two points on the
+// sphere, computed with the haversine formula, which is accurate for
+// all distances except those between nearly antipodal points, where it
+// loses a few digits of precision.
+func (s Sphere) Haversine(a, b LatLng) float64 {
+	return s.Radius * haversineAngle(a, b)
+}
+
+// LawOfCosines returns the great circle distance between two points on
+// the sphere, computed with the spherical law of cosines. It is slightly
+// faster than Haversine and accurate for distances of more than a few
+// meters, but for nearby points the result is dominated by rounding
+// error.
+func (s Sphere) LawOfCosines(a, b LatLng) float64 {
+	phi1, lambda1 := a.radians()
+	phi2, lambda2 := b.radians()
+	c := math.Sin(phi1)*math.Sin(phi2) + math.Cos(phi1)*math.Cos(phi2)*math.Cos(lambda2-lambda1)
+	return s.Radius * math.Acos(max(-1, min(1, c)))
+}
+
+// Destination returns the point reached by travelling the given
+// distance in meters along the great circle leaving start at the given
+// bearing, in degrees. The distance may be negative, to travel in the
+// opposite direction, or longer than half the circumference of the
+// sphere, in which case the path continues around the sphere.
+func (s Sphere) Destination(start LatLng, bearing, distance float64) LatLng {
+	phi1, lambda1 := start.radians()
+	delta := distance / s.Radius
+	sinPhi1, cosPhi1 := math.Sincos(phi1)
+	sinDelta, cosDelta := math.Sincos(delta)
+	sinTheta, cosTheta := math.Sincos(bearing * degToRad)
+	sinPhi2 := sinPhi1*cosDelta + cosPhi1*sinDelta*cosTheta
+	phi2 := math.Asin(max(-1, min(1, sinPhi2)))
+	lambda2 := lambda1 + math.Atan2(sinTheta*sinDelta*cosPhi1, cosDelta-sinPhi1*sinPhi2)
+	return LatLng{phi2 / degToRad, wrapLng(lambda2 / degToRad)}
+}
+
+// CrossTrackDistance returns the distance in meters of a point from the
+// great circle through start and end, positive if the point lies to the
+// right of the path from start towards end and negative if it lies to
+// the left.
+func (s Sphere) CrossTrackDistance(p, start, end LatLng) float64 {
+	return s.Radius * crossTrackAngle(p, start, end)
+}
+
+// AlongTrackDistance returns the distance in meters from start, along
+// the great circle through start and end, to the point on it closest to
+// p. It is negative if that point lies behind start.
+func (s Sphere) AlongTrackDistance(p, start, end LatLng) float64 {
+	delta13 := haversineAngle(start, p)
+	deltaXT := crossTrackAngle(p, start, end)
+	dat := math.Acos(max(-1, min(1, math.Cos(delta13)/math.Cos(deltaXT))))
+	theta12 := InitialBearing(start, end) * degToRad
+	theta13 := InitialBearing(start, p) * degToRad
+	return math.Copysign(s.Radius*dat, math.Cos(theta12-theta13))
+}
+
+// crossTrackAngle returns the angular distance in radians of p from the
+// great circle through start and end.
+func crossTrackAngle(p, start, end LatLng) float64 {
+	delta13 := haversineAngle(start, p)
+	theta12 := InitialBearing(start, end) * degToRad
+	theta13 := InitialBearing(start, p) * degToRad
+	return math.Asin(max(-1, min(1, math.Sin(delta13)*math.Sin(theta13-theta12))))
+}
+
+// SamplePath returns points along the great circle from one point to
+// another, spaced evenly and no more than maxSegment meters apart,
+// starting with from and ending with to. Drawing straight lines between
+// the points in a map projection then follows the great circle closely,
+// rather than cutting the corner as a single straight line would at
+// high latitudes. The maximum segment length must be positive.
+func (s Sphere) SamplePath(from, to LatLng, maxSegment float64) []LatLng {
+	n := max(1, int(math.Ceil(s.Haversine(from, to)/maxSegment)))
+	path := make([]LatLng, n+1)
+	for k := range n {
+		path[k] = Intermediate(from, to, float64(k)/float64(n))
+	}
+	path[n] = to
+	return path
+}
+
+// haversineAngle returns the angle in radians at the center of the
+// sphere between two points.
+func haversineAngle(a, b LatLng) float64 {
+	phi1, lambda1 := a.radians()
+	phi2, lambda2 := b.radians()
+	sinPhi := math.Sin((phi2 - phi1) / 2)
+	sinLambda := math.Sin((lambda2 - lambda1) / 2)
+	h := sinPhi*sinPhi + math.Cos(phi1)*math.Cos(phi2)*sinLambda*sinLambda
+	return 2 * math.Asin(math.Sqrt(min(1, h)))
+}
+
+// Haversine returns the great circle distance between two points on the
+// Sphere Earth, computed with the haversine formula.
+func Haversine(a, b LatLng) float64 {
+	return Earth.Haversine(a, b)
+}
+
+// LawOfCosines returns the great circle distance between two points on
+// the Sphere Earth, computed with the spherical law of cosines.
+func LawOfCosines(a, b LatLng) float64 {
+	return Earth.LawOfCosines(a, b)
+}
+
+// InitialBearing returns the bearing, in degrees in the range [0, 360),
+// at which the great circle path from one point to another leaves the
+// first point. The bearing does not depend on the radius of the sphere.
+// It is 0 if the points coincide, and is not meaningful if they are
+// antipodal or from is at a pole.
+func InitialBearing(from, to LatLng) float64 {
+	phi1, lambda1 := from.radians()
+	phi2, lambda2 := to.radians()
+	sinPhi1, cosPhi1 := math.Sincos(phi1)
+	sinPhi2, cosPhi2 := math.Sincos(phi2)
+	sinLambda, cosLambda := math.Sincos(lambda2 - lambda1)
+	y := sinLambda * cosPhi2
+	x := cosPhi1*sinPhi2 - sinPhi1*cosPhi2*cosLambda
+	return normalizeBearing(math.Atan2(y, x) / degToRad)
+}
+
+// FinalBearing returns the bearing, in degrees in the range [0, 360), at
+// which the great circle path from one point to another arrives at the
+// second point. This is the reverse of the initial bearing of the path
+// back from to to from.
+func FinalBearing(from, to LatLng) float64 {
+	return normalizeBearing(InitialBearing(to, from) + 180)
+}
+
+// Destination returns the point reached by travelling the given
+// distance in meters along the great circle leaving start at the given
+// bearing, in degrees, on the Sphere Earth.
+func Destination(start LatLng, bearing, distance float64) LatLng {
+	return Earth.Destination(start, bearing, distance)
+}
+
+// CrossTrackDistance returns the distance in meters of a point from the
+// great circle through start and end on the Sphere Earth, positive to
+// the right of the path and negative to the left.
+func CrossTrackDistance(p, start, end LatLng) float64 {
+	return Earth.CrossTrackDistance(p, start, end)
+}
+
+// AlongTrackDistance returns the distance in meters from start, along
+// the great circle through start and end on the Sphere Earth, to the
+// point on it closest to p.
+func AlongTrackDistance(p, start, end LatLng) float64 {
+	return Earth.AlongTrackDistance(p, start, end)
+}
+
+// Intermediate returns the point the given fraction of the way along the
+// great circle from one point to another, so that a fraction of 0 gives
+// from and 1 gives to. The point does not depend on the radius of the
+// sphere. The great circle is not defined for antipodal points, for
+// which the result is not meaningful.
+func Intermediate(from, to LatLng, fraction float64) LatLng {
+	delta := haversineAngle(from, to)
+	if delta == 0 {
+		return from
+	}
+	phi1, lambda1 := from.radians()
+	phi2, lambda2 := to.radians()
+	sinPhi1, cosPhi1 := math.Sincos(phi1)
+	sinPhi2, cosPhi2 := math.Sincos(phi2)
+	sinLambda1, cosLambda1 := math.Sincos(lambda1)
+	sinLambda2, cosLambda2 := math.Sincos(lambda2)
+	// Interpolate spherically between the unit vectors of the points.
+	sinDelta := math.Sin(delta)
+	a := math.Sin((1-fraction)*delta) / sinDelta
+	b := math.Sin(fraction*delta) / sinDelta
+	x := a*cosPhi1*cosLambda1 + b*cosPhi2*cosLambda2
+	y := a*cosPhi1*sinLambda1 + b*cosPhi2*sinLambda2
+	z := a*sinPhi1 + b*sinPhi2
+	return LatLng{math.Atan2(z, math.Hypot(x, y)) / degToRad, math.Atan2(y, x) / degToRad}
+}
+
+// SamplePath returns points along the great circle from one point to
+// another on the Sphere Earth, spaced evenly and no more than maxSegment
+// meters apart.
+func SamplePath(from, to LatLng, maxSegment float64) []LatLng {
+	return Earth.SamplePath(from, to, maxSegment)
+}
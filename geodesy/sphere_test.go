@@ -0,0 +1,262 @@
+package geodesy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomLatLng returns a point distributed uniformly over the sphere.
+func randomLatLng(r *rand.Rand) LatLng {
+	return LatLng{math.Asin(2*r.Float64()-1) / degToRad, r.Float64()*360 - 180}
+}
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		a, b LatLng
+		want float64
+	}{
+		// Cambridge to Paris, 404.3 km on a sphere of radius 6371 km.
+		{LatLng{52.205, 0.119}, LatLng{48.857, 2.351}, 404279.16 * MeanRadius / 6371e3},
+		{LatLng{0, 0}, LatLng{0, 90}, MeanRadius * math.Pi / 2},
+		{LatLng{90, 0}, LatLng{-90, 0}, MeanRadius * math.Pi},
+		{LatLng{0, 179.5}, LatLng{0, -179.5}, MeanRadius * math.Pi / 180},
+		{LatLng{10, 20}, LatLng{10, 20}, 0},
+	}
+	for _, tt := range tests {
+		if d := Haversine(tt.a, tt.b); math.Abs(d-tt.want) > 0.01 {
+			t.Errorf("Haversine(%v, %v) = %v, want %v", tt.a, tt.b, d, tt.want)
+		}
+		if d := LawOfCosines(tt.a, tt.b); math.Abs(d-tt.want) > 0.5 {
+			t.Errorf("LawOfCosines(%v, %v) = %v, want %v", tt.a, tt.b, d, tt.want)
+		}
+	}
+	if d := (Sphere{1}).Haversine(LatLng{0, 0}, LatLng{0, 90}); math.Abs(d-math.Pi/2) > 1e-15 {
+		t.Errorf("unit Sphere Haversine = %v, want π/2", d)
+	}
+}
+
+func TestHaversineRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(37))
+	for range 10000 {
+		a, b := randomLatLng(r), randomLatLng(r)
+		d := Haversine(a, b)
+		if d2 := Haversine(b, a); math.Abs(d-d2) > 1e-6 {
+			t.Fatalf("Haversine(%v, %v) = %v, but %v the other way", a, b, d, d2)
+		}
+		if d < 0 || d > MeanRadius*math.Pi+1e-6 {
+			t.Fatalf("Haversine(%v, %v) = %v, out of range", a, b, d)
+		}
+		if d > 1000 {
+			if d2 := LawOfCosines(a, b); math.Abs(d-d2) > 1e-3 {
+				t.Fatalf("LawOfCosines(%v, %v) = %v, want %v", a, b, d2, d)
+			}
+		}
+	}
+}
+
+func TestBearing(t *testing.T) {
+	// From Baghdad to Osaka, both at 35 degrees north and 90 degrees
+	// apart, the bearing is atan(1/sin 35°) east of north, and the path
+	// arrives symmetrically.
+	baghdad, osaka := LatLng{35, 45}, LatLng{35, 135}
+	want := math.Atan2(1, math.Sin(35*degToRad)) / degToRad
+	tests := []struct {
+		from, to       LatLng
+		initial, final float64
+	}{
+		{baghdad, osaka, want, 180 - want},
+		{osaka, baghdad, 360 - want, 180 + want},
+		{LatLng{0, 0}, LatLng{10, 0}, 0, 0},
+		{LatLng{0, 0}, LatLng{-10, 0}, 180, 180},
+		{LatLng{0, 0}, LatLng{0, 10}, 90, 90},
+		{LatLng{0, 10}, LatLng{0, 0}, 270, 270},
+		{LatLng{0, 179}, LatLng{0, -179}, 90, 90},
+		{LatLng{10, 20}, LatLng{10, 20}, 0, 180},
+	}
+	for _, tt := range tests {
+		if b := InitialBearing(tt.from, tt.to); math.Abs(b-tt.initial) > 1e-9 {
+			t.Errorf("InitialBearing(%v, %v) = %v, want %v", tt.from, tt.to, b, tt.initial)
+		}
+		if b := FinalBearing(tt.from, tt.to); math.Abs(b-tt.final) > 1e-9 {
+			t.Errorf("FinalBearing(%v, %v) = %v, want %v", tt.from, tt.to, b, tt.final)
+		}
+	}
+}
+
+func TestBearingRange(t *testing.T) {
+	r := rand.New(rand.NewSource(40))
+	for range 10000 {
+		a, b := randomLatLng(r), randomLatLng(r)
+		ib, fb := InitialBearing(a, b), FinalBearing(a, b)
+		if ib < 0 || ib >= 360 || fb < 0 || fb >= 360 {
+			t.Fatalf("bearings from %v to %v = %v, %v, not in [0, 360)", a, b, ib, fb)
+		}
+		if back := InitialBearing(b, a); math.Abs(math.Remainder(fb-back-180, 360)) > 1e-9 {
+			t.Fatalf("FinalBearing(%v, %v) = %v, want the reverse of %v", a, b, fb, back)
+		}
+	}
+}
+
+func TestDestination(t *testing.T) {
+	tests := []struct {
+		start             LatLng
+		bearing, distance float64
+		want              LatLng
+	}{
+		// The example of Chris Veness's "Movable Type Scripts", to the
+		// nearest second of arc.
+		{LatLng{dms(53, 19, 14), -dms(1, 43, 47)}, dms(96, 1, 18), 124.8e3, LatLng{dms(53, 11, 18), dms(0, 8, 0)}},
+		{LatLng{0, 0}, 90, MeanRadius * math.Pi / 2, LatLng{0, 90}},
+		{LatLng{0, 0}, 0, MeanRadius * math.Pi / 4, LatLng{45, 0}},
+		{LatLng{80, 0}, 0, MeanRadius * math.Pi / 9, LatLng{80, 180}},
+		{LatLng{0, 0}, 90, -MeanRadius * math.Pi / 2, LatLng{0, -90}},
+		{LatLng{0, 170}, 90, MeanRadius * math.Pi / 9, LatLng{0, -170}},
+		{LatLng{0, 0}, 45, 3 * MeanRadius * math.Pi, LatLng{0, -180}},
+		{LatLng{10, 20}, 123, 0, LatLng{10, 20}},
+	}
+	for _, tt := range tests {
+		p := Destination(tt.start, tt.bearing, tt.distance)
+		if math.Abs(p.Lat-tt.want.Lat) > 1/3600.0 || math.Abs(math.Remainder(p.Lng-tt.want.Lng, 360)) > 1/3600.0 {
+			t.Errorf("Destination(%v, %v, %v) = %v, want %v", tt.start, tt.bearing, tt.distance, p, tt.want)
+		}
+	}
+}
+
+func TestDestinationRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(41))
+	for range 10000 {
+		a, b := randomLatLng(r), randomLatLng(r)
+		if math.Abs(a.Lat) > 89.9 {
+			continue
+		}
+		p := Destination(a, InitialBearing(a, b), Haversine(a, b))
+		if d := Haversine(p, b); d > 1e-3 {
+			t.Fatalf("Destination from %v toward %v ends %v m away", a, b, d)
+		}
+		if p.Lng < -180 || p.Lng >= 180 {
+			t.Fatalf("Destination from %v toward %v = %v, not wrapped", a, b, p)
+		}
+	}
+	s := Sphere{1}
+	if p := s.Destination(LatLng{0, 0}, 90, math.Pi/4); math.Abs(p.Lng-45) > 1e-12 {
+		t.Errorf("unit Sphere Destination = %v, want (0, 45)", p)
+	}
+}
+
+func TestTrackDistance(t *testing.T) {
+	tests := []struct {
+		p, start, end LatLng
+		cross, along  float64
+		tolerance     float64
+	}{
+		// The example of Chris Veness's "Movable Type Scripts".
+		{LatLng{53.2611, -0.7972}, LatLng{53.3206, -1.7297}, LatLng{53.1887, 0.1334}, -307.5, 62.331e3, 1},
+		// Off the equator, to the left of a path heading east, and
+		// behind its start.
+		{LatLng{1, 1}, LatLng{0, 0}, LatLng{0, 2}, -MeanRadius * degToRad, MeanRadius * degToRad, 1e-6},
+		{LatLng{-1, -1}, LatLng{0, 0}, LatLng{0, 2}, MeanRadius * degToRad, -MeanRadius * degToRad, 1e-6},
+		{LatLng{0, 1}, LatLng{0, 0}, LatLng{0, 2}, 0, MeanRadius * degToRad, 1e-6},
+	}
+	for _, tt := range tests {
+		if d := CrossTrackDistance(tt.p, tt.start, tt.end); math.Abs(d-tt.cross) > tt.tolerance {
+			t.Errorf("CrossTrackDistance(%v, %v, %v) = %v, want %v", tt.p, tt.start, tt.end, d, tt.cross)
+		}
+		if d := AlongTrackDistance(tt.p, tt.start, tt.end); math.Abs(d-tt.along) > tt.tolerance {
+			t.Errorf("AlongTrackDistance(%v, %v, %v) = %v, want %v", tt.p, tt.start, tt.end, d, tt.along)
+		}
+	}
+}
+
+func TestTrackDistanceRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(43))
+	for range 10000 {
+		start, end := randomLatLng(r), randomLatLng(r)
+		// A point reached by going along the track and then off it at a
+		// right angle is at those distances.
+		along, cross := (r.Float64()-0.5)*4e6, (r.Float64()-0.5)*2e6
+		foot := Destination(start, InitialBearing(start, end), along)
+		if math.Abs(start.Lat) > 89 || math.Abs(foot.Lat) > 89 || Haversine(start, end) < 1e3 {
+			continue
+		}
+		forward := FinalBearing(start, foot)
+		if along < 0 {
+			forward = InitialBearing(foot, start)
+		}
+		p := Destination(foot, forward+90, cross)
+		if d := CrossTrackDistance(p, start, end); math.Abs(d-cross) > 1e-3 {
+			t.Fatalf("CrossTrackDistance(%v, %v, %v) = %v, want %v", p, start, end, d, cross)
+		}
+		if d := AlongTrackDistance(p, start, end); math.Abs(d-along) > 1e-3 {
+			t.Fatalf("AlongTrackDistance(%v, %v, %v) = %v, want %v", p, start, end, d, along)
+		}
+	}
+}
+
+func TestIntermediate(t *testing.T) {
+	// The midpoint example of Chris Veness's "Movable Type Scripts",
+	// from Land's End to John o' Groats.
+	from, to := LatLng{dms(50, 3, 59), -dms(5, 42, 53)}, LatLng{dms(58, 38, 38), -dms(3, 4, 12)}
+	mid := LatLng{dms(54, 21, 44), -dms(4, 31, 50)}
+	if p := Intermediate(from, to, 0.5); Haversine(p, mid) > 30 {
+		t.Errorf("Intermediate(Land's End, John o' Groats, 0.5) = %v, want %v", p, mid)
+	}
+	tests := []struct {
+		from, to LatLng
+		fraction float64
+		want     LatLng
+	}{
+		{LatLng{0, 0}, LatLng{0, 90}, 0, LatLng{0, 0}},
+		{LatLng{0, 0}, LatLng{0, 90}, 1, LatLng{0, 90}},
+		{LatLng{0, 0}, LatLng{0, 90}, 1.0 / 3, LatLng{0, 30}},
+		{LatLng{0, 0}, LatLng{0, 90}, 2, LatLng{0, 180}},
+		{LatLng{0, 0}, LatLng{0, 90}, -0.5, LatLng{0, -45}},
+		{LatLng{0, 170}, LatLng{0, -170}, 0.5, LatLng{0, 180}},
+		{LatLng{10, 20}, LatLng{10, 20}, 0.7, LatLng{10, 20}},
+	}
+	for _, tt := range tests {
+		p := Intermediate(tt.from, tt.to, tt.fraction)
+		if math.Abs(p.Lat-tt.want.Lat) > 1e-9 || math.Abs(math.Remainder(p.Lng-tt.want.Lng, 360)) > 1e-9 {
+			t.Errorf("Intermediate(%v, %v, %v) = %v, want %v", tt.from, tt.to, tt.fraction, p, tt.want)
+		}
+	}
+}
+
+func TestIntermediateRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(44))
+	for range 10000 {
+		a, b := randomLatLng(r), randomLatLng(r)
+		if Haversine(a, b) > 0.99*MeanRadius*math.Pi {
+			continue
+		}
+		f := r.Float64()
+		p, d := Intermediate(a, b, f), Haversine(a, b)
+		if math.Abs(Haversine(a, p)-f*d) > 1e-3 || math.Abs(Haversine(p, b)-(1-f)*d) > 1e-3 {
+			t.Fatalf("Intermediate(%v, %v, %v) = %v, not on the way", a, b, f, p)
+		}
+	}
+}
+
+func TestSamplePath(t *testing.T) {
+	from, to := LatLng{40.64, -73.78}, LatLng{51.47, -0.45}
+	d := Haversine(from, to)
+	path := SamplePath(from, to, 100e3)
+	if n := int(math.Ceil(d / 100e3)); len(path) != n+1 {
+		t.Fatalf("SamplePath(JFK, LHR, 100 km) has %d points, want %d", len(path), n+1)
+	}
+	if path[0] != from || path[len(path)-1] != to {
+		t.Errorf("SamplePath(JFK, LHR, 100 km) runs from %v to %v", path[0], path[len(path)-1])
+	}
+	step := d / float64(len(path)-1)
+	for k := 1; k < len(path); k++ {
+		if s := Haversine(path[k-1], path[k]); math.Abs(s-step) > 1e-3 {
+			t.Errorf("SamplePath step %d is %v m, want %v", k, s, step)
+		}
+		if x := CrossTrackDistance(path[k], from, to); math.Abs(x) > 1e-3 {
+			t.Errorf("SamplePath point %d is %v m off the great circle", k, x)
+		}
+	}
+	if path := SamplePath(from, from, 1); len(path) != 2 || path[0] != from || path[1] != from {
+		t.Errorf("SamplePath of a single point = %v", path)
+	}
+}
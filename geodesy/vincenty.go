@@ -0,0 +1,155 @@
+package geodesy
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNoConvergence is returned by VincentyInverse when the iteration of
+// Vincenty's formulae fails to converge, which happens only for nearly
+// antipodal points.
+var ErrNoConvergence = errors.New("geodesy: Vincenty's formulae failed to converge")
+
+// vincentyMaxIter is the iteration limit for Vincenty's formulae. The
+// iteration normally converges in a handful of steps, so reaching the
+// limit means that it never will.
+const vincentyMaxIter = 200
+
+// VincentyInverse solves the inverse geodesic problem on the ellipsoid
+// with Vincenty's formulae: it returns the length in meters of the
+// shortest path between two points, and the azimuths, or bearings, of
+// the path at each end, in degrees in the range [0, 360).
+//
+// The result is accurate to within a millimeter or so, but for nearly
+// antipodal points, roughly those less than half a degree from
+// antipodal, the iteration may fail to converge and VincentyInverse
+// returns ErrNoConvergence. Use the Karney geodesic algorithms, which
+// converge everywhere, when such points may occur.
+//
+// The algorithm is due to T. Vincenty, "Direct and inverse solutions of
+// geodesics on the ellipsoid with application of nested equations",
+// Survey Review 23 (1975).
+func (e Ellipsoid) VincentyInverse(from, to LatLng) (distance, azi1, azi2 float64, err error) {
+	f, major, minor := e.F, e.A, e.B()
+	phi1, lambda1 := from.radians()
+	phi2, lambda2 := to.radians()
+	l := lambda2 - lambda1
+	tanU1 := (1 - f) * math.Tan(phi1)
+	tanU2 := (1 - f) * math.Tan(phi2)
+	cosU1 := 1 / math.Hypot(1, tanU1)
+	cosU2 := 1 / math.Hypot(1, tanU2)
+	sinU1, sinU2 := tanU1*cosU1, tanU2*cosU2
+	lambda := l
+	var sinLambda, cosLambda, sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	converged := false
+	for range vincentyMaxIter {
+		sinLambda, cosLambda = math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, 0, 0, nil // Coincident points
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0 // On the equator
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = l + (1-c)*f*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-l) > math.Pi {
+			// The iteration has run away, as it does for points
+			// which are nearly antipodal.
+			break
+		}
+		if math.Abs(lambda-prev) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return 0, 0, 0, ErrNoConvergence
+	}
+	u2 := cos2Alpha * (major*major - minor*minor) / (minor * minor)
+	a := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+	b := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+	deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	distance = minor * a * (sigma - deltaSigma)
+	azi1 = math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+	azi2 = math.Atan2(cosU1*sinLambda, -sinU1*cosU2+cosU1*sinU2*cosLambda)
+	return distance, normalizeBearing(azi1 / degToRad), normalizeBearing(azi2 / degToRad), nil
+}
+
+// VincentyDirect solves the direct geodesic problem on the ellipsoid
+// with Vincenty's formulae: it returns the point reached by
+// following the geodesic from start with the initial azimuth azi1, in
+// degrees, for the given distance in meters, and the azimuth of the
+// geodesic at that point, in degrees in the range [0, 360). Unlike the
+// inverse problem, the iteration converges for all distances up to half
+// the circumference of the ellipsoid.
+func (e Ellipsoid) VincentyDirect(start LatLng, azi1, distance float64) (end LatLng, azi2 float64) {
+	f, major, minor := e.F, e.A, e.B()
+	phi1, lambda1 := start.radians()
+	sinAlpha1, cosAlpha1 := math.Sincos(azi1 * degToRad)
+	tanU1 := (1 - f) * math.Tan(phi1)
+	cosU1 := 1 / math.Hypot(1, tanU1)
+	sinU1 := tanU1 * cosU1
+	sigma1 := math.Atan2(tanU1, cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cos2Alpha := 1 - sinAlpha*sinAlpha
+	u2 := cos2Alpha * (major*major - minor*minor) / (minor * minor)
+	a := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+	b := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+	sigma := distance / (minor * a)
+	var sinSigma, cosSigma, cos2SigmaM float64
+	for range vincentyMaxIter {
+		cos2SigmaM = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sincos(sigma)
+		deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		prev := sigma
+		sigma = distance/(minor*a) + deltaSigma
+		if math.Abs(sigma-prev) < 1e-12 {
+			break
+		}
+	}
+	sinSigma, cosSigma = math.Sincos(sigma)
+	cos2SigmaM = math.Cos(2*sigma1 + sigma)
+	x := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	phi2 := math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-f)*math.Hypot(sinAlpha, x))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+	l := lambda - (1-c)*f*sinAlpha*
+		(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+	end = LatLng{phi2 / degToRad, wrapLng((lambda1 + l) / degToRad)}
+	azi2 = math.Atan2(sinAlpha, -x) / degToRad
+	return end, normalizeBearing(azi2)
+}
+
+// VincentyInverse solves the inverse geodesic problem on the WGS84
+// ellipsoid with Vincenty's formulae.
+func VincentyInverse(from, to LatLng) (distance, azi1, azi2 float64, err error) {
+	return WGS84.VincentyInverse(from, to)
+}
+
+// VincentyDirect solves the direct geodesic problem on the WGS84
+// ellipsoid with Vincenty's formulae.
+func VincentyDirect(start LatLng, azi1, distance float64) (end LatLng, azi2 float64) {
+	return WGS84.VincentyDirect(start, azi1, distance)
+}
+
+// normalizeBearing wraps a bearing in degrees into the range [0, 360).
+func normalizeBearing(b float64) float64 {
+	b = math.Mod(b, 360)
+	if b < 0 {
+		b += 360
+	}
+	if b == 360 || b == 0 {
+		b = 0 // Avoid -0, and 360 from rounding a tiny negative b
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+package geodesy
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// dms returns the angle in degrees with the given degrees, minutes and
+// seconds.
+func dms(d, m, s float64) float64 {
+	return d + m/60 + s/3600
+}
+
+// The classic test case of Flinders Peak to Buninyong, from Geoscience
+// Australia, on GRS80, whose flattening differs from that of WGS84 by
+// too little to matter at this distance.
+var (
+	flindersPeak = LatLng{-dms(37, 57, 3.72030), dms(144, 25, 29.52440)}
+	buninyong    = LatLng{-dms(37, 39, 10.15610), dms(143, 55, 35.38390)}
+)
+
+const (
+	flindersDistance = 54972.271
+	flindersAzi1     = 306 + 52/60.0 + 5.37/3600
+	flindersAzi2     = 307 + 10/60.0 + 25.07/3600
+)
+
+func TestVincentyInverse(t *testing.T) {
+	tests := []struct {
+		from, to         LatLng
+		dist, azi1, azi2 float64
+	}{
+		{flindersPeak, buninyong, flindersDistance, flindersAzi1, flindersAzi2},
+		{LatLng{0, 0}, LatLng{0, 90}, WGS84.A * math.Pi / 2, 90, 90},
+		{LatLng{0, 0}, LatLng{10, 0}, 1105854.833, 0, 0},
+		{LatLng{0, 0}, LatLng{0, 0}, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		d, a1, a2, err := VincentyInverse(tt.from, tt.to)
+		if err != nil || math.Abs(d-tt.dist) > 1e-3 || math.Abs(a1-tt.azi1) > 1e-5 || math.Abs(a2-tt.azi2) > 1e-5 {
+			t.Errorf("VincentyInverse(%v, %v) = (%v, %v, %v, %v), want (%v, %v, %v)", tt.from, tt.to, d, a1, a2, err, tt.dist, tt.azi1, tt.azi2)
+		}
+	}
+	if _, _, _, err := VincentyInverse(LatLng{0, 0}, LatLng{0.5, 179.7}); !errors.Is(err, ErrNoConvergence) {
+		t.Errorf("VincentyInverse of nearly antipodal points error = %v, want %v", err, ErrNoConvergence)
+	}
+}
+
+func TestVincentyDirect(t *testing.T) {
+	end, azi2 := VincentyDirect(flindersPeak, flindersAzi1, flindersDistance)
+	if math.Abs(end.Lat-buninyong.Lat) > 1e-8 || math.Abs(end.Lng-buninyong.Lng) > 1e-8 || math.Abs(azi2-flindersAzi2) > 1e-5 {
+		t.Errorf("VincentyDirect(Flinders Peak) = (%v, %v), want (%v, %v)", end, azi2, buninyong, flindersAzi2)
+	}
+	end, azi2 = VincentyDirect(LatLng{0, 0}, 90, WGS84.A*math.Pi)
+	if math.Abs(end.Lat) > 1e-12 || math.Abs(end.Lng+180) > 1e-9 || azi2 != 90 {
+		t.Errorf("VincentyDirect half way around the equator = (%v, %v)", end, azi2)
+	}
+}
+
+func TestVincentyRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(38))
+	for range 10000 {
+		a, b := randomLatLng(r), randomLatLng(r)
+		d, azi1, azi2, err := VincentyInverse(a, b)
+		if errors.Is(err, ErrNoConvergence) {
+			continue
+		}
+		end, endAzi := VincentyDirect(a, azi1, d)
+		if math.Abs(end.Lat-b.Lat) > 1e-7 || math.Abs(math.Remainder(end.Lng-b.Lng, 360))*math.Cos(b.Lat*degToRad) > 1e-7 {
+			t.Fatalf("VincentyDirect(%v, %v, %v) = %v, want %v", a, azi1, d, end, b)
+		}
+		if math.Abs(b.Lat) < 89 && math.Abs(math.Remainder(endAzi-azi2, 360)) > 1e-5 {
+			t.Fatalf("VincentyDirect(%v, %v, %v) azimuth = %v, want %v", a, azi1, d, endAzi, azi2)
+		}
+		// On the ellipsoid, the distance is within 0.6% of the
+		// spherical distance.
+		if h := Haversine(a, b); math.Abs(d-h) > 0.006*h+1e-6 {
+			t.Fatalf("VincentyInverse(%v, %v) = %v, far from the haversine %v", a, b, d, h)
+		}
+	}
+}
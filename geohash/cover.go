@@ -0,0 +1,178 @@
+package geohash
+
+import (
+	"math"
+	"slices"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// CoverBox returns a small set of geohashes, of varying precision, whose
+// rectangles together cover every point of a box, for use as prefixes
+// in a range or prefix scan over geohash keys. If b.MinLng is greater
+// than b.MaxLng, the box is taken to cross the antimeridian.
+//
+// The covering starts from the whole Earth and repeatedly divides the
+// coarsest geohash which only partly overlaps the box into those of its
+// 32 children which intersect the box, for as long as the result has no
+// more than maxHashes geohashes and no geohash with more than
+// maxPrecision characters. Geohashes that lie entirely inside the box
+// are never divided. If even the geohashes of one character which
+// intersect the box number more than maxHashes, the result is the
+// single empty prefix, which matches every geohash. The result is
+// sorted.
+func CoverBox(b Box, maxPrecision, maxHashes int) []string {
+	if b.MinLng > b.MaxLng {
+		west := Box{b.MinLat, b.MaxLat, b.MinLng, 180}
+		east := Box{b.MinLat, b.MaxLat, -180, b.MaxLng}
+		return cover(boxRegion{west, east}, maxPrecision, maxHashes)
+	}
+	return cover(boxRegion{b}, maxPrecision, maxHashes)
+}
+
+// CoverCircle returns a small set of geohashes which together cover
+// every point within radius meters of the point with the given latitude
+// and longitude, in degrees. Distances are great circle distances on a
+// sphere with the mean radius of the Earth. The covering is built, and
+// the arguments maxPrecision and maxHashes have the same meaning, as in
+// CoverBox.
+func CoverCircle(lat, lng, radius float64, maxPrecision, maxHashes int) []string {
+	return cover(circleRegion{lat, lng, radius / earthRadius}, maxPrecision, maxHashes)
+}
+
+// region is an area of the Earth which can be covered by geohashes.
+type region interface {
+	// intersects reports whether the region and a box have any point in
+	// common.
+	intersects(b Box) bool
+
+	// contains reports whether the region contains every point of a
+	// box.
+	contains(b Box) bool
+}
+
+// cell is a geohash of the given precision in integer form.
+type cell struct {
+	d         uint64
+	precision int
+}
+
+// cover returns a covering of a region as described by CoverBox.
+func cover(r region, maxPrecision, maxHashes int) []string {
+	// Refining the cells in first-in first-out order divides the
+	// coarsest first, since children are always queued after their
+	// parents.
+	queue := []cell{{0, 0}}
+	var done []cell
+	var children []cell
+	for len(queue) > 0 && queue[0].precision < maxPrecision {
+		c := queue[0]
+		children = children[:0]
+		for k := range uint64(32) {
+			ch := cell{c.d<<5 | k, c.precision + 1}
+			if r.intersects(decodeBits(ch.d, 5*ch.precision)) {
+				children = append(children, ch)
+			}
+		}
+		if len(done)+len(queue)-1+len(children) > maxHashes {
+			break
+		}
+		queue = queue[1:]
+		for _, ch := range children {
+			if r.contains(decodeBits(ch.d, 5*ch.precision)) {
+				done = append(done, ch)
+			} else {
+				queue = append(queue, ch)
+			}
+		}
+	}
+	hashes := make([]string, 0, len(done)+len(queue))
+	for _, c := range append(done, queue...) {
+		hashes = append(hashes, format(c.d, c.precision))
+	}
+	slices.Sort(hashes)
+	return hashes
+}
+
+// boxRegion is the union of one or more boxes which do not cross the
+// antimeridian.
+type boxRegion []Box
+
+func (r boxRegion) intersects(b Box) bool {
+	for _, q := range r {
+		if b.MinLat <= q.MaxLat && q.MinLat <= b.MaxLat && b.MinLng <= q.MaxLng && q.MinLng <= b.MaxLng {
+			return true
+		}
+	}
+	return false
+}
+
+func (r boxRegion) contains(b Box) bool {
+	for _, q := range r {
+		if q.MinLat <= b.MinLat && b.MaxLat <= q.MaxLat && q.MinLng <= b.MinLng && b.MaxLng <= q.MaxLng {
+			return true
+		}
+	}
+	return false
+}
+
+// circleRegion is a spherical cap, given by the latitude and longitude
+// of its center in degrees and its angular radius in radians.
+type circleRegion struct {
+	lat, lng, radius float64
+}
+
+func (r circleRegion) intersects(b Box) bool {
+	// The nearest point of the box lies on the meridian through the
+	// center if the box spans the center's longitude, or otherwise on
+	// the nearer of its western and eastern edges. Along a meridian
+	// the distance to the center has a single minimum, so clamping the
+	// nearest point of the whole meridian to the edge gives the nearest
+	// point of the edge.
+	phi := r.lat * math.Pi / 180
+	nearest := func(lng float64) float64 {
+		dLambda := (lng - r.lng) * math.Pi / 180
+		lat := math.Atan2(math.Sin(phi), math.Cos(phi)*math.Cos(dLambda)) * 180 / math.Pi
+		return angle(r.lat, r.lng, max(b.MinLat, min(b.MaxLat, lat)), lng)
+	}
+	if wrap(r.lng-b.MinLng) <= b.MaxLng-b.MinLng {
+		return nearest(r.lng) <= r.radius
+	}
+	return min(nearest(b.MinLng), nearest(b.MaxLng)) <= r.radius
+}
+
+func (r circleRegion) contains(b Box) bool {
+	// The farthest point of a box from the center is one of its
+	// corners, unless the box spans the meridian opposite the center.
+	if wrap(r.lng+180-b.MinLng) <= b.MaxLng-b.MinLng {
+		return false
+	}
+	for _, lat := range [2]float64{b.MinLat, b.MaxLat} {
+		for _, lng := range [2]float64{b.MinLng, b.MaxLng} {
+			if angle(r.lat, r.lng, lat, lng) > r.radius {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// wrap wraps a difference of longitudes into the range [0, 360).
+func wrap(dLng float64) float64 {
+	dLng = math.Mod(dLng, 360)
+	if dLng < 0 {
+		dLng += 360
+	}
+	return dLng
+}
+
+// angle returns the angle in radians between two points on the sphere,
+// given by their latitudes and longitudes in degrees, using the
+// haversine formula.
+func angle(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi, dLambda := phi2-phi1, (lng2-lng1)*math.Pi/180
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * math.Asin(math.Sqrt(min(1, h)))
+}
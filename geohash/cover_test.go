@@ -0,0 +1,104 @@
+package geohash
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// covered reports whether a covering has a prefix of the geohash of a
+// point.
+func covered(hashes []string, lat, lng float64) bool {
+	h := Encode(lat, lng, MaxPrecision)
+	for _, p := range hashes {
+		if strings.HasPrefix(h, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCoverBox(t *testing.T) {
+	tests := []struct {
+		b                       Box
+		maxPrecision, maxHashes int
+		want                    []string
+	}{
+		{Box{37.7, 37.8, -122.5, -122.4}, 6, 8, []string{"9q8y", "9q8z"}},
+		// The whole Earth is covered by its 32 geohashes of one
+		// character, or the empty prefix if that is too many.
+		{Box{-90, 90, -180, 180}, 6, 8, []string{""}},
+		{Box{-90, 90, -180, 180}, 6, 32, strings.Split(alphabet, "")},
+		// A box across the antimeridian.
+		{Box{0, 10, 170, -170}, 2, 100, []string{"2p", "80", "81", "rz", "xb", "xc"}},
+		// A box inside a single geohash, at most maxPrecision deep.
+		{Box{42.6, 42.61, -5.6, -5.59}, 3, 100, []string{"ezs"}},
+	}
+	for _, tt := range tests {
+		if got := CoverBox(tt.b, tt.maxPrecision, tt.maxHashes); !slices.Equal(got, tt.want) {
+			t.Errorf("CoverBox(%v, %d, %d) = %q, want %q", tt.b, tt.maxPrecision, tt.maxHashes, got, tt.want)
+		}
+	}
+}
+
+func TestCoverCircle(t *testing.T) {
+	want := []string{"9q8yy4", "9q8yy5", "9q8yy6", "9q8yy7", "9q8yye", "9q8yyh", "9q8yyj", "9q8yyk", "9q8yym", "9q8yys"}
+	if got := CoverCircle(37.77, -122.42, 1000, 7, 16); !slices.Equal(got, want) {
+		t.Errorf("CoverCircle(37.77, -122.42, 1000, 7, 16) = %q, want %q", got, want)
+	}
+	// A circle around a pole covers every longitude.
+	got := CoverCircle(90, 0, 100e3, 2, 100)
+	for lng := -179.5; lng < 180; lng += 1 {
+		if !covered(got, 89.5, lng) {
+			t.Fatalf("CoverCircle around the pole = %q, missing longitude %v", got, lng)
+		}
+	}
+}
+
+func TestCoverRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(31))
+	for range 200 {
+		lat, lng := r.Float64()*170-85, r.Float64()*360-180
+		radius := math.Pow(10, r.Float64()*6+1)
+		maxHashes := 1 + r.Intn(64)
+		hs := CoverCircle(lat, lng, radius, 9, maxHashes)
+		if len(hs) > maxHashes || !slices.IsSorted(hs) {
+			t.Fatalf("CoverCircle(%v, %v, %v, 9, %d) = %q", lat, lng, radius, maxHashes, hs)
+		}
+		for range 200 {
+			// A random point within the circle.
+			d := radius * math.Sqrt(r.Float64()) / earthRadius
+			az := r.Float64() * 2 * math.Pi
+			p1 := lat * math.Pi / 180
+			p2 := math.Asin(math.Sin(p1)*math.Cos(d) + math.Cos(p1)*math.Sin(d)*math.Cos(az))
+			l2 := lng*math.Pi/180 + math.Atan2(math.Sin(az)*math.Sin(d)*math.Cos(p1), math.Cos(d)-math.Sin(p1)*math.Sin(p2))
+			plat, plng := p2*180/math.Pi, math.Mod(l2*180/math.Pi+540, 360)-180
+			if !covered(hs, plat, plng) {
+				t.Fatalf("CoverCircle(%v, %v, %v, 9, %d) = %q, missing (%v, %v)", lat, lng, radius, maxHashes, hs, plat, plng)
+			}
+		}
+		b := Box{lat, lat + r.Float64()*3, lng, lng + r.Float64()*5}
+		if b.MaxLng > 180 {
+			b.MaxLng -= 360
+		}
+		hs = CoverBox(b, 8, maxHashes)
+		if len(hs) > maxHashes || !slices.IsSorted(hs) {
+			t.Fatalf("CoverBox(%v, 8, %d) = %q", b, maxHashes, hs)
+		}
+		for range 200 {
+			w := b.MaxLng - b.MinLng
+			if w < 0 {
+				w += 360
+			}
+			plat, plng := b.MinLat+r.Float64()*(b.MaxLat-b.MinLat), b.MinLng+r.Float64()*w
+			if plng >= 180 {
+				plng -= 360
+			}
+			if !covered(hs, plat, plng) {
+				t.Fatalf("CoverBox(%v, 8, %d) = %q, missing (%v, %v)", b, maxHashes, hs, plat, plng)
+			}
+		}
+	}
+}
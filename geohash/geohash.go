@@ -0,0 +1,180 @@
+// Package geohash encodes and decodes geohashes, the strings of base-32
+// characters invented by Gustavo Niemeyer which identify rectangles on
+// the Earth, such that rectangles which share a prefix of their
+// geohashes are usually near each other.
+//
+// A geohash of precision p encodes 5p bits, alternately halving the
+// range of longitude and of latitude, starting with longitude. The bits
+// are therefore the bits of a Morton code of the latitude and longitude,
+// as produced by morton.Encode2, and geohashes sort in Z order.
+// EncodeInt and DecodeInt work with the same bits as integers, without
+// the base-32 step.
+package geohash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gogama/geospat/morton"
+)
+
+// MaxPrecision is the largest supported precision, in characters. A
+// geohash of MaxPrecision characters identifies a rectangle a few
+// centimeters across.
+const MaxPrecision = 12
+
+// alphabet is the geohash base-32 alphabet, which omits the letters a,
+// i, l and o.
+const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// ErrInvalid is returned, wrapped, when a function is given a string
+// which is not a valid geohash.
+var ErrInvalid = errors.New("geohash: invalid geohash")
+
+// decodeTable maps each byte to its value in the geohash alphabet, or to
+// 0xff if it is not in the alphabet. Upper case letters are accepted.
+var decodeTable = func() (t [256]byte) {
+	for i := range t {
+		t[i] = 0xff
+	}
+	for i := 0; i < len(alphabet); i++ {
+		t[alphabet[i]] = byte(i)
+		if c := alphabet[i]; 'a' <= c && c <= 'z' {
+			t[c-'a'+'A'] = byte(i)
+		}
+	}
+	return
+}()
+
+// Box is a rectangle on the Earth, bounded by lines of latitude and
+// longitude given in degrees.
+type Box struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// Center returns the latitude and longitude of the center of the box.
+func (b Box) Center() (lat, lng float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
+
+// Contains reports whether the point with the given latitude and
+// longitude lies inside the box or on its edge.
+func (b Box) Contains(lat, lng float64) bool {
+	return b.MinLat <= lat && lat <= b.MaxLat && b.MinLng <= lng && lng <= b.MaxLng
+}
+
+// Encode returns the geohash with the given precision, in characters,
+// of the point with the given latitude and longitude, in degrees. The
+// precision must be in the range [1, MaxPrecision]. Latitudes beyond
+// the poles are clamped and longitudes are wrapped.
+//
+// The complementary function Decode performs the inverse mapping.
+func Encode(lat, lng float64, precision int) string {
+	return format(encodeBits(lat, lng, 5*precision), precision)
+}
+
+// format returns the geohash string of the given precision for the
+// 5*precision bits of d.
+func format(d uint64, precision int) string {
+	b := make([]byte, precision)
+	for i := range b {
+		b[i] = alphabet[d>>uint(5*(precision-1-i))&31]
+	}
+	return string(b)
+}
+
+// Decode returns the center of the rectangle identified by a geohash,
+// together with the largest possible error of the center in latitude
+// and longitude: the true point lies within latErr degrees of lat and
+// within lngErr degrees of lng. It returns an error wrapping ErrInvalid
+// if the geohash is empty, too long, or contains a character other than
+// those of the geohash alphabet.
+//
+// The complementary function Encode performs the inverse mapping.
+func Decode(hash string) (lat, lng, latErr, lngErr float64, err error) {
+	b, err := DecodeBoundingBox(hash)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	lat, lng = b.Center()
+	return lat, lng, (b.MaxLat - b.MinLat) / 2, (b.MaxLng - b.MinLng) / 2, nil
+}
+
+// DecodeBoundingBox returns the rectangle identified by a geohash. It
+// returns the same errors as Decode.
+func DecodeBoundingBox(hash string) (Box, error) {
+	d, err := parse(hash)
+	if err != nil {
+		return Box{}, err
+	}
+	return decodeBits(d, 5*len(hash)), nil
+}
+
+// parse returns the bits encoded by a geohash.
+func parse(hash string) (d uint64, err error) {
+	if len(hash) == 0 || len(hash) > MaxPrecision {
+		return 0, fmt.Errorf("%w: %q must have from 1 to %d characters", ErrInvalid, hash, MaxPrecision)
+	}
+	for i := 0; i < len(hash); i++ {
+		v := decodeTable[hash[i]]
+		if v == 0xff {
+			return 0, fmt.Errorf("%w: %q has invalid character %q", ErrInvalid, hash, hash[i])
+		}
+		d = d<<5 | uint64(v)
+	}
+	return d, nil
+}
+
+// encodeBits returns the n-bit geohash of a point as an integer, with
+// the first longitude bit most significant. The bit count n must be in
+// the range [1, 64].
+func encodeBits(lat, lng float64, n int) uint64 {
+	lngBits, latBits := (n+1)/2, n/2
+	lat = max(-90, min(90, lat))
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return interleave(quantize(lng/360, lngBits), quantize((lat+90)/180, latBits), n)
+}
+
+// interleave returns the n-bit geohash of the cell in column x and row y
+// of the grid of n-bit geohashes, counted from the south-west corner.
+func interleave(x, y uint32, n int) uint64 {
+	// The most significant of the n interleaved bits is a longitude bit,
+	// which is an odd bit of a Morton code if n is even.
+	if n%2 == 0 {
+		return morton.Encode2(y, x)
+	}
+	return morton.Encode2(x, y)
+}
+
+// deinterleave returns the column and row of the n-bit geohash d.
+func deinterleave(d uint64, n int) (x, y uint32) {
+	if n%2 == 0 {
+		y, x = morton.Decode2(d)
+	} else {
+		x, y = morton.Decode2(d)
+	}
+	return
+}
+
+// decodeBits returns the rectangle identified by the n-bit geohash d.
+func decodeBits(d uint64, n int) Box {
+	lngBits, latBits := (n+1)/2, n/2
+	x, y := deinterleave(d, n)
+	latSize := 180 / math.Ldexp(1, latBits)
+	lngSize := 360 / math.Ldexp(1, lngBits)
+	minLat := float64(y)*latSize - 90
+	minLng := float64(x)*lngSize - 180
+	return Box{minLat, minLat + latSize, minLng, minLng + lngSize}
+}
+
+// quantize returns the integer part of f * 2^bits, for f in [0, 1],
+// clamped to the range [0, 2^bits-1].
+func quantize(f float64, bits int) uint32 {
+	scale := math.Ldexp(1, bits)
+	return uint32(max(0, min(scale-1, math.Floor(f*scale))))
+}
@@ -0,0 +1,70 @@
+package geohash
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		lat, lng  float64
+		precision int
+		hash      string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"}, // Niemeyer's original example
+		{42.6, -5.6, 5, "ezs42"},
+		{0, 0, 1, "s"},
+		{-0.0001, -0.0001, 1, "7"},
+		{90, 180, 12, "bpbpbpbpbpbp"},
+		{-90, -180, 1, "0"},
+		{100, 190, 3, Encode(90, -170, 3)},
+	}
+	for _, tt := range tests {
+		if h := Encode(tt.lat, tt.lng, tt.precision); h != tt.hash {
+			t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.precision, h, tt.hash)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	lat, lng, latErr, lngErr, err := Decode("ezs42")
+	if lat != 42.60498046875 || lng != -5.60302734375 || latErr != 0.02197265625 || lngErr != 0.02197265625 || err != nil {
+		t.Errorf("Decode(%q) = (%v, %v, %v, %v, %v)", "ezs42", lat, lng, latErr, lngErr, err)
+	}
+	want := Box{42.5830078125, 42.626953125, -5.625, -5.5810546875}
+	if b, err := DecodeBoundingBox("EZS42"); b != want || err != nil {
+		t.Errorf("DecodeBoundingBox(%q) = (%v, %v), want %v", "EZS42", b, err, want)
+	}
+	if b, _ := DecodeBoundingBox("b"); b != (Box{45, 90, -180, -135}) {
+		t.Errorf("DecodeBoundingBox(%q) = %v", "b", b)
+	}
+	for _, h := range []string{"", "ezs4a", "ezs4i", "ezs4l", "ezs4o", "ezs 4", "0123456789bcd"} {
+		if _, _, _, _, err := Decode(h); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) error = %v, want %v", h, err, ErrInvalid)
+		}
+		if _, err := DecodeBoundingBox(h); !errors.Is(err, ErrInvalid) {
+			t.Errorf("DecodeBoundingBox(%q) error = %v, want %v", h, err, ErrInvalid)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(29))
+	for range 10000 {
+		lat, lng := r.Float64()*180-90, r.Float64()*360-180
+		p := 1 + r.Intn(MaxPrecision)
+		h := Encode(lat, lng, p)
+		b, err := DecodeBoundingBox(h)
+		if err != nil || len(h) != p || !b.Contains(lat, lng) {
+			t.Fatalf("DecodeBoundingBox(Encode(%v, %v, %d)) = (%v, %v)", lat, lng, p, b, err)
+		}
+		if clat, clng := b.Center(); Encode(clat, clng, p) != h {
+			t.Fatalf("the center of %q is not in it", h)
+		}
+		// Geohashes of a point are prefixes of each other.
+		if long := Encode(lat, lng, MaxPrecision); long[:p] != h {
+			t.Fatalf("Encode(%v, %v, %d) = %q, not a prefix of %q", lat, lng, p, h, long)
+		}
+	}
+}
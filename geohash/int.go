@@ -0,0 +1,34 @@
+package geohash
+
+// MaxBits is the largest supported bit count of an integer geohash.
+const MaxBits = 64
+
+// EncodeInt returns the integer geohash with the given number of bits
+// of the point with the given latitude and longitude, in degrees. The
+// bit count must be in the range [1, MaxBits]. Latitudes beyond the
+// poles are clamped and longitudes are wrapped.
+//
+// An integer geohash holds the interleaved bits of a geohash string
+// without the base-32 step, right-aligned, so the first longitude bit is
+// bit bits-1. For a bit count of 5p it is exactly the value encoded by
+// the geohash string of precision p, but any bit count may be used, for
+// example 52 to fit in the mantissa of a float64. Integer geohashes with
+// the same bit count sort numerically in the same order as geohash
+// strings.
+//
+// The complementary function DecodeInt performs the inverse mapping.
+func EncodeInt(lat, lng float64, bits int) uint64 {
+	return encodeBits(lat, lng, bits)
+}
+
+// DecodeInt returns the rectangle identified by an integer geohash with
+// the given number of bits, which must be in the range [1, MaxBits].
+// Bits of d above the bit count are ignored.
+//
+// The complementary function EncodeInt performs the inverse mapping.
+func DecodeInt(d uint64, bits int) Box {
+	if bits < MaxBits {
+		d &= 1<<uint(bits) - 1
+	}
+	return decodeBits(d, bits)
+}
@@ -0,0 +1,64 @@
+package geohash
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeInt(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		bits     int
+		want     uint64
+	}{
+		{42.6, -5.6, 25, 14672002}, // "ezs42"
+		{42.6, -5.6, 52, 1969242828722037},
+		{90, 179.99999, 1, 1},
+		{-90, -180, 64, 0},
+		{90, 180, 2, 1}, // Longitude 180 wraps to -180
+	}
+	for _, tt := range tests {
+		if d := EncodeInt(tt.lat, tt.lng, tt.bits); d != tt.want {
+			t.Errorf("EncodeInt(%v, %v, %d) = %d, want %d", tt.lat, tt.lng, tt.bits, d, tt.want)
+		}
+	}
+}
+
+func TestDecodeInt(t *testing.T) {
+	tests := []struct {
+		d    uint64
+		bits int
+		want Box
+	}{
+		{1, 1, Box{-90, 90, 0, 180}},
+		{14672002, 25, Box{42.5830078125, 42.626953125, -5.625, -5.5810546875}},
+		{1<<25 | 14672002, 25, Box{42.5830078125, 42.626953125, -5.625, -5.5810546875}},
+		{3, 2, Box{0, 90, 0, 180}},
+	}
+	for _, tt := range tests {
+		if b := DecodeInt(tt.d, tt.bits); b != tt.want {
+			t.Errorf("DecodeInt(%d, %d) = %v, want %v", tt.d, tt.bits, b, tt.want)
+		}
+	}
+}
+
+func TestEncodeIntMatchesEncode(t *testing.T) {
+	r := rand.New(rand.NewSource(32))
+	for range 10000 {
+		lat, lng := r.Float64()*180-90, r.Float64()*360-180
+		p := 1 + r.Intn(MaxPrecision)
+		d, _ := parse(Encode(lat, lng, p))
+		if e := EncodeInt(lat, lng, 5*p); e != d {
+			t.Fatalf("EncodeInt(%v, %v, %d) = %d, want %d", lat, lng, 5*p, e, d)
+		}
+		bits := 1 + r.Intn(MaxBits)
+		e := EncodeInt(lat, lng, bits)
+		if b := DecodeInt(e, bits); !b.Contains(lat, lng) {
+			t.Fatalf("DecodeInt(EncodeInt(%v, %v, %d)) = %v", lat, lng, bits, b)
+		}
+		// Dropping the last bit gives the parent.
+		if bits > 1 && EncodeInt(lat, lng, bits-1) != e>>1 {
+			t.Fatalf("EncodeInt(%v, %v, %d) is not the parent of %d", lat, lng, bits-1, e)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package geohash
+
+import "fmt"
+
+// Direction is one of the eight compass directions from a geohash
+// rectangle to its neighbors.
+type Direction int
+
+// The compass directions, clockwise from north. Neighbors returns the
+// neighbors of a geohash in this order.
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// offsets holds the change in column and row for each direction.
+var offsets = [8][2]int{{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1}}
+
+// String returns the name of the direction, for example "NorthEast".
+func (d Direction) String() string {
+	names := [8]string{"North", "NorthEast", "East", "SouthEast", "South", "SouthWest", "West", "NorthWest"}
+	if d < 0 || int(d) >= len(names) {
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+	return names[d]
+}
+
+// Adjacent returns the geohash of the same precision which neighbors
+// hash in the given direction. Neighbors wrap around the antimeridian,
+// so the eastern neighbor of a hash on the eastern edge of the map is on
+// its western edge. There is nothing north of the northernmost row of
+// geohashes or south of the southernmost, so in those cases Adjacent
+// returns the empty string. It returns an error wrapping ErrInvalid if
+// hash is not a valid geohash, and panics if the direction is not one
+// of the eight compass directions.
+func Adjacent(hash string, dir Direction) (string, error) {
+	d, err := parse(hash)
+	if err != nil {
+		return "", err
+	}
+	return adjacent(d, len(hash), dir), nil
+}
+
+// Neighbors returns the geohashes of the same precision which neighbor
+// hash, in the order given by the Direction constants, that is,
+// clockwise from north. The neighbors which would lie beyond a pole are
+// the empty string, as with Adjacent. It returns an error wrapping
+// ErrInvalid if hash is not a valid geohash.
+func Neighbors(hash string) (neighbors [8]string, err error) {
+	d, err := parse(hash)
+	if err != nil {
+		return neighbors, err
+	}
+	for dir := range neighbors {
+		neighbors[dir] = adjacent(d, len(hash), Direction(dir))
+	}
+	return neighbors, nil
+}
+
+// adjacent returns the neighbor in the given direction of the geohash
+// with bits d and the given precision.
+func adjacent(d uint64, precision int, dir Direction) string {
+	n := 5 * precision
+	lngBits, latBits := (n+1)/2, n/2
+	x, y := deinterleave(d, n)
+	off := offsets[dir]
+	y += uint32(off[1])
+	if y>>uint(latBits) != 0 {
+		return "" // Beyond a pole, where y has underflowed or overflowed
+	}
+	x = (x + uint32(off[0])) & uint32(1<<uint(lngBits)-1)
+	return format(interleave(x, y, n), precision)
+}
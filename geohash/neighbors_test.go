@@ -0,0 +1,103 @@
+package geohash
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestNeighbors(t *testing.T) {
+	tests := []struct {
+		hash string
+		want [8]string
+	}{
+		{"dqcjq", [8]string{"dqcjw", "dqcjx", "dqcjr", "dqcjp", "dqcjn", "dqcjj", "dqcjm", "dqcjt"}},
+		// On the northern edge of the map, with nothing to the north.
+		// Its western neighbors wrap around the antimeridian.
+		{"b", [8]string{"", "", "c", "9", "8", "x", "z", ""}},
+		// On the southern edge, at the antimeridian.
+		{"pbpb", [8]string{"pbpc", "0001", "0000", "", "", "", "pbp8", "pbp9"}},
+	}
+	for _, tt := range tests {
+		if got, err := Neighbors(tt.hash); got != tt.want || err != nil {
+			t.Errorf("Neighbors(%q) = (%q, %v), want %q", tt.hash, got, err, tt.want)
+		}
+	}
+	if _, err := Neighbors("dqcja"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Neighbors(%q) error = %v, want %v", "dqcja", err, ErrInvalid)
+	}
+}
+
+func TestAdjacent(t *testing.T) {
+	tests := []struct {
+		hash string
+		dir  Direction
+		want string
+	}{
+		{"zzz", East, "bpb"},
+		{"bpb", West, "zzz"},
+		{"dqcjq", North, "dqcjw"},
+		{"DQCJQ", South, "dqcjn"},
+		{"zzz", North, ""},
+	}
+	for _, tt := range tests {
+		if got, err := Adjacent(tt.hash, tt.dir); got != tt.want || err != nil {
+			t.Errorf("Adjacent(%q, %v) = (%q, %v), want %q", tt.hash, tt.dir, got, err, tt.want)
+		}
+	}
+	if _, err := Adjacent("", North); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Adjacent(%q) error = %v, want %v", "", err, ErrInvalid)
+	}
+}
+
+func TestAdjacentRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(30))
+	for range 10000 {
+		lat, lng := r.Float64()*178-89, r.Float64()*360-180
+		h := Encode(lat, lng, 2+r.Intn(MaxPrecision-1))
+		b, _ := DecodeBoundingBox(h)
+		for dir := North; dir <= NorthWest; dir++ {
+			n, err := Adjacent(h, dir)
+			if err != nil {
+				t.Fatalf("Adjacent(%q, %v) error = %v", h, dir, err)
+			}
+			// The neighbor in a direction contains the point one box
+			// beyond the center in that direction, if there is one.
+			clat, clng := b.Center()
+			off := offsets[dir]
+			lat := clat + float64(off[1])*(b.MaxLat-b.MinLat)
+			lng := clng + float64(off[0])*(b.MaxLng-b.MinLng)
+			want := ""
+			if lat > -90 && lat < 90 {
+				want = Encode(lat, lng, len(h))
+			}
+			if n != want {
+				t.Fatalf("Adjacent(%q, %v) = %q, want %q", h, dir, n, want)
+			}
+			if n == "" {
+				continue
+			}
+			if back, _ := Adjacent(n, (dir+4)%8); back != h {
+				t.Fatalf("Adjacent(Adjacent(%q, %v), %v) = %q", h, dir, (dir+4)%8, back)
+			}
+		}
+	}
+}
+
+func TestDirectionString(t *testing.T) {
+	tests := []struct {
+		dir  Direction
+		want string
+	}{
+		{North, "North"},
+		{SouthWest, "SouthWest"},
+		{NorthWest, "NorthWest"},
+		{Direction(9), "Direction(9)"},
+		{Direction(-1), "Direction(-1)"},
+	}
+	for _, tt := range tests {
+		if s := tt.dir.String(); s != tt.want {
+			t.Errorf("Direction(%d).String() = %q, want %q", int(tt.dir), s, tt.want)
+		}
+	}
+}
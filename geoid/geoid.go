@@ -0,0 +1,183 @@
+// Package geoid converts between heights above the ellipsoid, such as
+// those reported by GPS receivers, and orthometric heights above the
+// geoid, or mean sea level, such as those of maps and elevation models.
+//
+// The two differ by the geoid undulation N, the height of the geoid
+// above the ellipsoid, which ranges from about -106 m to +85 m over the
+// Earth: the orthometric height is H = h - N for an ellipsoidal height h.
+// The undulation is interpolated bilinearly in a grid of values, such as
+// the 15 minute grid of the EGM96 geoid model, which the user supplies,
+// for example by reading the file WW15MGH.GRD published by the United
+// States National Geospatial-Intelligence Agency with ReadGRD.
+package geoid
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// ErrInvalid is returned, wrapped, when a grid is malformed.
+var ErrInvalid = errors.New("geoid: invalid grid")
+
+// Grid is a regular grid of geoid undulations in meters at points
+// spaced evenly in latitude and longitude.
+type Grid struct {
+	south, west      float64
+	latStep, lngStep float64
+	rows, cols       int
+	// values holds the undulations by row from the south and by column
+	// from the west.
+	values []float64
+	// global is true if the grid spans all longitudes, so that
+	// longitudes are wrapped into it.
+	global bool
+}
+
+// NewGrid returns the grid of undulations with the given number of rows
+// and columns, whose south-west point is at latitude south and longitude
+// west, in degrees, and whose points are latStep and lngStep degrees
+// apart. The values are given by row from the south and by column from
+// the west. A grid spanning 360 degrees of longitude, with or without a
+// repeated last column, is taken to cover all longitudes. It returns an
+// error wrapping ErrInvalid if the grid has fewer than two rows or
+// columns, non-positive steps, or the wrong number of values.
+func NewGrid(south, west, latStep, lngStep float64, rows, cols int, values []float64) (*Grid, error) {
+	if rows < 2 || cols < 2 {
+		return nil, fmt.Errorf("%w: %d x %d points", ErrInvalid, rows, cols)
+	}
+	if !(latStep > 0) || !(lngStep > 0) {
+		return nil, fmt.Errorf("%w: steps %g and %g must be positive", ErrInvalid, latStep, lngStep)
+	}
+	if len(values) != rows*cols {
+		return nil, fmt.Errorf("%w: %d values for %d x %d points", ErrInvalid, len(values), rows, cols)
+	}
+	span := float64(cols) * lngStep
+	const eps = 1e-9
+	return &Grid{
+		south:   south,
+		west:    west,
+		latStep: latStep,
+		lngStep: lngStep,
+		rows:    rows,
+		cols:    cols,
+		values:  values,
+		global:  span >= 360-eps,
+	}, nil
+}
+
+// ReadGRD reads a grid in the text format of the NGA EGM96 and EGM2008
+// grids, such as WW15MGH.GRD: a header line giving the south, north,
+// west and east bounds of the grid and its latitude and longitude steps
+// in degrees, followed by the undulations in meters by row from the north
+// and by column from the west, separated by white space. It returns an
+// error wrapping ErrInvalid if the data is malformed, or the error from
+// r if reading fails.
+func ReadGRD(r io.Reader) (*Grid, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	s.Split(bufio.ScanWords)
+	var header [6]float64
+	for k := range header {
+		v, err := scanFloat(s)
+		if err != nil {
+			return nil, err
+		}
+		header[k] = v
+	}
+	south, north, west, east, dLat, dLng := header[0], header[1], header[2], header[3], header[4], header[5]
+	if !(dLat > 0) || !(dLng > 0) || !(north > south) || !(east > west) {
+		return nil, fmt.Errorf("%w: header %v", ErrInvalid, header)
+	}
+	rows := int(math.Round((north-south)/dLat)) + 1
+	cols := int(math.Round((east-west)/dLng)) + 1
+	values := make([]float64, rows*cols)
+	for row := rows - 1; row >= 0; row-- {
+		for col := range cols {
+			v, err := scanFloat(s)
+			if err != nil {
+				return nil, err
+			}
+			values[row*cols+col] = v
+		}
+	}
+	return NewGrid(south, west, dLat, dLng, rows, cols, values)
+}
+
+// scanFloat returns the next number from s.
+func scanFloat(s *bufio.Scanner) (float64, error) {
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrInvalid)
+	}
+	v, err := strconv.ParseFloat(s.Text(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a number", ErrInvalid, s.Text())
+	}
+	return v, nil
+}
+
+// Undulation returns the height in meters of the geoid above the
+// ellipsoid at a position, interpolated bilinearly between the four
+// surrounding points of the grid. It returns NaN for positions outside
+// the grid.
+func (g *Grid) Undulation(p geodesy.LatLng) float64 {
+	y := (p.Lat - g.south) / g.latStep
+	x := p.Lng - g.west
+	if g.global {
+		x = math.Mod(x, 360)
+		if x < 0 {
+			x += 360
+		}
+	}
+	x /= g.lngStep
+	if !(y >= 0 && y <= float64(g.rows-1)) || !(x >= 0) {
+		return math.NaN()
+	}
+	row := min(int(y), g.rows-2)
+	col := int(x)
+	if !g.global && col >= g.cols-1 {
+		if x > float64(g.cols-1) {
+			return math.NaN()
+		}
+		col = g.cols - 2
+	}
+	fy, fx := y-float64(row), x-float64(col)
+	v00, v01 := g.at(row, col), g.at(row, col+1)
+	v10, v11 := g.at(row+1, col), g.at(row+1, col+1)
+	return (1-fy)*((1-fx)*v00+fx*v01) + fy*((1-fx)*v10+fx*v11)
+}
+
+// at returns the value at a row and column of the grid, wrapping the
+// column for a global grid.
+func (g *Grid) at(row, col int) float64 {
+	if col >= g.cols {
+		col -= g.cols
+	}
+	return g.values[row*g.cols+col]
+}
+
+// OrthometricHeight returns the height in meters above the geoid of the
+// point at a position and ellipsoidal height in meters.
+//
+// The complementary method EllipsoidalHeight performs the inverse
+// mapping.
+func (g *Grid) OrthometricHeight(p geodesy.LatLng, ellipsoidal float64) float64 {
+	return ellipsoidal - g.Undulation(p)
+}
+
+// EllipsoidalHeight returns the height in meters above the ellipsoid of
+// the point at a position and orthometric height in meters.
+//
+// The complementary method OrthometricHeight performs the inverse
+// mapping.
+func (g *Grid) EllipsoidalHeight(p geodesy.LatLng, orthometric float64) float64 {
+	return orthometric + g.Undulation(p)
+}
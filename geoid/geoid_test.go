@@ -0,0 +1,150 @@
+package geoid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// linearGRD returns a grid in the GRD format with 90 degree steps whose
+// undulation at each point is lat + lng/1000, with a repeated last
+// column at 360 degrees east.
+func linearGRD() string {
+	var b strings.Builder
+	fmt.Fprintln(&b, "-90 90 0 360 90 90")
+	for lat := 90.0; lat >= -90; lat -= 90 {
+		for lng := 0.0; lng <= 360; lng += 90 {
+			fmt.Fprintf(&b, "%g ", lat+math.Mod(lng, 360)/1000)
+		}
+		fmt.Fprintln(&b)
+	}
+	return b.String()
+}
+
+func TestReadGRD(t *testing.T) {
+	g, err := ReadGRD(strings.NewReader(linearGRD()))
+	if err != nil {
+		t.Fatalf("ReadGRD() error = %v", err)
+	}
+	tests := []struct {
+		p    geodesy.LatLng
+		want float64
+	}{
+		{geodesy.LatLng{Lat: 0, Lng: 0}, 0},
+		{geodesy.LatLng{Lat: 45, Lng: 45}, 45.045},
+		{geodesy.LatLng{Lat: 90, Lng: 0}, 90},
+		{geodesy.LatLng{Lat: -90, Lng: 180}, -89.82},
+		// Between the last column and the repeated first, and wrapped.
+		{geodesy.LatLng{Lat: 0, Lng: 315}, 0.135},
+		{geodesy.LatLng{Lat: 0, Lng: -45}, 0.135},
+		{geodesy.LatLng{Lat: 0, Lng: 720}, 0},
+	}
+	for _, tt := range tests {
+		if v := g.Undulation(tt.p); math.Abs(v-tt.want) > 1e-9 {
+			t.Errorf("Undulation(%v) = %v, want %v", tt.p, v, tt.want)
+		}
+	}
+}
+
+func TestReadGRDErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"1 2 3",
+		"-90 90 0 360 0 90",
+		"90 -90 0 360 90 90",
+		"0 1 0 1 1 1 0 1 2",
+		"0 1 0 1 1 1 0 1 2 x",
+	}
+	for _, s := range tests {
+		if _, err := ReadGRD(strings.NewReader(s)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ReadGRD(%q) error = %v, want %v", s, err, ErrInvalid)
+		}
+	}
+	boom := errors.New("boom")
+	if _, err := ReadGRD(iotest.ErrReader(boom)); !errors.Is(err, boom) {
+		t.Errorf("ReadGRD(failing reader) error = %v, want %v", err, boom)
+	}
+}
+
+func TestNewGrid(t *testing.T) {
+	tests := []struct {
+		latStep, lngStep float64
+		rows, cols, n    int
+		ok               bool
+	}{
+		{1, 1, 2, 2, 4, true},
+		{1, 1, 1, 2, 2, false},
+		{1, 1, 2, 1, 2, false},
+		{0, 1, 2, 2, 4, false},
+		{1, -1, 2, 2, 4, false},
+		{math.NaN(), 1, 2, 2, 4, false},
+		{1, 1, 2, 2, 3, false},
+	}
+	for _, tt := range tests {
+		_, err := NewGrid(0, 0, tt.latStep, tt.lngStep, tt.rows, tt.cols, make([]float64, tt.n))
+		if tt.ok && err != nil || !tt.ok && !errors.Is(err, ErrInvalid) {
+			t.Errorf("NewGrid(%v, %v, %d, %d, %d values) error = %v", tt.latStep, tt.lngStep, tt.rows, tt.cols, tt.n, err)
+		}
+	}
+}
+
+func TestUndulation(t *testing.T) {
+	// A regional grid of 2 x 2 points a degree apart, and a global grid
+	// without a repeated column.
+	regional, _ := NewGrid(10, 20, 1, 1, 2, 2, []float64{0, 1, 2, 3})
+	global, _ := NewGrid(0, 0, 1, 90, 2, 4, []float64{0, 1, 2, 3, 10, 11, 12, 13})
+	nan := math.NaN()
+	tests := []struct {
+		g    *Grid
+		p    geodesy.LatLng
+		want float64
+	}{
+		{regional, geodesy.LatLng{Lat: 10, Lng: 20}, 0},
+		{regional, geodesy.LatLng{Lat: 11, Lng: 21}, 3},
+		{regional, geodesy.LatLng{Lat: 10.5, Lng: 20.5}, 1.5},
+		{regional, geodesy.LatLng{Lat: 10.25, Lng: 21}, 1.5},
+		{regional, geodesy.LatLng{Lat: 11.001, Lng: 20}, nan},
+		{regional, geodesy.LatLng{Lat: 9.999, Lng: 20}, nan},
+		{regional, geodesy.LatLng{Lat: 10, Lng: 21.001}, nan},
+		{regional, geodesy.LatLng{Lat: 10, Lng: 19.999}, nan},
+		{regional, geodesy.LatLng{Lat: nan, Lng: 20}, nan},
+		{global, geodesy.LatLng{Lat: 0.5, Lng: 45}, 5.5},
+		{global, geodesy.LatLng{Lat: 0.5, Lng: 315}, 6.5},
+		{global, geodesy.LatLng{Lat: 1, Lng: -90}, 13},
+		{global, geodesy.LatLng{Lat: 2, Lng: 0}, nan},
+	}
+	for _, tt := range tests {
+		v := tt.g.Undulation(tt.p)
+		if math.IsNaN(tt.want) && !math.IsNaN(v) || !math.IsNaN(tt.want) && math.Abs(v-tt.want) > 1e-12 {
+			t.Errorf("Undulation(%v) = %v, want %v", tt.p, v, tt.want)
+		}
+	}
+}
+
+func TestHeights(t *testing.T) {
+	// Bilinear interpolation is exact for a linear function, which the
+	// undulations of linearGRD are up to 270 degrees east.
+	g, _ := ReadGRD(strings.NewReader(linearGRD()))
+	r := rand.New(rand.NewSource(50))
+	for range 1000 {
+		p := geodesy.LatLng{Lat: r.Float64()*180 - 90, Lng: r.Float64() * 270}
+		n := p.Lat + p.Lng/1000
+		h := r.Float64()*9000 - 500
+		if v := g.Undulation(p); math.Abs(v-n) > 1e-9 {
+			t.Fatalf("Undulation(%v) = %v, want %v", p, v, n)
+		}
+		o := g.OrthometricHeight(p, h)
+		if math.Abs(o-(h-n)) > 1e-9 {
+			t.Fatalf("OrthometricHeight(%v, %v) = %v, want %v", p, h, o, h-n)
+		}
+		if e := g.EllipsoidalHeight(p, o); math.Abs(e-h) > 1e-9 {
+			t.Fatalf("EllipsoidalHeight(%v, %v) = %v, want %v", p, o, e, h)
+		}
+	}
+}
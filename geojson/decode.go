@@ -0,0 +1,257 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// UnmarshalGeometry returns the geometry of a GeoJSON geometry object,
+// and its layout: XYZ if any of its positions has an elevation, and XY
+// otherwise. A Point with no coordinates, or a position of a MultiPoint
+// with none, is read as an empty point. Members of the object other than
+// those of its type are ignored. It returns an error wrapping ErrInvalid
+// if the document is not a geometry object.
+func UnmarshalGeometry(data []byte) (geom.Geometry, geom.Layout, error) {
+	var r reader
+	g, err := r.geometry(data)
+	if err != nil {
+		return nil, geom.XY, err
+	}
+	return g, r.layout(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading a GeoJSON Feature
+// whose geometry is read as by UnmarshalGeometry. A geometry which is
+// null or missing is read as nil.
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	var o struct {
+		Type       string          `json:"type"`
+		ID         json.RawMessage `json:"id"`
+		Geometry   json.RawMessage `json:"geometry"`
+		Properties map[string]any  `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return invalid(err)
+	}
+	if o.Type != "Feature" {
+		return fmt.Errorf("%w: type %q is not Feature", ErrInvalid, o.Type)
+	}
+	var id any
+	if len(o.ID) > 0 {
+		if err := json.Unmarshal(o.ID, &id); err != nil {
+			return invalid(err)
+		}
+		switch id.(type) {
+		case nil, string, float64:
+		default:
+			return fmt.Errorf("%w: feature id %s is not a string or a number", ErrInvalid, o.ID)
+		}
+	}
+	var r reader
+	var g geom.Geometry
+	if !isNull(o.Geometry) {
+		var err error
+		if g, err = r.geometry(o.Geometry); err != nil {
+			return err
+		}
+	}
+	*f = Feature{ID: id, Geometry: g, Layout: r.layout(), Properties: o.Properties}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading a GeoJSON
+// FeatureCollection whose features are read as by Feature.UnmarshalJSON.
+func (c *FeatureCollection) UnmarshalJSON(data []byte) error {
+	var o struct {
+		Type     string     `json:"type"`
+		Features []*Feature `json:"features"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return invalid(err)
+	}
+	if o.Type != "FeatureCollection" {
+		return fmt.Errorf("%w: type %q is not FeatureCollection", ErrInvalid, o.Type)
+	}
+	for i, f := range o.Features {
+		if f == nil {
+			return fmt.Errorf("%w: feature %d is null", ErrInvalid, i)
+		}
+	}
+	c.Features = o.Features
+	return nil
+}
+
+// invalid returns an error of encoding/json wrapped with ErrInvalid,
+// unless it already is.
+func invalid(err error) error {
+	if errors.Is(err, ErrInvalid) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrInvalid, err)
+}
+
+// isNull reports whether a JSON value is missing or null.
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+// geometryTypes maps the names of the types of geometries other than
+// GeometryCollection to the types.
+var geometryTypes = map[string]geom.Type{
+	"Point":           geom.TypePoint,
+	"LineString":      geom.TypeLineString,
+	"Polygon":         geom.TypePolygon,
+	"MultiPoint":      geom.TypeMultiPoint,
+	"MultiLineString": geom.TypeMultiLineString,
+	"MultiPolygon":    geom.TypeMultiPolygon,
+}
+
+// reader reads geometries, noting whether any position has an
+// elevation.
+type reader struct {
+	z bool
+}
+
+// layout returns the layout of the positions read.
+func (r *reader) layout() geom.Layout {
+	if r.z {
+		return geom.XYZ
+	}
+	return geom.XY
+}
+
+// geometry reads a geometry object.
+func (r *reader) geometry(data []byte) (geom.Geometry, error) {
+	var o struct {
+		Type        string            `json:"type"`
+		Coordinates json.RawMessage   `json:"coordinates"`
+		Geometries  []json.RawMessage `json:"geometries"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, invalid(err)
+	}
+	if o.Type == "GeometryCollection" {
+		if o.Geometries == nil {
+			return nil, fmt.Errorf("%w: GeometryCollection has no geometries", ErrInvalid)
+		}
+		c := make(geom.GeometryCollection, len(o.Geometries))
+		for i, m := range o.Geometries {
+			var err error
+			if c[i], err = r.geometry(m); err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
+	}
+	t, ok := geometryTypes[o.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown geometry type %q", ErrInvalid, o.Type)
+	}
+	if isNull(o.Coordinates) {
+		return nil, fmt.Errorf("%w: %s has no coordinates", ErrInvalid, o.Type)
+	}
+	var err error
+	unmarshal := func(v any) bool {
+		if err = json.Unmarshal(o.Coordinates, v); err != nil {
+			err = invalid(err)
+		}
+		return err == nil
+	}
+	switch t {
+	case geom.TypePoint:
+		var c []float64
+		if unmarshal(&c) {
+			return r.point(c)
+		}
+	case geom.TypeLineString:
+		var c [][]float64
+		if unmarshal(&c) {
+			return r.line(c)
+		}
+	case geom.TypePolygon:
+		var c [][][]float64
+		if unmarshal(&c) {
+			return r.polygon(c)
+		}
+	case geom.TypeMultiPoint:
+		var c [][]float64
+		if unmarshal(&c) {
+			m := make(geom.MultiPoint, len(c))
+			for i, p := range c {
+				if m[i], err = r.point(p); err != nil {
+					return nil, err
+				}
+			}
+			return m, nil
+		}
+	case geom.TypeMultiLineString:
+		var c [][][]float64
+		if unmarshal(&c) {
+			m := make(geom.MultiLineString, len(c))
+			for i, l := range c {
+				if m[i], err = r.line(l); err != nil {
+					return nil, err
+				}
+			}
+			return m, nil
+		}
+	case geom.TypeMultiPolygon:
+		var c [][][][]float64
+		if unmarshal(&c) {
+			m := make(geom.MultiPolygon, len(c))
+			for i, p := range c {
+				if m[i], err = r.polygon(p); err != nil {
+					return nil, err
+				}
+			}
+			return m, nil
+		}
+	}
+	return nil, err
+}
+
+// point reads a position, or an empty point if it has no coordinates.
+func (r *reader) point(c []float64) (geom.Point, error) {
+	switch len(c) {
+	case 0:
+		return geom.EmptyPoint(), nil
+	case 1:
+		return geom.Point{}, fmt.Errorf("%w: position %v has a single coordinate", ErrInvalid, c)
+	case 2:
+		return geom.Point{X: c[0], Y: c[1]}, nil
+	}
+	r.z = true
+	return geom.Point{X: c[0], Y: c[1], Z: c[2]}, nil
+}
+
+// line reads an array of positions, none of which may be empty.
+func (r *reader) line(c [][]float64) (geom.LineString, error) {
+	l := make(geom.LineString, len(c))
+	for i, p := range c {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("%w: position %d of a line has no coordinates", ErrInvalid, i)
+		}
+		var err error
+		if l[i], err = r.point(p); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// polygon reads an array of rings.
+func (r *reader) polygon(c [][][]float64) (geom.Polygon, error) {
+	p := make(geom.Polygon, len(c))
+	for i, ring := range c {
+		l, err := r.line(ring)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = geom.Ring(l)
+	}
+	return p, nil
+}
@@ -0,0 +1,128 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestUnmarshalGeometry(t *testing.T) {
+	tests := []struct {
+		json   string
+		g      geom.Geometry
+		layout geom.Layout
+	}{
+		{`{"type":"Point","coordinates":[1,2,3]}`, geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ},
+		// A measure has no place in GeoJSON, and is ignored.
+		{`{"type":"Point","coordinates":[1,2,3,4]}`, geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ},
+		{`{"type":"LineString","coordinates":[[1,2],[3,4,5]]}`, geom.LineString{{X: 1, Y: 2}, {X: 3, Y: 4, Z: 5}}, geom.XYZ},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2,3]}]}`,
+			geom.GeometryCollection{geom.Point{X: 1, Y: 2, Z: 3}}, geom.XYZ},
+		{`{"coordinates":[1.5e2,-2E-1],"bbox":[0,0,1,1],"type":"Point","crs":null}`, geom.Point{X: 150, Y: -0.2}, geom.XY},
+		// Unclosed rings are read as they are.
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1]]]}`, geom.Polygon{geom.Ring(pts(0, 0, 1, 0, 1, 1))}, geom.XY},
+		{`{"type":"LineString","coordinates":[]}`, geom.LineString{}, geom.XY},
+		{`{"type":"MultiPolygon","coordinates":[]}`, geom.MultiPolygon{}, geom.XY},
+	}
+	for _, tt := range tests {
+		g, layout, err := UnmarshalGeometry([]byte(tt.json))
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != tt.layout {
+			t.Errorf("UnmarshalGeometry(%s) = %v, %v, %v, want %v, %v", tt.json, g, layout, err, tt.g, tt.layout)
+		}
+	}
+	g, _, err := UnmarshalGeometry([]byte(`{"type":"MultiPoint","coordinates":[[],[1,2]]}`))
+	if m, ok := g.(geom.MultiPoint); err != nil || !ok || len(m) != 2 || !m[0].IsEmpty() || m[1] != (geom.Point{X: 1, Y: 2}) {
+		t.Errorf("UnmarshalGeometry(empty position) = %v, %v", g, err)
+	}
+}
+
+func TestUnmarshalGeometryErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`[]`,
+		`{"type":"Point","coordinates":[1,2]`,
+		`{"type":"Circle","coordinates":[1,2]}`,
+		`{"coordinates":[1,2]}`,
+		`{"type":"Point"}`,
+		`{"type":"Point","coordinates":null}`,
+		`{"type":"Point","coordinates":[1]}`,
+		`{"type":"Point","coordinates":["1","2"]}`,
+		`{"type":"Point","coordinates":[[1,2]]}`,
+		`{"type":"LineString","coordinates":[[1,2],[]]}`,
+		`{"type":"Polygon","coordinates":[[[1,2],[3]]]}`,
+		`{"type":"MultiPoint","coordinates":[[1]]}`,
+		`{"type":"MultiLineString","coordinates":[[[]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[1,2]]]}`,
+		`{"type":"GeometryCollection"}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point"}]}`,
+		`{"type":"Feature","geometry":null,"properties":null}`,
+	}
+	for _, s := range tests {
+		if g, _, err := UnmarshalGeometry([]byte(s)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("UnmarshalGeometry(%s) = %v, %v, want %v", s, g, err, ErrInvalid)
+		}
+	}
+}
+
+func TestUnmarshalFeature(t *testing.T) {
+	tests := []struct {
+		json string
+		f    Feature
+	}{
+		{`{"type":"Feature","id":12,"geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{"a":[1,"b"]}}`,
+			Feature{ID: 12.0, Geometry: geom.Point{X: 1, Y: 2, Z: 3}, Layout: geom.XYZ, Properties: map[string]any{"a": []any{1.0, "b"}}}},
+		{`{"type":"Feature","geometry":null,"properties":null}`, Feature{}},
+		{`{"type":"Feature","id":null}`, Feature{}},
+		{`{"type":"Feature","id":"x","geometry":{"type":"Point","coordinates":[]},"properties":{}}`,
+			Feature{ID: "x", Geometry: nil, Properties: map[string]any{}}},
+	}
+	for i, tt := range tests {
+		var f Feature
+		err := json.Unmarshal([]byte(tt.json), &f)
+		if i == 3 {
+			// An empty point is not comparable with DeepEqual.
+			if p, ok := f.Geometry.(geom.Point); err != nil || !ok || !p.IsEmpty() || f.ID != "x" {
+				t.Errorf("Unmarshal(%s) = %+v, %v", tt.json, f, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(f, tt.f) {
+			t.Errorf("Unmarshal(%s) = %+v, %v, want %+v", tt.json, f, err, tt.f)
+		}
+	}
+	for _, s := range []string{
+		`{"type":"feature","geometry":null,"properties":null}`,
+		`{"geometry":null,"properties":null}`,
+		`{"type":"Feature","id":true,"geometry":null}`,
+		`{"type":"Feature","id":{"a":1},"geometry":null}`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1]}}`,
+		`{"type":"Feature","properties":[1]}`,
+		`[1]`,
+	} {
+		var f Feature
+		if err := json.Unmarshal([]byte(s), &f); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Unmarshal(%s) error = %v, want %v", s, err, ErrInvalid)
+		}
+	}
+}
+
+func TestUnmarshalFeatureCollection(t *testing.T) {
+	var c FeatureCollection
+	if err := json.Unmarshal([]byte(`{"type":"FeatureCollection","features":[]}`), &c); err != nil || c.Features == nil || len(c.Features) != 0 {
+		t.Errorf("Unmarshal(empty) = %+v, %v", c, err)
+	}
+	for _, s := range []string{
+		`{"type":"Feature","features":[]}`,
+		`{"type":"FeatureCollection","features":[null]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Point"}]}`,
+		`{"type":"FeatureCollection","features":{}}`,
+	} {
+		var c FeatureCollection
+		if err := json.Unmarshal([]byte(s), &c); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Unmarshal(%s) error = %v, want %v", s, err, ErrInvalid)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Package geojson encodes and decodes geometries, features and feature
+// collections as GeoJSON, the format of RFC 7946.
+//
+// Features and FeatureCollections implement json.Marshaler and
+// json.Unmarshaler, so they may be used with encoding/json directly,
+// while the geometries of package geom, which know nothing of JSON, are
+// marshaled and unmarshaled by MarshalGeometry and UnmarshalGeometry.
+// A Format controls the output: the precision of the coordinates, the
+// bounding boxes written, and the winding of the rings of polygons.
+//
+// Positions are written as [longitude, latitude], or as [longitude,
+// latitude, elevation] for a geometry whose Layout has Z. GeoJSON has no
+// place for a measure, so M is never written, and the elements of a
+// position beyond the third are ignored when it is read. Geometries are
+// read as they are written, without validation, so that rings which are
+// not closed or do not follow the right-hand rule are read unchanged;
+// geom.Validate and geom.Orient may be used to check and repair them.
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gogama/geospat/geom"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, when a document is not valid
+	// GeoJSON, or not of the object expected.
+	ErrInvalid = errors.New("geojson: invalid GeoJSON")
+
+	// ErrUnsupported is returned, wrapped, when a value cannot be written
+	// as GeoJSON, such as a coordinate which is not finite.
+	ErrUnsupported = errors.New("geojson: unsupported value")
+)
+
+// Feature is a GeoJSON Feature: a geometry with an identifier and a set
+// of properties.
+type Feature struct {
+	// ID is the identifier of the feature, a string or a number, or nil
+	// if it has none. A number is read as a float64.
+	ID any
+
+	// Geometry is the geometry of the feature, or nil for a feature
+	// which is not located.
+	Geometry geom.Geometry
+
+	// Layout is the layout of the positions of the geometry. A geometry
+	// is written with elevations only if its layout has Z, and is read
+	// with layout XYZ if any of its positions has an elevation, and XY
+	// otherwise.
+	Layout geom.Layout
+
+	// Properties are the properties of the feature, as marshaled and
+	// unmarshaled by encoding/json, or nil for none.
+	Properties map[string]any
+}
+
+// MarshalJSON implements json.Marshaler with the zero Format.
+func (f Feature) MarshalJSON() ([]byte, error) {
+	return Format{}.MarshalFeature(&f)
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection: a list of features.
+type FeatureCollection struct {
+	Features []*Feature
+}
+
+// MarshalJSON implements json.Marshaler with the zero Format.
+func (c FeatureCollection) MarshalJSON() ([]byte, error) {
+	return Format{}.MarshalFeatureCollection(&c)
+}
+
+// Format is the way geometries and features are written as GeoJSON. The
+// zero value writes coordinates with as many digits as they need to be
+// read back exactly, and writes the rings of polygons as they are.
+type Format struct {
+	// Precision, if positive, is the number of digits after the decimal
+	// point to which coordinates are rounded, with trailing zeros
+	// omitted. RFC 7946 suggests 6, which for degrees is about 10
+	// centimeters. Otherwise coordinates are written with the fewest
+	// digits that read back as the same float64.
+	Precision int
+
+	// BBox, if true, writes a "bbox" member with the bounds of every
+	// feature collection, every feature with a geometry which is not
+	// empty, and every geometry marshaled on its own, including
+	// elevations if the layout has Z. The bounds of geometries are
+	// rectangles in the plane, which do not cross the antimeridian.
+	BBox bool
+
+	// Orient, if true, writes the rings of polygons by the right-hand
+	// rule of RFC 7946, exterior rings counterclockwise and holes
+	// clockwise, as by geom.Orient.
+	Orient bool
+}
+
+// MarshalGeometry returns the GeoJSON of a geometry with the zero
+// Format, with positions of the given layout.
+func MarshalGeometry(g geom.Geometry, layout geom.Layout) ([]byte, error) {
+	return Format{}.MarshalGeometry(g, layout)
+}
+
+// MarshalGeometry returns the GeoJSON of a geometry, with positions of
+// the given layout, or "null" if the geometry is nil. It returns an
+// error wrapping ErrUnsupported if a coordinate is not finite, including
+// one of an empty point in a line string or polygon, which GeoJSON
+// cannot represent. An empty Point, or an empty point of a MultiPoint,
+// is written with no coordinates, as [].
+func (f Format) MarshalGeometry(g geom.Geometry, layout geom.Layout) ([]byte, error) {
+	w := writer{Format: f}
+	if err := w.geometry(g, layout.HasZ(), f.BBox); err != nil {
+		return nil, err
+	}
+	return w.b, nil
+}
+
+// MarshalFeature returns the GeoJSON of a feature, as for
+// MarshalGeometry. It returns an error wrapping ErrUnsupported if the ID
+// is not a string or a number, and any error of encoding/json in
+// marshaling the properties.
+func (f Format) MarshalFeature(ft *Feature) ([]byte, error) {
+	w := writer{Format: f}
+	if err := w.feature(ft); err != nil {
+		return nil, err
+	}
+	return w.b, nil
+}
+
+// MarshalFeatureCollection returns the GeoJSON of a feature collection,
+// as for MarshalFeature.
+func (f Format) MarshalFeatureCollection(c *FeatureCollection) ([]byte, error) {
+	w := writer{Format: f}
+	w.b = append(w.b, `{"type":"FeatureCollection"`...)
+	if f.BBox {
+		var all geom.GeometryCollection
+		z := true
+		for _, ft := range c.Features {
+			if ft != nil && ft.Geometry != nil && !ft.Geometry.IsEmpty() {
+				all = append(all, ft.Geometry)
+				z = z && ft.Layout.HasZ()
+			}
+		}
+		if err := w.bbox(all, z); err != nil {
+			return nil, err
+		}
+	}
+	w.b = append(w.b, `,"features":[`...)
+	for i, ft := range c.Features {
+		if i > 0 {
+			w.b = append(w.b, ',')
+		}
+		if ft == nil {
+			return nil, fmt.Errorf("%w: feature %d is nil", ErrUnsupported, i)
+		}
+		if err := w.feature(ft); err != nil {
+			return nil, err
+		}
+	}
+	w.b = append(w.b, "]}"...)
+	return w.b, nil
+}
+
+// writer accumulates GeoJSON written in a Format.
+type writer struct {
+	Format
+	b []byte
+}
+
+// feature writes a feature.
+func (w *writer) feature(f *Feature) error {
+	w.b = append(w.b, `{"type":"Feature"`...)
+	if f.ID != nil {
+		id, err := json.Marshal(f.ID)
+		if err != nil {
+			return err
+		}
+		if c := id[0]; c != '"' && c != '-' && (c < '0' || c > '9') {
+			return fmt.Errorf("%w: feature id %s is not a string or a number", ErrUnsupported, id)
+		}
+		w.b = append(w.b, `,"id":`...)
+		w.b = append(w.b, id...)
+	}
+	if w.BBox && f.Geometry != nil {
+		if err := w.bbox(f.Geometry, f.Layout.HasZ()); err != nil {
+			return err
+		}
+	}
+	w.b = append(w.b, `,"geometry":`...)
+	if err := w.geometry(f.Geometry, f.Layout.HasZ(), false); err != nil {
+		return err
+	}
+	props, err := json.Marshal(f.Properties)
+	if err != nil {
+		return err
+	}
+	w.b = append(w.b, `,"properties":`...)
+	w.b = append(w.b, props...)
+	w.b = append(w.b, '}')
+	return nil
+}
+
+// geometry writes a geometry, oriented if the format says so, with
+// elevations if z is true, and with its bounds if bbox is true.
+func (w *writer) geometry(g geom.Geometry, z, bbox bool) error {
+	if g == nil {
+		w.b = append(w.b, "null"...)
+		return nil
+	}
+	if w.Orient {
+		g = geom.Orient(g, geom.Counterclockwise)
+	}
+	return w.object(g, z, bbox)
+}
+
+// object writes the GeoJSON object of a geometry.
+func (w *writer) object(g geom.Geometry, z, bbox bool) error {
+	switch g.(type) {
+	case geom.Point, geom.LineString, geom.Polygon, geom.MultiPoint,
+		geom.MultiLineString, geom.MultiPolygon, geom.GeometryCollection:
+	default:
+		return fmt.Errorf("%w: geometry of type %T", ErrUnsupported, g)
+	}
+	w.b = append(w.b, `{"type":"`...)
+	w.b = append(w.b, g.Type().String()...)
+	w.b = append(w.b, '"')
+	if bbox {
+		if err := w.bbox(g, z); err != nil {
+			return err
+		}
+	}
+	if c, ok := g.(geom.GeometryCollection); ok {
+		w.b = append(w.b, `,"geometries":[`...)
+		for i, m := range c {
+			if i > 0 {
+				w.b = append(w.b, ',')
+			}
+			if m == nil {
+				return fmt.Errorf("%w: member %d of a GeometryCollection is nil", ErrUnsupported, i)
+			}
+			if err := w.object(m, z, false); err != nil {
+				return err
+			}
+		}
+		w.b = append(w.b, "]}"...)
+		return nil
+	}
+	w.b = append(w.b, `,"coordinates":`...)
+	if err := w.coordinates(g, z); err != nil {
+		return err
+	}
+	w.b = append(w.b, '}')
+	return nil
+}
+
+// coordinates writes the coordinates of a geometry other than a
+// GeometryCollection.
+func (w *writer) coordinates(g geom.Geometry, z bool) error {
+	switch g := g.(type) {
+	case geom.Point:
+		if g.IsEmpty() {
+			w.b = append(w.b, "[]"...)
+			return nil
+		}
+		return w.position(g, z)
+	case geom.LineString:
+		return w.positions(g, z)
+	case geom.Polygon:
+		return w.array(len(g), func(i int) error { return w.positions(g[i], z) })
+	case geom.MultiPoint:
+		return w.array(len(g), func(i int) error { return w.coordinates(g[i], z) })
+	case geom.MultiLineString:
+		return w.array(len(g), func(i int) error { return w.positions(g[i], z) })
+	case geom.MultiPolygon:
+		return w.array(len(g), func(i int) error { return w.coordinates(g[i], z) })
+	}
+	return nil
+}
+
+// array writes an array of n elements written by elem.
+func (w *writer) array(n int, elem func(i int) error) error {
+	w.b = append(w.b, '[')
+	for i := range n {
+		if i > 0 {
+			w.b = append(w.b, ',')
+		}
+		if err := elem(i); err != nil {
+			return err
+		}
+	}
+	w.b = append(w.b, ']')
+	return nil
+}
+
+// positions writes an array of positions.
+func (w *writer) positions(ps []geom.Point, z bool) error {
+	return w.array(len(ps), func(i int) error { return w.position(ps[i], z) })
+}
+
+// position writes a position.
+func (w *writer) position(p geom.Point, z bool) error {
+	cs := []float64{p.X, p.Y}
+	if z {
+		cs = append(cs, p.Z)
+	}
+	return w.numbers(cs)
+}
+
+// numbers writes an array of numbers.
+func (w *writer) numbers(vs []float64) error {
+	return w.array(len(vs), func(i int) error { return w.number(vs[i]) })
+}
+
+// number writes a number, rounded to the precision of the format.
+func (w *writer) number(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("%w: coordinate %g is not finite", ErrUnsupported, v)
+	}
+	if w.Precision <= 0 {
+		// Use exponents for the numbers which would otherwise have many
+		// zeros, as does encoding/json.
+		if a := math.Abs(v); a != 0 && (a < 1e-6 || a >= 1e21) {
+			w.b = strconv.AppendFloat(w.b, v, 'e', -1, 64)
+		} else {
+			w.b = strconv.AppendFloat(w.b, v, 'f', -1, 64)
+		}
+		return nil
+	}
+	n := len(w.b)
+	w.b = strconv.AppendFloat(w.b, v, 'f', w.Precision, 64)
+	for w.b[len(w.b)-1] == '0' {
+		w.b = w.b[:len(w.b)-1]
+	}
+	if w.b[len(w.b)-1] == '.' {
+		w.b = w.b[:len(w.b)-1]
+	}
+	if string(w.b[n:]) == "-0" {
+		w.b = append(w.b[:n], '0')
+	}
+	return nil
+}
+
+// bbox writes the "bbox" member of a geometry with elevations if z is
+// true, or nothing if the geometry is empty.
+func (w *writer) bbox(g geom.Geometry, z bool) error {
+	r := geom.Bounds(g)
+	if r.IsEmpty() {
+		return nil
+	}
+	w.b = append(w.b, `,"bbox":`...)
+	if !z {
+		return w.numbers([]float64{r.MinX, r.MinY, r.MaxX, r.MaxY})
+	}
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	for p := range geom.Points(g) {
+		minZ, maxZ = min(minZ, p.Z), max(maxZ, p.Z)
+	}
+	return w.numbers([]float64{r.MinX, r.MinY, minZ, r.MaxX, r.MaxY, maxZ})
+}
@@ -0,0 +1,211 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func pts(c ...float64) []geom.Point {
+	ps := make([]geom.Point, len(c)/2)
+	for i := range ps {
+		ps[i] = geom.Point{X: c[2*i], Y: c[2*i+1]}
+	}
+	return ps
+}
+
+// The examples of geometries of RFC 7946, Appendix A.
+var rfcTests = []struct {
+	name string
+	g    geom.Geometry
+	json string
+}{
+	{"Point", geom.Point{X: 100, Y: 0}, `{"type":"Point","coordinates":[100,0]}`},
+	{"LineString", geom.LineString(pts(100, 0, 101, 1)), `{"type":"LineString","coordinates":[[100,0],[101,1]]}`},
+	{"Polygon", geom.Polygon{pts(100, 0, 101, 0, 101, 1, 100, 1, 100, 0)},
+		`{"type":"Polygon","coordinates":[[[100,0],[101,0],[101,1],[100,1],[100,0]]]}`},
+	{"Polygon with holes", geom.Polygon{pts(100, 0, 101, 0, 101, 1, 100, 1, 100, 0), pts(100.8, 0.8, 100.8, 0.2, 100.2, 0.2, 100.2, 0.8, 100.8, 0.8)},
+		`{"type":"Polygon","coordinates":[[[100,0],[101,0],[101,1],[100,1],[100,0]],[[100.8,0.8],[100.8,0.2],[100.2,0.2],[100.2,0.8],[100.8,0.8]]]}`},
+	{"MultiPoint", geom.MultiPoint(pts(100, 0, 101, 1)), `{"type":"MultiPoint","coordinates":[[100,0],[101,1]]}`},
+	{"MultiLineString", geom.MultiLineString{pts(100, 0, 101, 1), pts(102, 2, 103, 3)},
+		`{"type":"MultiLineString","coordinates":[[[100,0],[101,1]],[[102,2],[103,3]]]}`},
+	{"MultiPolygon", geom.MultiPolygon{
+		{pts(102, 2, 103, 2, 103, 3, 102, 3, 102, 2)},
+		{pts(100, 0, 101, 0, 101, 1, 100, 1, 100, 0), pts(100.2, 0.2, 100.2, 0.8, 100.8, 0.8, 100.8, 0.2, 100.2, 0.2)},
+	}, `{"type":"MultiPolygon","coordinates":[[[[102,2],[103,2],[103,3],[102,3],[102,2]]],[[[100,0],[101,0],[101,1],[100,1],[100,0]],[[100.2,0.2],[100.2,0.8],[100.8,0.8],[100.8,0.2],[100.2,0.2]]]]}`},
+	{"GeometryCollection", geom.GeometryCollection{geom.Point{X: 100, Y: 0}, geom.LineString(pts(101, 0, 102, 1))},
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[100,0]},{"type":"LineString","coordinates":[[101,0],[102,1]]}]}`},
+}
+
+func TestMarshalGeometry(t *testing.T) {
+	for _, tt := range rfcTests {
+		b, err := MarshalGeometry(tt.g, geom.XY)
+		if err != nil || string(b) != tt.json {
+			t.Errorf("%s: MarshalGeometry() = %s, %v, want %s", tt.name, b, err, tt.json)
+		}
+		g, layout, err := UnmarshalGeometry([]byte(tt.json))
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != geom.XY {
+			t.Errorf("%s: UnmarshalGeometry() = %v, %v, %v, want %v", tt.name, g, layout, err, tt.g)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cw := geom.Polygon{pts(0, 0, 0, 1, 1, 1, 1, 0, 0, 0)}
+	tests := []struct {
+		f      Format
+		g      geom.Geometry
+		layout geom.Layout
+		json   string
+	}{
+		{Format{}, geom.Point{X: 1.0 / 3, Y: -1e-7}, geom.XY, `{"type":"Point","coordinates":[0.3333333333333333,-1e-07]}`},
+		{Format{}, geom.Point{X: 1e21, Y: 123456789}, geom.XY, `{"type":"Point","coordinates":[1e+21,123456789]}`},
+		{Format{Precision: 6}, geom.Point{X: 1.23456789, Y: -0.0000001}, geom.XY, `{"type":"Point","coordinates":[1.234568,0]}`},
+		{Format{Precision: 2}, geom.Point{X: 10, Y: 2.5}, geom.XY, `{"type":"Point","coordinates":[10,2.5]}`},
+		{Format{}, geom.Point{X: 1, Y: 2, Z: 3, M: 4}, geom.XYZM, `{"type":"Point","coordinates":[1,2,3]}`},
+		{Format{}, geom.Point{X: 1, Y: 2, Z: 3}, geom.XY, `{"type":"Point","coordinates":[1,2]}`},
+		{Format{}, geom.EmptyPoint(), geom.XY, `{"type":"Point","coordinates":[]}`},
+		{Format{}, geom.MultiPoint{geom.EmptyPoint(), {X: 1, Y: 2}}, geom.XY, `{"type":"MultiPoint","coordinates":[[],[1,2]]}`},
+		{Format{}, geom.GeometryCollection{}, geom.XY, `{"type":"GeometryCollection","geometries":[]}`},
+		{Format{}, nil, geom.XY, `null`},
+		{Format{BBox: true}, geom.LineString(pts(1, 5, 3, 2)), geom.XY, `{"type":"LineString","bbox":[1,2,3,5],"coordinates":[[1,5],[3,2]]}`},
+		{Format{BBox: true}, geom.LineString{{X: 1, Y: 5, Z: 7}, {X: 3, Y: 2, Z: -1}}, geom.XYZ,
+			`{"type":"LineString","bbox":[1,2,-1,3,5,7],"coordinates":[[1,5,7],[3,2,-1]]}`},
+		{Format{BBox: true}, geom.LineString{}, geom.XY, `{"type":"LineString","coordinates":[]}`},
+		{Format{}, cw, geom.XY, `{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[1,0],[0,0]]]}`},
+		{Format{Orient: true}, cw, geom.XY, `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`},
+	}
+	for _, tt := range tests {
+		b, err := tt.f.MarshalGeometry(tt.g, tt.layout)
+		if err != nil || string(b) != tt.json {
+			t.Errorf("%+v.MarshalGeometry(%v) = %s, %v, want %s", tt.f, tt.g, b, err, tt.json)
+		}
+	}
+	// Orienting does not modify the geometry.
+	if cw[0][1].X != 0 {
+		t.Errorf("MarshalGeometry modified its geometry to %v", cw)
+	}
+}
+
+type otherGeometry struct{ geom.Point }
+
+func TestMarshalGeometryErrors(t *testing.T) {
+	tests := []geom.Geometry{
+		geom.Point{X: math.Inf(-1), Y: 0},
+		geom.MultiPoint{{X: 1, Y: math.Inf(1)}},
+		geom.Point{X: 0, Y: math.Inf(1)},
+		geom.LineString{{X: 0, Y: 0}, geom.EmptyPoint()},
+		geom.GeometryCollection{geom.Point{}, nil},
+		otherGeometry{},
+	}
+	for _, g := range tests {
+		if b, err := MarshalGeometry(g, geom.XY); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("MarshalGeometry(%v) = %s, %v, want %v", g, b, err, ErrUnsupported)
+		}
+	}
+	if _, err := MarshalGeometry(geom.Point{Z: math.NaN()}, geom.XYZ); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("MarshalGeometry(NaN elevation) error = %v, want %v", err, ErrUnsupported)
+	}
+}
+
+// The example of RFC 7946, Section 1.5.
+const rfcFeatureCollection = `{
+	"type": "FeatureCollection",
+	"features": [{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [102.0, 0.5]},
+		"properties": {"prop0": "value0"}
+	}, {
+		"type": "Feature",
+		"geometry": {
+			"type": "LineString",
+			"coordinates": [[102.0, 0.0], [103.0, 1.0], [104.0, 0.0], [105.0, 1.0]]
+		},
+		"properties": {"prop0": "value0", "prop1": 0.0}
+	}, {
+		"type": "Feature",
+		"geometry": {
+			"type": "Polygon",
+			"coordinates": [[[100.0, 0.0], [101.0, 0.0], [101.0, 1.0], [100.0, 1.0], [100.0, 0.0]]]
+		},
+		"properties": {"prop0": "value0", "prop1": {"this": "that"}}
+	}]
+}`
+
+func TestFeatureCollection(t *testing.T) {
+	var c FeatureCollection
+	if err := json.Unmarshal([]byte(rfcFeatureCollection), &c); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := FeatureCollection{Features: []*Feature{
+		{Geometry: geom.Point{X: 102, Y: 0.5}, Properties: map[string]any{"prop0": "value0"}},
+		{Geometry: geom.LineString(pts(102, 0, 103, 1, 104, 0, 105, 1)), Properties: map[string]any{"prop0": "value0", "prop1": 0.0}},
+		{Geometry: geom.Polygon{pts(100, 0, 101, 0, 101, 1, 100, 1, 100, 0)},
+			Properties: map[string]any{"prop0": "value0", "prop1": map[string]any{"this": "that"}}},
+	}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", c, want)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	const compact = `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[102,0.5]},"properties":{"prop0":"value0"}},` +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[102,0],[103,1],[104,0],[105,1]]},"properties":{"prop0":"value0","prop1":0}},` +
+		`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[100,0],[101,0],[101,1],[100,1],[100,0]]]},"properties":{"prop0":"value0","prop1":{"this":"that"}}}]}`
+	if string(b) != compact {
+		t.Errorf("Marshal() = %s, want %s", b, compact)
+	}
+	// The bounds of the collection are those of its features.
+	b, err = Format{BBox: true}.MarshalFeatureCollection(&c)
+	if err != nil || string(b[:59]) != `{"type":"FeatureCollection","bbox":[100,0,105,1],"features"` {
+		t.Errorf("MarshalFeatureCollection(BBox) = %s, %v", b, err)
+	}
+	if _, err := (Format{}).MarshalFeatureCollection(&FeatureCollection{Features: []*Feature{nil}}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("MarshalFeatureCollection(nil feature) error = %v, want %v", err, ErrUnsupported)
+	}
+}
+
+func TestFeature(t *testing.T) {
+	tests := []struct {
+		f    Feature
+		json string
+	}{
+		{Feature{ID: "a", Geometry: geom.Point{X: 1, Y: 2}}, `{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}`},
+		{Feature{ID: 7.5, Properties: map[string]any{"n": 1.0}}, `{"type":"Feature","id":7.5,"geometry":null,"properties":{"n":1}}`},
+		{Feature{ID: -3.0, Geometry: geom.Point{X: 1, Y: 2, Z: 3}, Layout: geom.XYZ, Properties: map[string]any{}},
+			`{"type":"Feature","id":-3,"geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{}}`},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.f)
+		if err != nil || string(b) != tt.json {
+			t.Errorf("Marshal(%+v) = %s, %v, want %s", tt.f, b, err, tt.json)
+		}
+		var f Feature
+		if err := json.Unmarshal(b, &f); err != nil || !reflect.DeepEqual(f, tt.f) {
+			t.Errorf("Unmarshal(%s) = %+v, %v, want %+v", b, f, err, tt.f)
+		}
+	}
+	b, err := Format{BBox: true}.MarshalFeature(&Feature{Geometry: geom.MultiPoint(pts(1, 2, 3, 4))})
+	if want := `{"type":"Feature","bbox":[1,2,3,4],"geometry":{"type":"MultiPoint","coordinates":[[1,2],[3,4]]},"properties":null}`; err != nil || string(b) != want {
+		t.Errorf("MarshalFeature(BBox) = %s, %v, want %s", b, err, want)
+	}
+	for _, f := range []Feature{{ID: true}, {ID: []int{1}}, {Geometry: geom.Point{X: math.Inf(1)}}} {
+		if b, err := json.Marshal(f); err == nil {
+			t.Errorf("Marshal(%+v) = %s, want an error", f, b)
+		} else if f.Geometry != nil && !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Marshal(%+v) error = %v, want %v", f, err, ErrUnsupported)
+		}
+	}
+	if _, err := (Format{}).MarshalFeature(&Feature{ID: true}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("MarshalFeature(ID true) error = %v, want %v", err, ErrUnsupported)
+	}
+	if _, err := (Format{}).MarshalFeature(&Feature{Properties: map[string]any{"c": make(chan int)}}); err == nil {
+		t.Errorf("MarshalFeature(channel property) error = nil")
+	}
+}
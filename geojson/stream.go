@@ -0,0 +1,184 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Decoder reads the features of a FeatureCollection from a stream one at
+// a time, holding no more than one feature in memory at once, so that it
+// may read documents far larger than would fit in memory.
+type Decoder struct {
+	d *json.Decoder
+	// inFeatures is true once the array of features has been entered.
+	inFeatures bool
+	// typ is the type of the collection, if it has been read.
+	typ string
+	err error
+}
+
+// NewDecoder returns a decoder which reads from r. The decoder buffers
+// its reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{d: json.NewDecoder(r)}
+}
+
+// Next returns the next feature of the collection, decoded as by
+// Feature.UnmarshalJSON, or io.EOF after the last feature has been read
+// and the rest of the collection found valid. It returns an error
+// wrapping ErrInvalid if the document is not a FeatureCollection, or
+// the error of reading from the stream, and returns the same error on
+// every call after that.
+//
+// Members of the collection other than "type" and "features", such as
+// "bbox", are skipped without being held in memory, whether they come
+// before or after the features.
+func (d *Decoder) Next() (*Feature, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	f, err := d.next()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			err = invalid(err)
+		}
+		d.err = err
+		return nil, err
+	}
+	return f, nil
+}
+
+// All returns an iterator over the remaining features of the collection,
+// as read by Next. If Next returns an error other than io.EOF, it is
+// yielded with a nil feature, after which the iteration stops.
+func (d *Decoder) All() iter.Seq2[*Feature, error] {
+	return func(yield func(*Feature, error) bool) {
+		for {
+			f, err := d.Next()
+			if errors.Is(err, io.EOF) || !yield(f, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// next reads the next feature, or the rest of the collection and returns
+// io.EOF.
+func (d *Decoder) next() (*Feature, error) {
+	if !d.inFeatures {
+		if err := d.expect(json.Delim('{')); err != nil {
+			return nil, err
+		}
+		if found, err := d.members(); err != nil {
+			return nil, err
+		} else if !found {
+			return nil, d.end()
+		}
+		d.inFeatures = true
+	}
+	if d.d.More() {
+		f := new(Feature)
+		if err := d.d.Decode(f); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	if err := d.expect(json.Delim(']')); err != nil {
+		return nil, err
+	}
+	if _, err := d.members(); err != nil {
+		return nil, err
+	}
+	return nil, d.end()
+}
+
+// members reads the members of the collection up to the start of the
+// array of features, returning true, or up to its end, returning false.
+func (d *Decoder) members() (bool, error) {
+	for d.d.More() {
+		t, err := d.d.Token()
+		if err != nil {
+			return false, unexpected(err)
+		}
+		switch t {
+		case "type":
+			if err := d.d.Decode(&d.typ); err != nil {
+				return false, err
+			}
+			if d.typ != "FeatureCollection" {
+				return false, fmt.Errorf("%w: type %q is not FeatureCollection", ErrInvalid, d.typ)
+			}
+		case "features":
+			if d.inFeatures {
+				return false, fmt.Errorf("%w: FeatureCollection has two features members", ErrInvalid)
+			}
+			if err := d.expect(json.Delim('[')); err != nil {
+				return false, err
+			}
+			return true, nil
+		default:
+			if err := d.skip(); err != nil {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// end reads the end of the collection, returning io.EOF if it is valid.
+func (d *Decoder) end() error {
+	if err := d.expect(json.Delim('}')); err != nil {
+		return err
+	}
+	if d.typ == "" {
+		return fmt.Errorf("%w: FeatureCollection has no type", ErrInvalid)
+	}
+	if !d.inFeatures {
+		return fmt.Errorf("%w: FeatureCollection has no features", ErrInvalid)
+	}
+	return io.EOF
+}
+
+// expect reads a token which must be the given delimiter.
+func (d *Decoder) expect(delim json.Delim) error {
+	t, err := d.d.Token()
+	if err != nil {
+		return unexpected(err)
+	}
+	if t != delim {
+		return fmt.Errorf("%w: found %v where %v was expected", ErrInvalid, t, delim)
+	}
+	return nil
+}
+
+// skip reads a value, token by token, discarding it.
+func (d *Decoder) skip() error {
+	depth := 0
+	for {
+		t, err := d.d.Token()
+		if err != nil {
+			return unexpected(err)
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// unexpected returns an error reading a token, with io.EOF before the
+// end of the collection replaced by io.ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestDecoder(t *testing.T) {
+	var want FeatureCollection
+	if err := json.Unmarshal([]byte(rfcFeatureCollection), &want); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(strings.NewReader(rfcFeatureCollection))
+	for i, w := range want.Features {
+		f, err := d.Next()
+		if err != nil || !reflect.DeepEqual(f, w) {
+			t.Fatalf("Next() %d = %+v, %v, want %+v", i, f, err, w)
+		}
+	}
+	for range 2 {
+		if f, err := d.Next(); f != nil || err != io.EOF {
+			t.Errorf("Next() at the end = %+v, %v, want io.EOF", f, err)
+		}
+	}
+}
+
+func TestDecoderMembers(t *testing.T) {
+	tests := []struct {
+		json string
+		n    int
+	}{
+		{`{"type":"FeatureCollection","features":[]}`, 0},
+		{` { "bbox" : [0, 0, 1, 1], "type": "FeatureCollection", "features": [{"type":"Feature","geometry":null}] } `, 1},
+		// Members after the features, including the type, are read to
+		// check the collection.
+		{`{"features":[{"type":"Feature"},{"type":"Feature"}],"foo":{"a":[1,{"b":null}]},"type":"FeatureCollection"}`, 2},
+		{`{"crs":null,"name":"x","features":[{"type":"Feature"}],"type":"FeatureCollection","n":1}`, 1},
+	}
+	for _, tt := range tests {
+		n := 0
+		for f, err := range NewDecoder(strings.NewReader(tt.json)).All() {
+			if err != nil || f == nil {
+				t.Fatalf("All(%s) yielded %v, %v", tt.json, f, err)
+			}
+			n++
+		}
+		if n != tt.n {
+			t.Errorf("All(%s) yielded %d features, want %d", tt.json, n, tt.n)
+		}
+	}
+}
+
+type errReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if err == io.EOF {
+		err = r.err
+	}
+	return n, err
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		json string
+		n    int // features before the error
+	}{
+		{``, 0},
+		{`[]`, 0},
+		{`"FeatureCollection"`, 0},
+		{`{"type":"Feature","features":[]}`, 0},
+		{`{"type":1,"features":[]}`, 0},
+		{`{"features":[]}`, 0},
+		{`{"type":"FeatureCollection"}`, 0},
+		{`{"type":"FeatureCollection","features":{}}`, 0},
+		{`{"type":"FeatureCollection","features":[{"type":"Feature"}],"features":[]}`, 1},
+		{`{"features":[{"type":"Feature"}],"type":"Feature"}`, 1},
+		{`{"type":"FeatureCollection","features":[{"type":"Feature"},{"type":"Point"}]}`, 1},
+		{`{"type":"FeatureCollection","features":[{"type":"Feature"},null]}`, 1},
+		{`{"type":"FeatureCollection","features":[{"type":"Feature"}`, 1},
+		{`{"type":"FeatureCollection","features":[{"type":"Feature"}]`, 1},
+		{`{"type":"FeatureCollection","bbox":[0,0`, 0},
+	}
+	for _, tt := range tests {
+		d := NewDecoder(strings.NewReader(tt.json))
+		for i := range tt.n {
+			if _, err := d.Next(); err != nil {
+				t.Fatalf("Next() %d of %s error = %v", i, tt.json, err)
+			}
+		}
+		_, err := d.Next()
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("Next() of %s error = %v, want %v", tt.json, err, ErrInvalid)
+		}
+		// The error is returned again.
+		if _, err2 := d.Next(); err2 != err {
+			t.Errorf("Next() of %s after %v error = %v", tt.json, err, err2)
+		}
+	}
+	// A truncated collection does not end as if it were complete.
+	d := NewDecoder(strings.NewReader(`{"type":"FeatureCollection","features":[`))
+	if _, err := d.Next(); err == io.EOF || !errors.Is(err, ErrInvalid) {
+		t.Errorf("Next() of a truncated collection error = %v, want %v", err, ErrInvalid)
+	}
+	// An error of the reader is returned, wrapped.
+	errRead := errors.New("read failed")
+	d = NewDecoder(&errReader{strings.NewReader(`{"type":"FeatureCollection","features":[{"type":"Feature"},`), errRead})
+	if _, err := d.Next(); err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if _, err := d.Next(); !errors.Is(err, errRead) {
+		t.Errorf("Next() error = %v, want %v", err, errRead)
+	}
+}
+
+func TestDecoderAll(t *testing.T) {
+	// An error is yielded, and ends the iteration.
+	var errs []error
+	n := 0
+	for f, err := range NewDecoder(strings.NewReader(`{"type":"FeatureCollection","features":[{"type":"Feature"},{}]}`)).All() {
+		if err != nil {
+			errs = append(errs, err)
+		} else if f != nil {
+			n++
+		}
+	}
+	if n != 1 || len(errs) != 1 || !errors.Is(errs[0], ErrInvalid) {
+		t.Errorf("All() yielded %d features and errors %v, want 1 and one error", n, errs)
+	}
+	// Breaking out leaves the rest to read.
+	d := NewDecoder(strings.NewReader(rfcFeatureCollection))
+	for range d.All() {
+		break
+	}
+	f, err := d.Next()
+	if err != nil || f.Geometry.Type() != geom.TypeLineString {
+		t.Errorf("Next() after breaking = %+v, %v, want the second feature", f, err)
+	}
+}
+
+// features is a reader of a FeatureCollection of n features, generated as
+// it is read, counting the bytes read.
+type features struct {
+	n, i int
+	buf  []byte
+	read int
+}
+
+func (r *features) Read(b []byte) (int, error) {
+	for len(r.buf) == 0 {
+		switch {
+		case r.i == 0:
+			r.buf = []byte(`{"type":"FeatureCollection","features":[`)
+		case r.i <= r.n:
+			if r.i > 1 {
+				r.buf = append(r.buf, ',')
+			}
+			r.buf = fmt.Appendf(r.buf, `{"type":"Feature","id":%d,"geometry":{"type":"Point","coordinates":[%d,1]},"properties":{"name":"feature %[1]d"}}`, r.i, r.i%180)
+		case r.i == r.n+1:
+			r.buf = []byte(`]}`)
+		default:
+			return 0, io.EOF
+		}
+		r.i++
+	}
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	r.read += n
+	return n, nil
+}
+
+func TestDecoderStreams(t *testing.T) {
+	r := &features{n: 100000}
+	d := NewDecoder(r)
+	f, err := d.Next()
+	if err != nil || f.ID != 1.0 {
+		t.Fatalf("Next() = %+v, %v", f, err)
+	}
+	// The decoder has read little of the document of about 10 MB.
+	if r.read > 1<<16 {
+		t.Errorf("Next() read %d bytes for the first feature", r.read)
+	}
+	n := 1
+	for f, err := range d.All() {
+		if err != nil {
+			t.Fatalf("All() error = %v", err)
+		}
+		if n++; f.ID != float64(n) {
+			t.Fatalf("All() feature %d has ID %v", n, f.ID)
+		}
+	}
+	if n != r.n {
+		t.Errorf("All() yielded %d features, want %d", n, r.n)
+	}
+}
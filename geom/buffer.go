@@ -0,0 +1,260 @@
+package geom
+
+import "math"
+
+// CapStyle is the shape given to the ends of lines by BufferStyle.
+type CapStyle int
+
+// The cap styles.
+const (
+	// CapRound ends lines with half circles around their ends.
+	CapRound CapStyle = iota
+	// CapFlat ends lines flat at their ends.
+	CapFlat
+	// CapSquare ends lines with half squares around their ends, as if
+	// the lines were extended by the buffer distance with flat caps.
+	CapSquare
+)
+
+// JoinStyle is the shape given to the corners of lines and rings by
+// BufferStyle.
+type JoinStyle int
+
+// The join styles.
+const (
+	// JoinRound rounds corners with arcs of circles around them.
+	JoinRound JoinStyle = iota
+	// JoinMitre extends the sides of the buffer to meet at a sharp
+	// point at corners, unless the point lies beyond the mitre limit.
+	JoinMitre
+	// JoinBevel cuts corners with straight segments.
+	JoinBevel
+)
+
+// BufferStyle is the style in which Buffer approximates the curves of a
+// buffer. The zero value rounds the ends and corners of lines with 8
+// segments to each quarter circle.
+type BufferStyle struct {
+	Cap  CapStyle
+	Join JoinStyle
+	// QuadrantSegments is the number of segments approximating a
+	// quarter circle, or 8 if it is zero.
+	QuadrantSegments int
+	// MitreLimit is the greatest distance of the point of a mitre join
+	// from its corner, as a multiple of the buffer distance, beyond which
+	// the corner is beveled instead, or 5 if it is zero.
+	MitreLimit float64
+}
+
+// Buffer returns the buffer of a geometry in the default BufferStyle.
+func Buffer(g Geometry, distance float64) Geometry {
+	return BufferStyle{}.Buffer(g, distance)
+}
+
+// Buffer returns the buffer of a geometry, the area within a distance
+// of it, as a Polygon or a MultiPolygon, which is empty if the area is.
+// A negative distance shrinks polygons instead, leaving the area more
+// than the distance inside them, and gives an empty buffer of points and
+// lines, as does a distance of zero. Points have no buffer with flat
+// caps. The distance is in the units of the coordinates, so geometries
+// of longitudes and latitudes should first be projected with a
+// conformal projection centered on them, such as
+// proj.TransverseMercator.
+//
+// The buffer is computed as the union of the polygons, the rectangles
+// around each segment of their boundaries and of the lines, and the
+// shapes of the joins and caps, or, for a negative distance, as the
+// polygons less the buffers of their boundaries. The exterior rings of
+// the polygons of the buffer run counterclockwise and their holes
+// clockwise, and the Z and M of the points are discarded.
+func (s BufferStyle) Buffer(g Geometry, distance float64) Geometry {
+	return polygonal(s.buffer(g, distance))
+}
+
+// buffer returns the buffer of a geometry.
+func (s BufferStyle) buffer(g Geometry, d float64) MultiPolygon {
+	var edges []edge
+	switch g := g.(type) {
+	case Point:
+		return s.buffer(MultiPoint{g}, d)
+	case LineString:
+		return s.buffer(MultiLineString{g}, d)
+	case Polygon:
+		return s.buffer(MultiPolygon{g}, d)
+	case MultiPoint:
+		if d <= 0 {
+			return nil
+		}
+		for _, p := range g {
+			edges = s.pointEdges(edges, p, d)
+		}
+	case MultiLineString:
+		if d <= 0 {
+			return nil
+		}
+		for _, l := range g {
+			if c := cleanLine(l); c != nil {
+				edges = s.lineEdges(edges, c, d, false)
+			} else if len(l) > 0 {
+				edges = s.pointEdges(edges, l[0], d)
+			}
+		}
+	case MultiPolygon:
+		for _, p := range g {
+			for _, r := range p {
+				c := cleanLine(LineString(r))
+				if len(c) < 4 {
+					continue
+				}
+				edges = ringEdges(edges, Ring(c), [2]int{1, 0})
+				if d != 0 {
+					edges = s.lineEdges(edges, c, abs(d), true)
+				}
+			}
+		}
+		if d < 0 {
+			return buildArea(node(edges), func(w [2]int) bool { return w[0]%2 != 0 && w[1] == 0 })
+		}
+		return buildArea(node(edges), func(w [2]int) bool { return w[0]%2 != 0 || w[1] > 0 })
+	case GeometryCollection:
+		for _, m := range g {
+			for _, p := range s.buffer(m, d) {
+				for _, r := range p {
+					edges = ringEdges(edges, r, [2]int{0, 1})
+				}
+			}
+		}
+	}
+	return buildArea(node(edges), func(w [2]int) bool { return w[1] > 0 })
+}
+
+// pointEdges appends the edges of the buffer of a point.
+func (s BufferStyle) pointEdges(edges []edge, p Point, d float64) []edge {
+	if p.IsEmpty() || !finite(p) {
+		return edges
+	}
+	switch s.Cap {
+	case CapRound:
+		end := offset(p, Point{X: 1}, d)
+		c := s.arc(nil, p, Point{X: 1}, 2*math.Pi, d, end)
+		return pieceEdges(edges, c[:len(c)-1])
+	case CapSquare:
+		return pieceEdges(edges, []Point{
+			{X: p.X - d, Y: p.Y - d}, {X: p.X + d, Y: p.Y - d},
+			{X: p.X + d, Y: p.Y + d}, {X: p.X - d, Y: p.Y + d},
+		})
+	}
+	return edges
+}
+
+// lineEdges appends the edges of the buffer of the boundary of a line
+// without repeated points, which is a ring if closed is true: the
+// rectangles around its segments and the shapes of its joins, and of its
+// caps if it is not closed.
+func (s BufferStyle) lineEdges(edges []edge, ps []Point, d float64, closed bool) []edge {
+	n := len(ps) - 1 // The number of segments
+	dir := func(i int) Point {
+		a, b := ps[i], ps[i+1]
+		l := math.Hypot(b.X-a.X, b.Y-a.Y)
+		return Point{X: (b.X - a.X) / l, Y: (b.Y - a.Y) / l}
+	}
+	for i := range n {
+		a, b, u := ps[i], ps[i+1], dir(i)
+		if !closed && s.Cap == CapSquare {
+			if i == 0 {
+				a = offset(a, u, -d)
+			}
+			if i == n-1 {
+				b = offset(b, u, d)
+			}
+		}
+		nl := Point{X: -u.Y, Y: u.X}
+		edges = pieceEdges(edges, []Point{offset(a, nl, -d), offset(b, nl, -d), offset(b, nl, d), offset(a, nl, d)})
+	}
+	for i := range n + 1 {
+		switch {
+		case closed && i == n:
+			continue // The joins of a ring were made at its start
+		case closed && i == 0:
+			edges = s.joinEdges(edges, ps[0], dir(n-1), dir(0), d)
+		case i > 0 && i < n:
+			edges = s.joinEdges(edges, ps[i], dir(i-1), dir(i), d)
+		case s.Cap == CapRound && i == 0:
+			nl := Point{X: -dir(0).Y, Y: dir(0).X}
+			edges = pieceEdges(edges, s.arc([]Point{ps[0]}, ps[0], nl, math.Pi, d, offset(ps[0], nl, -d)))
+		case s.Cap == CapRound:
+			nr := Point{X: dir(n - 1).Y, Y: -dir(n - 1).X}
+			edges = pieceEdges(edges, s.arc([]Point{ps[n]}, ps[n], nr, math.Pi, d, offset(ps[n], nr, -d)))
+		}
+	}
+	return edges
+}
+
+// joinEdges appends the edges of the join at a vertex v between
+// segments running in the unit directions u1 and u2, which fills the gap
+// between their rectangles on the outer side of the turn, if any.
+func (s BufferStyle) joinEdges(edges []edge, v, u1, u2 Point, d float64) []edge {
+	cross := u1.X*u2.Y - u1.Y*u2.X
+	dot := u1.X*u2.X + u1.Y*u2.Y
+	if cross == 0 && dot > 0 {
+		return edges // Straight on
+	}
+	// The normals on the outer side of the turn, and the angle between
+	// them, clockwise for a turn to the right.
+	o1, o2 := Point{X: -u1.Y, Y: u1.X}, Point{X: -u2.Y, Y: u2.X}
+	angle := -math.Atan2(abs(cross), dot)
+	if cross > 0 {
+		o1, o2 = Point{X: -o1.X, Y: -o1.Y}, Point{X: -o2.X, Y: -o2.Y}
+		angle = -angle
+	}
+	p1, p2 := offset(v, o1, d), offset(v, o2, d)
+	switch s.Join {
+	case JoinRound:
+		return pieceEdges(edges, s.arc([]Point{v}, v, o1, angle, d, p2))
+	case JoinMitre:
+		limit := s.MitreLimit
+		if limit == 0 {
+			limit = 5
+		}
+		c := 1 + o1.X*o2.X + o1.Y*o2.Y
+		if c > 0 && 2/c <= limit*limit {
+			m := Point{X: v.X + d*(o1.X+o2.X)/c, Y: v.Y + d*(o1.Y+o2.Y)/c}
+			return pieceEdges(edges, []Point{v, p1, m, p2})
+		}
+	}
+	return pieceEdges(edges, []Point{v, p1, p2})
+}
+
+// arc appends to ps the points of the arc of radius d around c from
+// the unit direction o through the angle a, counterclockwise if it is
+// positive, and ending at the point end, approximated with
+// QuadrantSegments segments to each quarter circle. The arc starts
+// exactly at c+d*o and ends exactly at end, so that it meets the
+// rectangles of the segments on either side.
+func (s BufferStyle) arc(ps []Point, c, o Point, a, d float64, end Point) []Point {
+	q := s.QuadrantSegments
+	if q <= 0 {
+		q = 8
+	}
+	n := max(1, int(math.Ceil(abs(a)/(math.Pi/2)*float64(q)-1e-9)))
+	for i := range n {
+		sin, cos := math.Sincos(a * float64(i) / float64(n))
+		ps = append(ps, Point{X: c.X + d*(o.X*cos-o.Y*sin), Y: c.Y + d*(o.X*sin+o.Y*cos)})
+	}
+	return append(ps, end)
+}
+
+// offset returns the point p moved by d in the unit direction u.
+func offset(p, u Point, d float64) Point {
+	return Point{X: p.X + d*u.X, Y: p.Y + d*u.Y}
+}
+
+// pieceEdges appends the edges of one of the shapes whose union is a
+// buffer, labeled as a counterclockwise ring of the second input.
+func pieceEdges(edges []edge, ps []Point) []edge {
+	r := Ring(ps)
+	if signedArea(r) < 0 {
+		r = r.Reverse()
+	}
+	return ringEdges(edges, r, [2]int{0, 1})
+}
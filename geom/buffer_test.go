@@ -0,0 +1,109 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// polygon32 is the area of a regular polygon of 32 sides inscribed in
+// the unit circle, that of a circle approximated with 8 segments to
+// each quarter.
+var polygon32 = 16 * math.Sin(2*math.Pi/32)
+
+func TestBuffer(t *testing.T) {
+	line := LineString{{X: 0, Y: 0}, {X: 10, Y: 0}}
+	sq := Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)}
+	tests := []struct {
+		name  string
+		s     BufferStyle
+		g     Geometry
+		d     float64
+		area  float64
+		holes int
+	}{
+		{"round point", BufferStyle{}, Point{X: 1, Y: 2}, 2, 4 * polygon32, 0},
+		{"round point 1", BufferStyle{QuadrantSegments: 1}, Point{X: 1, Y: 2}, 1, 2, 0},
+		{"square point", BufferStyle{Cap: CapSquare}, Point{X: 1, Y: 2}, 2, 16, 0},
+		{"flat point", BufferStyle{Cap: CapFlat}, Point{X: 1, Y: 2}, 2, 0, 0},
+		{"round line", BufferStyle{}, line, 1, 20 + polygon32, 0},
+		{"flat line", BufferStyle{Cap: CapFlat}, line, 1, 20, 0},
+		{"square line", BufferStyle{Cap: CapSquare}, line, 1, 24, 0},
+		{"one point line", BufferStyle{}, LineString{{X: 1, Y: 1}, {X: 1, Y: 1}}, 1, polygon32, 0},
+		{"round square", BufferStyle{}, sq, 1, 140 + polygon32, 0},
+		{"mitre square", BufferStyle{Join: JoinMitre}, sq, 1, 144, 0},
+		{"limited mitre square", BufferStyle{Join: JoinMitre, MitreLimit: 1.4}, sq, 1, 142, 0},
+		{"bevel square", BufferStyle{Join: JoinBevel}, sq, 1, 142, 0},
+		{"shrunk square", BufferStyle{}, sq, -1, 64, 0},
+		{"shrunk away", BufferStyle{}, sq, -5, 0, 0},
+		{"zero", BufferStyle{}, sq, 0, 100, 0},
+		{"zero point", BufferStyle{}, Point{}, 0, 0, 0},
+		{"negative line", BufferStyle{}, line, -1, 0, 0},
+		// The buffer of a ring of lines has a hole, and square caps
+		// fill the corner at its ends.
+		{"mitre frame", BufferStyle{Cap: CapSquare, Join: JoinMitre}, LineString(sq[0]), 1, 144 - 64, 1},
+		{"round frame", BufferStyle{Cap: CapFlat}, LineString(sq[0]), 1, 140 + 3*polygon32/4 - 64, 1},
+		{"shrunk hole", BufferStyle{Join: JoinMitre}, Polygon{sq[0], ring(3, 3, 3, 7, 7, 7, 7, 3, 3, 3)}, 1, 144 - 4, 1},
+		{"filled hole", BufferStyle{}, Polygon{sq[0], ring(3, 3, 3, 7, 7, 7, 7, 3, 3, 3)}, 2.5, 200 + polygon32*6.25, 0},
+		{"two points", BufferStyle{Cap: CapSquare}, MultiPoint{{X: 0, Y: 0}, {X: 1, Y: 0}}, 1, 6, 0},
+		{"collection", BufferStyle{Cap: CapSquare}, GeometryCollection{Point{X: 0, Y: 0}, Point{X: 1, Y: 0}}, 1, 6, 0},
+	}
+	for _, tt := range tests {
+		b := tt.s.Buffer(tt.g, tt.d)
+		if err := Validate(b); err != nil {
+			t.Errorf("%s: Buffer() is invalid: %v", tt.name, err)
+		}
+		if !IsOriented(b, Counterclockwise) {
+			t.Errorf("%s: Buffer() is not oriented counterclockwise", tt.name)
+		}
+		if a := polygonArea(b); math.Abs(a-tt.area) > 1e-9 {
+			t.Errorf("%s: Buffer() has area %v, want %v", tt.name, a, tt.area)
+		}
+		if p, ok := b.(Polygon); ok && len(p) > 0 && len(p)-1 != tt.holes {
+			t.Errorf("%s: Buffer() has %d holes, want %d", tt.name, len(p)-1, tt.holes)
+		}
+	}
+	if b := Buffer(Point{X: 0, Y: 0}, 1); polygonArea(b) != polygonArea(BufferStyle{}.Buffer(Point{X: 0, Y: 0}, 1)) {
+		t.Errorf("Buffer() = %v, not the default style", b)
+	}
+	if m, ok := Buffer(MultiPoint{{X: 0, Y: 0}, {X: 10, Y: 0}}, 1).(MultiPolygon); !ok || len(m) != 2 {
+		t.Errorf("Buffer(two distant points) = %v, want two polygons", m)
+	}
+}
+
+func TestBufferRandom(t *testing.T) {
+	// Points near a random line are inside its buffer, and points far
+	// from it outside.
+	r := rand.New(rand.NewSource(68))
+	for range 20 {
+		var l LineString
+		for range 2 + r.Intn(10) {
+			l = append(l, Point{X: r.Float64() * 20, Y: r.Float64() * 20})
+		}
+		d := 0.5 + r.Float64()*2
+		for _, s := range []BufferStyle{{}, {Join: JoinMitre}, {Join: JoinBevel, Cap: CapSquare}} {
+			b := s.Buffer(l, d)
+			if err := Validate(b); err != nil {
+				t.Fatalf("%+v.Buffer(%v, %v) is invalid: %v", s, l, d, err)
+			}
+			for range 200 {
+				p := Point{X: r.Float64()*30 - 5, Y: r.Float64()*30 - 5}
+				dist := math.Inf(1)
+				for _, sg := range segments(l) {
+					dist = min(dist, sg.Distance(p))
+				}
+				// The round parts are inscribed polygons, within 2% of
+				// the distance of the circle, and bevels, and mitres beyond the
+				// limit, cut corners.
+				switch {
+				case dist < d*0.98 && s.Join == JoinRound && !CoversPoint(b, p):
+					t.Fatalf("%+v.Buffer(%v, %v) leaves out %v, at %v", s, l, d, p, dist)
+				case s.Join == JoinRound && dist > d && CoversPoint(b, p):
+					t.Fatalf("%+v.Buffer(%v, %v) covers %v, at %v", s, l, d, p, dist)
+				case dist > 5*d && CoversPoint(b, p):
+					t.Fatalf("%+v.Buffer(%v, %v) covers %v, at %v", s, l, d, p, dist)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package geom
+
+import (
+	"math"
+	"slices"
+)
+
+// Centroid returns the centroid of a geometry, its center of mass, or an
+// empty point if it is empty. Only the parts of the highest dimension
+// count: the centroid of a geometry with polygons of positive area is
+// that of their area, holes excluded, or else that of its lines, each
+// segment weighted by its length, or else the mean of its points. A
+// polygon of zero area counts as the line of its boundary.
+//
+// The centroid of a concave polygon may lie outside it. PointOnSurface
+// returns a point which is always inside.
+func Centroid(g Geometry) Point {
+	polys, lines, points := flatten(g)
+	var area, length, n float64
+	var ca, cl, cp Point
+	for _, p := range polys {
+		for i, r := range p {
+			a, c := ringCentroid(r)
+			if i > 0 {
+				a = -a
+			}
+			area += a
+			ca.X += a * c.X
+			ca.Y += a * c.Y
+			// Only needed if the area is zero.
+			lines = append(lines, LineString(r))
+		}
+	}
+	if area > 0 {
+		return Point{X: ca.X / area, Y: ca.Y / area}
+	}
+	for _, l := range lines {
+		for _, s := range segments(l) {
+			d := s.Length()
+			length += d
+			cl.X += d * (s.A.X + s.B.X) / 2
+			cl.Y += d * (s.A.Y + s.B.Y) / 2
+		}
+		points = append(points, l...)
+	}
+	if length > 0 {
+		return Point{X: cl.X / length, Y: cl.Y / length}
+	}
+	for _, p := range points {
+		if !p.IsEmpty() {
+			n++
+			cp.X += p.X
+			cp.Y += p.Y
+		}
+	}
+	if n > 0 {
+		return Point{X: cp.X / n, Y: cp.Y / n}
+	}
+	return EmptyPoint()
+}
+
+// ringCentroid returns the unsigned area enclosed by a ring and the
+// centroid of the area. The terms are taken relative to the first point
+// of the ring to limit rounding.
+func ringCentroid(r Ring) (float64, Point) {
+	if len(r) < 3 {
+		return 0, Point{}
+	}
+	o := r[0]
+	var a, cx, cy float64
+	for i := 1; i+1 < len(r); i++ {
+		x1, y1 := r[i].X-o.X, r[i].Y-o.Y
+		x2, y2 := r[i+1].X-o.X, r[i+1].Y-o.Y
+		cross := x1*y2 - x2*y1
+		a += cross
+		cx += (x1 + x2) * cross
+		cy += (y1 + y2) * cross
+	}
+	if a == 0 {
+		return 0, Point{}
+	}
+	return abs(a) / 2, Point{X: o.X + cx/(3*a), Y: o.Y + cy/(3*a)}
+}
+
+// PointOnSurface returns a point of a geometry, or an empty point if it
+// is empty, chosen like the centroid from the parts of the highest
+// dimension. The point is interior to a polygon whenever the geometry
+// has one of positive area, which makes it suitable for placing labels.
+// Otherwise it is the vertex of a line nearest to the centroid of the
+// lines, preferring those other than their ends, or the point nearest
+// to the centroid of the points.
+//
+// The point of polygons is found on a horizontal line through their
+// bounds which passes through no vertex, at the middle of the widest
+// interval of the line inside a polygon.
+func PointOnSurface(g Geometry) Point {
+	polys, lines, points := flatten(g)
+	best, width := EmptyPoint(), 0.0
+	for _, p := range polys {
+		if q, w := polygonInteriorPoint(p); w > width {
+			best, width = q, w
+		}
+	}
+	if width > 0 {
+		return best
+	}
+	for _, p := range polys {
+		for _, r := range p {
+			lines = append(lines, LineString(r))
+		}
+	}
+	c := Centroid(lines)
+	if !c.IsEmpty() {
+		d := math.Inf(1)
+		for _, interior := range [2]bool{true, false} {
+			for _, l := range lines {
+				for i, p := range l {
+					if interior == (i > 0 && i < len(l)-1) && sqDist(p, c) < d {
+						best, d = p, sqDist(p, c)
+					}
+				}
+			}
+			if !best.IsEmpty() {
+				return best
+			}
+		}
+	}
+	c = Centroid(points)
+	d := math.Inf(1)
+	for _, p := range points {
+		if !p.IsEmpty() && sqDist(p, c) < d {
+			best, d = p, sqDist(p, c)
+		}
+	}
+	return best
+}
+
+// polygonInteriorPoint returns the middle of the widest interval of a
+// horizontal line inside a polygon, and the width of the interval, or a
+// width of zero if the line misses the polygon.
+func polygonInteriorPoint(p Polygon) (Point, float64) {
+	b := RectOf(p.Exterior()...)
+	if b.IsEmpty() {
+		return EmptyPoint(), 0
+	}
+	// The line lies halfway between the vertices nearest to the middle
+	// of the bounds, above and below it.
+	mid := (b.MinY + b.MaxY) / 2
+	lo, hi := b.MinY, b.MaxY
+	for _, r := range p {
+		for _, v := range r {
+			switch {
+			case v.Y <= mid && v.Y > lo:
+				lo = v.Y
+			case v.Y > mid && v.Y < hi:
+				hi = v.Y
+			}
+		}
+	}
+	y := (lo + hi) / 2
+	var xs []float64
+	for _, r := range p {
+		for _, s := range segments(r) {
+			if (s.A.Y > y) != (s.B.Y > y) {
+				xs = append(xs, s.A.X+(y-s.A.Y)*(s.B.X-s.A.X)/(s.B.Y-s.A.Y))
+			}
+		}
+	}
+	slices.Sort(xs)
+	best, width := EmptyPoint(), 0.0
+	for i := 0; i+1 < len(xs); i += 2 {
+		if w := xs[i+1] - xs[i]; w > width {
+			best, width = Point{X: (xs[i] + xs[i+1]) / 2, Y: y}, w
+		}
+	}
+	return best, width
+}
+
+// flatten returns the polygons, line strings and points of a geometry,
+// including those in collections, in slices which may be appended to
+// without changing the geometry.
+func flatten(g Geometry) (MultiPolygon, MultiLineString, MultiPoint) {
+	switch g := g.(type) {
+	case Point:
+		return nil, nil, MultiPoint{g}
+	case MultiPoint:
+		return nil, nil, slices.Clip(g)
+	case LineString:
+		return nil, MultiLineString{g}, nil
+	case MultiLineString:
+		return nil, slices.Clip(g), nil
+	case Polygon:
+		return MultiPolygon{g}, nil, nil
+	case MultiPolygon:
+		return slices.Clip(g), nil, nil
+	case GeometryCollection:
+		var polys MultiPolygon
+		var lines MultiLineString
+		var points MultiPoint
+		for _, m := range g {
+			p, l, q := flatten(m)
+			polys = append(polys, p...)
+			lines = append(lines, l...)
+			points = append(points, q...)
+		}
+		return polys, lines, points
+	}
+	return nil, nil, nil
+}
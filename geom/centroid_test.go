@@ -0,0 +1,107 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// cShape is a polygon whose centroid lies outside it.
+var cShape = Polygon{ring(0, 0, 10, 0, 10, 2, 2, 2, 2, 8, 10, 8, 10, 10, 0, 10, 0, 0)}
+
+func TestCentroid(t *testing.T) {
+	tests := []struct {
+		g    Geometry
+		want Point
+	}{
+		{square, Point{X: 0.5, Y: 0.5}},
+		{Polygon{ring(0, 0, 0, 3, 6, 0, 0, 0)}, Point{X: 2, Y: 1}},
+		{Polygon{ring(1e9, 1e9, 1e9+6, 1e9, 1e9, 1e9+3, 1e9, 1e9)}, Point{X: 1e9 + 2, Y: 1e9 + 1}},
+		// The hole of area 4 at (3, 3) moves the centroid away from it.
+		{donut, Point{X: (500 - 12) / 96.0, Y: (500 - 12) / 96.0}},
+		// The rectangles of area 20, 12 and 20 of the C shape.
+		{cShape, Point{X: (20*5 + 12*1 + 20*5) / 52.0, Y: 5}},
+		{MultiPolygon{square, {ring(2, 0, 4, 0, 4, 1, 2, 1, 2, 0)}}, Point{X: (0.5 + 2*3) / 3, Y: 0.5}},
+		{GeometryCollection{square, Point{X: 100, Y: 100}, LineString{{X: -5}, {X: -9}}}, Point{X: 0.5, Y: 0.5}},
+		// Segments weighted by their lengths.
+		{LineString{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}}, Point{X: 4.0 / 3, Y: 1.0 / 6}},
+		{MultiLineString{{{X: 0}, {X: 2}}, {{X: 10, Y: 10}, {X: 10, Y: 10}}}, Point{X: 1}},
+		{GeometryCollection{LineString{{X: 0}, {X: 2}}, Point{X: 9, Y: 9}}, Point{X: 1}},
+		// A polygon of zero area counts as its boundary.
+		{Polygon{ring(0, 0, 2, 0, 4, 0, 0, 0)}, Point{X: 2}},
+		{MultiPoint{{X: 0, Y: 0}, {X: 3, Y: 0}, EmptyPoint(), {X: 0, Y: 3}}, Point{X: 1, Y: 1}},
+		{LineString{{X: 3, Y: 4}, {X: 3, Y: 4}}, Point{X: 3, Y: 4}},
+		{Point{X: 3, Y: 4}, Point{X: 3, Y: 4}},
+	}
+	for _, tt := range tests {
+		c := Centroid(tt.g)
+		if math.Abs(c.X-tt.want.X) > 1e-9 || math.Abs(c.Y-tt.want.Y) > 1e-9 {
+			t.Errorf("Centroid(%v) = %v, want %v", tt.g, c, tt.want)
+		}
+		if p, ok := tt.g.(Polygon); ok {
+			rev := Orient(p, Clockwise)
+			if c := Centroid(rev); math.Abs(c.X-tt.want.X) > 1e-9 || math.Abs(c.Y-tt.want.Y) > 1e-9 {
+				t.Errorf("Centroid(%v) = %v, want %v", rev, c, tt.want)
+			}
+		}
+	}
+	for _, g := range []Geometry{EmptyPoint(), Polygon{}, MultiPoint{EmptyPoint()}, GeometryCollection{}} {
+		if c := Centroid(g); !c.IsEmpty() {
+			t.Errorf("Centroid(%v) = %v, want empty", g, c)
+		}
+	}
+}
+
+func TestPointOnSurface(t *testing.T) {
+	if c := Centroid(cShape); Locate(cShape, c) != Exterior {
+		t.Fatalf("Centroid(cShape) = %v, inside", c)
+	}
+	tests := []struct {
+		g    Geometry
+		want Point
+	}{
+		// The middle of the widest interval of the line halfway between
+		// the vertices at Y 2 and 8.
+		{cShape, Point{X: 1, Y: 5}},
+		{square, Point{X: 0.5, Y: 0.5}},
+		{donut, Point{X: 5, Y: 7}},
+		{GeometryCollection{Point{X: 100}, cShape}, Point{X: 1, Y: 5}},
+		// The interior vertex of a line nearest its centroid, at 2.5.
+		{LineString{{X: 0}, {X: 1}, {X: 5}}, Point{X: 1}},
+		{LineString{{X: 0}, {X: 5}}, Point{X: 0}},
+		{MultiLineString{{{X: 0}, {X: 1}}, {{X: 1}, {X: 3}, {X: 4}}}, Point{X: 3}},
+		{Polygon{ring(0, 0, 1, 0, 2, 0, 0, 0)}, Point{X: 1}},
+		{MultiPoint{{X: 0}, {X: 1}, {X: 5}}, Point{X: 1}},
+		{Point{X: 3, Y: 4}, Point{X: 3, Y: 4}},
+	}
+	for _, tt := range tests {
+		if p := PointOnSurface(tt.g); !p.Equal(tt.want) {
+			t.Errorf("PointOnSurface(%v) = %v, want %v", tt.g, p, tt.want)
+		}
+	}
+	for _, g := range []Geometry{EmptyPoint(), Polygon{}, MultiLineString{}, GeometryCollection{}} {
+		if p := PointOnSurface(g); !p.IsEmpty() {
+			t.Errorf("PointOnSurface(%v) = %v, want empty", g, p)
+		}
+	}
+}
+
+func TestPointOnSurfaceRandom(t *testing.T) {
+	// Random star-shaped polygons, many concave, with a hole about the
+	// center.
+	r := rand.New(rand.NewSource(69))
+	for range 1000 {
+		n := 3 + r.Intn(30)
+		shell := make(Ring, n+1)
+		for i := range n {
+			a := 2 * math.Pi * float64(i) / float64(n)
+			d := 2 + r.Float64()*8
+			shell[i] = Point{X: d * math.Cos(a), Y: d * math.Sin(a)}
+		}
+		shell[n] = shell[0]
+		p := Polygon{shell, ring(-1, -1, -1, 1, 1, 1, 1, -1, -1, -1)}
+		if q := PointOnSurface(p); Locate(p, q) != Interior {
+			t.Fatalf("PointOnSurface(%v) = %v, not inside", p, q)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package geom
+
+// Clip returns the part of a geometry inside the rectangle, or on its
+// boundary, as a geometry of the same dimension: the points inside,
+// the pieces of lines clipped by ClipLineString, as a LineString if
+// there is just one, and the polygons clipped by ClipPolygon. Parts
+// entirely inside the rectangle are shared with g rather than copied.
+func (r Rect) Clip(g Geometry) Geometry {
+	switch g := g.(type) {
+	case Point:
+		if r.Contains(g) {
+			return g
+		}
+		return EmptyPoint()
+	case MultiPoint:
+		var m MultiPoint
+		for _, p := range g {
+			if r.Contains(p) {
+				m = append(m, p)
+			}
+		}
+		return m
+	case LineString:
+		m := r.ClipLineString(g)
+		if len(m) == 1 {
+			return m[0]
+		}
+		return m
+	case MultiLineString:
+		var m MultiLineString
+		for _, l := range g {
+			m = append(m, r.ClipLineString(l)...)
+		}
+		return m
+	case Polygon:
+		return r.ClipPolygon(g)
+	case MultiPolygon:
+		var m MultiPolygon
+		for _, p := range g {
+			if c := r.ClipPolygon(p); !c.IsEmpty() {
+				m = append(m, c)
+			}
+		}
+		return m
+	case GeometryCollection:
+		c := make(GeometryCollection, len(g))
+		for i, m := range g {
+			c[i] = r.Clip(m)
+		}
+		return c
+	}
+	return g
+}
+
+// ClipPolygon returns the part of a polygon inside the rectangle, or an
+// empty polygon if there is none, clipping each ring in turn by the
+// algorithm of Sutherland and Hodgman against each side of the
+// rectangle. The points where the rings cross the sides are
+// interpolated, Z and M included.
+//
+// The algorithm is fast, taking time proportional to the number of
+// points, and allocating little more than the result. But a ring which
+// leaves and reenters the rectangle is clipped to a single ring, joined
+// by edges running along the sides of the rectangle where the area
+// outside was cut away, where the general Intersection gives separate
+// polygons. This is usually harmless for rendering, such as in clipping
+// to the bounds of map tiles, though the result is not valid.
+func (r Rect) ClipPolygon(p Polygon) Polygon {
+	if p.IsEmpty() || r.IsEmpty() {
+		return Polygon{}
+	}
+	var buf [2][]Point
+	var q Polygon
+	for i, ring := range p {
+		c := r.clipRing(ring, &buf)
+		if c == nil && i == 0 {
+			return Polygon{}
+		}
+		if c != nil {
+			q = append(q, c)
+		}
+	}
+	return q
+}
+
+// clipRing returns the part of a ring inside the rectangle, or nil if
+// it encloses no area, using buf for the intermediate results.
+func (r Rect) clipRing(ring Ring, buf *[2][]Point) Ring {
+	b := RectOf(ring...)
+	switch {
+	case r.ContainsRect(b):
+		return ring
+	case !r.Intersects(b):
+		return nil
+	}
+	in := []Point(ring)
+	if ring.IsClosed() {
+		in = ring[:len(ring)-1]
+	}
+	for k, h := range r.sides() {
+		out := buf[k%2][:0]
+		for j, cur := range in {
+			prev := in[(j+len(in)-1)%len(in)]
+			switch ci, pi := h.inside(cur), h.inside(prev); {
+			case ci && pi:
+				out = appendDistinct(out, cur)
+			case ci:
+				out = appendDistinct(appendDistinct(out, h.cross(prev, cur)), cur)
+			case pi:
+				out = appendDistinct(out, h.cross(prev, cur))
+			}
+		}
+		if len(out) > 1 && out[0].Equal(out[len(out)-1]) {
+			out = out[:len(out)-1]
+		}
+		buf[k%2], in = out, out
+	}
+	if len(in) < 3 || signedArea(in) == 0 {
+		return nil
+	}
+	c := make(Ring, len(in)+1)
+	copy(c, in)
+	c[len(in)] = in[0]
+	return c
+}
+
+// ClipLineString returns the pieces of a line string inside the
+// rectangle, clipping it against each side of the rectangle in turn.
+// The points where the line crosses the sides are interpolated, Z and M
+// included, and pieces which only touch the rectangle at a point are
+// dropped. A line string entirely inside the rectangle is returned as
+// the only piece, without copying it.
+func (r Rect) ClipLineString(l LineString) MultiLineString {
+	b := RectOf(l...)
+	switch {
+	case len(l) < 2 || !r.Intersects(b):
+		return nil
+	case r.ContainsRect(b):
+		return MultiLineString{l}
+	}
+	pieces := MultiLineString{l}
+	for _, h := range r.sides() {
+		var next MultiLineString
+		for _, piece := range pieces {
+			var cur LineString
+			for j, p := range piece {
+				in := h.inside(p)
+				if j > 0 && in != h.inside(piece[j-1]) {
+					cur = appendDistinct(cur, h.cross(piece[j-1], p))
+				}
+				if in {
+					cur = appendDistinct(cur, p)
+				} else if cur != nil {
+					next = appendPiece(next, cur)
+					cur = nil
+				}
+			}
+			next = appendPiece(next, cur)
+		}
+		pieces = next
+	}
+	return pieces
+}
+
+// appendPiece appends a piece of a line to m if it has a segment.
+func appendPiece(m MultiLineString, l LineString) MultiLineString {
+	if len(l) < 2 {
+		return m
+	}
+	return append(m, l)
+}
+
+// appendDistinct appends p to ps unless it equals the last point.
+func appendDistinct[S ~[]Point](ps S, p Point) S {
+	if len(ps) > 0 && ps[len(ps)-1].Equal(p) {
+		return ps
+	}
+	return append(ps, p)
+}
+
+// side is one of the closed half-planes whose intersection is a
+// rectangle: the points whose X, or Y if y is true, is at least v, or at
+// most v if upper is true.
+type side struct {
+	y, upper bool
+	v        float64
+}
+
+// sides returns the sides of the rectangle.
+func (r Rect) sides() [4]side {
+	return [4]side{{false, false, r.MinX}, {false, true, r.MaxX}, {true, false, r.MinY}, {true, true, r.MaxY}}
+}
+
+// inside reports whether a point lies in the half-plane.
+func (h side) inside(p Point) bool {
+	c := p.X
+	if h.y {
+		c = p.Y
+	}
+	if h.upper {
+		return c <= h.v
+	}
+	return c >= h.v
+}
+
+// cross returns the point where the segment from p to q, which lie
+// either side of the boundary of the half-plane, crosses the boundary,
+// interpolating each coordinate.
+func (h side) cross(p, q Point) Point {
+	var t float64
+	if h.y {
+		t = (h.v - p.Y) / (q.Y - p.Y)
+	} else {
+		t = (h.v - p.X) / (q.X - p.X)
+	}
+	c := Point{
+		X: p.X + t*(q.X-p.X), Y: p.Y + t*(q.Y-p.Y),
+		Z: p.Z + t*(q.Z-p.Z), M: p.M + t*(q.M-p.M),
+	}
+	if h.y {
+		c.Y = h.v
+	} else {
+		c.X = h.v
+	}
+	return c
+}
+
+// The bits of the outcodes of the Cohen-Sutherland algorithm, set for a
+// point beyond each side of a rectangle.
+const (
+	outLeft = 1 << iota
+	outRight
+	outBottom
+	outTop
+)
+
+// ClipSegment returns the part of a segment inside the rectangle, or on
+// its boundary, and true, or false if the segment misses the rectangle.
+// The ends moved onto the sides of the rectangle are interpolated, Z and
+// M included, and lie exactly on the sides.
+//
+// The segment is clipped by the algorithm of Cohen and Sutherland, which
+// accepts segments entirely inside the rectangle and rejects those
+// entirely beyond one of its sides with a few comparisons, so that
+// culling many segments of which most lie well inside or outside a
+// viewport is fast. It allocates nothing.
+func (r Rect) ClipSegment(s Segment) (Segment, bool) {
+	if r.IsEmpty() || s.A.IsEmpty() || s.B.IsEmpty() {
+		return Segment{}, false
+	}
+	ca, cb := r.outcode(s.A), r.outcode(s.B)
+	for {
+		switch {
+		case ca|cb == 0:
+			return s, true
+		case ca&cb != 0:
+			return Segment{}, false
+		}
+		// Move an end outside the rectangle onto the side it lies
+		// beyond.
+		c := ca
+		if c == 0 {
+			c = cb
+		}
+		var h side
+		switch {
+		case c&outLeft != 0:
+			h = side{false, false, r.MinX}
+		case c&outRight != 0:
+			h = side{false, true, r.MaxX}
+		case c&outBottom != 0:
+			h = side{true, false, r.MinY}
+		default:
+			h = side{true, true, r.MaxY}
+		}
+		p := h.cross(s.A, s.B)
+		if c == ca {
+			s.A, ca = p, r.outcode(p)
+		} else {
+			s.B, cb = p, r.outcode(p)
+		}
+	}
+}
+
+// outcode returns the Cohen-Sutherland outcode of a point.
+func (r Rect) outcode(p Point) int {
+	c := 0
+	switch {
+	case p.X < r.MinX:
+		c |= outLeft
+	case p.X > r.MaxX:
+		c |= outRight
+	}
+	switch {
+	case p.Y < r.MinY:
+		c |= outBottom
+	case p.Y > r.MaxY:
+		c |= outTop
+	}
+	return c
+}
@@ -0,0 +1,232 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestClipPolygon(t *testing.T) {
+	r := Rect{5, 5, 15, 15}
+	tests := []struct {
+		name string
+		r    Rect
+		p    Polygon
+		want Polygon
+	}{
+		{"corner", r, Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)}, Polygon{ring(5, 5, 10, 5, 10, 10, 5, 10, 5, 5)}},
+		{"inside", r, Polygon{ring(6, 6, 7, 6, 7, 7, 6, 6)}, Polygon{ring(6, 6, 7, 6, 7, 7, 6, 6)}},
+		{"outside", r, Polygon{ring(0, 0, 1, 0, 1, 1, 0, 0)}, Polygon{}},
+		{"around", r, Polygon{ring(0, 0, 20, 0, 20, 20, 0, 20, 0, 0)}, Polygon{ring(5, 15, 5, 5, 15, 5, 15, 15, 5, 15)}},
+		{"triangle", Rect{0, 0, 2, 2}, Polygon{ring(-2, 0, 4, 0, 1, 3, -2, 0)}, Polygon{ring(0, 2, 0, 0, 2, 0, 2, 2, 0, 2)}},
+		{"unclosed", Rect{0, 0, 2, 2}, Polygon{ring(-2, 0, 4, 0, 1, 3)}, Polygon{ring(0, 2, 0, 0, 2, 0, 2, 2, 0, 2)}},
+		{"touching", r, Polygon{ring(0, 0, 5, 0, 5, 5, 0, 5, 0, 0)}, Polygon{}},
+		// The hole is clipped too, and one outside is dropped.
+		{"hole", Rect{0, 0, 3, 3}, donut, Polygon{ring(0, 3, 0, 0, 3, 0, 3, 3, 0, 3), ring(3, 3, 3, 2, 2, 2, 2, 3, 3, 3)}},
+		{"hole outside", Rect{5, 5, 10, 10}, donut, Polygon{ring(5, 5, 10, 5, 10, 10, 5, 10, 5, 5)}},
+		{"empty", r, Polygon{}, Polygon{}},
+		{"empty rect", EmptyRect(), square, Polygon{}},
+	}
+	for _, tt := range tests {
+		if c := tt.r.ClipPolygon(tt.p); !reflect.DeepEqual(c, tt.want) {
+			t.Errorf("%s: %v.ClipPolygon(%v) = %v, want %v", tt.name, tt.r, tt.p, c, tt.want)
+		}
+	}
+	// A U shape leaving and reentering the rectangle gives one ring,
+	// joined along the side where the gap of the U is cut away, where
+	// the intersection gives two polygons.
+	u := Polygon{ring(0, 0, 3, 0, 3, 3, 2, 3, 2, 1, 1, 1, 1, 3, 0, 3, 0, 0)}
+	r = Rect{-1, 2, 4, 4}
+	want := Polygon{ring(0, 2, 3, 2, 3, 3, 2, 3, 2, 2, 1, 2, 1, 3, 0, 3, 0, 2)}
+	if c := r.ClipPolygon(u); !reflect.DeepEqual(c, want) {
+		t.Errorf("ClipPolygon(%v) = %v, want %v", u, c, want)
+	}
+	if m, ok := Intersection(u, r.Polygon()).(MultiPolygon); !ok || len(m) != 2 {
+		t.Errorf("Intersection(%v) = %v, want two polygons", u, m)
+	}
+	r = Rect{5, 5, 15, 15}
+	// The ring inside is shared.
+	in := Polygon{ring(6, 6, 7, 6, 7, 7, 6, 6)}
+	if c := r.ClipPolygon(in); &c[0][0] != &in[0][0] {
+		t.Errorf("ClipPolygon() copies a ring inside")
+	}
+}
+
+func TestClipPolygonZM(t *testing.T) {
+	p := Polygon{{{X: 0, Y: 0, Z: 0, M: 10}, {X: 4, Y: 0, Z: 4, M: 20}, {X: 4, Y: 4, Z: 8, M: 30}, {X: 0, Y: 0, Z: 0, M: 10}}}
+	c := Rect{0, 0, 2, 4}.ClipPolygon(p)
+	want := Polygon{{{X: 2, Y: 2, Z: 4, M: 20}, {X: 0, Y: 0, Z: 0, M: 10}, {X: 2, Y: 0, Z: 2, M: 15}, {X: 2, Y: 2, Z: 4, M: 20}}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("ClipPolygon(%v) = %v, want %v", p, c, want)
+	}
+}
+
+func TestClipPolygonRandom(t *testing.T) {
+	// The clip of a convex polygon is the intersection.
+	r := rand.New(rand.NewSource(71))
+	for range 500 {
+		var mp MultiPoint
+		for range 3 + r.Intn(20) {
+			mp = append(mp, Point{X: r.Float64() * 20, Y: r.Float64() * 20})
+		}
+		p, ok := ConvexHull(mp).(Polygon)
+		if !ok {
+			continue
+		}
+		x, y := r.Float64()*15, r.Float64()*15
+		rect := Rect{x, y, x + r.Float64()*10, y + r.Float64()*10}
+		c := rect.ClipPolygon(p)
+		want := polygonArea(Intersection(p, rect.Polygon()))
+		if a := polygonArea(c); math.Abs(a-want) > 1e-9*max(1, want) {
+			t.Fatalf("%v.ClipPolygon(%v) has area %v, want %v", rect, p, a, want)
+		}
+		if !c.IsEmpty() && Validate(c) != nil {
+			t.Fatalf("%v.ClipPolygon(%v) = %v, invalid: %v", rect, p, c, Validate(c))
+		}
+	}
+}
+
+func TestClipPolygonAllocs(t *testing.T) {
+	var p Polygon
+	var rg Ring
+	for i := range 1000 {
+		a := 2 * math.Pi * float64(i) / 1000
+		rg = append(rg, Point{X: 10 * math.Cos(a), Y: 10 * math.Sin(a)})
+	}
+	p = Polygon{append(rg, rg[0])}
+	r := Rect{0, 0, 20, 20}
+	// The buffers grow to the size of the result, and the result is
+	// allocated once.
+	if a := testing.AllocsPerRun(10, func() { r.ClipPolygon(p) }); a > 30 {
+		t.Errorf("ClipPolygon() allocates %v times, want at most 30", a)
+	}
+}
+
+func TestClipLineString(t *testing.T) {
+	r := Rect{0, 0, 10, 10}
+	tests := []struct {
+		l    LineString
+		want MultiLineString
+	}{
+		{LineString{{X: -5, Y: 5}, {X: 15, Y: 5}}, MultiLineString{{{X: 0, Y: 5}, {X: 10, Y: 5}}}},
+		{LineString{{X: 1, Y: 1}, {X: 2, Y: 2}}, MultiLineString{{{X: 1, Y: 1}, {X: 2, Y: 2}}}},
+		{LineString{{X: -1, Y: -1}, {X: -2, Y: 20}}, nil},
+		// In, out and back in again.
+		{LineString{{X: 5, Y: 5}, {X: 15, Y: 5}, {X: 15, Y: 8}, {X: 5, Y: 8}}, MultiLineString{{{X: 5, Y: 5}, {X: 10, Y: 5}}, {{X: 10, Y: 8}, {X: 5, Y: 8}}}},
+		// Along a side, and touching a corner.
+		{LineString{{X: -5, Y: 0}, {X: 15, Y: 0}}, MultiLineString{{{X: 0, Y: 0}, {X: 10, Y: 0}}}},
+		{LineString{{X: -5, Y: 5}, {X: 0, Y: 10}, {X: -5, Y: 15}}, nil},
+		{LineString{{X: -5, Y: 5}, {X: 5, Y: 15}}, nil},
+		{LineString{{X: 1, Y: 1}}, nil},
+		{LineString{{X: -10, Y: 5}, {X: 5, Y: -10}}, nil},
+		{LineString{{X: -2, Y: 5}, {X: 5, Y: -2}}, MultiLineString{{{X: 0, Y: 3}, {X: 3, Y: 0}}}},
+	}
+	for _, tt := range tests {
+		if c := r.ClipLineString(tt.l); !reflect.DeepEqual(c, tt.want) {
+			t.Errorf("%v.ClipLineString(%v) = %v, want %v", r, tt.l, c, tt.want)
+		}
+	}
+	l := LineString{{X: 0, Y: 0, Z: 0, M: 0}, {X: 20, Y: 0, Z: 100, M: 1}}
+	want := MultiLineString{{{X: 0, Y: 0, Z: 0, M: 0}, {X: 10, Y: 0, Z: 50, M: 0.5}}}
+	if c := r.ClipLineString(l); !reflect.DeepEqual(c, want) {
+		t.Errorf("%v.ClipLineString(%v) = %v, want %v", r, l, c, want)
+	}
+}
+
+func TestClip(t *testing.T) {
+	r := Rect{0, 0, 10, 10}
+	tests := []struct {
+		g, want Geometry
+	}{
+		{Point{X: 1, Y: 1}, Point{X: 1, Y: 1}},
+		{MultiPoint{{X: 1, Y: 1}, {X: 11, Y: 1}, {X: 10, Y: 10}}, MultiPoint{{X: 1, Y: 1}, {X: 10, Y: 10}}},
+		{LineString{{X: -5, Y: 5}, {X: 15, Y: 5}}, LineString{{X: 0, Y: 5}, {X: 10, Y: 5}}},
+		{LineString{{X: 5, Y: 5}, {X: 15, Y: 5}, {X: 15, Y: 8}, {X: 5, Y: 8}}, MultiLineString{{{X: 5, Y: 5}, {X: 10, Y: 5}}, {{X: 10, Y: 8}, {X: 5, Y: 8}}}},
+		{MultiLineString{{{X: 1, Y: 1}, {X: 2, Y: 2}}, {{X: 20, Y: 20}, {X: 30, Y: 30}}}, MultiLineString{{{X: 1, Y: 1}, {X: 2, Y: 2}}}},
+		{Polygon{ring(5, 5, 15, 5, 15, 15, 5, 15, 5, 5)}, Polygon{ring(5, 10, 5, 5, 10, 5, 10, 10, 5, 10)}},
+		{MultiPolygon{square, {ring(20, 20, 21, 20, 21, 21, 20, 20)}}, MultiPolygon{square}},
+		{GeometryCollection{Point{X: 1, Y: 1}, square}, GeometryCollection{Point{X: 1, Y: 1}, square}},
+	}
+	for _, tt := range tests {
+		if c := r.Clip(tt.g); !reflect.DeepEqual(c, tt.want) {
+			t.Errorf("%v.Clip(%v) = %v, want %v", r, tt.g, c, tt.want)
+		}
+	}
+	if p := r.Clip(Point{X: 20}).(Point); !p.IsEmpty() {
+		t.Errorf("%v.Clip(outside) = %v, want empty", r, p)
+	}
+}
+
+func TestClipSegment(t *testing.T) {
+	r := Rect{0, 0, 10, 10}
+	seg := func(ax, ay, bx, by float64) Segment { return Segment{Point{X: ax, Y: ay}, Point{X: bx, Y: by}} }
+	tests := []struct {
+		s    Segment
+		want Segment
+		ok   bool
+	}{
+		{seg(1, 1, 9, 9), seg(1, 1, 9, 9), true},
+		{seg(-5, 5, 15, 5), seg(0, 5, 10, 5), true},
+		{seg(15, 5, -5, 5), seg(10, 5, 0, 5), true},
+		{seg(5, -5, 5, 5), seg(5, 0, 5, 5), true},
+		{seg(-5, -5, 15, 15), seg(0, 0, 10, 10), true},
+		{seg(-2, 5, 5, -2), seg(0, 3, 3, 0), true},
+		// Touching a corner, and along a side.
+		{seg(-5, 5, 5, 15), seg(0, 10, 0, 10), true},
+		{seg(-5, 0, 15, 0), seg(0, 0, 10, 0), true},
+		// Beyond one side, and beyond two sides but missing the corner.
+		{seg(-5, -5, -1, 20), Segment{}, false},
+		{seg(11, 0, 11, 10), Segment{}, false},
+		{seg(-10, 5, 5, -10), Segment{}, false},
+		{seg(9, 12, 12, 9), Segment{}, false},
+		{Segment{EmptyPoint(), Point{}}, Segment{}, false},
+	}
+	for _, tt := range tests {
+		c, ok := r.ClipSegment(tt.s)
+		if ok != tt.ok || c != tt.want {
+			t.Errorf("%v.ClipSegment(%v) = %v, %v, want %v, %v", r, tt.s, c, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := EmptyRect().ClipSegment(seg(0, 0, 1, 1)); ok {
+		t.Errorf("EmptyRect().ClipSegment() = true")
+	}
+	s := Segment{Point{X: -10, Y: 0, Z: 0, M: 2}, Point{X: 10, Y: 0, Z: 20, M: 4}}
+	want := Segment{Point{X: 0, Y: 0, Z: 10, M: 3}, Point{X: 10, Y: 0, Z: 20, M: 4}}
+	if c, ok := r.ClipSegment(s); !ok || c != want {
+		t.Errorf("%v.ClipSegment(%v) = %v, want %v", r, s, c, want)
+	}
+}
+
+func TestClipSegmentRandom(t *testing.T) {
+	// The clipped segment lies on the segment, inside the rectangle, and
+	// agrees with ClipLineString.
+	r := rand.New(rand.NewSource(72))
+	rect := Rect{0, 0, 1, 1}
+	for range 10000 {
+		s := Segment{Point{X: r.Float64()*3 - 1, Y: r.Float64()*3 - 1}, Point{X: r.Float64()*3 - 1, Y: r.Float64()*3 - 1}}
+		c, ok := rect.ClipSegment(s)
+		pieces := rect.ClipLineString(LineString{s.A, s.B})
+		if ok != (len(pieces) == 1) {
+			t.Fatalf("%v.ClipSegment(%v) = %v, %v, but ClipLineString = %v", rect, s, c, ok, pieces)
+		}
+		if !ok {
+			continue
+		}
+		if !rect.Contains(c.A) || !rect.Contains(c.B) || s.Distance(c.A) > 1e-12 || s.Distance(c.B) > 1e-12 {
+			t.Fatalf("%v.ClipSegment(%v) = %v", rect, s, c)
+		}
+		p := pieces[0]
+		if math.Abs(c.A.X-p[0].X)+math.Abs(c.A.Y-p[0].Y)+math.Abs(c.B.X-p[1].X)+math.Abs(c.B.Y-p[1].Y) > 1e-12 {
+			t.Fatalf("%v.ClipSegment(%v) = %v, ClipLineString = %v", rect, s, c, p)
+		}
+	}
+}
+
+func TestClipSegmentAllocs(t *testing.T) {
+	r := Rect{0, 0, 10, 10}
+	s := Segment{Point{X: -5, Y: 5}, Point{X: 15, Y: 7}}
+	if a := testing.AllocsPerRun(100, func() { r.ClipSegment(s) }); a != 0 {
+		t.Errorf("ClipSegment() allocates %v times, want 0", a)
+	}
+}
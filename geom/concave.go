@@ -0,0 +1,276 @@
+package geom
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// ConcaveHull returns a concave hull of a geometry, a simple polygon
+// containing all of its points which follows their outline more closely
+// than the convex hull, as needed for instance to draw a realistic
+// footprint around a cloud of GPS fixes. The exterior ring runs
+// counterclockwise, and every vertex of the hull is a point of the
+// geometry. Geometries whose points have no convex hull polygon give the
+// same result as ConvexHull.
+//
+// The concavity controls the tightness of the hull. The hull starts
+// as the convex hull, and each edge of length l is repeatedly replaced
+// by two edges through the point nearest to it until no point within
+// l/concavity of both its ends remains which would keep the hull simple
+// and covering all the points. Smaller values therefore give tighter
+// hulls: a concavity of 1 or less digs deeply into clusters, 2 gives a
+// reasonable outline of most point clouds, and +Inf gives the convex
+// hull. This is the gift opening algorithm of Park and Oh, "A New
+// Concave Hull Algorithm and Concaveness Measure for n-dimensional
+// Datasets" (2012).
+func ConcaveHull(g Geometry, concavity float64) Geometry {
+	ps := sortedPoints(g)
+	h := convexHull(ps)
+	if len(h) < 3 || math.IsInf(concavity, 1) {
+		return hullGeometry(ps, h)
+	}
+	c := newDigger(ps, h)
+	for len(c.queue) > 0 {
+		i := c.queue[0]
+		c.queue = c.queue[1:]
+		a, b := ps[c.pt[i]], ps[c.pt[c.next[i]]]
+		d := sqDist(a, b) / (concavity * concavity)
+		if k := c.candidate(i, d); k >= 0 && min(sqDist(ps[k], a), sqDist(ps[k], b)) <= d {
+			c.insert(i, k)
+		}
+	}
+	vs := make([]int, 0, len(c.pt))
+	for i := 0; len(vs) == 0 || i != 0; i = c.next[i] {
+		vs = append(vs, c.pt[i])
+	}
+	return hullGeometry(ps, vs)
+}
+
+// digger holds the state of the gift opening algorithm of ConcaveHull:
+// the hull as a circular linked list of nodes, each the index of a
+// point, a grid of the points not yet on the hull, and the queue of
+// nodes whose following edge is to be dug into. The edges of the hull
+// are listed in the cells of the grid they may pass through, each given
+// by the node it starts at and the node it ends at, and are stale once
+// that is no longer the following node.
+type digger struct {
+	ps         []Point
+	pt         []int // The index of the point of each node
+	prev, next []int // The neighbors of each node
+	grid       pointGrid
+	edges      [][][2]int
+	queue      []int
+}
+
+// newDigger returns a digger whose hull is the convex hull h.
+func newDigger(ps []Point, h []int) *digger {
+	n := len(h)
+	c := &digger{ps: ps, grid: newPointGrid(ps)}
+	c.edges = make([][][2]int, len(c.grid.cells))
+	for i, k := range h {
+		c.pt = append(c.pt, k)
+		c.prev = append(c.prev, (i+n-1)%n)
+		c.next = append(c.next, (i+1)%n)
+		c.queue = append(c.queue, i)
+		c.use(k)
+	}
+	for i := range h {
+		c.addEdge(i)
+	}
+	return c
+}
+
+// use removes the point k, which is on the hull, from the grid.
+func (c *digger) use(k int) {
+	cell := c.grid.cellOf(c.ps[k])
+	c.grid.cells[cell] = slices.DeleteFunc(c.grid.cells[cell], func(j int) bool { return j == k })
+}
+
+// edge returns the edge of the hull following the node i.
+func (c *digger) edge(i int) Segment {
+	return Segment{c.ps[c.pt[i]], c.ps[c.pt[c.next[i]]]}
+}
+
+// addEdge lists the edge following the node i in the cells of the grid.
+func (c *digger) addEdge(i int) {
+	c.grid.cellsOf(c.edge(i).Bounds(), func(cell int) {
+		c.edges[cell] = append(c.edges[cell], [2]int{i, c.next[i]})
+	})
+}
+
+// insert adds the point k to the hull after the node i, and queues both
+// edges which replace the edge following i.
+func (c *digger) insert(i, k int) {
+	j := len(c.pt)
+	c.pt = append(c.pt, k)
+	c.prev = append(c.prev, i)
+	c.next = append(c.next, c.next[i])
+	c.prev[c.next[i]] = j
+	c.next[i] = j
+	c.use(k)
+	c.addEdge(i)
+	c.addEdge(j)
+	c.queue = append(c.queue, i, j)
+}
+
+// candidate returns the index of the point within a squared distance d
+// of the edge following the node i which is nearest to the edge, among
+// those which are nearer to it than to the edges either side and which
+// can be added to the hull without leaving a point outside, or -1 if
+// there is none. The points are searched within a distance of the edge
+// doubling from the size of a cell, since the nearest point is usually
+// suitable.
+func (c *digger) candidate(i int, d float64) int {
+	ps := c.ps
+	p, a, b, n := ps[c.pt[c.prev[i]]], ps[c.pt[i]], ps[c.pt[c.next[i]]], ps[c.pt[c.next[c.next[i]]]]
+	e := Segment{a, b}
+	type near struct {
+		k int
+		d float64
+	}
+	var ks []near
+	done := -1.0 // The squared distance within which points were tried
+	for r := c.grid.size; done < d; r *= 2 {
+		lim := min(r*r, d)
+		ks = ks[:0]
+		c.grid.query(e.Bounds().Expand(math.Sqrt(lim)), func(k int) {
+			if dk := sqSegDist(e, ps[k]); dk > done && dk <= lim {
+				ks = append(ks, near{k, dk})
+			}
+		})
+		slices.SortFunc(ks, func(x, y near) int { return cmp.Compare(x.d, y.d) })
+		for _, x := range ks {
+			q := ps[x.k]
+			if sqSegDist(Segment{p, a}, q) < x.d || sqSegDist(Segment{b, n}, q) < x.d {
+				continue
+			}
+			if c.enclosesPoint(a, b, x.k) || c.crossesHull(i, q) {
+				continue
+			}
+			return x.k
+		}
+		done = lim
+	}
+	return -1
+}
+
+// enclosesPoint reports whether a point not on the hull lies strictly
+// inside the triangle of a, b and the point k, or on the edge from a to
+// b, so that it would be left outside the hull by adding k between a
+// and b.
+func (c *digger) enclosesPoint(a, b Point, k int) bool {
+	q := c.ps[k]
+	found := false
+	c.grid.query(RectOf(a, b, q), func(j int) {
+		if found || j == k {
+			return
+		}
+		v := c.ps[j]
+		found = orient(a, b, v) >= 0 && orient(b, q, v) > 0 && orient(q, a, v) > 0
+	})
+	return found
+}
+
+// crossesHull reports whether the edges from the node i to q and from q
+// to the following node meet any edge of the hull other than at the
+// node they start or end at.
+func (c *digger) crossesHull(i int, q Point) bool {
+	a, b := c.ps[c.pt[i]], c.ps[c.pt[c.next[i]]]
+	crosses := false
+	c.grid.cellsOf(RectOf(a, b, q), func(cell int) {
+		c.edges[cell] = slices.DeleteFunc(c.edges[cell], func(ij [2]int) bool {
+			return c.next[ij[0]] != ij[1]
+		})
+		for _, ij := range c.edges[cell] {
+			if crosses || ij[0] == i {
+				continue
+			}
+			e := c.edge(ij[0])
+			for _, s := range [2]Segment{{a, q}, {b, q}} {
+				switch si := s.Intersection(e); {
+				case si.Kind == NoIntersection:
+				case si.Kind == EndpointIntersection && si.P.Equal(s.A):
+				default:
+					crosses = true
+				}
+			}
+		}
+	})
+	return crosses
+}
+
+// pointGrid is a uniform grid of square cells over a set of points,
+// each cell listing the indexes of the points in it.
+type pointGrid struct {
+	bounds Rect
+	size   float64
+	nx, ny int
+	cells  [][]int
+}
+
+// newPointGrid returns a grid over the points with about one point per
+// cell.
+func newPointGrid(ps []Point) pointGrid {
+	b := RectOf(ps...)
+	n := float64(len(ps))
+	size := max(math.Sqrt(b.Area()/n), max(b.Width(), b.Height())/n)
+	if size == 0 {
+		size = 1
+	}
+	g := pointGrid{bounds: b, size: size}
+	g.nx, g.ny = int(b.Width()/size)+1, int(b.Height()/size)+1
+	g.cells = make([][]int, g.nx*g.ny)
+	for k, p := range ps {
+		cell := g.cellOf(p)
+		g.cells[cell] = append(g.cells[cell], k)
+	}
+	return g
+}
+
+// cell returns the column and row of the cell containing p, clamped to
+// the grid.
+func (g pointGrid) cell(p Point) (i, j int) {
+	i = int((p.X - g.bounds.MinX) / g.size)
+	j = int((p.Y - g.bounds.MinY) / g.size)
+	return max(0, min(g.nx-1, i)), max(0, min(g.ny-1, j))
+}
+
+// cellOf returns the index of the cell containing p.
+func (g pointGrid) cellOf(p Point) int {
+	i, j := g.cell(p)
+	return j*g.nx + i
+}
+
+// query calls fn with the index of every point in the cells meeting r,
+// which includes every point in r.
+func (g pointGrid) query(r Rect, fn func(k int)) {
+	g.cellsOf(r, func(cell int) {
+		for _, k := range g.cells[cell] {
+			fn(k)
+		}
+	})
+}
+
+// cellsOf calls fn with the index of every cell meeting r, clamped to
+// the grid.
+func (g pointGrid) cellsOf(r Rect, fn func(cell int)) {
+	i0, j0 := g.cell(Point{X: r.MinX, Y: r.MinY})
+	i1, j1 := g.cell(Point{X: r.MaxX, Y: r.MaxY})
+	for j := j0; j <= j1; j++ {
+		for i := i0; i <= i1; i++ {
+			fn(j*g.nx + i)
+		}
+	}
+}
+
+// sqDist returns the squared distance between two points.
+func sqDist(p, q Point) float64 {
+	dx, dy := p.X-q.X, p.Y-q.Y
+	return dx*dx + dy*dy
+}
+
+// sqSegDist returns the squared distance from p to a segment.
+func sqSegDist(s Segment, p Point) float64 {
+	return sqDist(p, s.ClosestPoint(p))
+}
@@ -0,0 +1,84 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestConcaveHull(t *testing.T) {
+	notched := MultiPoint{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 5, Y: 8}}
+	tests := []struct {
+		g         Geometry
+		concavity float64
+		want      Geometry
+	}{
+		{notched, 1, Polygon{ring(0, 0, 10, 0, 10, 10, 5, 8, 0, 10, 0, 0)}},
+		{notched, 2, Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)}},
+		{notched, math.Inf(1), Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)}},
+		{MultiPoint{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}}, 1, LineString{{X: 0, Y: 0}, {X: 2, Y: 2}}},
+		{Point{X: 1, Y: 2}, 1, Point{X: 1, Y: 2}},
+		{MultiPoint{}, 1, GeometryCollection{}},
+	}
+	for _, tt := range tests {
+		if h := ConcaveHull(tt.g, tt.concavity); !reflect.DeepEqual(h, tt.want) {
+			t.Errorf("ConcaveHull(%v, %v) = %v, want %v", tt.g, tt.concavity, h, tt.want)
+		}
+	}
+}
+
+func TestConcaveHullRandom(t *testing.T) {
+	// Points scattered over a C shape, whose opening a convex hull
+	// fills.
+	r := rand.New(rand.NewSource(65))
+	var mp MultiPoint
+	for len(mp) < 2000 {
+		p := Point{X: r.Float64() * 10, Y: r.Float64() * 10}
+		if p.X < 3 || p.Y < 3 || p.Y > 7 {
+			mp = append(mp, p)
+		}
+	}
+	inputs := make(map[Point]bool, len(mp))
+	for _, p := range mp {
+		inputs[p] = true
+	}
+	convex := polygonArea(ConvexHull(mp))
+	// The area of the C shape is 100 less its 7 by 4 opening, which
+	// tighter hulls cut further into.
+	tests := []struct {
+		concavity        float64
+		minArea, maxArea float64
+	}{
+		{1, 45, 72},
+		{2, 55, 80},
+		{5, 65, 90},
+		{math.Inf(1), convex, convex},
+	}
+	for _, tt := range tests {
+		h := ConcaveHull(mp, tt.concavity)
+		if err := Validate(h); err != nil {
+			t.Fatalf("ConcaveHull(%v) is invalid: %v", tt.concavity, err)
+		}
+		p := h.(Polygon)
+		if len(p) != 1 || p[0].Winding() != Counterclockwise {
+			t.Errorf("ConcaveHull(%v) = %d rings, winding %v", tt.concavity, len(p), p[0].Winding())
+		}
+		if a := polygonArea(p); a < tt.minArea || a > tt.maxArea {
+			t.Errorf("ConcaveHull(%v) has area %v, want %v to %v", tt.concavity, a, tt.minArea, tt.maxArea)
+		}
+		if open := (Point{X: 9, Y: 5}); CoversPoint(p, open) != math.IsInf(tt.concavity, 1) {
+			t.Errorf("ConcaveHull(%v) covers %v = %v", tt.concavity, open, !math.IsInf(tt.concavity, 1))
+		}
+		for _, v := range p[0] {
+			if !inputs[v] {
+				t.Fatalf("ConcaveHull(%v) has vertex %v, not a point", tt.concavity, v)
+			}
+		}
+		for _, q := range mp {
+			if !CoversPoint(p, q) {
+				t.Fatalf("ConcaveHull(%v) leaves out %v", tt.concavity, q)
+			}
+		}
+	}
+}
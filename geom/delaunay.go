@@ -0,0 +1,400 @@
+package geom
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Triangulation is a triangulation of a set of points, with the
+// triangles held in the compact half-edge structure of Delaunator: each
+// triangle t owns the three half-edges 3t, 3t+1 and 3t+2, the directed
+// edges between its vertices in counterclockwise order, and each half-edge
+// is paired with the opposite half-edge of the adjacent triangle, if any.
+type Triangulation struct {
+	// Points are the points triangulated.
+	Points []Point
+	// Triangles holds the indices in Points of the vertices of the
+	// triangles, three to each triangle in counterclockwise order. The
+	// half-edge e runs from the point Triangles[e] to the next vertex of
+	// its triangle.
+	Triangles []int
+	// Halfedges holds for each half-edge the opposite half-edge, running
+	// the other way along the same edge in the adjacent triangle, or -1
+	// if the edge lies on the convex hull.
+	Halfedges []int
+	// Hull holds the indices in Points of the points on the convex hull,
+	// in counterclockwise order, or of all the distinct points in order
+	// along their line if they are collinear.
+	Hull []int
+}
+
+// Delaunay returns the Delaunay triangulation of a set of points, in
+// which the circle through the vertices of each triangle has none of the
+// points inside it. Of the triangulations of the points, it maximizes the
+// smallest angle of the triangles, which makes it suitable for
+// interpolating values measured at the points. Empty and infinite
+// points are ignored, as are all but the first of points with the same X
+// and Y, and collinear points have no triangles. Where four or more
+// points lie on a circle, the choice among the triangulations of their
+// polygon is arbitrary.
+//
+// The triangulation is built by the sweep-hull algorithm of Sinclair,
+// "S-hull: a fast radial sweep-hull routine for Delaunay triangulation"
+// (2010), as refined by Agafonkin in Delaunator: the points are added in
+// order of their distance from a seed triangle, each joined to the edges
+// of the convex hull of the points before it which it sees, and the
+// edges of the new triangles are flipped until all are Delaunay. The
+// tests of orientation and of points in circles are exact. It takes time
+// proportional to n log n for n points in practice.
+func Delaunay(points []Point) *Triangulation {
+	t := &Triangulation{Points: points}
+	ids := distinctPoints(points)
+	if len(ids) < 3 {
+		t.Hull = ids
+		return t
+	}
+	b := EmptyRect()
+	for _, i := range ids {
+		b = b.Extend(points[i])
+	}
+	c := b.Center()
+	// The seed triangle joins the point nearest the center of the bounds,
+	// the point nearest to it, and the point making the smallest circle
+	// with them.
+	nearest := func(p Point, not int) int {
+		k, d := -1, math.Inf(1)
+		for _, i := range ids {
+			if i != not && sqDist(p, points[i]) < d {
+				k, d = i, sqDist(p, points[i])
+			}
+		}
+		return k
+	}
+	i0 := nearest(c, -1)
+	i1 := nearest(points[i0], i0)
+	i2, r := -1, math.Inf(1)
+	for _, i := range ids {
+		if orient(points[i0], points[i1], points[i]) == 0 {
+			continue
+		}
+		if d := sqDist(circumcenter(points[i0], points[i1], points[i]), points[i0]); d < r || i2 < 0 {
+			i2, r = i, d
+		}
+	}
+	if i2 < 0 {
+		// The points are collinear, and in order along their line.
+		t.Hull = ids
+		return t
+	}
+	if orient(points[i0], points[i1], points[i2]) < 0 {
+		i1, i2 = i2, i1
+	}
+	c = circumcenter(points[i0], points[i1], points[i2])
+	dist := make([]float64, len(points))
+	for _, i := range ids {
+		dist[i] = sqDist(c, points[i])
+	}
+	slices.SortStableFunc(ids, func(i, j int) int { return cmp.Compare(dist[i], dist[j]) })
+
+	d := newSweep(t, c, len(ids))
+	d.seed(i0, i1, i2)
+	for _, i := range ids {
+		if i != i0 && i != i1 && i != i2 {
+			d.insert(i)
+		}
+	}
+	e := d.start
+	for {
+		t.Hull = append(t.Hull, e)
+		if e = d.next[e]; e == d.start {
+			break
+		}
+	}
+	return t
+}
+
+// Len returns the number of triangles.
+func (t *Triangulation) Len() int {
+	return len(t.Triangles) / 3
+}
+
+// Triangle returns the indices in Points of the vertices of the triangle
+// i, in counterclockwise order.
+func (t *Triangulation) Triangle(i int) [3]int {
+	return [3]int(t.Triangles[3*i : 3*i+3])
+}
+
+// Neighbors returns the triangles adjacent to the triangle i across each
+// of its edges, from each of its vertices to the next, or -1 where the
+// edge lies on the convex hull.
+func (t *Triangulation) Neighbors(i int) [3]int {
+	var n [3]int
+	for j := range n {
+		n[j] = -1
+		if o := t.Halfedges[3*i+j]; o >= 0 {
+			n[j] = o / 3
+		}
+	}
+	return n
+}
+
+// Find returns the triangle containing a point, or on whose boundary it
+// lies, or -1 if the point lies outside the convex hull, walking from
+// triangle to triangle towards the point.
+func (t *Triangulation) Find(p Point) int {
+	if t.Len() == 0 || !finite(p) {
+		return -1
+	}
+	i := 0
+	for range len(t.Triangles) {
+		next := i
+		for j := range 3 {
+			e := 3*i + j
+			a, b := t.Points[t.Triangles[e]], t.Points[t.Triangles[nextHalfedge(e)]]
+			if orient(a, b, p) < 0 {
+				if t.Halfedges[e] < 0 {
+					return -1
+				}
+				next = t.Halfedges[e] / 3
+				break
+			}
+		}
+		if next == i {
+			return i
+		}
+		i = next
+	}
+	return -1
+}
+
+// Polygons returns the triangles as polygons, each with a
+// counterclockwise ring of its vertices.
+func (t *Triangulation) Polygons() MultiPolygon {
+	m := make(MultiPolygon, t.Len())
+	for i := range m {
+		v := t.Triangle(i)
+		a := t.Points[v[0]]
+		m[i] = Polygon{{a, t.Points[v[1]], t.Points[v[2]], a}}
+	}
+	return m
+}
+
+// nextHalfedge returns the half-edge after e in its triangle.
+func nextHalfedge(e int) int {
+	if e%3 == 2 {
+		return e - 2
+	}
+	return e + 1
+}
+
+// prevHalfedge returns the half-edge before e in its triangle.
+func prevHalfedge(e int) int {
+	if e%3 == 0 {
+		return e + 2
+	}
+	return e - 1
+}
+
+// distinctPoints returns the indices of the finite points, but for the
+// first of each with the same X and Y, in order of X and then Y.
+func distinctPoints(points []Point) []int {
+	var ids []int
+	for i, p := range points {
+		if finite(p) {
+			ids = append(ids, i)
+		}
+	}
+	slices.SortStableFunc(ids, func(i, j int) int {
+		return cmp.Or(cmp.Compare(points[i].X, points[j].X), cmp.Compare(points[i].Y, points[j].Y))
+	})
+	return slices.CompactFunc(ids, func(i, j int) bool {
+		return points[i].X == points[j].X && points[i].Y == points[j].Y
+	})
+}
+
+// circumcenter returns the center of the circle through three points
+// which are not collinear.
+func circumcenter(a, b, c Point) Point {
+	bx, by := b.X-a.X, b.Y-a.Y
+	cx, cy := c.X-a.X, c.Y-a.Y
+	bl, cl := bx*bx+by*by, cx*cx+cy*cy
+	d := 2 * (bx*cy - by*cx)
+	return Point{X: a.X + (cy*bl-by*cl)/d, Y: a.Y + (bx*cl-cx*bl)/d}
+}
+
+// sweep is the state of the sweep-hull algorithm: the triangulation being
+// built, and the convex hull of the points added so far as a circular
+// doubly linked list of points, counterclockwise.
+type sweep struct {
+	t      *Triangulation
+	center Point
+	// next and prev link the points of the hull, and tri holds for each
+	// the half-edge on the hull which starts at it. The next of a point
+	// no longer on the hull is itself.
+	next, prev, tri []int
+	start           int
+	// hash holds points of the hull by their angle around the center,
+	// for finding the edges of the hull each new point sees.
+	hash  []int
+	stack []int
+}
+
+// newSweep returns the state for triangulating n points about the
+// center.
+func newSweep(t *Triangulation, center Point, n int) *sweep {
+	k := len(t.Points)
+	d := &sweep{
+		t:      t,
+		center: center,
+		next:   make([]int, k),
+		prev:   make([]int, k),
+		tri:    make([]int, k),
+		hash:   make([]int, int(math.Ceil(math.Sqrt(float64(n))))),
+	}
+	for i := range d.hash {
+		d.hash[i] = -1
+	}
+	t.Triangles = make([]int, 0, 3*(2*n-5))
+	t.Halfedges = make([]int, 0, 3*(2*n-5))
+	return d
+}
+
+// seed starts the triangulation with the counterclockwise triangle of
+// the points i0, i1 and i2.
+func (d *sweep) seed(i0, i1, i2 int) {
+	d.next[i0], d.next[i1], d.next[i2] = i1, i2, i0
+	d.prev[i0], d.prev[i1], d.prev[i2] = i2, i0, i1
+	for _, i := range [3]int{i0, i1, i2} {
+		d.hash[d.key(d.t.Points[i])] = i
+	}
+	d.start = i0
+	d.add(i0, i1, i2, -1, -1, -1)
+	d.tri[i0], d.tri[i1], d.tri[i2] = 0, 1, 2
+}
+
+// insert adds the point i, which lies outside the hull, joining it to
+// the edges of the hull it sees.
+func (d *sweep) insert(i int) {
+	ps := d.t.Points
+	p := ps[i]
+	// sees reports whether p lies to the right of the edge of the hull
+	// from a to b, outside it.
+	sees := func(a, b int) bool { return orient(ps[a], ps[b], p) < 0 }
+	start, key := -1, d.key(p)
+	for j := range d.hash {
+		start = d.hash[(key+j)%len(d.hash)]
+		if start >= 0 && d.next[start] != start {
+			break
+		}
+	}
+	start = d.prev[start]
+	e := start
+	for !sees(e, d.next[e]) {
+		if e = d.next[e]; e == start {
+			return // On the hull, where no edge is seen
+		}
+	}
+	n := d.next[e]
+	t := d.add(e, i, n, -1, -1, d.tri[e])
+	d.tri[e], d.tri[i] = t, t+1
+	d.legalize(t + 2)
+	// Join the point to the edges seen after e, and before it if there
+	// may be any.
+	for q := d.next[n]; sees(n, q); q = d.next[n] {
+		t = d.add(n, i, q, d.tri[i], -1, d.tri[n])
+		d.tri[i] = t + 1
+		d.legalize(t + 2)
+		d.next[n] = n
+		n = q
+	}
+	if e == start {
+		for q := d.prev[e]; sees(q, e); q = d.prev[e] {
+			t = d.add(q, i, e, -1, d.tri[e], d.tri[q])
+			d.tri[q] = t
+			d.legalize(t + 2)
+			d.next[e] = e
+			e = q
+		}
+	}
+	d.start = e
+	d.next[e], d.prev[i] = i, e
+	d.next[i], d.prev[n] = n, i
+	d.hash[key] = i
+	d.hash[d.key(ps[e])] = e
+}
+
+// key returns the index in the hash of a point, by a pseudo-angle
+// around the center which increases monotonically with the angle.
+func (d *sweep) key(p Point) int {
+	dx, dy := p.X-d.center.X, p.Y-d.center.Y
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+	a := dx / (abs(dx) + abs(dy))
+	if dy > 0 {
+		a = 3 - a
+	} else {
+		a = 1 + a
+	}
+	return int(a/4*float64(len(d.hash))) % len(d.hash)
+}
+
+// add appends the triangle of the points i, j and k, in counterclockwise
+// order, pairing its half-edges with a, b and c, and returns its first
+// half-edge.
+func (d *sweep) add(i, j, k, a, b, c int) int {
+	t := len(d.t.Triangles)
+	d.t.Triangles = append(d.t.Triangles, i, j, k)
+	d.t.Halfedges = append(d.t.Halfedges, a, b, c)
+	d.link(t, a)
+	d.link(t+1, b)
+	d.link(t+2, c)
+	return t
+}
+
+// link pairs the half-edges a and b, where b may be -1.
+func (d *sweep) link(a, b int) {
+	d.t.Halfedges[a] = b
+	if b >= 0 {
+		d.t.Halfedges[b] = a
+	}
+}
+
+// legalize flips the edge of the half-edge a, and then the edges
+// opposite the new point in the triangles it gives, for as long as they
+// are not Delaunay.
+func (d *sweep) legalize(a int) {
+	tri, half := d.t.Triangles, d.t.Halfedges
+	stack := d.stack[:0]
+	for {
+		b := half[a]
+		// The half-edge a runs from p to q in the triangle p, q, r, and b
+		// from q to p in the triangle q, p, s.
+		ar, bl := prevHalfedge(a), prevHalfedge(max(b, 0))
+		if b >= 0 && incircle(d.t.Points[tri[a]], d.t.Points[tri[b]], d.t.Points[tri[ar]], d.t.Points[tri[bl]]) > 0 {
+			// Flip the edge to join r and s, turning the triangles into
+			// s, q, r and r, p, s.
+			r, s := tri[ar], tri[bl]
+			tri[a], tri[b] = s, r
+			hbl, har := half[bl], half[ar]
+			d.link(a, hbl)
+			d.link(b, har)
+			d.link(ar, bl)
+			// The half-edges of the hull from s and r have moved.
+			if hbl < 0 {
+				d.tri[s] = a
+			}
+			if har < 0 {
+				d.tri[r] = b
+			}
+			stack = append(stack, nextHalfedge(b))
+			continue
+		}
+		if len(stack) == 0 {
+			break
+		}
+		a, stack = stack[len(stack)-1], stack[:len(stack)-1]
+	}
+	d.stack = stack
+}
@@ -0,0 +1,181 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkTriangulation checks that a triangulation of distinct points is
+// consistent and Delaunay.
+func checkTriangulation(t *testing.T, tr *Triangulation) {
+	t.Helper()
+	ps := tr.Points
+	n := len(distinctPoints(ps))
+	if len(tr.Triangles) != len(tr.Halfedges) || len(tr.Triangles)%3 != 0 {
+		t.Fatalf("%d triangle vertices, %d half-edges", len(tr.Triangles), len(tr.Halfedges))
+	}
+	// Euler's formula for a triangulated convex polygon with holes
+	// at its interior points.
+	if want := 2*n - len(tr.Hull) - 2; tr.Len() != want {
+		t.Fatalf("Len() = %d for %d points and %d on the hull, want %d", tr.Len(), n, len(tr.Hull), want)
+	}
+	hull := 0
+	for e, o := range tr.Halfedges {
+		if o < 0 {
+			hull++
+			continue
+		}
+		if tr.Halfedges[o] != e || tr.Triangles[e] != tr.Triangles[nextHalfedge(o)] || tr.Triangles[o] != tr.Triangles[nextHalfedge(e)] {
+			t.Fatalf("half-edges %d and %d are not opposite", e, o)
+		}
+	}
+	if hull != len(tr.Hull) {
+		t.Fatalf("%d half-edges on the hull, want %d", hull, len(tr.Hull))
+	}
+	for i := range tr.Len() {
+		v := tr.Triangle(i)
+		a, b, c := ps[v[0]], ps[v[1]], ps[v[2]]
+		if orient(a, b, c) <= 0 {
+			t.Fatalf("triangle %d %v is not counterclockwise", i, v)
+		}
+		// Neighbors across each edge are each other's.
+		for j, o := range tr.Neighbors(i) {
+			if o >= 0 && !slices.Contains(neighbors(tr, o), i) {
+				t.Fatalf("triangle %d has neighbor %d across edge %d, but not the reverse", i, o, j)
+			}
+		}
+		for k, p := range ps {
+			if k != v[0] && k != v[1] && k != v[2] && finite(p) && incircle(a, b, c, p) > 0 {
+				t.Fatalf("triangle %d %v has point %d %v in its circle", i, v, k, p)
+			}
+		}
+	}
+	for i, k := range tr.Hull {
+		a, b, c := ps[k], ps[tr.Hull[(i+1)%len(tr.Hull)]], ps[tr.Hull[(i+2)%len(tr.Hull)]]
+		if orient(a, b, c) < 0 {
+			t.Fatalf("hull turns clockwise at %v", b)
+		}
+	}
+}
+
+func TestDelaunay(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []Point
+		triangles int
+		hull      []int
+	}{
+		{"triangle", []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}}, 1, []int{0, 1, 2}},
+		{"clockwise", []Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}}, 1, []int{0, 2, 1}},
+		// Four points on a circle.
+		{"square", []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}, 2, []int{0, 1, 2, 3}},
+		{"center", []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}, {X: 1, Y: 1}}, 4, []int{0, 1, 2, 3}},
+		{"duplicates", []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0, Z: 5}, {X: 0, Y: 1}, EmptyPoint(), {X: math.Inf(1)}}, 1, []int{0, 1, 3}},
+		{"collinear", []Point{{X: 2, Y: 2}, {X: 0, Y: 0}, {X: 1, Y: 1}}, 0, []int{1, 2, 0}},
+		{"two", []Point{{X: 1, Y: 0}, {X: 0, Y: 0}}, 0, []int{1, 0}},
+		{"none", nil, 0, nil},
+	}
+	for _, tt := range tests {
+		tr := Delaunay(tt.points)
+		if tr.Len() != tt.triangles {
+			t.Errorf("%s: Delaunay() has %d triangles, want %d", tt.name, tr.Len(), tt.triangles)
+		}
+		// Compare the hulls from their least point.
+		h := slices.Clone(tr.Hull)
+		if len(h) > 0 && tt.triangles > 0 {
+			m := slices.Index(h, slices.Min(h))
+			h = append(h[m:], h[:m]...)
+		}
+		if !slices.Equal(h, tt.hull) {
+			t.Errorf("%s: Delaunay().Hull = %v, want %v", tt.name, tr.Hull, tt.hull)
+		}
+		if tt.triangles > 0 {
+			checkTriangulation(t, tr)
+		}
+	}
+}
+
+func TestDelaunayRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(73))
+	for _, n := range []int{4, 10, 100, 1000} {
+		ps := make([]Point, n)
+		for i := range ps {
+			ps[i] = Point{X: r.Float64() * 100, Y: r.Float64() * 100}
+		}
+		tr := Delaunay(ps)
+		checkTriangulation(t, tr)
+		// The hull is the convex hull.
+		hull := ConvexHull(MultiPoint(ps)).(Polygon)[0]
+		if len(tr.Hull) != len(hull)-1 {
+			t.Errorf("Delaunay(%d points).Hull has %d points, want %d", n, len(tr.Hull), len(hull)-1)
+		}
+	}
+	// A grid, whose points lie four to a circle, and points along lines
+	// through it.
+	var grid []Point
+	for i := range 15 {
+		for j := range 15 {
+			grid = append(grid, Point{X: float64(i), Y: float64(j)})
+		}
+	}
+	checkTriangulation(t, Delaunay(grid))
+	var circle []Point
+	for i := range 64 {
+		s, c := math.Sincos(2 * math.Pi * float64(i) / 64)
+		circle = append(circle, Point{X: c, Y: s})
+	}
+	checkTriangulation(t, Delaunay(append(circle, Point{})))
+}
+
+func TestTriangulationFind(t *testing.T) {
+	r := rand.New(rand.NewSource(73))
+	ps := make([]Point, 200)
+	for i := range ps {
+		ps[i] = Point{X: r.Float64(), Y: r.Float64()}
+	}
+	tr := Delaunay(ps)
+	polys := tr.Polygons()
+	if len(polys) != tr.Len() {
+		t.Fatalf("Polygons() = %d polygons, want %d", len(polys), tr.Len())
+	}
+	hull := Polygon{slices.Collect(func(yield func(Point) bool) {
+		for _, k := range tr.Hull {
+			yield(ps[k])
+		}
+		yield(ps[tr.Hull[0]])
+	})}
+	for range 1000 {
+		p := Point{X: r.Float64()*1.2 - 0.1, Y: r.Float64()*1.2 - 0.1}
+		i := tr.Find(p)
+		if i < 0 {
+			if CoversPoint(hull, p) {
+				t.Fatalf("Find(%v) = -1, inside the hull", p)
+			}
+			continue
+		}
+		if !CoversPoint(polys[i], p) {
+			t.Fatalf("Find(%v) = %d, %v", p, i, polys[i])
+		}
+	}
+	if i := tr.Find(ps[17]); i < 0 || !slices.Contains(vertices(tr, i), 17) {
+		t.Errorf("Find(vertex 17) = %d", i)
+	}
+	if i := tr.Find(EmptyPoint()); i != -1 {
+		t.Errorf("Find(empty) = %d, want -1", i)
+	}
+	if i := Delaunay(nil).Find(Point{}); i != -1 {
+		t.Errorf("Find() in no triangles = %d, want -1", i)
+	}
+}
+
+func neighbors(tr *Triangulation, i int) []int {
+	n := tr.Neighbors(i)
+	return n[:]
+}
+
+func vertices(tr *Triangulation, i int) []int {
+	v := tr.Triangle(i)
+	return v[:]
+}
@@ -0,0 +1,93 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// Densify returns a copy of a geometry with points inserted into its
+// lines and rings so that no segment is longer than maxLength, in the
+// units of the coordinates. Each segment which is longer is divided into
+// the fewest segments of equal length which are short enough, with the Z
+// and M of the points inserted interpolated linearly. Points are copied
+// unchanged, as is the whole geometry if maxLength is not positive.
+func Densify(g Geometry, maxLength float64) Geometry {
+	return densify(g, func(a, b Point) float64 { return math.Hypot(b.X-a.X, b.Y-a.Y) / maxLength },
+		func(a, b Point, f float64) Point {
+			return Point{X: a.X + f*(b.X-a.X), Y: a.Y + f*(b.Y-a.Y)}
+		})
+}
+
+// DensifyGeodesic returns a copy of a geometry of longitudes and
+// latitudes in degrees with points inserted into its lines and rings so
+// that no segment is longer than maxMeters on the sphere geodesy.Earth,
+// as for Densify. The points inserted lie on the great circles between
+// the ends of the segments, so that the segments of the result drawn as
+// straight lines in a map projection follow the shortest paths between
+// the original points, rather than cutting across them as a long segment
+// would. Their longitudes are in the range (-180, 180].
+func DensifyGeodesic(g Geometry, maxMeters float64) Geometry {
+	return densify(g, func(a, b Point) float64 { return geodesy.Haversine(a.LatLng(), b.LatLng()) / maxMeters },
+		func(a, b Point, f float64) Point {
+			return FromLatLng(geodesy.Intermediate(a.LatLng(), b.LatLng(), f))
+		})
+}
+
+// densify returns a copy of a geometry densified with the number of
+// pieces a segment must be divided into, exceeding 1 if it is too long
+// and infinite or NaN if the maximum length is not positive, and the
+// point a fraction of the way along a segment, whose Z and M densify
+// interpolates.
+func densify(g Geometry, pieces func(a, b Point) float64, at func(a, b Point, f float64) Point) Geometry {
+	line := func(ps []Point) []Point {
+		if len(ps) == 0 {
+			return nil
+		}
+		out := make([]Point, 0, len(ps))
+		for i, b := range ps {
+			if i > 0 {
+				a := ps[i-1]
+				if n := math.Ceil(pieces(a, b)); n > 1 && !math.IsInf(n, 0) {
+					for k := 1; k < int(n); k++ {
+						f := float64(k) / n
+						p := at(a, b, f)
+						p.Z, p.M = a.Z+f*(b.Z-a.Z), a.M+f*(b.M-a.M)
+						out = append(out, p)
+					}
+				}
+			}
+			out = append(out, b)
+		}
+		return out
+	}
+	switch g := g.(type) {
+	case LineString:
+		return LineString(line(g))
+	case Polygon:
+		p := make(Polygon, len(g))
+		for i, r := range g {
+			p[i] = Ring(line(r))
+		}
+		return p
+	case MultiLineString:
+		m := make(MultiLineString, len(g))
+		for i, l := range g {
+			m[i] = LineString(line(l))
+		}
+		return m
+	case MultiPolygon:
+		m := make(MultiPolygon, len(g))
+		for i, p := range g {
+			m[i] = densify(p, pieces, at).(Polygon)
+		}
+		return m
+	case GeometryCollection:
+		c := make(GeometryCollection, len(g))
+		for i, m := range g {
+			c[i] = densify(m, pieces, at)
+		}
+		return c
+	}
+	return Clone(g)
+}
@@ -0,0 +1,122 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+func TestDensify(t *testing.T) {
+	zm := LineString{{X: 0, Y: 0, Z: 0, M: 10}, {X: 0, Y: 10, Z: 8, M: 0}}
+	tests := []struct {
+		name string
+		g    Geometry
+		max  float64
+		want Geometry
+	}{
+		{"pieces", LineString(ring(0, 0, 10, 0)), 3, LineString(ring(0, 0, 2.5, 0, 5, 0, 7.5, 0, 10, 0))},
+		{"exact", LineString(ring(0, 0, 10, 0)), 5, LineString(ring(0, 0, 5, 0, 10, 0))},
+		{"short", LineString(ring(0, 0, 3, 4)), 5, LineString(ring(0, 0, 3, 4))},
+		{"zm", zm, 5, LineString{zm[0], {X: 0, Y: 5, Z: 4, M: 5}, zm[1]}},
+		{"square", square, 0.5, Polygon{ring(0, 0, 0.5, 0, 1, 0, 1, 0.5, 1, 1, 0.5, 1, 0, 1, 0, 0.5, 0, 0)}},
+		{"multi", MultiLineString{LineString(ring(0, 0, 0, 2)), LineString(ring(5, 5))}, 1,
+			MultiLineString{LineString(ring(0, 0, 0, 1, 0, 2)), LineString(ring(5, 5))}},
+		{"collection", GeometryCollection{Point{X: 1, Y: 2}, MultiPolygon{square}}, 0.5,
+			GeometryCollection{Point{X: 1, Y: 2}, MultiPolygon{Polygon{ring(0, 0, 0.5, 0, 1, 0, 1, 0.5, 1, 1, 0.5, 1, 0, 1, 0, 0.5, 0, 0)}}}},
+		{"points", MultiPoint(ring(0, 0, 10, 10)), 1, MultiPoint(ring(0, 0, 10, 10))},
+		{"zero", LineString(ring(0, 0, 10, 0)), 0, LineString(ring(0, 0, 10, 0))},
+		{"negative", LineString(ring(0, 0, 10, 0)), -1, LineString(ring(0, 0, 10, 0))},
+		{"nan", LineString(ring(0, 0, 10, 0)), math.NaN(), LineString(ring(0, 0, 10, 0))},
+		{"repeated", LineString(ring(0, 0, 0, 0)), 0, LineString(ring(0, 0, 0, 0))},
+	}
+	for _, tt := range tests {
+		if got := Densify(tt.g, tt.max); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Densify(%v, %v) = %v, want %v", tt.name, tt.g, tt.max, got, tt.want)
+		}
+	}
+	if g := Densify(LineString{}, 1); !g.IsEmpty() {
+		t.Errorf("Densify(empty) = %v, want empty", g)
+	}
+	// The copy shares no points with the original.
+	l := LineString(ring(0, 0, 1, 0))
+	Densify(l, 10).(LineString)[0].X = 5
+	if l[0].X != 0 {
+		t.Errorf("Densify modified its argument")
+	}
+}
+
+func TestDensifyRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(76))
+	for range 200 {
+		l := make(LineString, 2+r.Intn(10))
+		for i := range l {
+			l[i] = Point{X: r.NormFloat64() * 100, Y: r.NormFloat64() * 100}
+		}
+		max := r.Float64()*50 + 1
+		d := Densify(l, max).(LineString)
+		// The points of the line are kept, in order, with the fewest
+		// points between them which make the segments short enough.
+		k := 0
+		for i, p := range l {
+			if i > 0 {
+				n := int(math.Ceil(math.Hypot(p.X-l[i-1].X, p.Y-l[i-1].Y) / max))
+				k += n
+			}
+			if k >= len(d) || d[k] != p {
+				t.Fatalf("Densify(%v, %v) = %v, without point %d", l, max, d, i)
+			}
+		}
+		if len(d) != k+1 {
+			t.Fatalf("Densify(%v, %v) = %d points, want %d", l, max, len(d), k+1)
+		}
+		for i := 1; i < len(d); i++ {
+			if s := math.Hypot(d[i].X-d[i-1].X, d[i].Y-d[i-1].Y); s > max*(1+1e-12) {
+				t.Fatalf("Densify(%v, %v) has a segment of length %v", l, max, s)
+			}
+		}
+	}
+}
+
+func TestDensifyGeodesic(t *testing.T) {
+	tests := []struct {
+		name string
+		l    LineString
+		n    float64
+		want LineString
+	}{
+		{"equator", LineString(ring(0, 0, 90, 0)), 3, LineString(ring(0, 0, 30, 0, 60, 0, 90, 0))},
+		{"meridian", LineString(ring(10, 0, 10, 60)), 3, LineString(ring(10, 0, 10, 20, 10, 40, 10, 60))},
+		{"antimeridian", LineString(ring(170, 0, -170, 0)), 2, LineString(ring(170, 0, 180, 0, -170, 0))},
+		// The great circle between points at the same latitude passes
+		// nearer the pole, at atan(tan(60°)/cos(45°)).
+		{"parallel", LineString(ring(0, 60, 90, 60)), 2, LineString(ring(0, 60, 45, 67.79234570140408, 90, 60))},
+	}
+	for _, tt := range tests {
+		// A maximum a little more than the length over n gives n pieces.
+		max := geodesy.Haversine(tt.l[0].LatLng(), tt.l[len(tt.l)-1].LatLng()) / tt.n * (1 + 1e-9)
+		got := DensifyGeodesic(tt.l, max).(LineString)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: DensifyGeodesic(%v, %v) = %v, want %v", tt.name, tt.l, max, got, tt.want)
+			continue
+		}
+		for i, p := range got {
+			if math.Abs(p.X-tt.want[i].X) > 1e-9 || math.Abs(p.Y-tt.want[i].Y) > 1e-9 {
+				t.Errorf("%s: DensifyGeodesic(%v, %v) = %v, want %v", tt.name, tt.l, max, got, tt.want)
+				break
+			}
+		}
+	}
+	// Every segment is at most the maximum.
+	p := DensifyGeodesic(Polygon{ring(-120, 40, 120, 40, 0, -30, -120, 40)}, 100e3).(Polygon)
+	for i := 1; i < len(p[0]); i++ {
+		if d := geodesy.Haversine(p[0][i-1].LatLng(), p[0][i].LatLng()); d > 100e3 {
+			t.Fatalf("DensifyGeodesic has a segment of %v m", d)
+		}
+	}
+	if g := DensifyGeodesic(LineString(ring(0, 0, 90, 0)), 0); !reflect.DeepEqual(g, LineString(ring(0, 0, 90, 0))) {
+		t.Errorf("DensifyGeodesic(0) = %v, want unchanged", g)
+	}
+}
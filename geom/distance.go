@@ -0,0 +1,264 @@
+package geom
+
+import (
+	"math"
+	"slices"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// Distance returns the shortest distance between two geometries, in the
+// plane, between any point of one and any point of the other, or +Inf if
+// either is empty. The distance is 0 if the geometries intersect, as
+// when a point lies on a line or inside a polygon, or a line crosses the
+// ring of a polygon, and otherwise the shortest distance between their
+// points, segments and the rings of their polygons, found with exact
+// tests of whether segments meet.
+//
+// Every segment of one geometry is compared with every segment of the
+// other, except for those whose bounds are further apart than the
+// shortest distance found so far, taking time proportional to the
+// product of their numbers of points at worst.
+func Distance(a, b Geometry) float64 {
+	return distance(a, b, 0, planarMetric)
+}
+
+// DistanceWithin reports whether two geometries lie within a distance
+// of each other in the plane, as measured by Distance. It returns as
+// soon as it finds a pair of segments within the distance, so that it
+// is usually faster than comparing the distance.
+func DistanceWithin(a, b Geometry, d float64) bool {
+	return distance(a, b, d, planarMetric) <= d
+}
+
+// DistanceGeodesic returns the shortest distance in meters between two
+// geometries of longitudes and latitudes in degrees on the sphere
+// geodesy.Earth, as for Distance, with each segment taken as the
+// shortest great circle arc between its ends. Whether a point lies
+// inside a polygon is decided in the plane of the longitudes and
+// latitudes, as by Locate.
+func DistanceGeodesic(a, b Geometry) float64 {
+	return distance(a, b, 0, sphereMetric)
+}
+
+// DistanceWithinGeodesic reports whether two geometries of longitudes
+// and latitudes lie within a distance in meters of each other, as
+// measured by DistanceGeodesic, returning as soon as it finds a pair of
+// segments within the distance, as for DistanceWithin. It serves for
+// proximity alerts, such as whether a vehicle comes within a distance
+// of a restricted area.
+func DistanceWithinGeodesic(a, b Geometry, meters float64) bool {
+	return distance(a, b, meters, sphereMetric) <= meters
+}
+
+// metric measures distances between segments, with the bounds of the
+// points of a segment and a lower bound of the distance between the
+// points of two rectangles for pruning the comparisons.
+type metric struct {
+	segments func(s, t Segment) float64
+	bounds   func(s Segment) Rect
+	bound    func(r, s Rect) float64
+}
+
+// planarMetric measures distances in the plane.
+var planarMetric = metric{
+	segments: func(s, t Segment) float64 {
+		if s.Intersection(t).Kind != NoIntersection {
+			return 0
+		}
+		return min(t.Distance(s.A), t.Distance(s.B), s.Distance(t.A), s.Distance(t.B))
+	},
+	bounds: Segment.Bounds,
+	bound: func(r, s Rect) float64 {
+		dx := max(0, r.MinX-s.MaxX, s.MinX-r.MaxX)
+		dy := max(0, r.MinY-s.MaxY, s.MinY-r.MaxY)
+		return math.Hypot(dx, dy)
+	},
+}
+
+// sphereMetric measures distances on the sphere geodesy.Earth, bounding
+// the distance between rectangles by the difference of their latitudes,
+// which are those of the great circle arcs of the segments, not only of
+// their ends.
+var sphereMetric = metric{
+	segments: func(s, t Segment) float64 {
+		a, b, c, d := unitVector(s.A), unitVector(s.B), unitVector(t.A), unitVector(t.B)
+		if arcsCross(a, b, c, d) {
+			return 0
+		}
+		return geodesy.MeanRadius * min(arcAngle(a, c, d), arcAngle(b, c, d), arcAngle(c, a, b), arcAngle(d, a, b))
+	},
+	bounds: func(s Segment) Rect {
+		r := s.Bounds()
+		r.MinY, r.MaxY = arcLatitudes(s)
+		return r
+	},
+	bound: func(r, s Rect) float64 {
+		return geodesy.MeanRadius * degToRad * max(0, r.MinY-s.MaxY, s.MinY-r.MaxY)
+	},
+}
+
+// distance returns the shortest distance between two geometries
+// measured by a metric, or a distance no greater than within as soon as
+// one is found.
+func distance(a, b Geometry, within float64, m metric) float64 {
+	pa, la, qa := flatten(a)
+	pb, lb, qb := flatten(b)
+	sa, sb := parts(pa, la, qa), parts(pb, lb, qb)
+	if len(sa) == 0 || len(sb) == 0 {
+		return math.Inf(1)
+	}
+	if overlaps(pa, la, qa, pb) || overlaps(pb, lb, qb, pa) {
+		return 0
+	}
+	ra, rb := make([]Rect, len(sa)), make([]Rect, len(sb))
+	for i, s := range sa {
+		ra[i] = m.bounds(s)
+	}
+	for j, t := range sb {
+		rb[j] = m.bounds(t)
+	}
+	best := math.Inf(1)
+	for i, s := range sa {
+		for j, t := range sb {
+			if m.bound(ra[i], rb[j]) >= best {
+				continue
+			}
+			if d := m.segments(s, t); d < best {
+				if best = d; best <= within {
+					return best
+				}
+			}
+		}
+	}
+	return best
+}
+
+// parts returns the segments of the rings of polygons, of lines and of
+// points, each point, and each line of a single distinct point, a
+// segment of zero length.
+func parts(polys MultiPolygon, lines MultiLineString, points MultiPoint) []Segment {
+	var segs []Segment
+	for _, p := range polys {
+		for _, r := range p {
+			lines = append(lines, LineString(r))
+		}
+	}
+	for _, l := range lines {
+		if c := cleanLine(l); c != nil {
+			segs = append(segs, segments(c)...)
+		} else if i := slices.IndexFunc(l, finite); i >= 0 {
+			segs = append(segs, Segment{l[i], l[i]})
+		}
+	}
+	for _, p := range points {
+		if finite(p) {
+			segs = append(segs, Segment{p, p})
+		}
+	}
+	return segs
+}
+
+// overlaps reports whether a point of the polygons, lines or points of
+// one geometry lies inside or on the boundary of a polygon of another,
+// testing one point of each part, which suffices when no segments of the
+// geometries meet.
+func overlaps(polys MultiPolygon, lines MultiLineString, points MultiPoint, other MultiPolygon) bool {
+	if len(other) == 0 {
+		return false
+	}
+	var ps []Point
+	for _, p := range polys {
+		for _, r := range p {
+			if len(r) > 0 {
+				ps = append(ps, r[0])
+			}
+		}
+	}
+	for _, l := range lines {
+		if len(l) > 0 {
+			ps = append(ps, l[0])
+		}
+	}
+	ps = append(ps, points...)
+	for _, p := range ps {
+		if !p.IsEmpty() && CoversPoint(other, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// unitVector returns the unit vector from the center of the sphere
+// through the point of a longitude X and latitude Y.
+func unitVector(p Point) [3]float64 {
+	sinLat, cosLat := math.Sincos(p.Y * degToRad)
+	sinLng, cosLng := math.Sincos(p.X * degToRad)
+	return [3]float64{cosLat * cosLng, cosLat * sinLng, sinLat}
+}
+
+// cross3 returns the cross product of two vectors.
+func cross3(u, v [3]float64) [3]float64 {
+	return [3]float64{u[1]*v[2] - u[2]*v[1], u[2]*v[0] - u[0]*v[2], u[0]*v[1] - u[1]*v[0]}
+}
+
+// dot3 returns the dot product of two vectors.
+func dot3(u, v [3]float64) float64 {
+	return u[0]*v[0] + u[1]*v[1] + u[2]*v[2]
+}
+
+// angle3 returns the angle between two unit vectors.
+func angle3(u, v [3]float64) float64 {
+	c := cross3(u, v)
+	return math.Atan2(math.Sqrt(dot3(c, c)), dot3(u, v))
+}
+
+// arcAngle returns the angle from the unit vector p to the nearest point
+// of the great circle arc from a to b.
+func arcAngle(p, a, b [3]float64) float64 {
+	n := cross3(a, b)
+	if l := math.Sqrt(dot3(n, n)); l > 0 && dot3(cross3(a, p), n) > 0 && dot3(cross3(p, b), n) > 0 {
+		// The nearest point of the great circle lies on the arc.
+		return math.Asin(min(1, abs(dot3(p, n))/l))
+	}
+	return min(angle3(p, a), angle3(p, b))
+}
+
+// arcLatitudes returns the least and greatest latitudes of the points
+// of the great circle arc between the ends of a segment, which bulges
+// toward the nearer pole beyond the latitudes of its ends.
+func arcLatitudes(s Segment) (lo, hi float64) {
+	lo, hi = min(s.A.Y, s.B.Y), max(s.A.Y, s.B.Y)
+	a, b := unitVector(s.A), unitVector(s.B)
+	n := cross3(a, b)
+	// The northernmost point of the great circle, and its antipode, the
+	// southernmost, which the arc may pass through.
+	v := [3]float64{-n[2] * n[0], -n[2] * n[1], n[0]*n[0] + n[1]*n[1]}
+	if v == [3]float64{} {
+		return lo, hi // Degenerate, or along the equator
+	}
+	for _, w := range [][3]float64{v, {-v[0], -v[1], -v[2]}} {
+		if dot3(cross3(a, w), n) > 0 && dot3(cross3(w, b), n) > 0 {
+			lat := math.Asin(max(-1, min(1, w[2]/math.Sqrt(dot3(w, w))))) / degToRad
+			lo, hi = min(lo, lat), max(hi, lat)
+		}
+	}
+	return lo, hi
+}
+
+// arcsCross reports whether the great circle arcs from a to b and from c
+// to d cross.
+func arcsCross(a, b, c, d [3]float64) bool {
+	n1, n2 := cross3(a, b), cross3(c, d)
+	x := cross3(n1, n2)
+	if x == [3]float64{} {
+		return false // Degenerate, or on the same great circle
+	}
+	// Of the two points where the great circles cross, take the one on
+	// the side of the first arc.
+	if dot3(x, [3]float64{a[0] + b[0], a[1] + b[1], a[2] + b[2]}) < 0 {
+		x = [3]float64{-x[0], -x[1], -x[2]}
+	}
+	return dot3(cross3(a, x), n1) >= 0 && dot3(cross3(x, b), n1) >= 0 &&
+		dot3(cross3(c, x), n2) >= 0 && dot3(cross3(x, d), n2) >= 0
+}
@@ -0,0 +1,152 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+func TestDistance(t *testing.T) {
+	inf := math.Inf(1)
+	hole := Polygon{ring(2.5, 2.5, 3.5, 2.5, 3.5, 3.5, 2.5, 3.5, 2.5, 2.5)}
+	tests := []struct {
+		name string
+		a, b Geometry
+		want float64
+	}{
+		{"points", Point{X: 0, Y: 0}, Point{X: 3, Y: 4}, 5},
+		{"same point", Point{X: 1, Y: 1}, Point{X: 1, Y: 1, Z: 5}, 0},
+		{"point to line", Point{X: 0, Y: 1}, LineString(ring(-1, 0, 1, 0)), 1},
+		{"point past line", Point{X: 4, Y: 4}, LineString(ring(-1, 0, 1, 0)), 5},
+		{"point on line", Point{X: 0.5, Y: 0}, LineString(ring(-1, 0, 1, 0)), 0},
+		{"crossing lines", LineString(ring(0, -1, 0, 1)), LineString(ring(-1, 0, 1, 0)), 0},
+		{"parallel lines", LineString(ring(0, 0, 5, 0)), LineString(ring(2, 2, 9, 2)), 2},
+		{"skew lines", LineString(ring(0, 0, 1, 0)), LineString(ring(4, 4, 4, 8)), 5},
+		{"point in polygon", Point{X: 0.5, Y: 0.5}, square, 0},
+		{"point in hole", Point{X: 3, Y: 3}, donut, 1},
+		{"point outside", Point{X: 4, Y: 5}, square, 5},
+		{"polygon in hole", hole, donut, 0.5},
+		{"polygon in polygon", hole, square.Clone(), math.Hypot(1.5, 1.5)},
+		{"polygon inside", donut, Polygon{ring(5, 5, 6, 5, 6, 6, 5, 5)}, 0},
+		{"line in polygon", LineString(ring(5, 5, 6, 6)), donut, 0},
+		{"line through hole", LineString(ring(3, 2.5, 3, 3.5)), donut, 0.5},
+		{"polygons", square, Polygon{ring(3, 0, 4, 0, 4, 1, 3, 1, 3, 0)}, 2},
+		{"repeated point line", LineString(ring(3, 4, 3, 4)), Point{}, 5},
+		{"multi", MultiPoint(ring(10, 10, 0, 2)), MultiLineString{LineString(ring(-5, 0, 5, 0))}, 2},
+		{"collection", GeometryCollection{Point{X: 9, Y: 9}, LineString(ring(0, 3, 1, 3))}, square, 2},
+		{"empty", Point{}, LineString{}, inf},
+		{"empty point", EmptyPoint(), Point{}, inf},
+		{"empty collection", GeometryCollection{}, square, inf},
+	}
+	for _, tt := range tests {
+		if d := Distance(tt.a, tt.b); d != tt.want {
+			t.Errorf("%s: Distance(%v, %v) = %v, want %v", tt.name, tt.a, tt.b, d, tt.want)
+		}
+		if d := Distance(tt.b, tt.a); d != tt.want {
+			t.Errorf("%s: Distance(%v, %v) = %v, want %v", tt.name, tt.b, tt.a, d, tt.want)
+		}
+		if tt.want == inf {
+			continue
+		}
+		if !DistanceWithin(tt.a, tt.b, tt.want) {
+			t.Errorf("%s: DistanceWithin(%v, %v, %v) = false", tt.name, tt.a, tt.b, tt.want)
+		}
+		if tt.want > 0 && DistanceWithin(tt.a, tt.b, tt.want*0.99) {
+			t.Errorf("%s: DistanceWithin(%v, %v, %v) = true", tt.name, tt.a, tt.b, tt.want*0.99)
+		}
+	}
+	if DistanceWithin(Point{}, LineString{}, 1e300) {
+		t.Errorf("DistanceWithin(empty) = true")
+	}
+}
+
+func TestDistanceRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(78))
+	line := func() LineString {
+		l := make(LineString, 2+r.Intn(6))
+		for i := range l {
+			l[i] = Point{X: r.NormFloat64() * 10, Y: r.NormFloat64() * 10}
+		}
+		return l
+	}
+	for range 500 {
+		a, b := line(), line()
+		// The least distance between pairs of segments.
+		want := math.Inf(1)
+		for _, s := range segments(a) {
+			for _, u := range segments(b) {
+				if s.Intersection(u).Kind != NoIntersection {
+					want = 0
+				}
+				want = min(want, s.Distance(u.A), s.Distance(u.B), u.Distance(s.A), u.Distance(s.B))
+			}
+		}
+		if d := Distance(a, b); d != want {
+			t.Fatalf("Distance(%v, %v) = %v, want %v", a, b, d, want)
+		}
+		d := r.Float64() * 10
+		if w := DistanceWithin(a, b, d); w != (want <= d) {
+			t.Fatalf("DistanceWithin(%v, %v, %v) = %v, distance %v", a, b, d, w, want)
+		}
+	}
+}
+
+func TestDistanceGeodesic(t *testing.T) {
+	deg := geodesy.MeanRadius * math.Pi / 180
+	l := LineString(ring(0, 60, 90, 60))
+	tests := []struct {
+		name string
+		a, b Geometry
+		want float64
+	}{
+		{"points", Point{X: 0, Y: 0}, Point{X: 1, Y: 0}, deg},
+		{"antimeridian", Point{X: 179.5, Y: 0}, Point{X: -179.5, Y: 0}, deg},
+		{"point to equator", Point{X: 3, Y: 1}, LineString(ring(-10, 0, 10, 0)), deg},
+		{"point past line", Point{X: 12, Y: 0}, LineString(ring(-10, 0, 10, 0)), 2 * deg},
+		{"crossing", LineString(ring(0, -1, 0, 1)), LineString(ring(-1, 0, 1, 0)), 0},
+		{"point in polygon", Point{X: 0.5, Y: 0.5}, square, 0},
+		// The arc between points at the same latitude passes nearer the
+		// pole, through 67.79234570140408° at longitude 45°.
+		{"bulge", Point{X: 45, Y: 68}, l, (68 - 67.79234570140408) * deg},
+		{"bulge after", MultiPoint(ring(0, 66, 45, 68)), l, (68 - 67.79234570140408) * deg},
+		{"over the pole", Point{X: 90, Y: 89}, LineString(ring(0, 80, 180, 80)), deg},
+		{"empty", Point{}, LineString{}, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		if d := DistanceGeodesic(tt.a, tt.b); math.Abs(d-tt.want) > 1e-6 && d != tt.want {
+			t.Errorf("%s: DistanceGeodesic(%v, %v) = %v, want %v", tt.name, tt.a, tt.b, d, tt.want)
+		}
+		if within := tt.want*(1+1e-9) + 1e-6; tt.want != math.Inf(1) && !DistanceWithinGeodesic(tt.a, tt.b, within) {
+			t.Errorf("%s: DistanceWithinGeodesic(%v, %v, %v) = false", tt.name, tt.a, tt.b, within)
+		}
+		if tt.want > 1 && tt.want != math.Inf(1) && DistanceWithinGeodesic(tt.a, tt.b, tt.want*0.99) {
+			t.Errorf("%s: DistanceWithinGeodesic(%v, %v, %v) = true", tt.name, tt.a, tt.b, tt.want*0.99)
+		}
+	}
+}
+
+func TestDistanceGeodesicRandom(t *testing.T) {
+	// The distance from a point to a line is about the least distance to
+	// many points along its arcs, and no more.
+	r := rand.New(rand.NewSource(78))
+	for range 100 {
+		l := make(LineString, 2+r.Intn(4))
+		for i := range l {
+			l[i] = Point{X: r.Float64()*360 - 180, Y: r.Float64()*160 - 80}
+		}
+		p := Point{X: r.Float64()*360 - 180, Y: r.Float64()*160 - 80}
+		d := DistanceGeodesic(p, l)
+		want := math.Inf(1)
+		for i := 1; i < len(l); i++ {
+			for k := range 2001 {
+				q := geodesy.Intermediate(l[i-1].LatLng(), l[i].LatLng(), float64(k)/2000)
+				want = min(want, geodesy.Haversine(p.LatLng(), q))
+			}
+		}
+		if d > want+1e-6 || d < want-20e3 {
+			t.Fatalf("DistanceGeodesic(%v, %v) = %v, want about %v", p, l, d, want)
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package geom
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Earcut triangulates a polygon, returning the vertices of its rings,
+// without the closing point of each, one ring after another, and the
+// indices in the vertices of the vertices of triangles which together
+// cover the polygon, three to each triangle in counterclockwise order. The
+// vertices and indices suit the vertex and index buffers of a graphics
+// API for drawing the polygon filled.
+//
+// The triangulation is by ear clipping, as in the earcut library of
+// Mapbox: the holes are first joined to the exterior by bridges to the
+// nearest visible vertex, and ears, triangles of three consecutive
+// vertices which contain no other vertex, are then cut off the ring until
+// nothing is left. Polygons of many vertices are searched for vertices in
+// ears through an index in the order of a Z-order curve. The algorithm
+// takes time close to linear in the number of vertices in practice,
+// though quadratic at worst, and the triangles are not those of a
+// Delaunay triangulation, so may be thin. It tolerates polygons which are
+// not valid, such as those with self-intersections and duplicate points,
+// giving some triangulation of their area, but it may then leave some of
+// the area uncovered.
+func Earcut(p Polygon) ([]Point, []int) {
+	var vs []Point
+	var lists []*earNode
+	for i, r := range p {
+		ps := []Point(r)
+		if r.IsClosed() {
+			ps = ps[:len(ps)-1]
+		}
+		start := len(vs)
+		vs = append(vs, ps...)
+		if l := earList(vs, start, len(vs), i == 0); l != nil {
+			lists = append(lists, l)
+		} else if i == 0 {
+			return vs, nil
+		}
+	}
+	if len(lists) == 0 || lists[0].next == lists[0].prev {
+		return vs, nil
+	}
+	e := &earcut{}
+	outer := lists[0]
+	if len(lists) > 1 {
+		outer = eliminateHoles(lists[1:], outer)
+	}
+	if len(vs) > 80 {
+		b := EmptyRect()
+		for _, v := range p.Exterior() {
+			b = b.Extend(v)
+		}
+		e.min = Point{X: b.MinX, Y: b.MinY}
+		if s := max(b.Width(), b.Height()); s != 0 {
+			e.invSize = 32767 / s
+		}
+	}
+	e.linked(outer, 0)
+	return vs, e.triangles
+}
+
+// earNode is a vertex of a ring in the doubly linked lists of earcut,
+// linked also in the order of a Z-order curve.
+type earNode struct {
+	i            int
+	p            Point
+	prev, next   *earNode
+	z            int
+	prevZ, nextZ *earNode
+	steiner      bool
+}
+
+// earcut is the state of Earcut: the triangles found, and the bounds and
+// scale of the Z-order curve, whose scale is zero if it is not used.
+type earcut struct {
+	triangles []int
+	min       Point
+	invSize   float64
+}
+
+// earList returns the last node of a circular list of the vertices from
+// start to end, running counterclockwise for an exterior and clockwise
+// for a hole, or nil if there are none.
+func earList(vs []Point, start, end int, exterior bool) *earNode {
+	var last *earNode
+	if exterior == (signedArea(vs[start:end]) > 0) {
+		for i := start; i < end; i++ {
+			last = insertEarNode(i, vs[i], last)
+		}
+	} else {
+		for i := end - 1; i >= start; i-- {
+			last = insertEarNode(i, vs[i], last)
+		}
+	}
+	if last != nil && last.p.Equal(last.next.p) {
+		removeEarNode(last)
+		last = last.next
+	}
+	return last
+}
+
+// filterPoints removes duplicate and collinear points from the list from
+// start to end, and returns the new end.
+func filterPoints(start, end *earNode) *earNode {
+	if start == nil {
+		return start
+	}
+	if end == nil {
+		end = start
+	}
+	p := start
+	for {
+		again := false
+		if !p.steiner && (p.p.Equal(p.next.p) || turn(p.prev, p, p.next) == 0) {
+			removeEarNode(p)
+			p, end = p.prev, p.prev
+			if p == p.next {
+				break
+			}
+			again = true
+		} else {
+			p = p.next
+		}
+		if !again && p == end {
+			break
+		}
+	}
+	return end
+}
+
+// linked cuts the ears of a list in one of three passes: cutting ears
+// alone, then after removing duplicate points, then after curing small
+// self-intersections, before finally splitting the list in two and
+// starting again on each.
+func (e *earcut) linked(ear *earNode, pass int) {
+	if ear == nil {
+		return
+	}
+	if pass == 0 && e.invSize != 0 {
+		e.indexCurve(ear)
+	}
+	stop := ear
+	for ear.prev != ear.next {
+		prev, next := ear.prev, ear.next
+		if e.isEar(ear) {
+			e.triangles = append(e.triangles, prev.i, ear.i, next.i)
+			removeEarNode(ear)
+			// Skipping the next vertex leaves fewer thin triangles.
+			ear, stop = next.next, next.next
+			continue
+		}
+		ear = next
+		if ear == stop {
+			switch pass {
+			case 0:
+				e.linked(filterPoints(ear, nil), 1)
+			case 1:
+				e.linked(e.cureLocalIntersections(filterPoints(ear, nil)), 2)
+			case 2:
+				e.splitEarcut(ear)
+			}
+			return
+		}
+	}
+}
+
+// isEar reports whether the vertex of a list is convex and the triangle
+// it forms with its neighbors contains no reflex vertex.
+func (e *earcut) isEar(ear *earNode) bool {
+	a, b, c := ear.prev, ear, ear.next
+	if turn(a, b, c) <= 0 {
+		return false // Reflex
+	}
+	r := RectOf(a.p, b.p, c.p)
+	inEar := func(p *earNode) bool {
+		return p != a && p != c && r.Contains(p.p) &&
+			inTriangle(a.p, b.p, c.p, p.p) && turn(p.prev, p, p.next) <= 0
+	}
+	if e.invSize == 0 {
+		for p := c.next; p != a; p = p.next {
+			if inEar(p) {
+				return false
+			}
+		}
+		return true
+	}
+	// Look for points inside the triangle in both directions along the
+	// curve, between the curve values of its bounds.
+	minZ, maxZ := e.zOrder(Point{X: r.MinX, Y: r.MinY}), e.zOrder(Point{X: r.MaxX, Y: r.MaxY})
+	p, n := ear.prevZ, ear.nextZ
+	for p != nil && p.z >= minZ && n != nil && n.z <= maxZ {
+		if inEar(p) || inEar(n) {
+			return false
+		}
+		p, n = p.prevZ, n.nextZ
+	}
+	for ; p != nil && p.z >= minZ; p = p.prevZ {
+		if inEar(p) {
+			return false
+		}
+	}
+	for ; n != nil && n.z <= maxZ; n = n.nextZ {
+		if inEar(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// cureLocalIntersections cuts off the triangles where two consecutive
+// edges of a list cross, and returns the list with duplicate points
+// removed.
+func (e *earcut) cureLocalIntersections(start *earNode) *earNode {
+	p := start
+	for {
+		a, b := p.prev, p.next.next
+		if !a.p.Equal(b.p) && earIntersects(a, p, p.next, b) && locallyInside(a, b) && locallyInside(b, a) {
+			e.triangles = append(e.triangles, a.i, p.i, b.i)
+			removeEarNode(p)
+			removeEarNode(p.next)
+			p, start = b, b
+		}
+		if p = p.next; p == start {
+			break
+		}
+	}
+	return filterPoints(p, nil)
+}
+
+// splitEarcut splits a list in two along a valid diagonal, and cuts the
+// ears of each.
+func (e *earcut) splitEarcut(start *earNode) {
+	a := start
+	for {
+		for b := a.next.next; b != a.prev; b = b.next {
+			if a.i != b.i && isValidDiagonal(a, b) {
+				c := splitEarPolygon(a, b)
+				a = filterPoints(a, a.next)
+				c = filterPoints(c, c.next)
+				e.linked(a, 0)
+				e.linked(c, 0)
+				return
+			}
+		}
+		if a = a.next; a == start {
+			return
+		}
+	}
+}
+
+// eliminateHoles joins the lists of the holes to the list of the
+// exterior, from left to right, and returns the joined list.
+func eliminateHoles(holes []*earNode, outer *earNode) *earNode {
+	queue := make([]*earNode, len(holes))
+	for i, h := range holes {
+		if h == h.next {
+			h.steiner = true
+		}
+		queue[i] = leftmost(h)
+	}
+	slices.SortStableFunc(queue, func(a, b *earNode) int { return cmp.Compare(a.p.X, b.p.X) })
+	for _, h := range queue {
+		if bridge := findHoleBridge(h, outer); bridge != nil {
+			r := splitEarPolygon(bridge, h)
+			filterPoints(r, r.next)
+			outer = filterPoints(bridge, bridge.next)
+		}
+	}
+	return outer
+}
+
+// findHoleBridge returns the vertex of the exterior to join to the
+// leftmost vertex of a hole, found by David Eberly's algorithm, or nil if
+// there is none.
+func findHoleBridge(hole, outer *earNode) *earNode {
+	h := hole.p
+	qx := math.Inf(-1)
+	var m *earNode
+	// Find the edge crossed by a ray from the vertex of the hole to the
+	// left, and the endpoint of the edge to the left.
+	for p := outer; ; {
+		if h.Y <= p.p.Y && h.Y >= p.next.p.Y && p.next.p.Y != p.p.Y {
+			x := p.p.X + (h.Y-p.p.Y)*(p.next.p.X-p.p.X)/(p.next.p.Y-p.p.Y)
+			if x <= h.X && x > qx {
+				qx = x
+				m = p
+				if p.next.p.X < p.p.X {
+					m = p.next
+				}
+				if x == h.X {
+					return m // The hole touches the edge
+				}
+			}
+		}
+		if p = p.next; p == outer {
+			break
+		}
+	}
+	if m == nil {
+		return nil
+	}
+	// Of the vertices inside the triangle of the vertex of the hole, the
+	// point the ray crosses the edge and the endpoint, which would block
+	// the bridge, choose the one at the least angle to the ray.
+	stop, mp, tanMin := m, m.p, math.Inf(1)
+	a, c := Point{X: h.X, Y: h.Y}, Point{X: qx, Y: h.Y}
+	if h.Y >= mp.Y {
+		a, c = c, a
+	}
+	for p := m; ; {
+		if h.X >= p.p.X && p.p.X >= mp.X && h.X != p.p.X && inTriangle(a, mp, c, p.p) {
+			tan := abs(h.Y-p.p.Y) / (h.X - p.p.X)
+			if locallyInside(p, hole) && (tan < tanMin || tan == tanMin && (p.p.X > m.p.X || p.p.X == m.p.X && sectorContainsSector(m, p))) {
+				m, tanMin = p, tan
+			}
+		}
+		if p = p.next; p == stop {
+			break
+		}
+	}
+	return m
+}
+
+// sectorContainsSector reports whether the sector of the vertex m
+// contains the sector of p.
+func sectorContainsSector(m, p *earNode) bool {
+	return turn(m.prev, m, p.prev) > 0 && turn(p.next, m, m.next) > 0
+}
+
+// indexCurve links the nodes of a list in the order of their values on
+// the Z-order curve.
+func (e *earcut) indexCurve(start *earNode) {
+	var nodes []*earNode
+	for p := start; ; {
+		if p.z == 0 {
+			p.z = e.zOrder(p.p)
+		}
+		nodes = append(nodes, p)
+		if p = p.next; p == start {
+			break
+		}
+	}
+	slices.SortStableFunc(nodes, func(a, b *earNode) int { return cmp.Compare(a.z, b.z) })
+	for i, p := range nodes {
+		p.prevZ, p.nextZ = nil, nil
+		if i > 0 {
+			p.prevZ = nodes[i-1]
+		}
+		if i+1 < len(nodes) {
+			p.nextZ = nodes[i+1]
+		}
+	}
+}
+
+// zOrder returns the value of a point on the Z-order curve, interleaving
+// the bits of its coordinates scaled to 15 bits.
+func (e *earcut) zOrder(p Point) int {
+	spread := func(v int) int {
+		v = (v | v<<8) & 0x00FF00FF
+		v = (v | v<<4) & 0x0F0F0F0F
+		v = (v | v<<2) & 0x33333333
+		return (v | v<<1) & 0x55555555
+	}
+	x := int((p.X - e.min.X) * e.invSize)
+	y := int((p.Y - e.min.Y) * e.invSize)
+	return spread(x) | spread(y)<<1
+}
+
+// leftmost returns the leftmost vertex of a list, the lowest of those
+// furthest left.
+func leftmost(start *earNode) *earNode {
+	l := start
+	for p := start.next; p != start; p = p.next {
+		if p.p.X < l.p.X || p.p.X == l.p.X && p.p.Y < l.p.Y {
+			l = p
+		}
+	}
+	return l
+}
+
+// inTriangle reports whether the point p lies in the counterclockwise
+// triangle a, b, c, or on its boundary.
+func inTriangle(a, b, c, p Point) bool {
+	return orient(c, a, p) >= 0 && orient(a, b, p) >= 0 && orient(b, c, p) >= 0
+}
+
+// isValidDiagonal reports whether a diagonal from a to b splits a list
+// in two without crossing it.
+func isValidDiagonal(a, b *earNode) bool {
+	if a.next.i == b.i || a.prev.i == b.i || intersectsList(a, b) {
+		return false
+	}
+	if locallyInside(a, b) && locallyInside(b, a) && middleInside(a, b) &&
+		(turn(a.prev, a, b.prev) != 0 || turn(a, b.prev, b) != 0) {
+		return true // Locally visible, and not making opposite sectors
+	}
+	// A diagonal of zero length between convex vertices.
+	return a.p.Equal(b.p) && turn(a.prev, a, a.next) < 0 && turn(b.prev, b, b.next) < 0
+}
+
+// turn returns the orientation of three nodes, positive if they turn
+// counterclockwise.
+func turn(p, q, r *earNode) int {
+	return orient(p.p, q.p, r.p)
+}
+
+// earIntersects reports whether the segments from p1 to q1 and from p2
+// to q2 intersect.
+func earIntersects(p1, q1, p2, q2 *earNode) bool {
+	o1, o2 := turn(p1, q1, p2), turn(p1, q1, q2)
+	o3, o4 := turn(p2, q2, p1), turn(p2, q2, q1)
+	// on reports whether q, collinear with p and r, lies between them.
+	on := func(p, q, r *earNode) bool { return RectOf(p.p, r.p).Contains(q.p) }
+	return o1 != o2 && o3 != o4 ||
+		o1 == 0 && on(p1, p2, q1) || o2 == 0 && on(p1, q2, q1) ||
+		o3 == 0 && on(p2, p1, q2) || o4 == 0 && on(p2, q1, q2)
+}
+
+// intersectsList reports whether the diagonal from a to b crosses an
+// edge of the list other than those at a and b.
+func intersectsList(a, b *earNode) bool {
+	for p := a; ; {
+		if p.i != a.i && p.next.i != a.i && p.i != b.i && p.next.i != b.i && earIntersects(p, p.next, a, b) {
+			return true
+		}
+		if p = p.next; p == a {
+			return false
+		}
+	}
+}
+
+// locallyInside reports whether the diagonal from a to b starts into
+// the polygon at a.
+func locallyInside(a, b *earNode) bool {
+	if turn(a.prev, a, a.next) > 0 {
+		return turn(a, b, a.next) <= 0 && turn(a, a.prev, b) <= 0
+	}
+	return turn(a, b, a.prev) > 0 || turn(a, a.next, b) > 0
+}
+
+// middleInside reports whether the middle of the diagonal from a to b
+// lies inside the polygon.
+func middleInside(a, b *earNode) bool {
+	in := false
+	m := Point{X: (a.p.X + b.p.X) / 2, Y: (a.p.Y + b.p.Y) / 2}
+	for p := a; ; {
+		if (p.p.Y > m.Y) != (p.next.p.Y > m.Y) && p.next.p.Y != p.p.Y &&
+			m.X < (p.next.p.X-p.p.X)*(m.Y-p.p.Y)/(p.next.p.Y-p.p.Y)+p.p.X {
+			in = !in
+		}
+		if p = p.next; p == a {
+			return in
+		}
+	}
+}
+
+// splitEarPolygon joins the vertices a and b by a diagonal, splitting
+// their list in two if they are in the same list, or joining their lists
+// by a bridge there and back if not, and returns the copy of b.
+func splitEarPolygon(a, b *earNode) *earNode {
+	a2 := &earNode{i: a.i, p: a.p}
+	b2 := &earNode{i: b.i, p: b.p}
+	an, bp := a.next, b.prev
+	a.next, b.prev = b, a
+	a2.next, an.prev = an, a2
+	b2.next, a2.prev = a2, b2
+	bp.next, b2.prev = b2, bp
+	return b2
+}
+
+// insertEarNode inserts a node for the vertex i after last, and returns
+// it.
+func insertEarNode(i int, p Point, last *earNode) *earNode {
+	n := &earNode{i: i, p: p}
+	if last == nil {
+		n.prev, n.next = n, n
+	} else {
+		n.next, n.prev = last.next, last
+		last.next.prev = n
+		last.next = n
+	}
+	return n
+}
+
+// removeEarNode removes a node from its lists.
+func removeEarNode(p *earNode) {
+	p.next.prev = p.prev
+	p.prev.next = p.next
+	if p.prevZ != nil {
+		p.prevZ.nextZ = p.nextZ
+	}
+	if p.nextZ != nil {
+		p.nextZ.prevZ = p.prevZ
+	}
+}
@@ -0,0 +1,121 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkEarcut checks that the triangles of Earcut are counterclockwise
+// and cover the area of a valid polygon, and returns them as polygons.
+func checkEarcut(t *testing.T, p Polygon) MultiPolygon {
+	t.Helper()
+	vs, tris := Earcut(p)
+	n := len(vs)
+	if len(tris) != 3*(n+2*(len(p)-1)-2) {
+		t.Fatalf("Earcut() = %d triangles of %d vertices and %d holes, want %d", len(tris)/3, n, len(p)-1, n+2*(len(p)-1)-2)
+	}
+	var mp MultiPolygon
+	a := 0.0
+	for i := 0; i < len(tris); i += 3 {
+		r := Ring{vs[tris[i]], vs[tris[i+1]], vs[tris[i+2]], vs[tris[i]]}
+		if signedArea(r) <= 0 {
+			t.Fatalf("triangle %v is not counterclockwise", tris[i:i+3])
+		}
+		a += signedArea(r)
+		mp = append(mp, Polygon{r})
+	}
+	if want := polygonArea(p); math.Abs(a-want) > 1e-9*want {
+		t.Fatalf("triangles have area %v, want %v", a, want)
+	}
+	return mp
+}
+
+func TestEarcut(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         Polygon
+		vertices  []Point
+		triangles []int
+	}{
+		{"square", square, square[0][:4], []int{2, 3, 0, 0, 1, 2}},
+		{"triangle", Polygon{ring(0, 0, 2, 0, 1, 1)}, ring(0, 0, 2, 0, 1, 1), []int{1, 2, 0}},
+		{"clockwise", Polygon{ring(0, 0, 0, 2, 2, 2, 2, 0, 0, 0)}, ring(0, 0, 0, 2, 2, 2, 2, 0), []int{1, 0, 3, 3, 2, 1}},
+		{"donut", donut, append(slices.Clone(donut[0][:4]), donut[1][:4]...),
+			[]int{4, 3, 0, 1, 2, 3, 3, 4, 5, 7, 4, 0, 3, 5, 6, 7, 0, 1, 1, 3, 6, 6, 7, 1}},
+		{"c", cShape, cShape[0][:8], []int{0, 1, 2, 4, 5, 6, 0, 2, 3, 4, 6, 7, 7, 0, 3, 3, 4, 7}},
+		{"collinear", Polygon{ring(0, 0, 1, 1, 2, 2, 0, 0)}, ring(0, 0, 1, 1, 2, 2), nil},
+		{"line", Polygon{ring(0, 0, 1, 1)}, ring(0, 0, 1, 1), nil},
+		{"empty", Polygon{}, nil, nil},
+	}
+	for _, tt := range tests {
+		vs, tris := Earcut(tt.p)
+		if !slices.Equal(vs, tt.vertices) || !slices.Equal(tris, tt.triangles) {
+			t.Errorf("%s: Earcut() = %v, %v, want %v, %v", tt.name, vs, tris, tt.vertices, tt.triangles)
+		}
+		if len(tt.triangles) > 0 {
+			checkEarcut(t, tt.p)
+		}
+	}
+}
+
+// starPolygon returns a random star-shaped polygon of n vertices around
+// c, with holes at the given fractions of its smallest radius.
+func starPolygon(r *rand.Rand, c Point, n int, holes ...float64) Polygon {
+	shell := make(Ring, 0, n+1)
+	for i := range n {
+		s, co := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		d := 50 + 50*r.Float64()
+		shell = append(shell, Point{X: c.X + co*d, Y: c.Y + s*d})
+	}
+	p := Polygon{append(shell, shell[0])}
+	for k, f := range holes {
+		// Small squares along the x axis, away from one another, and
+		// moved a little so that no three of their vertices are in line.
+		s := 50 * f / float64(2*len(holes)+2)
+		x := c.X + 50*f*float64(2*k-len(holes)+1)/float64(len(holes)+1) + s*r.Float64()/4
+		y := c.Y + s*r.Float64()/4
+		p = append(p, Ring{{X: x - s, Y: y - s}, {X: x - s, Y: y + s}, {X: x + s, Y: y + s}, {X: x + s, Y: y - s}, {X: x - s, Y: y - s}})
+	}
+	return p
+}
+
+func TestEarcutRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(75))
+	for _, n := range []int{5, 20, 79, 200, 2000} {
+		for _, holes := range [][]float64{nil, {0.5}, {0.8, 0.8, 0.8}} {
+			p := starPolygon(r, Point{X: 1000, Y: -1000}, n, holes...)
+			if err := Validate(p); err != nil {
+				t.Fatalf("starPolygon(%d, %v): %v", n, holes, err)
+			}
+			tris := checkEarcut(t, p)
+			// Each point of the polygon is in some triangle.
+			b := Bounds(p)
+			for range 200 {
+				q := Point{X: b.MinX + r.Float64()*b.Width(), Y: b.MinY + r.Float64()*b.Height()}
+				if ContainsPoint(p, q) && !CoversPoint(tris, q) {
+					t.Fatalf("%d vertices, %d holes: %v is in no triangle", n, len(holes), q)
+				}
+			}
+		}
+	}
+}
+
+func TestEarcutInvalid(t *testing.T) {
+	// A bow tie crosses itself, and a ring with a duplicate vertex
+	// touches itself; each is still triangulated.
+	bow := Polygon{ring(0, 0, 2, 2, 2, 0, 0, 2, 0, 0)}
+	if _, tris := Earcut(bow); len(tris) == 0 || len(tris)%3 != 0 {
+		t.Errorf("Earcut(bow tie) = %v", tris)
+	}
+	dup := Polygon{ring(0, 0, 1, 0, 1, 0, 1, 1, 0, 1, 0, 0)}
+	vs, tris := Earcut(dup)
+	a := 0.0
+	for i := 0; i < len(tris); i += 3 {
+		a += signedArea([]Point{vs[tris[i]], vs[tris[i+1]], vs[tris[i+2]]})
+	}
+	if a != 1 {
+		t.Errorf("Earcut(duplicate) = %v, area %v, want 1", tris, a)
+	}
+}
@@ -0,0 +1,236 @@
+// Package geom defines the geometry types shared by the packages which
+// read, write, index and compute with vector geometries: points, line
+// strings and polygons, their multipart forms, and collections of them,
+// following the Simple Features model of the OGC.
+//
+// Coordinates are planar, X increasing to the right and Y upwards. When
+// a geometry holds positions on the Earth, X is the longitude and Y the
+// latitude in degrees, in that order, as in GeoJSON and WKT. Every Point
+// also has Z and M fields for an elevation and a measure. Whether they
+// are meaningful is a property of the data, given by a Layout, which is
+// kept by the code that reads and writes geometries rather than by the
+// geometries themselves, and the computations of this package and those
+// built on it ignore them.
+//
+// The extent of a geometry is given by its Bounds, a Rect in the plane,
+// or by its LatLngBounds, a LatLngRect which may cross the antimeridian.
+//
+// The geometry types are plain values and slices, so they may be
+// constructed with composite literals:
+//
+//	square := geom.Polygon{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}}
+package geom
+
+import (
+	"iter"
+	"math"
+	"strconv"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// Geometry is implemented by every geometry type: Point, LineString,
+// Polygon, MultiPoint, MultiLineString, MultiPolygon and
+// GeometryCollection.
+type Geometry interface {
+	// Type returns the type of the geometry.
+	Type() Type
+	// IsEmpty reports whether the geometry is empty, containing no
+	// points at all.
+	IsEmpty() bool
+	// Dimension returns the topological dimension of the geometry: 0
+	// for points, 1 for lines and 2 for polygons. The dimension of a
+	// collection is the greatest dimension of its members.
+	Dimension() int
+}
+
+// Type is the type of a geometry.
+type Type int
+
+// The geometry types.
+const (
+	TypePoint Type = iota + 1
+	TypeLineString
+	TypePolygon
+	TypeMultiPoint
+	TypeMultiLineString
+	TypeMultiPolygon
+	TypeGeometryCollection
+)
+
+// typeNames holds the names of the types, by type.
+var typeNames = [...]string{
+	TypePoint:              "Point",
+	TypeLineString:         "LineString",
+	TypePolygon:            "Polygon",
+	TypeMultiPoint:         "MultiPoint",
+	TypeMultiLineString:    "MultiLineString",
+	TypeMultiPolygon:       "MultiPolygon",
+	TypeGeometryCollection: "GeometryCollection",
+}
+
+// String returns the name of the type as in GeoJSON and WKT, such as
+// "LineString", or "Type(n)" for an invalid type.
+func (t Type) String() string {
+	if t > 0 && int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return "Type(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Layout tells which of the coordinates of the points of a geometry are
+// meaningful. X and Y always are.
+type Layout int
+
+// The layouts.
+const (
+	XY Layout = iota
+	XYZ
+	XYM
+	XYZM
+)
+
+// HasZ reports whether the layout includes the Z coordinate.
+func (l Layout) HasZ() bool {
+	return l == XYZ || l == XYZM
+}
+
+// HasM reports whether the layout includes the M coordinate.
+func (l Layout) HasM() bool {
+	return l == XYM || l == XYZM
+}
+
+// String returns the name of the layout, such as "XYZ".
+func (l Layout) String() string {
+	s := "XY"
+	if l.HasZ() {
+		s += "Z"
+	}
+	if l.HasM() {
+		s += "M"
+	}
+	return s
+}
+
+// Point is a single position, with an optional elevation Z and measure
+// M. A point whose X or Y is NaN is empty.
+type Point struct {
+	X, Y, Z, M float64
+}
+
+// EmptyPoint returns an empty point, one whose coordinates are all NaN.
+func EmptyPoint() Point {
+	nan := math.NaN()
+	return Point{nan, nan, nan, nan}
+}
+
+// FromLatLng returns the point with X the longitude and Y the latitude
+// of a position.
+//
+// The complementary method Point.LatLng performs the inverse mapping.
+func FromLatLng(p geodesy.LatLng) Point {
+	return Point{X: p.Lng, Y: p.Lat}
+}
+
+// LatLng returns the position with latitude Y and longitude X.
+//
+// The complementary function FromLatLng performs the inverse mapping.
+func (p Point) LatLng() geodesy.LatLng {
+	return geodesy.LatLng{Lat: p.Y, Lng: p.X}
+}
+
+// Type returns TypePoint.
+func (p Point) Type() Type { return TypePoint }
+
+// IsEmpty reports whether X or Y is NaN.
+func (p Point) IsEmpty() bool { return math.IsNaN(p.X) || math.IsNaN(p.Y) }
+
+// Dimension returns 0.
+func (p Point) Dimension() int { return 0 }
+
+// Equal reports whether two points have the same X and Y, ignoring Z
+// and M.
+func (p Point) Equal(q Point) bool {
+	return p.X == q.X && p.Y == q.Y
+}
+
+// Points returns an iterator over the points of a geometry, in order,
+// omitting empty points.
+func Points(g Geometry) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		points(g, yield)
+	}
+}
+
+// points calls yield with each point of g in turn, returning false if
+// yield did.
+func points(g Geometry, yield func(Point) bool) bool {
+	switch g := g.(type) {
+	case Point:
+		return g.IsEmpty() || yield(g)
+	case LineString:
+		return yieldAll(g, yield)
+	case MultiPoint:
+		for _, p := range g {
+			if !p.IsEmpty() && !yield(p) {
+				return false
+			}
+		}
+	case Polygon:
+		for _, r := range g {
+			if !yieldAll(r, yield) {
+				return false
+			}
+		}
+	case MultiLineString:
+		for _, l := range g {
+			if !yieldAll(l, yield) {
+				return false
+			}
+		}
+	case MultiPolygon:
+		for _, p := range g {
+			if !points(p, yield) {
+				return false
+			}
+		}
+	case GeometryCollection:
+		for _, m := range g {
+			if !points(m, yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// yieldAll calls yield with each of the points in turn, returning false
+// if yield did.
+func yieldAll(ps []Point, yield func(Point) bool) bool {
+	for _, p := range ps {
+		if !yield(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a deep copy of a geometry, which shares no storage with
+// the original.
+func Clone(g Geometry) Geometry {
+	switch g := g.(type) {
+	case LineString:
+		return g.Clone()
+	case Polygon:
+		return g.Clone()
+	case MultiPoint:
+		return g.Clone()
+	case MultiLineString:
+		return g.Clone()
+	case MultiPolygon:
+		return g.Clone()
+	case GeometryCollection:
+		return g.Clone()
+	}
+	return g
+}
@@ -0,0 +1,171 @@
+package geom
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// square is the unit square with a closed exterior ring.
+var square = Polygon{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}}
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		g    Geometry
+		typ  Type
+		name string
+		dim  int
+	}{
+		{Point{}, TypePoint, "Point", 0},
+		{LineString{}, TypeLineString, "LineString", 1},
+		{square, TypePolygon, "Polygon", 2},
+		{MultiPoint{}, TypeMultiPoint, "MultiPoint", 0},
+		{MultiLineString{}, TypeMultiLineString, "MultiLineString", 1},
+		{MultiPolygon{}, TypeMultiPolygon, "MultiPolygon", 2},
+		{GeometryCollection{}, TypeGeometryCollection, "GeometryCollection", 0},
+		{GeometryCollection{Point{}, square, LineString{}}, TypeGeometryCollection, "GeometryCollection", 2},
+	}
+	for _, tt := range tests {
+		if typ := tt.g.Type(); typ != tt.typ || typ.String() != tt.name {
+			t.Errorf("%v Type() = %v, want %v", tt.g, typ, tt.name)
+		}
+		if d := tt.g.Dimension(); d != tt.dim {
+			t.Errorf("%v Dimension() = %d, want %d", tt.g, d, tt.dim)
+		}
+	}
+	for _, typ := range []Type{0, 8, -1} {
+		if s, want := typ.String(), fmt.Sprintf("Type(%d)", int(typ)); s != want {
+			t.Errorf("Type(%d).String() = %q, want %q", int(typ), s, want)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		l          Layout
+		name       string
+		hasZ, hasM bool
+	}{
+		{XY, "XY", false, false},
+		{XYZ, "XYZ", true, false},
+		{XYM, "XYM", false, true},
+		{XYZM, "XYZM", true, true},
+	}
+	for _, tt := range tests {
+		if tt.l.String() != tt.name || tt.l.HasZ() != tt.hasZ || tt.l.HasM() != tt.hasM {
+			t.Errorf("Layout %d = %q, HasZ %v, HasM %v, want %q, %v, %v", tt.l, tt.l, tt.l.HasZ(), tt.l.HasM(), tt.name, tt.hasZ, tt.hasM)
+		}
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		g    Geometry
+		want bool
+	}{
+		{Point{}, false},
+		{EmptyPoint(), true},
+		{Point{X: nan, Y: 1}, true},
+		{Point{X: 1, Y: 2, Z: nan, M: nan}, false},
+		{LineString(nil), true},
+		{LineString{{}, {X: 1}}, false},
+		{Polygon(nil), true},
+		{Polygon{{}}, true},
+		{Polygon{{}, square[0]}, true},
+		{square, false},
+		{MultiPoint{EmptyPoint()}, true},
+		{MultiPoint{EmptyPoint(), {}}, false},
+		{MultiLineString{nil, {}}, true},
+		{MultiPolygon{nil, {{}}}, true},
+		{MultiPolygon{nil, square}, false},
+		{GeometryCollection{}, true},
+		{GeometryCollection{EmptyPoint(), MultiPolygon{}}, true},
+		{GeometryCollection{EmptyPoint(), GeometryCollection{Point{}}}, false},
+	}
+	for _, tt := range tests {
+		if e := tt.g.IsEmpty(); e != tt.want {
+			t.Errorf("%v IsEmpty() = %v, want %v", tt.g, e, tt.want)
+		}
+	}
+}
+
+func TestPoint(t *testing.T) {
+	p := Point{X: 1, Y: 2, Z: 3, M: 4}
+	if !p.Equal(Point{X: 1, Y: 2}) || p.Equal(Point{X: 2, Y: 1}) || EmptyPoint().Equal(EmptyPoint()) {
+		t.Errorf("Equal ignores X and Y, or considers Z and M")
+	}
+	ll := geodesy.LatLng{Lat: 51.5, Lng: -0.1}
+	if q := FromLatLng(ll); q != (Point{X: -0.1, Y: 51.5}) || q.LatLng() != ll {
+		t.Errorf("FromLatLng(%v) = %v, LatLng() = %v", ll, q, q.LatLng())
+	}
+	e := EmptyPoint()
+	if !math.IsNaN(e.X) || !math.IsNaN(e.Y) || !math.IsNaN(e.Z) || !math.IsNaN(e.M) {
+		t.Errorf("EmptyPoint() = %v, want all NaN", e)
+	}
+}
+
+func TestPoints(t *testing.T) {
+	a, b, c, d := Point{X: 1}, Point{X: 2}, Point{X: 3}, Point{X: 4}
+	tests := []struct {
+		g    Geometry
+		want []Point
+	}{
+		{a, []Point{a}},
+		{EmptyPoint(), nil},
+		{LineString{a, b}, []Point{a, b}},
+		{MultiPoint{a, EmptyPoint(), b}, []Point{a, b}},
+		{Polygon{{a, b, c, a}, {d}}, []Point{a, b, c, a, d}},
+		{MultiLineString{{a}, {}, {b, c}}, []Point{a, b, c}},
+		{MultiPolygon{{{a}}, {{b}, {c}}}, []Point{a, b, c}},
+		{GeometryCollection{a, GeometryCollection{LineString{b, c}}, MultiPoint{d}}, []Point{a, b, c, d}},
+		{GeometryCollection{}, nil},
+	}
+	for _, tt := range tests {
+		if ps := slices.Collect(Points(tt.g)); !slices.Equal(ps, tt.want) {
+			t.Errorf("Points(%v) = %v, want %v", tt.g, ps, tt.want)
+		}
+	}
+	// Iteration stops when asked, even deep in a collection.
+	g := GeometryCollection{MultiPolygon{{{a, b, c, a}}}, LineString{d}}
+	var got []Point
+	for p := range Points(g) {
+		got = append(got, p)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, []Point{a, b}) {
+		t.Errorf("Points(%v) stopped after %v, want [a b]", g, got)
+	}
+}
+
+func TestClone(t *testing.T) {
+	gc := GeometryCollection{
+		Point{X: 1},
+		LineString{{X: 1}, {X: 2}},
+		square.Clone(),
+		MultiPoint{{X: 3}},
+		MultiLineString{{{X: 4}, {X: 5}}},
+		MultiPolygon{square.Clone()},
+	}
+	c := Clone(gc).(GeometryCollection)
+	c[1].(LineString)[0].X = 9
+	c[2].(Polygon)[0][0].X = 9
+	c[3].(MultiPoint)[0].X = 9
+	c[4].(MultiLineString)[0][0].X = 9
+	c[5].(MultiPolygon)[0][0][0].X = 9
+	if gc[1].(LineString)[0].X != 1 || gc[2].(Polygon)[0][0].X != 0 || gc[3].(MultiPoint)[0].X != 3 ||
+		gc[4].(MultiLineString)[0][0].X != 4 || gc[5].(MultiPolygon)[0][0][0].X != 0 {
+		t.Errorf("Clone shares storage with the original: %v", gc)
+	}
+	if c := Clone(Point{X: 5}); c != (Point{X: 5}) {
+		t.Errorf("Clone(Point) = %v", c)
+	}
+	if c := (Polygon(nil)).Clone(); c != nil {
+		t.Errorf("Polygon(nil).Clone() = %v, want nil", c)
+	}
+}
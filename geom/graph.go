@@ -0,0 +1,407 @@
+package geom
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// This file implements the planar graph on which polygons are repaired
+// and combined. The boundaries of the input polygons are broken into
+// edges which meet only at their endpoints, the faces of the plane
+// bounded by the edges are found and labeled with the winding numbers
+// of the inputs around them, and the result is assembled from the edges
+// which separate the faces selected by a predicate on the labels from
+// those which are not.
+
+// edge is a directed edge of the graph. Its label w holds the amount by
+// which the winding number of each of up to two inputs is greater on the
+// left of the edge than on its right.
+type edge struct {
+	a, b Point
+	w    [2]int
+}
+
+// ringEdges appends the edges of a ring, each with the winding label w,
+// to edges. A ring which is not closed is closed implicitly.
+func ringEdges(edges []edge, r Ring, w [2]int) []edge {
+	for i := range r {
+		a, b := r[i], r[(i+1)%len(r)]
+		if !a.Equal(b) {
+			edges = append(edges, edge{Point{X: a.X, Y: a.Y}, Point{X: b.X, Y: b.Y}, w})
+		}
+	}
+	return edges
+}
+
+// node splits the edges at every point where they meet another, so
+// that edges meet only at their endpoints, and merges edges which then
+// coincide, adding their labels. Edges whose labels cancel out are kept,
+// since they may still separate faces.
+//
+// The points at which edges cross are rounded, so the pieces of the
+// edges may cross again near them, where several edges cross at almost
+// the same point. The edges are therefore split repeatedly until no more
+// crossings are found.
+func node(edges []edge) []edge {
+	for range 8 {
+		var split bool
+		if edges, split = nodeOnce(edges); !split {
+			break
+		}
+	}
+	return edges
+}
+
+// nodeOnce performs one round of the splitting of node, and reports
+// whether any edge was split.
+func nodeOnce(edges []edge) ([]edge, bool) {
+	segs := make([]Segment, len(edges))
+	for i, e := range edges {
+		segs[i] = Segment{e.a, e.b}
+	}
+	cuts := make([][]Point, len(edges))
+	split := false
+	overlappingPairs(segs, func(i, j int) {
+		si := segs[i].Intersection(segs[j])
+		if si.Kind == NoIntersection {
+			return
+		}
+		for _, k := range [2]int{i, j} {
+			for _, p := range [2]Point{si.P, si.Q} {
+				if !p.Equal(segs[k].A) && !p.Equal(segs[k].B) {
+					cuts[k] = append(cuts[k], p)
+					split = true
+				}
+			}
+		}
+	})
+	type key struct{ a, b [2]float64 }
+	merged := map[key]int{}
+	var out []edge
+	add := func(a, b Point, w [2]int) {
+		if a.Equal(b) {
+			return
+		}
+		ka, kb := [2]float64{a.X, a.Y}, [2]float64{b.X, b.Y}
+		if cmp.Or(cmp.Compare(ka[0], kb[0]), cmp.Compare(ka[1], kb[1])) > 0 {
+			a, b, ka, kb = b, a, kb, ka
+			w = [2]int{-w[0], -w[1]}
+		}
+		if i, ok := merged[key{ka, kb}]; ok {
+			out[i].w[0] += w[0]
+			out[i].w[1] += w[1]
+			return
+		}
+		merged[key{ka, kb}] = len(out)
+		out = append(out, edge{a, b, w})
+	}
+	for i, e := range edges {
+		c := cuts[i]
+		// Order the cuts along the edge.
+		d := func(p Point) float64 { return (p.X-e.a.X)*(e.b.X-e.a.X) + (p.Y-e.a.Y)*(e.b.Y-e.a.Y) }
+		slices.SortFunc(c, func(p, q Point) int { return cmp.Compare(d(p), d(q)) })
+		prev := e.a
+		for _, p := range c {
+			add(prev, p, e.w)
+			prev = p
+		}
+		add(prev, e.b, e.w)
+	}
+	return out, split
+}
+
+// graph is a planar graph of noded edges, each held as two half-edges
+// of opposite directions. Half-edge h runs from vertex from[h], and its
+// twin is h^1.
+type graph struct {
+	pts  []Point
+	from []int
+	w    [][2]int // The label of each half-edge
+	out  [][]int  // The half-edges leaving each vertex, counterclockwise
+	pos  []int    // The position of each half-edge in out of its vertex
+}
+
+// newGraph returns the graph of noded edges.
+func newGraph(edges []edge) *graph {
+	g := &graph{}
+	ids := map[[2]float64]int{}
+	vertex := func(p Point) int {
+		k := [2]float64{p.X, p.Y}
+		id, ok := ids[k]
+		if !ok {
+			id = len(g.pts)
+			ids[k] = id
+			g.pts = append(g.pts, p)
+			g.out = append(g.out, nil)
+		}
+		return id
+	}
+	for _, e := range edges {
+		u, v := vertex(e.a), vertex(e.b)
+		h := len(g.from)
+		g.from = append(g.from, u, v)
+		g.w = append(g.w, e.w, [2]int{-e.w[0], -e.w[1]})
+		g.out[u] = append(g.out[u], h)
+		g.out[v] = append(g.out[v], h+1)
+	}
+	g.pos = make([]int, len(g.from))
+	for v, hs := range g.out {
+		o := g.pts[v]
+		slices.SortFunc(hs, func(h, k int) int { return compareAngle(o, g.pts[g.to(h)], g.pts[g.to(k)]) })
+		for i, h := range hs {
+			g.pos[h] = i
+		}
+	}
+	return g
+}
+
+// to returns the vertex at which a half-edge ends.
+func (g *graph) to(h int) int {
+	return g.from[h^1]
+}
+
+// next returns the half-edge which follows h around the face on its
+// left: the first half-edge clockwise from the twin of h around the
+// vertex at which h ends. Only the half-edges for which keep is true,
+// or all if keep is nil, are considered.
+func (g *graph) next(h int, keep []bool) int {
+	hs := g.out[g.to(h)]
+	i := g.pos[h^1]
+	for range hs {
+		i = (i + len(hs) - 1) % len(hs)
+		if keep == nil || keep[hs[i]] {
+			return hs[i]
+		}
+	}
+	return h ^ 1
+}
+
+// cycles returns the cycles of half-edges around the faces of the
+// graph, considering only the half-edges for which keep is true, or all
+// if keep is nil, together with the cycle of each half-edge, or -1 for
+// those not considered.
+func (g *graph) cycles(keep []bool) (cycles [][]int, cycleOf []int) {
+	cycleOf = make([]int, len(g.from))
+	for h := range cycleOf {
+		cycleOf[h] = -1
+	}
+	for h := range g.from {
+		if cycleOf[h] >= 0 || keep != nil && !keep[h] {
+			continue
+		}
+		var c []int
+		for k := h; cycleOf[k] < 0; k = g.next(k, keep) {
+			cycleOf[k] = len(cycles)
+			c = append(c, k)
+		}
+		cycles = append(cycles, c)
+	}
+	return cycles, cycleOf
+}
+
+// rings returns the rings traced by a cycle of half-edges. A cycle
+// which passes through a vertex more than once, where the face on its
+// left touches itself, is split there into separate rings, each passing
+// through the vertex once.
+func (g *graph) rings(c []int) []Ring {
+	var rings []Ring
+	var path []int
+	at := map[int]int{} // The position of each vertex in path
+	for _, h := range c {
+		v := g.from[h]
+		if i, ok := at[v]; ok {
+			r := make(Ring, 0, len(path)-i+1)
+			for _, u := range path[i:] {
+				r = append(r, g.pts[u])
+				delete(at, u)
+			}
+			rings = append(rings, append(r, g.pts[v]))
+			path = path[:i]
+		}
+		at[v] = len(path)
+		path = append(path, v)
+	}
+	if len(path) > 0 {
+		r := make(Ring, 0, len(path)+1)
+		for _, u := range path {
+			r = append(r, g.pts[u])
+		}
+		rings = append(rings, append(r, r[0]))
+	}
+	return rings
+}
+
+// buildArea returns the polygons covering the faces of the graph of the
+// noded edges whose labels satisfy inside. The exterior rings of the
+// polygons run counterclockwise and their holes clockwise.
+func buildArea(edges []edge, inside func(w [2]int) bool) MultiPolygon {
+	g := newGraph(edges)
+	cycles, cycleOf := g.cycles(nil)
+	labels := g.labelCycles(cycles, cycleOf)
+	// Keep the half-edges with an inside face on their left and an
+	// outside face on their right.
+	keep := make([]bool, len(g.from))
+	for h := range keep {
+		keep[h] = inside(labels[cycleOf[h]]) && !inside(labels[cycleOf[h^1]])
+	}
+	cycles, _ = g.cycles(keep)
+	var shells MultiPolygon
+	var holes []Ring
+	for _, c := range cycles {
+		for _, r := range g.rings(c) {
+			if signedArea(r) > 0 {
+				shells = append(shells, Polygon{r})
+			} else {
+				holes = append(holes, r)
+			}
+		}
+	}
+	assignHoles(shells, holes)
+	return shells
+}
+
+// labelCycles returns the label of the face on the left of each cycle,
+// the winding numbers of the inputs around the face.
+func (g *graph) labelCycles(cycles [][]int, cycleOf []int) [][2]int {
+	labels := make([][2]int, len(cycles))
+	done := make([]bool, len(cycles))
+	for c := range cycles {
+		if done[c] {
+			continue
+		}
+		// Find the cycles connected to c, and the label of each relative
+		// to that of c, by crossing their edges: the face on the left of
+		// a half-edge has a label greater by that of the half-edge than
+		// the face on its right.
+		component := []int{c}
+		done[c] = true
+		for i := 0; i < len(component); i++ {
+			d := component[i]
+			for _, h := range cycles[d] {
+				e := cycleOf[h^1]
+				if !done[e] {
+					done[e] = true
+					labels[e] = [2]int{labels[d][0] - g.w[h][0], labels[d][1] - g.w[h][1]}
+					component = append(component, e)
+				}
+			}
+		}
+		// The connected component has a single clockwise cycle, around
+		// its outside, where the label is that of the face of the rest
+		// of the graph which contains the component. It is found by
+		// winding around a vertex of the component.
+		in := make([]bool, len(g.from))
+		outer, outerArea := c, g.cycleArea(cycles[c])
+		for _, d := range component {
+			for _, h := range cycles[d] {
+				in[h] = true
+			}
+			if a := g.cycleArea(cycles[d]); a < outerArea {
+				outer, outerArea = d, a
+			}
+		}
+		w := g.winding(g.pts[g.from[cycles[outer][0]]], in)
+		delta := [2]int{w[0] - labels[outer][0], w[1] - labels[outer][1]}
+		for _, d := range component {
+			labels[d][0] += delta[0]
+			labels[d][1] += delta[1]
+		}
+	}
+	return labels
+}
+
+// cycleArea returns the signed area enclosed by a cycle of half-edges.
+func (g *graph) cycleArea(c []int) float64 {
+	a := 0.0
+	for _, h := range c {
+		p, q := g.pts[g.from[h]], g.pts[g.to(h)]
+		a += (p.X - q.X) * (p.Y + q.Y)
+	}
+	return a / 2
+}
+
+// winding returns the winding numbers of the inputs around a point,
+// counting the edges other than those whose half-edges are marked in
+// skip, which must not pass through the point.
+func (g *graph) winding(p Point, skip []bool) [2]int {
+	var w [2]int
+	for h := 0; h < len(g.from); h += 2 {
+		if skip[h] {
+			continue
+		}
+		a, b := g.pts[g.from[h]], g.pts[g.to(h)]
+		switch {
+		case a.Y <= p.Y && b.Y > p.Y && orient(a, b, p) > 0:
+			w[0] += g.w[h][0]
+			w[1] += g.w[h][1]
+		case a.Y > p.Y && b.Y <= p.Y && orient(a, b, p) < 0:
+			w[0] -= g.w[h][0]
+			w[1] -= g.w[h][1]
+		}
+	}
+	return w
+}
+
+// assignHoles adds each hole to the smallest of the polygons whose
+// exterior ring contains it. A hole contained by none is dropped.
+func assignHoles(polys MultiPolygon, holes []Ring) {
+	areas := make([]float64, len(polys))
+	bounds := make([]Rect, len(polys))
+	for i, p := range polys {
+		areas[i], bounds[i] = signedArea(p[0]), RectOf(p[0]...)
+	}
+	for _, h := range holes {
+		// The midpoint of an edge of the hole lies strictly inside the
+		// exterior ring containing the hole, which it may touch only at
+		// vertices.
+		m := Point{X: (h[0].X + h[1].X) / 2, Y: (h[0].Y + h[1].Y) / 2}
+		best := -1
+		for i, p := range polys {
+			if bounds[i].Contains(m) && (best < 0 || areas[i] < areas[best]) && locateRing(p[0], m) == Interior {
+				best = i
+			}
+		}
+		if best >= 0 {
+			polys[best] = append(polys[best], h)
+		}
+	}
+}
+
+// signedArea returns the area enclosed by a ring, positive if the ring
+// runs counterclockwise and negative if it runs clockwise.
+func signedArea(r []Point) float64 {
+	if len(r) < 3 {
+		return 0
+	}
+	a := 0.0
+	o := r[0]
+	for i := 1; i+1 < len(r); i++ {
+		a += (r[i].X-o.X)*(r[i+1].Y-o.Y) - (r[i+1].X-o.X)*(r[i].Y-o.Y)
+	}
+	return a / 2
+}
+
+// compareAngle compares the directions from o to p and from o to q,
+// by the counterclockwise angle from the positive X axis, exactly.
+func compareAngle(o, p, q Point) int {
+	hp, hq := lowerHalf(o, p), lowerHalf(o, q)
+	if hp != hq {
+		if hp {
+			return 1
+		}
+		return -1
+	}
+	return -orient(o, p, q)
+}
+
+// lowerHalf reports whether the direction from o to p points into the
+// lower half plane, at an angle in [180, 360) degrees.
+func lowerHalf(o, p Point) bool {
+	return p.Y < o.Y || p.Y == o.Y && p.X < o.X
+}
+
+// finite reports whether both coordinates of a point are finite.
+func finite(p Point) bool {
+	return !math.IsInf(p.X, 0) && !math.IsNaN(p.X) && !math.IsInf(p.Y, 0) && !math.IsNaN(p.Y)
+}
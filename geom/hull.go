@@ -0,0 +1,81 @@
+package geom
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ConvexHull returns the convex hull of a geometry, the smallest convex
+// polygon containing all of its points, whose exterior ring runs
+// counterclockwise from the point with the least X, and least Y among
+// those. The hull of points which all lie on a line is the LineString
+// joining the two farthest apart, the hull of a single distinct point is
+// that Point, and the hull of an empty geometry is an empty
+// GeometryCollection. A MultiPoint gives the hull of a point set.
+//
+// The hull is computed by Andrew's monotone chain algorithm, in
+// O(n log n) time for n points, with exact orientation tests, so that
+// points lying exactly on an edge of the hull are never taken as
+// vertices.
+func ConvexHull(g Geometry) Geometry {
+	ps := sortedPoints(g)
+	return hullGeometry(ps, convexHull(ps))
+}
+
+// sortedPoints returns the distinct points of a geometry, sorted by X
+// and then by Y.
+func sortedPoints(g Geometry) []Point {
+	ps := slices.Collect(Points(g))
+	slices.SortFunc(ps, func(p, q Point) int {
+		return cmp.Or(cmp.Compare(p.X, q.X), cmp.Compare(p.Y, q.Y))
+	})
+	return slices.CompactFunc(ps, Point.Equal)
+}
+
+// hullGeometry returns the geometry of a hull given by the indexes of
+// its vertices in counterclockwise order.
+func hullGeometry(ps []Point, h []int) Geometry {
+	r := make(Ring, len(h), len(h)+1)
+	for i, k := range h {
+		r[i] = ps[k]
+	}
+	switch len(r) {
+	case 0:
+		return GeometryCollection{}
+	case 1:
+		return r[0]
+	case 2:
+		return LineString(r)
+	}
+	return Polygon{append(r, r[0])}
+}
+
+// convexHull returns the indexes of the vertices of the convex hull of
+// points sorted by sortedPoints, in counterclockwise order from the
+// first point, without repeating it.
+func convexHull(ps []Point) []int {
+	h := make([]int, 0, len(ps)+1)
+	if len(ps) < 3 {
+		for k := range ps {
+			h = append(h, k)
+		}
+		return h
+	}
+	add := func(start, k int) {
+		for len(h) >= start+2 && orient(ps[h[len(h)-2]], ps[h[len(h)-1]], ps[k]) <= 0 {
+			h = h[:len(h)-1]
+		}
+		h = append(h, k)
+	}
+	// The lower chain runs left to right and the upper chain back, each
+	// turning only counterclockwise.
+	for k := range ps {
+		add(0, k)
+	}
+	h = h[:len(h)-1]
+	start := len(h)
+	for k := len(ps) - 1; k >= 0; k-- {
+		add(start, k)
+	}
+	return h[:len(h)-1]
+}
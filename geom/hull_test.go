@@ -0,0 +1,66 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestConvexHull(t *testing.T) {
+	tests := []struct {
+		g, want Geometry
+	}{
+		{
+			MultiPoint{{X: 1, Y: 1}, {X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}, {X: 1, Y: 0}, {X: 0.5, Y: 1.5}},
+			Polygon{ring(0, 0, 2, 0, 2, 2, 0, 2, 0, 0)},
+		},
+		{donut, Polygon{donut[0]}},
+		{
+			LineString{{X: 3, Y: 0}, {X: 0, Y: 3}, {X: -3, Y: 0}, {X: 0, Y: -3}, {X: 3, Y: 0}},
+			Polygon{ring(-3, 0, 0, -3, 3, 0, 0, 3, -3, 0)},
+		},
+		{MultiPoint{{X: 0, Y: 0}, {X: 2, Y: 2}, {X: 1, Y: 1}, {X: 3, Y: 3}}, LineString{{X: 0, Y: 0}, {X: 3, Y: 3}}},
+		{MultiPoint{{X: 1, Y: 5}, {X: 1, Y: 2}, {X: 1, Y: 9}}, LineString{{X: 1, Y: 2}, {X: 1, Y: 9}}},
+		{MultiPoint{{X: 5, Y: 5}, {X: 5, Y: 5}}, Point{X: 5, Y: 5}},
+		{MultiPoint{EmptyPoint()}, GeometryCollection{}},
+		{GeometryCollection{}, GeometryCollection{}},
+		{GeometryCollection{Point{X: 0, Y: 0}, LineString{{X: 1, Y: 0}, {X: 0, Y: 1}}}, Polygon{ring(0, 0, 1, 0, 0, 1, 0, 0)}},
+	}
+	for _, tt := range tests {
+		if h := ConvexHull(tt.g); !reflect.DeepEqual(h, tt.want) {
+			t.Errorf("ConvexHull(%v) = %v, want %v", tt.g, h, tt.want)
+		}
+	}
+}
+
+func TestConvexHullRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(64))
+	for range 200 {
+		var mp MultiPoint
+		for range 1 + r.Intn(200) {
+			// Points of a coarse grid, so that many are collinear.
+			mp = append(mp, Point{X: math.Round(r.NormFloat64() * 5), Y: math.Round(r.NormFloat64() * 5)})
+		}
+		h, ok := ConvexHull(mp).(Polygon)
+		if !ok {
+			continue
+		}
+		ring := h[0]
+		if !ring.IsClosed() || ring.Winding() != Counterclockwise {
+			t.Fatalf("ConvexHull(%v) = %v, not a closed counterclockwise ring", mp, h)
+		}
+		// The hull turns strictly left at every vertex.
+		n := len(ring) - 1
+		for i := range n {
+			if orient(ring[i], ring[i+1], ring[(i+2)%n]) <= 0 {
+				t.Fatalf("ConvexHull(%v) = %v, not strictly convex at %v", mp, h, ring[i+1])
+			}
+		}
+		for _, p := range mp {
+			if !CoversPoint(h, p) {
+				t.Fatalf("ConvexHull(%v) = %v, without %v", mp, h, p)
+			}
+		}
+	}
+}
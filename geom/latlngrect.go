@@ -0,0 +1,265 @@
+package geom
+
+import (
+	"math"
+	"slices"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// LatLngRect is a rectangle on the Earth, bounded by lines of latitude
+// and longitude given in degrees. A rectangle which crosses the
+// antimeridian has MinLng > MaxLng, and runs east from MinLng to 180
+// and on from -180 to MaxLng. One which spans every longitude has
+// MinLng = -180 and MaxLng = 180. A rectangle with MinLat > MaxLat is
+// empty.
+type LatLngRect struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// EmptyLatLngRect returns an empty rectangle, which is the identity for
+// Union and Extend.
+func EmptyLatLngRect() LatLngRect {
+	return LatLngRect{math.Inf(1), math.Inf(-1), 180, -180}
+}
+
+// IsEmpty reports whether the rectangle contains no points.
+func (r LatLngRect) IsEmpty() bool {
+	return !(r.MinLat <= r.MaxLat)
+}
+
+// IsFullLng reports whether the rectangle spans every longitude.
+func (r LatLngRect) IsFullLng() bool {
+	return r.lng().length >= 360
+}
+
+// LatSpan returns the extent in degrees of the rectangle from south to
+// north, or 0 if it is empty.
+func (r LatLngRect) LatSpan() float64 {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.MaxLat - r.MinLat
+}
+
+// LngSpan returns the extent in degrees of the rectangle from west to
+// east, in the range [0, 360], or 0 if it is empty.
+func (r LatLngRect) LngSpan() float64 {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.lng().length
+}
+
+// Center returns the position at the center of the rectangle, with its
+// longitude wrapped into the range [-180, 180).
+func (r LatLngRect) Center() geodesy.LatLng {
+	a := r.lng()
+	return geodesy.LatLng{Lat: (r.MinLat + r.MaxLat) / 2, Lng: wrap180(a.lo + a.length/2)}
+}
+
+// Contains reports whether a position lies inside the rectangle or on
+// its boundary. Longitudes are wrapped.
+func (r LatLngRect) Contains(p geodesy.LatLng) bool {
+	return r.MinLat <= p.Lat && p.Lat <= r.MaxLat && r.lng().contains(p.Lng)
+}
+
+// ContainsRect reports whether every point of another rectangle lies in
+// the rectangle. An empty rectangle is contained in every rectangle.
+func (r LatLngRect) ContainsRect(s LatLngRect) bool {
+	return s.IsEmpty() || r.MinLat <= s.MinLat && s.MaxLat <= r.MaxLat && r.lng().containsArc(s.lng())
+}
+
+// Intersects reports whether the rectangle and another have at least
+// one point in common, including when they only touch.
+func (r LatLngRect) Intersects(s LatLngRect) bool {
+	if r.IsEmpty() || s.IsEmpty() || r.MinLat > s.MaxLat || s.MinLat > r.MaxLat {
+		return false
+	}
+	a, b := r.lng(), s.lng()
+	return a.contains(b.lo) || b.contains(a.lo)
+}
+
+// Union returns the smallest rectangle containing both the rectangle
+// and another. When there is a choice, it spans the fewer degrees of
+// longitude, crossing the antimeridian if need be.
+func (r LatLngRect) Union(s LatLngRect) LatLngRect {
+	switch {
+	case r.IsEmpty():
+		return s
+	case s.IsEmpty():
+		return r
+	}
+	return makeLatLngRect(min(r.MinLat, s.MinLat), max(r.MaxLat, s.MaxLat), r.lng().union(s.lng()))
+}
+
+// Intersection returns the rectangle of the points common to the
+// rectangle and another, which is empty if they do not intersect. Where
+// two rectangles cross the antimeridian in opposite directions their
+// intersection may be two separate rectangles, in which case the
+// narrower of the two rectangles, which contains both, is returned.
+func (r LatLngRect) Intersection(s LatLngRect) LatLngRect {
+	if !r.Intersects(s) {
+		return EmptyLatLngRect()
+	}
+	return makeLatLngRect(max(r.MinLat, s.MinLat), min(r.MaxLat, s.MaxLat), r.lng().intersection(s.lng()))
+}
+
+// Extend returns the smallest rectangle containing both the rectangle
+// and a position, as does Union.
+func (r LatLngRect) Extend(p geodesy.LatLng) LatLngRect {
+	lng := wrap180(p.Lng)
+	return r.Union(LatLngRect{p.Lat, p.Lat, lng, lng})
+}
+
+// Expand returns the rectangle grown on every side by the distance d in
+// meters, measured on the sphere geodesy.Earth, so that it contains
+// every point within d of the rectangle. The rectangle grows to span
+// every longitude if it reaches a pole. The distance must not be
+// negative.
+func (r LatLngRect) Expand(d float64) LatLngRect {
+	if r.IsEmpty() {
+		return r
+	}
+	dLat := d / geodesy.MeanRadius / degToRad
+	minLat, maxLat := max(-90, r.MinLat-dLat), min(90, r.MaxLat+dLat)
+	if minLat == -90 || maxLat == 90 {
+		return LatLngRect{minLat, maxLat, -180, 180}
+	}
+	// The rectangle is widest in longitude at its latitude furthest
+	// from the equator.
+	cos := math.Cos(max(-minLat, maxLat) * degToRad)
+	dLng := math.Asin(min(1, math.Sin(d/geodesy.MeanRadius)/cos)) / degToRad
+	a := r.lng()
+	return makeLatLngRect(minLat, maxLat, lngArc{a.lo - dLng, a.length + 2*dLng})
+}
+
+// Polygon returns the rectangle as a polygon, with its exterior ring
+// running counterclockwise from the south-western corner, or nil if the
+// rectangle is empty. The longitudes of a rectangle crossing the
+// antimeridian run beyond 180 degrees, so that the ring is continuous.
+func (r LatLngRect) Polygon() Polygon {
+	if r.IsEmpty() {
+		return nil
+	}
+	a := r.lng()
+	return Rect{a.lo, r.MinLat, a.lo + a.length, r.MaxLat}.Polygon()
+}
+
+// LatLngBounds returns the smallest rectangle containing every point of
+// a geometry, which holds positions as longitudes X and latitudes Y.
+// When there is a choice, the rectangle spans the fewest degrees of
+// longitude, leaving out the widest gap between the longitudes of the
+// points, which may cross the antimeridian. The edges of the geometry
+// between its points are not considered.
+func LatLngBounds(g Geometry) LatLngRect {
+	r := EmptyLatLngRect()
+	var lngs []float64
+	for p := range Points(g) {
+		r.MinLat, r.MaxLat = min(r.MinLat, p.Y), max(r.MaxLat, p.Y)
+		lngs = append(lngs, wrap180(p.X))
+	}
+	if len(lngs) == 0 {
+		return r
+	}
+	slices.Sort(lngs)
+	// The gap after the last longitude wraps around to the first.
+	gap, after := lngs[0]+360-lngs[len(lngs)-1], len(lngs)-1
+	for i := 1; i < len(lngs); i++ {
+		if g := lngs[i] - lngs[i-1]; g > gap {
+			gap, after = g, i-1
+		}
+	}
+	lo := lngs[(after+1)%len(lngs)]
+	return makeLatLngRect(r.MinLat, r.MaxLat, lngArc{lo, 360 - gap})
+}
+
+const degToRad = math.Pi / 180
+
+// lngArc is an interval of longitudes, running east from lo for length
+// degrees.
+type lngArc struct {
+	lo, length float64
+}
+
+// lng returns the longitudes of the rectangle.
+func (r LatLngRect) lng() lngArc {
+	if r.MinLng == -180 && r.MaxLng == 180 {
+		return lngArc{-180, 360}
+	}
+	return lngArc{wrap180(r.MinLng), wrap360(r.MaxLng - r.MinLng)}
+}
+
+// makeLatLngRect returns the rectangle with the given latitudes and
+// longitudes.
+func makeLatLngRect(minLat, maxLat float64, a lngArc) LatLngRect {
+	if a.length >= 360 {
+		return LatLngRect{minLat, maxLat, -180, 180}
+	}
+	lo := wrap180(a.lo)
+	hi := lo + a.length
+	if hi > 180 {
+		hi -= 360
+	}
+	return LatLngRect{minLat, maxLat, lo, hi}
+}
+
+// contains reports whether the arc contains a longitude.
+func (a lngArc) contains(lng float64) bool {
+	return wrap360(lng-a.lo) <= a.length || a.length >= 360
+}
+
+// containsArc reports whether the arc contains another.
+func (a lngArc) containsArc(b lngArc) bool {
+	return a.length >= 360 || a.contains(b.lo) && wrap360(b.lo-a.lo)+b.length <= a.length
+}
+
+// union returns the shortest arc containing both arcs.
+func (a lngArc) union(b lngArc) lngArc {
+	switch {
+	case a.contains(b.lo):
+		return lngArc{a.lo, min(360, max(a.length, wrap360(b.lo-a.lo)+b.length))}
+	case b.contains(a.lo):
+		return lngArc{b.lo, min(360, max(b.length, wrap360(a.lo-b.lo)+a.length))}
+	}
+	// The arcs are disjoint, so the union may bridge either gap.
+	ab := lngArc{a.lo, wrap360(b.lo-a.lo) + b.length}
+	ba := lngArc{b.lo, wrap360(a.lo-b.lo) + a.length}
+	if ab.length <= ba.length {
+		return ab
+	}
+	return ba
+}
+
+// intersection returns the shortest arc containing the longitudes
+// common to two intersecting arcs.
+func (a lngArc) intersection(b lngArc) lngArc {
+	aHasB, bHasA := a.contains(b.lo), b.contains(a.lo)
+	switch {
+	case aHasB && bHasA:
+		// The intersection may be in two parts, both within the
+		// shorter arc.
+		if a.length <= b.length {
+			return a
+		}
+		return b
+	case aHasB:
+		return lngArc{b.lo, min(b.length, a.length-wrap360(b.lo-a.lo))}
+	}
+	return lngArc{a.lo, min(a.length, b.length-wrap360(a.lo-b.lo))}
+}
+
+// wrap180 wraps a longitude into the range [-180, 180).
+func wrap180(lng float64) float64 {
+	return wrap360(lng+180) - 180
+}
+
+// wrap360 wraps an angle into the range [0, 360).
+func wrap360(a float64) float64 {
+	a = math.Mod(a, 360)
+	if a < 0 {
+		a += 360
+	}
+	return a
+}
@@ -0,0 +1,202 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+func TestLatLngRect(t *testing.T) {
+	tests := []struct {
+		r                geodesy.LatLng
+		rect             LatLngRect
+		empty, full      bool
+		latSpan, lngSpan float64
+	}{
+		{geodesy.LatLng{Lat: 15, Lng: 15}, LatLngRect{10, 20, 10, 20}, false, false, 10, 10},
+		// Across the antimeridian.
+		{geodesy.LatLng{Lat: 0, Lng: -180}, LatLngRect{-10, 10, 170, -170}, false, false, 20, 20},
+		{geodesy.LatLng{Lat: 0, Lng: 175}, LatLngRect{-10, 10, 170, -180}, false, false, 20, 10},
+		{geodesy.LatLng{Lat: 0, Lng: 0}, LatLngRect{-90, 90, -180, 180}, false, true, 180, 360},
+	}
+	for _, tt := range tests {
+		r := tt.rect
+		if r.IsEmpty() != tt.empty || r.IsFullLng() != tt.full || r.LatSpan() != tt.latSpan || r.LngSpan() != tt.lngSpan {
+			t.Errorf("%v = empty %v, full %v, spans %v, %v, want %v, %v, %v, %v", r, r.IsEmpty(), r.IsFullLng(), r.LatSpan(), r.LngSpan(), tt.empty, tt.full, tt.latSpan, tt.lngSpan)
+		}
+		if c := r.Center(); c != tt.r {
+			t.Errorf("%v.Center() = %v, want %v", r, c, tt.r)
+		}
+	}
+	e := EmptyLatLngRect()
+	if !e.IsEmpty() || e.LatSpan() != 0 || e.LngSpan() != 0 || e.Polygon() != nil {
+		t.Errorf("EmptyLatLngRect() = %v, not empty", e)
+	}
+}
+
+func TestLatLngRectContains(t *testing.T) {
+	dateline := LatLngRect{-10, 10, 170, -170}
+	tests := []struct {
+		r    LatLngRect
+		p    geodesy.LatLng
+		want bool
+	}{
+		{dateline, geodesy.LatLng{Lat: 0, Lng: 180}, true},
+		{dateline, geodesy.LatLng{Lat: 0, Lng: -180}, true},
+		{dateline, geodesy.LatLng{Lat: 0, Lng: 175}, true},
+		{dateline, geodesy.LatLng{Lat: 0, Lng: -175}, true},
+		{dateline, geodesy.LatLng{Lat: 0, Lng: 185}, true},
+		{dateline, geodesy.LatLng{Lat: 0, Lng: 0}, false},
+		{dateline, geodesy.LatLng{Lat: 11, Lng: 180}, false},
+		{dateline, geodesy.LatLng{Lat: 10, Lng: 170}, true},
+		{LatLngRect{0, 1, -180, 180}, geodesy.LatLng{Lat: 0.5, Lng: 42}, true},
+		{EmptyLatLngRect(), geodesy.LatLng{}, false},
+	}
+	for _, tt := range tests {
+		if c := tt.r.Contains(tt.p); c != tt.want {
+			t.Errorf("%v.Contains(%v) = %v, want %v", tt.r, tt.p, c, tt.want)
+		}
+	}
+	rects := []struct {
+		s                    LatLngRect
+		contains, intersects bool
+	}{
+		{LatLngRect{-5, 5, 175, -175}, true, true},
+		{LatLngRect{-5, 5, 175, 179}, true, true},
+		{LatLngRect{-5, 5, 160, 175}, false, true},
+		{LatLngRect{-5, 5, -175, -160}, false, true},
+		{LatLngRect{-5, 5, -170, -160}, false, true},
+		{LatLngRect{-5, 5, 0, 10}, false, false},
+		{LatLngRect{20, 30, 175, -175}, false, false},
+		{LatLngRect{-5, 5, -180, 180}, false, true},
+		{EmptyLatLngRect(), true, false},
+	}
+	for _, tt := range rects {
+		if c := dateline.ContainsRect(tt.s); c != tt.contains {
+			t.Errorf("%v.ContainsRect(%v) = %v, want %v", dateline, tt.s, c, tt.contains)
+		}
+		if i := dateline.Intersects(tt.s); i != tt.intersects || tt.s.Intersects(dateline) != i {
+			t.Errorf("%v.Intersects(%v) = %v, want %v", dateline, tt.s, i, tt.intersects)
+		}
+	}
+}
+
+func TestLatLngRectUnion(t *testing.T) {
+	tests := []struct {
+		r, s, union, intersection LatLngRect
+	}{
+		{LatLngRect{0, 1, 0, 10}, LatLngRect{0, 2, 5, 20}, LatLngRect{0, 2, 0, 20}, LatLngRect{0, 1, 5, 10}},
+		// The union takes the shorter way round, across the antimeridian.
+		{LatLngRect{0, 1, 170, 175}, LatLngRect{0, 1, -175, -170}, LatLngRect{0, 1, 170, -170}, EmptyLatLngRect()},
+		{LatLngRect{0, 1, -10, 0}, LatLngRect{0, 1, 10, 20}, LatLngRect{0, 1, -10, 20}, EmptyLatLngRect()},
+		{LatLngRect{0, 1, 170, -170}, LatLngRect{0, 1, -175, 0}, LatLngRect{0, 1, 170, 0}, LatLngRect{0, 1, -175, -170}},
+		{LatLngRect{0, 1, -180, 180}, LatLngRect{2, 3, 5, 6}, LatLngRect{0, 3, -180, 180}, EmptyLatLngRect()},
+		{LatLngRect{0, 1, 0, 10}, EmptyLatLngRect(), LatLngRect{0, 1, 0, 10}, EmptyLatLngRect()},
+		// Two rectangles crossing the antimeridian in opposite
+		// directions meet in two parts, within the narrower rectangle.
+		{LatLngRect{0, 1, 90, -90}, LatLngRect{0, 1, -100, 100}, LatLngRect{0, 1, -180, 180}, LatLngRect{0, 1, 90, -90}},
+	}
+	for _, tt := range tests {
+		if u := tt.r.Union(tt.s); u != tt.union {
+			t.Errorf("%v.Union(%v) = %v, want %v", tt.r, tt.s, u, tt.union)
+		}
+		if i := tt.r.Intersection(tt.s); i != tt.intersection && !(i.IsEmpty() && tt.intersection.IsEmpty()) {
+			t.Errorf("%v.Intersection(%v) = %v, want %v", tt.r, tt.s, i, tt.intersection)
+		}
+	}
+}
+
+func TestLatLngRectRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(59))
+	random := func() LatLngRect {
+		// Whole degrees of longitude keep the arcs exact.
+		lat1, lat2 := r.Float64()*180-90, r.Float64()*180-90
+		return LatLngRect{min(lat1, lat2), max(lat1, lat2), math.Floor(r.Float64()*360) - 180, math.Floor(r.Float64()*360) - 180}
+	}
+	for range 10000 {
+		a, b := random(), random()
+		u := a.Union(b)
+		if !u.ContainsRect(a) || !u.ContainsRect(b) || u.LngSpan() > a.LngSpan()+b.LngSpan()+360-max(a.LngSpan(), b.LngSpan()) {
+			t.Fatalf("%v.Union(%v) = %v", a, b, u)
+		}
+		i := a.Intersection(b)
+		if i.IsEmpty() != !a.Intersects(b) {
+			t.Fatalf("%v.Intersection(%v) = %v, but Intersects = %v", a, b, i, a.Intersects(b))
+		}
+		p := geodesy.LatLng{Lat: r.Float64()*180 - 90, Lng: math.Floor(r.Float64()*360) - 180}
+		if a.Contains(p) && b.Contains(p) && !i.Contains(p) {
+			t.Fatalf("%v.Intersection(%v) = %v, without %v", a, b, i, p)
+		}
+		if e := a.Extend(p); !e.Contains(p) || !e.ContainsRect(a) {
+			t.Fatalf("%v.Extend(%v) = %v", a, p, e)
+		}
+	}
+}
+
+func TestLatLngRectExpand(t *testing.T) {
+	// A degree of latitude on the sphere Earth.
+	deg := geodesy.MeanRadius * degToRad
+	tests := []struct {
+		r    LatLngRect
+		d    float64
+		want LatLngRect
+	}{
+		{LatLngRect{-1, 1, -1, 1}, deg, LatLngRect{-2, 2, -2.0006, 2.0006}},
+		{LatLngRect{0, 0, 179.5, 179.5}, deg, LatLngRect{-1, 1, 178.49985, -179.49985}},
+		{LatLngRect{80, 89.5, 0, 10}, deg, LatLngRect{79, 90, -180, 180}},
+		{LatLngRect{60, 60, 0, 0}, deg, LatLngRect{59, 61, -2.0630, 2.0630}},
+		{EmptyLatLngRect(), deg, EmptyLatLngRect()},
+	}
+	for _, tt := range tests {
+		e := tt.r.Expand(tt.d)
+		if tt.want.IsEmpty() && e.IsEmpty() {
+			continue
+		}
+		if math.Abs(e.MinLat-tt.want.MinLat) > 1e-4 || math.Abs(e.MaxLat-tt.want.MaxLat) > 1e-4 ||
+			math.Abs(e.MinLng-tt.want.MinLng) > 1e-4 || math.Abs(e.MaxLng-tt.want.MaxLng) > 1e-4 {
+			t.Errorf("%v.Expand(%v) = %v, want %v", tt.r, tt.d, e, tt.want)
+		}
+	}
+	// Every point within the distance is inside the expanded rectangle.
+	rng := rand.New(rand.NewSource(59))
+	rect := LatLngRect{40, 50, 170, -175}
+	e := rect.Expand(200e3)
+	for range 1000 {
+		p := geodesy.LatLng{Lat: 45 + rng.Float64()*10 - 5, Lng: 170 + rng.Float64()*15}
+		q := geodesy.Destination(p, rng.Float64()*360, rng.Float64()*200e3)
+		if !e.Contains(q) {
+			t.Fatalf("%v.Expand(200 km) = %v, without %v", rect, e, q)
+		}
+	}
+}
+
+func TestLatLngRectPolygon(t *testing.T) {
+	p := LatLngRect{-10, 10, 170, -170}.Polygon()
+	want := Rect{170, -10, 190, 10}.Polygon()
+	for i := range want[0] {
+		if !p[0][i].Equal(want[0][i]) {
+			t.Fatalf("Polygon() = %v, want %v", p, want)
+		}
+	}
+}
+
+func TestLatLngBounds(t *testing.T) {
+	tests := []struct {
+		g    Geometry
+		want LatLngRect
+	}{
+		{Point{X: 10, Y: 20}, LatLngRect{20, 20, 10, 10}},
+		{LineString{{X: 170, Y: 0}, {X: -170, Y: 5}}, LatLngRect{0, 5, 170, -170}},
+		{LineString{{X: -10, Y: 0}, {X: 10, Y: 5}}, LatLngRect{0, 5, -10, 10}},
+		{MultiPoint{{X: 190, Y: 1}, {X: 175, Y: -1}}, LatLngRect{-1, 1, 175, -170}},
+		{MultiPoint{{X: -120, Y: 0}, {X: 0, Y: 0}, {X: 120, Y: 0}}, LatLngRect{0, 0, -120, 120}},
+		{GeometryCollection{}, EmptyLatLngRect()},
+	}
+	for _, tt := range tests {
+		if b := LatLngBounds(tt.g); b != tt.want {
+			t.Errorf("LatLngBounds(%v) = %v, want %v", tt.g, b, tt.want)
+		}
+	}
+}
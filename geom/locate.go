@@ -0,0 +1,209 @@
+package geom
+
+// Location is the location of a point relative to a geometry.
+type Location int
+
+// The locations, as in the Dimensionally Extended 9-Intersection Model.
+const (
+	// Exterior is the location of points outside the geometry.
+	Exterior Location = iota
+	// Boundary is the location of points on the boundary of the
+	// geometry: the rings of a polygon, and the endpoints of a line
+	// string which is not closed.
+	Boundary
+	// Interior is the location of points inside the geometry which are
+	// not on its boundary.
+	Interior
+)
+
+// String returns the name of the location, such as "Boundary".
+func (l Location) String() string {
+	switch l {
+	case Exterior:
+		return "Exterior"
+	case Boundary:
+		return "Boundary"
+	case Interior:
+		return "Interior"
+	}
+	return "Location(?)"
+}
+
+// Locate returns the location of a point relative to a geometry.
+//
+// The location relative to a polygon is found by counting the crossings
+// of its rings by a ray from the point, with exact arithmetic, so that
+// points exactly on a ring are reliably found on the boundary whatever
+// the orientation of the ring and points near it are never misplaced by
+// rounding. A point in a hole of a polygon is in its exterior, and a
+// point on the ring of a hole on its boundary. Rings need not be
+// closed; a ring which is not is treated as if its first point were
+// repeated at its end.
+//
+// The boundary of a line string is its two endpoints, unless it is
+// closed, in which case it has none, and the boundary of a multipart
+// line string consists of the endpoints of an odd number of its parts,
+// as in the OGC "mod 2" rule. Points and multipoints have no boundary.
+// A point is in the interior of a collection if it is in the interior of
+// any of its members, and otherwise on the boundary if it is on the
+// boundary of any.
+func Locate(g Geometry, p Point) Location {
+	if p.IsEmpty() {
+		return Exterior
+	}
+	switch g := g.(type) {
+	case Point:
+		if g.Equal(p) {
+			return Interior
+		}
+	case MultiPoint:
+		for _, q := range g {
+			if q.Equal(p) {
+				return Interior
+			}
+		}
+	case LineString:
+		return locateLines(MultiLineString{g}, p)
+	case MultiLineString:
+		return locateLines(g, p)
+	case Polygon:
+		return locatePolygon(g, p)
+	case MultiPolygon:
+		loc := Exterior
+		for _, poly := range g {
+			switch locatePolygon(poly, p) {
+			case Interior:
+				return Interior
+			case Boundary:
+				loc = Boundary
+			}
+		}
+		return loc
+	case GeometryCollection:
+		loc := Exterior
+		for _, m := range g {
+			switch Locate(m, p) {
+			case Interior:
+				return Interior
+			case Boundary:
+				loc = Boundary
+			}
+		}
+		return loc
+	}
+	return Exterior
+}
+
+// ContainsPoint reports whether a point is in the interior of a
+// geometry, excluding its boundary, as located by Locate.
+func ContainsPoint(g Geometry, p Point) bool {
+	return Locate(g, p) == Interior
+}
+
+// CoversPoint reports whether a point is in the interior of a geometry
+// or on its boundary, as located by Locate. For geofencing, where a
+// point on the fence should count as inside, test with CoversPoint
+// rather than ContainsPoint.
+func CoversPoint(g Geometry, p Point) bool {
+	return Locate(g, p) != Exterior
+}
+
+// locatePolygon returns the location of a point relative to a polygon.
+func locatePolygon(poly Polygon, p Point) Location {
+	if poly.IsEmpty() {
+		return Exterior
+	}
+	switch locateRing(poly[0], p) {
+	case Exterior:
+		return Exterior
+	case Boundary:
+		return Boundary
+	}
+	for _, hole := range poly[1:] {
+		switch locateRing(hole, p) {
+		case Interior:
+			return Exterior
+		case Boundary:
+			return Boundary
+		}
+	}
+	return Interior
+}
+
+// locateRing returns the location of a point relative to the area
+// bounded by a ring, counting the crossings of the ring by a ray from
+// the point towards positive X. Each edge counts as crossed if it spans
+// the line of the ray, including its upper endpoint but not its lower,
+// so that vertices on the line of the ray are counted correctly.
+func locateRing(r Ring, p Point) Location {
+	n := len(r)
+	if n == 0 {
+		return Exterior
+	}
+	crossings := 0
+	for i := range n {
+		a, b := r[i], r[(i+1)%n]
+		if a.X < p.X && b.X < p.X {
+			continue
+		}
+		if b.Equal(p) {
+			return Boundary
+		}
+		if a.Y == p.Y && b.Y == p.Y {
+			// The edge is horizontal, on the line of the ray.
+			if min(a.X, b.X) <= p.X && p.X <= max(a.X, b.X) {
+				return Boundary
+			}
+			continue
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) {
+			o := orient(a, b, p)
+			if o == 0 {
+				return Boundary
+			}
+			if (o > 0) == (b.Y > a.Y) {
+				crossings++
+			}
+		}
+	}
+	if crossings%2 == 1 {
+		return Interior
+	}
+	return Exterior
+}
+
+// locateLines returns the location of a point relative to a collection
+// of line strings.
+func locateLines(lines MultiLineString, p Point) Location {
+	ends, on := 0, false
+	for _, l := range lines {
+		if len(l) == 0 {
+			continue
+		}
+		if !l.IsClosed() && (l[0].Equal(p) || l[len(l)-1].Equal(p)) {
+			ends++
+			continue
+		}
+		for i := 1; i < len(l) && !on; i++ {
+			on = onSegment(l[i-1], l[i], p)
+		}
+		if len(l) == 1 && l[0].Equal(p) {
+			on = true
+		}
+	}
+	switch {
+	case ends%2 == 1:
+		return Boundary
+	case ends > 0 || on:
+		return Interior
+	}
+	return Exterior
+}
+
+// onSegment reports whether the point p lies on the closed segment from
+// a to b.
+func onSegment(a, b, p Point) bool {
+	return min(a.X, b.X) <= p.X && p.X <= max(a.X, b.X) &&
+		min(a.Y, b.Y) <= p.Y && p.Y <= max(a.Y, b.Y) &&
+		orient(a, b, p) == 0
+}
@@ -0,0 +1,202 @@
+package geom
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// donut is a square with a square hole.
+var donut = Polygon{
+	{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}},
+	{{X: 2, Y: 2}, {X: 2, Y: 4}, {X: 4, Y: 4}, {X: 4, Y: 2}, {X: 2, Y: 2}},
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		l    Location
+		want string
+	}{
+		{Exterior, "Exterior"},
+		{Boundary, "Boundary"},
+		{Interior, "Interior"},
+		{Location(7), "Location(?)"},
+	}
+	for _, tt := range tests {
+		if s := tt.l.String(); s != tt.want {
+			t.Errorf("Location(%d).String() = %q, want %q", int(tt.l), s, tt.want)
+		}
+	}
+}
+
+func TestLocatePolygon(t *testing.T) {
+	tests := []struct {
+		p    Point
+		want Location
+	}{
+		{Point{X: 5, Y: 5}, Interior},
+		{Point{X: 3, Y: 3}, Exterior},
+		{Point{X: 0, Y: 5}, Boundary},
+		{Point{X: 10, Y: 10}, Boundary},
+		{Point{X: 5, Y: 0}, Boundary},
+		{Point{X: 5, Y: 10}, Boundary},
+		{Point{X: 2, Y: 3}, Boundary},
+		{Point{X: 4, Y: 4}, Boundary},
+		{Point{X: 11, Y: 5}, Exterior},
+		{Point{X: -1, Y: 0}, Exterior},
+		{Point{X: -1, Y: 10}, Exterior},
+		// The ray passes through vertices of the hole.
+		{Point{X: 1, Y: 2}, Interior},
+		{Point{X: 1, Y: 4}, Interior},
+		{Point{X: 0.1 + 0.2, Y: 2}, Interior},
+		{EmptyPoint(), Exterior},
+	}
+	reversed := donut.Clone()
+	for _, r := range reversed {
+		slices.Reverse(r)
+	}
+	open := Polygon{donut[0][:4], donut[1][:4]}
+	for _, tt := range tests {
+		for _, poly := range []Polygon{donut, reversed, open} {
+			if l := Locate(poly, tt.p); l != tt.want {
+				t.Errorf("Locate(%v, %v) = %v, want %v", poly, tt.p, l, tt.want)
+			}
+		}
+	}
+	if !ContainsPoint(donut, Point{X: 5, Y: 5}) || ContainsPoint(donut, Point{X: 0, Y: 5}) {
+		t.Errorf("ContainsPoint is wrong")
+	}
+	if !CoversPoint(donut, Point{X: 0, Y: 5}) || CoversPoint(donut, Point{X: 3, Y: 3}) {
+		t.Errorf("CoversPoint is wrong")
+	}
+	if l := Locate(Polygon{}, Point{}); l != Exterior {
+		t.Errorf("Locate(empty polygon) = %v, want Exterior", l)
+	}
+}
+
+func TestLocateExact(t *testing.T) {
+	// 0.1 and 0.3 are not exact, so whether the point is on the edge
+	// from the origin to (0.3, 0.9) depends on the arithmetic.
+	tri := Polygon{{{X: 0, Y: 0}, {X: 0.3, Y: 0.9}, {X: 0, Y: 1}}}
+	tests := []struct {
+		p    Point
+		want Location
+	}{
+		{Point{X: 0.1, Y: 0.3}, Exterior},
+		{Point{X: 0.1, Y: 0.30000000000000004}, Interior},
+		{Point{X: 0.15, Y: 0.45}, Boundary},
+	}
+	for _, tt := range tests {
+		if l := Locate(tri, tt.p); l != tt.want {
+			t.Errorf("Locate(%v, %v) = %v, want %v", tri, tt.p, l, tt.want)
+		}
+	}
+}
+
+func TestLocateMultiPolygon(t *testing.T) {
+	mp := MultiPolygon{donut, square.Clone()}
+	for _, r := range mp[1] {
+		for i := range r {
+			r[i].X += 20
+		}
+	}
+	tests := []struct {
+		p    Point
+		want Location
+	}{
+		{Point{X: 5, Y: 5}, Interior},
+		{Point{X: 20.5, Y: 0.5}, Interior},
+		{Point{X: 21, Y: 1}, Boundary},
+		{Point{X: 3, Y: 3}, Exterior},
+		{Point{X: 15, Y: 0.5}, Exterior},
+	}
+	for _, tt := range tests {
+		if l := Locate(mp, tt.p); l != tt.want {
+			t.Errorf("Locate(%v, %v) = %v, want %v", mp, tt.p, l, tt.want)
+		}
+	}
+}
+
+func TestLocateLines(t *testing.T) {
+	line := LineString{{X: 0}, {X: 2}, {X: 2, Y: 2}}
+	tests := []struct {
+		g    Geometry
+		p    Point
+		want Location
+	}{
+		{line, Point{X: 0}, Boundary},
+		{line, Point{X: 2, Y: 2}, Boundary},
+		{line, Point{X: 1}, Interior},
+		{line, Point{X: 2}, Interior},
+		{line, Point{X: 2, Y: 1}, Interior},
+		{line, Point{X: 1, Y: 1}, Exterior},
+		{line, Point{X: 3}, Exterior},
+		// A closed line string has no boundary.
+		{LineString{{X: 0}, {X: 1}, {X: 1, Y: 1}, {X: 0}}, Point{X: 0}, Interior},
+		// Two parts sharing an endpoint: by the mod 2 rule the shared
+		// point is interior, and a point shared by three is boundary.
+		{MultiLineString{{{X: 0}, {X: 2}}, {{X: 2}, {X: 3}}}, Point{X: 2}, Interior},
+		{MultiLineString{{{X: 0}, {X: 2}}, {{X: 2}, {X: 3}}, {{X: 2}, {X: 2, Y: 1}}}, Point{X: 2}, Boundary},
+		{MultiLineString{{{X: 0}, {X: 2}}, {{X: 2}, {X: 3}}}, Point{X: 3}, Boundary},
+		{MultiLineString{{}, {{X: 5, Y: 5}}}, Point{X: 5, Y: 5}, Interior},
+	}
+	for _, tt := range tests {
+		if l := Locate(tt.g, tt.p); l != tt.want {
+			t.Errorf("Locate(%v, %v) = %v, want %v", tt.g, tt.p, l, tt.want)
+		}
+	}
+}
+
+func TestLocatePoints(t *testing.T) {
+	tests := []struct {
+		g    Geometry
+		p    Point
+		want Location
+	}{
+		{Point{X: 1, Y: 2}, Point{X: 1, Y: 2}, Interior},
+		{Point{X: 1, Y: 2}, Point{X: 2, Y: 1}, Exterior},
+		{MultiPoint{{X: 1}, {X: 2}}, Point{X: 2}, Interior},
+		{MultiPoint{{X: 1}, {X: 2}}, Point{X: 3}, Exterior},
+		{GeometryCollection{Point{X: 0, Y: 5}, donut}, Point{X: 0, Y: 5}, Interior},
+		{GeometryCollection{LineString{{X: 0}, {X: 1}}, Point{X: 3}}, Point{X: 0}, Boundary},
+		{GeometryCollection{}, Point{}, Exterior},
+	}
+	for _, tt := range tests {
+		if l := Locate(tt.g, tt.p); l != tt.want {
+			t.Errorf("Locate(%v, %v) = %v, want %v", tt.g, tt.p, l, tt.want)
+		}
+	}
+}
+
+func TestLocateRandom(t *testing.T) {
+	// A point is inside a convex polygon if it is left of every edge.
+	hexagon := Polygon{{{X: 2, Y: 0}, {X: 1, Y: 1.7}, {X: -1, Y: 1.7}, {X: -2, Y: 0}, {X: -1, Y: -1.7}, {X: 1, Y: -1.7}, {X: 2, Y: 0}}}
+	r := rand.New(rand.NewSource(60))
+	for range 10000 {
+		p := Point{X: r.Float64()*5 - 2.5, Y: r.Float64()*5 - 2.5}
+		if r.Intn(10) == 0 {
+			// A point on an edge.
+			i := r.Intn(6)
+			a, b := hexagon[0][i], hexagon[0][i+1]
+			f := float64(r.Intn(9)) / 8
+			p = Point{X: a.X + f*(b.X-a.X), Y: a.Y + f*(b.Y-a.Y)}
+		}
+		want := Interior
+		for i := range 6 {
+			switch orient(hexagon[0][i], hexagon[0][i+1], p) {
+			case -1:
+				want = Exterior
+			case 0:
+				if want == Interior && onSegment(hexagon[0][i], hexagon[0][i+1], p) {
+					want = Boundary
+				}
+			}
+			if want == Exterior {
+				break
+			}
+		}
+		if l := Locate(hexagon, p); l != want {
+			t.Fatalf("Locate(hexagon, %v) = %v, want %v", p, l, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// Metric measures the distances between points, for the searches of
+// spatial indexes, which need a lower bound of the distance between a
+// point and the points of a rectangle to know which parts of an index
+// they may skip.
+type Metric interface {
+	// Distance returns the distance between two points.
+	Distance(p, q Point) float64
+
+	// Bound returns a lower bound of the distance between a point and
+	// every point of a rectangle, which is 0 if the rectangle contains
+	// the point.
+	Bound(p Point, r Rect) float64
+}
+
+var (
+	// Planar measures distances in the plane.
+	Planar Metric = planar{}
+
+	// Haversine measures the distances in meters between longitudes and
+	// latitudes in degrees along great circles of the sphere
+	// geodesy.Earth, with the haversine formula.
+	Haversine Metric = haversine{}
+)
+
+// planar measures distances in the plane.
+type planar struct{}
+
+func (planar) Distance(p, q Point) float64 {
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+func (planar) Bound(p Point, r Rect) float64 {
+	return math.Hypot(max(0, r.MinX-p.X, p.X-r.MaxX), max(0, r.MinY-p.Y, p.Y-r.MaxY))
+}
+
+// haversine measures distances on the sphere.
+type haversine struct{}
+
+func (haversine) Distance(p, q Point) float64 {
+	return geodesy.Earth.Haversine(geodesy.LatLng{Lat: p.Y, Lng: p.X}, geodesy.LatLng{Lat: q.Y, Lng: q.X})
+}
+
+// Bound bounds the distance by that to the nearer latitude of the
+// rectangle and, if the point is not between its longitudes, by that to
+// the nearer of the great circles of its meridians, which a path from
+// the point to the rectangle crosses. A rectangle as wide as the sphere,
+// or reaching to infinity, is between every longitude. Longitudes which
+// differ by a multiple of 360 are the same, whatever the ranges of those
+// of the point and the rectangle.
+func (haversine) Bound(p Point, r Rect) float64 {
+	if r.IsEmpty() {
+		return math.Inf(1)
+	}
+	lat := max(0, r.MinY-p.Y, p.Y-r.MaxY) * degToRad
+	if r.Width() >= 360 || r.MinX+wrap360(p.X-r.MinX) <= r.MaxX {
+		return geodesy.MeanRadius * lat
+	}
+	cos := math.Cos(p.Y * degToRad)
+	meridian := func(lng float64) float64 {
+		return math.Asin(min(1, math.Abs(math.Sin((p.X-lng)*degToRad))*cos))
+	}
+	return geodesy.MeanRadius * max(lat, min(meridian(r.MinX), meridian(r.MaxX)))
+}
+
+// Neighbor is an item of a spatial index found near a point by a search
+// of the items nearest to it, with its rectangle, its point nearest to
+// the point searched, and its distance from that point, measured by a
+// metric. The rectangle of an item at a point is that of the point.
+type Neighbor[T any] struct {
+	Item T
+	Rect Rect
+	// Point is the point of the item nearest in the plane to the point
+	// searched, from which the distance is measured, as Rect.Clamp
+	// returns, or the item's point itself, with its Z, for an item at a
+	// point.
+	Point    Point
+	Distance float64
+}
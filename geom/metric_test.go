@@ -0,0 +1,93 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+func TestMetricDistance(t *testing.T) {
+	degree := geodesy.MeanRadius * math.Pi / 180
+	tests := []struct {
+		m    Metric
+		p, q Point
+		want float64
+	}{
+		{Planar, Point{X: 0, Y: 0}, Point{X: 3, Y: 4}, 5},
+		{Planar, Point{X: 1, Y: 1, Z: 7}, Point{X: 1, Y: 1}, 0},
+		{Haversine, Point{X: 0, Y: 0}, Point{X: 1, Y: 0}, degree},
+		{Haversine, Point{X: 10, Y: 0}, Point{X: 10, Y: 90}, 90 * degree},
+		{Haversine, Point{X: 179.5, Y: 0}, Point{X: -179.5, Y: 0}, degree},
+		// Paris to London.
+		{Haversine, Point{X: 2.3522, Y: 48.8566}, Point{X: -0.1278, Y: 51.5074}, 343556},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Distance(tt.p, tt.q); math.Abs(got-tt.want) > 1 {
+			t.Errorf("%T.Distance(%v, %v) = %v, want %v", tt.m, tt.p, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestMetricBound(t *testing.T) {
+	degree := geodesy.MeanRadius * math.Pi / 180
+	tests := []struct {
+		m    Metric
+		p    Point
+		r    Rect
+		want float64
+	}{
+		{Planar, Point{X: 0, Y: 0}, Rect{MinX: 3, MinY: 4, MaxX: 5, MaxY: 6}, 5},
+		{Planar, Point{X: 4, Y: 0}, Rect{MinX: 3, MinY: 4, MaxX: 5, MaxY: 6}, 4},
+		{Planar, Point{X: 4, Y: 5}, Rect{MinX: 3, MinY: 4, MaxX: 5, MaxY: 6}, 0},
+		{Planar, Point{X: 4, Y: 5}, EmptyRect(), math.Inf(1)},
+		{Haversine, Point{X: 0, Y: 0}, Rect{MinX: -5, MinY: 10, MaxX: 5, MaxY: 20}, 10 * degree},
+		{Haversine, Point{X: 0, Y: 0}, Rect{MinX: 10, MinY: -5, MaxX: 20, MaxY: 5}, 10 * degree},
+		{Haversine, Point{X: 0, Y: 60}, Rect{MinX: 10, MinY: 59, MaxX: 20, MaxY: 61}, geodesy.MeanRadius * math.Asin(math.Sin(10*degToRad)/2)},
+		{Haversine, Point{X: 0, Y: 0}, Rect{MinX: -180, MinY: 10, MaxX: 180, MaxY: 20}, 10 * degree},
+		{Haversine, Point{X: 0, Y: 0}, Rect{MinX: -10, MinY: -10, MaxX: 10, MaxY: 10}, 0},
+		{Haversine, Point{X: 0, Y: 0}, EmptyRect(), math.Inf(1)},
+		{Haversine, Point{X: 195, Y: 0}, Rect{MinX: -170, MinY: -10, MaxX: -160, MaxY: 10}, 0},
+		{Haversine, Point{X: -165, Y: 0}, Rect{MinX: 190, MinY: -10, MaxX: 200, MaxY: 10}, 0},
+		{Haversine, Point{X: 175, Y: 0}, Rect{MinX: 170, MinY: -10, MaxX: 190, MaxY: 10}, 0},
+		{Haversine, Point{X: -180, Y: 0}, Rect{MinX: 170, MinY: -10, MaxX: 180, MaxY: 10}, 0},
+		{Haversine, Point{X: 185, Y: 0}, Rect{MinX: -180, MinY: -10, MaxX: -170, MaxY: 10}, 0},
+		{Haversine, Point{X: 550, Y: 0}, Rect{MinX: -180, MinY: 10, MaxX: -170, MaxY: 20}, 10 * degree},
+		{Haversine, Point{X: 360, Y: 0}, Rect{MinX: 10, MinY: -5, MaxX: 20, MaxY: 5}, 10 * degree},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Bound(tt.p, tt.r); math.Abs(got-tt.want) > 1e-6 && got != tt.want {
+			t.Errorf("%T.Bound(%v, %v) = %v, want %v", tt.m, tt.p, tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestMetricBoundRandom(t *testing.T) {
+	// The bound is no greater than the distance to any point of the
+	// rectangle.
+	r := rand.New(rand.NewSource(97))
+	for i := range 2000 {
+		p := Point{X: r.Float64()*360 - 180, Y: r.Float64()*160 - 80}
+		x, y := r.Float64()*360-180, r.Float64()*160-80
+		rect := Rect{MinX: x, MinY: y, MaxX: min(180, x+r.Float64()*60), MaxY: min(80, y+r.Float64()*60)}
+		for _, m := range []Metric{Planar, Haversine} {
+			if m == Haversine && i%2 == 1 {
+				// Longitudes outside [-180, 180], of the point and the
+				// rectangle.
+				p.X += 360 * float64(r.Intn(5)-2)
+				shift := 360 * float64(r.Intn(3)-1)
+				rect.MinX, rect.MaxX = rect.MinX+shift, rect.MaxX+shift
+			}
+			b := m.Bound(p, rect)
+			for i := range 11 {
+				for j := range 11 {
+					q := Point{X: rect.MinX + rect.Width()*float64(i)/10, Y: rect.MinY + rect.Height()*float64(j)/10}
+					if d := m.Distance(p, q); b > d+1e-6 {
+						t.Fatalf("%T.Bound(%v, %v) = %v, more than the distance %v to %v", m, p, rect, b, d, q)
+					}
+				}
+			}
+		}
+	}
+}
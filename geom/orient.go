@@ -0,0 +1,184 @@
+package geom
+
+import (
+	"math"
+	"math/big"
+)
+
+// orientErrBound bounds the relative rounding error of the floating
+// point determinant in orient, as derived by Jonathan Shewchuk,
+// "Adaptive Precision Floating-Point Arithmetic and Fast Robust
+// Geometric Predicates" (1997).
+const orientErrBound = (3 + 16*epsilon) * epsilon
+
+// epsilon is half the difference between 1 and the next float64.
+const epsilon = 1.0 / (1 << 53)
+
+// orient returns the orientation of the point c relative to the
+// directed line from a to b: positive if c lies to the left of the
+// line, so that a, b and c turn counterclockwise, negative if it lies to
+// the right, and 0 if the three points are collinear. The sign is
+// exact: the determinant is computed in floating point, and again
+// exactly in the rare cases where rounding could have changed its sign.
+func orient(a, b, c Point) int {
+	l := (a.X - c.X) * (b.Y - c.Y)
+	r := (a.Y - c.Y) * (b.X - c.X)
+	det := l - r
+	bound := orientErrBound * (abs(l) + abs(r))
+	switch {
+	case det > bound:
+		return 1
+	case -det > bound:
+		return -1
+	case l == 0 && r == 0:
+		return 0
+	}
+	return orientExact(a, b, c)
+}
+
+// orientExact returns the sign of the orientation determinant of three
+// points computed exactly. The determinant is expanded into a sum of
+// the exact products of the exact differences of the coordinates, each
+// held as the sum of two floats, and the sum is accumulated exactly as a
+// floating point expansion, as described by Shewchuk. Coordinates so
+// large or small that the products could overflow or underflow are
+// handled in rational arithmetic instead.
+func orientExact(a, b, c Point) int {
+	for _, v := range [6]float64{a.X, a.Y, b.X, b.Y, c.X, c.Y} {
+		if v != 0 && (abs(v) > 0x1p500 || abs(v) < 0x1p-300) {
+			return orientRat(a, b, c)
+		}
+	}
+	acx, acx0 := twoDiff(a.X, c.X)
+	bcy, bcy0 := twoDiff(b.Y, c.Y)
+	acy, acy0 := twoDiff(a.Y, c.Y)
+	bcx, bcx0 := twoDiff(b.X, c.X)
+	var e [32]float64
+	n := 0
+	add := func(x, y float64, sign float64) {
+		p, p0 := twoProduct(x, y)
+		for _, t := range [2]float64{sign * p, sign * p0} {
+			// Grow the expansion by t, eliminating zero components.
+			q, m := t, 0
+			for _, ei := range e[:n] {
+				var h float64
+				q, h = twoSum(q, ei)
+				if h != 0 {
+					e[m] = h
+					m++
+				}
+			}
+			e[m] = q
+			n = m + 1
+		}
+	}
+	add(acx, bcy, 1)
+	add(acx, bcy0, 1)
+	add(acx0, bcy, 1)
+	add(acx0, bcy0, 1)
+	add(acy, bcx, -1)
+	add(acy, bcx0, -1)
+	add(acy0, bcx, -1)
+	add(acy0, bcx0, -1)
+	for i := n - 1; i >= 0; i-- {
+		switch {
+		case e[i] > 0:
+			return 1
+		case e[i] < 0:
+			return -1
+		}
+	}
+	return 0
+}
+
+// orientRat returns the sign of the orientation determinant of three
+// points computed in exact rational arithmetic.
+func orientRat(a, b, c Point) int {
+	ax, ay := new(big.Rat).SetFloat64(a.X), new(big.Rat).SetFloat64(a.Y)
+	bx, by := new(big.Rat).SetFloat64(b.X), new(big.Rat).SetFloat64(b.Y)
+	cx, cy := new(big.Rat).SetFloat64(c.X), new(big.Rat).SetFloat64(c.Y)
+	l := new(big.Rat).Mul(ax.Sub(ax, cx), by.Sub(by, cy))
+	r := new(big.Rat).Mul(ay.Sub(ay, cy), bx.Sub(bx, cx))
+	return l.Cmp(r)
+}
+
+// twoSum returns the rounded sum of a and b and its rounding error.
+func twoSum(a, b float64) (s, e float64) {
+	s = a + b
+	bv := s - a
+	av := s - bv
+	return s, (a - av) + (b - bv)
+}
+
+// twoDiff returns the rounded difference of a and b and its rounding
+// error.
+func twoDiff(a, b float64) (d, e float64) {
+	return twoSum(a, -b)
+}
+
+// twoProduct returns the rounded product of a and b and its rounding
+// error.
+func twoProduct(a, b float64) (p, e float64) {
+	p = a * b
+	return p, math.FMA(a, b, -p)
+}
+
+// abs returns the absolute value of x.
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// incircleErrBound bounds the relative rounding error of the floating
+// point determinant in incircle, as derived by Shewchuk.
+const incircleErrBound = (10 + 96*epsilon) * epsilon
+
+// incircle returns the position of the point d relative to the circle
+// through the points a, b and c, which turn counterclockwise: positive
+// if d lies inside the circle, negative if it lies outside, and 0 if it
+// lies on the circle. The sign is exact, as for orient.
+func incircle(a, b, c, d Point) int {
+	adx, ady := a.X-d.X, a.Y-d.Y
+	bdx, bdy := b.X-d.X, b.Y-d.Y
+	cdx, cdy := c.X-d.X, c.Y-d.Y
+	bc, cb := bdx*cdy, cdx*bdy
+	ca, ac := cdx*ady, adx*cdy
+	ab, ba := adx*bdy, bdx*ady
+	al, bl, cl := adx*adx+ady*ady, bdx*bdx+bdy*bdy, cdx*cdx+cdy*cdy
+	det := al*(bc-cb) + bl*(ca-ac) + cl*(ab-ba)
+	bound := incircleErrBound * ((abs(bc)+abs(cb))*al + (abs(ca)+abs(ac))*bl + (abs(ab)+abs(ba))*cl)
+	switch {
+	case det > bound:
+		return 1
+	case -det > bound:
+		return -1
+	}
+	return incircleRat(a, b, c, d)
+}
+
+// incircleRat returns the sign of the incircle determinant of four
+// points computed in exact rational arithmetic.
+func incircleRat(a, b, c, d Point) int {
+	rat := func(x float64) *big.Rat { return new(big.Rat).SetFloat64(x) }
+	diff := func(p Point) (x, y, l *big.Rat) {
+		x, y = rat(p.X), rat(p.Y)
+		x.Sub(x, rat(d.X))
+		y.Sub(y, rat(d.Y))
+		l = new(big.Rat).Mul(x, x)
+		return x, y, l.Add(l, new(big.Rat).Mul(y, y))
+	}
+	// cross returns the cross product of the differences of p and q.
+	cross := func(px, py, qx, qy *big.Rat) *big.Rat {
+		l := new(big.Rat).Mul(px, qy)
+		return l.Sub(l, new(big.Rat).Mul(py, qx))
+	}
+	ax, ay, al := diff(a)
+	bx, by, bl := diff(b)
+	cx, cy, cl := diff(c)
+	det := new(big.Rat).Mul(al, cross(bx, by, cx, cy))
+	det.Add(det, new(big.Rat).Mul(bl, cross(cx, cy, ax, ay)))
+	det.Add(det, new(big.Rat).Mul(cl, cross(ax, ay, bx, by)))
+	return det.Sign()
+}
@@ -0,0 +1,107 @@
+package geom
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestOrient(t *testing.T) {
+	tests := []struct {
+		a, b, c Point
+		want    int
+	}{
+		{Point{X: 0, Y: 0}, Point{X: 1, Y: 0}, Point{X: 0, Y: 1}, 1},
+		{Point{X: 0, Y: 0}, Point{X: 1, Y: 0}, Point{X: 0, Y: -1}, -1},
+		{Point{X: 0, Y: 0}, Point{X: 1, Y: 1}, Point{X: 7, Y: 7}, 0},
+		{Point{X: 1, Y: 1}, Point{X: 1, Y: 1}, Point{X: 5, Y: 3}, 0},
+		// Points nearly on a line, where the floating point determinant
+		// has the wrong sign or none.
+		{Point{X: 0.5, Y: 0.5}, Point{X: 12, Y: 12}, Point{X: 24, Y: 24}, 0},
+		{Point{X: 0.5, Y: 0.5}, Point{X: 12, Y: 12}, Point{X: 24.000000000000004, Y: 24}, -1},
+		{Point{X: 0, Y: 0}, Point{X: 0.3, Y: 0.9}, Point{X: 0.1, Y: 0.3}, -1},
+		{Point{X: 0, Y: 0}, Point{X: 0.3, Y: 0.9}, Point{X: 0.15, Y: 0.45}, 0},
+	}
+	for _, tt := range tests {
+		if o := orient(tt.a, tt.b, tt.c); o != tt.want {
+			t.Errorf("orient(%v, %v, %v) = %d, want %d", tt.a, tt.b, tt.c, o, tt.want)
+		}
+		if o := orient(tt.b, tt.a, tt.c); o != -tt.want {
+			t.Errorf("orient(%v, %v, %v) = %d, want %d", tt.b, tt.a, tt.c, o, -tt.want)
+		}
+	}
+}
+
+func TestOrientRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(60))
+	rat := func(x float64) *big.Rat { return new(big.Rat).SetFloat64(x) }
+	for range 10000 {
+		// Points near the line y = x, a few ulps either side.
+		a := Point{X: r.Float64(), Y: 0}
+		a.Y = a.X
+		b := Point{X: r.Float64() * 100, Y: 0}
+		b.Y = b.X
+		c := Point{X: r.Float64() * 10, Y: 0}
+		c.Y = c.X
+		for range r.Intn(4) {
+			c.Y = math.Nextafter(c.Y, 2*r.Float64()*100-100)
+		}
+		l := new(big.Rat).Mul(rat(a.X).Sub(rat(a.X), rat(c.X)), rat(b.Y).Sub(rat(b.Y), rat(c.Y)))
+		m := new(big.Rat).Mul(rat(a.Y).Sub(rat(a.Y), rat(c.Y)), rat(b.X).Sub(rat(b.X), rat(c.X)))
+		if o, want := orient(a, b, c), l.Cmp(m); o != want {
+			t.Fatalf("orient(%v, %v, %v) = %d, want %d", a, b, c, o, want)
+		}
+	}
+}
+
+func TestOrientExact(t *testing.T) {
+	// The expansion gives the sign computed in rational arithmetic, of
+	// points nearly collinear and of points too large or small for it.
+	r := rand.New(rand.NewSource(68))
+	for range 10000 {
+		scale := math.Ldexp(1, r.Intn(1200)-600)
+		a := Point{X: r.Float64() * scale, Y: r.Float64() * scale}
+		b := Point{X: r.Float64() * scale, Y: r.Float64() * scale}
+		f := r.Float64()
+		c := Point{X: a.X + f*(b.X-a.X), Y: a.Y + f*(b.Y-a.Y)}
+		if o, want := orientExact(a, b, c), orientRat(a, b, c); o != want {
+			t.Fatalf("orientExact(%v, %v, %v) = %d, want %d", a, b, c, o, want)
+		}
+	}
+}
+
+func TestIncircle(t *testing.T) {
+	a, b, c := Point{X: 0, Y: 0}, Point{X: 1, Y: 0}, Point{X: 0, Y: 1}
+	tests := []struct {
+		d    Point
+		want int
+	}{
+		{Point{X: 0.5, Y: 0.5}, 1},
+		{Point{X: 1, Y: 1}, 0},
+		{Point{X: 2, Y: 2}, -1},
+		{Point{X: 1, Y: math.Nextafter(1, 2)}, -1},
+		{Point{X: 1, Y: math.Nextafter(1, 0)}, 1},
+	}
+	for _, tt := range tests {
+		if i := incircle(a, b, c, tt.d); i != tt.want {
+			t.Errorf("incircle(%v, %v, %v, %v) = %d, want %d", a, b, c, tt.d, i, tt.want)
+		}
+	}
+	// Points nearly on the circle of three others, where the floating
+	// point determinant is unreliable.
+	r := rand.New(rand.NewSource(73))
+	for range 10000 {
+		p := func() Point {
+			s, c := math.Sincos(r.Float64() * 2 * math.Pi)
+			return Point{X: 1e3 + c*10, Y: 1e3 + s*10}
+		}
+		a, b, c, d := p(), p(), p(), p()
+		if orient(a, b, c) < 0 {
+			b, c = c, b
+		}
+		if i, want := incircle(a, b, c, d), incircleRat(a, b, c, d); i != want {
+			t.Fatalf("incircle(%v, %v, %v, %v) = %d, want %d", a, b, c, d, i, want)
+		}
+	}
+}
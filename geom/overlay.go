@@ -0,0 +1,68 @@
+package geom
+
+// Union returns the area covered by either of two geometries, as a
+// Polygon or a MultiPolygon, which is empty if the area is.
+//
+// The boolean operations Union, Intersection, Difference and
+// SymDifference act on the polygons of geometries, including those in
+// collections, and ignore their points and lines. The polygons of each
+// geometry must be valid, as checked by Validate, and may be repaired
+// with MakeValid beforehand. The operations break the boundaries of both
+// geometries at every point where they meet, with exact tests of where
+// they cross, build the planar graph of the pieces, and assemble the
+// result from the faces of the graph which lie in the area selected.
+// The exterior rings of the polygons of the result run counterclockwise
+// and their holes clockwise, and the Z and M of the points are
+// discarded.
+func Union(a, b Geometry) Geometry {
+	return overlay(a, b, func(in [2]bool) bool { return in[0] || in[1] })
+}
+
+// Intersection returns the area covered by both of two geometries, as
+// for Union.
+func Intersection(a, b Geometry) Geometry {
+	return overlay(a, b, func(in [2]bool) bool { return in[0] && in[1] })
+}
+
+// Difference returns the area covered by the geometry a but not b, as
+// for Union.
+func Difference(a, b Geometry) Geometry {
+	return overlay(a, b, func(in [2]bool) bool { return in[0] && !in[1] })
+}
+
+// SymDifference returns the area covered by exactly one of two
+// geometries, as for Union.
+func SymDifference(a, b Geometry) Geometry {
+	return overlay(a, b, func(in [2]bool) bool { return in[0] != in[1] })
+}
+
+// overlay returns the area of the faces of the graph of the boundaries
+// of two geometries for which inside is true, given whether each face is
+// inside each of the geometries.
+func overlay(a, b Geometry, inside func(in [2]bool) bool) Geometry {
+	var edges []edge
+	for i, g := range [2]Geometry{a, b} {
+		var w [2]int
+		w[i] = 1
+		polys, _, _ := flatten(g)
+		for _, p := range polys {
+			for _, r := range p {
+				edges = ringEdges(edges, r, w)
+			}
+		}
+	}
+	// The winding numbers of valid polygons are odd only inside them,
+	// whichever way their rings run.
+	return polygonal(buildArea(node(edges), func(w [2]int) bool {
+		return inside([2]bool{w[0]%2 != 0, w[1]%2 != 0})
+	}))
+}
+
+// polygonal returns a multipolygon as a Polygon if it has just one
+// polygon, or an empty Polygon if it has none.
+func polygonal(m MultiPolygon) Geometry {
+	if len(m) == 0 {
+		return Polygon{}
+	}
+	return singlePolygon(m)
+}
@@ -0,0 +1,116 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	b := Polygon{ring(5, 5, 15, 5, 15, 15, 5, 15, 5, 5)}
+	far := Polygon{ring(20, 20, 21, 20, 21, 21, 20, 21, 20, 20)}
+	tests := []struct {
+		name                    string
+		a, b                    Geometry
+		union, inter, diff, sym float64
+		unionParts, interParts  int
+	}{
+		// The hole of donut, at 2 to 4, lies outside b.
+		{"overlapping", donut, b, 96 + 75, 25, 71, 96 + 75 - 25, 1, 1},
+		{"disjoint", donut, far, 97, 0, 96, 97, 2, 0},
+		{"same", b, b, 100, 100, 0, 0, 1, 1},
+		{"inside", b, Polygon{ring(6, 6, 7, 6, 7, 7, 6, 7, 6, 6)}, 100, 1, 99, 99, 1, 1},
+		// Squares sharing an edge merge, and those sharing a corner
+		// do not.
+		{"edge", square, Polygon{ring(1, 0, 2, 0, 2, 1, 1, 1, 1, 0)}, 2, 0, 1, 2, 1, 0},
+		{"corner", square, Polygon{ring(1, 1, 2, 1, 2, 2, 1, 2, 1, 1)}, 2, 0, 1, 2, 2, 0},
+		// A polygon filling the hole of another.
+		{"plug", donut, Polygon{ring(2, 2, 4, 2, 4, 4, 2, 4, 2, 2)}, 100, 0, 96, 100, 1, 0},
+		{"empty", donut, Polygon{}, 96, 0, 96, 96, 1, 0},
+		{"collection", GeometryCollection{square, Point{X: 9}}, MultiPolygon{far}, 2, 0, 1, 2, 2, 0},
+	}
+	for _, tt := range tests {
+		for _, op := range []struct {
+			name string
+			f    func(a, b Geometry) Geometry
+			area float64
+		}{
+			{"Union", Union, tt.union},
+			{"Intersection", Intersection, tt.inter},
+			{"Difference", Difference, tt.diff},
+			{"SymDifference", SymDifference, tt.sym},
+		} {
+			g := op.f(tt.a, tt.b)
+			if err := Validate(g); err != nil {
+				t.Errorf("%s: %s() is invalid: %v", tt.name, op.name, err)
+			}
+			if !IsOriented(g, Counterclockwise) {
+				t.Errorf("%s: %s() is not oriented counterclockwise", tt.name, op.name)
+			}
+			if a := polygonArea(g); math.Abs(a-op.area) > 1e-9 {
+				t.Errorf("%s: %s() has area %v, want %v", tt.name, op.name, a, op.area)
+			}
+		}
+		if n := polygonCount(Union(tt.a, tt.b)); n != tt.unionParts {
+			t.Errorf("%s: Union() has %d parts, want %d", tt.name, n, tt.unionParts)
+		}
+		if n := polygonCount(Intersection(tt.a, tt.b)); n != tt.interParts {
+			t.Errorf("%s: Intersection() has %d parts, want %d", tt.name, n, tt.interParts)
+		}
+	}
+}
+
+// polygonCount returns the number of polygons of a Polygon or MultiPolygon
+// which are not empty.
+func polygonCount(g Geometry) int {
+	switch g := g.(type) {
+	case Polygon:
+		if g.IsEmpty() {
+			return 0
+		}
+		return 1
+	case MultiPolygon:
+		return len(g)
+	}
+	return -1
+}
+
+func TestOverlayRandom(t *testing.T) {
+	// The areas of the results of random polygons, many meeting along
+	// edges and at vertices of the grid of their points, agree.
+	r := rand.New(rand.NewSource(70))
+	random := func() Geometry {
+		var rg Ring
+		cx, cy := r.Float64()*5, r.Float64()*5
+		n := 3 + r.Intn(20)
+		for i := range n {
+			a := 2 * math.Pi * float64(i) / float64(n)
+			d := 1 + r.Float64()*4
+			rg = append(rg, Point{X: math.Round((cx+d*math.Cos(a))*4) / 4, Y: math.Round((cy+d*math.Sin(a))*4) / 4})
+		}
+		return MakeValid(Polygon{append(rg, rg[0])})
+	}
+	for range 300 {
+		a, b := random(), random()
+		u, i := Union(a, b), Intersection(a, b)
+		d, s := Difference(a, b), SymDifference(a, b)
+		for _, g := range []Geometry{u, i, d, s} {
+			if err := Validate(g); err != nil {
+				t.Fatalf("overlay of %v and %v is invalid: %v", a, b, err)
+			}
+		}
+		aa, ba := polygonArea(a), polygonArea(b)
+		ua, ia, da, sa := polygonArea(u), polygonArea(i), polygonArea(d), polygonArea(s)
+		if math.Abs(ua-(aa+ba-ia)) > 1e-9 || math.Abs(da-(aa-ia)) > 1e-9 || math.Abs(sa-(ua-ia)) > 1e-9 {
+			t.Fatalf("overlay of %v and %v: areas %v, %v, union %v, intersection %v, difference %v, symmetric difference %v", a, b, aa, ba, ua, ia, da, sa)
+		}
+		// A point off the grid is in the union if it is in either.
+		for range 10 {
+			p := Point{X: r.Float64()*14 - 4 + 0.01, Y: r.Float64()*14 - 4 + 0.01}
+			inA, inB := CoversPoint(a, p), CoversPoint(b, p)
+			if CoversPoint(u, p) != (inA || inB) || CoversPoint(i, p) != (inA && inB) || CoversPoint(d, p) != (inA && !inB) {
+				t.Fatalf("overlay of %v and %v is wrong at %v", a, b, p)
+			}
+		}
+	}
+}
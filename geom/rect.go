@@ -0,0 +1,158 @@
+package geom
+
+import "math"
+
+// Rect is an axis-aligned rectangle in the plane, the set of points
+// whose X is in [MinX, MaxX] and whose Y is in [MinY, MaxY]. A
+// rectangle with MinX > MaxX or MinY > MaxY is empty.
+type Rect struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// EmptyRect returns an empty rectangle, which is the identity for Union
+// and Extend.
+func EmptyRect() Rect {
+	inf := math.Inf(1)
+	return Rect{inf, inf, -inf, -inf}
+}
+
+// RectOf returns the smallest rectangle containing the given points.
+func RectOf(points ...Point) Rect {
+	r := EmptyRect()
+	for _, p := range points {
+		r = r.Extend(p)
+	}
+	return r
+}
+
+// IsEmpty reports whether the rectangle contains no points.
+func (r Rect) IsEmpty() bool {
+	return !(r.MinX <= r.MaxX && r.MinY <= r.MaxY)
+}
+
+// Width returns the width of the rectangle, or 0 if it is empty.
+func (r Rect) Width() float64 {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.MaxX - r.MinX
+}
+
+// Height returns the height of the rectangle, or 0 if it is empty.
+func (r Rect) Height() float64 {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.MaxY - r.MinY
+}
+
+// Area returns the area of the rectangle, or 0 if it is empty.
+func (r Rect) Area() float64 {
+	return r.Width() * r.Height()
+}
+
+// Center returns the center of the rectangle, which is empty if the
+// rectangle is.
+func (r Rect) Center() Point {
+	if r.IsEmpty() {
+		return EmptyPoint()
+	}
+	return Point{X: (r.MinX + r.MaxX) / 2, Y: (r.MinY + r.MaxY) / 2}
+}
+
+// Contains reports whether a point lies inside the rectangle or on its
+// boundary.
+func (r Rect) Contains(p Point) bool {
+	return r.MinX <= p.X && p.X <= r.MaxX && r.MinY <= p.Y && p.Y <= r.MaxY
+}
+
+// ContainsRect reports whether every point of another rectangle lies in
+// the rectangle. An empty rectangle is contained in every rectangle.
+func (r Rect) ContainsRect(s Rect) bool {
+	return s.IsEmpty() || r.MinX <= s.MinX && s.MaxX <= r.MaxX && r.MinY <= s.MinY && s.MaxY <= r.MaxY
+}
+
+// Clamp returns the point of the rectangle nearest to a point in the
+// plane, which is the point itself if the rectangle contains it, or an
+// empty point if the rectangle is empty.
+func (r Rect) Clamp(p Point) Point {
+	if r.IsEmpty() {
+		return EmptyPoint()
+	}
+	return Point{X: max(r.MinX, min(r.MaxX, p.X)), Y: max(r.MinY, min(r.MaxY, p.Y))}
+}
+
+// Intersects reports whether the rectangle and another have at least
+// one point in common, including when they only touch.
+func (r Rect) Intersects(s Rect) bool {
+	return r.MinX <= s.MaxX && s.MinX <= r.MaxX && r.MinY <= s.MaxY && s.MinY <= r.MaxY &&
+		!r.IsEmpty() && !s.IsEmpty()
+}
+
+// Union returns the smallest rectangle containing both the rectangle
+// and another.
+func (r Rect) Union(s Rect) Rect {
+	switch {
+	case r.IsEmpty():
+		return s
+	case s.IsEmpty():
+		return r
+	}
+	return Rect{min(r.MinX, s.MinX), min(r.MinY, s.MinY), max(r.MaxX, s.MaxX), max(r.MaxY, s.MaxY)}
+}
+
+// Intersection returns the rectangle of the points common to the
+// rectangle and another, which is empty if they do not intersect.
+func (r Rect) Intersection(s Rect) Rect {
+	t := Rect{max(r.MinX, s.MinX), max(r.MinY, s.MinY), min(r.MaxX, s.MaxX), min(r.MaxY, s.MaxY)}
+	if t.IsEmpty() {
+		return EmptyRect()
+	}
+	return t
+}
+
+// Extend returns the smallest rectangle containing both the rectangle
+// and a point. An empty point leaves the rectangle unchanged.
+func (r Rect) Extend(p Point) Rect {
+	if p.IsEmpty() {
+		return r
+	}
+	return r.Union(Rect{p.X, p.Y, p.X, p.Y})
+}
+
+// Expand returns the rectangle grown by the distance d on every side,
+// which contains every point within d of the rectangle. A negative
+// distance shrinks the rectangle instead, and may leave it empty.
+func (r Rect) Expand(d float64) Rect {
+	if r.IsEmpty() {
+		return r
+	}
+	s := Rect{r.MinX - d, r.MinY - d, r.MaxX + d, r.MaxY + d}
+	if s.IsEmpty() {
+		return EmptyRect()
+	}
+	return s
+}
+
+// Polygon returns the rectangle as a polygon, with its exterior ring
+// running counterclockwise from the lower left-hand corner, or nil if
+// the rectangle is empty.
+func (r Rect) Polygon() Polygon {
+	if r.IsEmpty() {
+		return nil
+	}
+	return Polygon{{
+		{X: r.MinX, Y: r.MinY}, {X: r.MaxX, Y: r.MinY}, {X: r.MaxX, Y: r.MaxY},
+		{X: r.MinX, Y: r.MaxY}, {X: r.MinX, Y: r.MinY},
+	}}
+}
+
+// Bounds returns the smallest rectangle containing every point of a
+// geometry, which is empty if the geometry is.
+func Bounds(g Geometry) Rect {
+	r := EmptyRect()
+	for p := range Points(g) {
+		r = r.Extend(p)
+	}
+	return r
+}
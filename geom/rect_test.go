@@ -0,0 +1,181 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRect(t *testing.T) {
+	r := Rect{0, 0, 4, 2}
+	tests := []struct {
+		r             Rect
+		empty         bool
+		width, height float64
+		center        Point
+	}{
+		{r, false, 4, 2, Point{X: 2, Y: 1}},
+		{Rect{1, 1, 1, 1}, false, 0, 0, Point{X: 1, Y: 1}},
+		{Rect{1, 0, 0, 1}, true, 0, 0, EmptyPoint()},
+		{Rect{0, 1, 1, 0}, true, 0, 0, EmptyPoint()},
+		{EmptyRect(), true, 0, 0, EmptyPoint()},
+		{Rect{math.NaN(), 0, 1, 1}, true, 0, 0, EmptyPoint()},
+	}
+	for _, tt := range tests {
+		if e := tt.r.IsEmpty(); e != tt.empty {
+			t.Errorf("%v.IsEmpty() = %v, want %v", tt.r, e, tt.empty)
+		}
+		if w, h := tt.r.Width(), tt.r.Height(); w != tt.width || h != tt.height || tt.r.Area() != w*h {
+			t.Errorf("%v Width, Height, Area = %v, %v, %v, want %v, %v", tt.r, w, h, tt.r.Area(), tt.width, tt.height)
+		}
+		if c := tt.r.Center(); c.IsEmpty() != tt.center.IsEmpty() || !c.IsEmpty() && !c.Equal(tt.center) {
+			t.Errorf("%v.Center() = %v, want %v", tt.r, c, tt.center)
+		}
+	}
+}
+
+func TestRectContains(t *testing.T) {
+	r := Rect{0, 0, 4, 2}
+	points := []struct {
+		p    Point
+		want bool
+	}{
+		{Point{X: 1, Y: 1}, true},
+		{Point{X: 0, Y: 0}, true},
+		{Point{X: 4, Y: 2}, true},
+		{Point{X: 4.1, Y: 1}, false},
+		{Point{X: 1, Y: -0.1}, false},
+		{EmptyPoint(), false},
+	}
+	for _, tt := range points {
+		if c := r.Contains(tt.p); c != tt.want {
+			t.Errorf("%v.Contains(%v) = %v, want %v", r, tt.p, c, tt.want)
+		}
+	}
+	rects := []struct {
+		s                    Rect
+		contains, intersects bool
+	}{
+		{Rect{1, 0.5, 3, 1.5}, true, true},
+		{r, true, true},
+		{Rect{3, 1, 5, 3}, false, true},
+		{Rect{4, 2, 5, 3}, false, true},
+		{Rect{5, 0, 6, 1}, false, false},
+		{Rect{-1, -1, 5, 3}, false, true},
+		{EmptyRect(), true, false},
+	}
+	for _, tt := range rects {
+		if c := r.ContainsRect(tt.s); c != tt.contains {
+			t.Errorf("%v.ContainsRect(%v) = %v, want %v", r, tt.s, c, tt.contains)
+		}
+		if i := r.Intersects(tt.s); i != tt.intersects || tt.s.Intersects(r) != i {
+			t.Errorf("%v.Intersects(%v) = %v, want %v", r, tt.s, i, tt.intersects)
+		}
+	}
+	if EmptyRect().ContainsRect(r) || EmptyRect().Intersects(EmptyRect()) {
+		t.Errorf("an empty Rect contains or intersects")
+	}
+}
+
+func TestRectClamp(t *testing.T) {
+	r := Rect{0, 0, 4, 2}
+	tests := []struct {
+		p, want Point
+	}{
+		{Point{X: 1, Y: 1}, Point{X: 1, Y: 1}},
+		{Point{X: -3, Y: 1}, Point{X: 0, Y: 1}},
+		{Point{X: 5, Y: 7}, Point{X: 4, Y: 2}},
+		{Point{X: 2, Y: -1}, Point{X: 2, Y: 0}},
+		{Point{X: 4, Y: 2}, Point{X: 4, Y: 2}},
+	}
+	for _, tt := range tests {
+		if c := r.Clamp(tt.p); c != tt.want {
+			t.Errorf("%v.Clamp(%v) = %v, want %v", r, tt.p, c, tt.want)
+		}
+	}
+	if c := EmptyRect().Clamp(Point{X: 1, Y: 1}); !c.IsEmpty() {
+		t.Errorf("EmptyRect().Clamp = %v, want an empty point", c)
+	}
+}
+
+func TestRectUnionIntersection(t *testing.T) {
+	tests := []struct {
+		r, s, union, intersection Rect
+	}{
+		{Rect{0, 0, 2, 2}, Rect{1, 1, 3, 3}, Rect{0, 0, 3, 3}, Rect{1, 1, 2, 2}},
+		{Rect{0, 0, 2, 2}, Rect{2, 0, 3, 1}, Rect{0, 0, 3, 2}, Rect{2, 0, 2, 1}},
+		{Rect{0, 0, 1, 1}, Rect{2, 2, 3, 3}, Rect{0, 0, 3, 3}, EmptyRect()},
+		{Rect{0, 0, 1, 1}, EmptyRect(), Rect{0, 0, 1, 1}, EmptyRect()},
+		{EmptyRect(), Rect{0, 0, 1, 1}, Rect{0, 0, 1, 1}, EmptyRect()},
+	}
+	for _, tt := range tests {
+		if u := tt.r.Union(tt.s); u != tt.union {
+			t.Errorf("%v.Union(%v) = %v, want %v", tt.r, tt.s, u, tt.union)
+		}
+		if i := tt.r.Intersection(tt.s); i != tt.intersection {
+			t.Errorf("%v.Intersection(%v) = %v, want %v", tt.r, tt.s, i, tt.intersection)
+		}
+	}
+}
+
+func TestRectExtendExpand(t *testing.T) {
+	r := RectOf(Point{X: 1, Y: 5}, Point{X: -2, Y: 3}, EmptyPoint(), Point{X: 0, Y: 7})
+	if r != (Rect{-2, 3, 1, 7}) {
+		t.Errorf("RectOf() = %v, want {-2 3 1 7}", r)
+	}
+	if e := RectOf(); e != EmptyRect() {
+		t.Errorf("RectOf() = %v, want empty", e)
+	}
+	if e := r.Extend(EmptyPoint()); e != r {
+		t.Errorf("Extend(empty) = %v, want %v", e, r)
+	}
+	tests := []struct {
+		r    Rect
+		d    float64
+		want Rect
+	}{
+		{Rect{0, 0, 2, 4}, 1, Rect{-1, -1, 3, 5}},
+		{Rect{0, 0, 2, 4}, -0.5, Rect{0.5, 0.5, 1.5, 3.5}},
+		{Rect{0, 0, 2, 4}, -1, Rect{1, 1, 1, 3}},
+		{Rect{0, 0, 2, 4}, -1.5, EmptyRect()},
+		{EmptyRect(), 1, EmptyRect()},
+	}
+	for _, tt := range tests {
+		if e := tt.r.Expand(tt.d); e != tt.want {
+			t.Errorf("%v.Expand(%v) = %v, want %v", tt.r, tt.d, e, tt.want)
+		}
+	}
+}
+
+func TestRectPolygon(t *testing.T) {
+	p := Rect{0, 0, 1, 1}.Polygon()
+	if len(p) != 1 || len(p[0]) != 5 || !p[0].IsClosed() {
+		t.Fatalf("Polygon() = %v", p)
+	}
+	for i, want := range square[0] {
+		if !p[0][i].Equal(want) {
+			t.Errorf("Polygon() = %v, want %v", p, square)
+			break
+		}
+	}
+	if p := EmptyRect().Polygon(); p != nil {
+		t.Errorf("EmptyRect().Polygon() = %v, want nil", p)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		g    Geometry
+		want Rect
+	}{
+		{Point{X: 1, Y: 2}, Rect{1, 2, 1, 2}},
+		{EmptyPoint(), EmptyRect()},
+		{square, Rect{0, 0, 1, 1}},
+		{GeometryCollection{Point{X: -5, Y: 3}, LineString{{X: 2, Y: -1}, {X: 4, Y: 0}}}, Rect{-5, -1, 4, 3}},
+		{GeometryCollection{}, EmptyRect()},
+	}
+	for _, tt := range tests {
+		if b := Bounds(tt.g); b != tt.want {
+			t.Errorf("Bounds(%v) = %v, want %v", tt.g, b, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package geom
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Segment is the straight line segment from A to B.
+type Segment struct {
+	A, B Point
+}
+
+// Length returns the length of the segment.
+func (s Segment) Length() float64 {
+	return math.Hypot(s.B.X-s.A.X, s.B.Y-s.A.Y)
+}
+
+// Bounds returns the smallest rectangle containing the segment.
+func (s Segment) Bounds() Rect {
+	return RectOf(s.A, s.B)
+}
+
+// ClosestPoint returns the point of the segment closest to p.
+func (s Segment) ClosestPoint(p Point) Point {
+	dx, dy := s.B.X-s.A.X, s.B.Y-s.A.Y
+	d := dx*dx + dy*dy
+	if d == 0 {
+		return s.A
+	}
+	switch t := ((p.X-s.A.X)*dx + (p.Y-s.A.Y)*dy) / d; {
+	case t <= 0:
+		return s.A
+	case t >= 1:
+		return s.B
+	default:
+		return Point{X: s.A.X + t*dx, Y: s.A.Y + t*dy}
+	}
+}
+
+// Distance returns the distance from p to the closest point of the
+// segment.
+func (s Segment) Distance(p Point) float64 {
+	q := s.ClosestPoint(p)
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+// IntersectionKind classifies the intersection of two segments.
+type IntersectionKind int
+
+// The kinds of intersection.
+const (
+	// NoIntersection is that of segments with no point in common.
+	NoIntersection IntersectionKind = iota
+	// ProperIntersection is that of segments which cross at a single
+	// point interior to both.
+	ProperIntersection
+	// EndpointIntersection is that of segments which meet at a single
+	// point which is an endpoint of one or both of them.
+	EndpointIntersection
+	// CollinearIntersection is that of collinear segments which
+	// overlap along a segment of positive length.
+	CollinearIntersection
+)
+
+// String returns the name of the kind, such as "ProperIntersection".
+func (k IntersectionKind) String() string {
+	switch k {
+	case NoIntersection:
+		return "NoIntersection"
+	case ProperIntersection:
+		return "ProperIntersection"
+	case EndpointIntersection:
+		return "EndpointIntersection"
+	case CollinearIntersection:
+		return "CollinearIntersection"
+	}
+	return "IntersectionKind(?)"
+}
+
+// SegmentIntersection is the intersection of two segments.
+type SegmentIntersection struct {
+	Kind IntersectionKind
+	// P is the point of intersection, or the start of the overlap of
+	// collinear segments, in the direction of the first segment, and Q
+	// is the end of the overlap. Q is P unless the kind is
+	// CollinearIntersection, and both are empty if the kind is
+	// NoIntersection.
+	P, Q Point
+}
+
+// Intersection returns the intersection of the segment and another.
+//
+// The kind of intersection is decided with exact arithmetic, so that
+// segments which touch are never taken to cross or to miss each other.
+// The point at which segments cross properly is rounded, but always to
+// a point within the bounds of both segments. The intersection points of
+// the other kinds are endpoints of the segments, and are exact.
+func (s Segment) Intersection(t Segment) SegmentIntersection {
+	a, b, c, d := s.A, s.B, t.A, t.B
+	if !s.Bounds().Intersects(t.Bounds()) {
+		return noIntersection()
+	}
+	o1, o2 := orient(a, b, c), orient(a, b, d)
+	o3, o4 := orient(c, d, a), orient(c, d, b)
+	switch {
+	case o1 == 0 && o2 == 0 && o3 == 0 && o4 == 0:
+		return collinearIntersection(s, t)
+	case o1*o2 > 0 || o3*o4 > 0:
+		return noIntersection()
+	case o1 == 0:
+		return pointIntersection(EndpointIntersection, c)
+	case o2 == 0:
+		return pointIntersection(EndpointIntersection, d)
+	case o3 == 0:
+		return pointIntersection(EndpointIntersection, a)
+	case o4 == 0:
+		return pointIntersection(EndpointIntersection, b)
+	}
+	return pointIntersection(ProperIntersection, crossing(s, t))
+}
+
+// noIntersection returns the SegmentIntersection of disjoint segments.
+func noIntersection() SegmentIntersection {
+	return SegmentIntersection{NoIntersection, EmptyPoint(), EmptyPoint()}
+}
+
+// pointIntersection returns the SegmentIntersection of segments
+// meeting at a single point.
+func pointIntersection(k IntersectionKind, p Point) SegmentIntersection {
+	return SegmentIntersection{k, p, p}
+}
+
+// crossing returns the point at which two segments which cross
+// properly meet, clamped to their bounds.
+func crossing(s, t Segment) Point {
+	rx, ry := s.B.X-s.A.X, s.B.Y-s.A.Y
+	qx, qy := t.B.X-t.A.X, t.B.Y-t.A.Y
+	u := ((t.A.X-s.A.X)*qy - (t.A.Y-s.A.Y)*qx) / (rx*qy - ry*qx)
+	p := Point{X: s.A.X + u*rx, Y: s.A.Y + u*ry}
+	r := s.Bounds().Intersection(t.Bounds())
+	p.X = max(r.MinX, min(r.MaxX, p.X))
+	p.Y = max(r.MinY, min(r.MaxY, p.Y))
+	return p
+}
+
+// collinearIntersection returns the intersection of two collinear
+// segments, comparing their endpoints along the axis in which they
+// extend further.
+func collinearIntersection(s, t Segment) SegmentIntersection {
+	key := func(p Point) float64 { return p.X }
+	if abs(s.B.X-s.A.X)+abs(t.B.X-t.A.X) < abs(s.B.Y-s.A.Y)+abs(t.B.Y-t.A.Y) {
+		key = func(p Point) float64 { return p.Y }
+	}
+	p0, p1 := s.A, s.B
+	reversed := key(p0) > key(p1)
+	if reversed {
+		p0, p1 = p1, p0
+	}
+	q0, q1 := t.A, t.B
+	if key(q0) > key(q1) {
+		q0, q1 = q1, q0
+	}
+	start, end := p0, p1
+	if key(q0) > key(p0) {
+		start = q0
+	}
+	if key(q1) < key(p1) {
+		end = q1
+	}
+	switch {
+	case key(start) > key(end):
+		return noIntersection()
+	case key(start) == key(end):
+		return pointIntersection(EndpointIntersection, start)
+	case reversed:
+		start, end = end, start
+	}
+	return SegmentIntersection{CollinearIntersection, start, end}
+}
+
+// LineIntersection is an intersection of a segment of one line string
+// with a segment of another.
+type LineIntersection struct {
+	SegmentIntersection
+	// I and J are the indexes of the segments which intersect: the
+	// segment from point I to point I+1 of the first line string, and
+	// that from point J to point J+1 of the second.
+	I, J int
+}
+
+// LineIntersections returns the intersections of the segments of one
+// line string with those of another, ordered by I and then by J. Where
+// the line strings meet at a vertex, the intersection is reported for
+// each pair of segments which meet there, so a line string which
+// crosses another at one of its vertices gives two intersections at the
+// same point, one for each of the segments either side of the vertex.
+//
+// The segments are found with a sweep along X, which takes time
+// proportional to n log n for line strings with n points in all, plus
+// the number of pairs of segments whose bounds overlap.
+func LineIntersections(a, b LineString) []LineIntersection {
+	sa := segments(a)
+	segs := slices.Concat(sa, segments(b))
+	n := len(sa)
+	var result []LineIntersection
+	overlappingPairs(segs, func(i, j int) {
+		if i >= n || j < n {
+			return // Both segments are of the same line string
+		}
+		if si := segs[i].Intersection(segs[j]); si.Kind != NoIntersection {
+			result = append(result, LineIntersection{si, i, j - n})
+		}
+	})
+	slices.SortFunc(result, func(x, y LineIntersection) int {
+		return cmp.Or(cmp.Compare(x.I, y.I), cmp.Compare(x.J, y.J))
+	})
+	return result
+}
+
+// segments returns the segments between consecutive points.
+func segments(ps []Point) []Segment {
+	if len(ps) < 2 {
+		return nil
+	}
+	segs := make([]Segment, len(ps)-1)
+	for i := range segs {
+		segs[i] = Segment{ps[i], ps[i+1]}
+	}
+	return segs
+}
+
+// overlappingPairs calls fn for each pair of indexes i < j of segments
+// whose bounds intersect, found with a sweep along X.
+func overlappingPairs(segs []Segment, fn func(i, j int)) {
+	order := make([]int, len(segs))
+	bounds := make([]Rect, len(segs))
+	for i, s := range segs {
+		order[i], bounds[i] = i, s.Bounds()
+	}
+	slices.SortFunc(order, func(i, j int) int { return cmp.Compare(bounds[i].MinX, bounds[j].MinX) })
+	var active []int
+	for _, i := range order {
+		// Drop the segments which end before this one begins.
+		active = slices.DeleteFunc(active, func(j int) bool { return bounds[j].MaxX < bounds[i].MinX })
+		for _, j := range active {
+			if bounds[i].Intersects(bounds[j]) {
+				fn(min(i, j), max(i, j))
+			}
+		}
+		active = append(active, i)
+	}
+}
@@ -0,0 +1,160 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	s := Segment{Point{X: 0, Y: 0}, Point{X: 3, Y: 4}}
+	if l := s.Length(); l != 5 {
+		t.Errorf("%v.Length() = %v, want 5", s, l)
+	}
+	if b := s.Bounds(); b != (Rect{0, 0, 3, 4}) {
+		t.Errorf("%v.Bounds() = %v, want {0 0 3 4}", s, b)
+	}
+	tests := []struct {
+		s    Segment
+		p    Point
+		want Point
+		d    float64
+	}{
+		{s, Point{X: 4, Y: -3}, Point{X: 0, Y: 0}, 5},
+		{s, Point{X: 6, Y: 8}, Point{X: 3, Y: 4}, 5},
+		{Segment{Point{X: 0, Y: 0}, Point{X: 6, Y: 8}}, Point{X: -1, Y: 7}, Point{X: 3, Y: 4}, 5},
+		{Segment{Point{X: 0, Y: 0}, Point{X: 4, Y: 0}}, Point{X: 1, Y: 2}, Point{X: 1, Y: 0}, 2},
+		{Segment{Point{X: 1, Y: 1}, Point{X: 1, Y: 1}}, Point{X: 4, Y: 5}, Point{X: 1, Y: 1}, 5},
+	}
+	for _, tt := range tests {
+		if c := tt.s.ClosestPoint(tt.p); !c.Equal(tt.want) {
+			t.Errorf("%v.ClosestPoint(%v) = %v, want %v", tt.s, tt.p, c, tt.want)
+		}
+		if d := tt.s.Distance(tt.p); math.Abs(d-tt.d) > 1e-12 {
+			t.Errorf("%v.Distance(%v) = %v, want %v", tt.s, tt.p, d, tt.d)
+		}
+	}
+}
+
+func TestIntersectionKind(t *testing.T) {
+	for k, want := range []string{"NoIntersection", "ProperIntersection", "EndpointIntersection", "CollinearIntersection", "IntersectionKind(?)"} {
+		if s := IntersectionKind(k).String(); s != want {
+			t.Errorf("IntersectionKind(%d).String() = %q, want %q", k, s, want)
+		}
+	}
+}
+
+func TestSegmentIntersection(t *testing.T) {
+	seg := func(ax, ay, bx, by float64) Segment { return Segment{Point{X: ax, Y: ay}, Point{X: bx, Y: by}} }
+	tests := []struct {
+		s, t Segment
+		kind IntersectionKind
+		p, q Point
+	}{
+		{seg(0, 0, 2, 2), seg(0, 2, 2, 0), ProperIntersection, Point{X: 1, Y: 1}, Point{X: 1, Y: 1}},
+		{seg(0, 0, 4, 0), seg(1, -1, 1, 3), ProperIntersection, Point{X: 1, Y: 0}, Point{X: 1, Y: 0}},
+		{seg(0, 0, 2, 2), seg(3, 3, 4, 0), NoIntersection, EmptyPoint(), EmptyPoint()},
+		{seg(0, 0, 2, 0), seg(0, 1, 2, 1), NoIntersection, EmptyPoint(), EmptyPoint()},
+		{seg(0, 0, 2, 2), seg(2, 0, 1.1, 0.9), NoIntersection, EmptyPoint(), EmptyPoint()},
+		// Touching at an endpoint of one or both.
+		{seg(0, 0, 2, 0), seg(1, 0, 1, 5), EndpointIntersection, Point{X: 1, Y: 0}, Point{X: 1, Y: 0}},
+		{seg(1, 0, 1, 5), seg(0, 0, 2, 0), EndpointIntersection, Point{X: 1, Y: 0}, Point{X: 1, Y: 0}},
+		{seg(0, 0, 2, 0), seg(2, 0, 3, 3), EndpointIntersection, Point{X: 2, Y: 0}, Point{X: 2, Y: 0}},
+		{seg(0, 0, 2, 0), seg(3, 3, 2, 0), EndpointIntersection, Point{X: 2, Y: 0}, Point{X: 2, Y: 0}},
+		// Collinear, overlapping or touching end to end.
+		{seg(0, 0, 4, 0), seg(2, 0, 6, 0), CollinearIntersection, Point{X: 2, Y: 0}, Point{X: 4, Y: 0}},
+		{seg(4, 0, 0, 0), seg(2, 0, 6, 0), CollinearIntersection, Point{X: 4, Y: 0}, Point{X: 2, Y: 0}},
+		{seg(0, 0, 4, 0), seg(3, 0, 1, 0), CollinearIntersection, Point{X: 1, Y: 0}, Point{X: 3, Y: 0}},
+		{seg(0, 0, 0, 4), seg(0, 4, 0, 6), EndpointIntersection, Point{X: 0, Y: 4}, Point{X: 0, Y: 4}},
+		{seg(0, 0, 1, 1), seg(2, 2, 3, 3), NoIntersection, EmptyPoint(), EmptyPoint()},
+		{seg(0, 0, 3, 1), seg(6, 2, -3, -1), CollinearIntersection, Point{X: 0, Y: 0}, Point{X: 3, Y: 1}},
+	}
+	for _, tt := range tests {
+		si := tt.s.Intersection(tt.t)
+		if si.Kind != tt.kind || !equalOrEmpty(si.P, tt.p) || !equalOrEmpty(si.Q, tt.q) {
+			t.Errorf("%v.Intersection(%v) = %v, want {%v %v %v}", tt.s, tt.t, si, tt.kind, tt.p, tt.q)
+		}
+		if r := tt.t.Intersection(tt.s); r.Kind != tt.kind {
+			t.Errorf("%v.Intersection(%v) = %v, want %v", tt.t, tt.s, r, tt.kind)
+		}
+	}
+}
+
+// equalOrEmpty reports whether two points are equal or both empty.
+func equalOrEmpty(p, q Point) bool {
+	return p.IsEmpty() && q.IsEmpty() || p.Equal(q)
+}
+
+func TestSegmentIntersectionRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(61))
+	for range 10000 {
+		s := Segment{Point{X: r.Float64(), Y: r.Float64()}, Point{X: r.Float64(), Y: r.Float64()}}
+		u := Segment{Point{X: r.Float64(), Y: r.Float64()}, Point{X: r.Float64(), Y: r.Float64()}}
+		si := s.Intersection(u)
+		switch si.Kind {
+		case ProperIntersection:
+			// The crossing is within the bounds of both, and very near
+			// both lines.
+			b := s.Bounds().Intersection(u.Bounds())
+			if !b.Contains(si.P) || s.Distance(si.P) > 1e-12 || u.Distance(si.P) > 1e-12 {
+				t.Fatalf("%v.Intersection(%v) = %v, off the segments", s, u, si)
+			}
+		case NoIntersection:
+			if orient(s.A, s.B, u.A)*orient(s.A, s.B, u.B) < 0 && orient(u.A, u.B, s.A)*orient(u.A, u.B, s.B) < 0 {
+				t.Fatalf("%v.Intersection(%v) = %v, but they cross", s, u, si)
+			}
+		default:
+			t.Fatalf("%v.Intersection(%v) = %v", s, u, si)
+		}
+	}
+}
+
+func TestLineIntersections(t *testing.T) {
+	route := LineString{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}}
+	fence := LineString{{X: 2, Y: -1}, {X: 2, Y: 1}, {X: 6, Y: 1}, {X: 6, Y: 2}, {X: 4, Y: 2}, {X: 4, Y: 3}}
+	got := LineIntersections(route, fence)
+	want := []LineIntersection{
+		{SegmentIntersection{ProperIntersection, Point{X: 2, Y: 0}, Point{X: 2, Y: 0}}, 0, 0},
+		{SegmentIntersection{ProperIntersection, Point{X: 4, Y: 1}, Point{X: 4, Y: 1}}, 1, 1},
+		{SegmentIntersection{EndpointIntersection, Point{X: 4, Y: 2}, Point{X: 4, Y: 2}}, 1, 3},
+		{SegmentIntersection{CollinearIntersection, Point{X: 4, Y: 2}, Point{X: 4, Y: 3}}, 1, 4},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("LineIntersections() = %v, want %v", got, want)
+	}
+	// Crossing at a vertex is reported for both segments at the vertex.
+	v := LineIntersections(LineString{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}}, LineString{{X: 1, Y: 0}, {X: 1, Y: 2}})
+	if len(v) != 2 || v[0].I != 0 || v[1].I != 1 || !v[0].P.Equal(Point{X: 1, Y: 1}) || !v[1].P.Equal(v[0].P) {
+		t.Errorf("LineIntersections(through a vertex) = %v", v)
+	}
+	if l := LineIntersections(route, LineString{{X: 10, Y: 10}}); l != nil {
+		t.Errorf("LineIntersections(one point) = %v, want nil", l)
+	}
+}
+
+func TestLineIntersectionsRandom(t *testing.T) {
+	// The sweep finds what testing every pair of segments finds.
+	r := rand.New(rand.NewSource(61))
+	walk := func() LineString {
+		l := make(LineString, 50)
+		for i := range l {
+			l[i] = Point{X: r.Float64() * 10, Y: r.Float64() * 10}
+		}
+		return l
+	}
+	for range 20 {
+		a, b := walk(), walk()
+		var want []LineIntersection
+		for i, s := range segments(a) {
+			for j, u := range segments(b) {
+				if si := s.Intersection(u); si.Kind != NoIntersection {
+					want = append(want, LineIntersection{si, i, j})
+				}
+			}
+		}
+		if got := LineIntersections(a, b); !slices.Equal(got, want) {
+			t.Fatalf("LineIntersections() = %d intersections, want %d", len(got), len(want))
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// HausdorffDistance returns the discrete Hausdorff distance between two
+// line strings in the plane, or +Inf if either is empty: the greatest
+// distance from a point of either line to the nearest point of the
+// other. It measures how far apart the lines stray, whatever the order
+// of their points, so that a trace which follows a route closely has a
+// small distance from it even if it doubles back.
+//
+// Only the points of the lines count, not the segments between them, so
+// that lines should be densified with Densify if their points are far
+// apart relative to the distances of interest. It takes time
+// proportional to the product of the numbers of points at worst, though
+// much less for similar lines, as the search for the nearest point stops
+// once it finds one closer than the greatest distance found so far.
+func HausdorffDistance(a, b LineString) float64 {
+	return hausdorff(a, b, planarDist)
+}
+
+// HausdorffDistanceGeodesic returns the discrete Hausdorff distance in
+// meters between two line strings of longitudes and latitudes in degrees
+// on the sphere geodesy.Earth, as for HausdorffDistance.
+func HausdorffDistanceGeodesic(a, b LineString) float64 {
+	return hausdorff(a, b, haversineDist)
+}
+
+// FrechetDistance returns the discrete Fréchet distance between two line
+// strings in the plane, or +Inf if either is empty: the least, over all
+// the ways of walking along both lines from start to end in steps from
+// point to point, never going back, of the greatest distance between the
+// points reached on each line. Unlike HausdorffDistance it takes the
+// direction and order of the lines into account, so that a trace
+// matches a route only if it travels along it the same way.
+//
+// It is computed by the dynamic programming of Eiter and Mannila,
+// "Computing Discrete Fréchet Distance" (1994), in time proportional to
+// the product of the numbers of points and space proportional to the
+// smaller of them.
+func FrechetDistance(a, b LineString) float64 {
+	return frechet(a, b, planarDist)
+}
+
+// FrechetDistanceGeodesic returns the discrete Fréchet distance in
+// meters between two line strings of longitudes and latitudes in degrees
+// on the sphere geodesy.Earth, as for FrechetDistance.
+func FrechetDistanceGeodesic(a, b LineString) float64 {
+	return frechet(a, b, haversineDist)
+}
+
+// planarDist returns the distance between two points in the plane.
+func planarDist(p, q Point) float64 {
+	return math.Hypot(q.X-p.X, q.Y-p.Y)
+}
+
+// haversineDist returns the distance in meters between two points of
+// longitude X and latitude Y on the sphere geodesy.Earth.
+func haversineDist(p, q Point) float64 {
+	return geodesy.Haversine(p.LatLng(), q.LatLng())
+}
+
+// nonEmpty returns the points of a line which are not empty.
+func nonEmpty(l LineString) []Point {
+	ps := make([]Point, 0, len(l))
+	for _, p := range l {
+		if !p.IsEmpty() {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// hausdorff returns the discrete Hausdorff distance between two lines.
+func hausdorff(a, b LineString, dist func(p, q Point) float64) float64 {
+	pa, pb := nonEmpty(a), nonEmpty(b)
+	if len(pa) == 0 || len(pb) == 0 {
+		return math.Inf(1)
+	}
+	h := 0.0
+	for _, d := range [2][2][]Point{{pa, pb}, {pb, pa}} {
+		for _, p := range d[0] {
+			nearest := math.Inf(1)
+			for _, q := range d[1] {
+				if nearest = min(nearest, dist(p, q)); nearest <= h {
+					break // p cannot raise the distance
+				}
+			}
+			h = max(h, nearest)
+		}
+	}
+	return h
+}
+
+// frechet returns the discrete Fréchet distance between two lines.
+func frechet(a, b LineString, dist func(p, q Point) float64) float64 {
+	pa, pb := nonEmpty(a), nonEmpty(b)
+	if len(pa) == 0 || len(pb) == 0 {
+		return math.Inf(1)
+	}
+	if len(pb) > len(pa) {
+		pa, pb = pb, pa
+	}
+	// prev and cur hold the distances of the walks reaching each point of
+	// pb, with the previous and current point of pa.
+	prev, cur := make([]float64, len(pb)), make([]float64, len(pb))
+	for i, p := range pa {
+		for j, q := range pb {
+			d := dist(p, q)
+			switch {
+			case i == 0 && j == 0:
+				cur[j] = d
+			case i == 0:
+				cur[j] = max(cur[j-1], d)
+			case j == 0:
+				cur[j] = max(prev[j], d)
+			default:
+				cur[j] = max(min(prev[j], prev[j-1], cur[j-1]), d)
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(pb)-1]
+}
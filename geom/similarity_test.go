@@ -0,0 +1,139 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestHausdorffDistance(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		a, b LineString
+		want float64
+	}{
+		// The examples of the JTS DiscreteHausdorffDistance tests.
+		{LineString(ring(0, 0, 100, 0, 10, 100, 10, 100)), LineString(ring(0, 100, 0, 10, 80, 10)), 22.360679774997898},
+		{LineString(ring(130, 0, 0, 0, 0, 150)), LineString(ring(10, 10, 10, 150, 130, 10)), 14.142135623730951},
+		{LineString(ring(0, 0, 1, 0)), LineString(ring(1, 0, 0, 0)), 0},
+		{LineString(ring(0, 0, 10, 0)), LineString(ring(0, 1, 10, 1, 10, 3)), 3},
+		{LineString(ring(0, 0)), LineString(ring(3, 4)), 5},
+		{LineString{EmptyPoint(), {X: 0, Y: 0}}, LineString(ring(3, 4)), 5},
+		{LineString{}, LineString(ring(3, 4)), inf},
+		{LineString{EmptyPoint()}, LineString(ring(3, 4)), inf},
+	}
+	for _, tt := range tests {
+		if d := HausdorffDistance(tt.a, tt.b); d != tt.want {
+			t.Errorf("HausdorffDistance(%v, %v) = %v, want %v", tt.a, tt.b, d, tt.want)
+		}
+		if d := HausdorffDistance(tt.b, tt.a); d != tt.want {
+			t.Errorf("HausdorffDistance(%v, %v) = %v, want %v", tt.b, tt.a, d, tt.want)
+		}
+	}
+}
+
+func TestFrechetDistance(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		a, b LineString
+		want float64
+	}{
+		// The example of the JTS DiscreteFrechetDistance tests.
+		{LineString(ring(0, 0, 100, 0)), LineString(ring(0, 0, 50, 50, 100, 0)), 70.71067811865476},
+		{LineString(ring(0, 0, 100, 0, 10, 100, 10, 100)), LineString(ring(0, 100, 0, 10, 80, 10)), 114.0175425099138},
+		// Reversing a line matches its start with the end of the other.
+		{LineString(ring(0, 0, 10, 0)), LineString(ring(10, 0, 0, 0)), 10},
+		{LineString(ring(0, 0, 10, 0)), LineString(ring(0, 1, 5, 1, 10, 1)), math.Hypot(5, 1)},
+		{LineString(ring(0, 0)), LineString(ring(3, 4, 0, 1)), 5},
+		{LineString{}, LineString(ring(3, 4)), inf},
+	}
+	for _, tt := range tests {
+		if d := FrechetDistance(tt.a, tt.b); d != tt.want {
+			t.Errorf("FrechetDistance(%v, %v) = %v, want %v", tt.a, tt.b, d, tt.want)
+		}
+		if d := FrechetDistance(tt.b, tt.a); d != tt.want {
+			t.Errorf("FrechetDistance(%v, %v) = %v, want %v", tt.b, tt.a, d, tt.want)
+		}
+	}
+	// Hausdorff ignores the direction of the lines.
+	if d := HausdorffDistance(LineString(ring(0, 0, 10, 0)), LineString(ring(10, 0, 0, 0))); d != 0 {
+		t.Errorf("HausdorffDistance(reversed) = %v, want 0", d)
+	}
+}
+
+// frechetRecursive returns the discrete Fréchet distance between the
+// first i+1 points of a and j+1 points of b by its recursive definition.
+func frechetRecursive(a, b LineString, i, j int, memo map[[2]int]float64) float64 {
+	if d, ok := memo[[2]int{i, j}]; ok {
+		return d
+	}
+	d := planarDist(a[i], b[j])
+	switch {
+	case i > 0 && j > 0:
+		d = max(d, min(frechetRecursive(a, b, i-1, j, memo), frechetRecursive(a, b, i-1, j-1, memo), frechetRecursive(a, b, i, j-1, memo)))
+	case i > 0:
+		d = max(d, frechetRecursive(a, b, i-1, j, memo))
+	case j > 0:
+		d = max(d, frechetRecursive(a, b, i, j-1, memo))
+	}
+	memo[[2]int{i, j}] = d
+	return d
+}
+
+func TestSimilarityRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(79))
+	line := func() LineString {
+		l := make(LineString, 1+r.Intn(12))
+		for i := range l {
+			l[i] = Point{X: r.NormFloat64() * 10, Y: r.NormFloat64() * 10}
+		}
+		return l
+	}
+	for range 500 {
+		a, b := line(), line()
+		h := 0.0
+		for _, d := range [][2]LineString{{a, b}, {b, a}} {
+			for _, p := range d[0] {
+				nearest := math.Inf(1)
+				for _, q := range d[1] {
+					nearest = min(nearest, planarDist(p, q))
+				}
+				h = max(h, nearest)
+			}
+		}
+		if d := HausdorffDistance(a, b); d != h {
+			t.Fatalf("HausdorffDistance(%v, %v) = %v, want %v", a, b, d, h)
+		}
+		f := frechetRecursive(a, b, len(a)-1, len(b)-1, map[[2]int]float64{})
+		if d := FrechetDistance(a, b); d != f {
+			t.Fatalf("FrechetDistance(%v, %v) = %v, want %v", a, b, d, f)
+		}
+		if f < h {
+			t.Fatalf("FrechetDistance(%v, %v) = %v, less than HausdorffDistance %v", a, b, f, h)
+		}
+		rb := slices.Clone(b)
+		slices.Reverse(rb)
+		if d := HausdorffDistance(a, rb); d != h {
+			t.Fatalf("HausdorffDistance(%v, %v) = %v, want %v", a, rb, d, h)
+		}
+	}
+}
+
+func TestSimilarityGeodesic(t *testing.T) {
+	deg := haversineDist(Point{X: 0, Y: 0}, Point{X: 1, Y: 0})
+	a := LineString(ring(179, 0, -179, 0))
+	b := LineString(ring(179, 1, 180, 1, -179, 1))
+	if d := HausdorffDistanceGeodesic(a, b); math.Abs(d-haversineDist(Point{X: 179, Y: 0}, Point{X: 180, Y: 1})) > 1e-6 {
+		t.Errorf("HausdorffDistanceGeodesic(%v, %v) = %v", a, b, d)
+	}
+	if d := FrechetDistanceGeodesic(a, b); math.Abs(d-haversineDist(Point{X: 179, Y: 0}, Point{X: 180, Y: 1})) > 1e-6 {
+		t.Errorf("FrechetDistanceGeodesic(%v, %v) = %v", a, b, d)
+	}
+	if d := FrechetDistanceGeodesic(a, LineString(ring(179, 1, -179, 1))); math.Abs(d-deg) > 1e-6 {
+		t.Errorf("FrechetDistanceGeodesic across the antimeridian = %v, want %v", d, deg)
+	}
+	if d := HausdorffDistanceGeodesic(a, LineString{}); d != math.Inf(1) {
+		t.Errorf("HausdorffDistanceGeodesic(empty) = %v, want +Inf", d)
+	}
+}
@@ -0,0 +1,345 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// DouglasPeucker simplifies geometries by the Ramer-Douglas-Peucker
+// algorithm, which keeps the ends of each line and then, recursively,
+// the point farthest from the segment joining the points kept either
+// side of it, as long as it is farther than the tolerance. Every point
+// of the simplified line is then within the tolerance of the original,
+// and the points kept are points of the original. It takes time
+// proportional to n log n for a line of n points on average, and n*n in
+// the worst case.
+type DouglasPeucker struct {
+	// Tolerance is the greatest distance of a point removed from the
+	// simplified line.
+	Tolerance float64
+	// Meters interprets the coordinates as longitudes and latitudes in
+	// degrees and the tolerance in meters, measuring distances on the
+	// sphere geodesy.Earth in an equirectangular approximation centered
+	// on each segment, which is accurate to a fraction of a percent
+	// over segments of up to hundreds of kilometers. Otherwise the
+	// tolerance is in the units of the coordinates.
+	Meters bool
+	// PreserveTopology keeps points which would otherwise be removed
+	// where it is needed to prevent the simplification of the lines and
+	// rings of a geometry from making them cross each other or
+	// themselves, and keeps at least four points of every ring, so that
+	// a valid polygon remains valid. Otherwise rings simplified to fewer
+	// than four points are dropped, together with their polygons if
+	// they are exterior rings.
+	PreserveTopology bool
+}
+
+// Simplify returns a copy of a geometry with its lines and rings
+// simplified. Points and multipoints are copied unchanged.
+//
+// The rings of a polygon, and of a multipolygon, are simplified
+// together, as are the lines of a multiline string, in order that
+// PreserveTopology may keep them from crossing. The start of a ring is
+// kept, and the point of the ring farthest from it.
+func (s DouglasPeucker) Simplify(g Geometry) Geometry {
+	return simplify(g, s.PreserveTopology, distFunc(s.Meters), func(ps []Point, keep []bool) {
+		douglasPeucker(ps, keep, s.Tolerance, distFunc(s.Meters))
+	})
+}
+
+// Simplify returns a copy of a geometry simplified by the
+// Douglas-Peucker algorithm with a tolerance in the units of its
+// coordinates, which is DouglasPeucker{Tolerance: tolerance}.Simplify(g).
+func Simplify(g Geometry, tolerance float64) Geometry {
+	return DouglasPeucker{Tolerance: tolerance}.Simplify(g)
+}
+
+// douglasPeucker marks the points of a line to keep besides its ends,
+// which the caller marks.
+func douglasPeucker(ps []Point, keep []bool, tolerance float64, dist func(p, a, b Point) float64) {
+	type span struct{ i, j int }
+	stack := []span{{0, len(ps) - 1}}
+	for len(stack) > 0 {
+		sp := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		m, dm := -1, tolerance
+		for k := sp.i + 1; k < sp.j; k++ {
+			if d := dist(ps[k], ps[sp.i], ps[sp.j]); d > dm {
+				m, dm = k, d
+			}
+		}
+		if m >= 0 {
+			keep[m] = true
+			stack = append(stack, span{sp.i, m}, span{m, sp.j})
+		}
+	}
+}
+
+// distFunc returns the function measuring the distance from a point to a
+// segment, in meters or in the units of the coordinates.
+func distFunc(meters bool) func(p, a, b Point) float64 {
+	if meters {
+		return metersDist
+	}
+	return func(p, a, b Point) float64 { return Segment{a, b}.Distance(p) }
+}
+
+// metersDist returns the distance in meters from a point to a segment,
+// with the coordinates as longitudes and latitudes, in an
+// equirectangular approximation centered on the segment.
+func metersDist(p, a, b Point) float64 {
+	k := geodesy.MeanRadius * degToRad
+	kx := k * math.Cos((a.Y+b.Y)/2*degToRad)
+	local := func(q Point) Point {
+		return Point{X: wrap180(q.X-a.X) * kx, Y: (q.Y - a.Y) * k}
+	}
+	return Segment{local(a), local(b)}.Distance(local(p))
+}
+
+// simplify returns a copy of a geometry whose lines and rings are
+// simplified by mark, which marks the points of a line to keep other
+// than its ends.
+func simplify(g Geometry, preserve bool, dist func(p, a, b Point) float64, mark func(ps []Point, keep []bool)) Geometry {
+	switch g := g.(type) {
+	case LineString:
+		ls := simplifyLines([][]Point{g}, false, preserve, dist, mark)
+		return LineString(ls[0])
+	case MultiLineString:
+		lines := make([][]Point, len(g))
+		for i, l := range g {
+			lines[i] = l
+		}
+		m := make(MultiLineString, len(g))
+		for i, l := range simplifyLines(lines, false, preserve, dist, mark) {
+			m[i] = l
+		}
+		return m
+	case Polygon:
+		if m := simplifyPolygons(MultiPolygon{g}, preserve, dist, mark); len(m) > 0 {
+			return m[0]
+		}
+		return Polygon{}
+	case MultiPolygon:
+		return simplifyPolygons(g, preserve, dist, mark)
+	case GeometryCollection:
+		c := make(GeometryCollection, len(g))
+		for i, m := range g {
+			c[i] = simplify(m, preserve, dist, mark)
+		}
+		return c
+	}
+	return Clone(g)
+}
+
+// simplifyPolygons simplifies the rings of polygons together, dropping
+// those left with fewer than four points.
+func simplifyPolygons(m MultiPolygon, preserve bool, dist func(p, a, b Point) float64, mark func(ps []Point, keep []bool)) MultiPolygon {
+	var rings [][]Point
+	for _, p := range m {
+		for _, r := range p {
+			rings = append(rings, r)
+		}
+	}
+	simple := simplifyLines(rings, true, preserve, dist, mark)
+	var out MultiPolygon
+	for _, p := range m {
+		rs := simple[:len(p)]
+		simple = simple[len(p):]
+		switch {
+		case p.IsEmpty():
+			out = append(out, p.Clone())
+		case len(rs[0]) >= 4:
+			q := Polygon{rs[0]}
+			for _, r := range rs[1:] {
+				if len(r) >= 4 {
+					q = append(q, r)
+				}
+			}
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// simplifyLines returns the simplified lines, which are rings if rings
+// is true. The start of a closed line is kept, and the point farthest
+// from it.
+func simplifyLines(lines [][]Point, rings, preserve bool, dist func(p, a, b Point) float64, mark func(ps []Point, keep []bool)) [][]Point {
+	keeps := make([][]bool, len(lines))
+	for i, ps := range lines {
+		keep := make([]bool, len(ps))
+		keeps[i] = keep
+		if len(ps) < 3 {
+			for k := range keep {
+				keep[k] = true
+			}
+			continue
+		}
+		n := len(ps) - 1
+		keep[0], keep[n] = true, true
+		if !ps[0].Equal(ps[n]) {
+			mark(ps, keep)
+			continue
+		}
+		far, fd := 0, 0.0
+		for k, p := range ps {
+			if d := sqDist(p, ps[0]); d > fd {
+				far, fd = k, d
+			}
+		}
+		keep[far] = true
+		mark(ps[:far+1], keep[:far+1])
+		mark(ps[far:], keep[far:])
+	}
+	if preserve {
+		for i, ps := range lines {
+			for rings && count(keeps[i]) < 4 {
+				if !splitFarthest(ps, keeps[i], dist) {
+					break
+				}
+			}
+		}
+		preserveTopology(lines, keeps, dist)
+	}
+	out := make([][]Point, len(lines))
+	for i, ps := range lines {
+		for k, p := range ps {
+			if keeps[i][k] {
+				out[i] = append(out[i], p)
+			}
+		}
+	}
+	return out
+}
+
+// count returns the number of true values.
+func count(keep []bool) int {
+	n := 0
+	for _, k := range keep {
+		if k {
+			n++
+		}
+	}
+	return n
+}
+
+// splitFarthest keeps the point of a line which is farthest from the
+// segment joining the kept points either side of it, returning false if
+// every point is kept.
+func splitFarthest(ps []Point, keep []bool, dist func(p, a, b Point) float64) bool {
+	m, dm := -1, -1.0
+	i := 0
+	for j := 1; j < len(ps); j++ {
+		if !keep[j] {
+			continue
+		}
+		if k, d := farthest(ps, i, j, dist); k >= 0 && d > dm {
+			m, dm = k, d
+		}
+		i = j
+	}
+	if m < 0 {
+		return false
+	}
+	keep[m] = true
+	return true
+}
+
+// farthest returns the index of the point strictly between the points i
+// and j of a line which is farthest from the segment joining them, and
+// its distance, or -1 if there is none.
+func farthest(ps []Point, i, j int, dist func(p, a, b Point) float64) (int, float64) {
+	m, dm := -1, -1.0
+	for k := i + 1; k < j; k++ {
+		if d := dist(ps[k], ps[i], ps[j]); d > dm {
+			m, dm = k, d
+		}
+	}
+	return m, dm
+}
+
+// preserveTopology keeps more points of the lines until no segment
+// between kept points which replaces several of the original meets
+// another segment except at an end of both, which is a point the lines
+// share, and no kept point lies in the area between such a segment and
+// the points it replaces, which it would leave on the wrong side of the
+// line, as a hole swept out of its shell. Segments of the original
+// which meet are left as they are.
+func preserveTopology(lines [][]Point, keeps [][]bool, dist func(p, a, b Point) float64) {
+	type span struct{ line, i, j int }
+	for {
+		var segs []Segment
+		var spans []span
+		for l, ps := range lines {
+			i := -1
+			for j := range ps {
+				if !keeps[l][j] {
+					continue
+				}
+				if i >= 0 {
+					segs = append(segs, Segment{ps[i], ps[j]})
+					spans = append(spans, span{l, i, j})
+				}
+				i = j
+			}
+		}
+		split := make([]bool, len(segs))
+		overlappingPairs(segs, func(a, b int) {
+			sa, sb := spans[a], spans[b]
+			if sa.j == sa.i+1 && sb.j == sb.i+1 {
+				return
+			}
+			si := segs[a].Intersection(segs[b])
+			switch {
+			case si.Kind == NoIntersection:
+				return
+			case si.Kind == EndpointIntersection && isEnd(segs[a], si.P) && isEnd(segs[b], si.P):
+				return
+			}
+			split[a] = split[a] || sa.j > sa.i+1
+			split[b] = split[b] || sb.j > sb.i+1
+		})
+		var kept []Point
+		for l, ps := range lines {
+			for k, p := range ps {
+				if keeps[l][k] {
+					kept = append(kept, p)
+				}
+			}
+		}
+		for s, sp := range spans {
+			if !split[s] && sp.j > sp.i+1 {
+				split[s] = sweeps(lines[sp.line][sp.i:sp.j+1], kept)
+			}
+		}
+		done := true
+		for s, sp := range spans {
+			if split[s] {
+				k, _ := farthest(lines[sp.line], sp.i, sp.j, dist)
+				keeps[sp.line][k] = true
+				done = false
+			}
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// sweeps reports whether any of the points lies inside the area
+// bounded by a line and the segment joining its ends.
+func sweeps(ps []Point, points []Point) bool {
+	b := RectOf(ps...)
+	for _, q := range points {
+		if b.Contains(q) && locateRing(ps, q) == Interior {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnd reports whether p is an end of a segment.
+func isEnd(s Segment, p Point) bool {
+	return p.Equal(s.A) || p.Equal(s.B)
+}
@@ -0,0 +1,133 @@
+package geom
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	zigzag := LineString{{X: 0, Y: 0}, {X: 1, Y: 0.5}, {X: 2, Y: 0}, {X: 3, Y: 3}, {X: 4, Y: 0}}
+	// A square with points nearly on its edges.
+	bumpy := Polygon{ring(0, 0, 5, 0.1, 10, 0, 10.1, 5, 10, 10, 5, 9.9, 0, 10, 0, 0)}
+	tests := []struct {
+		g         Geometry
+		tolerance float64
+		want      Geometry
+	}{
+		{zigzag, 1, LineString{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 3}, {X: 4, Y: 0}}},
+		{zigzag, 0.4, zigzag},
+		{zigzag, 5, LineString{{X: 0, Y: 0}, {X: 4, Y: 0}}},
+		{bumpy, 0.5, Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)}},
+		{bumpy, 0.05, bumpy},
+		// A ring reduced to three points is dropped, with its polygon.
+		{bumpy, 20, Polygon{}},
+		{MultiPolygon{bumpy, bumpy}, 20, MultiPolygon(nil)},
+		{MultiLineString{zigzag, {{X: 1, Y: 1}}}, 5, MultiLineString{{{X: 0, Y: 0}, {X: 4, Y: 0}}, {{X: 1, Y: 1}}}},
+		{LineString{{X: 0, Y: 0}, {X: 1, Y: 1}}, 5, LineString{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+		{MultiPoint{{X: 0, Y: 0}, {X: 0, Y: 0.1}}, 5, MultiPoint{{X: 0, Y: 0}, {X: 0, Y: 0.1}}},
+		{GeometryCollection{zigzag}, 5, GeometryCollection{LineString{{X: 0, Y: 0}, {X: 4, Y: 0}}}},
+	}
+	for _, tt := range tests {
+		if s := Simplify(tt.g, tt.tolerance); !reflect.DeepEqual(s, tt.want) {
+			t.Errorf("Simplify(%v, %v) = %v, want %v", tt.g, tt.tolerance, s, tt.want)
+		}
+	}
+	// Ring with four points kept by PreserveTopology.
+	s := DouglasPeucker{Tolerance: 20, PreserveTopology: true}.Simplify(bumpy).(Polygon)
+	if len(s) != 1 || len(s[0]) != 4 || !s[0].IsClosed() {
+		t.Errorf("Simplify(%v, 20, PreserveTopology) = %v, want a ring of four points", bumpy, s)
+	}
+	s[0][0].X = 99
+	if bumpy[0][0].X != 0 {
+		t.Errorf("Simplify() shares storage with its argument")
+	}
+}
+
+func TestSimplifyMeters(t *testing.T) {
+	// The middle point is a second of latitude, 30.9 m, off the line
+	// from its neighbors, at 60 degrees north.
+	sec := 1.0 / 3600
+	l := LineString{{X: 10, Y: 60}, {X: 10.01, Y: 60 + sec}, {X: 10.02, Y: 60}}
+	tests := []struct {
+		tolerance float64
+		points    int
+	}{
+		{30, 3},
+		{31, 2},
+	}
+	for _, tt := range tests {
+		s := DouglasPeucker{Tolerance: tt.tolerance, Meters: true}.Simplify(l).(LineString)
+		if len(s) != tt.points {
+			t.Errorf("Simplify(%v m) = %v, want %d points", tt.tolerance, s, tt.points)
+		}
+	}
+	// The segment crossing the antimeridian is short.
+	l = LineString{{X: 179.99, Y: 0}, {X: -180, Y: sec}, {X: -179.99, Y: 0}}
+	if s := (DouglasPeucker{Tolerance: 31, Meters: true}).Simplify(l).(LineString); len(s) != 2 {
+		t.Errorf("Simplify(across the antimeridian) = %v, want 2 points", s)
+	}
+}
+
+func TestSimplifyRandom(t *testing.T) {
+	// A random walk of 20000 points, each of which is within the
+	// tolerance of the simplified line.
+	r := rand.New(rand.NewSource(66))
+	var l LineString
+	x, y := 0.0, 0.0
+	for range 20000 {
+		x += r.Float64()
+		y += r.NormFloat64()
+		l = append(l, Point{X: x, Y: y})
+	}
+	s := Simplify(l, 5).(LineString)
+	if len(s) >= len(l)/10 || !s[0].Equal(l[0]) || !s[len(s)-1].Equal(l[len(l)-1]) {
+		t.Fatalf("Simplify() = %d points of %d", len(s), len(l))
+	}
+	// The points kept are in order, so each point lies within the
+	// tolerance of the segment of s spanning it.
+	j := 0
+	for _, p := range l {
+		for j+1 < len(s)-1 && p.X > s[j+1].X {
+			j++
+		}
+		if d := (Segment{s[j], s[j+1]}).Distance(p); d > 5 && !p.Equal(s[j+1]) {
+			t.Fatalf("Simplify() leaves %v %v from the line", p, d)
+		}
+	}
+}
+
+func TestSimplifyPreserveTopology(t *testing.T) {
+	// Simplifying away the bump in the shell makes it cross the hole.
+	bump := Polygon{
+		ring(0, 0, 10, 0, 10, 10, 5, 12, 0, 10, 0, 0),
+		ring(2, 7, 2, 10.5, 8, 10.5, 8, 7, 2, 7),
+	}
+	s := Simplify(bump, 2.5)
+	if err := Validate(s); !errors.Is(err, ErrInvalid) || err.(*ValidityError).Reason != SelfIntersection {
+		t.Errorf("Validate(Simplify(%v, 2.5)) = %v, want a self-intersection", bump, err)
+	}
+	s = DouglasPeucker{Tolerance: 2.5, PreserveTopology: true}.Simplify(bump)
+	if !reflect.DeepEqual(s, bump) {
+		t.Errorf("Simplify(%v, 2.5, PreserveTopology) = %v, want it unchanged", bump, s)
+	}
+	// A ragged shell with a hole close to it, which stays inside the
+	// shell even once the shell is reduced to a few points.
+	r := rand.New(rand.NewSource(66))
+	var shell Ring
+	for i := range 200 {
+		a := 2 * math.Pi * float64(i) / 200
+		d := 10 + r.Float64()
+		shell = append(shell, Point{X: d * math.Cos(a), Y: d * math.Sin(a)})
+	}
+	shell = append(shell, shell[0])
+	p := Polygon{shell, ring(-1, 9.5, 1, 9.5, 1, 9.9, -1, 9.9, -1, 9.5)}
+	for _, tol := range []float64{0.2, 0.5, 2, 5, 20} {
+		s := DouglasPeucker{Tolerance: tol, PreserveTopology: true}.Simplify(p).(Polygon)
+		if err := Validate(s); err != nil || len(s) != 2 {
+			t.Errorf("Simplify(%v, PreserveTopology) = %d rings, invalid: %v", tol, len(s), err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package geom
+
+import "math"
+
+// SnapToGrid returns a copy of a geometry with the X and Y of its points
+// rounded to the nearest multiples of cellSize, cleaned of the
+// degeneracies rounding makes. Equal consecutive points of lines and
+// rings are merged into one, and line strings left with fewer than two
+// points are dropped. Spikes of rings, where a ring runs out and back
+// along the same line, are removed, and rings left enclosing no area are
+// dropped, together with their polygons if they are exterior rings. An
+// empty geometry of the same type is returned if nothing is left of a
+// line string or polygon, including one in a GeometryCollection. The Z
+// and M of the points are unchanged, and the geometry is copied
+// unchanged if cellSize is not positive.
+//
+// Snapping a geometry to a grid gives the same coordinates for
+// coordinates which differ by less than rounding errors, as from a
+// reprojection there and back, so that geometries may be compared or
+// hashed to find duplicates. Rounding may still make the rings of a
+// polygon which does not collapse touch or cross, and Validate and
+// MakeValid may be used to find and repair them.
+func SnapToGrid(g Geometry, cellSize float64) Geometry {
+	if !(cellSize > 0) || math.IsInf(cellSize, 0) {
+		return Clone(g)
+	}
+	snap := func(p Point) Point {
+		if !p.IsEmpty() {
+			p.X = math.Round(p.X/cellSize) * cellSize
+			p.Y = math.Round(p.Y/cellSize) * cellSize
+		}
+		return p
+	}
+	line := func(l LineString) LineString {
+		s := make(LineString, len(l))
+		for i, p := range l {
+			s[i] = snap(p)
+		}
+		return cleanLine(s)
+	}
+	ring := func(r Ring) Ring {
+		l := line(LineString(r))
+		if len(l) > 1 && l[0].Equal(l[len(l)-1]) {
+			l = l[:len(l)-1]
+		}
+		ps := removeSpikes(l)
+		if len(ps) < 3 || signedArea(ps) == 0 {
+			return nil
+		}
+		return append(Ring(ps), ps[0])
+	}
+	polygon := func(p Polygon) Polygon {
+		var q Polygon
+		for i, r := range p {
+			s := ring(r)
+			switch {
+			case s == nil && i == 0:
+				return Polygon{}
+			case s != nil:
+				q = append(q, s)
+			}
+		}
+		return q
+	}
+	switch g := g.(type) {
+	case Point:
+		return snap(g)
+	case MultiPoint:
+		m := make(MultiPoint, len(g))
+		for i, p := range g {
+			m[i] = snap(p)
+		}
+		return m
+	case LineString:
+		return line(g)
+	case MultiLineString:
+		var m MultiLineString
+		for _, l := range g {
+			if s := line(l); s != nil {
+				m = append(m, s)
+			}
+		}
+		return m
+	case Polygon:
+		return polygon(g)
+	case MultiPolygon:
+		var m MultiPolygon
+		for _, p := range g {
+			if s := polygon(p); !s.IsEmpty() {
+				m = append(m, s)
+			}
+		}
+		return m
+	case GeometryCollection:
+		c := make(GeometryCollection, len(g))
+		for i, m := range g {
+			c[i] = SnapToGrid(m, cellSize)
+		}
+		return c
+	}
+	return g
+}
+
+// removeSpikes returns the points of a ring, given without its closing
+// point and without equal consecutive points, less the tips of its
+// spikes, where the ring turns back on itself.
+func removeSpikes(ps []Point) []Point {
+	out := make([]Point, 0, len(ps))
+	for _, p := range ps {
+		for len(out) >= 2 && isSpike(out[len(out)-2], out[len(out)-1], p) {
+			out = out[:len(out)-1]
+		}
+		out = appendDistinct(out, p)
+	}
+	// Remove the spikes at the start and end of the ring.
+	for len(out) >= 3 {
+		n := len(out)
+		switch {
+		case out[0].Equal(out[n-1]), isSpike(out[n-2], out[n-1], out[0]):
+			out = out[:n-1]
+		case isSpike(out[n-1], out[0], out[1]):
+			out = out[1:]
+		default:
+			return out
+		}
+	}
+	return out
+}
+
+// isSpike reports whether the line from a through b to c turns back at b
+// along the same line.
+func isSpike(a, b, c Point) bool {
+	return orient(a, b, c) == 0 && (b.X-a.X)*(c.X-b.X)+(b.Y-a.Y)*(c.Y-b.Y) < 0
+}
@@ -0,0 +1,81 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSnapToGrid(t *testing.T) {
+	spiked := Polygon{ring(0, 0, 4, 0, 4, 2, 6, 2.2, 4, 2.1, 4, 4, 0, 4, 0, 0)}
+	tests := []struct {
+		name string
+		g    Geometry
+		cell float64
+		want Geometry
+	}{
+		{"point", Point{X: 1.3, Y: -0.7, Z: 3, M: 4}, 0.25, Point{X: 1.25, Y: -0.75, Z: 3, M: 4}},
+		{"half", Point{X: 0.5, Y: -1.5}, 1, Point{X: 1, Y: -2}},
+		{"multipoint", MultiPoint(ring(0.4, 0.4, 0.6, 0.6)), 1, MultiPoint(ring(0, 0, 1, 1))},
+		{"line", LineString(ring(0, 0, 0.1, 0, 1, 0, 1.1, 0.1)), 1, LineString(ring(0, 0, 1, 0))},
+		{"collapsed line", LineString(ring(0, 0, 0.2, 0.1)), 1, LineString(nil)},
+		{"multiline", MultiLineString{LineString(ring(0, 0, 0.2, 0.1)), LineString(ring(0, 0, 2, 2))}, 1,
+			MultiLineString{LineString(ring(0, 0, 2, 2))}},
+		{"polygon", Polygon{ring(0.1, 0.1, 9.9, 0, 10, 10.2, 0, 10, 0.1, 0.1)}, 1, Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)}},
+		{"spike", spiked, 1, Polygon{ring(0, 0, 4, 0, 4, 2, 4, 4, 0, 4, 0, 0)}},
+		// A spike at the first point of the ring.
+		{"first spike", Polygon{ring(6, 2, 4, 2, 4, 4, 0, 4, 0, 0, 4, 0, 4, 2, 6, 2)}, 1, Polygon{ring(4, 2, 4, 4, 0, 4, 0, 0, 4, 0, 4, 2)}},
+		{"flat", Polygon{ring(0, 0, 10, 0, 10, 0.1, 0, 0.2, 0, 0)}, 1, Polygon{}},
+		{"hole", Polygon{donut[0], ring(2, 2, 2.3, 2, 2.3, 2.3, 2, 2)}, 1, Polygon{donut[0]}},
+		{"multipolygon", MultiPolygon{Polygon{ring(0, 0, 0.1, 0, 0, 0.1, 0, 0)}, square}, 1, MultiPolygon{square}},
+		{"collection", GeometryCollection{LineString(ring(0, 0, 0.1, 0)), Polygon{ring(0, 0, 0.1, 0, 0, 0.1, 0, 0)}, Point{X: 2.2, Y: 2}}, 1,
+			GeometryCollection{LineString(nil), Polygon{}, Point{X: 2, Y: 2}}},
+		{"zero", Point{X: 1.3, Y: 1}, 0, Point{X: 1.3, Y: 1}},
+		{"negative", LineString(ring(0, 0, 0.1, 0)), -1, LineString(ring(0, 0, 0.1, 0))},
+		{"infinite", Point{X: 1.3, Y: 1}, math.Inf(1), Point{X: 1.3, Y: 1}},
+		{"nan", Point{X: 1.3, Y: 1}, math.NaN(), Point{X: 1.3, Y: 1}},
+	}
+	for _, tt := range tests {
+		if got := SnapToGrid(tt.g, tt.cell); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SnapToGrid(%v, %v) = %v, want %v", tt.name, tt.g, tt.cell, got, tt.want)
+		}
+	}
+	if p := SnapToGrid(EmptyPoint(), 1); !p.IsEmpty() {
+		t.Errorf("SnapToGrid(empty) = %v, want empty", p)
+	}
+	// Coordinates differing by rounding error are made equal.
+	a := SnapToGrid(Point{X: 0.1 + 0.2, Y: 52.3}, 0.01)
+	b := SnapToGrid(Point{X: 0.3, Y: 52.3 + 1e-12}, 0.01)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("SnapToGrid(0.1+0.2) = %v, SnapToGrid(0.3) = %v", a, b)
+	}
+}
+
+func TestSnapToGridRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(77))
+	for range 500 {
+		shell := make(Ring, 3+r.Intn(10))
+		for i := range shell {
+			shell[i] = Point{X: r.Float64() * 5, Y: r.Float64() * 5}
+		}
+		cell := []float64{0.5, 1, 2}[r.Intn(3)]
+		g := SnapToGrid(Polygon{append(shell, shell[0])}, cell).(Polygon)
+		if g.IsEmpty() {
+			continue
+		}
+		ps := g[0][:len(g[0])-1]
+		if !g[0][0].Equal(g[0][len(g[0])-1]) || len(ps) < 3 || signedArea(ps) == 0 {
+			t.Fatalf("SnapToGrid(%v, %v) = %v, not a closed ring with area", shell, cell, g)
+		}
+		for i, p := range ps {
+			if p.X/cell != math.Round(p.X/cell) || p.Y/cell != math.Round(p.Y/cell) {
+				t.Fatalf("SnapToGrid(%v, %v) has %v off the grid", shell, cell, p)
+			}
+			q, s := ps[(i+1)%len(ps)], ps[(i+2)%len(ps)]
+			if p.Equal(q) || isSpike(p, q, s) {
+				t.Fatalf("SnapToGrid(%v, %v) = %v, repeating %v or turning back at it", shell, cell, g, q)
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package geom
+
+import "slices"
+
+// LineString is a sequence of points joined by straight line segments.
+// A valid line string has no points at all, when it is empty, or at
+// least two.
+type LineString []Point
+
+// Type returns TypeLineString.
+func (l LineString) Type() Type { return TypeLineString }
+
+// IsEmpty reports whether the line string has no points.
+func (l LineString) IsEmpty() bool { return len(l) == 0 }
+
+// Dimension returns 1.
+func (l LineString) Dimension() int { return 1 }
+
+// IsClosed reports whether the line string is not empty and its first
+// and last points are equal.
+func (l LineString) IsClosed() bool {
+	return len(l) > 0 && l[0].Equal(l[len(l)-1])
+}
+
+// Clone returns a copy of the line string.
+func (l LineString) Clone() LineString {
+	return slices.Clone(l)
+}
+
+// Ring is a closed line string which bounds a polygon, its last point
+// repeating its first. A valid ring has at least four points, or none
+// at all, and does not cross itself.
+type Ring []Point
+
+// IsClosed reports whether the ring is not empty and its first and last
+// points are equal.
+func (r Ring) IsClosed() bool {
+	return LineString(r).IsClosed()
+}
+
+// Clone returns a copy of the ring.
+func (r Ring) Clone() Ring {
+	return slices.Clone(r)
+}
+
+// Polygon is an area of the plane, given by its boundary: an exterior
+// ring followed by any number of interior rings, bounding holes in the
+// area.
+type Polygon []Ring
+
+// Type returns TypePolygon.
+func (p Polygon) Type() Type { return TypePolygon }
+
+// IsEmpty reports whether the polygon has no rings or an empty exterior
+// ring.
+func (p Polygon) IsEmpty() bool { return len(p) == 0 || len(p[0]) == 0 }
+
+// Dimension returns 2.
+func (p Polygon) Dimension() int { return 2 }
+
+// Exterior returns the exterior ring of the polygon, or nil if it has
+// none.
+func (p Polygon) Exterior() Ring {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[0]
+}
+
+// Holes returns the interior rings of the polygon.
+func (p Polygon) Holes() []Ring {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[1:]
+}
+
+// Clone returns a deep copy of the polygon.
+func (p Polygon) Clone() Polygon {
+	return cloneAll(p, Ring.Clone)
+}
+
+// MultiPoint is a collection of points.
+type MultiPoint []Point
+
+// Type returns TypeMultiPoint.
+func (m MultiPoint) Type() Type { return TypeMultiPoint }
+
+// IsEmpty reports whether every point of the collection is empty.
+func (m MultiPoint) IsEmpty() bool { return allEmpty(m) }
+
+// Dimension returns 0.
+func (m MultiPoint) Dimension() int { return 0 }
+
+// Clone returns a copy of the collection.
+func (m MultiPoint) Clone() MultiPoint {
+	return slices.Clone(m)
+}
+
+// MultiLineString is a collection of line strings.
+type MultiLineString []LineString
+
+// Type returns TypeMultiLineString.
+func (m MultiLineString) Type() Type { return TypeMultiLineString }
+
+// IsEmpty reports whether every line string of the collection is empty.
+func (m MultiLineString) IsEmpty() bool { return allEmpty(m) }
+
+// Dimension returns 1.
+func (m MultiLineString) Dimension() int { return 1 }
+
+// Clone returns a deep copy of the collection.
+func (m MultiLineString) Clone() MultiLineString {
+	return cloneAll(m, LineString.Clone)
+}
+
+// MultiPolygon is a collection of polygons, whose interiors must not
+// overlap.
+type MultiPolygon []Polygon
+
+// Type returns TypeMultiPolygon.
+func (m MultiPolygon) Type() Type { return TypeMultiPolygon }
+
+// IsEmpty reports whether every polygon of the collection is empty.
+func (m MultiPolygon) IsEmpty() bool { return allEmpty(m) }
+
+// Dimension returns 2.
+func (m MultiPolygon) Dimension() int { return 2 }
+
+// Clone returns a deep copy of the collection.
+func (m MultiPolygon) Clone() MultiPolygon {
+	return cloneAll(m, Polygon.Clone)
+}
+
+// GeometryCollection is a collection of geometries of any types.
+type GeometryCollection []Geometry
+
+// Type returns TypeGeometryCollection.
+func (c GeometryCollection) Type() Type { return TypeGeometryCollection }
+
+// IsEmpty reports whether every member of the collection is empty.
+func (c GeometryCollection) IsEmpty() bool { return allEmpty(c) }
+
+// Dimension returns the greatest dimension of the members of the
+// collection, or 0 if it has none.
+func (c GeometryCollection) Dimension() int {
+	d := 0
+	for _, g := range c {
+		d = max(d, g.Dimension())
+	}
+	return d
+}
+
+// Clone returns a deep copy of the collection.
+func (c GeometryCollection) Clone() GeometryCollection {
+	return cloneAll(c, Clone)
+}
+
+// allEmpty reports whether every geometry of a slice is empty.
+func allEmpty[S ~[]G, G Geometry](s S) bool {
+	for _, g := range s {
+		if !g.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneAll returns a copy of a slice whose elements are copied with
+// clone. It returns nil if the slice is nil.
+func cloneAll[S ~[]E, E any](s S, clone func(E) E) S {
+	if s == nil {
+		return nil
+	}
+	c := make(S, len(s))
+	for i, e := range s {
+		c[i] = clone(e)
+	}
+	return c
+}
+
+var (
+	_ Geometry = Point{}
+	_ Geometry = LineString{}
+	_ Geometry = Polygon{}
+	_ Geometry = MultiPoint{}
+	_ Geometry = MultiLineString{}
+	_ Geometry = MultiPolygon{}
+	_ Geometry = GeometryCollection{}
+)
@@ -0,0 +1,69 @@
+package geom
+
+import "testing"
+
+func TestIsClosed(t *testing.T) {
+	tests := []struct {
+		ps   []Point
+		want bool
+	}{
+		{nil, false},
+		{[]Point{{X: 1}}, true},
+		{[]Point{{X: 1}, {X: 2}}, false},
+		{square[0], true},
+		// Only X and Y are compared.
+		{[]Point{{X: 1, Z: 1}, {X: 2}, {X: 1, Z: 2}}, true},
+	}
+	for _, tt := range tests {
+		if c := LineString(tt.ps).IsClosed(); c != tt.want {
+			t.Errorf("LineString(%v).IsClosed() = %v, want %v", tt.ps, c, tt.want)
+		}
+		if c := Ring(tt.ps).IsClosed(); c != tt.want {
+			t.Errorf("Ring(%v).IsClosed() = %v, want %v", tt.ps, c, tt.want)
+		}
+	}
+}
+
+func TestPolygonRings(t *testing.T) {
+	hole := Ring{{X: 0.25, Y: 0.25}, {X: 0.25, Y: 0.75}, {X: 0.75, Y: 0.75}, {X: 0.25, Y: 0.25}}
+	p := Polygon{square[0], hole}
+	if e := p.Exterior(); len(e) != 5 || &e[0] != &square[0][0] {
+		t.Errorf("Exterior() = %v, want %v", e, square[0])
+	}
+	if h := p.Holes(); len(h) != 1 || len(h[0]) != 4 {
+		t.Errorf("Holes() = %v, want [%v]", h, hole)
+	}
+	if h := square.Holes(); len(h) != 0 {
+		t.Errorf("Holes() of a square = %v, want none", h)
+	}
+	var empty Polygon
+	if e, h := empty.Exterior(), empty.Holes(); e != nil || h != nil {
+		t.Errorf("empty Polygon Exterior(), Holes() = %v, %v, want nil, nil", e, h)
+	}
+}
+
+func TestTypesClone(t *testing.T) {
+	l := LineString{{X: 1}, {X: 2}}
+	if c := l.Clone(); &c[0] == &l[0] || c[1] != l[1] {
+		t.Errorf("LineString.Clone() shares or changes storage")
+	}
+	r := square[0].Clone()
+	r[0].X = 5
+	if square[0][0].X != 0 {
+		t.Errorf("Ring.Clone() shares storage")
+	}
+	mp := MultiPolygon{square, nil}
+	c := mp.Clone()
+	if len(c) != 2 || c[1] != nil || &c[0][0][0] == &mp[0][0][0] {
+		t.Errorf("MultiPolygon.Clone() = %v", c)
+	}
+	if c := (MultiLineString{nil}).Clone(); len(c) != 1 || c[0] != nil {
+		t.Errorf("MultiLineString{nil}.Clone() = %v", c)
+	}
+	if c := (GeometryCollection{}).Clone(); c == nil || len(c) != 0 {
+		t.Errorf("GeometryCollection{}.Clone() = %#v, want empty", c)
+	}
+	if c := (MultiPoint)(nil).Clone(); c != nil {
+		t.Errorf("MultiPoint(nil).Clone() = %v, want nil", c)
+	}
+}
@@ -0,0 +1,414 @@
+package geom
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalid is wrapped by the ValidityError returned by Validate.
+var ErrInvalid = errors.New("geom: invalid geometry")
+
+// Reason is the reason for which a geometry is invalid.
+type Reason int
+
+// The reasons for which a geometry may be invalid.
+const (
+	// InvalidCoordinate is the reason when a coordinate is NaN or
+	// infinite.
+	InvalidCoordinate Reason = iota + 1
+	// TooFewPoints is the reason when a line string has fewer than two
+	// distinct points, or a ring fewer than four points.
+	TooFewPoints
+	// UnclosedRing is the reason when the last point of a ring is not
+	// its first.
+	UnclosedRing
+	// DuplicatePoint is the reason when two consecutive points of a
+	// ring are equal.
+	DuplicatePoint
+	// SelfIntersection is the reason when a ring crosses or touches
+	// itself, or rings of a polygon cross each other or overlap.
+	SelfIntersection
+	// HoleOutsideShell is the reason when a hole of a polygon lies
+	// outside its exterior ring.
+	HoleOutsideShell
+	// NestedHoles is the reason when a hole of a polygon lies inside
+	// another.
+	NestedHoles
+	// OverlappingPolygons is the reason when the interiors of polygons
+	// of a multipolygon overlap, or their boundaries share a segment.
+	OverlappingPolygons
+)
+
+// String returns a description of the reason, such as
+// "self-intersection".
+func (r Reason) String() string {
+	switch r {
+	case InvalidCoordinate:
+		return "invalid coordinate"
+	case TooFewPoints:
+		return "too few points"
+	case UnclosedRing:
+		return "unclosed ring"
+	case DuplicatePoint:
+		return "duplicate point"
+	case SelfIntersection:
+		return "self-intersection"
+	case HoleOutsideShell:
+		return "hole outside shell"
+	case NestedHoles:
+		return "nested holes"
+	case OverlappingPolygons:
+		return "overlapping polygons"
+	}
+	return "Reason(?)"
+}
+
+// ValidityError describes the first problem found by Validate.
+type ValidityError struct {
+	Reason Reason
+	// Point is the location of the problem.
+	Point Point
+}
+
+// Error returns a description of the problem, such as
+// "geom: invalid geometry: self-intersection at (1, 2)".
+func (e *ValidityError) Error() string {
+	return fmt.Sprintf("%v: %v at (%g, %g)", ErrInvalid, e.Reason, e.Point.X, e.Point.Y)
+}
+
+// Unwrap returns ErrInvalid.
+func (e *ValidityError) Unwrap() error {
+	return ErrInvalid
+}
+
+// IsValid reports whether a geometry is valid, as checked by Validate.
+func IsValid(g Geometry) bool {
+	return Validate(g) == nil
+}
+
+// Validate checks that a geometry is valid in the sense of the OGC
+// Simple Features specification, as most algorithms on polygons
+// require, and returns a *ValidityError describing the first problem it
+// finds, or nil if there is none. Empty geometries are valid.
+//
+// The coordinates of a valid geometry are finite, its line strings have
+// at least two distinct points and its rings at least four points. The
+// rings are closed and simple, with no two consecutive points equal and
+// no crossing or touching of themselves. The rings of a polygon may
+// touch each other at single points but not cross, its holes lie inside
+// its exterior ring, and no hole lies inside another. The polygons of a
+// multipolygon may touch at single points, but their interiors do not
+// overlap. MakeValid repairs invalid geometries.
+func Validate(g Geometry) error {
+	switch g := g.(type) {
+	case Point:
+		return validatePoints(g)
+	case MultiPoint:
+		return validatePoints(g...)
+	case LineString:
+		return validateLine(g)
+	case MultiLineString:
+		for _, l := range g {
+			if err := validateLine(l); err != nil {
+				return err
+			}
+		}
+	case Polygon:
+		return validatePolygon(g)
+	case MultiPolygon:
+		return validateMultiPolygon(g)
+	case GeometryCollection:
+		for _, m := range g {
+			if err := Validate(m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// invalid returns the ValidityError for the reason at p.
+func invalid(r Reason, p Point) error {
+	return &ValidityError{r, p}
+}
+
+// validatePoints checks that the points are finite or empty.
+func validatePoints(ps ...Point) error {
+	for _, p := range ps {
+		if !p.IsEmpty() && !finite(p) {
+			return invalid(InvalidCoordinate, p)
+		}
+	}
+	return nil
+}
+
+// validateCoords checks that the points are finite, none of them
+// empty.
+func validateCoords(ps []Point) error {
+	for _, p := range ps {
+		if !finite(p) {
+			return invalid(InvalidCoordinate, p)
+		}
+	}
+	return nil
+}
+
+// validateLine checks a line string.
+func validateLine(l LineString) error {
+	if err := validateCoords(l); err != nil {
+		return err
+	}
+	for _, p := range l {
+		if !p.Equal(l[0]) {
+			return nil
+		}
+	}
+	if len(l) > 0 {
+		return invalid(TooFewPoints, l[0])
+	}
+	return nil
+}
+
+// validateRing checks that a ring is closed and simple.
+func validateRing(r Ring) error {
+	if err := validateCoords(r); err != nil {
+		return err
+	}
+	switch {
+	case len(r) == 0:
+		return nil
+	case !r.IsClosed():
+		return invalid(UnclosedRing, r[len(r)-1])
+	case len(r) < 4:
+		return invalid(TooFewPoints, r[0])
+	}
+	for i := 1; i < len(r); i++ {
+		if r[i].Equal(r[i-1]) {
+			return invalid(DuplicatePoint, r[i])
+		}
+	}
+	segs := segments(r)
+	n := len(segs)
+	var err error
+	overlappingPairs(segs, func(i, j int) {
+		if err != nil {
+			return
+		}
+		si := segs[i].Intersection(segs[j])
+		switch {
+		case si.Kind == NoIntersection:
+			return
+		case j == i+1 && si.Kind == EndpointIntersection && si.P.Equal(segs[j].A),
+			i == 0 && j == n-1 && si.Kind == EndpointIntersection && si.P.Equal(segs[i].A):
+			return // Consecutive segments meet at their common vertex
+		}
+		err = invalid(SelfIntersection, si.P)
+	})
+	return err
+}
+
+// validatePolygon checks a polygon.
+func validatePolygon(p Polygon) error {
+	if p.IsEmpty() {
+		return validatePoints(p.Exterior()...)
+	}
+	for _, r := range p {
+		if err := validateRing(r); err != nil {
+			return err
+		}
+	}
+	if err := crossingRings(p, SelfIntersection); err != nil {
+		return err
+	}
+	shell := p[0]
+	for i, h := range p.Holes() {
+		if len(h) == 0 {
+			continue
+		}
+		if v, loc := locateRingVertex(shell, h); loc == Exterior {
+			return invalid(HoleOutsideShell, v)
+		}
+		for _, k := range p.Holes()[i+1:] {
+			if len(k) == 0 {
+				continue
+			}
+			if v, loc := locateRingVertex(k, h); loc == Interior {
+				return invalid(NestedHoles, v)
+			}
+			if v, loc := locateRingVertex(h, k); loc == Interior {
+				return invalid(NestedHoles, v)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMultiPolygon checks a multipolygon.
+func validateMultiPolygon(m MultiPolygon) error {
+	for _, p := range m {
+		if err := validatePolygon(p); err != nil {
+			return err
+		}
+	}
+	bounds := make([]Rect, len(m))
+	for i, p := range m {
+		bounds[i] = RectOf(p.Exterior()...)
+	}
+	for i, p := range m {
+		for j := i + 1; j < len(m); j++ {
+			q := m[j]
+			if p.IsEmpty() || q.IsEmpty() || !bounds[i].Intersects(bounds[j]) {
+				continue
+			}
+			if err := crossingRings(Polygon{p[0], q[0]}, OverlappingPolygons); err != nil {
+				return err
+			}
+			for _, pair := range [2][2]Polygon{{p, q}, {q, p}} {
+				for _, v := range pair[0][0] {
+					if locatePolygon(pair[1], v) == Interior {
+						return invalid(OverlappingPolygons, v)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// crossingRings checks that no two of the rings cross or share a
+// segment, and returns an error for the given reason if they do.
+func crossingRings(rings []Ring, reason Reason) error {
+	var segs []Segment
+	var ringOf []int
+	for i, r := range rings {
+		s := segments(r)
+		segs = append(segs, s...)
+		for range s {
+			ringOf = append(ringOf, i)
+		}
+	}
+	var err error
+	overlappingPairs(segs, func(i, j int) {
+		if err != nil || ringOf[i] == ringOf[j] {
+			return
+		}
+		if si := segs[i].Intersection(segs[j]); si.Kind == ProperIntersection || si.Kind == CollinearIntersection {
+			err = invalid(reason, si.P)
+		}
+	})
+	return err
+}
+
+// locateRingVertex returns a vertex of the ring r which is not on the
+// ring s, if there is one, and its location relative to the area
+// bounded by s. It returns Boundary if every vertex of r is on s.
+func locateRingVertex(s, r Ring) (Point, Location) {
+	for _, v := range r {
+		if loc := locateRing(s, v); loc != Boundary {
+			return v, loc
+		}
+	}
+	return r[0], Boundary
+}
+
+// MakeValid returns a valid geometry covering the same area as an
+// invalid one, or the geometry itself if it is already valid, as checked
+// by Validate.
+//
+// Polygons are repaired by the even-odd rule: the area of the result is
+// that of the points around which the rings of the polygon wind an odd
+// number of times, so that a ring crossing itself in a figure eight
+// gives two polygons, and a hole outside the shell becomes a polygon of
+// its own. The polygons of a multipolygon are each repaired and then
+// merged, so that the result covers the area covered by any of them.
+// Repeated points and points with invalid coordinates are removed and
+// rings are closed. The parts of polygons of zero area, and line strings
+// with fewer than two distinct points, are dropped, and the result may
+// be a MultiPolygon where the input was a Polygon, or an empty
+// geometry.
+func MakeValid(g Geometry) Geometry {
+	if IsValid(g) {
+		return g
+	}
+	switch g := g.(type) {
+	case Point:
+		return EmptyPoint()
+	case MultiPoint:
+		var m MultiPoint
+		for _, p := range g {
+			if !p.IsEmpty() && finite(p) {
+				m = append(m, p)
+			}
+		}
+		return m
+	case LineString:
+		return cleanLine(g)
+	case MultiLineString:
+		var m MultiLineString
+		for _, l := range g {
+			if l = cleanLine(l); len(l) > 0 {
+				m = append(m, l)
+			}
+		}
+		return m
+	case Polygon:
+		return singlePolygon(repairPolygon(g))
+	case MultiPolygon:
+		var edges []edge
+		for _, p := range g {
+			for _, q := range repairPolygon(p) {
+				for _, r := range q {
+					edges = ringEdges(edges, r, [2]int{1, 0})
+				}
+			}
+		}
+		return buildArea(node(edges), func(w [2]int) bool { return w[0] > 0 })
+	case GeometryCollection:
+		c := make(GeometryCollection, len(g))
+		for i, m := range g {
+			c[i] = MakeValid(m)
+		}
+		return c
+	}
+	return g
+}
+
+// cleanLine returns a line string without repeated points or points
+// with invalid coordinates, or nil if fewer than two points remain.
+func cleanLine(l LineString) LineString {
+	var c LineString
+	for _, p := range l {
+		if finite(p) && (len(c) == 0 || !p.Equal(c[len(c)-1])) {
+			c = append(c, p)
+		}
+	}
+	if len(c) < 2 {
+		return nil
+	}
+	return c
+}
+
+// repairPolygon returns the polygons covering the area around which the
+// rings of a polygon wind an odd number of times.
+func repairPolygon(p Polygon) MultiPolygon {
+	var edges []edge
+	for _, r := range p {
+		var c Ring
+		for _, v := range r {
+			if finite(v) {
+				c = append(c, v)
+			}
+		}
+		edges = ringEdges(edges, c, [2]int{1, 0})
+	}
+	return buildArea(node(edges), func(w [2]int) bool { return w[0]%2 != 0 })
+}
+
+// singlePolygon returns the only polygon of a multipolygon, if it has
+// just one, or else the multipolygon.
+func singlePolygon(m MultiPolygon) Geometry {
+	if len(m) == 1 {
+		return m[0]
+	}
+	return m
+}
@@ -0,0 +1,184 @@
+package geom
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// ring returns the ring through the points with the coordinates x0, y0,
+// x1, y1, and so on.
+func ring(c ...float64) Ring {
+	r := make(Ring, len(c)/2)
+	for i := range r {
+		r[i] = Point{X: c[2*i], Y: c[2*i+1]}
+	}
+	return r
+}
+
+// polygonArea returns the area of a polygon or multipolygon, counting
+// the areas of its holes as negative.
+func polygonArea(g Geometry) float64 {
+	a := 0.0
+	switch g := g.(type) {
+	case Polygon:
+		for i, r := range g {
+			if i == 0 {
+				a += abs(signedArea(r))
+			} else {
+				a -= abs(signedArea(r))
+			}
+		}
+	case MultiPolygon:
+		for _, p := range g {
+			a += polygonArea(p)
+		}
+	}
+	return a
+}
+
+func TestReason(t *testing.T) {
+	for r, want := range []string{"Reason(?)", "invalid coordinate", "too few points", "unclosed ring", "duplicate point", "self-intersection", "hole outside shell", "nested holes", "overlapping polygons", "Reason(?)"} {
+		if s := Reason(r).String(); s != want {
+			t.Errorf("Reason(%d).String() = %q, want %q", r, s, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	sq := ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)
+	tests := []struct {
+		name   string
+		g      Geometry
+		reason Reason
+		at     Point
+	}{
+		{"square", Polygon{sq}, 0, Point{}},
+		{"square with hole", Polygon{sq, ring(2, 2, 2, 8, 8, 8, 8, 2, 2, 2)}, 0, Point{}},
+		{"hole touching shell", Polygon{sq, ring(0, 0, 5, 2, 2, 5, 0, 0)}, 0, Point{}},
+		{"empty", Polygon{}, 0, Point{}},
+		{"empty ring", Polygon{Ring{}}, 0, Point{}},
+		{"point", Point{X: 1, Y: 2}, 0, Point{}},
+		{"empty point", EmptyPoint(), 0, Point{}},
+		{"line", LineString{{X: 0}, {X: 1}}, 0, Point{}},
+		{"empty point in line", LineString{{X: 0}, EmptyPoint(), {X: 1}}, InvalidCoordinate, EmptyPoint()},
+		{"infinite point", Point{X: math.Inf(1)}, InvalidCoordinate, Point{X: math.Inf(1)}},
+		{"NaN in ring", Polygon{ring(0, 0, 1, 0, math.NaN(), 1, 0, 0)}, InvalidCoordinate, EmptyPoint()},
+		{"line of one point", LineString{{X: 1}, {X: 1}}, TooFewPoints, Point{X: 1}},
+		{"two lines", MultiLineString{{{X: 0}, {X: 1}}, {{X: 2}}}, TooFewPoints, Point{X: 2}},
+		{"triangle of three", Polygon{ring(0, 0, 1, 0, 0, 0)}, TooFewPoints, Point{}},
+		{"unclosed", Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10)}, UnclosedRing, Point{X: 0, Y: 10}},
+		{"duplicate", Polygon{ring(0, 0, 10, 0, 10, 0, 10, 10, 0, 0)}, DuplicatePoint, Point{X: 10, Y: 0}},
+		{"bowtie", Polygon{ring(0, 0, 10, 10, 10, 0, 0, 10, 0, 0)}, SelfIntersection, Point{X: 5, Y: 5}},
+		{"spike", Polygon{ring(0, 0, 10, 0, 10, 10, 5, 10, 5, 15, 5, 10, 0, 10, 0, 0)}, SelfIntersection, Point{X: 5, Y: 10}},
+		{"flat", Polygon{ring(0, 0, 1, 1, 2, 2, 0, 0)}, SelfIntersection, Point{X: 0, Y: 0}},
+		{"crossing hole", Polygon{sq, ring(5, 5, 15, 5, 15, 6, 5, 6, 5, 5)}, SelfIntersection, Point{X: 10, Y: 5}},
+		{"hole outside", Polygon{sq, ring(20, 20, 21, 20, 21, 21, 20, 20)}, HoleOutsideShell, Point{X: 20, Y: 20}},
+		{"nested holes", Polygon{sq, ring(2, 2, 8, 2, 8, 8, 2, 8, 2, 2), ring(3, 3, 4, 3, 4, 4, 3, 3)}, NestedHoles, Point{X: 3, Y: 3}},
+		{"overlapping", MultiPolygon{{sq}, {ring(5, 5, 15, 5, 15, 15, 5, 15, 5, 5)}}, OverlappingPolygons, Point{X: 5, Y: 10}},
+		{"inside another", MultiPolygon{{sq}, {ring(2, 2, 3, 2, 3, 3, 2, 2)}}, OverlappingPolygons, Point{X: 2, Y: 2}},
+		{"sharing an edge", MultiPolygon{{sq}, {ring(10, 0, 20, 0, 20, 10, 10, 10, 10, 0)}}, OverlappingPolygons, Point{X: 10, Y: 0}},
+		{"touching", MultiPolygon{{sq}, {ring(10, 10, 20, 10, 20, 20, 10, 10)}}, 0, Point{}},
+		{"collection", GeometryCollection{Point{}, LineString{{X: 1}}}, TooFewPoints, Point{X: 1}},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.g)
+		if tt.reason == 0 {
+			if err != nil || !IsValid(tt.g) {
+				t.Errorf("%s: Validate() = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		var ve *ValidityError
+		if !errors.As(err, &ve) || !errors.Is(err, ErrInvalid) || IsValid(tt.g) {
+			t.Errorf("%s: Validate() = %v, want a ValidityError", tt.name, err)
+			continue
+		}
+		if ve.Reason != tt.reason || !ve.Point.Equal(tt.at) && !(tt.at.IsEmpty() && ve.Point.IsEmpty()) {
+			t.Errorf("%s: Validate() = %v at %v, want %v at %v", tt.name, ve.Reason, ve.Point, tt.reason, tt.at)
+		}
+	}
+	err := Validate(Polygon{ring(0, 0, 10, 10, 10, 0, 0, 10, 0, 0)})
+	if want := "geom: invalid geometry: self-intersection at (5, 5)"; err == nil || err.Error() != want {
+		t.Errorf("Validate(bowtie) = %v, want %q", err, want)
+	}
+}
+
+func TestMakeValid(t *testing.T) {
+	sq := ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)
+	tests := []struct {
+		name  string
+		g     Geometry
+		parts int
+		area  float64
+	}{
+		{"bowtie", Polygon{ring(0, 0, 10, 10, 10, 0, 0, 10, 0, 0)}, 2, 50},
+		{"hole outside", Polygon{sq, ring(20, 20, 21, 20, 21, 21, 20, 20)}, 2, 100.5},
+		{"nested holes", Polygon{sq, ring(2, 2, 8, 2, 8, 8, 2, 8, 2, 2), ring(3, 3, 4, 3, 4, 4, 3, 3)}, 2, 64.5},
+		{"duplicate", Polygon{ring(0, 0, 10, 0, 10, 0, 10, 10, 0, 0)}, 1, 50},
+		{"unclosed", Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10)}, 1, 100},
+		{"spike", Polygon{ring(0, 0, 10, 0, 10, 10, 5, 10, 5, 15, 5, 10, 0, 10, 0, 0)}, 1, 100},
+		{"flat", Polygon{ring(0, 0, 1, 1, 2, 2, 0, 0)}, 0, 0},
+		{"overlapping", MultiPolygon{{sq}, {ring(5, 5, 15, 5, 15, 15, 5, 15, 5, 5)}}, 1, 175},
+		{"sharing an edge", MultiPolygon{{sq}, {ring(10, 0, 20, 0, 20, 10, 10, 10, 10, 0)}}, 1, 200},
+	}
+	for _, tt := range tests {
+		v := MakeValid(tt.g)
+		if err := Validate(v); err != nil {
+			t.Errorf("%s: MakeValid() = %v, invalid: %v", tt.name, v, err)
+		}
+		parts := 1
+		if m, ok := v.(MultiPolygon); ok {
+			parts = len(m)
+		}
+		if parts != tt.parts || math.Abs(polygonArea(v)-tt.area) > 1e-9 {
+			t.Errorf("%s: MakeValid() = %v, %d parts of area %v, want %d of %v", tt.name, v, parts, polygonArea(v), tt.parts, tt.area)
+		}
+	}
+	valid := Polygon{sq}
+	if v := MakeValid(valid); &v.(Polygon)[0][0] != &valid[0][0] {
+		t.Errorf("MakeValid(valid) is a copy")
+	}
+	if p := MakeValid(Point{X: math.Inf(1)}); !p.IsEmpty() {
+		t.Errorf("MakeValid(infinite point) = %v, want empty", p)
+	}
+	others := []struct {
+		g, want Geometry
+	}{
+		{MultiPoint{{X: 1}, {X: math.Inf(-1)}}, MultiPoint{{X: 1}}},
+		{LineString{{X: 0}, {X: 0}, {X: math.NaN()}, {X: 1}}, LineString{{X: 0}, {X: 1}}},
+		{LineString{{X: 1}, {X: 1}}, LineString(nil)},
+		{MultiLineString{{{X: 1}, {X: 1}}, {{X: 0}, {X: 2}}}, MultiLineString{{{X: 0}, {X: 2}}}},
+	}
+	for _, tt := range others {
+		if v := MakeValid(tt.g); !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("MakeValid(%v) = %v, want %v", tt.g, v, tt.want)
+		}
+	}
+}
+
+func TestMakeValidRandom(t *testing.T) {
+	// The repair of a random ring is valid and covers the points around
+	// which the ring winds an odd number of times.
+	r := rand.New(rand.NewSource(62))
+	for range 500 {
+		var rg Ring
+		for range 3 + r.Intn(12) {
+			rg = append(rg, Point{X: math.Round(r.Float64()*20) / 2, Y: math.Round(r.Float64()*20) / 2})
+		}
+		rg = append(rg, rg[0])
+		v := MakeValid(Polygon{rg})
+		if err := Validate(v); err != nil {
+			t.Fatalf("MakeValid(%v) = %v, invalid: %v", rg, v, err)
+		}
+		// Sample points off the half grid, so never on an edge.
+		for range 20 {
+			p := Point{X: r.Float64()*10 + 0.001, Y: r.Float64()*10 + 0.001}
+			if want := locateRing(rg, p) == Interior; CoversPoint(v, p) != want {
+				t.Fatalf("MakeValid(%v) = %v, covers %v = %v, want %v", rg, v, p, !want, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package geom
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// VisvalingamWhyatt simplifies geometries by the algorithm of
+// Visvalingam and Whyatt, "Line Generalisation by Repeated Elimination of
+// Points" (1993), which repeatedly removes the point of a line whose
+// effective area, the area of the triangle it forms with the points on
+// either side, is smallest, as long as it is smaller than the tolerance.
+// Removing a point changes the areas of its neighbors, which never
+// become smaller than the area of the point removed.
+//
+// The algorithm removes small details evenly, where Douglas-Peucker
+// keeps every sharp spike and removes gentle bends entirely, so that it
+// gives coastlines and boundaries which look more natural at small
+// scales. It takes time proportional to n log n for a line of n points.
+type VisvalingamWhyatt struct {
+	// Tolerance is the smallest effective area of a point kept.
+	Tolerance float64
+	// Weighted weights the effective areas by the angle of the line at
+	// each point, as proposed by Visvalingam (2016), so that the points
+	// of sharp spikes are removed before those of gentle bends of the
+	// same area, which smooths the line.
+	Weighted bool
+	// Meters interprets the coordinates as longitudes and latitudes in
+	// degrees and the tolerance in square meters, measuring areas on
+	// the sphere geodesy.Earth in an equirectangular approximation
+	// centered on each point. Otherwise the tolerance is in the square
+	// of the units of the coordinates.
+	Meters bool
+	// PreserveTopology keeps points to prevent the simplified lines and
+	// rings from crossing and to keep at least four points of every ring,
+	// as for DouglasPeucker.
+	PreserveTopology bool
+}
+
+// Simplify returns a copy of a geometry with its lines and rings
+// simplified, as for DouglasPeucker.Simplify.
+func (s VisvalingamWhyatt) Simplify(g Geometry) Geometry {
+	return simplify(g, s.PreserveTopology, distFunc(s.Meters), s.mark)
+}
+
+// mark marks the points of a line to keep besides its ends, which the
+// caller marks.
+func (s VisvalingamWhyatt) mark(ps []Point, keep []bool) {
+	n := len(ps)
+	prev, next := make([]int, n), make([]int, n)
+	area := make([]float64, n)
+	var h areaHeap
+	for k := 1; k < n-1; k++ {
+		prev[k], next[k] = k-1, k+1
+		area[k] = s.area(ps[k-1], ps[k], ps[k+1])
+		h = append(h, areaItem{k, area[k]})
+	}
+	heap.Init(&h)
+	removed := make([]bool, n)
+	for h.Len() > 0 {
+		it := heap.Pop(&h).(areaItem)
+		if removed[it.k] || it.area != area[it.k] {
+			continue // Stale
+		}
+		if it.area >= s.Tolerance {
+			break
+		}
+		removed[it.k] = true
+		p, q := prev[it.k], next[it.k]
+		next[p], prev[q] = q, p
+		for _, j := range [2]int{p, q} {
+			if j > 0 && j < n-1 {
+				area[j] = max(it.area, s.area(ps[prev[j]], ps[j], ps[next[j]]))
+				heap.Push(&h, areaItem{j, area[j]})
+			}
+		}
+	}
+	for k := 1; k < n-1; k++ {
+		keep[k] = !removed[k]
+	}
+}
+
+// area returns the effective area of the point b between a and c.
+func (s VisvalingamWhyatt) area(a, b, c Point) float64 {
+	if s.Meters {
+		k := geodesy.MeanRadius * degToRad
+		kx := k * math.Cos(b.Y*degToRad)
+		local := func(q Point) Point {
+			return Point{X: wrap180(q.X-b.X) * kx, Y: (q.Y - b.Y) * k}
+		}
+		a, b, c = local(a), Point{}, local(c)
+	}
+	t := abs((a.X-b.X)*(c.Y-b.Y)-(c.X-b.X)*(a.Y-b.Y)) / 2
+	if !s.Weighted {
+		return t
+	}
+	// The weight ranges from 0.3 for a spike to 1.7 for a straight line,
+	// by the cosine of the angle at b.
+	ux, uy, vx, vy := a.X-b.X, a.Y-b.Y, c.X-b.X, c.Y-b.Y
+	d := math.Hypot(ux, uy) * math.Hypot(vx, vy)
+	if d == 0 {
+		return t
+	}
+	return t * (1 - 0.7*(ux*vx+uy*vy)/d)
+}
+
+// areaItem is a point of a line and its effective area when queued.
+type areaItem struct {
+	k    int
+	area float64
+}
+
+// areaHeap is a min-heap of points by effective area, implementing
+// heap.Interface.
+type areaHeap []areaItem
+
+func (h areaHeap) Len() int           { return len(h) }
+func (h areaHeap) Less(i, j int) bool { return h[i].area < h[j].area }
+func (h areaHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *areaHeap) Push(x any)        { *h = append(*h, x.(areaItem)) }
+func (h *areaHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
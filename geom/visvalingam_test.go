@@ -0,0 +1,112 @@
+package geom
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestVisvalingamWhyatt(t *testing.T) {
+	// The effective areas are 1, 0.55 and 0.1, and removing the last
+	// point raises that of the one before it to 1.
+	l := LineString{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}, {X: 3, Y: 0.1}, {X: 4, Y: 0}}
+	tests := []struct {
+		tolerance float64
+		want      LineString
+	}{
+		{0.05, l},
+		{0.5, LineString{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}, {X: 4, Y: 0}}},
+		{0.99, LineString{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}, {X: 4, Y: 0}}},
+		{3, LineString{{X: 0, Y: 0}, {X: 4, Y: 0}}},
+	}
+	for _, tt := range tests {
+		if s := (VisvalingamWhyatt{Tolerance: tt.tolerance}).Simplify(l); !reflect.DeepEqual(s, tt.want) {
+			t.Errorf("VisvalingamWhyatt{%v}.Simplify(%v) = %v, want %v", tt.tolerance, l, s, tt.want)
+		}
+	}
+	if s := (VisvalingamWhyatt{Tolerance: 2}).Simplify(square).(Polygon); len(s) != 0 {
+		t.Errorf("VisvalingamWhyatt{2}.Simplify(square) = %v, want no rings", s)
+	}
+}
+
+func TestVisvalingamWhyattArea(t *testing.T) {
+	tests := []struct {
+		s       VisvalingamWhyatt
+		a, b, c Point
+		want    float64
+	}{
+		{VisvalingamWhyatt{}, Point{X: -1, Y: 0}, Point{X: 0, Y: 1}, Point{X: 1, Y: 0}, 1},
+		{VisvalingamWhyatt{}, Point{X: -1, Y: 0}, Point{X: 0, Y: -1}, Point{X: 1, Y: 0}, 1},
+		{VisvalingamWhyatt{}, Point{X: 0, Y: 0}, Point{X: 1, Y: 1}, Point{X: 2, Y: 2}, 0},
+		// A right angle has a weight of 1, a spike nearly 0.3 and a
+		// gentle bend nearly 1.7.
+		{VisvalingamWhyatt{Weighted: true}, Point{X: -1, Y: 0}, Point{X: 0, Y: 1}, Point{X: 1, Y: 0}, 1},
+		{VisvalingamWhyatt{Weighted: true}, Point{X: -0.1, Y: 0}, Point{X: 0, Y: 10}, Point{X: 0.1, Y: 0}, 0.30014},
+		{VisvalingamWhyatt{Weighted: true}, Point{X: -10, Y: 0}, Point{X: 0, Y: 0.1}, Point{X: 10, Y: 0}, 1.69993},
+		{VisvalingamWhyatt{Weighted: true}, Point{X: 0, Y: 0}, Point{X: 0, Y: 0}, Point{X: 1, Y: 0}, 0},
+		// A second of latitude off the line between points 0.01 degrees
+		// of longitude either side, at 60 degrees north.
+		{VisvalingamWhyatt{Meters: true}, Point{X: 9.99, Y: 60}, Point{X: 10, Y: 60 + 1.0/3600}, Point{X: 10.01, Y: 60}, 17172.7},
+		{VisvalingamWhyatt{Meters: true}, Point{X: 179.99, Y: 60}, Point{X: -180, Y: 60 + 1.0/3600}, Point{X: -179.99, Y: 60}, 17172.7},
+	}
+	for _, tt := range tests {
+		if a := tt.s.area(tt.a, tt.b, tt.c); math.Abs(a-tt.want) > 1e-4*max(1, tt.want) {
+			t.Errorf("%+v.area(%v, %v, %v) = %v, want %v", tt.s, tt.a, tt.b, tt.c, a, tt.want)
+		}
+	}
+}
+
+func TestVisvalingamWhyattRandom(t *testing.T) {
+	// Higher tolerances keep fewer points, each a point of the line, in
+	// order.
+	r := rand.New(rand.NewSource(67))
+	var l LineString
+	x, y := 0.0, 0.0
+	for range 10000 {
+		x += r.Float64()
+		y += r.NormFloat64()
+		l = append(l, Point{X: x, Y: y})
+	}
+	last := len(l) + 1
+	for _, tol := range []float64{0, 0.1, 1, 10, 100} {
+		s := VisvalingamWhyatt{Tolerance: tol}.Simplify(l).(LineString)
+		if len(s) > last || !s[0].Equal(l[0]) || !s[len(s)-1].Equal(l[len(l)-1]) {
+			t.Fatalf("VisvalingamWhyatt{%v}.Simplify() = %d points", tol, len(s))
+		}
+		last = len(s)
+		j := 0
+		for _, p := range s {
+			for j < len(l) && !l[j].Equal(p) {
+				j++
+			}
+			if j == len(l) {
+				t.Fatalf("VisvalingamWhyatt{%v}.Simplify() has %v out of order", tol, p)
+			}
+		}
+		if w := (VisvalingamWhyatt{Tolerance: tol, Weighted: true}).Simplify(l).(LineString); len(w) < 2 {
+			t.Fatalf("VisvalingamWhyatt{%v, Weighted}.Simplify() = %v", tol, w)
+		}
+	}
+	if last == 2 || last > len(l)/40 {
+		t.Errorf("VisvalingamWhyatt{100}.Simplify() = %d points of %d", last, len(l))
+	}
+}
+
+func TestVisvalingamWhyattPreserveTopology(t *testing.T) {
+	// Removing the bump in the shell, of area 10, but not the corners of
+	// the hole, of 10.5, makes the shell cross the hole.
+	bump := Polygon{
+		ring(0, 0, 10, 0, 10, 10, 5, 12, 0, 10, 0, 0),
+		ring(2, 7, 2, 10.5, 8, 10.5, 8, 7, 2, 7),
+	}
+	s := VisvalingamWhyatt{Tolerance: 10.2}.Simplify(bump)
+	if err := Validate(s); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Validate(VisvalingamWhyatt{10.2}.Simplify(%v)) = %v, want an error", bump, err)
+	}
+	s = VisvalingamWhyatt{Tolerance: 10.2, PreserveTopology: true}.Simplify(bump)
+	if !reflect.DeepEqual(s, bump) {
+		t.Errorf("VisvalingamWhyatt{10.2, PreserveTopology}.Simplify(%v) = %v, want it unchanged", bump, s)
+	}
+}
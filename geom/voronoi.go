@@ -0,0 +1,141 @@
+package geom
+
+import "math"
+
+// Voronoi returns the Voronoi cells of the points of the triangulation
+// within a rectangle. The cell of each point, at the same index as the
+// point in Points, is the part of the rectangle at least as near to it as
+// to any other point, as a convex Polygon with a counterclockwise ring.
+// The cells of points ignored by Delaunay, and of points whose cells miss
+// the rectangle, are empty polygons.
+//
+// Each cell is found by clipping the rectangle by the half-planes nearer
+// to its point than to each of the neighbors of the point in the
+// triangulation, which are the only points whose half-planes bound the
+// cell. Unlike joining the centers of the circles of the triangles around
+// each point, this needs no special case for the unbounded cells of the
+// points on the convex hull, nor for the thin triangles along it, whose
+// centers may lie very far away. It takes time proportional to the number
+// of neighbors of each point.
+func (t *Triangulation) Voronoi(r Rect) []Polygon {
+	cells := make([]Polygon, len(t.Points))
+	for i := range cells {
+		cells[i] = Polygon{}
+	}
+	if r.IsEmpty() {
+		return cells
+	}
+	nbrs, in := t.neighborPoints()
+	var buf [2][]Point
+	for i, ns := range nbrs {
+		if !in[i] {
+			continue
+		}
+		cell := append(buf[0][:0], r.Polygon()[0][:4]...)
+		buf[0] = cell
+		for k, j := range ns {
+			cell = clipBisector(buf[(k+1)%2][:0], cell, t.Points[i], t.Points[j])
+			buf[(k+1)%2] = cell
+			if len(cell) < 3 {
+				break
+			}
+		}
+		if len(cell) < 3 || signedArea(cell) <= 0 {
+			continue
+		}
+		ring := make(Ring, len(cell)+1)
+		copy(ring, cell)
+		ring[len(cell)] = cell[0]
+		cells[i] = Polygon{ring}
+	}
+	return cells
+}
+
+// VoronoiWithin returns the Voronoi cells of the points of the
+// triangulation clipped to the polygons of a geometry, such as the
+// region a set of depots serves, as a Polygon or a MultiPolygon for each
+// point, which is empty if its cell misses the polygons. The cells are
+// those within the bounds of the geometry given by Voronoi, intersected
+// with the geometry as for Intersection.
+func (t *Triangulation) VoronoiWithin(g Geometry) []Geometry {
+	cells := t.Voronoi(Bounds(g))
+	clipped := make([]Geometry, len(cells))
+	for i, c := range cells {
+		if c.IsEmpty() {
+			clipped[i] = c
+		} else {
+			clipped[i] = Intersection(c, g)
+		}
+	}
+	return clipped
+}
+
+// neighborPoints returns the indices of the points joined to each point
+// by an edge of the triangulation, and whether each point is a vertex of
+// the triangulation.
+func (t *Triangulation) neighborPoints() ([][]int, []bool) {
+	nbrs := make([][]int, len(t.Points))
+	in := make([]bool, len(t.Points))
+	join := func(i, j int) {
+		nbrs[i] = append(nbrs[i], j)
+		nbrs[j] = append(nbrs[j], i)
+	}
+	if t.Len() == 0 {
+		// The points are collinear, each joined to those either side.
+		for k, i := range t.Hull {
+			in[i] = true
+			if k > 0 {
+				join(t.Hull[k-1], i)
+			}
+		}
+		return nbrs, in
+	}
+	for e, i := range t.Triangles {
+		in[i] = true
+		// Join each edge once, by its half-edge with the greater index.
+		if o := t.Halfedges[e]; o < e {
+			join(i, t.Triangles[nextHalfedge(e)])
+		}
+	}
+	return nbrs, in
+}
+
+// clipBisector appends to out the points of the convex polygon ps,
+// given without repeating its first point, clipped to the half-plane at
+// least as near to the point p as to q. Points within rounding error of
+// the bisector count as on it, so that clipping by the bisectors of
+// several points through the same vertex of a cell leaves one vertex.
+func clipBisector(out, ps []Point, p, q Point) []Point {
+	m := Point{X: (p.X + q.X) / 2, Y: (p.Y + q.Y) / 2}
+	dx, dy := q.X-p.X, q.Y-p.Y
+	d := math.Hypot(dx, dy)
+	// side returns 1 on the side of the bisector nearer to q, -1 on the
+	// side nearer to p, and 0 on it, and the distance from it scaled by d.
+	side := func(v Point) (int, float64) {
+		f := (v.X-m.X)*dx + (v.Y-m.Y)*dy
+		tol := 1e-12 * d * (d + math.Hypot(v.X-m.X, v.Y-m.Y))
+		switch {
+		case f > tol:
+			return 1, f
+		case f < -tol:
+			return -1, f
+		}
+		return 0, f
+	}
+	for j, cur := range ps {
+		prev := ps[(j+len(ps)-1)%len(ps)]
+		sc, fc := side(cur)
+		sp, fp := side(prev)
+		if sc*sp < 0 {
+			s := fp / (fp - fc)
+			out = appendDistinct(out, Point{X: prev.X + s*(cur.X-prev.X), Y: prev.Y + s*(cur.Y-prev.Y)})
+		}
+		if sc <= 0 {
+			out = appendDistinct(out, cur)
+		}
+	}
+	if len(out) > 1 && out[0].Equal(out[len(out)-1]) {
+		out = out[:len(out)-1]
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestVoronoi(t *testing.T) {
+	r := Rect{0, 0, 4, 4}
+	tests := []struct {
+		name   string
+		points []Point
+		bounds []Rect
+	}{
+		{"two", []Point{{X: 1, Y: 1}, {X: 3, Y: 1}}, []Rect{{0, 0, 2, 4}, {2, 0, 4, 4}}},
+		// Four points on a circle, whose cells meet at one vertex.
+		{"square", []Point{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 3}, {X: 1, Y: 3}},
+			[]Rect{{0, 0, 2, 2}, {2, 0, 4, 2}, {2, 2, 4, 4}, {0, 2, 2, 4}}},
+		{"collinear", []Point{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}},
+			[]Rect{{0, 0, 3, 3}, {0, 0, 4, 4}, {1, 1, 4, 4}}},
+		{"duplicate", []Point{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 1, Y: 2}, EmptyPoint()},
+			[]Rect{{0, 0, 2, 4}, {2, 0, 4, 4}, EmptyRect(), EmptyRect()}},
+		{"outside", []Point{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 11, Y: 1}}, []Rect{{0, 0, 2, 4}, {2, 0, 4, 4}, EmptyRect()}},
+		{"one", []Point{{X: 9, Y: 9}}, []Rect{{0, 0, 4, 4}}},
+	}
+	for _, tt := range tests {
+		cells := Delaunay(tt.points).Voronoi(r)
+		if len(cells) != len(tt.bounds) {
+			t.Fatalf("%s: Voronoi() = %d cells, want %d", tt.name, len(cells), len(tt.bounds))
+		}
+		for i, c := range cells {
+			if b := Bounds(c); b != tt.bounds[i] {
+				t.Errorf("%s: cell %d = %v, want within %v", tt.name, i, c, tt.bounds[i])
+			}
+			if c.IsEmpty() {
+				continue
+			}
+			if len(c) != 1 || signedArea(c[0]) <= 0 || !c[0][0].Equal(c[0][len(c[0])-1]) {
+				t.Errorf("%s: cell %d = %v, want one closed counterclockwise ring", tt.name, i, c)
+			}
+		}
+	}
+	// A cell between bisectors at a slant.
+	cells := Delaunay([]Point{{X: 0, Y: 0}, {X: 4, Y: 4}}).Voronoi(r)
+	if a := polygonArea(cells[0]); a != 8 {
+		t.Errorf("cell below the diagonal has area %v, want 8", a)
+	}
+	for _, c := range Delaunay([]Point{{X: 1, Y: 1}, {X: 3, Y: 1}}).Voronoi(EmptyRect()) {
+		if !c.IsEmpty() {
+			t.Errorf("Voronoi(empty) = %v, want empty", c)
+		}
+	}
+}
+
+func TestVoronoiRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(74))
+	r := Rect{-10, -10, 110, 110}
+	for _, n := range []int{3, 20, 300} {
+		ps := make([]Point, n)
+		for i := range ps {
+			ps[i] = Point{X: rnd.Float64() * 100, Y: rnd.Float64() * 100}
+		}
+		cells := Delaunay(ps).Voronoi(r)
+		// The cells tile the rectangle.
+		a := 0.0
+		for i, c := range cells {
+			if c.IsEmpty() || !CoversPoint(c, ps[i]) {
+				t.Fatalf("%d points: cell %d %v does not cover %v", n, i, c, ps[i])
+			}
+			a += polygonArea(c)
+		}
+		if math.Abs(a-r.Area()) > 1e-9*r.Area() {
+			t.Errorf("%d points: cells have area %v, want %v", n, a, r.Area())
+		}
+		// Each point of the rectangle is in the cell of its nearest point.
+		for range 500 {
+			q := Point{X: r.MinX + rnd.Float64()*r.Width(), Y: r.MinY + rnd.Float64()*r.Height()}
+			near, d := 0, math.Inf(1)
+			for i, p := range ps {
+				if e := math.Hypot(p.X-q.X, p.Y-q.Y); e < d {
+					near, d = i, e
+				}
+			}
+			if !CoversPoint(cells[near], q) {
+				t.Fatalf("%d points: cell %d %v does not cover %v, nearest to %v", n, near, cells[near], q, ps[near])
+			}
+		}
+	}
+}
+
+func TestVoronoiGrid(t *testing.T) {
+	// Every vertex of a grid is shared by four cells, which the rounding
+	// tolerance must merge into one.
+	var ps []Point
+	for i := range 10 {
+		for j := range 10 {
+			ps = append(ps, Point{X: float64(i) + 0.5, Y: float64(j) + 0.5})
+		}
+	}
+	for i, c := range Delaunay(ps).Voronoi(Rect{0, 0, 10, 10}) {
+		if len(c) != 1 || len(c[0]) != 5 || polygonArea(c) != 1 {
+			t.Errorf("cell %d = %v, want a unit square", i, c)
+		}
+	}
+}
+
+func TestVoronoiWithin(t *testing.T) {
+	ps := []Point{{X: 3, Y: 3}, {X: 7, Y: 3}, {X: 3, Y: 7}, {X: 7, Y: 7}, {X: 30, Y: 30}}
+	cells := Delaunay(ps).VoronoiWithin(donut)
+	want := []float64{21, 25, 25, 25, 0}
+	a := 0.0
+	for i, c := range cells {
+		if got := polygonArea(c); math.Abs(got-want[i]) > 1e-9 {
+			t.Errorf("cell %d = %v, area %v, want %v", i, c, got, want[i])
+		}
+		a += polygonArea(c)
+	}
+	if a != 96 {
+		t.Errorf("cells have area %v, want 96", a)
+	}
+	if !cells[4].IsEmpty() {
+		t.Errorf("cell outside the polygon = %v, want empty", cells[4])
+	}
+}
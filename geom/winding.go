@@ -0,0 +1,147 @@
+package geom
+
+import "slices"
+
+// Winding is the direction in which a ring runs around the area it
+// bounds.
+type Winding int
+
+// The windings of rings. RFC 7946 requires GeoJSON polygons to have
+// counterclockwise exterior rings and clockwise holes, while shapefiles
+// use the opposite convention.
+const (
+	Counterclockwise Winding = iota + 1
+	Clockwise
+)
+
+// String returns "counterclockwise" or "clockwise".
+func (w Winding) String() string {
+	switch w {
+	case Counterclockwise:
+		return "counterclockwise"
+	case Clockwise:
+		return "clockwise"
+	}
+	return "Winding(?)"
+}
+
+// Reverse returns the opposite winding.
+func (w Winding) Reverse() Winding {
+	switch w {
+	case Counterclockwise:
+		return Clockwise
+	case Clockwise:
+		return Counterclockwise
+	}
+	return w
+}
+
+// Reverse returns a copy of the line string with its points in reverse
+// order.
+func (l LineString) Reverse() LineString {
+	l = slices.Clone(l)
+	slices.Reverse(l)
+	return l
+}
+
+// SignedArea returns the area enclosed by the ring, positive if the ring
+// runs counterclockwise and negative if it runs clockwise. The area of a
+// ring that crosses itself is the sum of the areas of its loops, each
+// signed by its own winding.
+func (r Ring) SignedArea() float64 {
+	return signedArea(r)
+}
+
+// Winding returns the direction in which the ring runs, by the sign of
+// its area, or zero if the ring encloses no area.
+func (r Ring) Winding() Winding {
+	switch a := r.SignedArea(); {
+	case a > 0:
+		return Counterclockwise
+	case a < 0:
+		return Clockwise
+	}
+	return 0
+}
+
+// Reverse returns a copy of the ring with its points in reverse order,
+// which runs around the same area in the opposite direction.
+func (r Ring) Reverse() Ring {
+	return Ring(LineString(r).Reverse())
+}
+
+// IsOriented reports whether every exterior ring of the polygons of a
+// geometry has the given winding and every hole the opposite winding.
+// Rings which enclose no area are ignored, and geometries without
+// polygons are always oriented. GeoJSON requires Counterclockwise
+// exterior rings.
+//
+// The complementary function Orient enforces the orientation.
+func IsOriented(g Geometry, exterior Winding) bool {
+	switch g := g.(type) {
+	case Polygon:
+		for i, r := range g {
+			if w := r.Winding(); w != 0 && w != ringWinding(i, exterior) {
+				return false
+			}
+		}
+	case MultiPolygon:
+		for _, p := range g {
+			if !IsOriented(p, exterior) {
+				return false
+			}
+		}
+	case GeometryCollection:
+		for _, m := range g {
+			if !IsOriented(m, exterior) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Orient returns a deep copy of a geometry in which the rings of every
+// polygon run in the directions required by IsOriented, reversing those
+// that do not. Rings which enclose no area are copied unchanged.
+func Orient(g Geometry, exterior Winding) Geometry {
+	switch g := g.(type) {
+	case Polygon:
+		return orientPolygon(g, exterior)
+	case MultiPolygon:
+		m := make(MultiPolygon, len(g))
+		for i, p := range g {
+			m[i] = orientPolygon(p, exterior)
+		}
+		return m
+	case GeometryCollection:
+		c := make(GeometryCollection, len(g))
+		for i, m := range g {
+			c[i] = Orient(m, exterior)
+		}
+		return c
+	}
+	return Clone(g)
+}
+
+// orientPolygon returns a copy of a polygon with its rings oriented.
+func orientPolygon(p Polygon, exterior Winding) Polygon {
+	q := make(Polygon, len(p))
+	for i, r := range p {
+		if w := r.Winding(); w != 0 && w != ringWinding(i, exterior) {
+			q[i] = r.Reverse()
+		} else {
+			q[i] = r.Clone()
+		}
+	}
+	return q
+}
+
+// ringWinding returns the winding required of the i'th ring of a polygon
+// whose exterior ring has the given winding.
+func ringWinding(i int, exterior Winding) Winding {
+	if i > 0 {
+		return exterior.Reverse()
+	}
+	return exterior
+}
@@ -0,0 +1,142 @@
+package geom
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestWinding(t *testing.T) {
+	tests := []struct {
+		w, reverse Winding
+		s          string
+	}{
+		{Counterclockwise, Clockwise, "counterclockwise"},
+		{Clockwise, Counterclockwise, "clockwise"},
+		{0, 0, "Winding(?)"},
+	}
+	for _, tt := range tests {
+		if s := tt.w.String(); s != tt.s {
+			t.Errorf("Winding(%d).String() = %q, want %q", int(tt.w), s, tt.s)
+		}
+		if r := tt.w.Reverse(); r != tt.reverse {
+			t.Errorf("%v.Reverse() = %v, want %v", tt.w, r, tt.reverse)
+		}
+	}
+}
+
+func TestRingWinding(t *testing.T) {
+	tests := []struct {
+		r    Ring
+		area float64
+		w    Winding
+	}{
+		{square[0], 1, Counterclockwise},
+		{ring(0, 0, 0, 2, 3, 2, 3, 0, 0, 0), -6, Clockwise},
+		// Unclosed rings are taken as closed.
+		{ring(0, 0, 4, 0, 0, 3), 6, Counterclockwise},
+		// A figure eight of two equal loops encloses no net area.
+		{ring(0, 0, 1, 1, 1, 0, 0, 1, 0, 0), 0, 0},
+		{ring(0, 0, 1, 1, 2, 2, 0, 0), 0, 0},
+		{ring(0, 0, 1, 1), 0, 0},
+		{nil, 0, 0},
+	}
+	for _, tt := range tests {
+		if a := tt.r.SignedArea(); a != tt.area {
+			t.Errorf("%v.SignedArea() = %v, want %v", tt.r, a, tt.area)
+		}
+		if w := tt.r.Winding(); w != tt.w {
+			t.Errorf("%v.Winding() = %v, want %v", tt.r, w, tt.w)
+		}
+		if a := tt.r.Reverse().SignedArea(); a != -tt.area {
+			t.Errorf("%v.Reverse().SignedArea() = %v, want %v", tt.r, a, -tt.area)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	l := LineString{{X: 1}, {X: 2}, {X: 3}}
+	r := l.Reverse()
+	if !slices.Equal(r, LineString{{X: 3}, {X: 2}, {X: 1}}) || l[0].X != 1 {
+		t.Errorf("%v.Reverse() = %v", l, r)
+	}
+	if r := LineString(nil).Reverse(); r != nil {
+		t.Errorf("nil.Reverse() = %v, want nil", r)
+	}
+}
+
+func TestIsOriented(t *testing.T) {
+	ccw, cw := ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0), ring(2, 2, 2, 8, 8, 8, 8, 2, 2, 2)
+	geojson := Polygon{ccw, cw}
+	shapefile := Polygon{ccw.Reverse(), cw.Reverse()}
+	flat := ring(20, 0, 21, 0, 22, 0, 20, 0)
+	tests := []struct {
+		g        Geometry
+		exterior Winding
+		oriented bool
+	}{
+		{geojson, Counterclockwise, true},
+		{geojson, Clockwise, false},
+		{shapefile, Clockwise, true},
+		{shapefile, Counterclockwise, false},
+		{Polygon{ccw, ccw}, Counterclockwise, false},
+		{MultiPolygon{geojson, shapefile}, Counterclockwise, false},
+		{MultiPolygon{geojson, {flat}}, Counterclockwise, true},
+		{GeometryCollection{Point{}, shapefile}, Clockwise, true},
+		{GeometryCollection{Point{}, shapefile}, Counterclockwise, false},
+		{LineString{{X: 1}, {X: 0}}, Counterclockwise, true},
+	}
+	for _, tt := range tests {
+		if o := IsOriented(tt.g, tt.exterior); o != tt.oriented {
+			t.Errorf("IsOriented(%v, %v) = %v, want %v", tt.g, tt.exterior, o, tt.oriented)
+		}
+		o := Orient(tt.g, tt.exterior)
+		if !IsOriented(o, tt.exterior) {
+			t.Errorf("Orient(%v, %v) = %v, not oriented", tt.g, tt.exterior, o)
+		}
+		if math.Abs(polygonArea(o)-polygonArea(tt.g)) > 0 {
+			t.Errorf("Orient(%v, %v) = %v, of a different area", tt.g, tt.exterior, o)
+		}
+	}
+	o := Orient(shapefile, Counterclockwise).(Polygon)
+	if !slices.Equal(o[0], ccw) || !slices.Equal(o[1], cw) {
+		t.Errorf("Orient(shapefile) = %v, want %v", o, geojson)
+	}
+	// The result is a copy, even of rings left unchanged.
+	o = Orient(geojson, Counterclockwise).(Polygon)
+	o[0][0].X = 99
+	if geojson[0][0].X != 0 {
+		t.Errorf("Orient() shares storage with its argument")
+	}
+	m := Orient(MultiPolygon{{flat}}, Clockwise).(MultiPolygon)
+	if !slices.Equal(m[0][0], flat) {
+		t.Errorf("Orient(flat) = %v, want %v", m, flat)
+	}
+}
+
+func TestIsOrientedRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(63))
+	for range 1000 {
+		// A star-shaped ring around the origin, in either direction.
+		n := 3 + r.Intn(20)
+		rg := make(Ring, n+1)
+		for i := range n {
+			a := 2 * math.Pi * float64(i) / float64(n)
+			d := 1 + r.Float64()
+			rg[i] = Point{X: d * math.Cos(a), Y: d * math.Sin(a)}
+		}
+		rg[n] = rg[0]
+		if rg.Winding() != Counterclockwise {
+			t.Fatalf("%v.Winding() = %v, want counterclockwise", rg, rg.Winding())
+		}
+		if r.Intn(2) == 0 {
+			rg = rg.Reverse()
+		}
+		for _, w := range []Winding{Counterclockwise, Clockwise} {
+			if o := Orient(Polygon{rg}, w).(Polygon); o[0].Winding() != w {
+				t.Fatalf("Orient(%v, %v) = %v", rg, w, o)
+			}
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package geoparquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// The physical types of Parquet.
+const (
+	typeBoolean = iota
+	typeInt32
+	typeInt64
+	typeInt96
+	typeFloat
+	typeDouble
+	typeByteArray
+	typeFixedLenByteArray
+)
+
+// kind is the kind of the values of a column, into which its physical
+// values are converted.
+type kind int
+
+const (
+	kindPlain       kind = iota // The physical values
+	kindString                  // Strings of byte arrays
+	kindUnsigned                // uint32 or uint64 of integers
+	kindDate                    // time.Time of days since the epoch
+	kindMillis                  // time.Time of milliseconds since the epoch
+	kindMicros                  // time.Time of microseconds since the epoch
+	kindNanos                   // time.Time of nanoseconds since the epoch
+	kindGeometry                // WKB, which is decoded later
+	kindCovering                // Not read
+	kindUnsupported             // Not read
+)
+
+// column is a leaf column of the schema of a file.
+type column struct {
+	path []string
+	// name is the path joined by dots.
+	name string
+	typ  int64
+	// length is the length of fixed-length byte arrays.
+	length int
+	// maxDef is the maximum definition level, that of a value which is
+	// not null, the number of optional columns and structures of the
+	// path.
+	maxDef int
+	kind   kind
+}
+
+// schema returns the leaf columns of a schema, the elements of a tree in
+// depth-first order.
+func schema(elements []tstruct) ([]*column, error) {
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("%w: no schema", ErrInvalid)
+	}
+	var columns []*column
+	i := 1
+	var walk func(n int, path []string, def int, repeated bool) error
+	walk = func(n int, path []string, def int, repeated bool) error {
+		for range n {
+			if i >= len(elements) {
+				return fmt.Errorf("%w: schema ends early", ErrInvalid)
+			}
+			e := elements[i]
+			i++
+			p := append(path[:len(path):len(path)], e.string(4))
+			d, rep := def, repeated
+			switch e.int(3) {
+			case 1: // Optional
+				d++
+			case 2: // Repeated
+				d++
+				rep = true
+			}
+			if children := e.int(5); children > 0 {
+				if children > int64(len(elements)) || len(p) > maxDepth {
+					return fmt.Errorf("%w: schema of %d children", ErrInvalid, children)
+				}
+				if err := walk(int(children), p, d, rep); err != nil {
+					return err
+				}
+				continue
+			}
+			c := &column{path: p, name: strings.Join(p, "."), typ: e.int(1), length: int(e.int(2)), maxDef: d}
+			c.kind = columnKind(e)
+			if rep || c.typ > typeFixedLenByteArray || c.typ < 0 {
+				c.kind = kindUnsupported
+			}
+			columns = append(columns, c)
+		}
+		return nil
+	}
+	return columns, walk(int(elements[0].int(5)), nil, 0, false)
+}
+
+// columnKind returns the kind of a leaf column, by its logical type, or
+// if it has none its converted type.
+func columnKind(e tstruct) kind {
+	typ := e.int(1)
+	if lt := e.child(10); lt != nil {
+		switch {
+		case lt[1] != nil, lt[4] != nil, lt[12] != nil: // String, enum, JSON
+			return kindString
+		case lt[6] != nil:
+			return kindDate
+		case lt[8] != nil && typ == typeInt64: // Timestamp
+			switch unit := lt.child(8).child(2); {
+			case unit[1] != nil:
+				return kindMillis
+			case unit[2] != nil:
+				return kindMicros
+			case unit[3] != nil:
+				return kindNanos
+			}
+		case lt[10] != nil && !lt.child(10).bool(2): // Unsigned integer
+			return kindUnsigned
+		}
+		return kindPlain
+	}
+	if _, ok := e[6]; !ok {
+		return kindPlain
+	}
+	switch e.int(6) {
+	case 0, 4, 19: // UTF8, ENUM, JSON
+		return kindString
+	case 6:
+		return kindDate
+	case 9:
+		return kindMillis
+	case 10:
+		return kindMicros
+	case 11, 12, 13, 14: // UINT_8 to UINT_64
+		return kindUnsigned
+	}
+	return kindPlain
+}
+
+// The types of pages.
+const (
+	pageData       = 0
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+// The encodings of values.
+const (
+	encodingPlain    = 0
+	encodingPlainDic = 2
+	encodingRLE      = 3
+	encodingRLEDic   = 8
+)
+
+// codecs are the names of the compressions of Parquet.
+var codecs = []string{"UNCOMPRESSED", "SNAPPY", "GZIP", "LZO", "BROTLI", "LZ4", "ZSTD", "LZ4_RAW"}
+
+// read reads the values of the rows of a column chunk, compressed with a
+// codec, nil for the null values.
+func (c *column) read(b []byte, codec int64, rows int) ([]any, error) {
+	var dict []any
+	values := make([]any, 0, min(rows, 1<<16))
+	for len(values) < rows {
+		if len(b) == 0 {
+			return nil, fmt.Errorf("%w: %d values of %d rows", ErrInvalid, len(values), rows)
+		}
+		h, n, err := decodeStruct(b)
+		if err != nil {
+			return nil, fmt.Errorf("%w: page header", err)
+		}
+		b = b[n:]
+		size, usize := h.int(3), h.int(2)
+		if size < 0 || size > int64(len(b)) || usize < 0 || usize > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: page of %d bytes in %d", ErrInvalid, size, len(b))
+		}
+		page := b[:size]
+		b = b[size:]
+		switch h.int(1) {
+		case pageDictionary:
+			data, err := decompress(codec, page, int(usize))
+			if err != nil {
+				return nil, err
+			}
+			if dict, err = c.plain(data, int(h.child(7).int(1))); err != nil {
+				return nil, err
+			}
+		case pageData:
+			dh := h.child(5)
+			n := dh.int(1)
+			if n < 0 || n > int64(rows-len(values)) {
+				return nil, fmt.Errorf("%w: page of %d values", ErrInvalid, n)
+			}
+			data, err := decompress(codec, page, int(usize))
+			if err != nil {
+				return nil, err
+			}
+			var defs []int
+			if c.maxDef > 0 {
+				// The definition levels are prefixed with their size.
+				if len(data) < 4 || binary.LittleEndian.Uint32(data) > uint32(len(data)-4) {
+					return nil, fmt.Errorf("%w: definition levels beyond the page", ErrInvalid)
+				}
+				m := 4 + int(binary.LittleEndian.Uint32(data))
+				if defs, err = levels(data[4:m], bits.Len(uint(c.maxDef)), int(n)); err != nil {
+					return nil, err
+				}
+				data = data[m:]
+			}
+			if values, err = c.values(values, data, dh.int(2), int(n), defs, dict); err != nil {
+				return nil, err
+			}
+		case pageDataV2:
+			dh := h.child(8)
+			n, rl, dl := dh.int(1), dh.int(6), dh.int(5)
+			if n < 0 || n > int64(rows-len(values)) {
+				return nil, fmt.Errorf("%w: page of %d values", ErrInvalid, n)
+			}
+			if rl < 0 || dl < 0 || rl+dl > size || rl+dl > usize {
+				return nil, fmt.Errorf("%w: levels of %d bytes in a page of %d", ErrInvalid, rl+dl, size)
+			}
+			var defs []int
+			var err error
+			if c.maxDef > 0 {
+				if defs, err = levels(page[rl:rl+dl], bits.Len(uint(c.maxDef)), int(n)); err != nil {
+					return nil, err
+				}
+			}
+			// The levels are not compressed, and the values may not be.
+			data := page[rl+dl:]
+			if compressed, ok := dh[7].(bool); !ok || compressed {
+				if data, err = decompress(codec, data, int(usize-rl-dl)); err != nil {
+					return nil, err
+				}
+			}
+			if values, err = c.values(values, data, dh.int(4), int(n), defs, dict); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+// decompress decompresses a page of a codec, of size bytes.
+func decompress(codec int64, src []byte, size int) ([]byte, error) {
+	switch codec {
+	case 0:
+		return src, nil
+	case 1:
+		return unsnappy(src, size)
+	case 2:
+		zr, err := gzip.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, fmt.Errorf("%w: gzip page: %w", ErrInvalid, err)
+		}
+		b, err := io.ReadAll(io.LimitReader(zr, int64(size)+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: gzip page: %w", ErrInvalid, err)
+		}
+		if len(b) != size {
+			return nil, fmt.Errorf("%w: gzip page of %d bytes, not %d", ErrInvalid, len(b), size)
+		}
+		return b, nil
+	}
+	name := fmt.Sprint(codec)
+	if codec > 0 && codec < int64(len(codecs)) {
+		name = codecs[codec]
+	}
+	return nil, fmt.Errorf("%w: compression %s", ErrUnsupported, name)
+}
+
+// levels decodes n levels of a width in the hybrid of run-length and
+// bit-packed encodings.
+func levels(b []byte, width, n int) ([]int, error) {
+	if width > 32 {
+		return nil, fmt.Errorf("%w: levels of %d bits", ErrInvalid, width)
+	}
+	vs := make([]int, 0, min(n, 1<<16))
+	for len(vs) < n {
+		h, i := binary.Uvarint(b)
+		if i <= 0 {
+			return nil, fmt.Errorf("%w: levels end after %d of %d", ErrInvalid, len(vs), n)
+		}
+		b = b[i:]
+		if h&1 == 0 {
+			// A run of a value.
+			m := (width + 7) / 8
+			if len(b) < m {
+				return nil, fmt.Errorf("%w: truncated levels", ErrInvalid)
+			}
+			v := 0
+			for j := range m {
+				v |= int(b[j]) << (8 * j)
+			}
+			b = b[m:]
+			for range min(h>>1, uint64(n-len(vs))) {
+				vs = append(vs, v)
+			}
+			continue
+		}
+		// Groups of eight bit-packed values.
+		groups := h >> 1
+		if groups > uint64(len(b)) || int(groups)*width > len(b) {
+			return nil, fmt.Errorf("%w: truncated levels", ErrInvalid)
+		}
+		m := int(groups) * width
+		for i := 0; i < 8*int(groups) && len(vs) < n; i++ {
+			v := 0
+			for j := range width {
+				bit := i*width + j
+				v |= int(b[bit/8]>>(bit%8)&1) << j
+			}
+			vs = append(vs, v)
+		}
+		b = b[m:]
+	}
+	return vs, nil
+}
+
+// values appends the n values of a page, of which those whose definition
+// levels are less than the maximum are null, in an encoding.
+func (c *column) values(values []any, b []byte, encoding int64, n int, defs []int, dict []any) ([]any, error) {
+	present := n
+	if defs != nil {
+		present = 0
+		for _, d := range defs {
+			if d == c.maxDef {
+				present++
+			}
+		}
+	}
+	var vs []any
+	var err error
+	switch encoding {
+	case encodingPlain:
+		vs, err = c.plain(b, present)
+	case encodingPlainDic, encodingRLEDic:
+		if len(b) == 0 && present > 0 {
+			return nil, fmt.Errorf("%w: no dictionary indexes", ErrInvalid)
+		}
+		var is []int
+		if present > 0 {
+			if is, err = levels(b[1:], int(b[0]), present); err != nil {
+				return nil, err
+			}
+		}
+		vs = make([]any, len(is))
+		for i, j := range is {
+			if j >= len(dict) {
+				return nil, fmt.Errorf("%w: index %d of a dictionary of %d", ErrInvalid, j, len(dict))
+			}
+			vs[i] = dict[j]
+		}
+	case encodingRLE:
+		if c.typ != typeBoolean || len(b) < 4 {
+			return nil, fmt.Errorf("%w: run-length encoding of type %d", ErrUnsupported, c.typ)
+		}
+		var bs []int
+		if bs, err = levels(b[4:], 1, present); err != nil {
+			return nil, err
+		}
+		vs = make([]any, len(bs))
+		for i, v := range bs {
+			vs[i] = v == 1
+		}
+	default:
+		return nil, fmt.Errorf("%w: encoding %d", ErrUnsupported, encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if defs == nil {
+		return append(values, vs...), nil
+	}
+	for _, d := range defs {
+		if d == c.maxDef {
+			values = append(values, vs[0])
+			vs = vs[1:]
+		} else {
+			values = append(values, nil)
+		}
+	}
+	return values, nil
+}
+
+// plain decodes n values in the plain encoding.
+func (c *column) plain(b []byte, n int) ([]any, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: %d values", ErrInvalid, n)
+	}
+	size := map[int64]int{typeInt32: 4, typeInt64: 8, typeInt96: 12, typeFloat: 4, typeDouble: 8, typeFixedLenByteArray: c.length}[c.typ]
+	if c.typ == typeBoolean {
+		if n > 8*len(b) {
+			return nil, fmt.Errorf("%w: %d booleans in %d bytes", ErrInvalid, n, len(b))
+		}
+	} else if c.typ != typeByteArray && (size <= 0 || n > len(b)/size) {
+		return nil, fmt.Errorf("%w: %d values of %d bytes in %d bytes", ErrInvalid, n, size, len(b))
+	}
+	vs := make([]any, 0, min(n, len(b)))
+	for i := range n {
+		var v any
+		switch c.typ {
+		case typeBoolean:
+			v = b[i/8]>>(i%8)&1 == 1
+		case typeInt32:
+			v = int32(binary.LittleEndian.Uint32(b[4*i:]))
+		case typeInt64:
+			v = int64(binary.LittleEndian.Uint64(b[8*i:]))
+		case typeInt96:
+			// Nanoseconds of the day and the Julian day of a timestamp.
+			nanos := int64(binary.LittleEndian.Uint64(b[12*i:]))
+			day := int64(binary.LittleEndian.Uint32(b[12*i+8:]))
+			v = time.Unix((day-2440588)*86400, nanos).UTC()
+		case typeFloat:
+			v = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+		case typeDouble:
+			v = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+		case typeByteArray:
+			if len(b) < 4 || binary.LittleEndian.Uint32(b) > uint32(len(b)-4) {
+				return nil, fmt.Errorf("%w: byte array beyond the page", ErrInvalid)
+			}
+			m := 4 + int(binary.LittleEndian.Uint32(b))
+			v = b[4:m:m]
+			b = b[m:]
+		case typeFixedLenByteArray:
+			v = b[size*i : size*(i+1) : size*(i+1)]
+		}
+		vs = append(vs, c.convert(v))
+	}
+	return vs, nil
+}
+
+// convert converts a physical value to the kind of the column.
+func (c *column) convert(v any) any {
+	switch c.kind {
+	case kindString:
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+	case kindUnsigned:
+		switch v := v.(type) {
+		case int32:
+			return uint32(v)
+		case int64:
+			return uint64(v)
+		}
+	case kindDate:
+		if d, ok := v.(int32); ok {
+			return time.Unix(int64(d)*86400, 0).UTC()
+		}
+	case kindMillis, kindMicros, kindNanos:
+		if t, ok := v.(int64); ok {
+			switch c.kind {
+			case kindMillis:
+				return time.UnixMilli(t).UTC()
+			case kindMicros:
+				return time.UnixMicro(t).UTC()
+			}
+			return time.Unix(0, t).UTC()
+		}
+	}
+	return v
+}
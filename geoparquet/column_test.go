@@ -0,0 +1,323 @@
+package geoparquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		name     string
+		b        string
+		width, n int
+		want     []int
+	}{
+		// The example of the bit-packed encoding of the specification.
+		{"bit-packed", "03 88C6FA", 3, 8, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"bit-packed, fewer", "03 88C6FA", 3, 5, []int{0, 1, 2, 3, 4}},
+		{"run", "0A 01", 1, 5, []int{1, 1, 1, 1, 1}},
+		{"run, fewer", "0A 01", 1, 2, []int{1, 1}},
+		{"wide run", "06 2C01", 9, 3, []int{300, 300, 300}},
+		{"run of width 0", "08", 0, 4, []int{0, 0, 0, 0}},
+		{"runs and bit-packed", "04 01 03 55 06 00", 1, 13, []int{1, 1, 1, 0, 1, 0, 1, 0, 1, 0, 0, 0, 0}},
+		{"none", "", 1, 0, []int{}},
+	}
+	for _, tt := range tests {
+		got, err := levels(unhex(tt.b), tt.width, tt.n)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: levels(%s, %d, %d) = %v, %v, want %v", tt.name, tt.b, tt.width, tt.n, got, err, tt.want)
+		}
+	}
+}
+
+func TestLevelsInvalid(t *testing.T) {
+	tests := []struct {
+		b        string
+		width, n int
+		msg      string
+	}{
+		{"", 1, 3, "geoparquet: invalid GeoParquet: levels end after 0 of 3"},
+		{"04 01", 1, 3, "geoparquet: invalid GeoParquet: levels end after 2 of 3"},
+		{"02", 8, 1, "geoparquet: invalid GeoParquet: truncated levels"},
+		{"02 01", 9, 1, "geoparquet: invalid GeoParquet: truncated levels"},
+		{"03 88", 3, 8, "geoparquet: invalid GeoParquet: truncated levels"},
+		{"FFFFFFFF0F", 1, 8, "geoparquet: invalid GeoParquet: truncated levels"},
+		{"02 00", 33, 1, "geoparquet: invalid GeoParquet: levels of 33 bits"},
+	}
+	for _, tt := range tests {
+		got, err := levels(unhex(tt.b), tt.width, tt.n)
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("levels(%s, %d, %d) = %v, %v, want error %q", tt.b, tt.width, tt.n, got, err, tt.msg)
+		}
+	}
+}
+
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		c    column
+		b    string
+		n    int
+		want []any
+	}{
+		{column{typ: typeBoolean}, "0D", 5, []any{true, false, true, true, false}},
+		{column{typ: typeInt32}, "01000000 FFFFFFFF", 2, []any{int32(1), int32(-1)}},
+		{column{typ: typeInt64}, "0000000000000080", 1, []any{int64(math.MinInt64)}},
+		{column{typ: typeFloat}, "0000C03F", 1, []any{float32(1.5)}},
+		{column{typ: typeDouble}, "000000000000F0BF", 1, []any{-1.0}},
+		{column{typ: typeByteArray}, "03000000 616263 00000000", 2, []any{[]byte("abc"), []byte{}}},
+		{column{typ: typeFixedLenByteArray, length: 2}, "0102 0304", 2, []any{[]byte{1, 2}, []byte{3, 4}}},
+		{column{typ: typeInt96}, "0000000000000000 8C3D2500", 1, []any{time.Unix(0, 0).UTC()}},
+		{column{typ: typeInt96}, "00A0B83046030000 8D3D2500", 1, []any{time.Date(1970, 1, 2, 1, 0, 0, 0, time.UTC)}},
+		{column{typ: typeByteArray, kind: kindString}, "02000000 C3A9", 1, []any{"é"}},
+		{column{typ: typeInt32, kind: kindUnsigned}, "FFFFFFFF", 1, []any{uint32(math.MaxUint32)}},
+		{column{typ: typeInt64, kind: kindUnsigned}, "FFFFFFFFFFFFFFFF", 1, []any{uint64(math.MaxUint64)}},
+		{column{typ: typeInt32, kind: kindDate}, "4F4B0000", 1, []any{time.Date(2022, 10, 14, 0, 0, 0, 0, time.UTC)}},
+		{column{typ: typeInt64, kind: kindMillis}, "E803000000000000", 1, []any{time.Unix(1, 0).UTC()}},
+		{column{typ: typeInt64, kind: kindMicros}, "E803000000000000", 1, []any{time.Unix(0, 1e6).UTC()}},
+		{column{typ: typeInt64, kind: kindNanos}, "E803000000000000", 1, []any{time.Unix(0, 1000).UTC()}},
+		{column{typ: typeInt32, kind: kindMillis}, "E8030000", 1, []any{int32(1000)}},
+		{column{typ: typeDouble}, "", 0, []any{}},
+	}
+	for _, tt := range tests {
+		got, err := tt.c.plain(unhex(tt.b), tt.n)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("plain(type %d kind %d, %s, %d) = %v, %v, want %v", tt.c.typ, tt.c.kind, tt.b, tt.n, got, err, tt.want)
+		}
+	}
+}
+
+func TestPlainInvalid(t *testing.T) {
+	tests := []struct {
+		c   column
+		b   string
+		n   int
+		msg string
+	}{
+		{column{typ: typeBoolean}, "0D", 9, "geoparquet: invalid GeoParquet: 9 booleans in 1 bytes"},
+		{column{typ: typeInt32}, "010000", 1, "geoparquet: invalid GeoParquet: 1 values of 4 bytes in 3 bytes"},
+		{column{typ: typeInt96}, "00", 1, "geoparquet: invalid GeoParquet: 1 values of 12 bytes in 1 bytes"},
+		{column{typ: typeFixedLenByteArray}, "00", 1, "geoparquet: invalid GeoParquet: 1 values of 0 bytes in 1 bytes"},
+		{column{typ: typeByteArray}, "03000000 6162", 1, "geoparquet: invalid GeoParquet: byte array beyond the page"},
+		{column{typ: typeByteArray}, "010000", 1, "geoparquet: invalid GeoParquet: byte array beyond the page"},
+		{column{typ: typeDouble}, "", -1, "geoparquet: invalid GeoParquet: -1 values"},
+	}
+	for _, tt := range tests {
+		got, err := tt.c.plain(unhex(tt.b), tt.n)
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("plain(type %d, %s, %d) = %v, %v, want error %q", tt.c.typ, tt.b, tt.n, got, err, tt.msg)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	dict := []any{"a", "b", "c"}
+	tests := []struct {
+		name     string
+		c        column
+		b        string
+		encoding int64
+		n        int
+		defs     []int
+		want     []any
+	}{
+		{"plain", column{typ: typeInt32}, "01000000 02000000", encodingPlain, 2, nil, []any{int32(1), int32(2)}},
+		{
+			"plain with nulls", column{typ: typeInt32, maxDef: 1}, "01000000 02000000", encodingPlain, 4, []int{0, 1, 0, 1},
+			[]any{nil, int32(1), nil, int32(2)},
+		},
+		{"dictionary", column{typ: typeByteArray}, "02 03 2400", encodingRLEDic, 4, nil, []any{"a", "b", "c", "a"}},
+		{"old dictionary", column{typ: typeByteArray}, "02 04 02", encodingPlainDic, 2, nil, []any{"c", "c"}},
+		{
+			"dictionary with nulls", column{typ: typeByteArray, maxDef: 2}, "02 03 0900", encodingRLEDic, 3, []int{2, 1, 2},
+			[]any{"b", nil, "c"},
+		},
+		{"all null", column{typ: typeByteArray, maxDef: 1}, "", encodingRLEDic, 2, []int{0, 0}, []any{nil, nil}},
+		{"run-length booleans", column{typ: typeBoolean}, "02000000 03 05", encodingRLE, 3, nil, []any{true, false, true}},
+	}
+	for _, tt := range tests {
+		got, err := tt.c.values([]any{"before"}, unhex(tt.b), tt.encoding, tt.n, tt.defs, dict)
+		if want := append([]any{"before"}, tt.want...); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: values = %v, %v, want %v", tt.name, got, err, want)
+		}
+	}
+}
+
+func TestValuesInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		c        column
+		b        string
+		encoding int64
+		err      error
+		msg      string
+	}{
+		{"no indexes", column{typ: typeByteArray}, "", encodingRLEDic, ErrInvalid, "geoparquet: invalid GeoParquet: no dictionary indexes"},
+		{"index beyond", column{typ: typeByteArray}, "02 02 03", encodingRLEDic, ErrInvalid, "geoparquet: invalid GeoParquet: index 3 of a dictionary of 3"},
+		{"truncated indexes", column{typ: typeByteArray}, "02", encodingRLEDic, ErrInvalid, "geoparquet: invalid GeoParquet: levels end after 0 of 1"},
+		{"run-length integers", column{typ: typeInt32}, "00000000", encodingRLE, ErrUnsupported, "geoparquet: unsupported GeoParquet: run-length encoding of type 1"},
+		{"delta", column{typ: typeInt32}, "00000000", 5, ErrUnsupported, "geoparquet: unsupported GeoParquet: encoding 5"},
+		{"short plain", column{typ: typeInt32}, "0000", encodingPlain, ErrInvalid, "geoparquet: invalid GeoParquet: 1 values of 4 bytes in 2 bytes"},
+	}
+	for _, tt := range tests {
+		got, err := tt.c.values(nil, unhex(tt.b), tt.encoding, 1, nil, []any{"a", "b", "c"})
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, tt.err) {
+			t.Errorf("%s: values = %v, %v, want error %q", tt.name, got, err, tt.msg)
+		}
+	}
+}
+
+func TestColumnKind(t *testing.T) {
+	tests := []struct {
+		e    []tf
+		want kind
+	}{
+		{[]tf{{1, int32(typeInt32)}}, kindPlain},
+		{[]tf{{1, int32(typeByteArray)}, {6, int32(0)}}, kindString},
+		{[]tf{{1, int32(typeByteArray)}, {6, int32(19)}}, kindString},
+		{[]tf{{1, int32(typeInt32)}, {6, int32(6)}}, kindDate},
+		{[]tf{{1, int32(typeInt64)}, {6, int32(9)}}, kindMillis},
+		{[]tf{{1, int32(typeInt64)}, {6, int32(10)}}, kindMicros},
+		{[]tf{{1, int32(typeInt32)}, {6, int32(13)}}, kindUnsigned},
+		{[]tf{{1, int32(typeInt32)}, {6, int32(15)}}, kindPlain}, // INT_8
+		{[]tf{{1, int32(typeByteArray)}, {10, []tf{{1, []tf{}}}}}, kindString},
+		{[]tf{{1, int32(typeByteArray)}, {10, []tf{{4, []tf{}}}}}, kindString},
+		{[]tf{{1, int32(typeByteArray)}, {10, []tf{{12, []tf{}}}}}, kindString},
+		{[]tf{{1, int32(typeInt32)}, {10, []tf{{6, []tf{}}}}}, kindDate},
+		{[]tf{{1, int32(typeInt64)}, {10, []tf{{8, []tf{{1, true}, {2, []tf{{1, []tf{}}}}}}}}}, kindMillis},
+		{[]tf{{1, int32(typeInt64)}, {10, []tf{{8, []tf{{1, true}, {2, []tf{{2, []tf{}}}}}}}}}, kindMicros},
+		{[]tf{{1, int32(typeInt64)}, {10, []tf{{8, []tf{{1, false}, {2, []tf{{3, []tf{}}}}}}}}}, kindNanos},
+		{[]tf{{1, int32(typeInt96)}, {10, []tf{{8, []tf{{2, []tf{{3, []tf{}}}}}}}}}, kindPlain},
+		{[]tf{{1, int32(typeInt32)}, {10, []tf{{10, []tf{{1, int64(32)}, {2, false}}}}}}, kindUnsigned},
+		{[]tf{{1, int32(typeInt32)}, {10, []tf{{10, []tf{{1, int64(32)}, {2, true}}}}}}, kindPlain},
+		{[]tf{{1, int32(typeByteArray)}, {10, []tf{{13, []tf{}}}}}, kindPlain}, // BSON
+	}
+	for _, tt := range tests {
+		e, _, err := decodeStruct(thrift(tt.e...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := columnKind(e); got != tt.want {
+			t.Errorf("columnKind(%v) = %d, want %d", e, got, tt.want)
+		}
+	}
+}
+
+// element returns a schema element of a name, a type, a repetition and a
+// number of children, those of groups having no type.
+func element(name string, typ, repetition int32, children int32) []tf {
+	e := []tf{{3, repetition}, {4, name}}
+	if children > 0 {
+		return append(e, tf{5, children})
+	}
+	return append([]tf{{1, typ}}, e...)
+}
+
+// decodeSchema decodes the elements of a schema.
+func decodeSchema(t *testing.T, elements ...[]tf) []tstruct {
+	t.Helper()
+	var es []tstruct
+	for _, e := range elements {
+		s, _, err := decodeStruct(thrift(e...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		es = append(es, s)
+	}
+	return es
+}
+
+func TestSchema(t *testing.T) {
+	es := decodeSchema(t,
+		element("schema", 0, 0, 4),
+		element("geometry", typeByteArray, 1, 0),
+		element("bbox", 0, 1, 2),
+		element("xmin", typeDouble, 0, 0),
+		element("ymin", typeFloat, 1, 0),
+		element("tags", 0, 2, 1),
+		element("tag", typeByteArray, 0, 0),
+		element("id", typeInt64, 0, 0),
+	)
+	got, err := schema(es)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*column{
+		{path: []string{"geometry"}, name: "geometry", typ: typeByteArray, maxDef: 1},
+		{path: []string{"bbox", "xmin"}, name: "bbox.xmin", typ: typeDouble, maxDef: 1},
+		{path: []string{"bbox", "ymin"}, name: "bbox.ymin", typ: typeFloat, maxDef: 2},
+		{path: []string{"tags", "tag"}, name: "tags.tag", typ: typeByteArray, maxDef: 1, kind: kindUnsupported},
+		{path: []string{"id"}, name: "id", typ: typeInt64},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Errorf("column %d = %+v", i, got[i])
+		}
+		t.Errorf("want %+v", want)
+	}
+}
+
+func TestSchemaInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements [][]tf
+		msg      string
+	}{
+		{"none", nil, "geoparquet: invalid GeoParquet: no schema"},
+		{"ends early", [][]tf{element("schema", 0, 0, 2), element("a", typeInt32, 0, 0)}, "geoparquet: invalid GeoParquet: schema ends early"},
+		{
+			"too many children", [][]tf{element("schema", 0, 0, 1), element("a", 0, 0, 100)},
+			"geoparquet: invalid GeoParquet: schema of 100 children",
+		},
+	}
+	for _, tt := range tests {
+		cs, err := schema(decodeSchema(t, tt.elements...))
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: schema = %v, %v, want error %q", tt.name, cs, err, tt.msg)
+		}
+	}
+	// A schema of unknown types has unsupported columns.
+	cs, err := schema(decodeSchema(t, element("schema", 0, 0, 1), element("a", 8, 0, 0)))
+	if err != nil || len(cs) != 1 || cs[0].kind != kindUnsupported {
+		t.Errorf("schema of an unknown type = %v, %v, want an unsupported column", cs, err)
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	want := bytes.Repeat([]byte("GeoParquet "), 50)
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(want)
+	zw.Close()
+	for codec, src := range map[int64][]byte{0: want, 1: snappy(want), 2: gz.Bytes()} {
+		if got, err := decompress(codec, src, len(want)); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("decompress of codec %d = %q, %v, want %q", codec, got, err, want)
+		}
+	}
+	tests := []struct {
+		codec int64
+		src   []byte
+		size  int
+		err   error
+		msg   string
+	}{
+		{2, gz.Bytes(), len(want) - 1, ErrInvalid, "geoparquet: invalid GeoParquet: gzip page of 550 bytes, not 549"},
+		{2, gz.Bytes(), len(want) + 1, ErrInvalid, "geoparquet: invalid GeoParquet: gzip page of 550 bytes, not 551"},
+		{2, []byte("not a gzip stream"), 1, ErrInvalid, "geoparquet: invalid GeoParquet: gzip page: gzip: invalid header"},
+		{2, gz.Bytes()[:20], len(want), ErrInvalid, "geoparquet: invalid GeoParquet: gzip page: unexpected EOF"},
+		{6, want, len(want), ErrUnsupported, "geoparquet: unsupported GeoParquet: compression ZSTD"},
+		{3, want, len(want), ErrUnsupported, "geoparquet: unsupported GeoParquet: compression LZO"},
+		{99, want, len(want), ErrUnsupported, "geoparquet: unsupported GeoParquet: compression 99"},
+		{-1, want, len(want), ErrUnsupported, "geoparquet: unsupported GeoParquet: compression -1"},
+	}
+	for _, tt := range tests {
+		got, err := decompress(tt.codec, tt.src, tt.size)
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, tt.err) {
+			t.Errorf("decompress(%d, %d bytes, %d) = %d bytes, %v, want error %q", tt.codec, len(tt.src), tt.size, len(got), err, tt.msg)
+		}
+	}
+}
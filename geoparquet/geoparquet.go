@@ -0,0 +1,461 @@
+// Package geoparquet reads GeoParquet, the Parquet files of geographic
+// features, in which each feature is a row whose geometry is a column of
+// WKB.
+//
+// A GeoParquet file is a Parquet file whose metadata has a "geo" key,
+// holding JSON which names the columns of geometries and describes them.
+// Its rows are stored in row groups, and each row group in chunks of its
+// columns, so that a reader reads only the row groups it needs. A file
+// may have a covering, columns of the bounding boxes of the geometries,
+// or the Parquet statistics of its geometries, from either of which File
+// learns the bounds of each row group and Search skips those which are
+// not in a rectangle.
+//
+// This package has its own Parquet reader, which reads the columns of
+// primitive values, whether required, optional or in structures, but
+// not lists or maps, in the plain and dictionary encodings, which are
+// those of most files, either uncompressed or compressed with Snappy or
+// gzip. It does not read the other encodings of Parquet, or its other
+// compressions, such as ZSTD.
+//
+// The format is described at https://geoparquet.org and that of Parquet
+// at https://parquet.apache.org/docs/file-format/.
+package geoparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"strings"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/wkb"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, when a file is not valid
+	// GeoParquet.
+	ErrInvalid = errors.New("geoparquet: invalid GeoParquet")
+
+	// ErrUnsupported is returned, wrapped, when a file has a geometry
+	// encoding other than WKB, or a column in an encoding or compression
+	// which this package does not read.
+	ErrUnsupported = errors.New("geoparquet: unsupported GeoParquet")
+)
+
+// magic is the magic number at the start and end of Parquet files.
+var magic = []byte("PAR1")
+
+// Metadata is the geo metadata of a file.
+type Metadata struct {
+	// Version is the version of GeoParquet, such as "1.1.0".
+	Version string
+
+	// PrimaryColumn is the name of the column of the geometries of the
+	// features.
+	PrimaryColumn string
+
+	// Columns are the columns of geometries, by name.
+	Columns map[string]*GeometryColumn
+}
+
+// GeometryColumn is the metadata of a column of geometries.
+type GeometryColumn struct {
+	// Encoding is the encoding of the geometries, "WKB" for those which
+	// this package reads.
+	Encoding string
+
+	// GeometryTypes are the types of the geometries, such as "Polygon"
+	// or "Point Z", or empty if they may be of any type.
+	GeometryTypes []string
+
+	// CRS is the PROJJSON of the coordinate system of the geometries, nil
+	// if it is absent, which is OGC:CRS84, or the JSON null if it is
+	// unknown.
+	CRS json.RawMessage
+
+	// Edges is "planar" or "spherical", the type of the edges between the
+	// points of the geometries.
+	Edges string
+
+	// Orientation is "counterclockwise" if the exterior rings of polygons
+	// are counterclockwise and their holes clockwise, or "" if the
+	// orientation of rings is unknown.
+	Orientation string
+
+	// Bounds is the bounding box of the geometries, which may be empty
+	// if it is unknown.
+	Bounds geom.Rect
+
+	// Epoch is the epoch of the coordinates in a dynamic coordinate
+	// system, such as 2021.5, or 0.
+	Epoch float64
+
+	// Covering names the columns of the bounding boxes of the geometries,
+	// by their paths, such as {"bbox", "xmin"}, or is nil.
+	Covering *Covering
+}
+
+// Covering names the columns of the bounding boxes of the geometries of a
+// column, by their paths.
+type Covering struct {
+	XMin, YMin, XMax, YMax []string
+}
+
+// decodeMetadata decodes the JSON of geo metadata.
+func decodeMetadata(data []byte) (*Metadata, error) {
+	var m struct {
+		Version       string `json:"version"`
+		PrimaryColumn string `json:"primary_column"`
+		Columns       map[string]struct {
+			Encoding      string          `json:"encoding"`
+			GeometryTypes []string        `json:"geometry_types"`
+			CRS           json.RawMessage `json:"crs"`
+			Edges         string          `json:"edges"`
+			Orientation   string          `json:"orientation"`
+			BBox          []float64       `json:"bbox"`
+			Epoch         float64         `json:"epoch"`
+			Covering      *struct {
+				BBox struct {
+					XMin []string `json:"xmin"`
+					YMin []string `json:"ymin"`
+					XMax []string `json:"xmax"`
+					YMax []string `json:"ymax"`
+				} `json:"bbox"`
+			} `json:"covering"`
+		} `json:"columns"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: geo metadata: %w", ErrInvalid, err)
+	}
+	md := &Metadata{Version: m.Version, PrimaryColumn: m.PrimaryColumn, Columns: make(map[string]*GeometryColumn, len(m.Columns))}
+	for name, c := range m.Columns {
+		gc := &GeometryColumn{
+			Encoding:      c.Encoding,
+			GeometryTypes: c.GeometryTypes,
+			CRS:           c.CRS,
+			Edges:         c.Edges,
+			Orientation:   c.Orientation,
+			Bounds:        geom.EmptyRect(),
+			Epoch:         c.Epoch,
+		}
+		if gc.Edges == "" {
+			gc.Edges = "planar"
+		}
+		switch len(c.BBox) {
+		case 4:
+			gc.Bounds = geom.Rect{MinX: c.BBox[0], MinY: c.BBox[1], MaxX: c.BBox[2], MaxY: c.BBox[3]}
+		case 6: // With the minimum and maximum Z
+			gc.Bounds = geom.Rect{MinX: c.BBox[0], MinY: c.BBox[1], MaxX: c.BBox[3], MaxY: c.BBox[4]}
+		}
+		if v := c.Covering; v != nil {
+			gc.Covering = &Covering{v.BBox.XMin, v.BBox.YMin, v.BBox.XMax, v.BBox.YMax}
+		}
+		md.Columns[name] = gc
+	}
+	if md.Columns[md.PrimaryColumn] == nil {
+		return nil, fmt.Errorf("%w: no metadata of primary column %q", ErrInvalid, md.PrimaryColumn)
+	}
+	return md, nil
+}
+
+// RowGroup is a row group of a file.
+type RowGroup struct {
+	// Rows is the number of rows of the row group.
+	Rows int
+
+	// Bounds is the bounding box of the geometries of the primary column
+	// of the row group, from the covering or the statistics of the
+	// column, or if neither has it the rectangle of the whole plane, so
+	// that it intersects every rectangle.
+	Bounds geom.Rect
+}
+
+// Feature is a feature of a file, a row.
+type Feature struct {
+	// Geometry is the geometry of the primary column, or nil if it is
+	// null.
+	Geometry geom.Geometry
+
+	// Layout is the layout of the geometry.
+	Layout geom.Layout
+
+	// Properties are the values of the other columns, by name, the names
+	// of the columns of structures being their paths joined by dots. The
+	// columns of the covering are omitted. A value is nil if it is null,
+	// a geom.Geometry for the other columns of geometries, and otherwise
+	// a bool, int32, int64, uint32 or uint64 for unsigned integers,
+	// float32, float64, string, time.Time for dates and timestamps, or
+	// []byte, as the column's type.
+	Properties map[string]any
+}
+
+// File reads the features of a GeoParquet file, by row group.
+type File struct {
+	r         io.ReaderAt
+	size      int64
+	metadata  *Metadata
+	columns   []*column
+	rowGroups []tstruct
+	groups    []RowGroup
+	// geometry is the index in columns of the primary column.
+	geometry int
+}
+
+// NewFile returns a GeoParquet file of a size read from r, having read
+// its footer, which holds the metadata of the file. It returns an error
+// wrapping ErrInvalid if the file is not Parquet or has no geo metadata,
+// and wrapping ErrUnsupported if its primary column is not of WKB.
+func NewFile(r io.ReaderAt, size int64) (*File, error) {
+	if size < 12 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalid, size)
+	}
+	var tail [8]byte
+	if n, err := r.ReadAt(tail[:], size-8); n < len(tail) {
+		return nil, err
+	}
+	n := int64(binary.LittleEndian.Uint32(tail[:]))
+	if !bytes.Equal(tail[4:], magic) || n > size-12 {
+		return nil, fmt.Errorf("%w: no Parquet footer", ErrInvalid)
+	}
+	footer := make([]byte, n)
+	if m, err := r.ReadAt(footer, size-8-n); m < len(footer) {
+		return nil, err
+	}
+	meta, _, err := decodeStruct(footer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: footer", err)
+	}
+	f := &File{r: r, size: size, rowGroups: meta.structs(4)}
+	for _, kv := range meta.structs(5) {
+		if kv.string(1) == "geo" {
+			if f.metadata, err = decodeMetadata(kv.bytes(2)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if f.metadata == nil {
+		return nil, fmt.Errorf("%w: no geo metadata", ErrInvalid)
+	}
+	if f.columns, err = schema(meta.structs(2)); err != nil {
+		return nil, err
+	}
+	if e := f.metadata.Columns[f.metadata.PrimaryColumn].Encoding; !strings.EqualFold(e, "WKB") {
+		return nil, fmt.Errorf("%w: geometries encoded as %s", ErrUnsupported, e)
+	}
+	f.geometry = -1
+	for i, c := range f.columns {
+		if gc := f.metadata.Columns[c.name]; gc != nil {
+			c.kind = kindGeometry
+			if !strings.EqualFold(gc.Encoding, "WKB") || c.typ != typeByteArray {
+				c.kind = kindUnsupported
+			}
+		}
+		if c.name == f.metadata.PrimaryColumn && c.kind == kindGeometry {
+			f.geometry = i
+		}
+	}
+	if f.geometry < 0 {
+		return nil, fmt.Errorf("%w: no primary column %q of WKB", ErrInvalid, f.metadata.PrimaryColumn)
+	}
+	if cov := f.metadata.Columns[f.metadata.PrimaryColumn].Covering; cov != nil {
+		for _, c := range f.columns {
+			for _, p := range [][]string{cov.XMin, cov.YMin, cov.XMax, cov.YMax} {
+				if strings.Join(p, ".") == c.name {
+					c.kind = kindCovering
+				}
+			}
+		}
+	}
+	f.groups = make([]RowGroup, len(f.rowGroups))
+	for i, rg := range f.rowGroups {
+		if len(rg.structs(1)) != len(f.columns) {
+			return nil, fmt.Errorf("%w: row group %d has %d columns, not %d", ErrInvalid, i, len(rg.structs(1)), len(f.columns))
+		}
+		rows := rg.int(3)
+		if rows < 0 || rows > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: row group %d has %d rows", ErrInvalid, i, rows)
+		}
+		f.groups[i] = RowGroup{int(rows), f.bounds(rg)}
+	}
+	return f, nil
+}
+
+// everywhere is the rectangle of the whole plane.
+var everywhere = geom.Rect{MinX: math.Inf(-1), MinY: math.Inf(-1), MaxX: math.Inf(1), MaxY: math.Inf(1)}
+
+// bounds returns the bounds of the geometries of a row group, from the
+// statistics of the columns of the covering or of the primary column.
+func (f *File) bounds(rg tstruct) geom.Rect {
+	chunks := rg.structs(1)
+	if cov := f.metadata.Columns[f.metadata.PrimaryColumn].Covering; cov != nil {
+		var v [4]float64
+		ok := true
+		for i, p := range [][]string{cov.XMin, cov.YMin, cov.XMax, cov.YMax} {
+			ok = ok && f.statistic(chunks, strings.Join(p, "."), i >= 2, &v[i])
+		}
+		if ok {
+			return geom.Rect{MinX: v[0], MinY: v[1], MaxX: v[2], MaxY: v[3]}
+		}
+	}
+	// The geospatial statistics of Parquet 2.11.
+	if bb := chunks[f.geometry].child(3).child(17).child(1); bb != nil {
+		v := func(id int16) float64 { f, _ := bb[id].(float64); return f }
+		if r := (geom.Rect{MinX: v(1), MinY: v(3), MaxX: v(2), MaxY: v(4)}); len(bb) >= 4 && r.MinX <= r.MaxX {
+			return r
+		}
+	}
+	return everywhere
+}
+
+// statistic sets v to the minimum, or the maximum if max, of the values
+// of a column of floats or doubles of a row group, reporting whether its
+// statistics have it.
+func (f *File) statistic(chunks []tstruct, name string, max bool, v *float64) bool {
+	for i, c := range f.columns {
+		if c.name != name {
+			continue
+		}
+		stats := chunks[i].child(3).child(12)
+		b := stats.bytes(6)
+		if max {
+			b = stats.bytes(5)
+		}
+		switch {
+		case c.typ == typeDouble && len(b) == 8:
+			*v = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		case c.typ == typeFloat && len(b) == 4:
+			*v = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		default:
+			return false
+		}
+		return !math.IsNaN(*v)
+	}
+	return false
+}
+
+// Metadata returns the geo metadata of the file.
+func (f *File) Metadata() *Metadata {
+	return f.metadata
+}
+
+// RowGroups returns the row groups of the file, which ReadRowGroup reads
+// by index.
+func (f *File) RowGroups() []RowGroup {
+	return f.groups
+}
+
+// Columns returns the names of the columns of the properties of the
+// features.
+func (f *File) Columns() []string {
+	var names []string
+	for i, c := range f.columns {
+		if i != f.geometry && c.kind != kindCovering && c.kind != kindUnsupported {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// ReadRowGroup reads the features of a row group. It returns an error
+// wrapping ErrInvalid if the row group is malformed, and wrapping
+// ErrUnsupported if a column is in an encoding or compression which
+// this package does not read.
+func (f *File) ReadRowGroup(i int) ([]*Feature, error) {
+	rows := f.groups[i].Rows
+	chunks := f.rowGroups[i].structs(1)
+	fs := make([]*Feature, rows)
+	for j := range fs {
+		fs[j] = &Feature{Properties: map[string]any{}}
+	}
+	for j, c := range f.columns {
+		if c.kind == kindCovering || c.kind == kindUnsupported {
+			continue
+		}
+		vs, err := f.readChunk(c, chunks[j], rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: column %s of row group %d", err, c.name, i)
+		}
+		for k, v := range vs {
+			if c.kind == kindGeometry && v != nil {
+				g, layout, _, err := wkb.Unmarshal(v.([]byte))
+				if err != nil {
+					return nil, fmt.Errorf("%w: row %d of row group %d: %w", ErrInvalid, k, i, err)
+				}
+				if j == f.geometry {
+					fs[k].Geometry, fs[k].Layout = g, layout
+					continue
+				}
+				v = g
+			}
+			if j != f.geometry {
+				fs[k].Properties[c.name] = v
+			}
+		}
+	}
+	return fs, nil
+}
+
+// readChunk reads the values of the rows of a column chunk.
+func (f *File) readChunk(c *column, chunk tstruct, rows int) ([]any, error) {
+	if chunk.string(1) != "" {
+		return nil, fmt.Errorf("%w: column chunk in file %s", ErrUnsupported, chunk.string(1))
+	}
+	meta := chunk.child(3)
+	start, size := meta.int(9), meta.int(7)
+	if d, ok := meta[11].(int64); ok && d > 0 && d < start {
+		start = d // The dictionary page is first.
+	}
+	if start < int64(len(magic)) || size < 0 || size > f.size-start {
+		return nil, fmt.Errorf("%w: column chunk of %d bytes at %d", ErrInvalid, size, start)
+	}
+	b := make([]byte, size)
+	if n, err := f.r.ReadAt(b, start); n < len(b) {
+		return nil, err
+	}
+	return c.read(b, meta.int(4), rows)
+}
+
+// Features returns an iterator over the features of the file, in order,
+// which stops after the first error.
+func (f *File) Features() iter.Seq2[*Feature, error] {
+	return f.features(nil)
+}
+
+// Search returns an iterator over the features whose geometries'
+// bounding boxes intersect a rectangle, in the order of the file, which
+// stops after the first error. It reads only the row groups whose bounds
+// intersect the rectangle.
+func (f *File) Search(r geom.Rect) iter.Seq2[*Feature, error] {
+	return f.features(&r)
+}
+
+// features returns an iterator over the features of the file, or those
+// which intersect a rectangle if it is not nil.
+func (f *File) features(r *geom.Rect) iter.Seq2[*Feature, error] {
+	return func(yield func(*Feature, error) bool) {
+		for i, rg := range f.groups {
+			if r != nil && !r.Intersects(rg.Bounds) {
+				continue
+			}
+			fs, err := f.ReadRowGroup(i)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, ft := range fs {
+				if r != nil && (ft.Geometry == nil || !r.Intersects(geom.Bounds(ft.Geometry))) {
+					continue
+				}
+				if !yield(ft, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,707 @@
+package geoparquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/wkb"
+)
+
+// testColumn is a leaf column of a file to build, whose name is its path
+// joined by dots, of one or two elements.
+type testColumn struct {
+	name     string
+	typ      int32
+	optional bool
+	// logical is the logical type, or nil.
+	logical []tf
+}
+
+// testFile is a file to build.
+type testFile struct {
+	// geo is the geo metadata, or "" for none.
+	geo     string
+	columns []testColumn
+	// groups are the values of the columns of the row groups, nil for
+	// null.
+	groups [][][]any
+	codec  int32
+	// dict is whether to encode byte arrays with dictionaries.
+	dict bool
+	// v2 is whether to write data pages of version 2.
+	v2 bool
+	// stats is whether to write the statistics of columns of doubles,
+	// and the geospatial statistics of the column "geometry".
+	stats bool
+	// edit, if not nil, changes the column chunks and their bytes.
+	edit func(cc []tf, b []byte) ([]tf, []byte)
+}
+
+// build returns the file.
+func (f testFile) build() []byte {
+	b := bytes.Clone(magic)
+	var groups []any
+	rows := 0
+	for _, g := range f.groups {
+		var chunks []any
+		for i, c := range f.columns {
+			meta, chunk := f.chunk(c, g[i], len(b))
+			cc := []tf{{1, nil}, {2, int64(0)}, {3, meta}}
+			if f.edit != nil {
+				cc, chunk = f.edit(cc, chunk)
+			}
+			b = append(b, chunk...)
+			chunks = append(chunks, cc)
+		}
+		groups = append(groups, []tf{{1, chunks}, {2, int64(0)}, {3, int64(len(g[0]))}})
+		rows += len(g[0])
+	}
+	kvs := []any{[]tf{{1, "ARROW:schema"}, {2, "..."}}}
+	if f.geo != "" {
+		kvs = append(kvs, []tf{{1, "geo"}, {2, f.geo}})
+	}
+	footer := thrift(tf{1, int32(2)}, tf{2, f.schema()}, tf{3, int64(rows)}, tf{4, groups}, tf{5, kvs}, tf{6, "test"})
+	b = append(b, footer...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(footer)))
+	return append(b, magic...)
+}
+
+// schema returns the elements of the schema of the file.
+func (f testFile) schema() []any {
+	var es []any
+	top := 0
+	for i := 0; i < len(f.columns); i++ {
+		top++
+		group, _, ok := strings.Cut(f.columns[i].name, ".")
+		if !ok {
+			es = append(es, f.columns[i].element(f.columns[i].name))
+			continue
+		}
+		j := i
+		for j < len(f.columns) && strings.HasPrefix(f.columns[j].name, group+".") {
+			j++
+		}
+		es = append(es, []tf{{3, int32(0)}, {4, group}, {5, int32(j - i)}})
+		for ; i < j; i++ {
+			es = append(es, f.columns[i].element(f.columns[i].name[len(group)+1:]))
+		}
+		i--
+	}
+	return append([]any{[]tf{{4, "schema"}, {5, int32(top)}}}, es...)
+}
+
+// element returns the schema element of a column of a name.
+func (c testColumn) element(name string) []tf {
+	repetition := int32(0)
+	if c.optional {
+		repetition = 1
+	}
+	e := []tf{{1, c.typ}, {3, repetition}, {4, name}}
+	if c.logical != nil {
+		e = append(e, tf{10, c.logical})
+	}
+	return e
+}
+
+// chunk returns the metadata and the bytes of the chunk of values of a
+// column at an offset.
+func (f testFile) chunk(c testColumn, vs []any, off int) ([]tf, []byte) {
+	var present []any
+	var defs []byte
+	for i := 0; i < len(vs); {
+		// A run of definition levels.
+		j := i
+		for j < len(vs) && (vs[j] == nil) == (vs[i] == nil) {
+			if vs[j] != nil {
+				present = append(present, vs[j])
+			}
+			j++
+		}
+		def := byte(1)
+		if vs[i] == nil {
+			def = 0
+		}
+		defs = append(binary.AppendUvarint(defs, uint64(j-i)<<1), def)
+		i = j
+	}
+	if !c.optional {
+		defs = nil
+	}
+	var chunk []byte
+	var dictOffset any
+	encoding := int32(encodingPlain)
+	values := plainValues(c.typ, present)
+	if f.dict && c.typ == typeByteArray {
+		var dict []any
+		index := map[string]int{}
+		var is []int
+		for _, v := range present {
+			k := string(toBytes(v))
+			if _, ok := index[k]; !ok {
+				index[k] = len(dict)
+				dict = append(dict, v)
+			}
+			is = append(is, index[k])
+		}
+		data := plainValues(c.typ, dict)
+		page := compress(f.codec, data)
+		dictOffset = int64(off)
+		chunk = append(thrift(tf{1, int32(pageDictionary)}, tf{2, int32(len(data))}, tf{3, int32(len(page))},
+			tf{7, []tf{{1, int32(len(dict))}, {2, int32(encodingPlain)}}}), page...)
+		width := bits.Len(uint(max(len(dict)-1, 0)))
+		values = []byte{byte(width)}
+		for _, i := range is {
+			values = binary.AppendUvarint(values, 1<<1)
+			for k := range (width + 7) / 8 {
+				values = append(values, byte(i>>(8*k)))
+			}
+		}
+		encoding = encodingRLEDic
+	}
+	dataOffset := off + len(chunk)
+	if f.v2 {
+		page := compress(f.codec, values)
+		chunk = append(chunk, thrift(tf{1, int32(pageDataV2)}, tf{2, int32(len(defs) + len(values))}, tf{3, int32(len(defs) + len(page))},
+			tf{8, []tf{
+				{1, int32(len(vs))}, {2, int32(len(vs) - len(present))}, {3, int32(len(vs))}, {4, encoding},
+				{5, int32(len(defs))}, {6, int32(0)},
+			}})...)
+		chunk = append(append(chunk, defs...), page...)
+	} else {
+		var data []byte
+		if c.optional {
+			data = append(binary.LittleEndian.AppendUint32(nil, uint32(len(defs))), defs...)
+		}
+		data = append(data, values...)
+		page := compress(f.codec, data)
+		chunk = append(chunk, thrift(tf{1, int32(pageData)}, tf{2, int32(len(data))}, tf{3, int32(len(page))},
+			tf{5, []tf{{1, int32(len(vs))}, {2, encoding}, {3, int32(encodingRLE)}, {4, int32(encodingRLE)}}})...)
+		chunk = append(chunk, page...)
+	}
+	var path []any
+	for _, p := range strings.Split(c.name, ".") {
+		path = append(path, p)
+	}
+	meta := []tf{
+		{1, c.typ}, {2, []any{int32(encodingPlain)}}, {3, path}, {4, f.codec}, {5, int64(len(vs))},
+		{6, int64(len(chunk))}, {7, int64(len(chunk))}, {9, int64(dataOffset)}, {11, dictOffset},
+	}
+	if f.stats && c.typ == typeDouble && len(present) > 0 {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, v := range present {
+			if !math.IsNaN(v.(float64)) {
+				lo, hi = min(lo, v.(float64)), max(hi, v.(float64))
+			}
+		}
+		meta = append(meta, tf{12, []tf{
+			{5, binary.LittleEndian.AppendUint64(nil, math.Float64bits(hi))},
+			{6, binary.LittleEndian.AppendUint64(nil, math.Float64bits(lo))},
+		}})
+	}
+	if f.stats && c.name == "geometry" {
+		r := geom.EmptyRect()
+		for _, v := range present {
+			if g, _, _, err := wkb.Unmarshal(v.([]byte)); err == nil {
+				r = r.Union(geom.Bounds(g))
+			}
+		}
+		meta = append(meta, tf{17, []tf{{1, []tf{{1, r.MinX}, {2, r.MaxX}, {3, r.MinY}, {4, r.MaxY}}}}})
+	}
+	return meta, chunk
+}
+
+// toBytes returns a value of a byte array.
+func toBytes(v any) []byte {
+	if s, ok := v.(string); ok {
+		return []byte(s)
+	}
+	return v.([]byte)
+}
+
+// plainValues returns values of a type in the plain encoding.
+func plainValues(typ int32, vs []any) []byte {
+	var b []byte
+	if typ == typeBoolean {
+		b = make([]byte, (len(vs)+7)/8)
+	}
+	for i, v := range vs {
+		switch typ {
+		case typeBoolean:
+			if v.(bool) {
+				b[i/8] |= 1 << (i % 8)
+			}
+		case typeInt32:
+			b = binary.LittleEndian.AppendUint32(b, uint32(v.(int32)))
+		case typeInt64:
+			b = binary.LittleEndian.AppendUint64(b, uint64(v.(int64)))
+		case typeFloat:
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v.(float32)))
+		case typeDouble:
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v.(float64)))
+		case typeByteArray:
+			b = binary.LittleEndian.AppendUint32(b, uint32(len(toBytes(v))))
+			b = append(b, toBytes(v)...)
+		}
+	}
+	return b
+}
+
+// compress compresses data with a codec.
+func compress(codec int32, data []byte) []byte {
+	switch codec {
+	case 1:
+		return snappy(data)
+	case 2:
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		zw.Write(data)
+		zw.Close()
+		return b.Bytes()
+	}
+	return data
+}
+
+// point returns the WKB of a point.
+func point(x, y float64) []byte {
+	b, err := wkb.Marshal(geom.Point{X: x, Y: y}, geom.XY, binary.LittleEndian)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// buildings is the geo metadata of a file of buildings.
+const buildings = `{
+	"version": "1.1.0",
+	"primary_column": "geometry",
+	"columns": {
+		"geometry": {
+			"encoding": "WKB",
+			"geometry_types": ["Point"],
+			"bbox": [-10, -5, 20, 30],
+			"covering": {"bbox": {
+				"xmin": ["bbox", "xmin"], "ymin": ["bbox", "ymin"],
+				"xmax": ["bbox", "xmax"], "ymax": ["bbox", "ymax"]
+			}}
+		}
+	}
+}`
+
+// buildingColumns are the columns of a file of buildings.
+var buildingColumns = []testColumn{
+	{name: "id", typ: typeInt64},
+	{name: "geometry", typ: typeByteArray, optional: true},
+	{name: "name", typ: typeByteArray, optional: true, logical: []tf{{1, []tf{}}}},
+	{name: "height", typ: typeDouble, optional: true},
+	{name: "public", typ: typeBoolean},
+	{name: "built", typ: typeInt32, optional: true, logical: []tf{{6, []tf{}}}},
+	{name: "bbox.xmin", typ: typeDouble},
+	{name: "bbox.ymin", typ: typeDouble},
+	{name: "bbox.xmax", typ: typeDouble},
+	{name: "bbox.ymax", typ: typeDouble},
+}
+
+// building is a row of a file of buildings.
+type building struct {
+	x, y   float64
+	name   any
+	height any
+	public bool
+	built  any
+}
+
+// buildingGroups returns the values of the columns of row groups of
+// buildings.
+func buildingGroups(gs ...[]building) [][][]any {
+	var groups [][][]any
+	id := int64(0)
+	for _, bs := range gs {
+		g := make([][]any, len(buildingColumns))
+		for _, b := range bs {
+			id++
+			var geometry any = point(b.x, b.y)
+			if math.IsNaN(b.x) {
+				geometry = nil
+			}
+			for i, v := range []any{id, geometry, b.name, b.height, b.public, b.built, b.x, b.y, b.x, b.y} {
+				g[i] = append(g[i], v)
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// sampleGroups are the row groups of the sample file of buildings.
+var sampleGroups = buildingGroups(
+	[]building{
+		{-10, -5, "Town Hall", 21.5, true, int32(-3653)},
+		{-8, 0, nil, nil, false, nil},
+		{-9, -4, "Library", 12.0, true, int32(18000)},
+	},
+	[]building{
+		{15, 25, "Station", nil, true, int32(0)},
+		{20, 30, "Town Hall", 8.25, false, nil},
+	},
+)
+
+// sampleFeatures are the features of the sample file.
+var sampleFeatures = []*Feature{
+	{geom.Point{X: -10, Y: -5}, geom.XY, map[string]any{"id": int64(1), "name": "Town Hall", "height": 21.5, "public": true, "built": time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	{geom.Point{X: -8, Y: 0}, geom.XY, map[string]any{"id": int64(2), "name": nil, "height": nil, "public": false, "built": nil}},
+	{geom.Point{X: -9, Y: -4}, geom.XY, map[string]any{"id": int64(3), "name": "Library", "height": 12.0, "public": true, "built": time.Date(2019, 4, 14, 0, 0, 0, 0, time.UTC)}},
+	{geom.Point{X: 15, Y: 25}, geom.XY, map[string]any{"id": int64(4), "name": "Station", "height": nil, "public": true, "built": time.Unix(0, 0).UTC()}},
+	{geom.Point{X: 20, Y: 30}, geom.XY, map[string]any{"id": int64(5), "name": "Town Hall", "height": 8.25, "public": false, "built": nil}},
+}
+
+// readFeatures reads the features of an iterator.
+func readFeatures(t *testing.T, f *File, r *geom.Rect) []*Feature {
+	t.Helper()
+	var fs []*Feature
+	for ft, err := range f.features(r) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, ft)
+	}
+	return fs
+}
+
+func TestFile(t *testing.T) {
+	for _, tf := range []testFile{
+		{},
+		{codec: 1},
+		{codec: 2, dict: true},
+		{v2: true},
+		{v2: true, codec: 1, dict: true, stats: true},
+		{codec: 2, stats: true},
+	} {
+		tf.geo, tf.columns, tf.groups = buildings, buildingColumns, sampleGroups
+		name := fmt.Sprintf("codec %d, dict %t, v2 %t, stats %t", tf.codec, tf.dict, tf.v2, tf.stats)
+		b := tf.build()
+		f, err := NewFile(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Fatalf("%s: NewFile: %v", name, err)
+		}
+		md := &Metadata{
+			Version: "1.1.0", PrimaryColumn: "geometry",
+			Columns: map[string]*GeometryColumn{"geometry": {
+				Encoding: "WKB", GeometryTypes: []string{"Point"}, Edges: "planar",
+				Bounds:   geom.Rect{MinX: -10, MinY: -5, MaxX: 20, MaxY: 30},
+				Covering: &Covering{[]string{"bbox", "xmin"}, []string{"bbox", "ymin"}, []string{"bbox", "xmax"}, []string{"bbox", "ymax"}},
+			}},
+		}
+		if got := f.Metadata(); !reflect.DeepEqual(got, md) {
+			t.Errorf("%s: Metadata = %+v, want %+v", name, got, md)
+		}
+		groups := []RowGroup{{3, everywhere}, {2, everywhere}}
+		if tf.stats {
+			groups = []RowGroup{{3, geom.Rect{MinX: -10, MinY: -5, MaxX: -8, MaxY: 0}}, {2, geom.Rect{MinX: 15, MinY: 25, MaxX: 20, MaxY: 30}}}
+		}
+		if got := f.RowGroups(); !reflect.DeepEqual(got, groups) {
+			t.Errorf("%s: RowGroups = %v, want %v", name, got, groups)
+		}
+		if got, want := f.Columns(), []string{"id", "name", "height", "public", "built"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Columns = %q, want %q", name, got, want)
+		}
+		if got := readFeatures(t, f, nil); !reflect.DeepEqual(got, sampleFeatures) {
+			t.Errorf("%s: Features = %v, want %v", name, got, sampleFeatures)
+		}
+		r := geom.Rect{MinX: -9.5, MinY: -4.5, MaxX: 16, MaxY: 26}
+		if got, want := readFeatures(t, f, &r), sampleFeatures[1:4]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Search = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestFileStop(t *testing.T) {
+	b := testFile{geo: buildings, columns: buildingColumns, groups: sampleGroups}.build()
+	f, _ := NewFile(bytes.NewReader(b), int64(len(b)))
+	n := 0
+	for range f.Features() {
+		if n++; n == 4 {
+			break
+		}
+	}
+	if n != 4 {
+		t.Errorf("Features yielded %d features before the break, want 4", n)
+	}
+}
+
+func TestSearchSkips(t *testing.T) {
+	// The search of the second row group alone does not read the first,
+	// whose geometries are malformed.
+	groups := buildingGroups(
+		[]building{{0, 0, "a", 1.0, true, nil}},
+		[]building{{10, 10, "b", 2.0, true, nil}, {math.NaN(), 10, "c", 3.0, true, nil}},
+	)
+	groups[0][1][0] = []byte{1, 1, 0, 0}
+	b := testFile{geo: buildings, columns: buildingColumns, groups: groups, stats: true}.build()
+	f, err := NewFile(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := geom.Rect{MinX: 5, MinY: 5, MaxX: 15, MaxY: 15}
+	if fs := readFeatures(t, f, &r); len(fs) != 1 || fs[0].Properties["name"] != "b" {
+		t.Errorf("Search = %v, want the feature b", fs)
+	}
+	var err2 error
+	for _, err := range f.Features() {
+		err2 = err
+	}
+	want := "geoparquet: invalid GeoParquet: row 0 of row group 0: wkb: invalid WKB: unexpected end of data at offset 1"
+	if err2 == nil || err2.Error() != want || !errors.Is(err2, ErrInvalid) {
+		t.Errorf("Features = %v, want error %q", err2, want)
+	}
+}
+
+func TestRowGroupBounds(t *testing.T) {
+	// Without a covering, the bounds of a row group are those of the
+	// geospatial statistics.
+	geo := `{"version": "1.0.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB"}}}`
+	columns := []testColumn{{name: "geometry", typ: typeByteArray}}
+	groups := [][][]any{{{point(1, 2), point(3, -4)}}, {{point(5, 6)}}}
+	want := []RowGroup{{2, geom.Rect{MinX: 1, MinY: -4, MaxX: 3, MaxY: 2}}, {1, geom.Rect{MinX: 5, MinY: 6, MaxX: 5, MaxY: 6}}}
+	b := testFile{geo: geo, columns: columns, groups: groups, stats: true}.build()
+	f, err := NewFile(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.RowGroups(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RowGroups = %v, want %v", got, want)
+	}
+	// A covering of floats without statistics is ignored.
+	geo = `{"version": "1.1.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB",
+		"covering": {"bbox": {"xmin": ["x"], "ymin": ["y"], "xmax": ["x"], "ymax": ["y"]}}}}}`
+	columns = append(columns, testColumn{name: "x", typ: typeFloat}, testColumn{name: "y", typ: typeFloat})
+	groups = [][][]any{{{point(1, 2)}, {float32(1)}, {float32(2)}}}
+	b = testFile{geo: geo, columns: columns, groups: groups}.build()
+	if f, err = NewFile(bytes.NewReader(b), int64(len(b))); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.RowGroups(); !reflect.DeepEqual(got, []RowGroup{{1, everywhere}}) {
+		t.Errorf("RowGroups without statistics = %v, want everywhere", got)
+	}
+	if got := f.Columns(); got != nil {
+		t.Errorf("Columns = %q, want none", got)
+	}
+}
+
+func TestOtherGeometryColumns(t *testing.T) {
+	geo := `{"version": "1.1.0", "primary_column": "geometry", "columns": {
+		"geometry": {"encoding": "WKB"}, "centroid": {"encoding": "WKB"}, "shape": {"encoding": "point"}}}`
+	columns := []testColumn{
+		{name: "geometry", typ: typeByteArray},
+		{name: "centroid", typ: typeByteArray, optional: true},
+		{name: "shape", typ: typeDouble},
+	}
+	groups := [][][]any{{{point(1, 2), point(3, 4)}, {point(5, 6), nil}, {7.0, 8.0}}}
+	b := testFile{geo: geo, columns: columns, groups: groups}.build()
+	f, err := NewFile(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.Columns(), []string{"centroid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns = %q, want %q", got, want)
+	}
+	want := []*Feature{
+		{geom.Point{X: 1, Y: 2}, geom.XY, map[string]any{"centroid": geom.Point{X: 5, Y: 6}}},
+		{geom.Point{X: 3, Y: 4}, geom.XY, map[string]any{"centroid": nil}},
+	}
+	if got := readFeatures(t, f, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Features = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want *Metadata
+	}{
+		{
+			"minimal",
+			`{"version": "1.0.0", "primary_column": "geom", "columns": {"geom": {"encoding": "WKB", "geometry_types": []}}}`,
+			&Metadata{"1.0.0", "geom", map[string]*GeometryColumn{"geom": {Encoding: "WKB", GeometryTypes: []string{}, Edges: "planar", Bounds: geom.EmptyRect()}}},
+		},
+		{
+			"full",
+			`{"version": "1.1.0", "primary_column": "geom", "columns": {"geom": {
+				"encoding": "WKB", "geometry_types": ["Polygon Z"], "crs": {"id": {"authority": "EPSG", "code": 4978}},
+				"edges": "spherical", "orientation": "counterclockwise", "bbox": [1, 2, 3, 4, 5, 6], "epoch": 2021.5},
+				"other": {"encoding": "WKB", "crs": null, "bbox": [1, 2]}}}`,
+			&Metadata{"1.1.0", "geom", map[string]*GeometryColumn{
+				"geom": {
+					Encoding: "WKB", GeometryTypes: []string{"Polygon Z"}, CRS: json.RawMessage(`{"id": {"authority": "EPSG", "code": 4978}}`),
+					Edges: "spherical", Orientation: "counterclockwise", Bounds: geom.Rect{MinX: 1, MinY: 2, MaxX: 4, MaxY: 5}, Epoch: 2021.5,
+				},
+				"other": {Encoding: "WKB", CRS: json.RawMessage("null"), Edges: "planar", Bounds: geom.EmptyRect()},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := decodeMetadata([]byte(tt.json))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: decodeMetadata = %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		json string
+		msg  string
+	}{
+		{`{`, "geoparquet: invalid GeoParquet: geo metadata: unexpected end of JSON input"},
+		{`{"version": 1}`, "geoparquet: invalid GeoParquet: geo metadata: json: cannot unmarshal number into Go struct field .version of type string"},
+		{`{"primary_column": "geom", "columns": {}}`, `geoparquet: invalid GeoParquet: no metadata of primary column "geom"`},
+		{`{"columns": {"geom": {}}}`, `geoparquet: invalid GeoParquet: no metadata of primary column ""`},
+	}
+	for _, tt := range tests {
+		md, err := decodeMetadata([]byte(tt.json))
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("decodeMetadata(%s) = %v, %v, want error %q", tt.json, md, err, tt.msg)
+		}
+	}
+}
+
+// footerFile returns a file of no data of a footer.
+func footerFile(fs ...tf) []byte {
+	footer := thrift(fs...)
+	b := append(bytes.Clone(magic), footer...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(footer)))
+	return append(b, magic...)
+}
+
+func TestNewFileInvalid(t *testing.T) {
+	geo := `{"version": "1.1.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB"}}}`
+	meta := []any{[]tf{{1, "geo"}, {2, geo}}}
+	schema := []any{[]tf{{4, "schema"}, {5, int32(1)}}, []tf{{1, int32(typeByteArray)}, {4, "geometry"}}}
+	column := func(typ int32) []testColumn { return []testColumn{{name: "geometry", typ: typ}} }
+	tests := []struct {
+		name string
+		b    []byte
+		msg  string
+	}{
+		{"too small", []byte("PAR10000PAR"), "geoparquet: invalid GeoParquet: 11 bytes"},
+		{"no magic", []byte("PAR1PAR1\x00\x00\x00\x00PAR2"), "geoparquet: invalid GeoParquet: no Parquet footer"},
+		{"footer too large", []byte("PAR1PAR1\x05\x00\x00\x00PAR1"), "geoparquet: invalid GeoParquet: no Parquet footer"},
+		{"footer of bad Thrift", append(append([]byte("PAR1\x15"), 1, 0, 0, 0), magic...), "geoparquet: invalid GeoParquet: malformed varint at offset 1 of a Thrift structure: footer"},
+		{"no geo metadata", testFile{columns: column(typeByteArray)}.build(), "geoparquet: invalid GeoParquet: no geo metadata"},
+		{"bad geo metadata", footerFile(tf{5, []any{[]tf{{1, "geo"}, {2, `{"columns": {}}`}}}}), `geoparquet: invalid GeoParquet: no metadata of primary column ""`},
+		{"no schema", footerFile(tf{5, meta}), "geoparquet: invalid GeoParquet: no schema"},
+		{"schema ends early", footerFile(tf{2, schema[:1]}, tf{5, meta}), "geoparquet: invalid GeoParquet: schema ends early"},
+		{"no primary column", testFile{geo: strings.Replace(geo, `"geometry"`, `"geom"`, 2), columns: column(typeByteArray)}.build(), `geoparquet: invalid GeoParquet: no primary column "geom" of WKB`},
+		{"primary column of doubles", testFile{geo: geo, columns: column(typeDouble)}.build(), `geoparquet: invalid GeoParquet: no primary column "geometry" of WKB`},
+		{"row group of no columns", footerFile(tf{2, schema}, tf{4, []any{[]tf{{1, []any{}}, {3, int64(0)}}}}, tf{5, meta}), "geoparquet: invalid GeoParquet: row group 0 has 0 columns, not 1"},
+		{"row group of negative rows", footerFile(tf{2, schema}, tf{4, []any{[]tf{{1, []any{[]tf{{2, int64(0)}}}}, {3, int64(-1)}}}}, tf{5, meta}), "geoparquet: invalid GeoParquet: row group 0 has -1 rows"},
+	}
+	for _, tt := range tests {
+		f, err := NewFile(bytes.NewReader(tt.b), int64(len(tt.b)))
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: NewFile = %v, %v, want error %q", tt.name, f, err, tt.msg)
+		}
+	}
+}
+
+func TestNewFileUnsupported(t *testing.T) {
+	geo := `{"version": "1.1.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKT"}}}`
+	b := testFile{geo: geo, columns: []testColumn{{name: "geometry", typ: typeByteArray}}}.build()
+	f, err := NewFile(bytes.NewReader(b), int64(len(b)))
+	want := "geoparquet: unsupported GeoParquet: geometries encoded as WKT"
+	if err == nil || err.Error() != want || !errors.Is(err, ErrUnsupported) {
+		t.Errorf("NewFile = %v, %v, want error %q", f, err, want)
+	}
+}
+
+func TestReadRowGroupInvalid(t *testing.T) {
+	geo := `{"version": "1.1.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB"}}}`
+	columns := []testColumn{{name: "geometry", typ: typeByteArray}}
+	tests := []struct {
+		name string
+		f    testFile
+		err  error
+		msg  string
+	}{
+		{
+			"malformed WKB",
+			testFile{groups: [][][]any{{{point(1, 2), []byte{1, 1, 0, 0, 0}}}}},
+			ErrInvalid, "geoparquet: invalid GeoParquet: row 1 of row group 0: wkb: invalid WKB: unexpected end of data at offset 5",
+		},
+		{
+			"ZSTD",
+			testFile{codec: 6},
+			ErrUnsupported, "geoparquet: unsupported GeoParquet: compression ZSTD: column geometry of row group 0",
+		},
+		{
+			"chunk in another file",
+			testFile{edit: func(cc []tf, b []byte) ([]tf, []byte) { cc[0].v = "other.parquet"; return cc, b }},
+			ErrUnsupported, "geoparquet: unsupported GeoParquet: column chunk in file other.parquet: column geometry of row group 0",
+		},
+		{
+			"chunk beyond the file",
+			testFile{edit: func(cc []tf, b []byte) ([]tf, []byte) { cc[2].v.([]tf)[6].v = int64(1000); return cc, b }},
+			ErrInvalid, "geoparquet: invalid GeoParquet: column chunk of 1000 bytes at 4: column geometry of row group 0",
+		},
+		{
+			"chunk before the data",
+			testFile{edit: func(cc []tf, b []byte) ([]tf, []byte) { cc[2].v.([]tf)[7].v = int64(0); return cc, b }},
+			ErrInvalid, "geoparquet: invalid GeoParquet: column chunk of 42 bytes at 0: column geometry of row group 0",
+		},
+		{
+			"truncated page",
+			testFile{edit: func(cc []tf, b []byte) ([]tf, []byte) { cc[2].v.([]tf)[6].v = int64(len(b) - 3); return cc, b }},
+			ErrInvalid, "geoparquet: invalid GeoParquet: page of 25 bytes in 22: column geometry of row group 0",
+		},
+	}
+	for _, tt := range tests {
+		tt.f.geo, tt.f.columns = geo, columns
+		if tt.f.groups == nil {
+			tt.f.groups = [][][]any{{{point(1, 2)}}}
+		}
+		b := tt.f.build()
+		f, err := NewFile(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Fatalf("%s: NewFile: %v", tt.name, err)
+		}
+		fs, err := f.ReadRowGroup(0)
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, tt.err) {
+			t.Errorf("%s: ReadRowGroup = %v, %v, want error %q", tt.name, fs, err, tt.msg)
+		}
+	}
+}
+
+func TestFileCorrupt(t *testing.T) {
+	// Corrupted files are errors or features, but never panics.
+	rnd := rand.New(rand.NewSource(93))
+	for _, tf := range []testFile{{}, {codec: 1, dict: true, stats: true}, {codec: 2, v2: true, dict: true}} {
+		tf.geo, tf.columns, tf.groups = buildings, buildingColumns, sampleGroups
+		valid := tf.build()
+		for range 2000 {
+			b := bytes.Clone(valid)
+			for range 1 + rnd.Intn(3) {
+				b[rnd.Intn(len(b))] = byte(rnd.Intn(256))
+			}
+			f, err := NewFile(bytes.NewReader(b), int64(len(b)))
+			if err == nil {
+				for _, err = range f.Features() {
+				}
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) && !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("reading %x = %v, want ErrInvalid or ErrUnsupported", b, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package geoparquet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// unsnappy decompresses a block of Snappy, the compression of most
+// Parquet files, to a buffer of its size, which the page gives.
+func unsnappy(src []byte, size int) ([]byte, error) {
+	n, i := binary.Uvarint(src)
+	if i <= 0 || n != uint64(size) {
+		return nil, fmt.Errorf("%w: Snappy block of %d bytes in a page of %d", ErrInvalid, n, size)
+	}
+	dst := make([]byte, 0, min(size, 24*len(src)))
+	for i < len(src) {
+		tag := src[i]
+		i++
+		var length, offset int
+		switch tag & 3 {
+		case 0: // A literal
+			length = int(tag >> 2)
+			if length >= 60 {
+				m := length - 59
+				if len(src)-i < m {
+					return nil, fmt.Errorf("%w: truncated Snappy block", ErrInvalid)
+				}
+				length = 0
+				for j := range m {
+					length |= int(src[i+j]) << (8 * j)
+				}
+				i += m
+			}
+			length++
+			if length > len(src)-i || length > size-len(dst) {
+				return nil, fmt.Errorf("%w: Snappy literal beyond the end", ErrInvalid)
+			}
+			dst = append(dst, src[i:i+length]...)
+			i += length
+			continue
+		case 1:
+			if i >= len(src) {
+				return nil, fmt.Errorf("%w: truncated Snappy block", ErrInvalid)
+			}
+			length = 4 + int(tag>>2&7)
+			offset = int(tag>>5)<<8 | int(src[i])
+			i++
+		case 2:
+			if len(src)-i < 2 {
+				return nil, fmt.Errorf("%w: truncated Snappy block", ErrInvalid)
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[i:]))
+			i += 2
+		case 3:
+			if len(src)-i < 4 {
+				return nil, fmt.Errorf("%w: truncated Snappy block", ErrInvalid)
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[i:]))
+			i += 4
+		}
+		if offset == 0 || offset > len(dst) || length > size-len(dst) {
+			return nil, fmt.Errorf("%w: Snappy copy beyond the block", ErrInvalid)
+		}
+		// The copy may overlap what it appends, repeating it.
+		for start := len(dst) - offset; length > 0; length-- {
+			dst = append(dst, dst[start])
+			start++
+		}
+	}
+	if len(dst) != size {
+		return nil, fmt.Errorf("%w: Snappy block of %d bytes, not %d", ErrInvalid, len(dst), size)
+	}
+	return dst, nil
+}
@@ -0,0 +1,94 @@
+package geoparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// snappy returns a block of Snappy of literals, which unsnappy reads as
+// it reads any other.
+func snappy(b []byte) []byte {
+	s := binary.AppendUvarint(nil, uint64(len(b)))
+	for len(b) > 0 {
+		n := min(len(b), 256)
+		s = append(s, 60<<2, byte(n-1))
+		s = append(s, b[:n]...)
+		b = b[n:]
+	}
+	return s
+}
+
+func TestUnsnappy(t *testing.T) {
+	long := strings.Repeat("0123456789", 10)
+	tests := []struct {
+		name, src, want string
+	}{
+		{"empty", "00", ""},
+		{"literal", "03 08 616263", "abc"},
+		{"copy of a 1 byte offset", "0C 08 616263 15 03", "abcabcabcabc"},
+		{"copy of a 2 byte offset", "0C 08 616263 22 0300", "abcabcabcabc"},
+		{"copy of a 4 byte offset", "0C 08 616263 23 03000000", "abcabcabcabc"},
+		{"run", "0A 00 78 15 01", "xxxxxxxxxx"},
+		{"long literal", "64 F0 63 " + hex.EncodeToString([]byte(long)), long},
+		{"longer literal", "E807 F4 E703 " + hex.EncodeToString([]byte(strings.Repeat(long, 10))), strings.Repeat(long, 10)},
+		{"long copy", "E807 F4 6300 " + hex.EncodeToString([]byte(long)) + strings.Repeat("FE 6400 ", 14) + "0E 6400", strings.Repeat(long, 10)},
+	}
+	for _, tt := range tests {
+		got, err := unsnappy(unhex(tt.src), len(tt.want))
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: unsnappy = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestUnsnappyInvalid(t *testing.T) {
+	tests := []struct {
+		src  string
+		size int
+		msg  string
+	}{
+		{"", 0, "geoparquet: invalid GeoParquet: Snappy block of 0 bytes in a page of 0"},
+		{"05 10 6162636465", 4, "geoparquet: invalid GeoParquet: Snappy block of 5 bytes in a page of 4"},
+		{"0A F4 09", 10, "geoparquet: invalid GeoParquet: truncated Snappy block"},
+		{"03 08 61", 3, "geoparquet: invalid GeoParquet: Snappy literal beyond the end"},
+		{"02 08 616263", 2, "geoparquet: invalid GeoParquet: Snappy literal beyond the end"},
+		{"04 00 61 01", 4, "geoparquet: invalid GeoParquet: truncated Snappy block"},
+		{"04 00 61 02 01", 4, "geoparquet: invalid GeoParquet: truncated Snappy block"},
+		{"04 00 61 03 010000", 4, "geoparquet: invalid GeoParquet: truncated Snappy block"},
+		{"05 00 61 01 00", 5, "geoparquet: invalid GeoParquet: Snappy copy beyond the block"},
+		{"05 00 61 01 02", 5, "geoparquet: invalid GeoParquet: Snappy copy beyond the block"},
+		{"03 00 61 05 01", 3, "geoparquet: invalid GeoParquet: Snappy copy beyond the block"},
+		{"04 04 6162", 4, "geoparquet: invalid GeoParquet: Snappy block of 2 bytes, not 4"},
+	}
+	for _, tt := range tests {
+		b, err := unsnappy(unhex(tt.src), tt.size)
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("unsnappy(%s, %d) = %q, %v, want error %q", tt.src, tt.size, b, err, tt.msg)
+		}
+	}
+}
+
+func TestUnsnappyRandom(t *testing.T) {
+	// Blocks of literals are read back, and corrupted blocks are errors
+	// or bytes, but never panics.
+	rnd := rand.New(rand.NewSource(93))
+	for range 1000 {
+		b := make([]byte, rnd.Intn(1000))
+		rnd.Read(b)
+		s := snappy(b)
+		if got, err := unsnappy(s, len(b)); err != nil || !bytes.Equal(got, b) {
+			t.Fatalf("unsnappy(snappy(%x)) = %x, %v", b, got, err)
+		}
+		for range 1 + rnd.Intn(3) {
+			s[rnd.Intn(len(s))] = byte(rnd.Intn(256))
+		}
+		if _, err := unsnappy(s, len(b)); err != nil && !errors.Is(err, ErrInvalid) {
+			t.Fatalf("unsnappy(%x) = %v, want ErrInvalid", s, err)
+		}
+	}
+}
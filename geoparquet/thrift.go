@@ -0,0 +1,260 @@
+package geoparquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// The types of the fields of the Thrift compact protocol.
+const (
+	tStop = iota
+	tTrue
+	tFalse
+	tByte
+	tI16
+	tI32
+	tI64
+	tDouble
+	tBinary
+	tList
+	tSet
+	tMap
+	tStruct
+)
+
+// maxDepth is the depth of nesting of Thrift structures beyond which a
+// structure is taken to be invalid, rather than to exhaust the stack.
+const maxDepth = 32
+
+// tstruct is a structure decoded from the Thrift compact protocol, by
+// field ID. Its values are bools, int64s, float64s, []bytes, []anys of
+// lists, sets and maps, whose keys and values alternate, and tstructs.
+type tstruct map[int16]any
+
+// int returns an integer field, or 0 if it is absent.
+func (s tstruct) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+// bool returns a boolean field, or false if it is absent.
+func (s tstruct) bool(id int16) bool {
+	v, _ := s[id].(bool)
+	return v
+}
+
+// bytes returns a binary field, or nil if it is absent.
+func (s tstruct) bytes(id int16) []byte {
+	v, _ := s[id].([]byte)
+	return v
+}
+
+// string returns a binary field as a string, or "" if it is absent.
+func (s tstruct) string(id int16) string {
+	return string(s.bytes(id))
+}
+
+// child returns a structure field, or nil if it is absent.
+func (s tstruct) child(id int16) tstruct {
+	v, _ := s[id].(tstruct)
+	return v
+}
+
+// list returns the elements of a list field, or nil if it is absent.
+func (s tstruct) list(id int16) []any {
+	v, _ := s[id].([]any)
+	return v
+}
+
+// structs returns the structures of a list field.
+func (s tstruct) structs(id int16) []tstruct {
+	var ss []tstruct
+	for _, v := range s.list(id) {
+		if c, ok := v.(tstruct); ok {
+			ss = append(ss, c)
+		}
+	}
+	return ss
+}
+
+// decoder decodes the Thrift compact protocol.
+type decoder struct {
+	b     []byte
+	pos   int
+	depth int
+}
+
+// errorf returns an error wrapping ErrInvalid, telling what is wrong at
+// the position.
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d of a Thrift structure", ErrInvalid, fmt.Sprintf(format, args...), d.pos)
+}
+
+// uvarint decodes an unsigned varint.
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.b[d.pos:])
+	if n <= 0 {
+		return 0, d.errorf("malformed varint")
+	}
+	d.pos += n
+	return v, nil
+}
+
+// varint decodes a zigzag varint.
+func (d *decoder) varint() (int64, error) {
+	v, err := d.uvarint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+// byte decodes a byte.
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.b) {
+		return 0, d.errorf("unexpected end")
+	}
+	d.pos++
+	return d.b[d.pos-1], nil
+}
+
+// size decodes the size of a binary or collection of elements of at
+// least one byte each, which must fit in what remains.
+func (d *decoder) size(v uint64) (int, error) {
+	if v > uint64(len(d.b)-d.pos) {
+		return 0, d.errorf("size %d beyond the end", v)
+	}
+	return int(v), nil
+}
+
+// structure decodes a structure.
+func (d *decoder) structure() (tstruct, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, d.errorf("structures nested too deeply")
+	}
+	defer func() { d.depth-- }()
+	s := tstruct{}
+	var id int16
+	for {
+		h, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		typ := h & 0xf
+		if typ == tStop {
+			return s, nil
+		}
+		if delta := int16(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			v, err := d.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		var v any
+		switch typ {
+		case tTrue:
+			v = true
+		case tFalse:
+			v = false
+		default:
+			if v, err = d.value(typ); err != nil {
+				return nil, err
+			}
+		}
+		s[id] = v
+	}
+}
+
+// value decodes a value of a type.
+func (d *decoder) value(typ byte) (any, error) {
+	switch typ {
+	case tTrue, tFalse:
+		// A boolean element of a collection is a byte.
+		b, err := d.byte()
+		return b == tTrue, err
+	case tByte:
+		b, err := d.byte()
+		return int64(int8(b)), err
+	case tI16, tI32, tI64:
+		return d.varint()
+	case tDouble:
+		if len(d.b)-d.pos < 8 {
+			return nil, d.errorf("unexpected end")
+		}
+		d.pos += 8
+		return math.Float64frombits(binary.LittleEndian.Uint64(d.b[d.pos-8:])), nil
+	case tBinary:
+		v, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		n, err := d.size(v)
+		if err != nil {
+			return nil, err
+		}
+		d.pos += n
+		return d.b[d.pos-n : d.pos], nil
+	case tList, tSet:
+		h, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		v := uint64(h >> 4)
+		if v == 15 {
+			if v, err = d.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		n, err := d.size(v)
+		if err != nil {
+			return nil, err
+		}
+		return d.values(n, h&0xf, 0)
+	case tMap:
+		v, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		n, err := d.size(v)
+		if err != nil || n == 0 {
+			return []any(nil), err
+		}
+		h, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		return d.values(n, h>>4, h&0xf)
+	case tStruct:
+		return d.structure()
+	}
+	return nil, d.errorf("unknown type %d", typ)
+}
+
+// values decodes the n elements of a collection of a type, or of a map
+// of keys and values of two types.
+func (d *decoder) values(n int, typ, valueType byte) ([]any, error) {
+	per := 1
+	if valueType != 0 {
+		per = 2
+	}
+	vs := make([]any, 0, per*n)
+	for range n {
+		for _, t := range []byte{typ, valueType}[:per] {
+			v, err := d.value(t)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+	}
+	return vs, nil
+}
+
+// decodeStruct decodes a structure at the start of b, returning it and
+// its size in bytes.
+func decodeStruct(b []byte) (tstruct, int, error) {
+	d := decoder{b: b}
+	s, err := d.structure()
+	return s, d.pos, err
+}
@@ -0,0 +1,196 @@
+package geoparquet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// tf is a field of a Thrift structure to encode. Its value is a bool, an
+// int32 or int64, a float64, a string or []byte, a []tf of a structure,
+// or a []any of a list, or nil for none.
+type tf struct {
+	id int16
+	v  any
+}
+
+// thrift returns the encoding of a structure of fields in the compact
+// protocol.
+func thrift(fs ...tf) []byte {
+	return appendStruct(nil, fs)
+}
+
+func appendStruct(b []byte, fs []tf) []byte {
+	var last int16
+	for _, f := range fs {
+		if f.v == nil {
+			continue
+		}
+		typ := thriftType(f.v)
+		if v, ok := f.v.(bool); ok && !v {
+			typ = tFalse
+		}
+		if d := f.id - last; d > 0 && d <= 15 {
+			b = append(b, byte(d)<<4|typ)
+		} else {
+			b = binary.AppendVarint(append(b, typ), int64(f.id))
+		}
+		last = f.id
+		if typ != tTrue && typ != tFalse {
+			b = appendThrift(b, f.v)
+		}
+	}
+	return append(b, tStop)
+}
+
+// thriftType returns the type of a value to encode.
+func thriftType(v any) byte {
+	switch v.(type) {
+	case bool:
+		return tTrue
+	case int32:
+		return tI32
+	case int64:
+		return tI64
+	case float64:
+		return tDouble
+	case string, []byte:
+		return tBinary
+	case []tf:
+		return tStruct
+	case []any:
+		return tList
+	}
+	panic(v)
+}
+
+// appendThrift appends a value, but not its type.
+func appendThrift(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return append(b, tTrue)
+		}
+		return append(b, tFalse)
+	case int32:
+		return binary.AppendVarint(b, int64(v))
+	case int64:
+		return binary.AppendVarint(b, v)
+	case float64:
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+	case string:
+		return append(binary.AppendUvarint(b, uint64(len(v))), v...)
+	case []byte:
+		return append(binary.AppendUvarint(b, uint64(len(v))), v...)
+	case []tf:
+		return appendStruct(b, v)
+	case []any:
+		var typ byte = tI32
+		if len(v) > 0 {
+			typ = thriftType(v[0])
+		}
+		if len(v) < 15 {
+			b = append(b, byte(len(v))<<4|typ)
+		} else {
+			b = binary.AppendUvarint(append(b, 0xf0|typ), uint64(len(v)))
+		}
+		for _, e := range v {
+			b = appendThrift(b, e)
+		}
+		return b
+	}
+	panic(v)
+}
+
+func TestDecodeStruct(t *testing.T) {
+	b := unhex(`
+		15 02
+		18 02 6162
+		01 28
+		19 26 02 01
+		1C 17 000000000000F83F 00
+		1B 01 85 01 6B 06
+		1B 00
+		19 21 01 02
+		03 4F 7F
+		00 FF`)
+	want := tstruct{
+		1:   int64(1),
+		2:   []byte("ab"),
+		20:  true,
+		21:  []any{int64(1), int64(-1)},
+		22:  tstruct{1: 1.5},
+		23:  []any{[]byte("k"), int64(3)},
+		24:  []any(nil),
+		25:  []any{true, false},
+		-40: int64(127),
+	}
+	s, n, err := decodeStruct(b)
+	if err != nil || n != len(b)-1 || !reflect.DeepEqual(s, want) {
+		t.Errorf("decodeStruct = %v, %d, %v, want %v, %d", s, n, err, want, len(b)-1)
+	}
+	// The accessors of the fields.
+	if s.int(1) != 1 || s.int(2) != 0 || s.string(2) != "ab" || s.bytes(1) != nil || !s.bool(20) || s.bool(1) ||
+		s.child(22).child(1) != nil || len(s.list(21)) != 2 || s.structs(21) != nil || s.child(7) != nil {
+		t.Errorf("accessors of %v are wrong", s)
+	}
+}
+
+func TestDecodeStructRoundTrip(t *testing.T) {
+	long := make([]any, 20)
+	for i := range long {
+		long[i] = []tf{{1, int32(i)}}
+	}
+	b := thrift(
+		tf{1, int32(-7)}, tf{3, int64(1) << 40}, tf{40, "far"}, tf{41, false},
+		tf{42, []tf{{1, 2.5}, {2, []any{"a", "b"}}}}, tf{43, long},
+	)
+	s, n, err := decodeStruct(b)
+	if err != nil || n != len(b) {
+		t.Fatalf("decodeStruct = %v, %d, %v", s, n, err)
+	}
+	if s.int(1) != -7 || s.int(3) != 1<<40 || s.string(40) != "far" || s[41] != false ||
+		s.child(42)[1] != 2.5 || !reflect.DeepEqual(s.child(42).list(2), []any{[]byte("a"), []byte("b")}) {
+		t.Errorf("decodeStruct = %v", s)
+	}
+	if ss := s.structs(43); len(ss) != 20 || ss[19].int(1) != 19 {
+		t.Errorf("structs of a long list = %v", ss)
+	}
+}
+
+func TestDecodeStructInvalid(t *testing.T) {
+	deep := strings.Repeat("1C", 40)
+	tests := []struct {
+		b   string
+		msg string
+	}{
+		{"", "geoparquet: invalid GeoParquet: unexpected end at offset 0 of a Thrift structure"},
+		{"15", "geoparquet: invalid GeoParquet: malformed varint at offset 1 of a Thrift structure"},
+		{"15 FF", "geoparquet: invalid GeoParquet: malformed varint at offset 1 of a Thrift structure"},
+		{"18 05 6162", "geoparquet: invalid GeoParquet: size 5 beyond the end at offset 2 of a Thrift structure"},
+		{"17 0000", "geoparquet: invalid GeoParquet: unexpected end at offset 1 of a Thrift structure"},
+		{"19 F5 FFFFFFFF0F", "geoparquet: invalid GeoParquet: size 4294967295 beyond the end at offset 7 of a Thrift structure"},
+		{"1B 09", "geoparquet: invalid GeoParquet: size 9 beyond the end at offset 2 of a Thrift structure"},
+		{"1D", "geoparquet: invalid GeoParquet: unknown type 13 at offset 1 of a Thrift structure"},
+		{"19 1D 00", "geoparquet: invalid GeoParquet: unknown type 13 at offset 2 of a Thrift structure"},
+		{deep, "geoparquet: invalid GeoParquet: structures nested too deeply at offset 32 of a Thrift structure"},
+	}
+	for _, tt := range tests {
+		s, _, err := decodeStruct(unhex(tt.b))
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrInvalid) {
+			t.Errorf("decodeStruct(%s) = %v, %v, want error %q", tt.b, s, err, tt.msg)
+		}
+	}
+}
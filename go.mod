@@ -0,0 +1,3 @@
+module github.com/gogama/geospat
+
+go 1.23
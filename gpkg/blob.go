@@ -0,0 +1,60 @@
+package gpkg
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/wkb"
+)
+
+// The flags of the header of a geometry blob.
+const (
+	flagLittleEndian = 1 << 0
+	flagEnvelope     = 7 << 1
+	flagExtended     = 1 << 5
+)
+
+// envelopeSizes holds the sizes in bytes of the envelopes of geometry
+// blobs, by the envelope contents indicator of the flags: none, XY, XYZ,
+// XYM and XYZM.
+var envelopeSizes = [...]int{0, 32, 48, 48, 64}
+
+// Unmarshal returns the geometry of a GeoPackage geometry blob, its
+// layout, and the SRS ID of its coordinate system. The blob is a header,
+// with the SRS ID and an optional envelope, followed by standard WKB,
+// which is read as by wkb.Unmarshal.
+//
+// It returns an error wrapping ErrInvalid if the blob or its WKB is
+// malformed, and one wrapping ErrUnsupported if the geometry is of an
+// extension type.
+func Unmarshal(data []byte) (geom.Geometry, geom.Layout, int, error) {
+	if len(data) < 8 || data[0] != 'G' || data[1] != 'P' {
+		return nil, geom.XY, 0, fmt.Errorf("%w: geometry blob has no GP magic", ErrInvalid)
+	}
+	if data[2] != 0 {
+		return nil, geom.XY, 0, fmt.Errorf("%w: geometry blob version %d", ErrUnsupported, data[2])
+	}
+	flags := data[3]
+	if flags&flagExtended != 0 {
+		return nil, geom.XY, 0, fmt.Errorf("%w: extended geometry blob", ErrUnsupported)
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if flags&flagLittleEndian != 0 {
+		order = binary.LittleEndian
+	}
+	srsID := int(int32(order.Uint32(data[4:])))
+	e := int(flags&flagEnvelope) >> 1
+	if e >= len(envelopeSizes) {
+		return nil, geom.XY, 0, fmt.Errorf("%w: geometry blob envelope indicator %d", ErrInvalid, e)
+	}
+	n := 8 + envelopeSizes[e]
+	if len(data) < n {
+		return nil, geom.XY, 0, fmt.Errorf("%w: geometry blob of %d bytes has no room for its envelope", ErrInvalid, len(data))
+	}
+	g, layout, _, err := wkb.Unmarshal(data[n:])
+	if err != nil {
+		return nil, geom.XY, 0, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return g, layout, srsID, nil
+}
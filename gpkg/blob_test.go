@@ -0,0 +1,139 @@
+package gpkg
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name   string
+		blob   string
+		want   geom.Geometry
+		layout geom.Layout
+		srsID  int
+	}{
+		{
+			"little endian",
+			"47500001 E6100000 0101000000 000000000000F03F 0000000000000040",
+			geom.Point{X: 1, Y: 2}, geom.XY, 4326,
+		},
+		{
+			"big endian",
+			"47500000 000010E6 0000000001 3FF0000000000000 4000000000000000",
+			geom.Point{X: 1, Y: 2}, geom.XY, 4326,
+		},
+		{
+			// The header is big endian, and the WKB little endian.
+			"mixed",
+			"47500000 00000F11 0101000000 000000000000F03F 0000000000000040",
+			geom.Point{X: 1, Y: 2}, geom.XY, 3857,
+		},
+		{
+			"envelope XY",
+			`47500003 E6100000
+			000000000000F03F 0000000000000840 0000000000000040 0000000000001040
+			010200000002000000 000000000000F03F 0000000000000040 0000000000000840 0000000000001040`,
+			geom.LineString{{X: 1, Y: 2}, {X: 3, Y: 4}}, geom.XY, 4326,
+		},
+		{
+			"envelope XYZ",
+			`47500005 00000000
+			000000000000F03F 000000000000F03F 0000000000000040 0000000000000040 0000000000000840 0000000000000840
+			01E9030000 000000000000F03F 0000000000000040 0000000000000840`,
+			geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, 0,
+		},
+		{
+			"envelope XYZM",
+			`47500009 FFFFFFFF
+			0000000000000000 0000000000000000 0000000000000000 0000000000000000
+			0000000000000000 0000000000000000 0000000000000000 0000000000000000
+			01B90B0000 000000000000F03F 0000000000000040 0000000000000840 0000000000001040`,
+			geom.Point{X: 1, Y: 2, Z: 3, M: 4}, geom.XYZM, -1,
+		},
+		{
+			"Polygon",
+			`47500001 E6100000 010300000001000000 04000000
+			0000000000000000 0000000000000000 000000000000F03F 0000000000000000
+			000000000000F03F 000000000000F03F 0000000000000000 0000000000000000`,
+			geom.Polygon{{{}, {X: 1}, {X: 1, Y: 1}, {}}}, geom.XY, 4326,
+		},
+	}
+	for _, tt := range tests {
+		g, layout, srsID, err := Unmarshal(unhex(tt.blob))
+		if err != nil {
+			t.Errorf("%s: Unmarshal: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(g, tt.want) || layout != tt.layout || srsID != tt.srsID {
+			t.Errorf("%s: Unmarshal = %v, %v, %d, want %v, %v, %d", tt.name, g, layout, srsID, tt.want, tt.layout, tt.srsID)
+		}
+	}
+}
+
+func TestUnmarshalEmpty(t *testing.T) {
+	// An empty point has the empty flag, and NaN coordinates.
+	g, _, _, err := Unmarshal(unhex("47500011 E6100000 0101000000 000000000000F87F 000000000000F87F"))
+	if p, ok := g.(geom.Point); err != nil || !ok || !p.IsEmpty() {
+		t.Errorf("Unmarshal of an empty point = %v, %v, want the empty point", g, err)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		blob string
+		err  error
+		msg  string
+	}{
+		{"", ErrInvalid, "gpkg: invalid GeoPackage: geometry blob has no GP magic"},
+		{"47500001 E61000", ErrInvalid, "gpkg: invalid GeoPackage: geometry blob has no GP magic"},
+		{"47510001 E6100000 0101000000", ErrInvalid, "gpkg: invalid GeoPackage: geometry blob has no GP magic"},
+		{"0101000000 000000000000F03F 0000000000000040", ErrInvalid, "gpkg: invalid GeoPackage: geometry blob has no GP magic"},
+		{"47500101 E6100000 0101000000", ErrUnsupported, "gpkg: unsupported GeoPackage: geometry blob version 1"},
+		{"47500021 E6100000 0101000000", ErrUnsupported, "gpkg: unsupported GeoPackage: extended geometry blob"},
+		{"4750000B E6100000 0101000000", ErrInvalid, "gpkg: invalid GeoPackage: geometry blob envelope indicator 5"},
+		{"4750000F E6100000 0101000000", ErrInvalid, "gpkg: invalid GeoPackage: geometry blob envelope indicator 7"},
+		{"47500003 E6100000 000000000000F03F", ErrInvalid, "gpkg: invalid GeoPackage: geometry blob of 16 bytes has no room for its envelope"},
+		{"47500001 E6100000", ErrInvalid, "gpkg: invalid GeoPackage: wkb: invalid WKB: unexpected end of data at offset 0"},
+		{"47500001 E6100000 0101000000 000000000000F03F", ErrInvalid, "gpkg: invalid GeoPackage: wkb: invalid WKB: unexpected end of data at offset 5"},
+	}
+	for _, tt := range tests {
+		g, _, _, err := Unmarshal(unhex(tt.blob))
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, tt.err) {
+			t.Errorf("Unmarshal(%s) = %v, %v, want error %q", tt.blob, g, err, tt.msg)
+		}
+	}
+}
+
+func TestUnmarshalCorrupt(t *testing.T) {
+	// Corrupted blobs are errors, or geometries, but never panics.
+	valid := unhex(`47500003 E6100000
+		000000000000F03F 0000000000000840 0000000000000040 0000000000001040
+		010200000002000000 000000000000F03F 0000000000000040 0000000000000840 0000000000001040`)
+	rnd := rand.New(rand.NewSource(89))
+	for range 10000 {
+		b := append([]byte(nil), valid[:rnd.Intn(len(valid)+1)]...)
+		for range 1 + rnd.Intn(3) {
+			if len(b) > 0 {
+				b[rnd.Intn(len(b))] = byte(rnd.Intn(256))
+			}
+		}
+		if _, _, _, err := Unmarshal(b); err != nil && !errors.Is(err, ErrInvalid) && !errors.Is(err, ErrUnsupported) {
+			t.Fatalf("Unmarshal(%x) = %v, want ErrInvalid or ErrUnsupported", b, err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package gpkg reads the feature tables of GeoPackages, the SQLite
+// databases of the OGC GeoPackage Encoding Standard.
+//
+// A GeoPackage lists its layers in the gpkg_contents and
+// gpkg_geometry_columns tables, and stores the geometry of each feature
+// as a blob of a short header and WKB, which Unmarshal reads. The
+// standard library has no SQLite driver, so the functions of this
+// package read a GeoPackage through a *sql.DB opened with any
+// database/sql driver for SQLite, such as modernc.org/sqlite or
+// github.com/mattn/go-sqlite3, which the caller imports.
+//
+// The standard is described at https://www.geopackage.org/spec/.
+package gpkg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/gogama/geospat/geom"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, when a database is not a valid
+	// GeoPackage, or a geometry blob is malformed.
+	ErrInvalid = errors.New("gpkg: invalid GeoPackage")
+
+	// ErrUnsupported is returned, wrapped, for a geometry blob of an
+	// extension type or a later version.
+	ErrUnsupported = errors.New("gpkg: unsupported GeoPackage")
+)
+
+// Layer is a feature table of a GeoPackage.
+type Layer struct {
+	// Table is the name of the table.
+	Table string
+
+	// Identifier and Description are the human readable name and
+	// description of the layer, which may be empty.
+	Identifier, Description string
+
+	// GeometryColumn is the name of the column of the geometries, and
+	// GeometryType the name of their type, such as "POLYGON" or
+	// "GEOMETRY" for any type.
+	GeometryColumn, GeometryType string
+
+	// Layout is the layout of the geometries: it has Z or M if they are
+	// mandatory or optional for the layer.
+	Layout geom.Layout
+
+	// SRSID is the ID of the coordinate system of the geometries in the
+	// gpkg_spatial_ref_sys table.
+	SRSID int
+
+	// Bounds are the bounds of the features, which are informative and
+	// may be empty if they are unknown.
+	Bounds geom.Rect
+}
+
+// Feature is a row of a feature table.
+type Feature struct {
+	// ID is the integer primary key of the row.
+	ID int64
+
+	// Geometry and Layout are the geometry of the row and its layout.
+	// Geometry is nil if the geometry is NULL.
+	Geometry geom.Geometry
+	Layout   geom.Layout
+
+	// Attributes are the values of the other columns of the row, by
+	// name, as the driver returns them: nil, an int64, a float64, a
+	// string, a []byte, a bool or a time.Time.
+	Attributes map[string]any
+}
+
+// Layers returns the feature tables of a GeoPackage, in the order of
+// their names. It returns an error wrapping ErrInvalid if the database
+// has no gpkg_contents or gpkg_geometry_columns table.
+func Layers(ctx context.Context, db *sql.DB) ([]Layer, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.table_name, c.identifier, c.description,
+			c.min_x, c.min_y, c.max_x, c.max_y,
+			g.column_name, g.geometry_type_name, g.srs_id, g.z, g.m
+		FROM gpkg_contents c
+		JOIN gpkg_geometry_columns g ON g.table_name = c.table_name
+		WHERE c.data_type = 'features'
+		ORDER BY c.table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	defer rows.Close()
+	var layers []Layer
+	for rows.Next() {
+		var l Layer
+		var identifier, description sql.NullString
+		var minX, minY, maxX, maxY sql.NullFloat64
+		var z, m int
+		if err := rows.Scan(&l.Table, &identifier, &description, &minX, &minY, &maxX, &maxY,
+			&l.GeometryColumn, &l.GeometryType, &l.SRSID, &z, &m); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+		l.Identifier, l.Description = identifier.String, description.String
+		l.Bounds = geom.EmptyRect()
+		if minX.Valid && minY.Valid && maxX.Valid && maxY.Valid {
+			l.Bounds = geom.Rect{MinX: minX.Float64, MinY: minY.Float64, MaxX: maxX.Float64, MaxY: maxY.Float64}
+		}
+		// z and m are 0 if prohibited, 1 if mandatory and 2 if optional.
+		if z != 0 {
+			l.Layout |= geom.XYZ
+		}
+		if m != 0 {
+			l.Layout |= geom.XYM
+		}
+		layers = append(layers, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+// Features returns an iterator over the features of a layer, which
+// reads them from the database as it is iterated and stops after the
+// first error. A geometry blob which is malformed is an error wrapping
+// ErrInvalid.
+func Features(ctx context.Context, db *sql.DB, l Layer) iter.Seq2[*Feature, error] {
+	return func(yield func(*Feature, error) bool) {
+		pk, err := primaryKey(ctx, db, l.Table)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		rows, err := db.QueryContext(ctx, "SELECT * FROM "+quote(l.Table))
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rows.Close()
+		columns, err := rows.Columns()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(ptrs...); err != nil {
+				yield(nil, err)
+				return
+			}
+			f, err := feature(l, pk, columns, values)
+			if !yield(f, err) || err != nil {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// feature returns the feature of the values of a row.
+func feature(l Layer, pk string, columns []string, values []any) (*Feature, error) {
+	f := &Feature{Layout: l.Layout, Attributes: make(map[string]any, len(columns))}
+	for i, c := range columns {
+		switch v := values[i]; {
+		case strings.EqualFold(c, pk):
+			id, ok := v.(int64)
+			if !ok {
+				return nil, fmt.Errorf("%w: primary key %s of %s is %T, not an integer", ErrInvalid, pk, l.Table, v)
+			}
+			f.ID = id
+		case strings.EqualFold(c, l.GeometryColumn):
+			if v == nil {
+				continue
+			}
+			b, ok := v.([]byte)
+			if !ok {
+				return nil, fmt.Errorf("%w: geometry of %s is %T, not a blob", ErrInvalid, l.Table, v)
+			}
+			var err error
+			if f.Geometry, f.Layout, _, err = Unmarshal(b); err != nil {
+				return nil, fmt.Errorf("%w: feature %d of %s", err, f.ID, l.Table)
+			}
+		default:
+			f.Attributes[c] = v
+		}
+	}
+	return f, nil
+}
+
+// primaryKey returns the name of the integer primary key column of a
+// table.
+func primaryKey(ctx context.Context, db *sql.DB, table string) (string, error) {
+	var name string
+	err := db.QueryRowContext(ctx, "SELECT name FROM pragma_table_info(?) WHERE pk = 1", table).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: feature table %s has no integer primary key", ErrInvalid, table)
+	}
+	return name, err
+}
+
+// quote returns an SQL identifier quoted.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,287 @@
+package gpkg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// fakeDB is a GeoPackage served by a database/sql driver in answer to
+// the queries of this package, the standard library having no driver
+// for SQLite.
+type fakeDB struct {
+	// contents are the rows of the query of Layers, or nil if there is
+	// no gpkg_contents table.
+	contents [][]driver.Value
+	// pks are the primary keys of the tables, by name.
+	pks    map[string]string
+	tables map[string]fakeTable
+}
+
+// fakeTable is a table of columns and rows.
+type fakeTable struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// Connect implements driver.Connector.
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+
+// Driver implements driver.Connector.
+func (db *fakeDB) Driver() driver.Driver { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("read only")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	switch q := s.query; {
+	case strings.Contains(q, "FROM gpkg_contents"):
+		if s.db.contents == nil {
+			return nil, errors.New("no such table: gpkg_contents")
+		}
+		return &fakeRows{columns: make([]string, 12), rows: s.db.contents}, nil
+	case strings.Contains(q, "pragma_table_info"):
+		r := &fakeRows{columns: []string{"name"}}
+		if pk, ok := s.db.pks[args[0].(string)]; ok {
+			r.rows = [][]driver.Value{{pk}}
+		}
+		return r, nil
+	case strings.HasPrefix(q, "SELECT * FROM "):
+		name := strings.ReplaceAll(strings.Trim(q[len("SELECT * FROM "):], `"`), `""`, `"`)
+		t, ok := s.db.tables[name]
+		if !ok {
+			return nil, errors.New("no such table: " + name)
+		}
+		return &fakeRows{columns: t.columns, rows: t.rows}, nil
+	}
+	return nil, errors.New("unexpected query " + s.query)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// point is the geometry blob of POINT (1 2) in WGS 84.
+var point = unhex("47500001 E6100000 0101000000 000000000000F03F 0000000000000040")
+
+// pointZ is the geometry blob of POINT Z (1 2 3) in WGS 84.
+var pointZ = unhex("47500001 E6100000 01E9030000 000000000000F03F 0000000000000040 0000000000000840")
+
+// sample is a GeoPackage of two layers.
+func sample() *fakeDB {
+	return &fakeDB{
+		contents: [][]driver.Value{
+			{"roads", "Roads", "", -10.5, 40.0, 5.0, 52.25, "geom", "LINESTRING", int64(4326), int64(0), int64(0)},
+			{"wells", nil, nil, nil, nil, nil, nil, "shape", "POINT", int64(4326), int64(2), int64(1)},
+		},
+		pks: map[string]string{"wells": "fid"},
+		tables: map[string]fakeTable{
+			"wells": {
+				columns: []string{"fid", "shape", "name", "depth"},
+				rows: [][]driver.Value{
+					{int64(1), pointZ, "North", 12.5},
+					{int64(2), nil, "South", nil},
+					{int64(3), point, []byte("raw"), int64(7)},
+				},
+			},
+		},
+	}
+}
+
+func TestLayers(t *testing.T) {
+	db := sql.OpenDB(sample())
+	defer db.Close()
+	layers, err := Layers(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Layer{
+		{
+			Table: "roads", Identifier: "Roads", GeometryColumn: "geom", GeometryType: "LINESTRING",
+			Layout: geom.XY, SRSID: 4326, Bounds: geom.Rect{MinX: -10.5, MinY: 40, MaxX: 5, MaxY: 52.25},
+		},
+		{
+			Table: "wells", GeometryColumn: "shape", GeometryType: "POINT",
+			Layout: geom.XYZM, SRSID: 4326, Bounds: geom.EmptyRect(),
+		},
+	}
+	if !reflect.DeepEqual(layers, want) {
+		t.Errorf("Layers = %+v, want %+v", layers, want)
+	}
+}
+
+func TestLayersInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		db   *fakeDB
+		err  string
+	}{
+		{"no tables", &fakeDB{}, "gpkg: invalid GeoPackage: no such table: gpkg_contents"},
+		{"bad SRS ID", &fakeDB{contents: [][]driver.Value{
+			{"roads", nil, nil, nil, nil, nil, nil, "geom", "LINESTRING", "WGS 84", int64(0), int64(0)},
+		}}, `gpkg: invalid GeoPackage: sql: Scan error on column index 9, name "": converting driver.Value type string ("WGS 84") to a int: invalid syntax`},
+	}
+	for _, tt := range tests {
+		db := sql.OpenDB(tt.db)
+		layers, err := Layers(context.Background(), db)
+		if err == nil || err.Error() != tt.err || !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Layers = %v, %v, want error %q", tt.name, layers, err, tt.err)
+		}
+		db.Close()
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	db := sql.OpenDB(sample())
+	defer db.Close()
+	layers, err := Layers(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fs []*Feature
+	for f, err := range Features(context.Background(), db, layers[1]) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, f)
+	}
+	want := []*Feature{
+		{ID: 1, Geometry: geom.Point{X: 1, Y: 2, Z: 3}, Layout: geom.XYZ, Attributes: map[string]any{"name": "North", "depth": 12.5}},
+		{ID: 2, Layout: geom.XYZM, Attributes: map[string]any{"name": "South", "depth": nil}},
+		{ID: 3, Geometry: geom.Point{X: 1, Y: 2}, Layout: geom.XY, Attributes: map[string]any{"name": []byte("raw"), "depth": int64(7)}},
+	}
+	if len(fs) != len(want) {
+		t.Fatalf("Features = %d features, want %d", len(fs), len(want))
+	}
+	for i := range fs {
+		if !reflect.DeepEqual(fs[i], want[i]) {
+			t.Errorf("feature %d = %+v, want %+v", i, fs[i], want[i])
+		}
+	}
+}
+
+func TestFeaturesStop(t *testing.T) {
+	db := sql.OpenDB(sample())
+	defer db.Close()
+	l := Layer{Table: "wells", GeometryColumn: "shape"}
+	n := 0
+	for range Features(context.Background(), db, l) {
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("Features yielded %d features before the break, want 2", n)
+	}
+	// The connection was released by the break.
+	if s := db.Stats(); s.InUse != 0 {
+		t.Errorf("Features left %d connections in use", s.InUse)
+	}
+}
+
+func TestFeaturesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		table fakeTable
+		pk    string
+		n     int // The number of features before the error
+		err   string
+	}{
+		{
+			"no primary key", fakeTable{columns: []string{"fid", "geom"}}, "", 0,
+			"gpkg: invalid GeoPackage: feature table t has no integer primary key",
+		},
+		{
+			"text primary key", fakeTable{columns: []string{"fid", "geom"}, rows: [][]driver.Value{{"a", point}}}, "fid", 0,
+			"gpkg: invalid GeoPackage: primary key fid of t is string, not an integer",
+		},
+		{
+			"text geometry", fakeTable{columns: []string{"fid", "geom"}, rows: [][]driver.Value{{int64(1), point}, {int64(2), "POINT (1 2)"}}}, "fid", 1,
+			"gpkg: invalid GeoPackage: geometry of t is string, not a blob",
+		},
+		{
+			"bad blob", fakeTable{columns: []string{"FID", "GEOM"}, rows: [][]driver.Value{{int64(7), point[:12]}, {int64(8), point}}}, "fid", 0,
+			"gpkg: invalid GeoPackage: wkb: invalid WKB: unexpected end of data at offset 1: feature 7 of t",
+		},
+	}
+	for _, tt := range tests {
+		fdb := &fakeDB{pks: map[string]string{}, tables: map[string]fakeTable{"t": tt.table}}
+		if tt.pk != "" {
+			fdb.pks["t"] = tt.pk
+		}
+		db := sql.OpenDB(fdb)
+		n := 0
+		var err error
+		for f, e := range Features(context.Background(), db, Layer{Table: "t", GeometryColumn: "geom"}) {
+			if e != nil {
+				if f != nil || err != nil {
+					t.Errorf("%s: Features yielded %v, %v after %v", tt.name, f, e, err)
+				}
+				err = e
+				continue
+			}
+			n++
+		}
+		if n != tt.n || err == nil || err.Error() != tt.err || !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Features = %d features, %v, want %d, %q", tt.name, n, err, tt.n, tt.err)
+		}
+		db.Close()
+	}
+}
+
+func TestFeaturesMissingTable(t *testing.T) {
+	db := sql.OpenDB(&fakeDB{pks: map[string]string{`a"b`: "fid"}})
+	defer db.Close()
+	for f, err := range Features(context.Background(), db, Layer{Table: `a"b`}) {
+		if f != nil || err == nil || err.Error() != `no such table: a"b` {
+			t.Errorf(`Features of a missing table = %v, %v, want error "no such table: a"b"`, f, err)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"roads", `"roads"`},
+		{`a"b`, `"a""b"`},
+		{"", `""`},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.name); got != tt.want {
+			t.Errorf("quote(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
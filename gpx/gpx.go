@@ -0,0 +1,342 @@
+// Package gpx reads and writes GPX 1.1, the GPS Exchange Format in
+// which GPS receivers, fitness trackers and mapping applications
+// exchange waypoints, routes and tracks.
+//
+// A GPX document holds waypoints, which are points of interest, routes,
+// which are planned sequences of points, and tracks, which are recorded
+// sequences of points divided into segments where recording stopped and
+// resumed. Each point has a longitude and latitude, and optionally an
+// elevation and a time. Line and MultiLine map the points of routes and
+// tracks onto line strings of package geom, with the elevations as Z
+// and the times as M, and Waypoints maps them back.
+//
+// The format is described at https://www.topografix.com/GPX/1/1/.
+package gpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Namespace is the XML namespace of GPX 1.1.
+const Namespace = "http://www.topografix.com/GPX/1/1"
+
+// ErrInvalid is returned, wrapped, by Read when a document is not valid
+// GPX.
+var ErrInvalid = errors.New("gpx: invalid GPX")
+
+// GPX is a GPX document.
+type GPX struct {
+	// Creator is the name of the software which created the document,
+	// written as "geospat" if it is empty.
+	Creator string
+
+	// Name and Description are the name and description of the
+	// document, from its metadata.
+	Name, Description string
+
+	// Time is the time the document was created, from its metadata, or
+	// the zero time if it has none.
+	Time time.Time
+
+	Waypoints []Waypoint
+	Routes    []Route
+	Tracks    []Track
+}
+
+// Waypoint is a point: a waypoint of a document, or a point of a route
+// or track.
+type Waypoint struct {
+	// Point holds the longitude X and the latitude Y of the point in
+	// degrees, and its elevation Z in meters if HasElevation is true.
+	Point geom.Point
+
+	// HasElevation reports whether the point has an elevation.
+	HasElevation bool
+
+	// Time is the time of the point, or the zero time if it has none.
+	Time time.Time
+
+	// Name, Comment, Description, Symbol and Type are the optional
+	// names and descriptions of the point.
+	Name, Comment, Description, Symbol, Type string
+}
+
+// Route is a route: a sequence of points leading to a destination.
+type Route struct {
+	Name, Comment, Description, Type string
+	Points                           []Waypoint
+}
+
+// Line returns the points of the route as a line string, as for Line.
+func (r Route) Line() (geom.LineString, geom.Layout) {
+	return Line(r.Points)
+}
+
+// Track is a track: a recorded path, in segments of points recorded
+// continuously.
+type Track struct {
+	Name, Comment, Description, Type string
+	Segments                         [][]Waypoint
+}
+
+// MultiLine returns the segments of the track as a multiline string,
+// with a line string for each segment as for Line, and the layout of
+// the whole track.
+func (t Track) MultiLine() (geom.MultiLineString, geom.Layout) {
+	m := make(geom.MultiLineString, len(t.Segments))
+	layout := geom.XY
+	for i, s := range t.Segments {
+		var l geom.Layout
+		m[i], l = Line(s)
+		layout |= l // The union of the layouts
+	}
+	return m, layout
+}
+
+// Line returns a line string of points, and its layout. The Z of each
+// point of the line is the elevation of the point, or 0 if it has none,
+// and the M its time in seconds since the Unix epoch, or 0 if it has
+// none. The layout has Z if any point has an elevation and M if any has
+// a time.
+func Line(ps []Waypoint) (geom.LineString, geom.Layout) {
+	l := make(geom.LineString, len(ps))
+	layout := geom.XY
+	for i, p := range ps {
+		l[i] = geom.Point{X: p.Point.X, Y: p.Point.Y}
+		if p.HasElevation {
+			l[i].Z = p.Point.Z
+			layout |= geom.XYZ
+		}
+		if !p.Time.IsZero() {
+			l[i].M = float64(p.Time.Unix()) + float64(p.Time.Nanosecond())/1e9
+			layout |= geom.XYM
+		}
+	}
+	return l, layout
+}
+
+// Waypoints returns the points of a line string of a layout, with
+// elevations from Z if the layout has Z and times from M, in seconds
+// since the Unix epoch, if it has M. It is the inverse of Line, times
+// being rounded to the microsecond.
+func Waypoints(l geom.LineString, layout geom.Layout) []Waypoint {
+	ps := make([]Waypoint, len(l))
+	for i, p := range l {
+		ps[i].Point = geom.Point{X: p.X, Y: p.Y}
+		if layout.HasZ() {
+			ps[i].Point.Z, ps[i].HasElevation = p.Z, true
+		}
+		if layout.HasM() {
+			sec, frac := math.Modf(p.M)
+			ps[i].Time = time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3).UTC()
+		}
+	}
+	return ps
+}
+
+// The XML elements of GPX, in the order of the schema.
+type (
+	xmlGPX struct {
+		XMLName  xml.Name     `xml:"gpx"`
+		Xmlns    string       `xml:"xmlns,attr,omitempty"`
+		Version  string       `xml:"version,attr"`
+		Creator  string       `xml:"creator,attr"`
+		Metadata *xmlMetadata `xml:"metadata"`
+		Wpt      []xmlWpt     `xml:"wpt"`
+		Rte      []xmlRte     `xml:"rte"`
+		Trk      []xmlTrk     `xml:"trk"`
+	}
+	xmlMetadata struct {
+		Name string `xml:"name,omitempty"`
+		Desc string `xml:"desc,omitempty"`
+		Time string `xml:"time,omitempty"`
+	}
+	xmlWpt struct {
+		Lat  string `xml:"lat,attr"`
+		Lon  string `xml:"lon,attr"`
+		Ele  string `xml:"ele,omitempty"`
+		Time string `xml:"time,omitempty"`
+		Name string `xml:"name,omitempty"`
+		Cmt  string `xml:"cmt,omitempty"`
+		Desc string `xml:"desc,omitempty"`
+		Sym  string `xml:"sym,omitempty"`
+		Type string `xml:"type,omitempty"`
+	}
+	xmlRte struct {
+		Name  string   `xml:"name,omitempty"`
+		Cmt   string   `xml:"cmt,omitempty"`
+		Desc  string   `xml:"desc,omitempty"`
+		Type  string   `xml:"type,omitempty"`
+		Rtept []xmlWpt `xml:"rtept"`
+	}
+	xmlTrk struct {
+		Name   string      `xml:"name,omitempty"`
+		Cmt    string      `xml:"cmt,omitempty"`
+		Desc   string      `xml:"desc,omitempty"`
+		Type   string      `xml:"type,omitempty"`
+		Trkseg []xmlTrkseg `xml:"trkseg"`
+	}
+	xmlTrkseg struct {
+		Trkpt []xmlWpt `xml:"trkpt"`
+	}
+)
+
+// Read reads a GPX document. The elements of the document which GPX
+// does not map, such as links and extensions, are skipped, and GPX 1.0
+// documents are read as well, their elements being the same. It returns
+// an error wrapping ErrInvalid if the document is not well formed XML
+// with a gpx root element, or a point has no valid latitude and
+// longitude, or an elevation or time is malformed.
+func Read(r io.Reader) (*GPX, error) {
+	var x xmlGPX
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	g := &GPX{Creator: x.Creator}
+	var err error
+	if m := x.Metadata; m != nil {
+		g.Name, g.Description = m.Name, m.Desc
+		if g.Time, err = parseTime(m.Time); err != nil {
+			return nil, err
+		}
+	}
+	if g.Waypoints, err = readPoints(x.Wpt); err != nil {
+		return nil, err
+	}
+	for _, r := range x.Rte {
+		ps, err := readPoints(r.Rtept)
+		if err != nil {
+			return nil, err
+		}
+		g.Routes = append(g.Routes, Route{Name: r.Name, Comment: r.Cmt, Description: r.Desc, Type: r.Type, Points: ps})
+	}
+	for _, t := range x.Trk {
+		tr := Track{Name: t.Name, Comment: t.Cmt, Description: t.Desc, Type: t.Type}
+		for _, s := range t.Trkseg {
+			ps, err := readPoints(s.Trkpt)
+			if err != nil {
+				return nil, err
+			}
+			tr.Segments = append(tr.Segments, ps)
+		}
+		g.Tracks = append(g.Tracks, tr)
+	}
+	return g, nil
+}
+
+// readPoints converts the XML of points.
+func readPoints(xs []xmlWpt) ([]Waypoint, error) {
+	if len(xs) == 0 {
+		return nil, nil
+	}
+	ps := make([]Waypoint, len(xs))
+	for i, x := range xs {
+		// The values of XML Schema may be surrounded by white space.
+		lat, err := strconv.ParseFloat(strings.TrimSpace(x.Lat), 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return nil, fmt.Errorf("%w: latitude %q", ErrInvalid, x.Lat)
+		}
+		lon, err := strconv.ParseFloat(strings.TrimSpace(x.Lon), 64)
+		if err != nil || lon < -180 || lon > 180 {
+			return nil, fmt.Errorf("%w: longitude %q", ErrInvalid, x.Lon)
+		}
+		p := Waypoint{
+			Point: geom.Point{X: lon, Y: lat},
+			Name:  x.Name, Comment: x.Cmt, Description: x.Desc, Symbol: x.Sym, Type: x.Type,
+		}
+		if ele := strings.TrimSpace(x.Ele); ele != "" {
+			if p.Point.Z, err = strconv.ParseFloat(ele, 64); err != nil {
+				return nil, fmt.Errorf("%w: elevation %q", ErrInvalid, x.Ele)
+			}
+			p.HasElevation = true
+		}
+		if p.Time, err = parseTime(x.Time); err != nil {
+			return nil, err
+		}
+		ps[i] = p
+	}
+	return ps, nil
+}
+
+// parseTime parses a time in the format of XML Schema, or returns the
+// zero time if it is empty.
+func parseTime(s string) (time.Time, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		// Times without a zone are taken to be UTC, as GPX requires.
+		if t, err = time.Parse("2006-01-02T15:04:05.999999999", s); err != nil {
+			return time.Time{}, fmt.Errorf("%w: time %q", ErrInvalid, s)
+		}
+	}
+	return t, nil
+}
+
+// Write writes a GPX 1.1 document, indented, with times in UTC.
+func Write(w io.Writer, g *GPX) error {
+	x := xmlGPX{Xmlns: Namespace, Version: "1.1", Creator: g.Creator}
+	if x.Creator == "" {
+		x.Creator = "geospat"
+	}
+	if g.Name != "" || g.Description != "" || !g.Time.IsZero() {
+		x.Metadata = &xmlMetadata{Name: g.Name, Desc: g.Description, Time: formatTime(g.Time)}
+	}
+	x.Wpt = writePoints(g.Waypoints)
+	for _, r := range g.Routes {
+		x.Rte = append(x.Rte, xmlRte{Name: r.Name, Cmt: r.Comment, Desc: r.Description, Type: r.Type, Rtept: writePoints(r.Points)})
+	}
+	for _, t := range g.Tracks {
+		xt := xmlTrk{Name: t.Name, Cmt: t.Comment, Desc: t.Description, Type: t.Type}
+		for _, s := range t.Segments {
+			xt.Trkseg = append(xt.Trkseg, xmlTrkseg{Trkpt: writePoints(s)})
+		}
+		x.Trk = append(x.Trk, xt)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(x); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writePoints converts points to XML.
+func writePoints(ps []Waypoint) []xmlWpt {
+	xs := make([]xmlWpt, len(ps))
+	for i, p := range ps {
+		xs[i] = xmlWpt{
+			Lat:  strconv.FormatFloat(p.Point.Y, 'f', -1, 64),
+			Lon:  strconv.FormatFloat(p.Point.X, 'f', -1, 64),
+			Time: formatTime(p.Time),
+			Name: p.Name, Cmt: p.Comment, Desc: p.Description, Sym: p.Symbol, Type: p.Type,
+		}
+		if p.HasElevation {
+			xs[i].Ele = strconv.FormatFloat(p.Point.Z, 'f', -1, 64)
+		}
+	}
+	return xs
+}
+
+// formatTime formats a time in UTC in the format of XML Schema, or
+// returns "" for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
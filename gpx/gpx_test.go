@@ -0,0 +1,369 @@
+package gpx
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// sample is a GPX 1.1 document of the kind written by GPS receivers,
+// with elements which Read skips.
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="Garmin Connect">
+  <metadata>
+    <name>Morning Ride</name>
+    <link href="https://connect.garmin.com"><text>Garmin Connect</text></link>
+    <time>2024-05-01T06:30:00Z</time>
+  </metadata>
+  <wpt lat="47.644548" lon="-122.326897">
+    <ele>4.46</ele>
+    <time>2024-05-01T06:29:00Z</time>
+    <name>Start</name>
+    <sym>Flag, Blue</sym>
+  </wpt>
+  <rte>
+    <name>Lake Union</name>
+    <rtept lat="47.6446" lon="-122.3269"><name>A</name></rtept>
+    <rtept lat="47.6389" lon="-122.3308"><name>B</name></rtept>
+  </rte>
+  <trk>
+    <name>Morning Ride</name>
+    <type>cycling</type>
+    <trkseg>
+      <trkpt lat="47.644548" lon="-122.326897">
+        <ele>4.46</ele>
+        <time>2024-05-01T06:30:00Z</time>
+        <extensions><hr>92</hr></extensions>
+      </trkpt>
+      <trkpt lat="47.644549" lon="-122.326898">
+        <ele>
+          4.94
+        </ele>
+        <time>2024-05-01T08:30:01.5+02:00</time>
+      </trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="47.6" lon="-122.3"/>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestRead(t *testing.T) {
+	g, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)
+	want := &GPX{
+		Creator: "Garmin Connect",
+		Name:    "Morning Ride",
+		Time:    t0,
+		Waypoints: []Waypoint{{
+			Point:        geom.Point{X: -122.326897, Y: 47.644548, Z: 4.46},
+			HasElevation: true,
+			Time:         t0.Add(-time.Minute),
+			Name:         "Start",
+			Symbol:       "Flag, Blue",
+		}},
+		Routes: []Route{{
+			Name: "Lake Union",
+			Points: []Waypoint{
+				{Point: geom.Point{X: -122.3269, Y: 47.6446}, Name: "A"},
+				{Point: geom.Point{X: -122.3308, Y: 47.6389}, Name: "B"},
+			},
+		}},
+		Tracks: []Track{{
+			Name: "Morning Ride",
+			Type: "cycling",
+			Segments: [][]Waypoint{
+				{
+					{Point: geom.Point{X: -122.326897, Y: 47.644548, Z: 4.46}, HasElevation: true, Time: t0},
+					{Point: geom.Point{X: -122.326898, Y: 47.644549, Z: 4.94}, HasElevation: true, Time: t0.Add(1500 * time.Millisecond)},
+				},
+				{{Point: geom.Point{X: -122.3, Y: 47.6}}},
+			},
+		}},
+	}
+	// Times in other zones are equal, but not deeply equal, to UTC.
+	tp := &g.Tracks[0].Segments[0][1].Time
+	if !tp.Equal(want.Tracks[0].Segments[0][1].Time) {
+		t.Errorf("Read time = %v, want %v", *tp, want.Tracks[0].Segments[0][1].Time)
+	}
+	*tp = want.Tracks[0].Segments[0][1].Time
+	if !reflect.DeepEqual(g, want) {
+		t.Errorf("Read = %+v, want %+v", g, want)
+	}
+}
+
+func TestReadGPX10(t *testing.T) {
+	const doc = `<gpx version="1.0" creator="old" xmlns="http://www.topografix.com/GPX/1/0">
+<time>2002-02-27T17:18:33Z</time>
+<wpt lat="42.438878" lon="-71.119277"><ele>44.586548</ele><name>5066</name></wpt>
+</gpx>`
+	g, err := Read(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Waypoint{{Point: geom.Point{X: -71.119277, Y: 42.438878, Z: 44.586548}, HasElevation: true, Name: "5066"}}
+	if g.Creator != "old" || !reflect.DeepEqual(g.Waypoints, want) {
+		t.Errorf("Read = %+v, want creator old and waypoints %+v", g, want)
+	}
+}
+
+func TestReadTime(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"2024-05-01T06:30:00Z", time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)},
+		{"2024-05-01T06:30:00.123456789Z", time.Date(2024, 5, 1, 6, 30, 0, 123456789, time.UTC)},
+		{"2024-05-01T08:30:00+02:00", time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)},
+		{"2024-05-01T06:30:00", time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)},
+		{"2024-05-01T06:30:00.25", time.Date(2024, 5, 1, 6, 30, 0, 25e7, time.UTC)},
+		{" 2024-05-01T06:30:00Z\n", time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		g, err := Read(strings.NewReader(`<gpx><wpt lat="0" lon="0"><time>` + tt.s + `</time></wpt></gpx>`))
+		if err != nil {
+			t.Errorf("Read of time %q: %v", tt.s, err)
+			continue
+		}
+		if got := g.Waypoints[0].Time; !got.Equal(tt.want) {
+			t.Errorf("Read of time %q = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		doc, err string
+	}{
+		{``, "gpx: invalid GPX: EOF"},
+		{`<gpx><wpt lat="1" lon="2"></gpx>`, "gpx: invalid GPX: XML syntax error on line 1: element <wpt> closed by </gpx>"},
+		{`<kml></kml>`, "gpx: invalid GPX: expected element type <gpx> but have <kml>"},
+		{`<gpx><wpt lat="91" lon="0"/></gpx>`, `gpx: invalid GPX: latitude "91"`},
+		{`<gpx><wpt lat="-90.5" lon="0"/></gpx>`, `gpx: invalid GPX: latitude "-90.5"`},
+		{`<gpx><wpt lon="0"/></gpx>`, `gpx: invalid GPX: latitude ""`},
+		{`<gpx><wpt lat="0" lon="x"/></gpx>`, `gpx: invalid GPX: longitude "x"`},
+		{`<gpx><wpt lat="0" lon="180.1"/></gpx>`, `gpx: invalid GPX: longitude "180.1"`},
+		{`<gpx><wpt lat="0" lon="0"><ele>high</ele></wpt></gpx>`, `gpx: invalid GPX: elevation "high"`},
+		{`<gpx><wpt lat="0" lon="0"><time>yesterday</time></wpt></gpx>`, `gpx: invalid GPX: time "yesterday"`},
+		{`<gpx><metadata><time>2024-13-01T00:00:00Z</time></metadata></gpx>`, `gpx: invalid GPX: time "2024-13-01T00:00:00Z"`},
+		{`<gpx><rte><rtept lat="0" lon="0"/><rtept lat="0"/></rte></gpx>`, `gpx: invalid GPX: longitude ""`},
+		{`<gpx><trk><trkseg><trkpt lat="95" lon="0"/></trkseg></trk></gpx>`, `gpx: invalid GPX: latitude "95"`},
+	}
+	for _, tt := range tests {
+		g, err := Read(strings.NewReader(tt.doc))
+		if err == nil || err.Error() != tt.err || !errors.Is(err, ErrInvalid) {
+			t.Errorf("Read(%q) = %v, %v, want error %q", tt.doc, g, err, tt.err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := &GPX{
+		Name: "Walk",
+		Time: time.Date(2024, 5, 1, 8, 30, 0, 0, time.FixedZone("CEST", 2*3600)),
+		Waypoints: []Waypoint{
+			{Point: geom.Point{X: 2.2945, Y: 48.8584, Z: 35}, HasElevation: true, Name: "Tour Eiffel & co"},
+		},
+		Routes: []Route{{Name: "R", Points: []Waypoint{{Point: geom.Point{X: 1, Y: 2}}}}},
+		Tracks: []Track{{
+			Type: "walking",
+			Segments: [][]Waypoint{{
+				{Point: geom.Point{X: 2.2945, Y: 48.8584, Z: 0}, HasElevation: true, Time: time.Date(2024, 5, 1, 6, 30, 0, 5e8, time.UTC)},
+			}},
+		}},
+	}
+	const want = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="geospat">
+  <metadata>
+    <name>Walk</name>
+    <time>2024-05-01T06:30:00Z</time>
+  </metadata>
+  <wpt lat="48.8584" lon="2.2945">
+    <ele>35</ele>
+    <name>Tour Eiffel &amp; co</name>
+  </wpt>
+  <rte>
+    <name>R</name>
+    <rtept lat="2" lon="1"></rtept>
+  </rte>
+  <trk>
+    <type>walking</type>
+    <trkseg>
+      <trkpt lat="48.8584" lon="2.2945">
+        <ele>0</ele>
+        <time>2024-05-01T06:30:00.5Z</time>
+      </trkpt>
+    </trkseg>
+  </trk>
+</gpx>
+`
+	var b bytes.Buffer
+	if err := Write(&b, g); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("Write =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	const want = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="tool"></gpx>
+`
+	var b bytes.Buffer
+	if err := Write(&b, &GPX{Creator: "tool"}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("Write =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if w.n -= len(p); w.n < 0 {
+		return 0, errors.New("full")
+	}
+	return len(p), nil
+}
+
+func TestWriteError(t *testing.T) {
+	g := &GPX{Waypoints: []Waypoint{{Name: "A"}}}
+	for _, n := range []int{0, 50, 100} {
+		if err := Write(&errWriter{n}, g); err == nil || err.Error() != "full" {
+			t.Errorf("Write to a writer of %d bytes = %v, want full", n, err)
+		}
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	g, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := Write(&b, g); err != nil {
+		t.Fatal(err)
+	}
+	g2, err := Read(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Written times are in UTC.
+	g.Tracks[0].Segments[0][1].Time = g.Tracks[0].Segments[0][1].Time.UTC()
+	if !reflect.DeepEqual(g2, g) {
+		t.Errorf("Read(Write(g)) = %+v, want %+v", g2, g)
+	}
+}
+
+func TestLine(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)
+	tests := []struct {
+		ps     []Waypoint
+		want   geom.LineString
+		layout geom.Layout
+	}{
+		{nil, geom.LineString{}, geom.XY},
+		{
+			[]Waypoint{{Point: geom.Point{X: 1, Y: 2, Z: 3, M: 4}}, {Point: geom.Point{X: 5, Y: 6}}},
+			geom.LineString{{X: 1, Y: 2}, {X: 5, Y: 6}},
+			geom.XY,
+		},
+		{
+			[]Waypoint{{Point: geom.Point{X: 1, Y: 2, Z: 3}, HasElevation: true}, {Point: geom.Point{X: 5, Y: 6}}},
+			geom.LineString{{X: 1, Y: 2, Z: 3}, {X: 5, Y: 6}},
+			geom.XYZ,
+		},
+		{
+			[]Waypoint{{Point: geom.Point{X: 1, Y: 2}, Time: t0}, {Point: geom.Point{X: 5, Y: 6}, Time: t0.Add(2500 * time.Millisecond)}},
+			geom.LineString{{X: 1, Y: 2, M: 1714545000}, {X: 5, Y: 6, M: 1714545002.5}},
+			geom.XYM,
+		},
+		{
+			[]Waypoint{{Point: geom.Point{X: 1, Y: 2, Z: -4}, HasElevation: true}, {Point: geom.Point{X: 5, Y: 6}, Time: time.Unix(-1, 0)}},
+			geom.LineString{{X: 1, Y: 2, Z: -4}, {X: 5, Y: 6, M: -1}},
+			geom.XYZM,
+		},
+	}
+	for _, tt := range tests {
+		l, layout := Line(tt.ps)
+		if !reflect.DeepEqual(l, tt.want) || layout != tt.layout {
+			t.Errorf("Line(%v) = %v, %v, want %v, %v", tt.ps, l, layout, tt.want, tt.layout)
+		}
+		if l, _ := (Route{Points: tt.ps}).Line(); !reflect.DeepEqual(l, tt.want) {
+			t.Errorf("Route.Line() of %v differs from Line", tt.ps)
+		}
+	}
+}
+
+func TestMultiLine(t *testing.T) {
+	tr := Track{Segments: [][]Waypoint{
+		{{Point: geom.Point{X: 1, Y: 2, Z: 3}, HasElevation: true}},
+		{{Point: geom.Point{X: 4, Y: 5}, Time: time.Unix(10, 0)}},
+		{},
+	}}
+	want := geom.MultiLineString{{{X: 1, Y: 2, Z: 3}}, {{X: 4, Y: 5, M: 10}}, {}}
+	m, layout := tr.MultiLine()
+	if !reflect.DeepEqual(m, want) || layout != geom.XYZM {
+		t.Errorf("MultiLine() = %v, %v, want %v, XYZM", m, layout, want)
+	}
+	if m, layout := (Track{}).MultiLine(); len(m) != 0 || layout != geom.XY {
+		t.Errorf("MultiLine() of no segments = %v, %v, want empty, XY", m, layout)
+	}
+}
+
+func TestWaypoints(t *testing.T) {
+	l := geom.LineString{{X: 1, Y: 2, Z: 3, M: 1714545002.5}, {X: 5, Y: 6, Z: 7, M: -1.25}}
+	tests := []struct {
+		layout geom.Layout
+		want   []Waypoint
+	}{
+		{geom.XY, []Waypoint{{Point: geom.Point{X: 1, Y: 2}}, {Point: geom.Point{X: 5, Y: 6}}}},
+		{geom.XYZ, []Waypoint{
+			{Point: geom.Point{X: 1, Y: 2, Z: 3}, HasElevation: true},
+			{Point: geom.Point{X: 5, Y: 6, Z: 7}, HasElevation: true},
+		}},
+		{geom.XYM, []Waypoint{
+			{Point: geom.Point{X: 1, Y: 2}, Time: time.Date(2024, 5, 1, 6, 30, 2, 5e8, time.UTC)},
+			{Point: geom.Point{X: 5, Y: 6}, Time: time.Date(1969, 12, 31, 23, 59, 58, 75e7, time.UTC)},
+		}},
+	}
+	for _, tt := range tests {
+		if got := Waypoints(l, tt.layout); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Waypoints(%v, %v) = %+v, want %+v", l, tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestWaypointsLine(t *testing.T) {
+	// Times survive Line and Waypoints to the microsecond.
+	t0 := time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)
+	var ps []Waypoint
+	for i := range 1000 {
+		ps = append(ps, Waypoint{
+			Point:        geom.Point{X: float64(i), Y: -float64(i) / 100, Z: float64(i) / 10},
+			HasElevation: true,
+			Time:         t0.Add(time.Duration(i) * 1234567 * time.Microsecond),
+		})
+	}
+	l, layout := Line(ps)
+	if layout != geom.XYZM {
+		t.Fatalf("Line layout = %v, want XYZM", layout)
+	}
+	if got := Waypoints(l, layout); !reflect.DeepEqual(got, ps) {
+		for i := range got {
+			if !reflect.DeepEqual(got[i], ps[i]) {
+				t.Fatalf("Waypoints(Line(ps))[%d] = %+v, want %+v", i, got[i], ps[i])
+			}
+		}
+	}
+}
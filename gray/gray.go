@@ -0,0 +1,105 @@
+// Package gray implements the binary reflected Gray code, in which
+// consecutive integers differ in exactly one bit, together with the
+// Gray code ranks used by compact Hilbert indices.
+package gray
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Encode returns the Gray code of i. Because the Gray code of an
+// integer never has more significant bits than the integer itself,
+// Encode is correct for every width up to 64 bits.
+func Encode(i uint64) uint64 {
+	return i ^ i>>1
+}
+
+// Decode returns the integer whose Gray code is g. It is the inverse of
+// Encode.
+func Decode(g uint64) uint64 {
+	for s := uint(1); s < 64; s *= 2 {
+		g ^= g >> s
+	}
+	return g
+}
+
+// EncodeBig sets z to the Gray code of the non-negative integer x,
+// which may have any number of bits, and returns z.
+func EncodeBig(z, x *big.Int) *big.Int {
+	t := new(big.Int).Rsh(x, 1)
+	return z.Xor(x, t)
+}
+
+// DecodeBig sets z to the integer whose Gray code is the non-negative
+// integer g, which may have any number of bits, and returns z. It is
+// the inverse of EncodeBig.
+func DecodeBig(z, g *big.Int) *big.Int {
+	z.Set(g)
+	t := new(big.Int)
+	for s := uint(1); s < uint(g.BitLen()); s *= 2 {
+		z.Xor(z, t.Rsh(z, s))
+	}
+	return z
+}
+
+// Permutation returns the 2^width Gray codes of width bits in order, so
+// that element i is Encode(i). The width must be between 0 and 30.
+func Permutation(width int) []uint64 {
+	p := make([]uint64, 1<<uint(width))
+	for i := range p {
+		p[i] = Encode(uint64(i))
+	}
+	return p
+}
+
+// InversePermutation returns the inverse of Permutation(width), so that
+// element g is Decode(g). The width must be between 0 and 30.
+func InversePermutation(width int) []uint64 {
+	p := make([]uint64, 1<<uint(width))
+	for g := range p {
+		p[g] = Decode(uint64(g))
+	}
+	return p
+}
+
+// Rank returns the rank of the integer w among the integers whose Gray
+// codes agree with the Gray code of w in every bit not set in mask. The
+// rank is simply the bits of w selected by mask, concatenated in order
+// of significance.
+//
+// Rank is the "Gray code rank" of Hamilton and Rau-Chaplin's compact
+// Hilbert indices, where mask selects the dimensions which still have
+// bits at the current level.
+func Rank(w, mask uint64) (r uint64) {
+	for k := 63; k >= 0; k-- {
+		if mask>>uint(k)&1 == 1 {
+			r = r<<1 | w>>uint(k)&1
+		}
+	}
+	return
+}
+
+// RankInverse is the inverse of Rank over integers of the given width.
+// Given the mask of free bits, the Gray code bits fixed outside the
+// mask, and a rank r, it returns the integer w of that rank together
+// with its Gray code g.
+func RankInverse(r, mask, fixed uint64, width int) (w, g uint64) {
+	j := bits.OnesCount64(mask) - 1
+	var prev uint64 // Bit k+1 of w
+	for k := width - 1; k >= 0; k-- {
+		var wk, gk uint64
+		if mask>>uint(k)&1 == 1 {
+			wk = r >> uint(j) & 1
+			gk = wk ^ prev
+			j--
+		} else {
+			gk = fixed >> uint(k) & 1
+			wk = gk ^ prev
+		}
+		w |= wk << uint(k)
+		g |= gk << uint(k)
+		prev = wk
+	}
+	return
+}
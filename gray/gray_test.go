@@ -0,0 +1,119 @@
+package gray
+
+import (
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		i, g uint64
+	}{
+		{0, 0},
+		{1, 1},
+		{2, 3},
+		{3, 2},
+		{4, 6},
+		{5, 7},
+		{6, 5},
+		{7, 4},
+		{15, 8},
+		{1 << 63, 3 << 62},
+		{1<<64 - 1, 1 << 63},
+	}
+	for _, tt := range tests {
+		if g := Encode(tt.i); g != tt.g {
+			t.Errorf("Encode(%d) = %#b, want %#b", tt.i, g, tt.g)
+		}
+		if i := Decode(tt.g); i != tt.i {
+			t.Errorf("Decode(%#b) = %d, want %d", tt.g, i, tt.i)
+		}
+	}
+}
+
+func TestEncodeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(23))
+	for range 10000 {
+		i := r.Uint64()
+		if Decode(Encode(i)) != i {
+			t.Fatalf("Decode(Encode(%d)) = %d", i, Decode(Encode(i)))
+		}
+		if n := bits.OnesCount64(Encode(i) ^ Encode(i+1)); n != 1 {
+			t.Fatalf("Encode(%d) and Encode(%d) differ in %d bits", i, i+1, n)
+		}
+	}
+}
+
+func TestEncodeBig(t *testing.T) {
+	r := rand.New(rand.NewSource(29))
+	for range 1000 {
+		// A 134-bit integer, whose high and low words are both set.
+		x := new(big.Int).SetUint64(r.Uint64())
+		x.Lsh(x, 70).Or(x, new(big.Int).SetUint64(r.Uint64()))
+		g := EncodeBig(new(big.Int), x)
+		if want := new(big.Int).Xor(x, new(big.Int).Rsh(x, 1)); g.Cmp(want) != 0 {
+			t.Fatalf("EncodeBig(%v) = %v, want %v", x, g, want)
+		}
+		if d := DecodeBig(new(big.Int), g); d.Cmp(x) != 0 {
+			t.Fatalf("DecodeBig(EncodeBig(%v)) = %v", x, d)
+		}
+	}
+	small := big.NewInt(5)
+	if g := EncodeBig(new(big.Int), small); g.Uint64() != Encode(5) {
+		t.Errorf("EncodeBig(5) = %v, want %d", g, Encode(5))
+	}
+	if d := DecodeBig(small, small); d.Uint64() != Decode(5) {
+		t.Errorf("DecodeBig(5) in place = %v, want %d", d, Decode(5))
+	}
+}
+
+func TestPermutation(t *testing.T) {
+	if p := Permutation(3); !slices.Equal(p, []uint64{0, 1, 3, 2, 6, 7, 5, 4}) {
+		t.Errorf("Permutation(3) = %v", p)
+	}
+	if q := InversePermutation(3); !slices.Equal(q, []uint64{0, 1, 3, 2, 7, 6, 4, 5}) {
+		t.Errorf("InversePermutation(3) = %v", q)
+	}
+	if p := Permutation(0); !slices.Equal(p, []uint64{0}) {
+		t.Errorf("Permutation(0) = %v", p)
+	}
+	p, q := Permutation(10), InversePermutation(10)
+	for i := range p {
+		if q[p[i]] != uint64(i) {
+			t.Fatalf("InversePermutation(10)[Permutation(10)[%d]] = %d", i, q[p[i]])
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		w, mask, r uint64
+	}{
+		{0b1011, 0b0110, 0b01},
+		{0b1011, 0b1111, 0b1011},
+		{0b1011, 0b1001, 0b11},
+		{0b1011, 0, 0},
+		{1 << 63, 1<<63 | 1, 0b10},
+	}
+	for _, tt := range tests {
+		if r := Rank(tt.w, tt.mask); r != tt.r {
+			t.Errorf("Rank(%#b, %#b) = %#b, want %#b", tt.w, tt.mask, r, tt.r)
+		}
+	}
+}
+
+func TestRankInverse(t *testing.T) {
+	const width = 6
+	for mask := range uint64(1) << width {
+		for w := range uint64(1) << width {
+			g := Encode(w)
+			w2, g2 := RankInverse(Rank(w, mask), mask, g&^mask, width)
+			if w2 != w || g2 != g {
+				t.Fatalf("RankInverse(Rank(%#b, %#b)) = %#b, %#b, want %#b, %#b", w, mask, w2, g2, w, g)
+			}
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Package grid is a uniform grid, a spatial index of items with
+// rectangles which divides a rectangle into rows and columns of cells of
+// the same size, and keeps each item in every cell which its rectangle
+// overlaps.
+//
+// Unlike a tree, a grid does not adapt to its items, and is slow for
+// items crowded into a few of its cells. For items evenly spread, though,
+// it finds them with no more than arithmetic, and moving an item changes
+// only the cells which it leaves and enters, so that for many moving
+// items it is faster than a tree. Its cells are made at once, so that
+// the memory which it takes grows with its items alone.
+//
+// The distances of its searches are measured by a geom.Metric, such as
+// geom.Planar for rectangles in the plane or geom.Haversine for
+// longitudes and latitudes.
+package grid
+
+import (
+	"iter"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Grid is a uniform grid of items of type T with rectangles.
+type Grid[T comparable] struct {
+	bounds     geom.Rect
+	cols, rows int
+	// cells are the items of the cells, row by row from the south-west
+	// corner of the grid.
+	cells [][]entry[T]
+	len   int
+}
+
+// entry is an item and its rectangle.
+type entry[T comparable] struct {
+	rect geom.Rect
+	item T
+}
+
+// span is the range of the cells which a rectangle overlaps, from the
+// lowest to the highest column and row, inclusive.
+type span struct {
+	col0, row0, col1, row1 int
+}
+
+// contains reports whether the span has a cell.
+func (s span) contains(col, row int) bool {
+	return s.col0 <= col && col <= s.col1 && s.row0 <= row && row <= s.row1
+}
+
+// New returns an empty grid of a rectangle divided into columns and rows
+// of cells. Items outside the rectangle may be inserted, and are kept in
+// the cells at its edges. It panics if the rectangle is empty or if
+// there is not at least one column and row.
+func New[T comparable](bounds geom.Rect, cols, rows int) *Grid[T] {
+	if bounds.IsEmpty() {
+		panic("grid: empty bounds")
+	}
+	if cols < 1 || rows < 1 {
+		panic("grid: no columns or rows")
+	}
+	return &Grid[T]{bounds: bounds, cols: cols, rows: rows, cells: make([][]entry[T], cols*rows)}
+}
+
+// Len returns the number of items of the grid.
+func (g *Grid[T]) Len() int {
+	return g.len
+}
+
+// Bounds returns the rectangle of the grid.
+func (g *Grid[T]) Bounds() geom.Rect {
+	return g.bounds
+}
+
+// Size returns the number of columns and rows of the grid.
+func (g *Grid[T]) Size() (cols, rows int) {
+	return g.cols, g.rows
+}
+
+// Cell returns the rectangle of a cell of the grid, its columns counted
+// from the west and its rows from the south.
+func (g *Grid[T]) Cell(col, row int) geom.Rect {
+	w, h := g.bounds.Width()/float64(g.cols), g.bounds.Height()/float64(g.rows)
+	return geom.Rect{
+		MinX: g.bounds.MinX + float64(col)*w,
+		MinY: g.bounds.MinY + float64(row)*h,
+		MaxX: g.bounds.MinX + float64(col+1)*w,
+		MaxY: g.bounds.MinY + float64(row+1)*h,
+	}
+}
+
+// index returns the index of the cell of n along an axis in which a
+// coordinate is, clamped to those of the grid.
+func index(v, lo, width float64, n int) int {
+	if width == 0 {
+		return 0
+	}
+	return int(max(0, min(float64(n-1), math.Floor((v-lo)/width*float64(n)))))
+}
+
+// cell returns the column and row of the cell in which a point is.
+func (g *Grid[T]) cell(p geom.Point) (col, row int) {
+	return index(p.X, g.bounds.MinX, g.bounds.Width(), g.cols), index(p.Y, g.bounds.MinY, g.bounds.Height(), g.rows)
+}
+
+// span returns the span of the cells which a rectangle overlaps. An item
+// with an empty rectangle is kept in the first cell.
+func (g *Grid[T]) span(r geom.Rect) span {
+	if r.IsEmpty() {
+		return span{}
+	}
+	col0, row0 := g.cell(geom.Point{X: r.MinX, Y: r.MinY})
+	col1, row1 := g.cell(geom.Point{X: r.MaxX, Y: r.MaxY})
+	return span{col0, row0, col1, row1}
+}
+
+// Insert inserts an item with a rectangle. An item may be inserted more
+// than once, even with the same rectangle.
+func (g *Grid[T]) Insert(r geom.Rect, item T) {
+	s := g.span(r)
+	for row := s.row0; row <= s.row1; row++ {
+		for col := s.col0; col <= s.col1; col++ {
+			i := row*g.cols + col
+			g.cells[i] = append(g.cells[i], entry[T]{r, item})
+		}
+	}
+	g.len++
+}
+
+// Remove removes an item with a rectangle, reporting whether the grid
+// had it. If it was inserted more than once with the rectangle, it
+// removes only one of them.
+func (g *Grid[T]) Remove(r geom.Rect, item T) bool {
+	s := g.span(r)
+	e := entry[T]{r, item}
+	if g.find(s.col0, s.row0, e) < 0 {
+		return false
+	}
+	for row := s.row0; row <= s.row1; row++ {
+		for col := s.col0; col <= s.col1; col++ {
+			g.remove(col, row, g.find(col, row, e))
+		}
+	}
+	g.len--
+	return true
+}
+
+// Move moves an item from a rectangle to another, reporting whether the
+// grid had it with the first, changing only the cells which one of the
+// rectangles overlaps and the other does not. If it was inserted more
+// than once with the rectangle, it moves only one of them.
+func (g *Grid[T]) Move(from, to geom.Rect, item T) bool {
+	a, b := g.span(from), g.span(to)
+	e := entry[T]{from, item}
+	if g.find(a.col0, a.row0, e) < 0 {
+		return false
+	}
+	for row := a.row0; row <= a.row1; row++ {
+		for col := a.col0; col <= a.col1; col++ {
+			i := g.find(col, row, e)
+			if b.contains(col, row) {
+				g.cells[row*g.cols+col][i].rect = to
+			} else {
+				g.remove(col, row, i)
+			}
+		}
+	}
+	for row := b.row0; row <= b.row1; row++ {
+		for col := b.col0; col <= b.col1; col++ {
+			if !a.contains(col, row) {
+				i := row*g.cols + col
+				g.cells[i] = append(g.cells[i], entry[T]{to, item})
+			}
+		}
+	}
+	return true
+}
+
+// find returns the index of an entry in a cell, or -1 if the cell does
+// not have it.
+func (g *Grid[T]) find(col, row int, e entry[T]) int {
+	for i, f := range g.cells[row*g.cols+col] {
+		if f == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove removes the entry of an index from a cell, replacing it with the
+// last entry of the cell.
+func (g *Grid[T]) remove(col, row, i int) {
+	c := g.cells[row*g.cols+col]
+	last := len(c) - 1
+	c[i] = c[last]
+	c[last] = entry[T]{}
+	g.cells[row*g.cols+col] = c[:last]
+}
+
+// All returns an iterator over the items of the grid, with their
+// rectangles, in no particular order.
+func (g *Grid[T]) All() iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		for i, c := range g.cells {
+			for _, e := range c {
+				// An item in more than one cell is yielded from the
+				// first of them.
+				if s := g.span(e.rect); s.row0*g.cols+s.col0 == i && !yield(e.rect, e.item) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Search returns an iterator over the items whose rectangles intersect
+// a rectangle, with their rectangles, in no particular order.
+func (g *Grid[T]) Search(r geom.Rect) iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		if r.IsEmpty() {
+			return
+		}
+		s := g.span(r)
+		for row := s.row0; row <= s.row1; row++ {
+			for col := s.col0; col <= s.col1; col++ {
+				for _, e := range g.cells[row*g.cols+col] {
+					if !e.rect.Intersects(r) {
+						continue
+					}
+					// An item in more than one cell is yielded from the
+					// cell of the corner of its intersection with the
+					// rectangle, which both overlap.
+					c, w := g.cell(geom.Point{X: max(e.rect.MinX, r.MinX), Y: max(e.rect.MinY, r.MinY)})
+					if c == col && w == row && !yield(e.rect, e.item) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Within returns an iterator over the items whose rectangles are within
+// a distance of a point, measured by a metric, with their rectangles, in
+// no particular order. The distance to a rectangle is that to its point
+// nearest in the plane, as geom.Rect.Clamp returns, which is the item's
+// point itself for an item at a point.
+func (g *Grid[T]) Within(p geom.Point, d float64, m geom.Metric) iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		for row := range g.rows {
+			if m.Bound(p, g.extent(-1, row)) > d {
+				continue
+			}
+			for col := range g.cols {
+				if m.Bound(p, g.extent(col, row)) > d {
+					continue
+				}
+				for _, e := range g.cells[row*g.cols+col] {
+					q := e.rect.Clamp(p)
+					if q.IsEmpty() || m.Distance(p, q) > d {
+						continue
+					}
+					// An item in more than one cell is yielded from the
+					// cell of its nearest point, which is as near as it.
+					if c, w := g.cell(q); c == col && w == row && !yield(e.rect, e.item) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// extent returns the rectangle which the items of a cell may be in, or
+// of a row if col is -1, which reaches to infinity beyond the edges of
+// the grid, as the cells at the edges keep the items outside them.
+func (g *Grid[T]) extent(col, row int) geom.Rect {
+	inf := math.Inf(1)
+	r := g.Cell(max(0, col), row)
+	if col <= 0 {
+		r.MinX = -inf
+	}
+	if col < 0 || col == g.cols-1 {
+		r.MaxX = inf
+	}
+	if row == 0 {
+		r.MinY = -inf
+	}
+	if row == g.rows-1 {
+		r.MaxY = inf
+	}
+	return r
+}
@@ -0,0 +1,249 @@
+package grid
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// check checks that each item of a grid is in the cells which its
+// rectangle overlaps, and in no others, once each, and that the grid has
+// Len items.
+func check[T comparable](t *testing.T, g *Grid[T]) {
+	t.Helper()
+	n := 0
+	for i, c := range g.cells {
+		col, row := i%g.cols, i/g.cols
+		for _, e := range c {
+			s := g.span(e.rect)
+			if !s.contains(col, row) {
+				t.Fatalf("cell (%d, %d) holds an item of %v", col, row, e.rect)
+			}
+			if s.col0 == col && s.row0 == row {
+				n++
+			}
+		}
+	}
+	if n != g.len {
+		t.Fatalf("grid has %d items, but Len %d", n, g.len)
+	}
+}
+
+// randomRect returns a random rectangle in [-100, 1100)², mostly small,
+// some outside the grids of the tests.
+func randomRect(r *rand.Rand) geom.Rect {
+	x, y := r.Float64()*1200-100, r.Float64()*1200-100
+	s := r.Float64() * 10
+	if r.Intn(20) == 0 {
+		s *= 30
+	}
+	return geom.Rect{MinX: x, MinY: y, MaxX: x + s, MaxY: y + s}
+}
+
+// items returns the items of a sequence, sorted.
+func items(seq func(func(geom.Rect, int) bool)) []int {
+	var is []int
+	for _, i := range seq {
+		is = append(is, i)
+	}
+	slices.Sort(is)
+	return is
+}
+
+// point returns the rectangle of a point.
+func point(x, y float64) geom.Rect {
+	return geom.Rect{MinX: x, MinY: y, MaxX: x, MaxY: y}
+}
+
+func TestGrid(t *testing.T) {
+	g := New[string](geom.Rect{MinX: 0, MinY: 0, MaxX: 40, MaxY: 20}, 4, 2)
+	if cols, rows := g.Size(); cols != 4 || rows != 2 || g.Bounds() != (geom.Rect{MinX: 0, MinY: 0, MaxX: 40, MaxY: 20}) {
+		t.Errorf("Size = %d, %d and Bounds = %v", cols, rows, g.Bounds())
+	}
+	if c := g.Cell(2, 1); c != (geom.Rect{MinX: 20, MinY: 10, MaxX: 30, MaxY: 20}) {
+		t.Errorf("Cell(2, 1) = %v", c)
+	}
+	g.Insert(point(5, 5), "a")
+	g.Insert(geom.Rect{MinX: 15, MinY: 5, MaxX: 25, MaxY: 15}, "b")
+	g.Insert(point(-50, 50), "outside")
+	g.Insert(geom.EmptyRect(), "empty")
+	check(t, g)
+	if g.Len() != 4 || len(g.cells[0]) != 2 || len(g.cells[1]) != 1 || len(g.cells[6]) != 1 || len(g.cells[4]) != 1 {
+		t.Errorf("cells of the items are wrong: %v", g.cells)
+	}
+	tests := []struct {
+		r    geom.Rect
+		want []string
+	}{
+		{geom.Rect{MinX: 0, MinY: 0, MaxX: 40, MaxY: 20}, []string{"a", "b"}},
+		{geom.Rect{MinX: 20, MinY: 12, MaxX: 21, MaxY: 13}, []string{"b"}},
+		{geom.Rect{MinX: -100, MinY: 0, MaxX: 5, MaxY: 100}, []string{"a", "outside"}},
+		{geom.Rect{MinX: 30, MinY: 0, MaxX: 40, MaxY: 20}, nil},
+		{geom.EmptyRect(), nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, item := range g.Search(tt.r) {
+			got = append(got, item)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%v) = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+	var within []string
+	for _, item := range g.Within(geom.Point{X: 10, Y: 5}, 5, geom.Planar) {
+		within = append(within, item)
+	}
+	slices.Sort(within)
+	if want := []string{"a", "b"}; !slices.Equal(within, want) {
+		t.Errorf("Within = %q, want %q", within, want)
+	}
+	var all []string
+	for _, item := range g.All() {
+		all = append(all, item)
+	}
+	slices.Sort(all)
+	if want := []string{"a", "b", "empty", "outside"}; !slices.Equal(all, want) {
+		t.Errorf("All = %q, want %q", all, want)
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	for _, f := range []func(){
+		func() { New[int](geom.EmptyRect(), 1, 1) },
+		func() { New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, 0, 1) },
+		func() { New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, 1, -1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("New did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestInsertRemoveMove(t *testing.T) {
+	r := rand.New(rand.NewSource(99))
+	g := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1000, MaxY: 1000}, 25, 20)
+	rects := make([]geom.Rect, 3000)
+	for i := range rects {
+		rects[i] = randomRect(r)
+		g.Insert(rects[i], i)
+	}
+	check(t, g)
+	removed := map[int]bool{}
+	for i := 0; i < len(rects); i += 3 {
+		if !g.Remove(rects[i], i) {
+			t.Fatalf("Remove(%v, %d) = false", rects[i], i)
+		}
+		removed[i] = true
+	}
+	for i := 1; i < len(rects); i += 3 {
+		to := rects[i]
+		to.MinX, to.MaxX = to.MinX+r.Float64()*100-50, to.MaxX+r.Float64()*100-50
+		if !g.Move(rects[i], to, i) {
+			t.Fatalf("Move(%v, %v, %d) = false", rects[i], to, i)
+		}
+		rects[i] = to
+	}
+	check(t, g)
+	if g.Len() != 2000 || g.Remove(rects[0], 0) || g.Move(rects[3], rects[4], 3) || g.Remove(rects[1], 2) {
+		t.Errorf("Len = %d, or a removed item was removed or moved", g.Len())
+	}
+	for range 200 {
+		q := randomRect(r)
+		q.MaxX += 50
+		var want []int
+		for i, s := range rects {
+			if !removed[i] && q.Intersects(s) {
+				want = append(want, i)
+			}
+		}
+		if got := items(g.Search(q)); !slices.Equal(got, want) {
+			t.Fatalf("Search(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if got := items(g.All()); len(got) != 2000 {
+		t.Errorf("All yielded %d items, want 2000", len(got))
+	}
+}
+
+func TestWithin(t *testing.T) {
+	r := rand.New(rand.NewSource(99))
+	tests := []struct {
+		name   string
+		bounds geom.Rect
+		m      geom.Metric
+		d      float64
+		rect   func() geom.Rect
+		point  func() geom.Point
+	}{
+		{
+			"plane", geom.Rect{MinX: 0, MinY: 0, MaxX: 1000, MaxY: 1000}, geom.Planar, 40,
+			func() geom.Rect { return randomRect(r) },
+			func() geom.Point { return geom.Point{X: r.Float64()*1200 - 100, Y: r.Float64()*1200 - 100} },
+		},
+		{
+			"sphere", geom.Rect{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}, geom.Haversine, 500e3,
+			func() geom.Rect {
+				x, y := r.Float64()*358-180, r.Float64()*178-90
+				return geom.Rect{MinX: x, MinY: y, MaxX: x + r.Float64()*2, MaxY: y + r.Float64()*2}
+			},
+			func() geom.Point { return geom.Point{X: r.Float64()*360 - 180, Y: r.Float64()*180 - 90} },
+		},
+	}
+	for _, tt := range tests {
+		g := New[int](tt.bounds, 30, 15)
+		rects := make([]geom.Rect, 3000)
+		for i := range rects {
+			rects[i] = tt.rect()
+			g.Insert(rects[i], i)
+		}
+		for range 200 {
+			p := tt.point()
+			var want []int
+			for i, s := range rects {
+				if tt.m.Distance(p, s.Clamp(p)) <= tt.d {
+					want = append(want, i)
+				}
+			}
+			if got := items(g.Within(p, tt.d, tt.m)); !slices.Equal(got, want) {
+				t.Fatalf("%s: Within(%v, %v) = %v, want %v", tt.name, p, tt.d, got, want)
+			}
+		}
+	}
+}
+
+func TestDegenerate(t *testing.T) {
+	// A grid of no height keeps every item in its single row.
+	g := New[int](geom.Rect{MinX: 0, MinY: 5, MaxX: 10, MaxY: 5}, 10, 3)
+	for i := range 10 {
+		g.Insert(point(float64(i)+0.5, float64(i)), i)
+	}
+	check(t, g)
+	if got := items(g.Search(geom.Rect{MinX: 2, MinY: -10, MaxX: 4, MaxY: 100})); !slices.Equal(got, []int{2, 3}) {
+		t.Errorf("Search = %v, want [2 3]", got)
+	}
+	if got := items(g.Within(geom.Point{X: 0, Y: 0}, math.Inf(1), geom.Planar)); len(got) != 10 {
+		t.Errorf("Within an infinite distance = %v, want all", got)
+	}
+	if got := items(g.Search(g.Bounds())); !slices.Equal(got, []int{5}) {
+		t.Errorf("Search of the bounds = %v, want [5]", got)
+	}
+	n := 0
+	for range g.All() {
+		if n++; n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("All yielded %d items before the break, want 3", n)
+	}
+}
@@ -0,0 +1,49 @@
+package grid
+
+import (
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/internal/bestfirst"
+)
+
+// KNN returns the k items of the grid nearest to a point, or all of
+// them if it has fewer, in order of their distances from the point,
+// measured by a metric, the distance to an item being as for Within. Of
+// items at the same distance, it returns any. Items with empty
+// rectangles are not found.
+//
+// KNN searches the grid best first, dividing the nearest row into its
+// cells and the nearest cell into its items until an item is the
+// nearest.
+func (g *Grid[T]) KNN(p geom.Point, k int, m geom.Metric) []geom.Neighbor[T] {
+	if k <= 0 {
+		return nil
+	}
+	var q bestfirst.Queue[cell, T]
+	for row := range g.rows {
+		q.PushNode(m.Bound(p, g.extent(-1, row)), cell{-1, row})
+	}
+	return q.Nearest(k, func(c cell) {
+		if c.col < 0 {
+			for col := range g.cols {
+				q.PushNode(m.Bound(p, g.extent(col, c.row)), cell{col, c.row})
+			}
+			return
+		}
+		for _, e := range g.cells[c.row*g.cols+c.col] {
+			// An item in more than one cell is reached from the cell of
+			// its nearest point, as for Within.
+			near := e.rect.Clamp(p)
+			if near.IsEmpty() {
+				continue
+			}
+			if col, row := g.cell(near); col == c.col && row == c.row {
+				q.PushItem(geom.Neighbor[T]{Item: e.item, Rect: e.rect, Point: near, Distance: m.Distance(p, near)})
+			}
+		}
+	})
+}
+
+// cell is a cell or a row reached by KNN, col being -1 for a row.
+type cell struct {
+	col, row int
+}
@@ -0,0 +1,42 @@
+package grid
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/internal/indextest"
+)
+
+func TestKNN(t *testing.T) {
+	g := New[string](geom.Rect{MinX: 0, MinY: 0, MaxX: 4, MaxY: 4}, 4, 4)
+	for s, r := range indextest.Stores {
+		g.Insert(r, s)
+	}
+	empty := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, 2, 2)
+	indextest.CheckStores(t, g.KNN, empty.KNN)
+}
+
+func TestKNNRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(100))
+	for _, size := range [][2]int{{1, 1}, {25, 20}} {
+		g := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1000, MaxY: 1000}, size[0], size[1])
+		rects := make([]geom.Rect, 3000)
+		for i := range rects {
+			rects[i] = randomRect(r)
+			rects[i].MinX -= 100 // Some outside the grid.
+			if i%100 == 0 {
+				rects[i] = geom.EmptyRect()
+			}
+			g.Insert(rects[i], i)
+		}
+		indextest.CheckRandom(t, r, g.KNN, rects, geom.Planar)
+	}
+	// Longitudes and latitudes, on the sphere.
+	g := New[int](geom.Rect{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}, 36, 18)
+	rects := indextest.LngLatRects(r, 3000)
+	for i, rect := range rects {
+		g.Insert(rect, i)
+	}
+	indextest.CheckRandom(t, r, g.KNN, rects, geom.Haversine)
+}
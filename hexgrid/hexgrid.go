@@ -0,0 +1,355 @@
+// Package hexgrid divides the Earth into a hierarchy of hexagonal cells
+// in the style of Uber's H3, for binning and neighborhood analysis where
+// the uniform neighbor distance of hexagons is preferable to square
+// cells.
+//
+// Each resolution is a regular hexagonal lattice in the plane of the
+// Lambert cylindrical equal-area projection, so every cell at a given
+// resolution has exactly the same area on the authalic sphere. The
+// lattice at resolution k+1 has cells with one seventh of the area of
+// those at resolution k, rotated alternately by about 19.1 degrees in
+// either direction, so that the center of every coarse cell is also the
+// center of a fine cell (aperture 7, as in H3).
+//
+// Unlike H3, which is built on an icosahedron, the grid has no
+// pentagons but its hexagons are increasingly stretched east to west
+// toward the poles, where the cells in the top and bottom rows are cut
+// off by the pole itself. The cell IDs are not interchangeable with H3
+// cell indexes.
+package hexgrid
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+// MaxResolution is the finest resolution. Cells at MaxResolution have
+// centers roughly one meter apart.
+const MaxResolution = 15
+
+const (
+	// authalicRadius is the radius in meters of the sphere with the
+	// same surface area as the WGS84 ellipsoid.
+	authalicRadius = 6371007.1809
+
+	// width is the width of the projected plane in meters.
+	width = 2 * math.Pi * authalicRadius
+
+	// columns is the number of cell centers along the equator at
+	// resolution 0.
+	columns = 20
+
+	coordBits = 28
+	coordMask = 1<<coordBits - 1
+	resShift  = 2 * coordBits
+	validBit  = 1 << 60
+)
+
+// omega rotates a vector in the plane by 60 degrees.
+var omega = cmplx.Rect(1, math.Pi/3)
+
+var (
+	// basis[k] is the first axial basis vector of the lattice at
+	// resolution k, in projected meters. The second is basis[k]*omega.
+	basis [MaxResolution + 1]complex128
+
+	// period[k] is the axial lattice vector at resolution k which spans
+	// the full width of the plane, once around the Earth.
+	period [MaxResolution + 1][2]int64
+
+	// eisenstein[k] is the Eisenstein integer a+b*omega such that
+	// basis[k] = basis[0] * (a+b*omega) / 7^k, which lets canonical
+	// decide exactly on which side of the antimeridian a point lies.
+	eisenstein [MaxResolution + 1][2]int64
+
+	// pow7[k] is 7^k.
+	pow7 [MaxResolution + 1]int64
+)
+
+func init() {
+	basis[0] = complex(width/columns, 0)
+	period[0] = [2]int64{columns, 0}
+	eisenstein[0] = [2]int64{1, 0}
+	pow7[0] = 1
+	for k := 0; k < MaxResolution; k++ {
+		// Dividing by 2+omega is multiplying by its conjugate 3-omega and
+		// dividing by its norm 7, and vice versa.
+		e := eisenstein[k]
+		if k%2 == 0 {
+			basis[k+1] = basis[k] / (2 + omega)
+			eisenstein[k+1] = eisensteinMul(e, [2]int64{3, -1})
+		} else {
+			basis[k+1] = basis[k] / (3 - omega)
+			eisenstein[k+1] = eisensteinMul(e, [2]int64{2, 1})
+		}
+		period[k+1] = down(k, period[k][0], period[k][1])
+		pow7[k+1] = 7 * pow7[k]
+	}
+}
+
+// eisensteinMul returns the product of two Eisenstein integers, using
+// omega^2 = omega-1.
+func eisensteinMul(x, y [2]int64) [2]int64 {
+	return [2]int64{x[0]*y[0] - x[1]*y[1], x[0]*y[1] + x[1]*y[0] + x[1]*y[1]}
+}
+
+// down converts axial coordinates at resolution k to the axial
+// coordinates of the same point at resolution k+1.
+func down(k int, q, r int64) [2]int64 {
+	if k%2 == 0 {
+		return [2]int64{2*q - r, q + 3*r}
+	}
+	return [2]int64{3*q + r, -q + 2*r}
+}
+
+// up returns the fractional axial coordinates at resolution k of the
+// point with axial coordinates (q, r) at resolution k+1.
+func up(k int, q, r int64) (float64, float64) {
+	if k%2 == 0 {
+		return float64(3*q+r) / 7, float64(-q+2*r) / 7
+	}
+	return float64(2*q-r) / 7, float64(q+3*r) / 7
+}
+
+// Cell identifies a hexagonal cell. Bit 60 is always set, bits 56-59
+// hold the resolution, and the low 56 bits hold the two axial lattice
+// coordinates of the cell center as 28-bit two's complement integers.
+// The zero value is not a valid cell.
+type Cell uint64
+
+// makeCell returns the cell with the given resolution and axial
+// coordinates, after wrapping the coordinates around the antimeridian.
+func makeCell(res int, q, r int64) Cell {
+	q, r = canonical(res, q, r)
+	return Cell(validBit | uint64(res)<<resShift | uint64(q)&coordMask<<coordBits | uint64(r)&coordMask)
+}
+
+// canonical wraps axial coordinates at resolution res so that the cell
+// center lies in the part of the plane between longitudes -180,
+// inclusive, and 180, exclusive. Many lattice points lie exactly on
+// the antimeridian, so the decision is made in exact integer
+// arithmetic.
+func canonical(res int, q, r int64) (int64, int64) {
+	// The x coordinate of the center is basis[0] * (2a+b) / (2*7^res),
+	// where a+b*omega = (q+r*omega) * eisenstein[res].
+	e := eisensteinMul([2]int64{q, r}, eisenstein[res])
+	x := 2*e[0] + e[1]
+	w := 2 * columns * pow7[res] // The width of the plane in the same units
+	m := floorDiv(x+w/2, w)
+	return q - m*period[res][0], r - m*period[res][1]
+}
+
+// floorDiv returns the floor of a/b for b > 0.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b < 0 {
+		q--
+	}
+	return q
+}
+
+// center returns the projected position of the lattice point with axial
+// coordinates (q, r) at resolution res.
+func center(res int, q, r int64) complex128 {
+	return complex(float64(q), 0)*basis[res] + complex(float64(r), 0)*basis[res]*omega
+}
+
+// FromLatLng returns the cell at resolution res which contains the
+// point with the given latitude and longitude in degrees. The
+// resolution must be in the range [0, MaxResolution]. Latitudes beyond
+// the poles are clamped and longitudes are wrapped.
+func FromLatLng(lat, lng float64, res int) Cell {
+	lat = math.Max(-90, math.Min(90, lat))
+	p := complex(lng*math.Pi/180*authalicRadius, math.Sin(lat*math.Pi/180)*authalicRadius)
+	z := p / basis[res]
+	fr := imag(z) * 2 / math.Sqrt(3)
+	fq := real(z) - fr/2
+	q, r := hexRound(fq, fr)
+	return makeCell(res, q, r)
+}
+
+// hexRound returns the lattice point nearest to fractional axial
+// coordinates (fq, fr), by rounding in cube coordinates.
+func hexRound(fq, fr float64) (int64, int64) {
+	fs := -fq - fr
+	q, r, s := math.Round(fq), math.Round(fr), math.Round(fs)
+	dq, dr, ds := math.Abs(q-fq), math.Abs(r-fr), math.Abs(s-fs)
+	switch {
+	case dq > dr && dq > ds:
+		q = -r - s
+	case dr > ds:
+		r = -q - s
+	}
+	return int64(q), int64(r)
+}
+
+// IsValid reports whether c is a valid cell.
+func (c Cell) IsValid() bool {
+	if c&validBit == 0 || c>>61 != 0 || c.Resolution() > MaxResolution {
+		return false
+	}
+	q, r := c.axial()
+	cq, cr := canonical(c.Resolution(), q, r)
+	return cq == q && cr == r
+}
+
+// Resolution returns the resolution of the cell.
+func (c Cell) Resolution() int {
+	return int(c >> resShift & 0xf)
+}
+
+// axial returns the axial lattice coordinates of the cell center.
+func (c Cell) axial() (q, r int64) {
+	q = int64(uint64(c)>>coordBits&coordMask) << (64 - coordBits) >> (64 - coordBits)
+	r = int64(uint64(c)&coordMask) << (64 - coordBits) >> (64 - coordBits)
+	return
+}
+
+// LatLng returns the latitude and longitude, in degrees, of the center
+// of the cell. Cells whose centers lie beyond a pole report the pole.
+func (c Cell) LatLng() (lat, lng float64) {
+	q, r := c.axial()
+	return unproject(center(c.Resolution(), q, r))
+}
+
+// unproject returns the latitude and longitude of a projected point,
+// clamping points beyond the poles.
+func unproject(p complex128) (lat, lng float64) {
+	lat = math.Asin(math.Max(-1, math.Min(1, imag(p)/authalicRadius))) * 180 / math.Pi
+	lng = real(p) / authalicRadius * 180 / math.Pi
+	return
+}
+
+// circumradius returns the distance, in projected meters, from the
+// center of a cell at resolution res to each of its vertices.
+func circumradius(res int) float64 {
+	return cmplx.Abs(basis[res]) / math.Sqrt(3)
+}
+
+// Boundary returns the latitudes and longitudes, in degrees, of the six
+// vertices of the cell in counter-clockwise order. The edges are
+// straight lines in the equal-area projection. Vertices beyond a pole
+// are clamped to it, and the longitudes of a cell straddling the
+// antimeridian may lie outside [-180, 180] so the polygon does not wrap.
+func (c Cell) Boundary() [6][2]float64 {
+	res := c.Resolution()
+	q, r := c.axial()
+	p := center(res, q, r)
+	v := cmplx.Rect(circumradius(res), cmplx.Phase(basis[res])+math.Pi/6)
+	var b [6][2]float64
+	for k := range b {
+		b[k][0], b[k][1] = unproject(p + v)
+		v *= omega
+	}
+	return b
+}
+
+// Parent returns the cell at the coarser resolution res whose center is
+// nearest the center of c. The resolution must be in the range
+// [0, c.Resolution()]. As in H3, the seven children of a cell cover
+// nearly but not exactly the same area as the cell itself, so a point
+// in a cell is not necessarily in the cell's parent.
+func (c Cell) Parent(res int) Cell {
+	k := c.Resolution()
+	q, r := c.axial()
+	for ; k > res; k-- {
+		q, r = hexRound(up(k-1, q, r))
+	}
+	return makeCell(res, q, r)
+}
+
+// axialNeighbors are the axial offsets of the six neighbors of a cell,
+// counter-clockwise from the direction of the first basis vector.
+var axialNeighbors = [6][2]int64{{1, 0}, {0, 1}, {-1, 1}, {-1, 0}, {0, -1}, {1, -1}}
+
+// Children returns the seven cells at the next finer resolution whose
+// parent is c: the cell with the same center first, followed by the six
+// cells around it. The cell must not be at MaxResolution.
+func (c Cell) Children() [7]Cell {
+	k := c.Resolution()
+	q, r := c.axial()
+	m := down(k, q, r)
+	children := [7]Cell{makeCell(k+1, m[0], m[1])}
+	for i, n := range axialNeighbors {
+		children[i+1] = makeCell(k+1, m[0]+n[0], m[1]+n[1])
+	}
+	return children
+}
+
+// KRing returns the cells within grid distance k of c, that is, the
+// cells which can be reached from c by crossing at most k cell edges,
+// in order of increasing distance. The cell c itself is first. Cells
+// lying entirely beyond a pole are omitted, and each cell is returned
+// only once even when k is large enough for the ring to wrap around the
+// Earth.
+func (c Cell) KRing(k int) []Cell {
+	res := c.Resolution()
+	q, r := c.axial()
+	limit := authalicRadius + circumradius(res)
+	type ringCell struct {
+		cell Cell
+		dist int
+	}
+	var ring []ringCell
+	seen := make(map[Cell]bool)
+	for dq := -k; dq <= k; dq++ {
+		for dr := max(-k, -dq-k); dr <= min(k, -dq+k); dr++ {
+			nq, nr := q+int64(dq), r+int64(dr)
+			if math.Abs(imag(center(res, nq, nr))) > limit {
+				continue
+			}
+			n := makeCell(res, nq, nr)
+			if !seen[n] {
+				seen[n] = true
+				ring = append(ring, ringCell{n, hexDistance(int64(dq), int64(dr))})
+			}
+		}
+	}
+	slices.SortStableFunc(ring, func(a, b ringCell) int { return a.dist - b.dist })
+	cells := make([]Cell, len(ring))
+	for i := range ring {
+		cells[i] = ring[i].cell
+	}
+	return cells
+}
+
+// GridDistance returns the number of cell edges which must be crossed
+// to get from a to b, which must have the same resolution, taking the
+// shorter way around the Earth.
+func GridDistance(a, b Cell) int {
+	res := a.Resolution()
+	aq, ar := a.axial()
+	bq, br := b.axial()
+	dq, dr := bq-aq, br-ar
+	best := hexDistance(dq, dr)
+	for _, m := range []int64{-1, 1} {
+		best = min(best, hexDistance(dq+m*period[res][0], dr+m*period[res][1]))
+	}
+	return best
+}
+
+// hexDistance returns the grid distance spanned by an axial offset.
+func hexDistance(dq, dr int64) int {
+	abs := func(v int64) int64 {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+	return int(max(abs(dq), abs(dr), abs(dq+dr)))
+}
+
+// Area returns the area, in square meters, of each cell at resolution
+// res. Because the projection is equal-area, all cells at a resolution
+// have the same area, except those cut off by a pole.
+func Area(res int) float64 {
+	s := cmplx.Abs(basis[res])
+	return s * s * math.Sqrt(3) / 2
+}
+
+// String returns the cell ID in hexadecimal.
+func (c Cell) String() string {
+	return fmt.Sprintf("%016x", uint64(c))
+}
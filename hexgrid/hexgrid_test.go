@@ -0,0 +1,192 @@
+package hexgrid
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFromLatLng(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		res      int
+		want     Cell
+	}{
+		{0, 0, 0, 0x1000000000000000},
+		{0, 18, 0, 0x1000000010000000},
+		{0, 180, 0, 0x10ffffff60000000},
+		{0, -180, 0, 0x10ffffff60000000},
+		{0, 0, MaxResolution, 0x1f00000000000000},
+		{95, 0, 3, FromLatLng(90, 0, 3)},
+		{0, 378, 4, FromLatLng(0, 18, 4)},
+	}
+	for _, tt := range tests {
+		c := FromLatLng(tt.lat, tt.lng, tt.res)
+		if c != tt.want || !c.IsValid() || c.Resolution() != tt.res {
+			t.Errorf("FromLatLng(%v, %v, %d) = %v, want %v", tt.lat, tt.lng, tt.res, c, tt.want)
+		}
+	}
+	if lat, lng := Cell(0x1000000010000000).LatLng(); math.Abs(lat) > 1e-9 || math.Abs(lng-18) > 1e-9 {
+		t.Errorf("LatLng() = (%v, %v), want (0, 18)", lat, lng)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		c    Cell
+		want bool
+	}{
+		{0, false},
+		{0x1000000000000000, true},
+		{0x0000000010000000, false},
+		{0x3000000000000000, false},
+		{0x1000000140000000, false}, // 20 columns east, past the antimeridian
+	}
+	for _, tt := range tests {
+		if got := tt.c.IsValid(); got != tt.want {
+			t.Errorf("%v.IsValid() = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestArea(t *testing.T) {
+	side := 2 * math.Pi * authalicRadius / columns
+	want := side * side * math.Sqrt(3) / 2
+	for res := range MaxResolution + 1 {
+		if a := Area(res); math.Abs(a-want)/want > 1e-12 {
+			t.Errorf("Area(%d) = %v, want %v", res, a, want)
+		}
+		want /= 7
+	}
+	if a := Area(MaxResolution); a < 0.5 || a > 1 {
+		t.Errorf("Area(MaxResolution) = %v m², want about a square meter", a)
+	}
+}
+
+func TestBoundary(t *testing.T) {
+	// The cell at the origin is a hexagon with a vertex due north.
+	b := Cell(0x1000000000000000).Boundary()
+	for k, v := range b {
+		// The vertices at 30, 90, 150, ... degrees from the first basis
+		// vector, at latitudes as given by the equal-area projection.
+		a := math.Pi/6 + float64(k)*math.Pi/3
+		r := circumradius(0)
+		lat := math.Asin(r*math.Sin(a)/authalicRadius) * 180 / math.Pi
+		lng := r * math.Cos(a) / authalicRadius * 180 / math.Pi
+		if math.Abs(v[0]-lat) > 1e-9 || math.Abs(v[1]-lng) > 1e-9 {
+			t.Errorf("Boundary()[%d] = %v, want [%v %v]", k, v, lat, lng)
+		}
+	}
+	if b[1][0] < 10.45 || b[1][0] > 10.46 || math.Abs(b[0][1]-9) > 1e-9 {
+		t.Errorf("Boundary() = %v", b)
+	}
+}
+
+func TestChildren(t *testing.T) {
+	c := Cell(0x1000000000000000)
+	want := [7]Cell{
+		0x1100000000000000, 0x1100000010000000, 0x1100000000000001, 0x11fffffff0000001,
+		0x11fffffff0000000, 0x110000000fffffff, 0x110000001fffffff,
+	}
+	if got := c.Children(); got != want {
+		t.Errorf("Children() = %v, want %v", got, want)
+	}
+	for _, ch := range want {
+		if p := ch.Parent(0); p != c {
+			t.Errorf("%v.Parent(0) = %v, want %v", ch, p, c)
+		}
+	}
+}
+
+func TestKRing(t *testing.T) {
+	c := FromLatLng(0, 0, 2)
+	want := []Cell{
+		0x1200000000000000, 0x12fffffff0000000, 0x12fffffff0000001, 0x120000000fffffff,
+		0x1200000000000001, 0x120000001fffffff, 0x1200000010000000,
+	}
+	got := c.KRing(1)
+	if len(got) != len(want) || got[0] != c {
+		t.Fatalf("KRing(1) = %v, want %v", got, want)
+	}
+	for _, g := range got {
+		found := false
+		for _, w := range want {
+			found = found || g == w
+		}
+		if !found {
+			t.Errorf("KRing(1) = %v, want %v", got, want)
+		}
+	}
+	if n := len(c.KRing(0)); n != 1 {
+		t.Errorf("KRing(0) has %d cells, want 1", n)
+	}
+	// Near the pole, the cells beyond it are left out.
+	if n := len(FromLatLng(89.9, 0, 0).KRing(1)); n != 5 {
+		t.Errorf("polar KRing(1) has %d cells, want 5", n)
+	}
+}
+
+func TestGridDistance(t *testing.T) {
+	o := FromLatLng(0, 0, 0)
+	tests := []struct {
+		a, b Cell
+		want int
+	}{
+		{o, o, 0},
+		{o, FromLatLng(0, 18, 0), 1},
+		{o, FromLatLng(0, -162, 0), 9},
+		{o, FromLatLng(0, 180, 0), 10},
+		{FromLatLng(10, 179.9999, 10), FromLatLng(10, -179.9999, 10), 1},
+	}
+	for _, tt := range tests {
+		if d := GridDistance(tt.a, tt.b); d != tt.want {
+			t.Errorf("GridDistance(%v, %v) = %d, want %d", tt.a, tt.b, d, tt.want)
+		}
+	}
+}
+
+func TestHierarchyRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(24))
+	for range 20000 {
+		lat, lng := math.Asin(2*r.Float64()-1)*180/math.Pi, r.Float64()*360-180
+		res := r.Intn(MaxResolution + 1)
+		c := FromLatLng(lat, lng, res)
+		if !c.IsValid() || c.Resolution() != res {
+			t.Fatalf("FromLatLng(%v, %v, %d) = %v", lat, lng, res, c)
+		}
+		if clat, clng := c.LatLng(); math.Abs(lat) < 80 && FromLatLng(clat, clng, res) != c {
+			t.Fatalf("the center of %v is not in it", c)
+		}
+		if res < MaxResolution {
+			seen := make(map[Cell]bool)
+			for _, ch := range c.Children() {
+				if !ch.IsValid() || ch.Parent(res) != c {
+					t.Fatalf("%v.Children() has %v", c, ch)
+				}
+				seen[ch] = true
+			}
+			if res > 1 && len(seen) != 7 {
+				t.Fatalf("%v has %d distinct children, want 7", c, len(seen))
+			}
+			// The parent of the finer cell containing a point is the cell
+			// containing it, or next to it.
+			if p := FromLatLng(lat, lng, res+1).Parent(res); GridDistance(p, c) > 1 {
+				t.Fatalf("the parent of the cell at (%v, %v) is %d cells away", lat, lng, GridDistance(p, c))
+			}
+		}
+		if math.Abs(lat) < 60 && res > 1 {
+			ring := c.KRing(2)
+			if len(ring) != 19 || ring[0] != c {
+				t.Fatalf("%v.KRing(2) has %d cells, want 19", c, len(ring))
+			}
+			last := 0
+			for _, n := range ring {
+				d := GridDistance(c, n)
+				if d < last || d > 2 || GridDistance(n, c) != d {
+					t.Fatalf("%v.KRing(2) has %v at distance %d after %d", c, n, d, last)
+				}
+				last = d
+			}
+		}
+	}
+}
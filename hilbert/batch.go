@@ -0,0 +1,69 @@
+package hilbert
+
+// Point is a two-dimensional cell position with coordinates (X, Y).
+type Point struct {
+	X, Y uint32
+}
+
+// XYToDBatch converts each of the cell positions in ps to its distance
+// along a discrete Hilbert curve constructed by dividing a square into
+// n X n cells, exactly as XYToD64 would. The cell count n must be a
+// power of 2 no greater than 2^32.
+//
+// The distances are stored in dst, so that the distance of ps[i] is
+// dst[i], and the resulting slice is returned. If dst has sufficient
+// capacity it is resliced to len(ps) and no allocation is made;
+// otherwise a new slice is allocated. Passing the slice returned by an
+// earlier call as dst therefore lets a caller convert many batches of
+// the same size without allocating.
+//
+// The conversion method and its starting state are chosen once for the
+// whole batch rather than once for each position.
+func XYToDBatch(n uint64, dst []uint64, ps []Point) []uint64 {
+	dst = grow(dst, len(ps))
+	ps = ps[:len(dst)]
+	if n > 1 && n <= lutMaxN {
+		chunks, state := lutStart(n)
+		for i := range ps {
+			dst[i] = chunksXYToD(chunks, state, ps[i].X, ps[i].Y)
+		}
+		return dst
+	}
+	for i := range ps {
+		dst[i] = bitsXYToD(n, ps[i].X, ps[i].Y)
+	}
+	return dst
+}
+
+// DToXYBatch converts each of the distances in ds along a discrete
+// Hilbert curve constructed by dividing a square into n X n cells back
+// to its cell position, exactly as DToXY64 would. The cell count n must
+// be a power of 2 no greater than 2^32.
+//
+// The positions are stored in dst, so that the position of ds[i] is
+// dst[i], and the resulting slice is returned. As with XYToDBatch, dst
+// is reused if it has sufficient capacity.
+func DToXYBatch(n uint64, dst []Point, ds []uint64) []Point {
+	dst = grow(dst, len(ds))
+	ds = ds[:len(dst)]
+	if n > 1 && n <= lutMaxN {
+		chunks, state := lutStart(n)
+		for i := range ds {
+			dst[i].X, dst[i].Y = chunksDToXY(chunks, state, ds[i])
+		}
+		return dst
+	}
+	for i := range ds {
+		dst[i].X, dst[i].Y = bitsDToXY(n, ds[i])
+	}
+	return dst
+}
+
+// grow returns s resliced to length n, allocating a new slice only if
+// the capacity of s is less than n.
+func grow[T any](s []T, n int) []T {
+	if cap(s) < n {
+		return make([]T, n)
+	}
+	return s[:n]
+}
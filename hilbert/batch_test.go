@@ -0,0 +1,115 @@
+package hilbert
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestXYToDBatch(t *testing.T) {
+	ps := []Point{{0, 0}, {1, 0}, {0, 3}, {3, 3}, {3, 0}}
+	want := []uint64{0, 1, 5, 10, 15}
+	ds := XYToDBatch(4, nil, ps)
+	if !slices.Equal(ds, want) {
+		t.Fatalf("XYToDBatch(4, nil, %v) = %v, want %v", ps, ds, want)
+	}
+	back := DToXYBatch(4, nil, ds)
+	if !slices.Equal(back, ps) {
+		t.Errorf("DToXYBatch(4, nil, %v) = %v, want %v", ds, back, ps)
+	}
+}
+
+func TestBatchReusesDst(t *testing.T) {
+	ps := make([]Point, 100)
+	for i := range ps {
+		ps[i] = Point{uint32(i), uint32(i * 7)}
+	}
+	ds := XYToDBatch(1<<16, make([]uint64, 0, len(ps)), ps)
+	qs := DToXYBatch(1<<16, make([]Point, 0, len(ps)), ds)
+	if a := testing.AllocsPerRun(10, func() {
+		ds = XYToDBatch(1<<16, ds, ps)
+		qs = DToXYBatch(1<<16, qs, ds)
+	}); a != 0 {
+		t.Errorf("batches allocate %v times, want 0", a)
+	}
+	for i, p := range ps {
+		if d := XYToD64(1<<16, p.X, p.Y); ds[i] != d {
+			t.Fatalf("XYToDBatch: ds[%d] = %d, want %d", i, ds[i], d)
+		}
+	}
+	if !slices.Equal(qs, ps) {
+		t.Errorf("DToXYBatch(XYToDBatch(ps)) != ps")
+	}
+	if ds := XYToDBatch(4, make([]uint64, 10), ps[:2]); len(ds) != 2 {
+		t.Errorf("XYToDBatch of 2 points into 10 = %d distances", len(ds))
+	}
+}
+
+func TestBatchMatchesScalar(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	for order := 0; order <= 32; order++ {
+		n := uint64(1) << order
+		ps := randomCells(r, n, 50)
+		ds := XYToDBatch(n, nil, ps)
+		qs := DToXYBatch(n, nil, ds)
+		for i, p := range ps {
+			if d := XYToD64(n, p.X, p.Y); ds[i] != d {
+				t.Fatalf("XYToDBatch(%d): ds[%d] = %d, want %d", n, i, ds[i], d)
+			}
+			if x, y := DToXY64(n, ds[i]); qs[i] != (Point{x, y}) {
+				t.Fatalf("DToXYBatch(%d): qs[%d] = %v, want %v", n, i, qs[i], Point{x, y})
+			}
+		}
+	}
+}
+
+// randomCells returns count random cell positions of a curve with n
+// cells per side.
+func randomCells(r *rand.Rand, n uint64, count int) []Point {
+	ps := make([]Point, count)
+	for i := range ps {
+		ps[i] = Point{uint32(r.Uint64() & (n - 1)), uint32(r.Uint64() & (n - 1))}
+	}
+	return ps
+}
+
+func BenchmarkXYToDBatch(b *testing.B) {
+	r := rand.New(rand.NewSource(6))
+	for _, n := range []uint64{256, 1 << 32} {
+		ps := randomCells(r, n, 1024)
+		ds := make([]uint64, len(ps))
+		b.Run(fmt.Sprint("n=", n, "/batch"), func(b *testing.B) {
+			for range b.N {
+				ds = XYToDBatch(n, ds, ps)
+			}
+		})
+		b.Run(fmt.Sprint("n=", n, "/scalar"), func(b *testing.B) {
+			for range b.N {
+				for i, p := range ps {
+					ds[i] = XYToD64(n, p.X, p.Y)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDToXYBatch(b *testing.B) {
+	r := rand.New(rand.NewSource(6))
+	for _, n := range []uint64{256, 1 << 32} {
+		ds := XYToDBatch(n, nil, randomCells(r, n, 1024))
+		ps := make([]Point, len(ds))
+		b.Run(fmt.Sprint("n=", n, "/batch"), func(b *testing.B) {
+			for range b.N {
+				ps = DToXYBatch(n, ps, ds)
+			}
+		})
+		b.Run(fmt.Sprint("n=", n, "/scalar"), func(b *testing.B) {
+			for range b.N {
+				for i, d := range ds {
+					ps[i].X, ps[i].Y = DToXY64(n, d)
+				}
+			}
+		})
+	}
+}
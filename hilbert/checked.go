@@ -0,0 +1,72 @@
+package hilbert
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+var (
+	// ErrCellCount is returned, wrapped, by the checked functions when
+	// the cell count n is not a power of 2 or is too large for the
+	// distance to be represented.
+	ErrCellCount = errors.New("hilbert: invalid cell count")
+
+	// ErrOutOfRange is returned, wrapped, by the checked functions when
+	// a coordinate or distance lies outside the curve.
+	ErrOutOfRange = errors.New("hilbert: out of range")
+
+	// ErrOrder is returned, wrapped, by New when the requested curve
+	// order is not supported.
+	ErrOrder = errors.New("hilbert: invalid order")
+)
+
+// maxIntOrder is the largest curve order for which every distance fits in
+// an int.
+const maxIntOrder = (bits.UintSize - 1) / 2
+
+// XYToDE is the checked equivalent of XYToD. It returns exactly the
+// same distance as XYToD but, instead of silently producing a
+// meaningless result on invalid input, returns an error wrapping
+// ErrCellCount if n is not a power of 2 or n^2-1 does not fit in an
+// int, and an error wrapping ErrOutOfRange if x or y is outside the
+// range [0, n-1].
+func XYToDE(n, x, y int) (int, error) {
+	if err := checkN(n); err != nil {
+		return 0, err
+	}
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return 0, fmt.Errorf("%w: cell (%d, %d) is not in [0, %d]", ErrOutOfRange, x, y, n-1)
+	}
+	return XYToD(n, x, y), nil
+}
+
+// DToXYE is the checked equivalent of DToXY. It returns exactly the
+// same position as DToXY but, instead of silently producing a
+// meaningless result on invalid input, returns an error wrapping
+// ErrCellCount if n is not a power of 2 or n^2-1 does not fit in an
+// int, and an error wrapping ErrOutOfRange if d is outside the range
+// [0, n^2-1].
+func DToXYE(n, d int) (x, y int, err error) {
+	if err = checkN(n); err != nil {
+		return
+	}
+	if d < 0 || d > n*n-1 {
+		err = fmt.Errorf("%w: distance %d is not in [0, %d]", ErrOutOfRange, d, n*n-1)
+		return
+	}
+	x, y = DToXY(n, d)
+	return
+}
+
+// checkN returns an error wrapping ErrCellCount if n is not a valid
+// cell count for the int-based functions.
+func checkN(n int) error {
+	if n <= 0 || n&(n-1) != 0 {
+		return fmt.Errorf("%w: %d is not a power of 2", ErrCellCount, n)
+	}
+	if n > 1<<maxIntOrder {
+		return fmt.Errorf("%w: %d exceeds the maximum of %d", ErrCellCount, n, 1<<maxIntOrder)
+	}
+	return nil
+}
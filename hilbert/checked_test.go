@@ -0,0 +1,64 @@
+package hilbert
+
+import (
+	"errors"
+	"math/bits"
+	"testing"
+)
+
+func TestXYToDE(t *testing.T) {
+	tests := []struct {
+		n, x, y int
+		d       int
+		err     error
+	}{
+		{1, 0, 0, 0, nil},
+		{4, 3, 0, 15, nil},
+		{8, 7, 0, 63, nil},
+		{0, 0, 0, 0, ErrCellCount},
+		{-4, 0, 0, 0, ErrCellCount},
+		{6, 0, 0, 0, ErrCellCount},
+		{1 << (maxIntOrder + 1), 0, 0, 0, ErrCellCount},
+		{8, 8, 0, 0, ErrOutOfRange},
+		{8, 0, 8, 0, ErrOutOfRange},
+		{8, -1, 0, 0, ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		d, err := XYToDE(tt.n, tt.x, tt.y)
+		if !errors.Is(err, tt.err) || d != tt.d {
+			t.Errorf("XYToDE(%d, %d, %d) = %d, %v, want %d, %v", tt.n, tt.x, tt.y, d, err, tt.d, tt.err)
+		}
+	}
+}
+
+func TestXYToDELargest(t *testing.T) {
+	n := 1 << maxIntOrder
+	d, err := XYToDE(n, n-1, 0)
+	if want := n*n - 1; err != nil || d != want {
+		t.Errorf("XYToDE(%d, %d, 0) = %d, %v, want %d", n, n-1, d, err, want)
+	}
+	if bits.UintSize == 64 && maxIntOrder != 31 {
+		t.Errorf("maxIntOrder = %d, want 31", maxIntOrder)
+	}
+}
+
+func TestDToXYE(t *testing.T) {
+	tests := []struct {
+		n, d int
+		x, y int
+		err  error
+	}{
+		{1, 0, 0, 0, nil},
+		{4, 15, 3, 0, nil},
+		{4, 5, 0, 3, nil},
+		{6, 0, 0, 0, ErrCellCount},
+		{8, 64, 0, 0, ErrOutOfRange},
+		{8, -1, 0, 0, ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		x, y, err := DToXYE(tt.n, tt.d)
+		if !errors.Is(err, tt.err) || x != tt.x || y != tt.y {
+			t.Errorf("DToXYE(%d, %d) = (%d, %d), %v, want (%d, %d), %v", tt.n, tt.d, x, y, err, tt.x, tt.y, tt.err)
+		}
+	}
+}
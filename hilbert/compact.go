@@ -0,0 +1,153 @@
+package hilbert
+
+import (
+	"math/bits"
+
+	"github.com/gogama/geospat/gray"
+)
+
+// CompactIndex converts an N-dimensional cell position to its compact
+// Hilbert index, a one-dimensional distance along a discrete Hilbert
+// curve through a grid whose sides need not all have the same length.
+//
+// The number of dimensions N is len(m), which must equal len(p) and be
+// between 1 and 64. Dimension j of the grid has 2^m[j] cells and the
+// coordinate p[j] must be in the range [0, 2^m[j]-1]. The total number
+// of bits, the sum of all m[j], must not exceed 64.
+//
+// When every dimension has the same bit width, the compact index is the
+// ordinary N-dimensional Hilbert index, and consecutive distances map
+// to adjacent cells. Otherwise it orders the cells exactly as the
+// Hilbert curve through the smallest enclosing hypercube does, but with
+// the gaps left by cells outside the grid squeezed out, so the return
+// value h is a number in the range [0, 2^B-1] where B is the sum of the
+// bit widths.
+//
+// The implementation follows Chris H. Hamilton and Andrew
+// Rau-Chaplin, "Compact Hilbert indices: Space-filling curves for
+// domains with unequal side lengths", Information Processing Letters
+// 105 (2008).
+//
+// The complementary function CompactPoint performs the inverse mapping.
+func CompactIndex(m []int, p []uint64) (h uint64) {
+	n := len(m)
+	var e, d uint64
+	for i := maxBits(m) - 1; i >= 0; i-- {
+		mu := rotr(levelMask(m, i), d+1, n)
+		var l uint64
+		for j := n - 1; j >= 0; j-- {
+			l = l<<1 | p[j]>>uint(i)&1
+		}
+		l = rotr(l^e, d+1, n)
+		w := gray.Decode(l)
+		h = h<<uint(bits.OnesCount64(mu)) | gray.Rank(w, mu)
+		e ^= rotl(entry(w), d+1, n)
+		d = (d + direction(w, n) + 1) % uint64(n)
+	}
+	return
+}
+
+// CompactPoint converts a compact Hilbert index h to an N-dimensional
+// cell position, which it stores into p.
+//
+// The number of dimensions N is len(m), which must equal len(p) and be
+// between 1 and 64. Dimension j of the grid has 2^m[j] cells and the
+// sum of all m[j] must not exceed 64. The index h must be in the range
+// [0, 2^B-1] where B is the sum of the bit widths.
+//
+// The complementary function CompactIndex performs the inverse mapping.
+func CompactPoint(m []int, h uint64, p []uint64) {
+	n := len(m)
+	for j := range p {
+		p[j] = 0
+	}
+	b := 0
+	for _, mj := range m {
+		b += mj
+	}
+	var e, d uint64
+	for i := maxBits(m) - 1; i >= 0; i-- {
+		mu := rotr(levelMask(m, i), d+1, n)
+		pi := rotr(e, d+1, n) &^ mu
+		k := bits.OnesCount64(mu)
+		b -= k
+		r := h >> uint(b) & (1<<uint(k) - 1)
+		w, l := gray.RankInverse(r, mu, pi, n)
+		l = rotl(l, d+1, n) ^ e
+		for j := 0; j < n; j++ {
+			p[j] |= (l >> uint(j) & 1) << uint(i)
+		}
+		e ^= rotl(entry(w), d+1, n)
+		d = (d + direction(w, n) + 1) % uint64(n)
+	}
+}
+
+// maxBits returns the largest of the bit widths in m.
+func maxBits(m []int) (b int) {
+	for _, mj := range m {
+		if mj > b {
+			b = mj
+		}
+	}
+	return
+}
+
+// levelMask returns a mask with bit j set if dimension j has a bit at
+// position i, that is, if m[j] > i.
+func levelMask(m []int, i int) (mu uint64) {
+	for j := len(m) - 1; j >= 0; j-- {
+		mu <<= 1
+		if m[j] > i {
+			mu |= 1
+		}
+	}
+	return
+}
+
+// rotr rotates the low n bits of x right by k places.
+func rotr(x, k uint64, n int) uint64 {
+	k %= uint64(n)
+	if k == 0 {
+		return x
+	}
+	return (x>>k | x<<(uint64(n)-k)) & lowMask(n)
+}
+
+// rotl rotates the low n bits of x left by k places.
+func rotl(x, k uint64, n int) uint64 {
+	k %= uint64(n)
+	if k == 0 {
+		return x
+	}
+	return (x<<k | x>>(uint64(n)-k)) & lowMask(n)
+}
+
+// lowMask returns a mask of the low n bits.
+func lowMask(n int) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(n) - 1
+}
+
+// entry returns the entry point, into the sub-hypercube at position w
+// in Gray code order, of the Hilbert curve through that sub-hypercube.
+func entry(w uint64) uint64 {
+	if w == 0 {
+		return 0
+	}
+	return gray.Encode(2 * ((w - 1) / 2))
+}
+
+// direction returns the intra sub-hypercube direction of the Hilbert
+// curve through the sub-hypercube at position w in Gray code order.
+func direction(w uint64, n int) uint64 {
+	switch {
+	case w == 0:
+		return 0
+	case w%2 == 0:
+		return uint64(bits.TrailingZeros64(^(w - 1))) % uint64(n)
+	default:
+		return uint64(bits.TrailingZeros64(^w)) % uint64(n)
+	}
+}
@@ -0,0 +1,115 @@
+package hilbert
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCompactIndex(t *testing.T) {
+	tests := []struct {
+		m []int
+		p []uint64
+		h uint64
+	}{
+		{[]int{3}, []uint64{5}, 5},
+		{[]int{3, 1}, []uint64{0, 0}, 0},
+		{[]int{3, 1}, []uint64{0, 1}, 1},
+		{[]int{3, 1}, []uint64{1, 1}, 2},
+		{[]int{3, 1}, []uint64{1, 0}, 3},
+		{[]int{3, 1}, []uint64{2, 0}, 4},
+		{[]int{3, 1}, []uint64{3, 0}, 5},
+		{[]int{3, 1}, []uint64{3, 1}, 6},
+		{[]int{3, 1}, []uint64{2, 1}, 7},
+		{[]int{3, 1}, []uint64{7, 0}, 15},
+		{[]int{32, 32}, []uint64{1<<32 - 1, 0}, 1<<64 - 1},
+	}
+	for _, tt := range tests {
+		if h := CompactIndex(tt.m, tt.p); h != tt.h {
+			t.Errorf("CompactIndex(%v, %v) = %d, want %d", tt.m, tt.p, h, tt.h)
+		}
+		p := make([]uint64, len(tt.m))
+		CompactPoint(tt.m, tt.h, p)
+		for j := range p {
+			if p[j] != tt.p[j] {
+				t.Errorf("CompactPoint(%v, %d) = %v, want %v", tt.m, tt.h, p, tt.p)
+				break
+			}
+		}
+	}
+}
+
+func TestCompactIndexMatchesXYToD(t *testing.T) {
+	for k := 0; k <= 5; k++ {
+		n := 1 << k
+		for x := range n {
+			for y := range n {
+				if h, d := CompactIndex([]int{k, k}, []uint64{uint64(x), uint64(y)}), XYToD(n, x, y); h != uint64(d) {
+					t.Fatalf("CompactIndex(%d, %d) = %d, XYToD = %d", x, y, h, d)
+				}
+			}
+		}
+	}
+}
+
+func TestCompactCurve(t *testing.T) {
+	// Every cell is visited once, in the order of the Hilbert curve
+	// through the enclosing hypercube, and, when the sides are equal,
+	// consecutive cells are adjacent.
+	for _, m := range [][]int{{1}, {3}, {2, 2}, {4, 2}, {1, 3}, {3, 1, 2}, {1, 1, 1, 1}, {3, 3, 3}, {2, 1, 2, 1, 2}, {0, 3}, {5, 5}, {5, 2, 3}} {
+		bits, maxBits := 0, 0
+		for _, mj := range m {
+			bits += mj
+			maxBits = max(maxBits, mj)
+		}
+		cube := make([]int, len(m))
+		for j := range cube {
+			cube[j] = maxBits
+		}
+		seen := map[[5]uint64]bool{}
+		p, prev := make([]uint64, len(m)), make([]uint64, len(m))
+		var prevCube uint64
+		for h := range uint64(1) << bits {
+			CompactPoint(m, h, p)
+			var key [5]uint64
+			dist := 0
+			for j := range p {
+				if p[j] >= 1<<m[j] {
+					t.Fatalf("CompactPoint(%v, %d) = %v, outside the grid", m, h, p)
+				}
+				key[j] = p[j]
+				dist += abs(int(p[j]) - int(prev[j]))
+			}
+			if seen[key] {
+				t.Fatalf("CompactPoint(%v, %d) = %v, visited twice", m, h, p)
+			}
+			seen[key] = true
+			if h2 := CompactIndex(m, p); h2 != h {
+				t.Fatalf("CompactIndex(%v, CompactPoint(%d)) = %d", m, h, h2)
+			}
+			c := CompactIndex(cube, p)
+			if h > 0 && c <= prevCube {
+				t.Fatalf("CompactPoint(%v, %d) = %v, out of the order of the hypercube", m, h, p)
+			}
+			if h > 0 && bits == maxBits*len(m) && dist != 1 {
+				t.Fatalf("CompactPoint(%v, %d) = %v, not adjacent to %v", m, h, p, prev)
+			}
+			prevCube = c
+			copy(prev, p)
+		}
+	}
+}
+
+func TestCompactRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	m := []int{20, 13, 31}
+	p, q := make([]uint64, 3), make([]uint64, 3)
+	for range 10000 {
+		for j := range p {
+			p[j] = r.Uint64() & (1<<m[j] - 1)
+		}
+		CompactPoint(m, CompactIndex(m, p), q)
+		if p[0] != q[0] || p[1] != q[1] || p[2] != q[2] {
+			t.Fatalf("CompactPoint(CompactIndex(%v)) = %v", p, q)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package hilbert
+
+import "fmt"
+
+// MaxOrder is the largest order supported by Curve.
+const MaxOrder = 32
+
+// Curve is a discrete Hilbert curve of fixed order through a square
+// divided into 2^order X 2^order cells. Because the order is fixed when
+// the Curve is created, it does not need to be passed to each
+// conversion, and a Curve is a convenient building block to embed in
+// higher-level index types.
+//
+// The zero value is a valid curve of order 0, which consists of a
+// single cell. Curve is a small value type which is safe for concurrent
+// use.
+type Curve struct {
+	order int
+	n     uint64
+	maxD  uint64
+}
+
+// New returns the Hilbert curve of the given order, which must be in
+// the range [0, MaxOrder]. For any other order New returns an error
+// wrapping ErrOrder.
+func New(order int) (Curve, error) {
+	if order < 0 || order > MaxOrder {
+		return Curve{}, fmt.Errorf("%w: %d is not in [0, %d]", ErrOrder, order, MaxOrder)
+	}
+	c := Curve{order: order}
+	if order > 0 {
+		c.n = 1 << uint(order)
+		c.maxD = c.n*c.n - 1 // Wraps to 2^64-1 at order 32
+	}
+	return c, nil
+}
+
+// Order returns the order of the curve.
+func (c Curve) Order() int {
+	return c.order
+}
+
+// N returns the number of cells along each side of the square,
+// 2^Order(). Valid coordinates range from 0 to N()-1.
+func (c Curve) N() uint64 {
+	return 1 << uint(c.order)
+}
+
+// MaxD returns the largest distance along the curve, N()^2-1.
+func (c Curve) MaxD() uint64 {
+	return c.maxD
+}
+
+// XYToD converts a cell position with coordinates (x, y) to a distance
+// along the curve, as XYToD64 does. The coordinates x and y must be in
+// the range [0, N()-1] and the return value is in the range
+// [0, MaxD()].
+func (c Curve) XYToD(x, y uint32) uint64 {
+	return XYToD64(c.n, x, y)
+}
+
+// DToXY converts a distance along the curve, which must be in the range
+// [0, MaxD()], to a cell position with coordinates (x, y), as DToXY64
+// does.
+func (c Curve) DToXY(d uint64) (x, y uint32) {
+	return DToXY64(c.n, d)
+}
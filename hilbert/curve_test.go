@@ -0,0 +1,65 @@
+package hilbert
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		order int
+		n     uint64
+		maxD  uint64
+		err   error
+	}{
+		{0, 1, 0, nil},
+		{1, 2, 3, nil},
+		{3, 8, 63, nil},
+		{16, 1 << 16, 1<<32 - 1, nil},
+		{32, 1 << 32, 1<<64 - 1, nil},
+		{-1, 1, 0, ErrOrder},
+		{33, 1, 0, ErrOrder},
+	}
+	for _, tt := range tests {
+		c, err := New(tt.order)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("New(%d) error = %v, want %v", tt.order, err, tt.err)
+			continue
+		}
+		if c.N() != tt.n || c.MaxD() != tt.maxD {
+			t.Errorf("New(%d) = N %d, MaxD %d, want %d, %d", tt.order, c.N(), c.MaxD(), tt.n, tt.maxD)
+		}
+		if err == nil && c.Order() != tt.order {
+			t.Errorf("New(%d).Order() = %d", tt.order, c.Order())
+		}
+	}
+}
+
+func TestCurveZero(t *testing.T) {
+	var c Curve
+	if c.Order() != 0 || c.N() != 1 || c.MaxD() != 0 {
+		t.Errorf("zero Curve = order %d, N %d, MaxD %d, want 0, 1, 0", c.Order(), c.N(), c.MaxD())
+	}
+	if d := c.XYToD(0, 0); d != 0 {
+		t.Errorf("zero Curve XYToD(0, 0) = %d", d)
+	}
+	if x, y := c.DToXY(0); x != 0 || y != 0 {
+		t.Errorf("zero Curve DToXY(0) = (%d, %d)", x, y)
+	}
+}
+
+func TestCurveXYToD(t *testing.T) {
+	for _, tt := range curveTests {
+		order := 0
+		for 1<<order < tt.n {
+			order++
+		}
+		c, _ := New(order)
+		if d := c.XYToD(tt.x, tt.y); d != tt.d {
+			t.Errorf("order %d: XYToD(%d, %d) = %d, want %d", order, tt.x, tt.y, d, tt.d)
+		}
+		if x, y := c.DToXY(tt.d); x != tt.x || y != tt.y {
+			t.Errorf("order %d: DToXY(%d) = (%d, %d), want (%d, %d)", order, tt.d, x, y, tt.x, tt.y)
+		}
+	}
+}
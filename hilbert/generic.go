@@ -0,0 +1,75 @@
+package hilbert
+
+// Unsigned is a constraint that permits any unsigned integer type. It
+// is equivalent to constraints.Unsigned from golang.org/x/exp, and is
+// declared here so the package does not need the dependency.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// XYToDOf is the type-parameterized equivalent of XYToD. It converts a
+// cell position with coordinates (x, y) of type C to a distance (d) of
+// type D along a discrete Hilbert curve constructed by dividing a
+// square into n X n cells. The index type is usually given explicitly
+// and the coordinate type inferred, for example:
+//
+//	d := hilbert.XYToDOf[uint64](1<<32, x, y) // x, y are uint32
+//
+// The cell count n must be a power of 2, n-1 must be representable in
+// C, and n^2-1 must be representable in D. The coordinates x and y must
+// be in the range [0, n-1] and the return value d is in the range
+// [0, n^2-1].
+//
+// The complementary function DToXYOf performs the inverse mapping.
+func XYToDOf[D, C Unsigned](n D, x, y C) (d D) {
+	for s := n / 2; s > 0; s /= 2 {
+		var rx, ry D
+		if D(x)&s > 0 {
+			rx = 1
+		}
+		if D(y)&s > 0 {
+			ry = 1
+		}
+		d += s * s * ((3 * rx) ^ ry)
+		rotOf(C(s), rx, ry, &x, &y)
+	}
+	return
+}
+
+// DToXYOf is the type-parameterized equivalent of DToXY. It converts a
+// distance (d) of type D along a discrete Hilbert curve to a cell
+// position with coordinates (x, y) of type C. The coordinate type is
+// usually given explicitly and the index type inferred, for example:
+//
+//	x, y := hilbert.DToXYOf[uint32](1<<32, d) // d is a uint64
+//
+// The cell count n must be a power of 2, n-1 must be representable in
+// C, and n^2-1 must be representable in D. The distance d must be in
+// the range [0, n^2-1].
+//
+// The complementary function XYToDOf performs the inverse mapping.
+func DToXYOf[C, D Unsigned](n, d D) (x, y C) {
+	t := d
+	for s := D(1); s < n; s *= 2 {
+		rx := 1 & (t / 2)
+		ry := 1 & (t ^ rx)
+		rotOf(C(s), rx, ry, &x, &y)
+		x += C(s * rx)
+		y += C(s * ry)
+		t /= 4
+	}
+	return
+}
+
+// rotOf is the type-parameterized equivalent of rot. As with rot64,
+// unsigned subtraction wraps around and leaves the bits below n exactly
+// as rot would.
+func rotOf[C, D Unsigned](n C, rx, ry D, x, y *C) {
+	if ry == 0 {
+		if rx == 1 {
+			*x = n - 1 - *x
+			*y = n - 1 - *y
+		}
+		*x, *y = *y, *x // Swap x and y
+	}
+}
@@ -0,0 +1,37 @@
+package hilbert
+
+import "testing"
+
+func TestXYToDOf(t *testing.T) {
+	for _, tt := range curveTests {
+		if d := XYToDOf(tt.n, tt.x, tt.y); d != tt.d {
+			t.Errorf("XYToDOf(%d, %d, %d) = %d, want %d", tt.n, tt.x, tt.y, d, tt.d)
+		}
+		if x, y := DToXYOf[uint32](tt.n, tt.d); x != tt.x || y != tt.y {
+			t.Errorf("DToXYOf(%d, %d) = (%d, %d), want (%d, %d)", tt.n, tt.d, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestXYToDOfSmallTypes(t *testing.T) {
+	// A curve of order 8 has uint8 coordinates and uint16 distances.
+	for x := range 256 {
+		for y := range 256 {
+			d := XYToDOf[uint16](256, uint8(x), uint8(y))
+			if want := XYToD(256, x, y); int(d) != want {
+				t.Fatalf("XYToDOf[uint16](256, %d, %d) = %d, want %d", x, y, d, want)
+			}
+			if x2, y2 := DToXYOf[uint8](uint16(256), d); int(x2) != x || int(y2) != y {
+				t.Fatalf("DToXYOf[uint8](256, %d) = (%d, %d), want (%d, %d)", d, x2, y2, x, y)
+			}
+		}
+	}
+}
+
+type cellIndex uint64
+
+func TestXYToDOfNamedTypes(t *testing.T) {
+	if d := XYToDOf(cellIndex(4), uint(3), uint(0)); d != cellIndex(15) {
+		t.Errorf("XYToDOf(cellIndex(4), 3, 0) = %d, want 15", d)
+	}
+}
@@ -0,0 +1,67 @@
+package hilbert
+
+import "math/bits"
+
+// The Hilbert curves of successive orders are nested: the cell at
+// distance d on the curve of order k is divided into the four cells at
+// distances 4d, 4d+1, 4d+2 and 4d+3 on the curve of order k+1, and the
+// curve of order k+1 visits them consecutively. (Consequently, the cell
+// at (x, y) at order k+1 is the child of the cell at (x/2, y/2) at
+// order k.) The functions below exploit this to navigate the hierarchy
+// with bit arithmetic alone. In each of them, a level is the order of
+// the curve a distance lies on and must be in the range [0, MaxOrder].
+
+// Parent returns the distance, on the curve of order level-1, of the
+// parent of the cell at distance d on the curve of order level. The
+// level must be at least 1.
+func Parent(d uint64, level int) uint64 {
+	return Ancestor(d, level, level-1)
+}
+
+// Ancestor returns the distance, on the curve of order ancestorLevel, of
+// the ancestor of the cell at distance d on the curve of order level.
+// The ancestor level must not exceed level; if it equals level, d is
+// returned unchanged.
+func Ancestor(d uint64, level, ancestorLevel int) uint64 {
+	return d >> uint(2*(level-ancestorLevel))
+}
+
+// Children returns the distances, on the curve of order level+1, of the
+// four children of the cell at distance d on the curve of order level,
+// in increasing order. The level must be less than MaxOrder.
+func Children(d uint64, level int) [4]uint64 {
+	return [4]uint64{4 * d, 4*d + 1, 4*d + 2, 4*d + 3}
+}
+
+// Descendants returns the range [lo, hi] of distances, on the curve of
+// order descendantLevel, of the descendants of the cell at distance d on
+// the curve of order level. Because the descendants of a cell are
+// visited consecutively, the range contains no other cells. The
+// descendant level must be at least level.
+func Descendants(d uint64, level, descendantLevel int) (lo, hi uint64) {
+	shift := uint(2 * (descendantLevel - level))
+	lo = d << shift
+	hi = lo | (uint64(1)<<shift - 1)
+	return
+}
+
+// Contains reports whether the cell at distance a on the curve of order
+// aLevel contains the cell at distance b on the curve of order bLevel.
+// Every cell contains itself, and no cell contains a cell at a coarser
+// level.
+func Contains(a uint64, aLevel int, b uint64, bLevel int) bool {
+	return aLevel <= bLevel && Ancestor(b, bLevel, aLevel) == a
+}
+
+// CommonAncestor returns the smallest cell which contains both of the
+// cells at distances a and b on the curve of order level, as its
+// distance d on the curve of order ancestorLevel. If a equals b, the
+// common ancestor is the cell itself. Every pair of cells has a common
+// ancestor, since the single cell at level 0 contains everything.
+func CommonAncestor(a, b uint64, level int) (d uint64, ancestorLevel int) {
+	// The cells share the ancestors whose distances are prefixes of
+	// both a and b, so count the identical pairs of leading bits.
+	diff := bits.Len64(a ^ b)
+	up := (diff + 1) / 2
+	return a >> uint(2*up), level - up
+}
@@ -0,0 +1,88 @@
+package hilbert
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHierarchy(t *testing.T) {
+	if p := Parent(13, 2); p != 3 {
+		t.Errorf("Parent(13, 2) = %d, want 3", p)
+	}
+	if a := Ancestor(0xabcd, 8, 4); a != 0xab {
+		t.Errorf("Ancestor(0xabcd, 8, 4) = %#x, want 0xab", a)
+	}
+	if a := Ancestor(7, 3, 3); a != 7 {
+		t.Errorf("Ancestor(7, 3, 3) = %d, want 7", a)
+	}
+	if c := Children(3, 1); c != [4]uint64{12, 13, 14, 15} {
+		t.Errorf("Children(3, 1) = %v", c)
+	}
+	if lo, hi := Descendants(2, 1, 3); lo != 32 || hi != 47 {
+		t.Errorf("Descendants(2, 1, 3) = [%d, %d], want [32, 47]", lo, hi)
+	}
+	if lo, hi := Descendants(1<<62-1, 31, 32); lo != 1<<64-4 || hi != 1<<64-1 {
+		t.Errorf("Descendants(1<<62-1, 31, 32) = [%d, %d]", lo, hi)
+	}
+	containsTests := []struct {
+		a      uint64
+		aLevel int
+		b      uint64
+		bLevel int
+		want   bool
+	}{
+		{3, 1, 13, 2, true},
+		{3, 1, 11, 2, false},
+		{13, 2, 3, 1, false},
+		{5, 2, 5, 2, true},
+		{0, 0, 1<<64 - 1, 32, true},
+	}
+	for _, tt := range containsTests {
+		if got := Contains(tt.a, tt.aLevel, tt.b, tt.bLevel); got != tt.want {
+			t.Errorf("Contains(%d, %d, %d, %d) = %t, want %t", tt.a, tt.aLevel, tt.b, tt.bLevel, got, tt.want)
+		}
+	}
+	ancestorTests := []struct {
+		a, b  uint64
+		level int
+		d     uint64
+		dl    int
+	}{
+		{13, 13, 2, 13, 2},
+		{12, 15, 2, 3, 1},
+		{11, 12, 2, 0, 0},
+		{0, 1<<64 - 1, 32, 0, 0},
+	}
+	for _, tt := range ancestorTests {
+		if d, dl := CommonAncestor(tt.a, tt.b, tt.level); d != tt.d || dl != tt.dl {
+			t.Errorf("CommonAncestor(%d, %d, %d) = %d, %d, want %d, %d", tt.a, tt.b, tt.level, d, dl, tt.d, tt.dl)
+		}
+	}
+}
+
+func TestHierarchyRandom(t *testing.T) {
+	// The parent of a cell is the cell at half its coordinates, and the
+	// common ancestor of two cells is the smallest which contains both.
+	r := rand.New(rand.NewSource(31))
+	for range 10000 {
+		k := 1 + r.Intn(31)
+		n := uint64(1) << k
+		x, y := uint32(r.Uint64()%n), uint32(r.Uint64()%n)
+		d := XYToD64(n, x, y)
+		if p := Parent(d, k); p != XYToD64(n/2, x/2, y/2) {
+			t.Fatalf("Parent(%d, %d) = %d, want the cell of (%d, %d)", d, k, p, x/2, y/2)
+		}
+		x2, y2 := uint32(r.Uint64()%n), uint32(r.Uint64()%n)
+		d2 := XYToD64(n, x2, y2)
+		a, al := CommonAncestor(d, d2, k)
+		if !Contains(a, al, d, k) || !Contains(a, al, d2, k) {
+			t.Fatalf("CommonAncestor(%d, %d, %d) = %d, %d, which does not contain both", d, d2, k, a, al)
+		}
+		if al < k && Ancestor(d, k, al+1) == Ancestor(d2, k, al+1) {
+			t.Fatalf("CommonAncestor(%d, %d, %d) = %d, %d, not the smallest", d, d2, k, a, al)
+		}
+		if lo, hi := Descendants(a, al, k); d < lo || d > hi || d2 < lo || d2 > hi {
+			t.Fatalf("Descendants(%d, %d, %d) = [%d, %d], without %d or %d", a, al, k, lo, hi, d, d2)
+		}
+	}
+}
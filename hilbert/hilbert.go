@@ -15,6 +15,9 @@ package hilbert
 // The complementary function DToXY performs the inverse mapping from
 // one-dimensional distance (d) back to two-dimensional position (x, y).
 func XYToD(n, x, y int) (d int) {
+	if n > 1 && n <= lutMaxN {
+		return int(lutXYToD(uint64(n), uint32(x), uint32(y)))
+	}
 	for s:=n/2; s>0; s/=2 {
 		var rx, ry int
 		if x&s > 0 {
@@ -46,6 +49,10 @@ func XYToD(n, x, y int) (d int) {
 //
 // The complementary function XYToD performs the inverse transformation.
 func DToXY(n, d int) (x, y int) {
+	if n > 1 && n <= lutMaxN {
+		ux, uy := lutDToXY(uint64(n), uint64(d))
+		return int(ux), int(uy)
+	}
 	t := d
 	for s:=1; s<n; s*=2 {
 		rx := 1 & (t/2)
@@ -0,0 +1,117 @@
+package hilbert
+
+// XYZToD converts a three-dimensional cell position with coordinates
+// (x, y, z) to a one-dimensional distance (d) along a discrete Hilbert
+// curve. The curve is constructed by dividing a cube into n X n X n
+// cells.
+//
+// The cell count n must be a power of 2, and the coordinates x, y, and
+// z must range from (0, 0, 0) representing the cell at the origin
+// corner of the cube, to (n-1, n-1, n-1) representing the cell at the
+// opposite corner.
+//
+// The return value d is a number in the range [0, n^3-1]. The curve
+// starts at the origin cell (0, 0, 0) and consecutive distances always
+// map to face-adjacent cells.
+//
+// The complementary function DToXYZ performs the inverse mapping from
+// one-dimensional distance (d) back to three-dimensional position
+// (x, y, z).
+func XYZToD(n, x, y, z int) (d int) {
+	X := [3]int{x, y, z}
+	axesToTranspose(X[:], n)
+	for s := n / 2; s > 0; s /= 2 {
+		for i := range X {
+			d <<= 1
+			if X[i]&s > 0 {
+				d |= 1
+			}
+		}
+	}
+	return
+}
+
+// DToXYZ converts a one-dimensional distance (d) along a discrete
+// three-dimensional Hilbert curve to a cell position with coordinates
+// (x, y, z).
+//
+// The cell count n must be a power of 2, and the distance d must range
+// from 0, representing the cell at the origin corner of the cube, to
+// n^3-1.
+//
+// The return value (x, y, z), where x, y, and z are between 0 and n-1,
+// is the position of the cell to which d corresponds.
+//
+// The complementary function XYZToD performs the inverse
+// transformation.
+func DToXYZ(n, d int) (x, y, z int) {
+	var X [3]int
+	shift := 0
+	for s := 1; s < n; s *= 2 {
+		for i := len(X) - 1; i >= 0; i-- {
+			X[i] |= (d >> shift & 1) * s
+			shift++
+		}
+	}
+	transposeToAxes(X[:], n)
+	return X[0], X[1], X[2]
+}
+
+// axesToTranspose converts the coordinates in X, in place, to the
+// "transposed" Hilbert index described in John Skilling, "Programming
+// the Hilbert curve", AIP Conference Proceedings 707, 381 (2004). The
+// full index is obtained by interleaving the bits of the transposed
+// coordinates, most significant bit first, starting with X[0].
+//
+// The cell count n must be a power of 2.
+func axesToTranspose(X []int, n int) {
+	// Inverse undo.
+	for q := n / 2; q > 1; q /= 2 {
+		p := q - 1
+		for i := range X {
+			if X[i]&q > 0 {
+				X[0] ^= p // Invert
+			} else {
+				t := (X[0] ^ X[i]) & p // Exchange
+				X[0] ^= t
+				X[i] ^= t
+			}
+		}
+	}
+	// Gray encode.
+	for i := 1; i < len(X); i++ {
+		X[i] ^= X[i-1]
+	}
+	t := 0
+	for q := n / 2; q > 1; q /= 2 {
+		if X[len(X)-1]&q > 0 {
+			t ^= q - 1
+		}
+	}
+	for i := range X {
+		X[i] ^= t
+	}
+}
+
+// transposeToAxes is the inverse of axesToTranspose.
+func transposeToAxes(X []int, n int) {
+	// Gray decode.
+	t := X[len(X)-1] >> 1
+	for i := len(X) - 1; i > 0; i-- {
+		X[i] ^= X[i-1]
+	}
+	X[0] ^= t
+	// Undo excess work.
+	for q := 2; q < n; q *= 2 {
+		p := q - 1
+		for i := len(X) - 1; i >= 0; i-- {
+			if X[i]&q > 0 {
+				X[0] ^= p // Invert
+			} else {
+				t := (X[0] ^ X[i]) & p // Exchange
+				X[0] ^= t
+				X[i] ^= t
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package hilbert
+
+import "testing"
+
+func TestXYZToD(t *testing.T) {
+	tests := []struct {
+		n, x, y, z, d int
+	}{
+		{1, 0, 0, 0, 0},
+		// At order 1 the cells are the Gray codes of the distances.
+		{2, 0, 0, 0, 0},
+		{2, 0, 0, 1, 1},
+		{2, 0, 1, 1, 2},
+		{2, 0, 1, 0, 3},
+		{2, 1, 1, 0, 4},
+		{2, 1, 1, 1, 5},
+		{2, 1, 0, 1, 6},
+		{2, 1, 0, 0, 7},
+		{4, 0, 0, 1, 7},
+		{4, 0, 0, 2, 8},
+		{4, 3, 0, 0, 63},
+	}
+	for _, tt := range tests {
+		if d := XYZToD(tt.n, tt.x, tt.y, tt.z); d != tt.d {
+			t.Errorf("XYZToD(%d, %d, %d, %d) = %d, want %d", tt.n, tt.x, tt.y, tt.z, d, tt.d)
+		}
+		if x, y, z := DToXYZ(tt.n, tt.d); x != tt.x || y != tt.y || z != tt.z {
+			t.Errorf("DToXYZ(%d, %d) = (%d, %d, %d), want (%d, %d, %d)", tt.n, tt.d, x, y, z, tt.x, tt.y, tt.z)
+		}
+	}
+}
+
+func TestDToXYZCurve(t *testing.T) {
+	// Every cell is visited once, and consecutive cells share a face.
+	for n := 1; n <= 32; n *= 2 {
+		seen := make([]bool, n*n*n)
+		var px, py, pz int
+		for d := range n * n * n {
+			x, y, z := DToXYZ(n, d)
+			if x < 0 || x >= n || y < 0 || y >= n || z < 0 || z >= n {
+				t.Fatalf("DToXYZ(%d, %d) = (%d, %d, %d), outside the cube", n, d, x, y, z)
+			}
+			if seen[(x*n+y)*n+z] {
+				t.Fatalf("DToXYZ(%d, %d) = (%d, %d, %d), visited twice", n, d, x, y, z)
+			}
+			seen[(x*n+y)*n+z] = true
+			if d > 0 && abs(x-px)+abs(y-py)+abs(z-pz) != 1 {
+				t.Fatalf("DToXYZ(%d, %d) = (%d, %d, %d), not adjacent to (%d, %d, %d)", n, d, x, y, z, px, py, pz)
+			}
+			if d2 := XYZToD(n, x, y, z); d2 != d {
+				t.Fatalf("XYZToD(DToXYZ(%d, %d)) = %d", n, d, d2)
+			}
+			px, py, pz = x, y, z
+		}
+	}
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,86 @@
+package hilbert
+
+// XYToD64 is the 64-bit equivalent of XYToD. It converts a
+// two-dimensional cell position with coordinates (x, y) to a
+// one-dimensional distance (d) along a discrete Hilbert curve
+// constructed by dividing a square into n X n cells.
+//
+// The cell count n must be a power of 2 no greater than 2^32, so curve
+// orders from 0 up to 32 are supported. The coordinates x and y must
+// range from (0, 0), representing the cell at the lower left-hand
+// corner of the square, to (n-1, n-1), representing the cell at the
+// upper right-hand corner of the square.
+//
+// The return value d is a number in the range [0, n^2-1]. Because d is
+// a uint64, the full range of 32-bit coordinates maps to the full range
+// of 64-bit distances irrespective of the size of int on the target
+// platform.
+//
+// The complementary function DToXY64 performs the inverse mapping.
+func XYToD64(n uint64, x, y uint32) (d uint64) {
+	if n > 1 && n <= lutMaxN {
+		return lutXYToD(n, x, y)
+	}
+	return bitsXYToD(n, x, y)
+}
+
+// bitsXYToD is the iterative conversion of XYToD64, which handles one
+// bit of each coordinate at a time.
+func bitsXYToD(n uint64, x, y uint32) (d uint64) {
+	for s := n / 2; s > 0; s /= 2 {
+		var rx, ry uint64
+		if uint64(x)&s > 0 {
+			rx = 1
+		}
+		if uint64(y)&s > 0 {
+			ry = 1
+		}
+		d += s * s * ((3 * rx) ^ ry)
+		rot64(uint32(s), rx, ry, &x, &y)
+	}
+	return
+}
+
+// DToXY64 is the 64-bit equivalent of DToXY. It converts a
+// one-dimensional distance (d) along a discrete Hilbert curve to a
+// two-dimensional cell position with coordinates (x, y).
+//
+// The cell count n must be a power of 2 no greater than 2^32, and the
+// distance d must range from 0, representing the cell in the lower
+// left-hand corner of the square, to n^2-1, representing the cell in
+// the lower right-hand corner of the square.
+//
+// The complementary function XYToD64 performs the inverse mapping.
+func DToXY64(n, d uint64) (x, y uint32) {
+	if n > 1 && n <= lutMaxN {
+		return lutDToXY(n, d)
+	}
+	return bitsDToXY(n, d)
+}
+
+// bitsDToXY is the iterative conversion of DToXY64, which handles one
+// bit of each coordinate at a time.
+func bitsDToXY(n, d uint64) (x, y uint32) {
+	t := d
+	for s := uint64(1); s < n; s *= 2 {
+		rx := 1 & (t / 2)
+		ry := 1 & (t ^ rx)
+		rot64(uint32(s), rx, ry, &x, &y)
+		x += uint32(s * rx)
+		y += uint32(s * ry)
+		t /= 4
+	}
+	return
+}
+
+// rot64 is the unsigned equivalent of rot. Subtraction wraps around
+// modulo 2^32, which leaves the bits below n exactly as rot would.
+func rot64(n uint32, rx, ry uint64, x, y *uint32) {
+	if ry == 0 {
+		if rx == 1 {
+			*x = n - 1 - *x
+			*y = n - 1 - *y
+		}
+		*x, *y = *y, *x // Swap x and y
+	}
+}
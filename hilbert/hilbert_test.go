@@ -1,3 +1,120 @@
 package hilbert
 
-// TODO: Test.
+import (
+	"math/rand"
+	"testing"
+)
+
+// curveTests are known cells of the curves of orders 1 and 2, and the
+// corners of the curve of order 32, which, as for order 2, are at
+// distances 0101... and 1010... in binary.
+var curveTests = []struct {
+	n    uint64
+	x, y uint32
+	d    uint64
+}{
+	{1, 0, 0, 0},
+
+	{2, 0, 0, 0},
+	{2, 0, 1, 1},
+	{2, 1, 1, 2},
+	{2, 1, 0, 3},
+
+	{4, 0, 0, 0},
+	{4, 1, 0, 1},
+	{4, 1, 1, 2},
+	{4, 0, 1, 3},
+	{4, 0, 2, 4},
+	{4, 0, 3, 5},
+	{4, 1, 3, 6},
+	{4, 1, 2, 7},
+	{4, 2, 2, 8},
+	{4, 2, 3, 9},
+	{4, 3, 3, 10},
+	{4, 3, 2, 11},
+	{4, 3, 1, 12},
+	{4, 2, 1, 13},
+	{4, 2, 0, 14},
+	{4, 3, 0, 15},
+
+	{1 << 32, 0, 0, 0},
+	{1 << 32, 0, 1<<32 - 1, 0x5555555555555555},
+	{1 << 32, 1<<32 - 1, 1<<32 - 1, 0xaaaaaaaaaaaaaaaa},
+	{1 << 32, 1<<32 - 1, 0, 1<<64 - 1},
+}
+
+func TestXYToD(t *testing.T) {
+	for _, tt := range curveTests {
+		if tt.n > 1<<16 {
+			continue
+		}
+		if d := XYToD(int(tt.n), int(tt.x), int(tt.y)); d != int(tt.d) {
+			t.Errorf("XYToD(%d, %d, %d) = %d, want %d", tt.n, tt.x, tt.y, d, tt.d)
+		}
+	}
+}
+
+func TestDToXY(t *testing.T) {
+	for _, tt := range curveTests {
+		if tt.n > 1<<16 {
+			continue
+		}
+		if x, y := DToXY(int(tt.n), int(tt.d)); x != int(tt.x) || y != int(tt.y) {
+			t.Errorf("DToXY(%d, %d) = (%d, %d), want (%d, %d)", tt.n, tt.d, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestXYToD64(t *testing.T) {
+	for _, tt := range curveTests {
+		if d := XYToD64(tt.n, tt.x, tt.y); d != tt.d {
+			t.Errorf("XYToD64(%d, %d, %d) = %d, want %d", tt.n, tt.x, tt.y, d, tt.d)
+		}
+	}
+}
+
+func TestDToXY64(t *testing.T) {
+	for _, tt := range curveTests {
+		if x, y := DToXY64(tt.n, tt.d); x != tt.x || y != tt.y {
+			t.Errorf("DToXY64(%d, %d) = (%d, %d), want (%d, %d)", tt.n, tt.d, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestXYToD64MatchesXYToD(t *testing.T) {
+	for n := 1; n <= 256; n *= 2 {
+		for x := range n {
+			for y := range n {
+				d := XYToD(n, x, y)
+				if d64 := XYToD64(uint64(n), uint32(x), uint32(y)); d64 != uint64(d) {
+					t.Fatalf("XYToD64(%d, %d, %d) = %d, XYToD = %d", n, x, y, d64, d)
+				}
+			}
+		}
+	}
+}
+
+func TestRoundTrip64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 10000 {
+		x, y := r.Uint32(), r.Uint32()
+		d := XYToD64(1<<32, x, y)
+		if x2, y2 := DToXY64(1<<32, d); x2 != x || y2 != y {
+			t.Fatalf("DToXY64(XYToD64(%d, %d)) = (%d, %d)", x, y, x2, y2)
+		}
+	}
+}
+
+func TestAdjacent64(t *testing.T) {
+	// Consecutive distances are cells which share a side.
+	r := rand.New(rand.NewSource(2))
+	for range 10000 {
+		d := r.Uint64() >> 1
+		x0, y0 := DToXY64(1<<32, d)
+		x1, y1 := DToXY64(1<<32, d+1)
+		dx, dy := int64(x1)-int64(x0), int64(y1)-int64(y0)
+		if dx*dx+dy*dy != 1 {
+			t.Fatalf("cells %d (%d, %d) and %d (%d, %d) not adjacent", d, x0, y0, d+1, x1, y1)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package hilbert
+
+import "iter"
+
+// All returns an iterator over every cell of the curve in order of
+// increasing distance. Each iteration yields the distance d, from 0 to
+// MaxD(), together with the position of the corresponding cell. No
+// storage proportional to the size of the curve is allocated, so All is
+// suitable for streaming even the largest curves.
+//
+//	for d, p := range c.All() {
+//		render(d, p.X, p.Y)
+//	}
+func (c Curve) All() iter.Seq2[uint64, Point] {
+	return func(yield func(uint64, Point) bool) {
+		for d := uint64(0); ; d++ {
+			x, y := c.DToXY(d)
+			if !yield(d, Point{x, y}) || d == c.maxD {
+				return
+			}
+		}
+	}
+}
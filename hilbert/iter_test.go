@@ -0,0 +1,36 @@
+package hilbert
+
+import "testing"
+
+func TestCurveAll(t *testing.T) {
+	for order := range 6 {
+		c, _ := New(order)
+		next := uint64(0)
+		for d, p := range c.All() {
+			if d != next {
+				t.Fatalf("order %d: All yielded %d after %d", order, d, next-1)
+			}
+			if x, y := c.DToXY(d); p.X != x || p.Y != y {
+				t.Fatalf("order %d: All yielded %d at %v, want (%d, %d)", order, d, p, x, y)
+			}
+			next++
+		}
+		if next != c.MaxD()+1 {
+			t.Errorf("order %d: All yielded %d cells, want %d", order, next, c.MaxD()+1)
+		}
+	}
+}
+
+func TestCurveAllStops(t *testing.T) {
+	c, _ := New(32)
+	n := 0
+	for d := range c.All() {
+		if d == 9 {
+			break
+		}
+		n++
+	}
+	if n != 9 {
+		t.Errorf("All yielded %d cells before the break, want 9", n)
+	}
+}
@@ -0,0 +1,43 @@
+package hilbert
+
+import "math"
+
+// LatLngToD maps a WGS84 latitude and longitude, in degrees, to the
+// distance along the curve of the cell which contains it.
+//
+// The sphere is projected onto the square with the equirectangular
+// (plate carrée) projection, so that longitude -180 is the left-hand
+// edge of the square, longitude 180 the right-hand edge, latitude -90
+// the bottom edge and latitude 90 the top edge. Each cell therefore
+// spans 360/N() degrees of longitude and 180/N() degrees of latitude.
+// Cells include their western and southern edges, except that the cells
+// along the top of the square also include the north pole.
+//
+// Longitudes outside [-180, 180) are wrapped around the antimeridian,
+// and latitudes outside [-90, 90] are clamped to the nearest pole, so
+// every finite input maps to a valid cell.
+//
+// The complementary method DToLatLng returns the center of a cell.
+func (c Curve) LatLngToD(lat, lng float64) uint64 {
+	n := float64(c.N())
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	lat = math.Max(-90, math.Min(90, lat))
+	x := math.Min(math.Floor(lng/360*n), n-1)
+	y := math.Min(math.Floor((lat+90)/180*n), n-1)
+	return c.XYToD(uint32(x), uint32(y))
+}
+
+// DToLatLng returns the latitude and longitude, in degrees, of the
+// center of the cell at distance d along the curve, using the same
+// projection as LatLngToD. The distance d must be in the range
+// [0, MaxD()].
+func (c Curve) DToLatLng(d uint64) (lat, lng float64) {
+	n := float64(c.N())
+	x, y := c.DToXY(d)
+	lat = (float64(y)+0.5)/n*180 - 90
+	lng = (float64(x)+0.5)/n*360 - 180
+	return
+}
@@ -0,0 +1,47 @@
+package hilbert
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLatLngToD(t *testing.T) {
+	c, _ := New(1)
+	tests := []struct {
+		lat, lng float64
+		d        uint64
+	}{
+		{-45, -90, 0},
+		{45, -90, 1},
+		{45, 90, 2},
+		{-45, 90, 3},
+		{0, 0, 2},
+		{-0.001, -0.001, 0},
+		{90, 179.999, 2},
+		{-90, -180, 0},
+		// Longitudes wrap and latitudes clamp.
+		{45, 270, 1},
+		{-45, -270, 3},
+		{100, 180, 1},
+		{-100, 540, 0},
+	}
+	for _, tt := range tests {
+		if d := c.LatLngToD(tt.lat, tt.lng); d != tt.d {
+			t.Errorf("LatLngToD(%v, %v) = %d, want %d", tt.lat, tt.lng, d, tt.d)
+		}
+	}
+}
+
+func TestDToLatLng(t *testing.T) {
+	c, _ := New(1)
+	if lat, lng := c.DToLatLng(2); lat != 45 || lng != 90 {
+		t.Errorf("DToLatLng(2) = (%v, %v), want (45, 90)", lat, lng)
+	}
+	c, _ = New(20)
+	for _, p := range [][2]float64{{51.4779, -0.0015}, {-33.8568, 151.2153}, {89.9999, -179.9999}} {
+		lat, lng := c.DToLatLng(c.LatLngToD(p[0], p[1]))
+		if math.Abs(lat-p[0]) > 90.0/(1<<20) || math.Abs(lng-p[1]) > 180.0/(1<<20) {
+			t.Errorf("DToLatLng(LatLngToD(%v)) = (%v, %v), not in the cell", p, lat, lng)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package hilbert
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// lutMaxN is the largest cell count for which the table-driven
+// conversions are used instead of the iterative ones.
+const lutMaxN = 256
+
+// The table-driven conversions process four bits of each coordinate
+// (one byte of distance) at a time with a finite state machine. The
+// state records the transformation which the iterative algorithm would
+// by then have applied to the remaining low bits of the coordinates:
+// bit 0 of the state is set if x and y are swapped, and bit 1 if both
+// are complemented. These transformations commute, so composing two of
+// them is an XOR of their states.
+const (
+	stateSwap       = 1
+	stateComplement = 2
+)
+
+var (
+	lutOnce sync.Once
+
+	// xyToDTable is indexed by state<<8 | x<<4 | y, where x and y are
+	// four-bit chunks of the coordinates, and holds the corresponding
+	// byte of distance in its low byte and the next state in the byte
+	// above.
+	xyToDTable [4 << 8]uint16
+
+	// dToXYTable is indexed by state<<8 | d, where d is a byte of
+	// distance, and holds the corresponding four-bit chunks of x and y
+	// in its low and second-lowest nibbles and the next state in the
+	// byte above.
+	dToXYTable [4 << 8]uint16
+)
+
+// initTables builds the state transition tables. It is only run once,
+// the first time a table-driven conversion is made.
+func initTables() {
+	for state := uint16(0); state < 4; state++ {
+		for xy := uint16(0); xy < 256; xy++ {
+			s, d := state, uint16(0)
+			for b := 3; b >= 0; b-- {
+				rx, ry := transformBits(s, xy>>(4+b)&1, xy>>b&1)
+				q := (3 * rx) ^ ry
+				d = d<<2 | q
+				s ^= rotState(rx, ry)
+			}
+			xyToDTable[state<<8|xy] = s<<8 | d
+		}
+		for d := uint16(0); d < 256; d++ {
+			s, x, y := state, uint16(0), uint16(0)
+			for b := 3; b >= 0; b-- {
+				q := d >> (2 * b) & 3
+				rx := 1 & (q / 2)
+				ry := 1 & (q ^ rx)
+				bx, by := transformBits(s, rx, ry) // Each state is its own inverse
+				x, y = x<<1|bx, y<<1|by
+				s ^= rotState(rx, ry)
+			}
+			dToXYTable[state<<8|d] = s<<8 | y<<4 | x
+		}
+	}
+}
+
+// transformBits applies the transformation recorded in state to a pair
+// of coordinate bits.
+func transformBits(state, bx, by uint16) (uint16, uint16) {
+	if state&stateComplement != 0 {
+		bx, by = bx^1, by^1
+	}
+	if state&stateSwap != 0 {
+		bx, by = by, bx
+	}
+	return bx, by
+}
+
+// rotState returns the state of the transformation rot makes for the
+// quadrant (rx, ry).
+func rotState(rx, ry uint16) uint16 {
+	if ry != 0 {
+		return 0
+	}
+	if rx == 1 {
+		return stateSwap | stateComplement
+	}
+	return stateSwap
+}
+
+// lutStart returns the number of four-bit chunks needed for a curve
+// with 2 <= n <= lutMaxN cells per side, and the state in which to
+// start. The coordinates are padded with leading zero bits to a whole
+// number of chunks, and each padding bit swaps x and y just as the
+// first quadrant of a larger curve does.
+func lutStart(n uint64) (chunks int, state uint16) {
+	lutOnce.Do(initTables)
+	order := bits.Len64(n) - 1
+	chunks = (order + 3) / 4
+	if (4*chunks-order)%2 == 1 {
+		state = stateSwap
+	}
+	return
+}
+
+// lutXYToD is the table-driven equivalent of XYToD64 for cell counts n
+// with 2 <= n <= lutMaxN.
+func lutXYToD(n uint64, x, y uint32) uint64 {
+	chunks, state := lutStart(n)
+	return chunksXYToD(chunks, state, x, y)
+}
+
+// chunksXYToD converts (x, y) to a distance chunk by chunk, starting
+// from the chunk count and state returned by lutStart.
+func chunksXYToD(chunks int, state uint16, x, y uint32) (d uint64) {
+	for c := chunks - 1; c >= 0; c-- {
+		xy := uint16(x>>(4*c)&15)<<4 | uint16(y>>(4*c)&15)
+		e := xyToDTable[state<<8|xy]
+		d = d<<8 | uint64(e&0xff)
+		state = e >> 8
+	}
+	return
+}
+
+// lutDToXY is the table-driven equivalent of DToXY64 for cell counts n
+// with 2 <= n <= lutMaxN.
+func lutDToXY(n, d uint64) (x, y uint32) {
+	chunks, state := lutStart(n)
+	return chunksDToXY(chunks, state, d)
+}
+
+// chunksDToXY converts d to a cell position chunk by chunk, starting
+// from the chunk count and state returned by lutStart.
+func chunksDToXY(chunks int, state uint16, d uint64) (x, y uint32) {
+	for c := chunks - 1; c >= 0; c-- {
+		e := dToXYTable[state<<8|uint16(d>>(8*c)&0xff)]
+		x = x<<4 | uint32(e&15)
+		y = y<<4 | uint32(e>>4&15)
+		state = e >> 8
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package hilbert
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLUTMatchesLoop(t *testing.T) {
+	// The tables give the same curve as the bit-by-bit conversion, which
+	// XYToDOf performs.
+	for n := uint64(2); n <= lutMaxN; n *= 2 {
+		for x := range uint32(n) {
+			for y := range uint32(n) {
+				d := lutXYToD(n, x, y)
+				if want := XYToDOf(n, x, y); d != want {
+					t.Fatalf("lutXYToD(%d, %d, %d) = %d, want %d", n, x, y, d, want)
+				}
+				if x2, y2 := lutDToXY(n, d); x2 != x || y2 != y {
+					t.Fatalf("lutDToXY(%d, %d) = (%d, %d), want (%d, %d)", n, d, x2, y2, x, y)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkXYToD64(b *testing.B) {
+	for _, n := range []uint64{256, 1 << 32} {
+		b.Run(fmt.Sprint("n=", n), func(b *testing.B) {
+			for i := range b.N {
+				XYToD64(n, uint32(i)&uint32(n-1), uint32(i>>3)&uint32(n-1))
+			}
+		})
+	}
+}
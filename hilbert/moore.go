@@ -0,0 +1,72 @@
+package hilbert
+
+// MooreXYToD converts a two-dimensional cell position with coordinates
+// (x, y) to a one-dimensional distance (d) along a discrete Moore
+// curve, the closed-loop variant of the Hilbert curve. The curve is
+// constructed by dividing a square into n X n cells.
+//
+// The Moore curve is made of four Hilbert curves of n/2 X n/2 cells,
+// one through each quadrant of the square, joined so that the curve
+// starts at cell (n/2-1, 0) and ends at the adjacent cell (n/2, 0).
+// Consequently, unlike on the Hilbert curve, the cells at distances
+// n^2-1 and 0 are neighbors and the curve has locality across the
+// wraparound.
+//
+// The cell count n must be a power of 2, and the coordinates x and y
+// must range from (0, 0) representing the cell at the lower left-hand
+// corner of the square, to (n-1, n-1) representing the cell at the
+// upper right-hand corner of the square. The return value d is a number
+// in the range [0, n^2-1].
+//
+// The complementary function MooreDToXY performs the inverse mapping.
+func MooreXYToD(n, x, y int) int {
+	if n < 2 {
+		return 0
+	}
+	h := n / 2
+	lx, ly := x%h, y%h
+	var q, hx, hy int
+	if x < h {
+		q = 0
+		if y >= h {
+			q = 1
+		}
+		hx, hy = ly, h-1-lx
+	} else {
+		q = 3
+		if y >= h {
+			q = 2
+		}
+		hx, hy = h-1-ly, lx
+	}
+	return q*h*h + XYToD(h, hx, hy)
+}
+
+// MooreDToXY converts a one-dimensional distance (d) along a discrete
+// Moore curve to a two-dimensional cell position with coordinates
+// (x, y).
+//
+// The cell count n must be a power of 2, and the distance d must range
+// from 0, representing cell (n/2-1, 0), to n^2-1, representing cell
+// (n/2, 0).
+//
+// The complementary function MooreXYToD performs the inverse mapping.
+func MooreDToXY(n, d int) (x, y int) {
+	if n < 2 {
+		return 0, 0
+	}
+	h := n / 2
+	q := d / (h * h)
+	hx, hy := DToXY(h, d%(h*h))
+	switch q {
+	case 0:
+		x, y = h-1-hy, hx
+	case 1:
+		x, y = h-1-hy, h+hx
+	case 2:
+		x, y = h+hy, h+h-1-hx
+	default:
+		x, y = h+hy, h-1-hx
+	}
+	return
+}
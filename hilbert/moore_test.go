@@ -0,0 +1,49 @@
+package hilbert
+
+import "testing"
+
+func TestMooreDToXY(t *testing.T) {
+	tests := []struct {
+		n    int
+		want [][2]int
+	}{
+		{2, [][2]int{{0, 0}, {0, 1}, {1, 1}, {1, 0}}},
+		{4, [][2]int{
+			{1, 0}, {0, 0}, {0, 1}, {1, 1}, {1, 2}, {0, 2}, {0, 3}, {1, 3},
+			{2, 3}, {3, 3}, {3, 2}, {2, 2}, {2, 1}, {3, 1}, {3, 0}, {2, 0},
+		}},
+	}
+	for _, tt := range tests {
+		for d, p := range tt.want {
+			if x, y := MooreDToXY(tt.n, d); x != p[0] || y != p[1] {
+				t.Errorf("MooreDToXY(%d, %d) = (%d, %d), want %v", tt.n, d, x, y, p)
+			}
+			if d2 := MooreXYToD(tt.n, p[0], p[1]); d2 != d {
+				t.Errorf("MooreXYToD(%d, %v) = %d, want %d", tt.n, p, d2, d)
+			}
+		}
+	}
+}
+
+func TestMooreClosed(t *testing.T) {
+	// Every cell is visited once, and consecutive cells, including the
+	// last and the first, are adjacent.
+	for n := 2; n <= 64; n *= 2 {
+		seen := make([]bool, n*n)
+		px, py := MooreDToXY(n, n*n-1)
+		for d := range n * n {
+			x, y := MooreDToXY(n, d)
+			if seen[y*n+x] {
+				t.Fatalf("MooreDToXY(%d, %d) = (%d, %d), visited twice", n, d, x, y)
+			}
+			seen[y*n+x] = true
+			if abs(x-px)+abs(y-py) != 1 {
+				t.Fatalf("MooreDToXY(%d, %d) = (%d, %d), not adjacent to (%d, %d)", n, d, x, y, px, py)
+			}
+			if d2 := MooreXYToD(n, x, y); d2 != d {
+				t.Fatalf("MooreXYToD(MooreDToXY(%d, %d)) = %d", n, d, d2)
+			}
+			px, py = x, y
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package hilbert
+
+// Connectivity selects which cells Neighbors considers to be neighbors.
+type Connectivity int
+
+const (
+	// FourConnected selects the up to four cells which share an edge
+	// with a cell.
+	FourConnected Connectivity = 4
+
+	// EightConnected selects the up to eight cells which share an edge
+	// or a corner with a cell.
+	EightConnected Connectivity = 8
+)
+
+// Offsets of the neighboring cells, counter-clockwise from east. The
+// even entries share an edge with the center cell, the odd entries a
+// corner.
+var neighborOffsets = [8][2]int{
+	{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1},
+}
+
+// Neighbors returns the distances along a discrete Hilbert curve,
+// constructed by dividing a square into n X n cells, of the cells
+// neighboring the cell at distance d. The cell count n must be a power
+// of 2 and the distance d must be in the range [0, n^2-1].
+//
+// If c is EightConnected, the cells sharing an edge or a corner with
+// cell d are returned; otherwise only the cells sharing an edge are.
+// The neighbors are returned in counter-clockwise order starting with
+// the cell to the east (x+1, y). Cells that would lie outside the
+// square are omitted, so a cell on an edge of the square has fewer
+// neighbors and a curve with a single cell has none.
+func Neighbors(n, d int, c Connectivity) []int {
+	x, y := DToXY(n, d)
+	step := 2
+	if c == EightConnected {
+		step = 1
+	}
+	neighbors := make([]int, 0, 8/step)
+	for i := 0; i < len(neighborOffsets); i += step {
+		nx, ny := x+neighborOffsets[i][0], y+neighborOffsets[i][1]
+		if nx < 0 || nx >= n || ny < 0 || ny >= n {
+			continue
+		}
+		neighbors = append(neighbors, XYToD(n, nx, ny))
+	}
+	return neighbors
+}
@@ -0,0 +1,54 @@
+package hilbert
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNeighbors(t *testing.T) {
+	tests := []struct {
+		n, d int
+		c    Connectivity
+		want []int
+	}{
+		{4, 0, FourConnected, []int{1, 3}},
+		{4, 0, EightConnected, []int{1, 2, 3}},
+		{4, 2, FourConnected, []int{13, 7, 3, 1}},
+		{4, 2, EightConnected, []int{13, 8, 7, 4, 3, 0, 1, 14}},
+		{4, 15, FourConnected, []int{12, 14}},
+		{2, 1, EightConnected, []int{2, 0, 3}},
+		{1, 0, EightConnected, []int{}},
+	}
+	for _, tt := range tests {
+		if got := Neighbors(tt.n, tt.d, tt.c); !slices.Equal(got, tt.want) {
+			t.Errorf("Neighbors(%d, %d, %d) = %v, want %v", tt.n, tt.d, tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestNeighborsAdjacent(t *testing.T) {
+	const n = 16
+	for d := range n * n {
+		x, y := DToXY(n, d)
+		for _, c := range []Connectivity{FourConnected, EightConnected} {
+			want := 0
+			for _, o := range neighborOffsets {
+				if c == EightConnected || o[0] == 0 || o[1] == 0 {
+					if nx, ny := x+o[0], y+o[1]; nx >= 0 && nx < n && ny >= 0 && ny < n {
+						want++
+					}
+				}
+			}
+			ns := Neighbors(n, d, c)
+			if len(ns) != want {
+				t.Fatalf("Neighbors(%d, %d, %d) = %d cells, want %d", n, d, c, len(ns), want)
+			}
+			for _, e := range ns {
+				ex, ey := DToXY(n, e)
+				if dx, dy := abs(ex-x), abs(ey-y); e == d || dx > 1 || dy > 1 || c == FourConnected && dx+dy != 1 {
+					t.Fatalf("Neighbors(%d, %d, %d) has %d, at (%d, %d), not next to (%d, %d)", n, d, c, e, ex, ey, x, y)
+				}
+			}
+		}
+	}
+}
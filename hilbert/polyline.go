@@ -0,0 +1,26 @@
+package hilbert
+
+// Polyline returns the vertices of a polyline which traces the discrete
+// Hilbert curve constructed by dividing a square into n X n cells. The
+// cell count n must be a power of 2.
+//
+// The polyline has n^2 vertices, one at the center of each cell, in
+// order of increasing distance along the curve. The coordinates are
+// scaled so the square has sides of length size, with the origin at its
+// lower left-hand corner: vertex d is at
+//
+//	((x+0.5)*size/n, (y+0.5)*size/n)
+//
+// where (x, y) = DToXY(n, d). Passing size = n gives coordinates in
+// cell units. Because y increases upward, drawing the polyline with a
+// library whose y axis points down, such as SVG or HTML canvas, shows
+// the curve mirrored unless each y is replaced with size-y.
+func Polyline(n int, size float64) [][2]float64 {
+	scale := size / float64(n)
+	vertices := make([][2]float64, n*n)
+	for d := range vertices {
+		x, y := DToXY(n, d)
+		vertices[d] = [2]float64{(float64(x) + 0.5) * scale, (float64(y) + 0.5) * scale}
+	}
+	return vertices
+}
@@ -0,0 +1,37 @@
+package hilbert
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPolyline(t *testing.T) {
+	tests := []struct {
+		n    int
+		size float64
+		want [][2]float64
+	}{
+		{1, 1, [][2]float64{{0.5, 0.5}}},
+		{2, 2, [][2]float64{{0.5, 0.5}, {0.5, 1.5}, {1.5, 1.5}, {1.5, 0.5}}},
+		{2, 100, [][2]float64{{25, 25}, {25, 75}, {75, 75}, {75, 25}}},
+	}
+	for _, tt := range tests {
+		if got := Polyline(tt.n, tt.size); !slices.Equal(got, tt.want) {
+			t.Errorf("Polyline(%d, %v) = %v, want %v", tt.n, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestPolylineSteps(t *testing.T) {
+	// Consecutive vertices are one cell apart.
+	vs := Polyline(64, 64)
+	if len(vs) != 64*64 {
+		t.Fatalf("Polyline(64, 64) has %d vertices, want %d", len(vs), 64*64)
+	}
+	for i := 1; i < len(vs); i++ {
+		dx, dy := vs[i][0]-vs[i-1][0], vs[i][1]-vs[i-1][1]
+		if dx*dx+dy*dy != 1 {
+			t.Fatalf("vertices %d %v and %d %v not one cell apart", i-1, vs[i-1], i, vs[i])
+		}
+	}
+}
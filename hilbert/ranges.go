@@ -0,0 +1,73 @@
+package hilbert
+
+import (
+	"sort"
+
+	"github.com/gogama/geospat/internal/keyrange"
+)
+
+// Rect is an axis-aligned rectangle of cells. It contains every cell
+// (x, y) with XMin <= x <= XMax and YMin <= y <= YMax.
+type Rect struct {
+	XMin, YMin, XMax, YMax int
+}
+
+// Range is an inclusive interval [Lo, Hi] of distances along a Hilbert
+// curve.
+type Range struct {
+	Lo, Hi int
+}
+
+// QueryRanges decomposes a rectangle of cells into the sorted,
+// non-overlapping, non-adjacent ranges of Hilbert distances that cover
+// it on a curve constructed by dividing a square into n X n cells. The
+// cell count n must be a power of 2. The parts of r which lie outside
+// the square are ignored, and if r does not intersect the square at all
+// the return value is nil.
+//
+// If maxRanges is zero or negative, the decomposition is exact: every
+// distance in the returned ranges belongs to a cell of r. Otherwise at
+// most maxRanges ranges are returned, obtained by merging neighboring
+// ranges across the smallest gaps, so the ranges still cover every cell
+// of r but may also cover some cells outside it. This trades precision
+// for fewer, larger ranges, which is usually desirable when each range
+// becomes a separate scan of a Hilbert-keyed database column.
+func QueryRanges(n int, r Rect, maxRanges int) []Range {
+	r.XMin, r.YMin = max(r.XMin, 0), max(r.YMin, 0)
+	r.XMax, r.YMax = min(r.XMax, n-1), min(r.YMax, n-1)
+	if r.XMin > r.XMax || r.YMin > r.YMax {
+		return nil
+	}
+	var ranges []Range
+	var visit func(x0, y0, s int)
+	visit = func(x0, y0, s int) {
+		if x0 > r.XMax || x0+s-1 < r.XMin || y0 > r.YMax || y0+s-1 < r.YMin {
+			return
+		}
+		if x0 >= r.XMin && x0+s-1 <= r.XMax && y0 >= r.YMin && y0+s-1 <= r.YMax {
+			lo := XYToD(n, x0, y0) &^ (s*s - 1)
+			ranges = append(ranges, Range{lo, lo + s*s - 1})
+			return
+		}
+		h := s / 2
+		visit(x0, y0, h)
+		visit(x0+h, y0, h)
+		visit(x0, y0+h, h)
+		visit(x0+h, y0+h, h)
+	}
+	visit(0, 0, n)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Lo < ranges[j].Lo })
+	merged := ranges[:1]
+	for _, rg := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if rg.Lo == last.Hi+1 {
+			last.Hi = rg.Hi
+		} else {
+			merged = append(merged, rg)
+		}
+	}
+	if maxRanges > 0 && len(merged) > maxRanges {
+		merged = keyrange.Coarsen(merged, maxRanges)
+	}
+	return merged
+}
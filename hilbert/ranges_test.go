@@ -0,0 +1,60 @@
+package hilbert
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestQueryRanges(t *testing.T) {
+	tests := []struct {
+		n         int
+		r         Rect
+		maxRanges int
+		want      []Range
+	}{
+		{4, Rect{0, 0, 3, 3}, 0, []Range{{0, 15}}},
+		{4, Rect{0, 0, 1, 1}, 0, []Range{{0, 3}}},
+		{4, Rect{0, 0, 3, 0}, 0, []Range{{0, 1}, {14, 15}}},
+		{4, Rect{0, 0, 3, 0}, 1, []Range{{0, 15}}},
+		{4, Rect{1, 1, 2, 2}, 0, []Range{{2, 2}, {7, 8}, {13, 13}}},
+		{4, Rect{1, 1, 2, 2}, 2, []Range{{2, 8}, {13, 13}}},
+		{4, Rect{-5, -5, 0, 0}, 0, []Range{{0, 0}}},
+		{4, Rect{4, 0, 9, 9}, 0, nil},
+		{4, Rect{2, 2, 1, 1}, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := QueryRanges(tt.n, tt.r, tt.maxRanges); !slices.Equal(got, tt.want) {
+			t.Errorf("QueryRanges(%d, %v, %d) = %v, want %v", tt.n, tt.r, tt.maxRanges, got, tt.want)
+		}
+	}
+}
+
+func TestQueryRangesCover(t *testing.T) {
+	// Exact ranges hold the cells of the rectangle and no others, and
+	// coarsened ranges hold at least them.
+	const n = 32
+	r := rand.New(rand.NewSource(5))
+	for range 200 {
+		x0, y0 := r.Intn(n), r.Intn(n)
+		q := Rect{x0, y0, x0 + r.Intn(n-x0), y0 + r.Intn(n-y0)}
+		maxRanges := r.Intn(5)
+		ranges := QueryRanges(n, q, maxRanges)
+		if maxRanges > 0 && len(ranges) > maxRanges {
+			t.Fatalf("QueryRanges(%v, %d) = %d ranges", q, maxRanges, len(ranges))
+		}
+		for i := 1; i < len(ranges); i++ {
+			if ranges[i].Lo <= ranges[i-1].Hi+1 {
+				t.Fatalf("QueryRanges(%v, %d) = %v, not sorted and apart", q, maxRanges, ranges)
+			}
+		}
+		for d := range n * n {
+			x, y := DToXY(n, d)
+			in := q.XMin <= x && x <= q.XMax && q.YMin <= y && y <= q.YMax
+			covered := slices.ContainsFunc(ranges, func(rg Range) bool { return rg.Lo <= d && d <= rg.Hi })
+			if in && !covered || maxRanges == 0 && covered && !in {
+				t.Fatalf("QueryRanges(%v, %d): cell (%d, %d) in %t, covered %t", q, maxRanges, x, y, in, covered)
+			}
+		}
+	}
+}
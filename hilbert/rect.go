@@ -0,0 +1,40 @@
+package hilbert
+
+// CompactXYToD converts a cell position with coordinates (x, y) to a
+// distance (d) along a compact Hilbert curve through a rectangle of
+// 2^mx X 2^my cells. Unlike padding the rectangle out to a square, the
+// compact curve wastes none of the key space: the return value d is in
+// the range [0, 2^(mx+my)-1].
+//
+// The bit counts mx and my must be non-negative and their sum must not
+// exceed 64. The coordinates must range from (0, 0), representing the
+// cell at the lower left-hand corner of the rectangle, to
+// (2^mx-1, 2^my-1), representing the cell at the upper right-hand
+// corner.
+//
+// Cells are visited in the same order as the Hilbert curve through the
+// enclosing square visits them, and when mx equals my the result is
+// identical to XYToD64. This is the two-dimensional case of
+// CompactIndex.
+//
+// The complementary function CompactDToXY performs the inverse mapping.
+func CompactXYToD(mx, my int, x, y uint32) uint64 {
+	m := [2]int{mx, my}
+	p := [2]uint64{uint64(x), uint64(y)}
+	return CompactIndex(m[:], p[:])
+}
+
+// CompactDToXY converts a distance (d) along a compact Hilbert curve
+// through a rectangle of 2^mx X 2^my cells to a cell position with
+// coordinates (x, y).
+//
+// The bit counts mx and my must be non-negative and their sum must not
+// exceed 64. The distance d must be in the range [0, 2^(mx+my)-1].
+//
+// The complementary function CompactXYToD performs the inverse mapping.
+func CompactDToXY(mx, my int, d uint64) (x, y uint32) {
+	m := [2]int{mx, my}
+	var p [2]uint64
+	CompactPoint(m[:], d, p[:])
+	return uint32(p[0]), uint32(p[1])
+}
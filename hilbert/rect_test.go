@@ -0,0 +1,53 @@
+package hilbert
+
+import "testing"
+
+func TestCompactXYToD(t *testing.T) {
+	tests := []struct {
+		mx, my int
+		x, y   uint32
+		d      uint64
+	}{
+		{3, 1, 0, 0, 0},
+		{3, 1, 0, 1, 1},
+		{3, 1, 1, 1, 2},
+		{3, 1, 1, 0, 3},
+		{3, 1, 2, 0, 4},
+		{3, 1, 7, 0, 15},
+		{0, 0, 0, 0, 0},
+		{2, 2, 3, 0, 15},
+		{32, 32, 1<<32 - 1, 0, 1<<64 - 1},
+	}
+	for _, tt := range tests {
+		if d := CompactXYToD(tt.mx, tt.my, tt.x, tt.y); d != tt.d {
+			t.Errorf("CompactXYToD(%d, %d, %d, %d) = %d, want %d", tt.mx, tt.my, tt.x, tt.y, d, tt.d)
+		}
+		if x, y := CompactDToXY(tt.mx, tt.my, tt.d); x != tt.x || y != tt.y {
+			t.Errorf("CompactDToXY(%d, %d, %d) = (%d, %d), want (%d, %d)", tt.mx, tt.my, tt.d, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestCompactDToXYRectangle(t *testing.T) {
+	// Every distance is a cell of the rectangle, visited once.
+	for _, m := range [][2]int{{4, 2}, {1, 5}, {3, 3}, {6, 0}} {
+		mx, my := m[0], m[1]
+		seen := map[[2]uint32]bool{}
+		for d := range uint64(1) << (mx + my) {
+			x, y := CompactDToXY(mx, my, d)
+			if x >= 1<<mx || y >= 1<<my || seen[[2]uint32{x, y}] {
+				t.Fatalf("CompactDToXY(%d, %d, %d) = (%d, %d), outside or visited twice", mx, my, d, x, y)
+			}
+			seen[[2]uint32{x, y}] = true
+			if d2 := CompactXYToD(mx, my, x, y); d2 != d {
+				t.Fatalf("CompactXYToD(CompactDToXY(%d, %d, %d)) = %d", mx, my, d, d2)
+			}
+		}
+	}
+}
+
+func TestCompactXYToDAllocs(t *testing.T) {
+	if a := testing.AllocsPerRun(10, func() { CompactDToXY(12, 10, CompactXYToD(12, 10, 5, 6)) }); a != 0 {
+		t.Errorf("CompactXYToD and CompactDToXY allocate %v times, want 0", a)
+	}
+}
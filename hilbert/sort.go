@@ -0,0 +1,39 @@
+package hilbert
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Sort sorts s in place in order of increasing distance, along the
+// curve c, of the cell whose position xy returns for each element. The
+// coordinates returned by xy must be in the range [0, c.N()-1]. The
+// sort is stable, so elements in the same cell keep their original
+// relative order.
+//
+// xy is called exactly once per element, so it may be relatively
+// expensive, for example scaling floating-point coordinates onto the
+// grid.
+func Sort[S ~[]E, E any](c Curve, s S, xy func(E) (x, y uint32)) {
+	type keyed struct {
+		d uint64
+		i int
+	}
+	keys := make([]keyed, len(s))
+	for i := range s {
+		keys[i] = keyed{c.XYToD(xy(s[i])), i}
+	}
+	slices.SortStableFunc(keys, func(a, b keyed) int { return cmp.Compare(a.d, b.d) })
+	sorted := make(S, len(s))
+	for j, k := range keys {
+		sorted[j] = s[k.i]
+	}
+	copy(s, sorted)
+}
+
+// SortPoints sorts ps in place in order of increasing distance along
+// the curve c. It is equivalent to calling Sort with an accessor that
+// returns the X and Y fields of each point.
+func SortPoints(c Curve, ps []Point) {
+	Sort(c, ps, func(p Point) (uint32, uint32) { return p.X, p.Y })
+}
@@ -0,0 +1,43 @@
+package hilbert
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSortPoints(t *testing.T) {
+	c, _ := New(2)
+	ps := []Point{{3, 0}, {0, 0}, {3, 3}, {1, 0}, {0, 3}}
+	SortPoints(c, ps)
+	if want := []Point{{0, 0}, {1, 0}, {0, 3}, {3, 3}, {3, 0}}; !slices.Equal(ps, want) {
+		t.Errorf("SortPoints = %v, want %v", ps, want)
+	}
+}
+
+func TestSortStable(t *testing.T) {
+	type item struct {
+		x, y uint32
+		i    int
+	}
+	c, _ := New(3)
+	r := rand.New(rand.NewSource(7))
+	s := make([]item, 500)
+	for i := range s {
+		s[i] = item{uint32(r.Intn(8)), uint32(r.Intn(8)), i}
+	}
+	calls := 0
+	Sort(c, s, func(e item) (uint32, uint32) {
+		calls++
+		return e.x, e.y
+	})
+	if calls != len(s) {
+		t.Errorf("Sort called xy %d times, want %d", calls, len(s))
+	}
+	for i := 1; i < len(s); i++ {
+		a, b := c.XYToD(s[i-1].x, s[i-1].y), c.XYToD(s[i].x, s[i].y)
+		if a > b || a == b && s[i-1].i > s[i].i {
+			t.Fatalf("Sort: %v before %v", s[i-1], s[i])
+		}
+	}
+}
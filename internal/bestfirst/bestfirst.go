@@ -0,0 +1,76 @@
+// Package bestfirst holds the queue of the searches of the nearest items
+// shared by the spatial index packages, which search their indexes best
+// first, as Hjaltason and Samet describe in "Distance Browsing in
+// Spatial Databases", ACM TODS 1999.
+//
+// A search keeps the nodes of an index and the items which it has
+// reached in a queue by their distances, or the lower bounds of the
+// distances to the items of the nodes, and divides the nearest node into
+// the nodes and items which it holds until an item is the nearest, so
+// that it looks at no node farther than the kth item.
+package bestfirst
+
+import (
+	"container/heap"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Queue is a queue of the nodes of type N of an index and of its items
+// of type T, by distance. The zero value is an empty queue.
+type Queue[N, T any] struct {
+	h entries[N, T]
+}
+
+// entry is a node or an item of a queue.
+type entry[N, T any] struct {
+	dist float64
+	node N
+	// item is the item, with its distance, if isItem.
+	item   geom.Neighbor[T]
+	isItem bool
+}
+
+// PushNode pushes a node with the lower bound of the distances to its
+// items.
+func (q *Queue[N, T]) PushNode(bound float64, n N) {
+	heap.Push(&q.h, entry[N, T]{dist: bound, node: n})
+}
+
+// PushItem pushes an item with its distance.
+func (q *Queue[N, T]) PushItem(item geom.Neighbor[T]) {
+	heap.Push(&q.h, entry[N, T]{dist: item.Distance, item: item, isItem: true})
+}
+
+// Nearest returns the k items nearest to the point of the search, or all
+// of them if there are fewer, in order of their distances, popping the
+// nearest node or item of the queue until it has popped k items, and
+// calling expand with each node which it pops, to push the nodes and
+// items which the node holds.
+func (q *Queue[N, T]) Nearest(k int, expand func(N)) []geom.Neighbor[T] {
+	var nn []geom.Neighbor[T]
+	for len(q.h) > 0 && len(nn) < k {
+		e := heap.Pop(&q.h).(entry[N, T])
+		if e.isItem {
+			nn = append(nn, e.item)
+		} else {
+			expand(e.node)
+		}
+	}
+	return nn
+}
+
+// entries is a min-heap of entries by distance, implementing
+// heap.Interface.
+type entries[N, T any] []entry[N, T]
+
+func (h entries[N, T]) Len() int           { return len(h) }
+func (h entries[N, T]) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h entries[N, T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *entries[N, T]) Push(x any)        { *h = append(*h, x.(entry[N, T])) }
+func (h *entries[N, T]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
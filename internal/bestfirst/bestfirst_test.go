@@ -0,0 +1,100 @@
+package bestfirst
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// node is a node of a binary tree of the sorted values [lo, hi).
+type node struct {
+	lo, hi int
+}
+
+// search returns the k values nearest to x of a tree of sorted values,
+// and the number of nodes which it expanded.
+func search(values []float64, x float64, k int) ([]geom.Neighbor[int], int) {
+	bound := func(n node) float64 {
+		return max(0, values[n.lo]-x, x-values[n.hi-1])
+	}
+	var q Queue[node, int]
+	q.PushNode(bound(node{0, len(values)}), node{0, len(values)})
+	expanded := 0
+	nn := q.Nearest(k, func(n node) {
+		expanded++
+		if n.hi-n.lo == 1 {
+			q.PushItem(geom.Neighbor[int]{Item: n.lo, Distance: math.Abs(values[n.lo] - x)})
+			return
+		}
+		mid := (n.lo + n.hi) / 2
+		q.PushNode(bound(node{n.lo, mid}), node{n.lo, mid})
+		q.PushNode(bound(node{mid, n.hi}), node{mid, n.hi})
+	})
+	return nn, expanded
+}
+
+func TestNearest(t *testing.T) {
+	values := []float64{0, 1, 3, 6, 10, 15, 22, 28}
+	tests := []struct {
+		x    float64
+		k    int
+		want []int
+	}{
+		{7, 3, []int{3, 4, 2}},
+		{-5, 2, []int{0, 1}},
+		{100, 1, []int{7}},
+		{12, 20, []int{4, 5, 3, 2, 6, 1, 0, 7}},
+		{12, 0, nil},
+	}
+	for _, tt := range tests {
+		nn, _ := search(values, tt.x, tt.k)
+		var got []int
+		for _, n := range nn {
+			got = append(got, n.Item)
+			if n.Distance != math.Abs(values[n.Item]-tt.x) {
+				t.Errorf("distance of %d is %v", n.Item, n.Distance)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Nearest(%d) to %v = %v, want %v", tt.k, tt.x, got, tt.want)
+		}
+	}
+	var q Queue[node, int]
+	if nn := q.Nearest(5, func(node) { t.Error("empty queue expanded a node") }); nn != nil {
+		t.Errorf("Nearest of an empty queue = %v", nn)
+	}
+}
+
+func TestNearestRandom(t *testing.T) {
+	// The nearest are those of a sort by distance, found by expanding
+	// few of the nodes of the tree.
+	r := rand.New(rand.NewSource(100))
+	values := make([]float64, 1024)
+	for i := range values {
+		values[i] = r.Float64() * 1000
+	}
+	slices.Sort(values)
+	for range 100 {
+		x := r.Float64() * 1000
+		k := 1 + r.Intn(10)
+		byDistance := slices.Clone(values)
+		slices.SortFunc(byDistance, func(a, b float64) int {
+			return int(math.Copysign(1, math.Abs(a-x)-math.Abs(b-x)))
+		})
+		nn, expanded := search(values, x, k)
+		if len(nn) != k {
+			t.Fatalf("Nearest(%d) to %v found %d", k, x, len(nn))
+		}
+		for i, n := range nn {
+			if n.Distance != math.Abs(byDistance[i]-x) {
+				t.Fatalf("Nearest(%d) to %v: %dth is at %v, want %v", k, x, i, n.Distance, math.Abs(byDistance[i]-x))
+			}
+		}
+		if expanded > 100 {
+			t.Errorf("Nearest(%d) to %v expanded %d of %d nodes", k, x, expanded, 2*len(values)-1)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package indextest holds the tests of the searches of the nearest items
+// shared by the spatial index packages, which check the items which a
+// search of an index finds against those found by brute force.
+package indextest
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// KNN is a search of an index for the k items nearest to a point,
+// measured by a metric, as the KNN methods of the indexes are.
+type KNN[T any] func(p geom.Point, k int, m geom.Metric) []geom.Neighbor[T]
+
+// Stores are the rectangles of the items of the index searched by
+// CheckStores, by item, one of them being empty.
+var Stores = map[string]geom.Rect{
+	"a": geom.RectOf(geom.Point{X: 1, Y: 1}),
+	"b": geom.RectOf(geom.Point{X: 5, Y: 5}),
+	"c": geom.RectOf(geom.Point{X: -2, Y: 0}),
+	"d": {MinX: 3, MinY: -1, MaxX: 4, MaxY: 1},
+	"e": geom.EmptyRect(),
+}
+
+// CheckStores checks the items found by searches of an index of the
+// Stores, and that a search of an empty index finds nothing.
+func CheckStores(t *testing.T, knn KNN[string], empty KNN[int]) {
+	t.Helper()
+	tests := []struct {
+		p    geom.Point
+		k    int
+		want []string
+	}{
+		{geom.Point{X: 0, Y: 0}, 2, []string{"a", "c"}},
+		{geom.Point{X: 3.5, Y: 0}, 1, []string{"d"}},
+		{geom.Point{X: 6, Y: 6}, 3, []string{"b", "d", "a"}},
+		{geom.Point{X: 0, Y: 0}, 10, []string{"a", "c", "d", "b"}},
+		{geom.Point{X: 0, Y: 0}, 0, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, n := range knn(tt.p, tt.k, geom.Planar) {
+			got = append(got, n.Item)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("KNN(%v, %d) = %q, want %q", tt.p, tt.k, got, tt.want)
+		}
+	}
+	if nn := empty(geom.Point{}, 3, geom.Planar); nn != nil {
+		t.Errorf("KNN of an empty index = %v", nn)
+	}
+}
+
+// CheckKNN checks that the neighbors of a search for the k nearest of
+// items of rectangles, each item being the index of its rectangle, are
+// at the distances of the nearest, found by brute force, in order, and
+// have the rectangles, points and distances of their items.
+func CheckKNN(t *testing.T, nn []geom.Neighbor[int], rects []geom.Rect, p geom.Point, k int, m geom.Metric) {
+	t.Helper()
+	var want []float64
+	for _, r := range rects {
+		if !r.IsEmpty() {
+			want = append(want, m.Distance(p, r.Clamp(p)))
+		}
+	}
+	slices.Sort(want)
+	want = want[:min(k, len(want))]
+	if len(nn) != len(want) {
+		t.Fatalf("KNN(%v, %d) found %d items, want %d", p, k, len(nn), len(want))
+	}
+	for i, n := range nn {
+		if n.Distance != want[i] || n.Rect != rects[n.Item] || n.Point != n.Rect.Clamp(p) || n.Distance != m.Distance(p, n.Point) {
+			t.Fatalf("KNN(%v, %d)[%d] = %+v, want an item at %v", p, k, i, n, want[i])
+		}
+	}
+}
+
+// CheckRandom checks, as CheckKNN does, the searches of an index of
+// items of rectangles for the k nearest to 100 random points, with k
+// from 1 to 20. The points of planar searches are in and around the
+// rectangles, and those of other searches are longitudes and latitudes,
+// the longitudes ranging from -540 to 540 degrees.
+func CheckRandom(t *testing.T, r *rand.Rand, knn KNN[int], rects []geom.Rect, m geom.Metric) {
+	t.Helper()
+	bounds := geom.Rect{MinX: -540, MinY: -90, MaxX: 540, MaxY: 90}
+	if m == geom.Planar {
+		bounds = geom.EmptyRect()
+		for _, rect := range rects {
+			bounds = bounds.Union(rect)
+		}
+		bounds = bounds.Expand(max(bounds.Width(), bounds.Height()) / 10)
+	}
+	for range 100 {
+		p := geom.Point{X: bounds.MinX + r.Float64()*bounds.Width(), Y: bounds.MinY + r.Float64()*bounds.Height()}
+		k := 1 + r.Intn(20)
+		CheckKNN(t, knn(p, k, m), rects, p, k, m)
+	}
+}
+
+// LngLatRects returns n random rectangles of longitudes and latitudes,
+// with sides of up to 2 degrees.
+func LngLatRects(r *rand.Rand, n int) []geom.Rect {
+	rects := make([]geom.Rect, n)
+	for i := range rects {
+		x, y := r.Float64()*358-180, r.Float64()*178-90
+		rects[i] = geom.Rect{MinX: x, MinY: y, MaxX: x + r.Float64()*2, MaxY: y + r.Float64()*2}
+	}
+	return rects
+}
@@ -0,0 +1,41 @@
+// Package keyrange holds the operations on ranges of distances shared by
+// the range decompositions of the space-filling curve packages.
+package keyrange
+
+import "sort"
+
+// Range is an inclusive interval [Lo, Hi] of distances along a curve,
+// the underlying type of the Range type of each curve package.
+type Range struct {
+	Lo, Hi int
+}
+
+// Coarsen reduces a sorted list of non-overlapping ranges to k ranges by
+// closing the len(ranges)-k smallest gaps between neighbors, the first
+// of equal gaps first. It reuses the storage of ranges, and returns
+// them unchanged if there are no more than k.
+func Coarsen[R ~struct{ Lo, Hi int }](ranges []R, k int) []R {
+	if len(ranges) <= k {
+		return ranges
+	}
+	gaps := make([]int, len(ranges)-1)
+	for i := range gaps {
+		gaps[i] = i
+	}
+	gap := func(i int) int { return Range(ranges[i+1]).Lo - Range(ranges[i]).Hi }
+	sort.SliceStable(gaps, func(i, j int) bool { return gap(gaps[i]) < gap(gaps[j]) })
+	closed := make([]bool, len(ranges)-1)
+	for _, i := range gaps[:len(ranges)-k] {
+		closed[i] = true
+	}
+	out := ranges[:1]
+	for i, rg := range ranges[1:] {
+		if closed[i] {
+			last := Range(out[len(out)-1])
+			out[len(out)-1] = R(Range{last.Lo, Range(rg).Hi})
+		} else {
+			out = append(out, rg)
+		}
+	}
+	return out
+}
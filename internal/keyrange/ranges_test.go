@@ -0,0 +1,39 @@
+package keyrange
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCoarsen(t *testing.T) {
+	tests := []struct {
+		ranges []Range
+		k      int
+		want   []Range
+	}{
+		{[]Range{{0, 1}, {3, 4}, {10, 12}}, 3, []Range{{0, 1}, {3, 4}, {10, 12}}},
+		{[]Range{{0, 1}, {3, 4}, {10, 12}}, 5, []Range{{0, 1}, {3, 4}, {10, 12}}},
+		{[]Range{{0, 1}, {3, 4}, {10, 12}}, 2, []Range{{0, 4}, {10, 12}}},
+		{[]Range{{0, 1}, {3, 4}, {10, 12}}, 1, []Range{{0, 12}}},
+		{[]Range{{0, 0}, {8, 8}, {13, 13}, {15, 15}}, 2, []Range{{0, 0}, {8, 15}}},
+		// Of equal gaps, the first is closed first.
+		{[]Range{{2, 2}, {7, 8}, {13, 13}}, 2, []Range{{2, 8}, {13, 13}}},
+	}
+	for _, tt := range tests {
+		in := slices.Clone(tt.ranges)
+		if got := Coarsen(in, tt.k); !slices.Equal(got, tt.want) {
+			t.Errorf("Coarsen(%v, %d) = %v, want %v", tt.ranges, tt.k, got, tt.want)
+		}
+	}
+}
+
+type named struct {
+	Lo, Hi int
+}
+
+func TestCoarsenNamed(t *testing.T) {
+	got := Coarsen([]named{{0, 1}, {5, 6}, {7, 9}}, 2)
+	if want := []named{{0, 1}, {5, 9}}; !slices.Equal(got, want) {
+		t.Errorf("Coarsen = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,268 @@
+// Package kdtree is a k-d tree of points, a spatial index which finds
+// the points nearest to a point, or within a distance of it or in a
+// rectangle, in two or three dimensions.
+//
+// A k-d tree divides its points in halves at their median along one
+// axis, and each half at its median along the next, in turn, down to
+// small buckets of points. It is built at once, by reordering the
+// points, with no nodes other than ranges of the points, and is not
+// changed afterward: a tree of moving points is rebuilt. For points
+// alone it is smaller and faster than an R-tree, and as it is no more
+// than its points in order, it is as simple to store as they are.
+//
+// The distances of the searches are measured by a geom.Metric, such as
+// geom.Planar for points in the plane or geom.Haversine for longitudes
+// and latitudes.
+package kdtree
+
+import (
+	"iter"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// bucketSize is the number of points up to which a node of a tree is
+// not divided, but searched point by point.
+const bucketSize = 8
+
+// Tree is a k-d tree of items of type T at points.
+type Tree[T any] struct {
+	dims   int
+	points []geom.Point
+	items  []T
+	bounds box
+}
+
+// box is the bounds of the points of a node of a tree, in the dimensions
+// of the tree.
+type box struct {
+	min, max [3]float64
+}
+
+// rect returns the box in the plane.
+func (b box) rect() geom.Rect {
+	return geom.Rect{MinX: b.min[0], MinY: b.min[1], MaxX: b.max[0], MaxY: b.max[1]}
+}
+
+// coord returns a coordinate of a point, X, Y or Z by the axis.
+func coord(p geom.Point, axis int) float64 {
+	switch axis {
+	case 0:
+		return p.X
+	case 1:
+		return p.Y
+	}
+	return p.Z
+}
+
+// New returns a tree of items at the points which point returns, of two
+// dimensions, X and Y, or of three, X, Y and Z, if dims is 3. It panics
+// if dims is not 2 or 3.
+func New[T any](items []T, point func(T) geom.Point, dims int) *Tree[T] {
+	if dims != 2 && dims != 3 {
+		panic("kdtree: dimensions not 2 or 3")
+	}
+	t := &Tree[T]{dims: dims, points: make([]geom.Point, len(items)), items: make([]T, len(items))}
+	copy(t.items, items)
+	inf := math.Inf(1)
+	t.bounds = box{[3]float64{inf, inf, inf}, [3]float64{-inf, -inf, -inf}}
+	for i, it := range items {
+		t.points[i] = point(it)
+		for a := range dims {
+			t.bounds.min[a] = min(t.bounds.min[a], coord(t.points[i], a))
+			t.bounds.max[a] = max(t.bounds.max[a], coord(t.points[i], a))
+		}
+	}
+	if dims == 2 {
+		t.bounds.min[2], t.bounds.max[2] = -inf, inf
+	}
+	t.build(0, len(items), 0)
+	return t
+}
+
+// build divides the points [lo, hi) at their median along the axis of a
+// depth, and each half along the next.
+func (t *Tree[T]) build(lo, hi, depth int) {
+	if hi-lo <= bucketSize {
+		return
+	}
+	m := (lo + hi) / 2
+	t.selectNth(lo, hi, m, depth%t.dims)
+	t.build(lo, m, depth+1)
+	t.build(m+1, hi, depth+1)
+}
+
+// selectNth reorders the points [lo, hi) so that the nth is that which
+// would be there if they were sorted along an axis, those before it
+// being no greater and those after it no less.
+func (t *Tree[T]) selectNth(lo, hi, n, axis int) {
+	for hi-lo > 1 {
+		// Partition around the median of three.
+		mid := (lo + hi) / 2
+		a, b, c := coord(t.points[lo], axis), coord(t.points[mid], axis), coord(t.points[hi-1], axis)
+		pivot := max(min(a, b), min(max(a, b), c))
+		i, j := lo, hi-1
+		for i <= j {
+			for coord(t.points[i], axis) < pivot {
+				i++
+			}
+			for coord(t.points[j], axis) > pivot {
+				j--
+			}
+			if i <= j {
+				t.swap(i, j)
+				i++
+				j--
+			}
+		}
+		switch {
+		case n <= j:
+			hi = j + 1
+		case n >= i:
+			lo = i
+		default:
+			return
+		}
+	}
+}
+
+// swap swaps two points and their items.
+func (t *Tree[T]) swap(i, j int) {
+	t.points[i], t.points[j] = t.points[j], t.points[i]
+	t.items[i], t.items[j] = t.items[j], t.items[i]
+}
+
+// Len returns the number of points of the tree.
+func (t *Tree[T]) Len() int {
+	return len(t.points)
+}
+
+// All returns an iterator over the points of the tree and their items,
+// in the order of the tree.
+func (t *Tree[T]) All() iter.Seq2[geom.Point, T] {
+	return func(yield func(geom.Point, T) bool) {
+		for i, p := range t.points {
+			if !yield(p, t.items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Search returns an iterator over the points of the tree in a rectangle
+// and their items, in the order of the tree. The Z of the points of a
+// tree of three dimensions is ignored.
+func (t *Tree[T]) Search(r geom.Rect) iter.Seq2[geom.Point, T] {
+	return func(yield func(geom.Point, T) bool) {
+		t.search(0, len(t.points), 0, t.bounds, func(b box) bool { return r.Intersects(b.rect()) },
+			func(p geom.Point) bool { return r.Contains(p) }, yield)
+	}
+}
+
+// Within returns an iterator over the points of the tree within a
+// distance of a point, measured by a metric, and their items, in the
+// order of the tree. The metric of a tree of three dimensions measures
+// the distances in X and Y, except for geom.Planar, for which Within
+// measures the distances in space, in X, Y and Z.
+func (t *Tree[T]) Within(p geom.Point, d float64, m geom.Metric) iter.Seq2[geom.Point, T] {
+	return func(yield func(geom.Point, T) bool) {
+		dist, bound := t.metric(m)
+		t.search(0, len(t.points), 0, t.bounds, func(b box) bool { return bound(p, b) <= d },
+			func(q geom.Point) bool { return dist(p, q) <= d }, yield)
+	}
+}
+
+// metric returns the distance and lower bound of a metric for the
+// tree.
+func (t *Tree[T]) metric(m geom.Metric) (func(p, q geom.Point) float64, func(p geom.Point, b box) float64) {
+	if t.dims == 3 && m == geom.Planar {
+		return func(p, q geom.Point) float64 {
+				return math.Sqrt((p.X-q.X)*(p.X-q.X) + (p.Y-q.Y)*(p.Y-q.Y) + (p.Z-q.Z)*(p.Z-q.Z))
+			}, func(p geom.Point, b box) float64 {
+				var s float64
+				for a := range 3 {
+					v := coord(p, a)
+					d := max(0, b.min[a]-v, v-b.max[a])
+					s += d * d
+				}
+				return math.Sqrt(s)
+			}
+	}
+	return m.Distance, func(p geom.Point, b box) float64 { return m.Bound(p, b.rect()) }
+}
+
+// search yields the points of the node [lo, hi) of a depth, bounded by a
+// box, which match, descending only into the nodes whose boxes may hold
+// points which match, reporting whether to go on.
+func (t *Tree[T]) search(lo, hi, depth int, b box, mayMatch func(box) bool, match func(geom.Point) bool, yield func(geom.Point, T) bool) bool {
+	if lo >= hi || !mayMatch(b) {
+		return true
+	}
+	if hi-lo <= bucketSize {
+		for i := lo; i < hi; i++ {
+			if match(t.points[i]) && !yield(t.points[i], t.items[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	m := (lo + hi) / 2
+	if match(t.points[m]) && !yield(t.points[m], t.items[m]) {
+		return false
+	}
+	left, right := t.halves(b, m, depth)
+	return t.search(lo, m, depth+1, left, mayMatch, match, yield) &&
+		t.search(m+1, hi, depth+1, right, mayMatch, match, yield)
+}
+
+// halves returns the boxes of the halves of a node of a depth, bounded
+// by a box, which is divided at the point m.
+func (t *Tree[T]) halves(b box, m, depth int) (left, right box) {
+	axis := depth % t.dims
+	left, right = b, b
+	left.max[axis] = coord(t.points[m], axis)
+	right.min[axis] = coord(t.points[m], axis)
+	return left, right
+}
+
+// Nearest returns the item at the point of the tree nearest to a point,
+// measured by a metric as for Within, that point and its distance, or
+// false if the tree has no points. Of points at the same distance, it
+// returns any.
+func (t *Tree[T]) Nearest(p geom.Point, m geom.Metric) (item T, point geom.Point, dist float64, ok bool) {
+	distance, bound := t.metric(m)
+	best, bestDist := -1, math.Inf(1)
+	var nearest func(lo, hi, depth int, b box)
+	nearest = func(lo, hi, depth int, b box) {
+		if lo >= hi || bound(p, b) > bestDist {
+			return
+		}
+		if hi-lo <= bucketSize {
+			for i := lo; i < hi; i++ {
+				if d := distance(p, t.points[i]); d < bestDist || best < 0 {
+					best, bestDist = i, d
+				}
+			}
+			return
+		}
+		mid := (lo + hi) / 2
+		if d := distance(p, t.points[mid]); d < bestDist || best < 0 {
+			best, bestDist = mid, d
+		}
+		left, right := t.halves(b, mid, depth)
+		// Search first the half whose box is nearer.
+		if bound(p, right) < bound(p, left) {
+			nearest(mid+1, hi, depth+1, right)
+			nearest(lo, mid, depth+1, left)
+		} else {
+			nearest(lo, mid, depth+1, left)
+			nearest(mid+1, hi, depth+1, right)
+		}
+	}
+	nearest(0, len(t.points), 0, t.bounds)
+	if best < 0 {
+		return item, point, 0, false
+	}
+	return t.items[best], t.points[best], bestDist, true
+}
@@ -0,0 +1,172 @@
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// randomPoints returns n random points in [0, 100)³, or of longitudes
+// and latitudes if lngLat.
+func randomPoints(r *rand.Rand, n int, lngLat bool) []geom.Point {
+	ps := make([]geom.Point, n)
+	for i := range ps {
+		ps[i] = geom.Point{X: r.Float64() * 100, Y: r.Float64() * 100, Z: r.Float64() * 100}
+		if lngLat {
+			ps[i] = geom.Point{X: r.Float64()*360 - 180, Y: r.Float64()*180 - 90}
+		}
+	}
+	return ps
+}
+
+// indexes returns the items of a sequence, sorted.
+func indexes(seq func(func(geom.Point, int) bool)) []int {
+	var is []int
+	for _, i := range seq {
+		is = append(is, i)
+	}
+	slices.Sort(is)
+	return is
+}
+
+// build returns a tree of the indexes of points.
+func build(ps []geom.Point, dims int) *Tree[int] {
+	items := make([]int, len(ps))
+	for i := range items {
+		items[i] = i
+	}
+	return New(items, func(i int) geom.Point { return ps[i] }, dims)
+}
+
+func TestTree(t *testing.T) {
+	ps := []geom.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}, {X: 5, Y: 5}, {X: 1, Y: 1}}
+	tr := build(ps, 2)
+	if tr.Len() != 5 {
+		t.Errorf("Len = %d, want 5", tr.Len())
+	}
+	if got, want := indexes(tr.All()), []int{0, 1, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("All = %v, want %v", got, want)
+	}
+	if got, want := indexes(tr.Search(geom.Rect{MinX: 0.5, MinY: 0, MaxX: 2, MaxY: 1})), []int{1, 2, 4}; !slices.Equal(got, want) {
+		t.Errorf("Search = %v, want %v", got, want)
+	}
+	if got, want := indexes(tr.Within(geom.Point{X: 1, Y: 0}, 1, geom.Planar)), []int{0, 1, 2, 4}; !slices.Equal(got, want) {
+		t.Errorf("Within = %v, want %v", got, want)
+	}
+	item, p, d, ok := tr.Nearest(geom.Point{X: 4, Y: 4.5}, geom.Planar)
+	if item != 3 || p != ps[3] || d != math.Hypot(1, 0.5) || !ok {
+		t.Errorf("Nearest = %d, %v, %v, %t, want 3, %v, %v", item, p, d, ok, ps[3], math.Hypot(1, 0.5))
+	}
+	for range tr.Search(geom.EmptyRect()) {
+		t.Error("Search of an empty rectangle yielded a point")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	tr := New(nil, func(geom.Point) geom.Point { return geom.Point{} }, 3)
+	if _, _, _, ok := tr.Nearest(geom.Point{}, geom.Planar); ok || tr.Len() != 0 {
+		t.Errorf("empty tree has Len %d or a nearest point", tr.Len())
+	}
+	for range tr.Within(geom.Point{}, math.Inf(1), geom.Planar) {
+		t.Error("Within of an empty tree yielded a point")
+	}
+}
+
+func TestNewDims(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("New of 4 dimensions did not panic")
+		}
+	}()
+	New([]geom.Point{{}}, func(p geom.Point) geom.Point { return p }, 4)
+}
+
+func TestNewCopies(t *testing.T) {
+	items := []int{3, 2, 1, 0, 9, 8, 7, 6, 5, 4, 10, 11}
+	want := slices.Clone(items)
+	New(items, func(i int) geom.Point { return geom.Point{X: float64(i)} }, 2)
+	if !slices.Equal(items, want) {
+		t.Errorf("New reordered its items to %v", items)
+	}
+}
+
+func TestSearchRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(97))
+	for _, dims := range []int{2, 3} {
+		ps := randomPoints(r, 3000, false)
+		tr := build(ps, dims)
+		for range 100 {
+			x, y := r.Float64()*100, r.Float64()*100
+			rect := geom.Rect{MinX: x, MinY: y, MaxX: x + r.Float64()*20, MaxY: y + r.Float64()*20}
+			var want []int
+			for i, p := range ps {
+				if rect.Contains(p) {
+					want = append(want, i)
+				}
+			}
+			if got := indexes(tr.Search(rect)); !slices.Equal(got, want) {
+				t.Fatalf("%d dimensions: Search(%v) = %v, want %v", dims, rect, got, want)
+			}
+		}
+	}
+}
+
+func TestWithinNearestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(97))
+	dist3 := func(p, q geom.Point) float64 {
+		return math.Sqrt((p.X-q.X)*(p.X-q.X) + (p.Y-q.Y)*(p.Y-q.Y) + (p.Z-q.Z)*(p.Z-q.Z))
+	}
+	tests := []struct {
+		name   string
+		dims   int
+		m      geom.Metric
+		lngLat bool
+		dist   func(p, q geom.Point) float64
+		d      float64
+	}{
+		{"plane", 2, geom.Planar, false, geom.Planar.Distance, 8},
+		{"space", 3, geom.Planar, false, dist3, 15},
+		{"sphere", 2, geom.Haversine, true, geom.Haversine.Distance, 1e6},
+		{"sphere of 3 dimensions", 3, geom.Haversine, true, geom.Haversine.Distance, 1e6},
+	}
+	for _, tt := range tests {
+		ps := randomPoints(r, 2000, tt.lngLat)
+		tr := build(ps, tt.dims)
+		for range 100 {
+			p := randomPoints(r, 1, tt.lngLat)[0]
+			var want []int
+			best, bestDist := -1, math.Inf(1)
+			for i, q := range ps {
+				d := tt.dist(p, q)
+				if d <= tt.d {
+					want = append(want, i)
+				}
+				if d < bestDist {
+					best, bestDist = i, d
+				}
+			}
+			if got := indexes(tr.Within(p, tt.d, tt.m)); !slices.Equal(got, want) {
+				t.Fatalf("%s: Within(%v, %v) = %v, want %v", tt.name, p, tt.d, got, want)
+			}
+			if item, q, d, ok := tr.Nearest(p, tt.m); !ok || d != bestDist || q != ps[item] {
+				t.Fatalf("%s: Nearest(%v) = %d, %v, %v, want %d at %v", tt.name, p, item, q, d, best, bestDist)
+			}
+		}
+	}
+}
+
+func TestStop(t *testing.T) {
+	tr := build(randomPoints(rand.New(rand.NewSource(97)), 100, false), 2)
+	n := 0
+	for range tr.Search(geom.Rect{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100}) {
+		if n++; n == 7 {
+			break
+		}
+	}
+	if n != 7 {
+		t.Errorf("Search yielded %d points before the break, want 7", n)
+	}
+}
@@ -0,0 +1,52 @@
+package kdtree
+
+import (
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/internal/bestfirst"
+)
+
+// KNN returns the k points of the tree nearest to a point, or all of
+// them if it has fewer, with their items, in order of their distances
+// from the point, measured by a metric as for Within. The rectangle and
+// the point of each neighbor are those of its point. Of points at the
+// same distance, it returns any.
+//
+// KNN searches the tree best first, dividing the node whose box is
+// nearest into its halves and the point between them until a point is
+// the nearest.
+func (t *Tree[T]) KNN(p geom.Point, k int, m geom.Metric) []geom.Neighbor[T] {
+	if len(t.points) == 0 || k <= 0 {
+		return nil
+	}
+	distance, bound := t.metric(m)
+	var q bestfirst.Queue[span, T]
+	push := func(i int) {
+		pt := t.points[i]
+		q.PushItem(geom.Neighbor[T]{Item: t.items[i], Rect: geom.RectOf(pt), Point: pt, Distance: distance(p, pt)})
+	}
+	q.PushNode(0, span{0, len(t.points), 0, t.bounds})
+	return q.Nearest(k, func(s span) {
+		if s.hi-s.lo <= bucketSize {
+			for i := s.lo; i < s.hi; i++ {
+				push(i)
+			}
+			return
+		}
+		mid := (s.lo + s.hi) / 2
+		push(mid)
+		left, right := t.halves(s.b, mid, s.depth)
+		if s.lo < mid {
+			q.PushNode(bound(p, left), span{s.lo, mid, s.depth + 1, left})
+		}
+		if mid+1 < s.hi {
+			q.PushNode(bound(p, right), span{mid + 1, s.hi, s.depth + 1, right})
+		}
+	})
+}
+
+// span is a node of a tree, the points [lo, hi) of a depth, bounded by a
+// box.
+type span struct {
+	lo, hi, depth int
+	b             box
+}
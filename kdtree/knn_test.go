@@ -0,0 +1,85 @@
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestKNN(t *testing.T) {
+	ps := []geom.Point{{X: 1, Y: 1}, {X: 5, Y: 5}, {X: -2, Y: 0}, {X: 3.5, Y: 0.5}}
+	tr := build(ps, 2)
+	tests := []struct {
+		p    geom.Point
+		k    int
+		want []int
+	}{
+		{geom.Point{X: 0, Y: 0}, 2, []int{0, 2}},
+		{geom.Point{X: 3.5, Y: 0}, 1, []int{3}},
+		{geom.Point{X: 6, Y: 6}, 3, []int{1, 3, 0}},
+		{geom.Point{X: 0, Y: 0}, 10, []int{0, 2, 3, 1}},
+		{geom.Point{X: 0, Y: 0}, 0, nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, n := range tr.KNN(tt.p, tt.k, geom.Planar) {
+			got = append(got, n.Item)
+			if n.Point != ps[n.Item] || n.Rect != geom.RectOf(ps[n.Item]) {
+				t.Errorf("KNN(%v, %d) has %+v, not at the point of its item", tt.p, tt.k, n)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("KNN(%v, %d) = %v, want %v", tt.p, tt.k, got, tt.want)
+		}
+	}
+	if nn := build(nil, 2).KNN(geom.Point{}, 3, geom.Planar); nn != nil {
+		t.Errorf("KNN of an empty tree = %v", nn)
+	}
+}
+
+func TestKNNRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(100))
+	dist3 := func(p, q geom.Point) float64 {
+		return math.Sqrt((p.X-q.X)*(p.X-q.X) + (p.Y-q.Y)*(p.Y-q.Y) + (p.Z-q.Z)*(p.Z-q.Z))
+	}
+	tests := []struct {
+		name   string
+		dims   int
+		m      geom.Metric
+		lngLat bool
+		shift  float64 // Added to the longitudes searched
+		dist   func(p, q geom.Point) float64
+	}{
+		{"plane", 2, geom.Planar, false, 0, geom.Planar.Distance},
+		{"space", 3, geom.Planar, false, 0, dist3},
+		{"sphere", 2, geom.Haversine, true, 0, geom.Haversine.Distance},
+		{"sphere, east of 180", 2, geom.Haversine, true, 360, geom.Haversine.Distance},
+		{"sphere, west of -180", 2, geom.Haversine, true, -720, geom.Haversine.Distance},
+	}
+	for _, tt := range tests {
+		ps := randomPoints(r, 3000, tt.lngLat)
+		tr := build(ps, tt.dims)
+		for range 100 {
+			p := randomPoints(r, 1, tt.lngLat)[0]
+			p.X += tt.shift
+			k := 1 + r.Intn(20)
+			want := make([]float64, len(ps))
+			for i, q := range ps {
+				want[i] = tt.dist(p, q)
+			}
+			slices.Sort(want)
+			nn := tr.KNN(p, k, tt.m)
+			if len(nn) != k {
+				t.Fatalf("%s: KNN(%v, %d) found %d points", tt.name, p, k, len(nn))
+			}
+			for i, n := range nn {
+				if n.Distance != want[i] || n.Point != ps[n.Item] {
+					t.Fatalf("%s: KNN(%v, %d)[%d] = %+v, want a point at %v", tt.name, p, k, i, n, want[i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,472 @@
+// Package kml reads the placemarks of KML documents, the format of
+// Google Earth, and of KMZ archives, which are zip files holding a KML
+// document and the files it refers to.
+//
+// Only the features which locate things are read: the Placemarks, with
+// their names, descriptions, extended data, and geometries, which may be
+// Points, LineStrings, LinearRings, Polygons, the Tracks and MultiTracks
+// of the gx extension, and MultiGeometries of them. Styles, overlays,
+// network links and everything else are skipped.
+//
+// KML found in the wild is often not quite valid, so the reader is
+// lenient. Tags are matched by their local names, whatever their
+// namespaces; unmatched tags, unknown entities and unquoted attributes
+// are tolerated; Latin-1 documents are read as well as UTF-8 ones;
+// coordinates may be separated by spaces around their commas; and rings
+// which are not closed are closed.
+package kml
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// ErrInvalid is returned, wrapped, when a document cannot be read as
+// KML, or an archive as KMZ.
+var ErrInvalid = errors.New("kml: invalid KML")
+
+// Placemark is a placemark of a KML document.
+type Placemark struct {
+	// ID is the id attribute of the placemark, if any.
+	ID string
+
+	// Name and Description are the name and description of the
+	// placemark. The description is often HTML.
+	Name, Description string
+
+	// Folders are the names of the Documents and Folders enclosing the
+	// placemark, outermost first.
+	Folders []string
+
+	// Geometry is the geometry of the placemark, of longitudes X and
+	// latitudes Y in degrees, and altitudes Z in meters, or nil if it has
+	// none. A MultiGeometry becomes a MultiPoint, MultiLineString or
+	// MultiPolygon if all of its members are of the same type, and
+	// otherwise a GeometryCollection. A Track becomes a LineString and
+	// a MultiTrack a MultiLineString.
+	Geometry geom.Geometry
+
+	// Layout is XYZ if any of the coordinates of the geometry has an
+	// altitude, and XY otherwise.
+	Layout geom.Layout
+
+	// Data are the values of the extended data of the placemark, by
+	// name: both the Data elements and the SimpleData of SchemaData.
+	Data map[string]string
+}
+
+// Read reads the placemarks of a KML document, in the order of the
+// document. It returns an error wrapping ErrInvalid only if the document
+// cannot be parsed as XML at all, or does not start with a kml element.
+func Read(r io.Reader) ([]Placemark, error) {
+	root, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if root.name != "kml" {
+		return nil, fmt.Errorf("%w: root element <%s> is not <kml>", ErrInvalid, root.name)
+	}
+	var ps []Placemark
+	walk(root, nil, &ps)
+	return ps, nil
+}
+
+// ReadKMZ reads the placemarks of the KML document of a KMZ archive of
+// the given size, as for Read. The document is the file doc.kml at the
+// root of the archive if there is one, as Google Earth writes it, and
+// otherwise the first file whose name ends in .kml.
+func ReadKMZ(r io.ReaderAt, size int64) ([]Placemark, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	var doc *zip.File
+	for _, f := range z.File {
+		if f.Name == "doc.kml" {
+			doc = f
+			break
+		}
+		if doc == nil && strings.EqualFold(path.Ext(f.Name), ".kml") {
+			doc = f
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("%w: KMZ archive has no .kml file", ErrInvalid)
+	}
+	f, err := doc.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// node is an element of a document.
+type node struct {
+	name     string // The local name
+	attrs    []xml.Attr
+	children []*node
+	text     strings.Builder
+}
+
+// child returns the first child of a node of a name, or nil if it has
+// none.
+func (n *node) child(name string) *node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// childText returns the trimmed text of the first child of a node of a
+// name, or "" if it has none.
+func (n *node) childText(name string) string {
+	if c := n.child(name); c != nil {
+		return strings.TrimSpace(c.text.String())
+	}
+	return ""
+}
+
+// attr returns the value of an attribute of a node, or "".
+func (n *node) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// parse returns the root element of a document.
+func parse(r io.Reader) (*node, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charsetReader
+	var root *node
+	var stack []*node
+	for {
+		// Raw tokens, unlike those of Token, are not checked to be
+		// matched, so that an unmatched end tag does not close the
+		// elements which enclose it.
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if root != nil {
+				break // Keep what was read of a truncated document
+			}
+			return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				top := stack[len(stack)-1]
+				top.children = append(top.children, n)
+			} else if root == nil {
+				root = n
+			} else {
+				continue // A second root element
+			}
+			if !isVoid(t.Name.Local) {
+				stack = append(stack, n)
+			}
+		case xml.EndElement:
+			// Pop up to the matching element, if it is open.
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].name == t.Name.Local {
+					stack = stack[:i]
+					break
+				}
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: document has no elements", ErrInvalid)
+	}
+	return root, nil
+}
+
+// isVoid reports whether an element is one of the HTML elements, such
+// as br, which have no end tags.
+func isVoid(name string) bool {
+	for _, v := range xml.HTMLAutoClose {
+		if strings.EqualFold(v, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// charsetReader returns a reader converting a Latin-1 or Windows-1252
+// document to UTF-8, taking the characters which the two differ on as
+// Latin-1. Other documents are read as they are.
+func charsetReader(charset string, r io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1", "windows-1252", "cp1252", "us-ascii", "ascii":
+		return &latin1Reader{r: bufio.NewReader(r)}, nil
+	}
+	return r, nil
+}
+
+// latin1Reader converts Latin-1 to UTF-8.
+type latin1Reader struct {
+	r *bufio.Reader
+	// pending holds the bytes of a character not yet returned.
+	pending []byte
+}
+
+// Read implements io.Reader.
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(l.pending) > 0 {
+			p[n] = l.pending[0]
+			l.pending = l.pending[1:]
+			n++
+			continue
+		}
+		if n > 0 && l.r.Buffered() == 0 {
+			break // Do not block with bytes to return
+		}
+		c, err := l.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		if c < utf8.RuneSelf {
+			p[n] = c
+			n++
+		} else {
+			l.pending = utf8.AppendRune(l.pending[:0], rune(c))
+		}
+	}
+	return n, nil
+}
+
+// walk appends the placemarks of a node and its descendants to ps, with
+// the names of the enclosing folders.
+func walk(n *node, folders []string, ps *[]Placemark) {
+	switch n.name {
+	case "Placemark":
+		*ps = append(*ps, placemark(n, folders))
+		return
+	case "Document", "Folder":
+		folders = append(folders[:len(folders):len(folders)], n.childText("name"))
+	}
+	for _, c := range n.children {
+		walk(c, folders, ps)
+	}
+}
+
+// placemark returns the placemark of a Placemark element.
+func placemark(n *node, folders []string) Placemark {
+	p := Placemark{
+		ID:          n.attr("id"),
+		Name:        n.childText("name"),
+		Description: n.childText("description"),
+		Folders:     folders,
+	}
+	var r reader
+	for _, c := range n.children {
+		if g := r.geometry(c); g != nil {
+			p.Geometry = g
+			break
+		}
+	}
+	if r.z {
+		p.Layout = geom.XYZ
+	}
+	if e := n.child("ExtendedData"); e != nil {
+		p.Data = make(map[string]string)
+		for _, c := range e.children {
+			switch c.name {
+			case "Data":
+				p.Data[c.attr("name")] = c.childText("value")
+			case "SchemaData":
+				for _, s := range c.children {
+					if s.name == "SimpleData" {
+						p.Data[s.attr("name")] = strings.TrimSpace(s.text.String())
+					}
+				}
+			}
+		}
+	}
+	return p
+}
+
+// reader reads geometries, noting whether any coordinates have
+// altitudes.
+type reader struct {
+	z bool
+}
+
+// geometry returns the geometry of an element, or nil if it is not a
+// geometry.
+func (r *reader) geometry(n *node) geom.Geometry {
+	switch n.name {
+	case "Point":
+		if l := r.coordinates(n); len(l) > 0 {
+			return l[0]
+		}
+		return geom.EmptyPoint()
+	case "LineString", "LinearRing":
+		return r.coordinates(n)
+	case "Polygon":
+		return r.polygon(n)
+	case "Track":
+		return r.track(n)
+	case "MultiTrack":
+		var m geom.MultiLineString
+		for _, c := range n.children {
+			if c.name == "Track" {
+				m = append(m, r.track(c))
+			}
+		}
+		return m
+	case "MultiGeometry":
+		var c geom.GeometryCollection
+		for _, m := range n.children {
+			if g := r.geometry(m); g != nil {
+				c = append(c, g)
+			}
+		}
+		return homogenize(c)
+	}
+	return nil
+}
+
+// homogenize returns the members of a collection as a multipart
+// geometry if they are all points, all line strings or all polygons,
+// and otherwise the collection.
+func homogenize(c geom.GeometryCollection) geom.Geometry {
+	if len(c) == 0 {
+		return c
+	}
+	var mp geom.MultiPoint
+	var ml geom.MultiLineString
+	var mpoly geom.MultiPolygon
+	for _, g := range c {
+		switch g := g.(type) {
+		case geom.Point:
+			mp = append(mp, g)
+		case geom.LineString:
+			ml = append(ml, g)
+		case geom.Polygon:
+			mpoly = append(mpoly, g)
+		}
+	}
+	switch len(c) {
+	case len(mp):
+		return mp
+	case len(ml):
+		return ml
+	case len(mpoly):
+		return mpoly
+	}
+	return c
+}
+
+// polygon returns the polygon of a Polygon element, with its rings
+// closed.
+func (r *reader) polygon(n *node) geom.Polygon {
+	var p geom.Polygon
+	for _, b := range []string{"outerBoundaryIs", "innerBoundaryIs"} {
+		for _, c := range n.children {
+			if c.name != b {
+				continue
+			}
+			for _, lr := range c.children {
+				if lr.name != "LinearRing" {
+					continue
+				}
+				l := r.coordinates(lr)
+				if len(l) > 0 && !l[0].Equal(l[len(l)-1]) {
+					l = append(l, l[0])
+				}
+				if len(l) > 0 || b == "outerBoundaryIs" {
+					p = append(p, geom.Ring(l))
+				}
+			}
+		}
+		if len(p) == 0 {
+			return nil // No outer boundary
+		}
+	}
+	return p
+}
+
+// coordinates returns the points of the coordinates child of an
+// element, which are tuples of a longitude, a latitude and optionally
+// an altitude, separated by commas, the tuples separated by white space.
+// Tuples which are not numbers are skipped.
+func (r *reader) coordinates(n *node) geom.LineString {
+	// Remove the white space around commas, so that the tuples are
+	// separated by white space alone.
+	s := commaSpace.ReplaceAllString(n.childText("coordinates"), ",")
+	var l geom.LineString
+	for _, t := range strings.Fields(s) {
+		if p, ok := r.point(strings.Split(strings.Trim(t, ","), ",")); ok {
+			l = append(l, p)
+		}
+	}
+	return l
+}
+
+// commaSpace matches a comma and the white space around it.
+var commaSpace = regexp.MustCompile(`\s*,\s*`)
+
+// track returns the line string of the coord elements of a Track, each
+// of a longitude, a latitude and an altitude separated by spaces.
+func (r *reader) track(n *node) geom.LineString {
+	var l geom.LineString
+	for _, c := range n.children {
+		if c.name == "coord" {
+			if p, ok := r.point(strings.Fields(c.text.String())); ok {
+				l = append(l, p)
+			}
+		}
+	}
+	return l
+}
+
+// point returns the point of a longitude, a latitude and optionally an
+// altitude, reporting whether they are numbers.
+func (r *reader) point(cs []string) (geom.Point, bool) {
+	if len(cs) < 2 {
+		return geom.Point{}, false
+	}
+	var vs [3]float64
+	for i := 0; i < len(cs) && i < 3; i++ {
+		v, err := strconv.ParseFloat(cs[i], 64)
+		if err != nil {
+			return geom.Point{}, false
+		}
+		vs[i] = v
+	}
+	if len(cs) > 2 {
+		r.z = true
+	}
+	return geom.Point{X: vs[0], Y: vs[1], Z: vs[2]}, true
+}
@@ -0,0 +1,322 @@
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// sample is a document as Google Earth exports it.
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+<Document>
+	<name>Survey.kml</name>
+	<Style id="s"><IconStyle><scale>1.1</scale></IconStyle></Style>
+	<Folder>
+		<name>Day 1</name>
+		<Placemark id="p1">
+			<name>Well</name>
+			<description><![CDATA[<b>Dry</b>]]></description>
+			<styleUrl>#s</styleUrl>
+			<ExtendedData>
+				<Data name="depth"><displayName>Depth</displayName><value> 12 </value></Data>
+				<SchemaData schemaUrl="#survey"><SimpleData name="crew">A</SimpleData></SchemaData>
+			</ExtendedData>
+			<Point><coordinates>-122.0822035425683,37.42228990140251,0</coordinates></Point>
+		</Placemark>
+		<Placemark>
+			<name>Path</name>
+			<LineString>
+				<tessellate>1</tessellate>
+				<coordinates>
+					-112.2550785337791,36.07954952145647
+					-112.2549277039738,36.08117083492122
+				</coordinates>
+			</LineString>
+		</Placemark>
+	</Folder>
+	<Placemark>
+		<name>Field</name>
+		<Polygon>
+			<outerBoundaryIs><LinearRing><coordinates>0,0 10,0 10,10 0,10 0,0</coordinates></LinearRing></outerBoundaryIs>
+			<innerBoundaryIs><LinearRing><coordinates>2,2 2,4 4,4 4,2 2,2</coordinates></LinearRing></innerBoundaryIs>
+		</Polygon>
+	</Placemark>
+	<Placemark>
+		<name>Drive</name>
+		<gx:Track>
+			<when>2010-05-28T02:02:09Z</when>
+			<when>2010-05-28T02:02:35Z</when>
+			<gx:coord>-122.207881 37.371915 156.0</gx:coord>
+			<gx:coord>-122.205712 37.373288 152.0</gx:coord>
+		</gx:Track>
+	</Placemark>
+	<Placemark><name>Empty</name></Placemark>
+</Document>
+</kml>`
+
+func TestRead(t *testing.T) {
+	ps, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Placemark{
+		{
+			ID: "p1", Name: "Well", Description: "<b>Dry</b>",
+			Folders:  []string{"Survey.kml", "Day 1"},
+			Geometry: geom.Point{X: -122.0822035425683, Y: 37.42228990140251},
+			Layout:   geom.XYZ,
+			Data:     map[string]string{"depth": "12", "crew": "A"},
+		},
+		{
+			Name:     "Path",
+			Folders:  []string{"Survey.kml", "Day 1"},
+			Geometry: geom.LineString{{X: -112.2550785337791, Y: 36.07954952145647}, {X: -112.2549277039738, Y: 36.08117083492122}},
+		},
+		{
+			Name:     "Field",
+			Folders:  []string{"Survey.kml"},
+			Geometry: geom.Polygon{ring(0, 0, 10, 0, 10, 10, 0, 10, 0, 0), ring(2, 2, 2, 4, 4, 4, 4, 2, 2, 2)},
+		},
+		{
+			Name:     "Drive",
+			Folders:  []string{"Survey.kml"},
+			Geometry: geom.LineString{{X: -122.207881, Y: 37.371915, Z: 156}, {X: -122.205712, Y: 37.373288, Z: 152}},
+			Layout:   geom.XYZ,
+		},
+		{Name: "Empty", Folders: []string{"Survey.kml"}},
+	}
+	if !reflect.DeepEqual(ps, want) {
+		t.Errorf("Read =\n%+v\nwant\n%+v", ps, want)
+	}
+}
+
+func ring(c ...float64) geom.Ring {
+	r := make(geom.Ring, len(c)/2)
+	for i := range r {
+		r[i] = geom.Point{X: c[2*i], Y: c[2*i+1]}
+	}
+	return r
+}
+
+func TestReadGeometry(t *testing.T) {
+	tests := []struct {
+		kml    string
+		want   geom.Geometry
+		layout geom.Layout
+	}{
+		{`<Point><coordinates>1,2</coordinates></Point>`, geom.Point{X: 1, Y: 2}, geom.XY},
+		{`<Point><coordinates> 1 , 2 , 3 </coordinates></Point>`, geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ},
+		{`<LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing>`, geom.LineString{{}, {X: 1}, {X: 1, Y: 1}, {}}, geom.XY},
+		{`<LineString><coordinates>1,2
+			3,4 x,5 6 7,8,9,</coordinates></LineString>`, geom.LineString{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 7, Y: 8, Z: 9}}, geom.XYZ},
+		{`<LineString><coordinates>1,2 3,4</coordinates></LineString>`, geom.LineString{{X: 1, Y: 2}, {X: 3, Y: 4}}, geom.XY},
+		{`<Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1</coordinates></LinearRing></outerBoundaryIs></Polygon>`,
+			geom.Polygon{ring(0, 0, 1, 0, 1, 1, 0, 0)}, geom.XY},
+		{`<Polygon><innerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1</coordinates></LinearRing></innerBoundaryIs></Polygon>`,
+			geom.Polygon(nil), geom.XY},
+		{`<Polygon><innerBoundaryIs><LinearRing><coordinates/></LinearRing></innerBoundaryIs>
+			<outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon>`,
+			geom.Polygon{ring(0, 0, 1, 0, 1, 1, 0, 0)}, geom.XY},
+		{`<MultiGeometry><Point><coordinates>1,2</coordinates></Point><Point><coordinates>3,4</coordinates></Point></MultiGeometry>`,
+			geom.MultiPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}, geom.XY},
+		{`<MultiGeometry><LineString><coordinates>1,2 3,4</coordinates></LineString></MultiGeometry>`,
+			geom.MultiLineString{{{X: 1, Y: 2}, {X: 3, Y: 4}}}, geom.XY},
+		{`<MultiGeometry><Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon></MultiGeometry>`,
+			geom.MultiPolygon{{ring(0, 0, 1, 0, 1, 1, 0, 0)}}, geom.XY},
+		{`<MultiGeometry><Point><coordinates>1,2,3</coordinates></Point><LineString><coordinates>1,2 3,4</coordinates></LineString>
+			<MultiGeometry><Point><coordinates>5,6</coordinates></Point></MultiGeometry></MultiGeometry>`,
+			geom.GeometryCollection{geom.Point{X: 1, Y: 2, Z: 3}, geom.LineString{{X: 1, Y: 2}, {X: 3, Y: 4}}, geom.MultiPoint{{X: 5, Y: 6}}}, geom.XYZ},
+		{`<MultiGeometry><name>none</name></MultiGeometry>`, geom.GeometryCollection(nil), geom.XY},
+		{`<gx:MultiTrack><gx:Track><gx:coord>1 2 3</gx:coord></gx:Track><gx:Track><gx:coord>4 5</gx:coord><gx:coord>bad 6 7</gx:coord></gx:Track></gx:MultiTrack>`,
+			geom.MultiLineString{{{X: 1, Y: 2, Z: 3}}, {{X: 4, Y: 5}}}, geom.XYZ},
+		{`<Model><Location><longitude>1</longitude></Location></Model><Point><coordinates>1,2</coordinates></Point>`, geom.Point{X: 1, Y: 2}, geom.XY},
+		{`<Model/>`, nil, geom.XY},
+	}
+	for _, tt := range tests {
+		ps, err := Read(strings.NewReader(`<kml><Placemark>` + tt.kml + `</Placemark></kml>`))
+		if err != nil || len(ps) != 1 {
+			t.Errorf("Read of %s = %v, %v", tt.kml, ps, err)
+			continue
+		}
+		if !reflect.DeepEqual(ps[0].Geometry, tt.want) || ps[0].Layout != tt.layout {
+			t.Errorf("Read of %s = %#v, %v, want %#v, %v", tt.kml, ps[0].Geometry, ps[0].Layout, tt.want, tt.layout)
+		}
+	}
+}
+
+func TestReadEmptyPoint(t *testing.T) {
+	ps, err := Read(strings.NewReader(`<kml><Placemark><Point><coordinates/></Point></Placemark></kml>`))
+	if err != nil || len(ps) != 1 {
+		t.Fatalf("Read = %v, %v", ps, err)
+	}
+	if p, ok := ps[0].Geometry.(geom.Point); !ok || !p.IsEmpty() {
+		t.Errorf("Read of an empty Point = %v, want the empty point", ps[0].Geometry)
+	}
+}
+
+func TestReadLenient(t *testing.T) {
+	tests := []struct {
+		name, doc string
+		want      []Placemark
+	}{
+		{
+			"namespaced",
+			`<k:kml xmlns:k="http://earth.google.com/kml/2.1"><k:Placemark><k:name>A</k:name></k:Placemark></k:kml>`,
+			[]Placemark{{Name: "A"}},
+		},
+		{
+			"unknown entities and unquoted attributes",
+			`<kml><Placemark id=x1><name>A&nbsp;&amp;&bogus;B</name></Placemark></kml>`,
+			[]Placemark{{ID: "x1", Name: "A\u00a0&&bogus;B"}},
+		},
+		{
+			"unmatched tags",
+			`<kml><Document><name>D</name><Placemark><name>A</b></name><description>x<br>y</description></Placemark></Folder><Placemark><name>B</name></Placemark></Document></kml>`,
+			[]Placemark{{Name: "A", Description: "xy", Folders: []string{"D"}}, {Name: "B", Folders: []string{"D"}}},
+		},
+		{
+			"truncated",
+			`<kml><Placemark><name>A</name></Placemark><Placemark><name>B`,
+			[]Placemark{{Name: "A"}, {Name: "B"}},
+		},
+		{
+			"second root",
+			`<kml><Placemark><name>A</name></Placemark></kml><kml><Placemark><name>B</name></Placemark></kml>`,
+			[]Placemark{{Name: "A"}},
+		},
+		{
+			"Latin-1",
+			"<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><kml><Placemark><name>Caf\xe9 \xfc</name></Placemark></kml>",
+			[]Placemark{{Name: "Café ü"}},
+		},
+		{
+			"unlabelled encoding",
+			"<?xml version=\"1.0\" encoding=\"UTF-8\"?><kml><Placemark><name>Café</name></Placemark></kml>",
+			[]Placemark{{Name: "Café"}},
+		},
+		{
+			"nested documents",
+			`<kml><Document><Folder><name>F</name><Folder><name>G</name><Placemark/></Folder><Placemark/></Folder></Document></kml>`,
+			[]Placemark{{Folders: []string{"", "F", "G"}}, {Folders: []string{"", "F"}}},
+		},
+		{"no placemarks", `<kml><Document><name>D</name></Document></kml>`, nil},
+	}
+	for _, tt := range tests {
+		ps, err := Read(strings.NewReader(tt.doc))
+		if err != nil {
+			t.Errorf("%s: Read: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(ps, tt.want) {
+			t.Errorf("%s: Read = %+v, want %+v", tt.name, ps, tt.want)
+		}
+	}
+}
+
+func TestReadLatin1Large(t *testing.T) {
+	// The conversion of Latin-1 spans the reads of the decoder.
+	var b bytes.Buffer
+	b.WriteString("<?xml version=\"1.0\" encoding=\"windows-1252\"?><kml>")
+	for range 2000 {
+		b.WriteString("<Placemark><name>\xe9\xe8\xff</name></Placemark>")
+	}
+	b.WriteString("</kml>")
+	ps, err := Read(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2000 {
+		t.Fatalf("Read = %d placemarks, want 2000", len(ps))
+	}
+	for i, p := range ps {
+		if p.Name != "éèÿ" {
+			t.Fatalf("placemark %d name = %q, want éèÿ", i, p.Name)
+		}
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		doc, err string
+	}{
+		{``, "kml: invalid KML: document has no elements"},
+		{`just text`, "kml: invalid KML: document has no elements"},
+		{`<!-- a comment -->`, "kml: invalid KML: document has no elements"},
+		{`<gpx><wpt/></gpx>`, "kml: invalid KML: root element <gpx> is not <kml>"},
+		{`<?xml version="1.0" encoding="UTF-8"?><html><body/></html>`, "kml: invalid KML: root element <html> is not <kml>"},
+	}
+	for _, tt := range tests {
+		ps, err := Read(strings.NewReader(tt.doc))
+		if err == nil || err.Error() != tt.err || !errors.Is(err, ErrInvalid) {
+			t.Errorf("Read(%q) = %v, %v, want error %q", tt.doc, ps, err, tt.err)
+		}
+	}
+}
+
+// kmz returns a zip archive of files of names and contents.
+func kmz(t *testing.T, files ...string) *bytes.Reader {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for i := 0; i < len(files); i += 2 {
+		f, err := w.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(files[i+1]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(b.Bytes())
+}
+
+func TestReadKMZ(t *testing.T) {
+	const a = `<kml><Placemark><name>A</name></Placemark></kml>`
+	const b = `<kml><Placemark><name>B</name></Placemark></kml>`
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"doc.kml", []string{"files/icon.png", "png", "doc.kml", a}, "A"},
+		{"doc.kml after another", []string{"other.kml", b, "doc.kml", a}, "A"},
+		{"first .kml", []string{"images/x.png", "png", "sub/Trail.KML", b, "z.kml", a}, "B"},
+		{"nested doc.kml", []string{"sub/doc.kml", b}, "B"},
+	}
+	for _, tt := range tests {
+		r := kmz(t, tt.files...)
+		ps, err := ReadKMZ(r, r.Size())
+		if err != nil {
+			t.Errorf("%s: ReadKMZ: %v", tt.name, err)
+			continue
+		}
+		if len(ps) != 1 || ps[0].Name != tt.want {
+			t.Errorf("%s: ReadKMZ = %+v, want placemark %s", tt.name, ps, tt.want)
+		}
+	}
+}
+
+func TestReadKMZInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *bytes.Reader
+		err  string
+	}{
+		{"not a zip", bytes.NewReader([]byte(sample)), "kml: invalid KML: zip: not a valid zip file"},
+		{"no kml", kmz(t, "doc.txt", "x"), "kml: invalid KML: KMZ archive has no .kml file"},
+		{"empty", kmz(t), "kml: invalid KML: KMZ archive has no .kml file"},
+		{"bad kml", kmz(t, "doc.kml", "<gpx/>"), "kml: invalid KML: root element <gpx> is not <kml>"},
+	}
+	for _, tt := range tests {
+		ps, err := ReadKMZ(tt.r, tt.r.Size())
+		if err == nil || err.Error() != tt.err || !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: ReadKMZ = %v, %v, want error %q", tt.name, ps, err, tt.err)
+		}
+	}
+}
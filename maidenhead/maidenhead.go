@@ -0,0 +1,192 @@
+// Package maidenhead encodes and decodes Maidenhead locators, also known
+// as QTH locators or grid squares, the short codes such as "FN31pr" which
+// amateur radio operators use to exchange their locations.
+//
+// A locator consists of pairs of characters, each pair giving a
+// longitude and a latitude and dividing the rectangle of the previous
+// pair: a field of letters A to R divides the Earth into 18 X 18
+// rectangles of 20 degrees of longitude by 10 degrees of latitude, a
+// square of digits divides a field into 10 X 10, a subsquare of letters
+// a to x divides a square into 24 X 24, and so on, alternating between
+// digits and letters. Letters are not case sensitive, but by convention
+// the field is written in upper case and the subsquares in lower case.
+package maidenhead
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxLen is the largest supported number of characters in a locator.
+const MaxLen = 10
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// ErrInvalid is returned, wrapped, when a function is given a string
+// which is not a valid locator.
+var ErrInvalid = errors.New("maidenhead: invalid locator")
+
+// divisions returns the number of divisions of each axis made by the
+// character pair k, counting from 0 for the field.
+func divisions(k int) int {
+	switch {
+	case k == 0:
+		return 18
+	case k%2 == 1:
+		return 10
+	default:
+		return 24
+	}
+}
+
+// Box is the rectangle identified by a locator, bounded by lines of
+// latitude and longitude given in degrees.
+type Box struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// Center returns the latitude and longitude of the center of the box.
+func (b Box) Center() (lat, lng float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
+
+// Encode returns the locator with n characters of the rectangle
+// containing the point with the given latitude and longitude, in
+// degrees. The length n must be an even number in the range [2, MaxLen];
+// other values are rounded up to the next even length, or up to 2 or
+// down to MaxLen. Latitudes beyond the poles are clamped and longitudes
+// are wrapped.
+//
+// The complementary function Decode performs the inverse mapping.
+func Encode(lat, lng float64, n int) string {
+	n = max(2, min(MaxLen, n))
+	if n%2 == 1 {
+		n++
+	}
+	lat = max(-90, min(90, lat))
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	// Work with fractions of the whole range, peeling off one pair of
+	// characters at a time.
+	fx, fy := lng/360, (lat+90)/180
+	b := make([]byte, n)
+	for k := 0; k < n/2; k++ {
+		d := divisions(k)
+		x := min(d-1, int(fx*float64(d)))
+		y := min(d-1, int(fy*float64(d)))
+		fx, fy = fx*float64(d)-float64(x), fy*float64(d)-float64(y)
+		switch {
+		case k == 0:
+			b[0], b[1] = byte('A'+x), byte('A'+y)
+		case k%2 == 1:
+			b[2*k], b[2*k+1] = byte('0'+x), byte('0'+y)
+		default:
+			b[2*k], b[2*k+1] = byte('a'+x), byte('a'+y)
+		}
+	}
+	return string(b)
+}
+
+// DecodeBox returns the rectangle identified by a locator. It returns an
+// error wrapping ErrInvalid if the locator does not have an even number
+// of characters up to MaxLen or a character is out of range for its
+// position.
+func DecodeBox(loc string) (Box, error) {
+	if len(loc) == 0 || len(loc)%2 == 1 || len(loc) > MaxLen {
+		return Box{}, fmt.Errorf("%w: %q must have 2, 4, 6, 8 or 10 characters", ErrInvalid, loc)
+	}
+	lng, lat := -180.0, -90.0
+	w, h := 360.0, 180.0
+	for k := 0; k < len(loc)/2; k++ {
+		d := divisions(k)
+		x, okX := digit(loc[2*k], k, d)
+		y, okY := digit(loc[2*k+1], k, d)
+		if !okX || !okY {
+			return Box{}, fmt.Errorf("%w: %q has an invalid character in pair %d", ErrInvalid, loc, k+1)
+		}
+		w, h = w/float64(d), h/float64(d)
+		lng += float64(x) * w
+		lat += float64(y) * h
+	}
+	return Box{lat, lat + h, lng, lng + w}, nil
+}
+
+// digit returns the value of character c of the character pair k, which
+// has d divisions.
+func digit(c byte, k, d int) (int, bool) {
+	var v int
+	switch {
+	case k%2 == 1:
+		v = int(c) - '0'
+	case 'a' <= c && c <= 'z':
+		v = int(c) - 'a'
+	default:
+		v = int(c) - 'A'
+	}
+	return v, v >= 0 && v < d
+}
+
+// Decode returns the latitude and longitude, in degrees, of the center
+// of the rectangle identified by a locator. It returns the same errors
+// as DecodeBox.
+//
+// The complementary function Encode performs the inverse mapping.
+func Decode(loc string) (lat, lng float64, err error) {
+	b, err := DecodeBox(loc)
+	if err != nil {
+		return 0, 0, err
+	}
+	lat, lng = b.Center()
+	return lat, lng, nil
+}
+
+// Distance returns the great circle distance in meters between the
+// centers of two locators, on a sphere with the mean radius of the
+// Earth. This is the conventional distance used to score contacts in
+// amateur radio contests. It returns an error wrapping ErrInvalid if
+// either locator is invalid.
+func Distance(from, to string) (float64, error) {
+	phi1, lambda1, phi2, lambda2, err := centers(from, to)
+	if err != nil {
+		return 0, err
+	}
+	dPhi, dLambda := phi2-phi1, lambda2-lambda1
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(min(1, h))), nil
+}
+
+// Bearing returns the initial bearing, in degrees clockwise from true
+// north in the range [0, 360), of the great circle from the center of
+// one locator to the center of another, that is, the direction in which
+// to point an antenna. It returns an error wrapping ErrInvalid if either
+// locator is invalid.
+func Bearing(from, to string) (float64, error) {
+	phi1, lambda1, phi2, lambda2, err := centers(from, to)
+	if err != nil {
+		return 0, err
+	}
+	dLambda := lambda2 - lambda1
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	theta := math.Atan2(y, x) * 180 / math.Pi
+	return math.Mod(theta+360, 360), nil
+}
+
+// centers returns the centers of two locators in radians.
+func centers(from, to string) (phi1, lambda1, phi2, lambda2 float64, err error) {
+	lat1, lng1, err := Decode(from)
+	if err != nil {
+		return
+	}
+	lat2, lng2, err := Decode(to)
+	if err != nil {
+		return
+	}
+	const r = math.Pi / 180
+	return lat1 * r, lng1 * r, lat2 * r, lng2 * r, nil
+}
@@ -0,0 +1,89 @@
+package maidenhead
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		n        int
+		want     string
+	}{
+		{41.714775, -72.727260, 6, "FN31pr"}, // W1AW, the ARRL headquarters station
+		{48.14666, 11.60833, 10, "JN58td25xe"},
+		{48.14666, 11.60833, 4, "JN58"},
+		{90, 180, 8, "AR09ax09"},
+		{-90, -180, 4, "AA00"},
+		// Lengths which are odd or out of range are rounded and clamped.
+		{41.714775, -72.727260, 5, "FN31pr"},
+		{41.714775, -72.727260, 1, "FN"},
+		{41.714775, -72.727260, 0, "FN"},
+		{41.714775, -72.727260, -3, "FN"},
+		{48.14666, 11.60833, 11, "JN58td25xe"},
+		{48.14666, 11.60833, 100, "JN58td25xe"},
+	}
+	for _, tt := range tests {
+		if got := Encode(tt.lat, tt.lng, tt.n); got != tt.want {
+			t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	want := Box{41.708333333333336, 41.75, -72.75, -72.66666666666667}
+	if b, err := DecodeBox("FN31pr"); b != want || err != nil {
+		t.Errorf("DecodeBox(%q) = (%v, %v), want %v", "FN31pr", b, err, want)
+	}
+	if lat, lng, err := Decode("jn58TD"); math.Abs(lat-48.14583333333333) > 1e-12 || math.Abs(lng-11.625) > 1e-12 || err != nil {
+		t.Errorf("Decode(%q) = (%v, %v, %v)", "jn58TD", lat, lng, err)
+	}
+	for _, loc := range []string{"", "J", "JN5", "JN58tdz", "JN58td25xe00", "JN5Atd", "SN58", "JS58", "JN58ty", "JN58tdA5"} {
+		if _, _, err := Decode(loc); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) error = %v, want %v", loc, err, ErrInvalid)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(35))
+	for range 10000 {
+		lat, lng := r.Float64()*180-90, r.Float64()*360-180
+		n := 2 * (1 + r.Intn(MaxLen/2))
+		loc := Encode(lat, lng, n)
+		b, err := DecodeBox(loc)
+		if err != nil || len(loc) != n || lat < b.MinLat || lat > b.MaxLat || lng < b.MinLng || lng > b.MaxLng {
+			t.Fatalf("DecodeBox(Encode(%v, %v, %d) = %q) = (%v, %v)", lat, lng, n, loc, b, err)
+		}
+		if clat, clng := b.Center(); Encode(clat, clng, n) != loc {
+			t.Fatalf("the center of %q is not in it", loc)
+		}
+	}
+}
+
+func TestDistanceBearing(t *testing.T) {
+	d, err := Distance("FN31pr", "JN58td")
+	if err != nil || math.Abs(d-6335789.4) > 1 {
+		t.Errorf("Distance(FN31pr, JN58td) = (%v, %v), want 6335789.4", d, err)
+	}
+	b, err := Bearing("FN31pr", "JN58td")
+	if err != nil || math.Abs(b-52.3624) > 1e-4 {
+		t.Errorf("Bearing(FN31pr, JN58td) = (%v, %v), want 52.3624", b, err)
+	}
+	if d, _ := Distance("JN58td", "JN58td"); d != 0 {
+		t.Errorf("Distance(JN58td, JN58td) = %v, want 0", d)
+	}
+	// Nearly due west the bearing is about 270, not -90.
+	if b, _ := Bearing("JJ00", "IJ00"); math.Abs(b-270) > 0.1 {
+		t.Errorf("Bearing(JJ00, IJ00) = %v, want about 270", b)
+	}
+	if _, err := Distance("FN31pr", "FN3"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Distance with an invalid locator error = %v, want %v", err, ErrInvalid)
+	}
+	if _, err := Bearing("XX", "FN31"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Bearing with an invalid locator error = %v, want %v", err, ErrInvalid)
+	}
+}
@@ -0,0 +1,275 @@
+// Package mgrs formats and parses Military Grid Reference System (MGRS)
+// grid references, such as "18SUJ2337106519", and converts them to and
+// from latitude and longitude on the WGS84 ellipsoid.
+//
+// Between 80 degrees south and 84 degrees north, a grid reference
+// consists of a UTM zone number and latitude band letter, two letters
+// identifying a 100 km square within the zone, and an easting and a
+// northing within the square with an equal number of digits, from none
+// for a precision of 100 km to five for a precision of 1 m. Near the
+// poles the zone number is omitted and the band letter is one of A and B
+// in the south or Y and Z in the north, and the square is located on the
+// Universal Polar Stereographic (UPS) grid. The UTM coordinates are
+// computed by package utm.
+//
+// The letters of the 100 km squares follow the current (AA) lettering
+// scheme used for WGS84. The implementation follows NGA Standardization
+// Document NGA.STND.0037, "The Universal Grids and the Transverse
+// Mercator and Polar Stereographic Map Projections" (2014).
+package mgrs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/geospat/utm"
+)
+
+// Precision is the number of digits in each of the easting and northing
+// of a grid reference.
+type Precision int
+
+// The supported precisions.
+const (
+	Precision100km Precision = iota
+	Precision10km
+	Precision1km
+	Precision100m
+	Precision10m
+	Precision1m
+)
+
+// Size returns the size in meters of the grid square identified by a
+// grid reference of precision p.
+func (p Precision) Size() float64 {
+	return math.Pow(10, float64(5-p))
+}
+
+// ErrInvalid is returned, wrapped, by Decode when its argument is not a
+// valid grid reference.
+var ErrInvalid = errors.New("mgrs: invalid grid reference")
+
+const (
+	// bands are the UTM latitude band letters, each of 8 degrees from
+	// 80 degrees south, except for X which spans 12 degrees.
+	bands = "CDEFGHJKLMNPQRSTUVWX"
+
+	// utmRows are the row letters of the 100 km squares of the UTM grid,
+	// repeating every 2000 km.
+	utmRows = "ABCDEFGHJKLMNPQRSTUV"
+
+	// upsSouthRows and upsNorthRows are the row letters of the 100 km
+	// squares of the UPS grids, starting at the northings upsSouthRow0
+	// and upsNorthRow0.
+	upsSouthRows = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	upsNorthRows = "ABCDEFGHJKLMNP"
+	upsSouthRow0 = 800000.0
+	upsNorthRow0 = 1300000.0
+
+	// upsWestCols and upsEastCols are the column letters of the 100 km
+	// squares west and east of the pole, starting at the eastings
+	// upsWestCol0 and upsFalseEN.
+	upsWestCols = "JKLPQRSTUXYZ"
+	upsEastCols = "ABCFGHJKLPQR"
+	upsWestCol0 = 800000.0
+
+	minUTMLat = utm.MinLat
+	maxUTMLat = utm.MaxLat
+)
+
+// utmCols are the column letters of the 100 km squares of the UTM grid,
+// which repeat every three zones.
+var utmCols = [3]string{"ABCDEFGH", "JKLMNPQR", "STUVWXYZ"}
+
+// Encode returns the grid reference of the given precision for the
+// square containing the point with the given latitude and longitude, in
+// degrees, in the form "18SUJ2337106519", without spaces. Latitudes
+// beyond the poles are clamped and longitudes are wrapped. The
+// precision must be in the range [Precision100km, Precision1m].
+//
+// The complementary function Decode performs the inverse mapping.
+func Encode(lat, lng float64, p Precision) string {
+	lat = max(-90, min(90, lat))
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	lng -= 180
+	if lat < minUTMLat || lat >= maxUTMLat {
+		return encodeUPS(lat, lng, p)
+	}
+	c, _ := utm.FromLatLng(lat, lng)
+	zone, easting, northing := c.Zone, c.Easting, c.Northing
+	band := bandOf(lat)
+	col := int(easting / 100000)
+	row := int(math.Mod(northing, 2000000) / 100000)
+	letters := [2]byte{
+		utmCols[(zone-1)%3][max(0, min(7, col-1))],
+		utmRows[(row+rowOffset(zone))%len(utmRows)],
+	}
+	return fmt.Sprintf("%02d%c%s%s", zone, band, letters[:], digits(easting, northing, p))
+}
+
+// encodeUPS returns the grid reference of a point in a polar region.
+func encodeUPS(lat, lng float64, p Precision) string {
+	north := lat > 0
+	easting, northing := toUPS(lat, lng, north)
+	west := easting < upsFalseEN
+	var band byte
+	var cols, rows string
+	var col0, row0 float64
+	switch {
+	case !north && west:
+		band, cols, col0, rows, row0 = 'A', upsWestCols, upsWestCol0, upsSouthRows, upsSouthRow0
+	case !north:
+		band, cols, col0, rows, row0 = 'B', upsEastCols, upsFalseEN, upsSouthRows, upsSouthRow0
+	case west:
+		band, cols, col0, rows, row0 = 'Y', upsWestCols, upsWestCol0, upsNorthRows, upsNorthRow0
+	default:
+		band, cols, col0, rows, row0 = 'Z', upsEastCols, upsFalseEN, upsNorthRows, upsNorthRow0
+	}
+	col := max(0, min(len(cols)-1, int((easting-col0)/100000)))
+	row := max(0, min(len(rows)-1, int((northing-row0)/100000)))
+	return fmt.Sprintf("%c%c%c%s", band, cols[col], rows[row], digits(easting, northing, p))
+}
+
+// digits returns the easting and northing within their 100 km square,
+// truncated to the given precision.
+func digits(easting, northing float64, p Precision) string {
+	if p == Precision100km {
+		return ""
+	}
+	size := p.Size()
+	e := int(math.Mod(math.Floor(easting), 100000) / size)
+	n := int(math.Mod(math.Floor(northing), 100000) / size)
+	return fmt.Sprintf("%0*d%0*d", int(p), e, int(p), n)
+}
+
+// bandOf returns the UTM latitude band letter for a latitude in the
+// range [minUTMLat, maxUTMLat).
+func bandOf(lat float64) byte {
+	return bands[min(len(bands)-1, int((lat-minUTMLat)/8))]
+}
+
+// rowOffset returns the offset into utmRows of the row letter of the
+// 100 km squares at northing zero in a zone.
+func rowOffset(zone int) int {
+	if zone%2 == 0 {
+		return 5
+	}
+	return 0
+}
+
+// Decode returns the latitude and longitude, in degrees, of the center of
+// the grid square identified by a grid reference, and the precision of
+// the reference. Letters may be in either case, and spaces between the
+// parts of the reference are ignored. It returns an error wrapping
+// ErrInvalid if ref is not a valid grid reference.
+//
+// The complementary function Encode performs the inverse mapping.
+func Decode(ref string) (lat, lng float64, p Precision, err error) {
+	s := strings.ToUpper(strings.ReplaceAll(ref, " ", ""))
+	k := 0
+	for k < len(s) && k < 2 && s[k] >= '0' && s[k] <= '9' {
+		k++
+	}
+	zone := 0
+	if k > 0 {
+		zone, _ = strconv.Atoi(s[:k])
+		if zone < 1 || zone > 60 {
+			return 0, 0, 0, fmt.Errorf("%w: %q has zone %d", ErrInvalid, ref, zone)
+		}
+	}
+	if len(s) < k+3 {
+		return 0, 0, 0, fmt.Errorf("%w: %q is too short", ErrInvalid, ref)
+	}
+	band, letters, rest := s[k], s[k+1:k+3], s[k+3:]
+	if len(rest)%2 == 1 || len(rest) > 10 {
+		return 0, 0, 0, fmt.Errorf("%w: %q has an odd or excessive number of digits", ErrInvalid, ref)
+	}
+	p = Precision(len(rest) / 2)
+	if strings.Trim(rest, "0123456789") != "" {
+		return 0, 0, 0, fmt.Errorf("%w: %q has invalid digits", ErrInvalid, ref)
+	}
+	e, _ := strconv.Atoi("0" + rest[:p])
+	n, _ := strconv.Atoi("0" + rest[p:])
+	// Locate the center of the square within its 100 km square.
+	size := p.Size()
+	de, dn := float64(e)*size+size/2, float64(n)*size+size/2
+	if zone == 0 {
+		lat, lng, err = decodeUPS(band, letters, de, dn)
+	} else {
+		lat, lng, err = decodeUTM(zone, band, letters, de, dn)
+	}
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%w: %q %s", ErrInvalid, ref, err)
+	}
+	return lat, lng, p, nil
+}
+
+// decodeUTM returns the position of a point at offset (de, dn) within a
+// 100 km square of the UTM grid.
+func decodeUTM(zone int, band byte, letters string, de, dn float64) (lat, lng float64, err error) {
+	b := strings.IndexByte(bands, band)
+	if b < 0 {
+		return 0, 0, fmt.Errorf("has invalid band %q", band)
+	}
+	col := strings.IndexByte(utmCols[(zone-1)%3], letters[0])
+	row := strings.IndexByte(utmRows, letters[1])
+	if col < 0 || row < 0 {
+		return 0, 0, fmt.Errorf("has invalid 100 km square %q for zone %d", letters, zone)
+	}
+	easting := float64(col+1)*100000 + de
+	row = (row - rowOffset(zone) + len(utmRows)) % len(utmRows)
+	north := band >= 'N'
+	// The row letters repeat every 2000 km, so choose the northing
+	// which puts the point closest to the middle of its band.
+	south := minUTMLat + 8*float64(b)
+	middle := south + 4
+	if band == 'X' {
+		middle = 78
+	}
+	best := math.Inf(1)
+	for k := 0; k < 5; k++ {
+		northing := float64(row)*100000 + float64(k)*2000000 + dn
+		la, lo := utm.Coord{Zone: zone, North: north, Easting: easting, Northing: northing}.LatLng()
+		if d := math.Abs(la - middle); d < best {
+			best, lat, lng = d, la, lo
+		}
+	}
+	if best > 6.5 {
+		return 0, 0, fmt.Errorf("has 100 km square %q outside band %c", letters, band)
+	}
+	return lat, lng, nil
+}
+
+// decodeUPS returns the position of a point at offset (de, dn) within a
+// 100 km square of the UPS grid.
+func decodeUPS(band byte, letters string, de, dn float64) (lat, lng float64, err error) {
+	var cols, rows string
+	var col0, row0 float64
+	switch band {
+	case 'A':
+		cols, col0, rows, row0 = upsWestCols, upsWestCol0, upsSouthRows, upsSouthRow0
+	case 'B':
+		cols, col0, rows, row0 = upsEastCols, upsFalseEN, upsSouthRows, upsSouthRow0
+	case 'Y':
+		cols, col0, rows, row0 = upsWestCols, upsWestCol0, upsNorthRows, upsNorthRow0
+	case 'Z':
+		cols, col0, rows, row0 = upsEastCols, upsFalseEN, upsNorthRows, upsNorthRow0
+	default:
+		return 0, 0, fmt.Errorf("has no zone and invalid polar band %q", band)
+	}
+	col := strings.IndexByte(cols, letters[0])
+	row := strings.IndexByte(rows, letters[1])
+	if col < 0 || row < 0 {
+		return 0, 0, fmt.Errorf("has invalid 100 km square %q for band %c", letters, band)
+	}
+	easting := col0 + float64(col)*100000 + de
+	northing := row0 + float64(row)*100000 + dn
+	lat, lng = fromUPS(easting, northing, band >= 'Y')
+	return lat, lng, nil
+}
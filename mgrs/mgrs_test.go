@@ -0,0 +1,96 @@
+package mgrs
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		p        Precision
+		want     string
+	}{
+		{38.8895, -77.0352, Precision1m, "18SUJ2348606483"}, // The Washington Monument
+		{-34.6037, -58.3816, Precision10m, "21HUB73317003"},
+		{0, 0, Precision100km, "31NAA"},
+		// South-western Norway and Svalbard have wider zones.
+		{60, 5, Precision1km, "32VKM7658"},
+		{78, 15, Precision1km, "33XWG0058"},
+		// The polar regions are on the UPS grid.
+		{-85, 10, Precision1m, "BAT9645447018"},
+		{88, -100, Precision100m, "YXH813385"},
+		{90, 0, Precision1m, "ZAH0000000000"},
+		{-90, 0, Precision1m, "BAN0000000000"},
+		{38.8895, 282.9648, Precision1m, "18SUJ2348606483"},
+	}
+	for _, tt := range tests {
+		if got := Encode(tt.lat, tt.lng, tt.p); got != tt.want {
+			t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		ref      string
+		lat, lng float64
+		p        Precision
+	}{
+		{"18SUJ2348606483", 38.8895, -77.0352, Precision1m},
+		{"18s uj 23486 06483", 38.8895, -77.0352, Precision1m},
+		{"4QFJ12345678", 21.309478, -157.916819, Precision10m},
+		{"31NAA", 0.4517, -0.1439, Precision100km},
+		{"ZAH", 89.3631, 135, Precision100km},
+		{"BAN0000000000", -90, 0, Precision1m},
+	}
+	for _, tt := range tests {
+		lat, lng, p, err := Decode(tt.ref)
+		if err != nil || p != tt.p || math.Abs(lat-tt.lat) > 1e-4 || math.Abs(math.Cos(lat*math.Pi/180)*(lng-tt.lng)) > 1e-4 {
+			t.Errorf("Decode(%q) = (%v, %v, %d, %v), want (%v, %v, %d)", tt.ref, lat, lng, p, err, tt.lat, tt.lng, tt.p)
+		}
+	}
+	for _, ref := range []string{
+		"", "18", "18S", "18SU", "18SUJ123", "18SUJ12345678901", "18SUJ12a4", "61SUJ", "00SUJ",
+		"18IUJ", "18SIJ", "18SUW", "18SAJ", "18XUA", "CAN", "AAN", "ZZA",
+	} {
+		if _, _, _, err := Decode(ref); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) error = %v, want %v", ref, err, ErrInvalid)
+		}
+	}
+}
+
+func TestPrecisionSize(t *testing.T) {
+	for p, want := range []float64{100000, 10000, 1000, 100, 10, 1} {
+		if s := Precision(p).Size(); s != want {
+			t.Errorf("Precision(%d).Size() = %v, want %v", p, s, want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(33))
+	for range 20000 {
+		lat, lng := math.Asin(2*r.Float64()-1)*180/math.Pi, r.Float64()*360-180
+		p := Precision(r.Intn(int(Precision1m) + 1))
+		ref := Encode(lat, lng, p)
+		dlat, dlng, dp, err := Decode(ref)
+		if err != nil || dp != p {
+			t.Fatalf("Decode(Encode(%v, %v, %d) = %q) = (%d, %v)", lat, lng, p, ref, dp, err)
+		}
+		if p >= Precision1km {
+			// The center of the square is within its diagonal of the
+			// point, and encodes to the same square unless the square
+			// straddles the edge of its zone or band.
+			d := math.Hypot(dlat-lat, math.Remainder(dlng-lng, 360)*math.Cos(lat*math.Pi/180)) * 111.2e3
+			if d > 1.5*p.Size() {
+				t.Fatalf("Decode(%q) = (%v, %v), %v m from (%v, %v)", ref, dlat, dlng, d, lat, lng)
+			}
+			if again := Encode(dlat, dlng, p); again != ref && again[:3] == ref[:3] {
+				t.Fatalf("Encode(Decode(%q)) = %q", ref, again)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package mgrs
+
+import (
+	"github.com/gogama/geospat/geodesy"
+	"github.com/gogama/geospat/proj"
+)
+
+const (
+	upsK0      = 0.994
+	upsFalseEN = 2000000.0
+)
+
+// upsProjection returns the northern or southern UPS projection,
+// according to north.
+func upsProjection(north bool) proj.PolarStereographic {
+	p := proj.PolarStereographic{
+		Ellipsoid:           geodesy.WGS84,
+		LatitudeOfTrueScale: 90,
+		ScaleFactor:         upsK0,
+		FalseEasting:        upsFalseEN,
+		FalseNorthing:       upsFalseEN,
+	}
+	if !north {
+		p.LatitudeOfTrueScale = -90
+	}
+	return p
+}
+
+// toUPS returns the UPS easting and northing of a point, using the
+// northern or southern projection according to north.
+func toUPS(lat, lng float64, north bool) (easting, northing float64) {
+	return upsProjection(north).Forward(geodesy.LatLng{Lat: lat, Lng: lng})
+}
+
+// fromUPS returns the latitude and longitude of a point with the given
+// UPS easting and northing, in the northern or southern projection.
+func fromUPS(easting, northing float64, north bool) (lat, lng float64) {
+	p := upsProjection(north).Inverse(easting, northing)
+	return p.Lat, p.Lng
+}
@@ -0,0 +1,50 @@
+package mgrs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// snyderUPS returns the UPS easting and northing of a point by the
+// closed formulas for the polar aspect of the ellipsoidal stereographic
+// projection in John P. Snyder, "Map Projections: A Working Manual",
+// USGS Professional Paper 1395 (1987), equations 15-9 and 21-33.
+func snyderUPS(lat, lng float64, north bool) (easting, northing float64) {
+	a, e := geodesy.WGS84.A, math.Sqrt(geodesy.WGS84.E2())
+	if !north {
+		lat = -lat
+	}
+	phi, lambda := lat*math.Pi/180, lng*math.Pi/180
+	es := e * math.Sin(phi)
+	t := math.Tan(math.Pi/4-phi/2) / math.Pow((1-es)/(1+es), e/2)
+	rho := 2 * a * upsK0 * t / math.Sqrt(math.Pow(1+e, 1+e)*math.Pow(1-e, 1-e))
+	easting = upsFalseEN + rho*math.Sin(lambda)
+	if north {
+		northing = upsFalseEN - rho*math.Cos(lambda)
+	} else {
+		northing = upsFalseEN + rho*math.Cos(lambda)
+	}
+	return
+}
+
+func TestUPS(t *testing.T) {
+	for _, lat := range []float64{84, 86, 89.9, 90, -80, -85, -90} {
+		north := lat > 0
+		for lng := -180.0; lng < 180; lng += 17 {
+			e, n := toUPS(lat, lng, north)
+			we, wn := snyderUPS(lat, lng, north)
+			if math.Hypot(e-we, n-wn) > 1e-6 {
+				t.Errorf("toUPS(%v, %v) = (%v, %v), want (%v, %v)", lat, lng, e, n, we, wn)
+			}
+			la, lo := fromUPS(e, n, north)
+			if math.Abs(la-lat) > 1e-9 || math.Abs(lat) < 90 && math.Abs(lo-lng) > 1e-9 {
+				t.Errorf("fromUPS(toUPS(%v, %v)) = (%v, %v)", lat, lng, la, lo)
+			}
+		}
+	}
+	if e, n := toUPS(90, 0, true); e != upsFalseEN || n != upsFalseEN {
+		t.Errorf("toUPS(90, 0) = (%v, %v), want the false origin", e, n)
+	}
+}
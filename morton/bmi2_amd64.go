@@ -0,0 +1,60 @@
+//go:build amd64 && !purego
+
+package morton
+
+// useBMI2 reports whether the Morton codes are computed with the BMI2
+// PDEP and PEXT instructions, which deposit and extract bits under a
+// mask in a single instruction each.
+//
+// On AMD processors before Zen 3, and on the Zen-derived Hygon
+// processors, the instructions are microcoded and
+// much slower than the portable bit twiddling, so they are only used on
+// processors which implement them in hardware.
+var useBMI2 = hasFastBMI2()
+
+// cpuid executes the CPUID instruction with the given leaf and subleaf.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func encode2BMI2(x, y uint32) uint64
+func decode2BMI2(d uint64) (x, y uint32)
+func encode3BMI2(x, y, z uint32) uint64
+func decode3BMI2(d uint64) (x, y, z uint32)
+
+// hasFastBMI2 returns true if the processor supports BMI2 and is not
+// known to implement PDEP and PEXT slowly.
+func hasFastBMI2() bool {
+	maxLeaf, b, c, d := cpuid(0, 0)
+	if maxLeaf < 7 {
+		return false
+	}
+	if _, ebx, _, _ := cpuid(7, 0); ebx&(1<<8) == 0 {
+		return false
+	}
+	eax, _, _, _ := cpuid(1, 0)
+	return !slowBMI2(b, d, c, eax)
+}
+
+// slowBMI2 reports whether the processor with the vendor string in ebx,
+// edx and ecx of CPUID leaf 0, and the signature in eax of leaf 1,
+// implements PDEP and PEXT in microcode. These are AMD processors
+// before Zen 3, and the Hygon Dhyana, which is derived from Zen 1.
+func slowBMI2(ebx, edx, ecx, eax uint32) bool {
+	const (
+		auth = 'A' | 'u'<<8 | 't'<<16 | 'h'<<24
+		enti = 'e' | 'n'<<8 | 't'<<16 | 'i'<<24
+		camd = 'c' | 'A'<<8 | 'M'<<16 | 'D'<<24
+		hygo = 'H' | 'y'<<8 | 'g'<<16 | 'o'<<24
+		ngen = 'n' | 'G'<<8 | 'e'<<16 | 'n'<<24
+		uine = 'u' | 'i'<<8 | 'n'<<16 | 'e'<<24
+	)
+	amd := ebx == auth && edx == enti && ecx == camd
+	hygon := ebx == hygo && edx == ngen && ecx == uine
+	if !amd && !hygon {
+		return false
+	}
+	family := eax >> 8 & 0xf
+	if family == 0xf {
+		family += eax >> 20 & 0xff
+	}
+	return family < 0x19 // Before Zen 3
+}
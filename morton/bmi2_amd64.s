@@ -0,0 +1,67 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func encode2BMI2(x, y uint32) uint64
+TEXT ·encode2BMI2(SB), NOSPLIT, $0-16
+	MOVL x+0(FP), AX
+	MOVL y+4(FP), BX
+	MOVQ $0x5555555555555555, CX
+	PDEPQ CX, AX, AX
+	MOVQ $0xaaaaaaaaaaaaaaaa, CX
+	PDEPQ CX, BX, BX
+	ORQ  BX, AX
+	MOVQ AX, ret+8(FP)
+	RET
+
+// func decode2BMI2(d uint64) (x, y uint32)
+TEXT ·decode2BMI2(SB), NOSPLIT, $0-16
+	MOVQ d+0(FP), AX
+	MOVQ $0x5555555555555555, CX
+	PEXTQ CX, AX, BX
+	MOVL BX, x+8(FP)
+	MOVQ $0xaaaaaaaaaaaaaaaa, CX
+	PEXTQ CX, AX, BX
+	MOVL BX, y+12(FP)
+	RET
+
+// func encode3BMI2(x, y, z uint32) uint64
+TEXT ·encode3BMI2(SB), NOSPLIT, $0-24
+	MOVL x+0(FP), AX
+	MOVL y+4(FP), BX
+	MOVL z+8(FP), DX
+	MOVQ $0x1249249249249249, CX
+	PDEPQ CX, AX, AX
+	MOVQ $0x2492492492492492, CX
+	PDEPQ CX, BX, BX
+	MOVQ $0x4924924924924924, CX
+	PDEPQ CX, DX, DX
+	ORQ  BX, AX
+	ORQ  DX, AX
+	MOVQ AX, ret+16(FP)
+	RET
+
+// func decode3BMI2(d uint64) (x, y, z uint32)
+TEXT ·decode3BMI2(SB), NOSPLIT, $0-20
+	MOVQ d+0(FP), AX
+	MOVQ $0x1249249249249249, CX
+	PEXTQ CX, AX, BX
+	MOVL BX, x+8(FP)
+	MOVQ $0x2492492492492492, CX
+	PEXTQ CX, AX, BX
+	MOVL BX, y+12(FP)
+	MOVQ $0x4924924924924924, CX
+	PEXTQ CX, AX, BX
+	MOVL BX, z+16(FP)
+	RET
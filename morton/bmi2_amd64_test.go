@@ -0,0 +1,35 @@
+//go:build amd64 && !purego
+
+package morton
+
+import "testing"
+
+func TestSlowBMI2(t *testing.T) {
+	// vendor returns the registers of a CPUID vendor string.
+	vendor := func(s string) (ebx, edx, ecx uint32) {
+		reg := func(s string) uint32 {
+			return uint32(s[0]) | uint32(s[1])<<8 | uint32(s[2])<<16 | uint32(s[3])<<24
+		}
+		return reg(s[0:4]), reg(s[4:8]), reg(s[8:12])
+	}
+	tests := []struct {
+		vendor string
+		eax    uint32
+		want   bool
+	}{
+		{"AuthenticAMD", 0x00800f12, true},  // Zen 1, family 0x17
+		{"AuthenticAMD", 0x00870f10, true},  // Zen 2, family 0x17
+		{"AuthenticAMD", 0x00a20f10, false}, // Zen 3, family 0x19
+		{"AuthenticAMD", 0x00a60f12, false}, // Zen 4, family 0x19
+		{"HygonGenuine", 0x00900f01, true},  // Dhyana, family 0x18
+		{"HygonGenuine", 0x00a00f00, false}, // family 0x19
+		{"GenuineIntel", 0x000906ea, false},
+		{"GenuineIntel", 0x00000f00, false}, // family 0xf
+	}
+	for _, tt := range tests {
+		ebx, edx, ecx := vendor(tt.vendor)
+		if got := slowBMI2(ebx, edx, ecx, tt.eax); got != tt.want {
+			t.Errorf("slowBMI2(%q, %#x) = %v, want %v", tt.vendor, tt.eax, got, tt.want)
+		}
+	}
+}
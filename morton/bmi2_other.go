@@ -0,0 +1,12 @@
+//go:build !amd64 || purego
+
+package morton
+
+// useBMI2 is always false where the BMI2 assembly is not available, so
+// the compiler removes the calls to the stubs below.
+const useBMI2 = false
+
+func encode2BMI2(x, y uint32) uint64        { panic("morton: BMI2 unavailable") }
+func decode2BMI2(d uint64) (x, y uint32)    { panic("morton: BMI2 unavailable") }
+func encode3BMI2(x, y, z uint32) uint64     { panic("morton: BMI2 unavailable") }
+func decode3BMI2(d uint64) (x, y, z uint32) { panic("morton: BMI2 unavailable") }
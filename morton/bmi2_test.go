@@ -0,0 +1,50 @@
+package morton
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBMI2MatchesPortable(t *testing.T) {
+	if !useBMI2 {
+		t.Skip("BMI2 not in use")
+	}
+	r := rand.New(rand.NewSource(13))
+	for range 10000 {
+		x, y, z := r.Uint32(), r.Uint32(), r.Uint32()
+		if d, want := encode2BMI2(x, y), spread2(x)|spread2(y)<<1; d != want {
+			t.Fatalf("encode2BMI2(%d, %d) = %#x, want %#x", x, y, d, want)
+		}
+		d := r.Uint64()
+		if x2, y2 := decode2BMI2(d); x2 != compact2(d) || y2 != compact2(d>>1) {
+			t.Fatalf("decode2BMI2(%#x) = (%d, %d)", d, x2, y2)
+		}
+		if d, want := encode3BMI2(x, y, z), spread3(x)|spread3(y)<<1|spread3(z)<<2; d != want {
+			t.Fatalf("encode3BMI2(%d, %d, %d) = %#x, want %#x", x, y, z, d, want)
+		}
+		if x2, y2, z2 := decode3BMI2(d); x2 != compact3(d) || y2 != compact3(d>>1) || z2 != compact3(d>>2) {
+			t.Fatalf("decode3BMI2(%#x) = (%d, %d, %d)", d, x2, y2, z2)
+		}
+	}
+}
+
+var sink uint64
+
+func BenchmarkEncode2(b *testing.B) {
+	for i := range b.N {
+		sink += Encode2(uint32(i), uint32(i>>3))
+	}
+}
+
+func BenchmarkEncode2Portable(b *testing.B) {
+	for i := range b.N {
+		sink += spread2(uint32(i)) | spread2(uint32(i>>3))<<1
+	}
+}
+
+func BenchmarkDecode3(b *testing.B) {
+	for i := range b.N {
+		x, _, _ := Decode3(uint64(i))
+		sink += uint64(x)
+	}
+}
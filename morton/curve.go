@@ -0,0 +1,69 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOrder is returned, wrapped, by New when the requested curve order
+// is not supported.
+var ErrOrder = errors.New("morton: invalid order")
+
+// MaxOrder is the largest order supported by Curve.
+const MaxOrder = 32
+
+// Curve is a discrete two-dimensional Morton curve of fixed order
+// through a square divided into 2^order X 2^order cells. It is the
+// Morton equivalent of hilbert.Curve and has the same methods.
+//
+// The zero value is a valid curve of order 0, which consists of a
+// single cell. Curve is a small value type which is safe for concurrent
+// use.
+type Curve struct {
+	order int
+	maxD  uint64
+}
+
+// New returns the Morton curve of the given order, which must be in the
+// range [0, MaxOrder]. For any other order New returns an error wrapping
+// ErrOrder.
+func New(order int) (Curve, error) {
+	if order < 0 || order > MaxOrder {
+		return Curve{}, fmt.Errorf("%w: %d is not in [0, %d]", ErrOrder, order, MaxOrder)
+	}
+	c := Curve{order: order}
+	if order > 0 {
+		n := uint64(1) << uint(order)
+		c.maxD = n*n - 1 // Wraps to 2^64-1 at order 32
+	}
+	return c, nil
+}
+
+// Order returns the order of the curve.
+func (c Curve) Order() int {
+	return c.order
+}
+
+// N returns the number of cells along each side of the square,
+// 2^Order(). Valid coordinates range from 0 to N()-1.
+func (c Curve) N() uint64 {
+	return 1 << uint(c.order)
+}
+
+// MaxD returns the largest distance along the curve, N()^2-1.
+func (c Curve) MaxD() uint64 {
+	return c.maxD
+}
+
+// XYToD converts a cell position with coordinates (x, y) to a distance
+// along the curve. The coordinates x and y must be in the range
+// [0, N()-1] and the return value is in the range [0, MaxD()].
+func (c Curve) XYToD(x, y uint32) uint64 {
+	return Encode2(x, y)
+}
+
+// DToXY converts a distance along the curve, which must be in the range
+// [0, MaxD()], to a cell position with coordinates (x, y).
+func (c Curve) DToXY(d uint64) (x, y uint32) {
+	return Decode2(d)
+}
@@ -0,0 +1,40 @@
+package morton
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		order int
+		n     uint64
+		maxD  uint64
+		err   error
+	}{
+		{0, 1, 0, nil},
+		{2, 4, 15, nil},
+		{32, 1 << 32, 1<<64 - 1, nil},
+		{-1, 1, 0, ErrOrder},
+		{33, 1, 0, ErrOrder},
+	}
+	for _, tt := range tests {
+		c, err := New(tt.order)
+		if !errors.Is(err, tt.err) || c.N() != tt.n || c.MaxD() != tt.maxD {
+			t.Errorf("New(%d) = N %d, MaxD %d, %v, want %d, %d, %v", tt.order, c.N(), c.MaxD(), err, tt.n, tt.maxD, tt.err)
+		}
+	}
+}
+
+func TestCurve(t *testing.T) {
+	c, _ := New(2)
+	if c.Order() != 2 {
+		t.Errorf("Order() = %d, want 2", c.Order())
+	}
+	if d := c.XYToD(2, 1); d != 6 {
+		t.Errorf("XYToD(2, 1) = %d, want 6", d)
+	}
+	if x, y := c.DToXY(6); x != 2 || y != 1 {
+		t.Errorf("DToXY(6) = (%d, %d), want (2, 1)", x, y)
+	}
+}
@@ -0,0 +1,173 @@
+// Package morton maps between multi-dimensional cell positions and
+// distances along a Morton, or Z-order, curve. The distance of a cell
+// is formed by interleaving the bits of its coordinates.
+//
+// The package mirrors the API of package hilbert, so the two curves can
+// be used interchangeably.
+package morton
+
+// XYToD converts a two-dimensional cell position with coordinates
+// (x, y) to a one-dimensional distance (d) along a discrete Morton
+// curve. The curve is constructed by dividing a square into n X n
+// cells.
+//
+// The cell count n must be a power of 2, and the coordinates x and y
+// must range from (0, 0) representing the cell at the lower left-hand
+// corner of the square, to (n-1, n-1) representing the cell at the
+// upper right-hand corner of the square.
+//
+// The return value d is a number in the range [0, n^2-1], in which bit
+// 2i is bit i of x and bit 2i+1 is bit i of y. Because a Morton
+// distance does not depend on the size of the square, n only serves to
+// mirror hilbert.XYToD.
+//
+// The complementary function DToXY performs the inverse mapping from
+// one-dimensional distance (d) back to two-dimensional position (x, y).
+func XYToD(n, x, y int) int {
+	return int(Encode2(uint32(x), uint32(y)))
+}
+
+// DToXY converts a one-dimensional distance (d) along a discrete Morton
+// curve to a two-dimensional cell position with coordinates (x, y).
+//
+// The cell count n must be a power of 2, and the distance d must range
+// from 0, representing the cell in the lower left-hand corner of the
+// square, to n^2-1, representing the cell in the upper right-hand
+// corner of the square.
+//
+// The complementary function XYToD performs the inverse transformation.
+func DToXY(n, d int) (x, y int) {
+	ux, uy := Decode2(uint64(d))
+	return int(ux), int(uy)
+}
+
+// XYZToD converts a three-dimensional cell position with coordinates
+// (x, y, z) to a one-dimensional distance (d) along a discrete Morton
+// curve. The curve is constructed by dividing a cube into n X n X n
+// cells.
+//
+// The cell count n must be a power of 2 no greater than 2^21, and the
+// coordinates x, y, and z must be in the range [0, n-1]. The return
+// value d is a number in the range [0, n^3-1], in which bit 3i is bit i
+// of x, bit 3i+1 is bit i of y, and bit 3i+2 is bit i of z.
+//
+// The complementary function DToXYZ performs the inverse mapping.
+func XYZToD(n, x, y, z int) int {
+	return int(Encode3(uint32(x), uint32(y), uint32(z)))
+}
+
+// DToXYZ converts a one-dimensional distance (d) along a discrete
+// three-dimensional Morton curve to a cell position with coordinates
+// (x, y, z).
+//
+// The cell count n must be a power of 2 no greater than 2^21, and the
+// distance d must be in the range [0, n^3-1].
+//
+// The complementary function XYZToD performs the inverse mapping.
+func DToXYZ(n, d int) (x, y, z int) {
+	ux, uy, uz := Decode3(uint64(d))
+	return int(ux), int(uy), int(uz)
+}
+
+// XYToD64 is the 64-bit equivalent of XYToD. The cell count n must be a
+// power of 2 no greater than 2^32.
+func XYToD64(n uint64, x, y uint32) uint64 {
+	return Encode2(x, y)
+}
+
+// DToXY64 is the 64-bit equivalent of DToXY. The cell count n must be a
+// power of 2 no greater than 2^32, and d must be in the range
+// [0, n^2-1].
+func DToXY64(n, d uint64) (x, y uint32) {
+	return Decode2(d)
+}
+
+// Encode2 interleaves the bits of x and y into a 64-bit Morton code in
+// which bit 2i is bit i of x and bit 2i+1 is bit i of y.
+//
+// On amd64 processors with fast BMI2 support, Encode2 and the other
+// encoding and decoding functions use the PDEP and PEXT instructions.
+// Elsewhere, or when built with the purego build tag, they use portable
+// bit twiddling. Both paths produce identical results.
+func Encode2(x, y uint32) uint64 {
+	if useBMI2 {
+		return encode2BMI2(x, y)
+	}
+	return spread2(x) | spread2(y)<<1
+}
+
+// Decode2 separates a 64-bit Morton code produced by Encode2 back into
+// its x and y coordinates.
+func Decode2(d uint64) (x, y uint32) {
+	if useBMI2 {
+		return decode2BMI2(d)
+	}
+	return compact2(d), compact2(d >> 1)
+}
+
+// Encode3 interleaves the low 21 bits of each of x, y, and z into a
+// 63-bit Morton code in which bit 3i is bit i of x, bit 3i+1 is bit i
+// of y, and bit 3i+2 is bit i of z. Higher bits of the coordinates are
+// ignored.
+func Encode3(x, y, z uint32) uint64 {
+	if useBMI2 {
+		return encode3BMI2(x, y, z)
+	}
+	return spread3(x) | spread3(y)<<1 | spread3(z)<<2
+}
+
+// Decode3 separates a Morton code produced by Encode3 back into its x, y,
+// and z coordinates.
+func Decode3(d uint64) (x, y, z uint32) {
+	if useBMI2 {
+		return decode3BMI2(d)
+	}
+	return compact3(d), compact3(d >> 1), compact3(d >> 2)
+}
+
+// spread2 spaces out the bits of v so that bit i moves to bit 2i.
+func spread2(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000ffff0000ffff
+	x = (x | x<<8) & 0x00ff00ff00ff00ff
+	x = (x | x<<4) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+// compact2 is the inverse of spread2. It gathers bit 2i of x into bit i
+// of the result.
+func compact2(x uint64) uint32 {
+	x &= 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x>>4) & 0x00ff00ff00ff00ff
+	x = (x | x>>8) & 0x0000ffff0000ffff
+	x = (x | x>>16) & 0x00000000ffffffff
+	return uint32(x)
+}
+
+// spread3 spaces out the low 21 bits of v so that bit i moves to bit
+// 3i.
+func spread3(v uint32) uint64 {
+	x := uint64(v) & 0x1fffff
+	x = (x | x<<32) & 0x001f00000000ffff
+	x = (x | x<<16) & 0x001f0000ff0000ff
+	x = (x | x<<8) & 0x100f00f00f00f00f
+	x = (x | x<<4) & 0x10c30c30c30c30c3
+	x = (x | x<<2) & 0x1249249249249249
+	return x
+}
+
+// compact3 is the inverse of spread3. It gathers bit 3i of x into bit i
+// of the result.
+func compact3(x uint64) uint32 {
+	x &= 0x1249249249249249
+	x = (x | x>>2) & 0x10c30c30c30c30c3
+	x = (x | x>>4) & 0x100f00f00f00f00f
+	x = (x | x>>8) & 0x001f0000ff0000ff
+	x = (x | x>>16) & 0x001f00000000ffff
+	x = (x | x>>32) & 0x00000000001fffff
+	return uint32(x)
+}
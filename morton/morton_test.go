@@ -0,0 +1,112 @@
+package morton
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEncode2(t *testing.T) {
+	tests := []struct {
+		x, y uint32
+		d    uint64
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{0, 1, 2},
+		{1, 1, 3},
+		{2, 0, 4},
+		{3, 5, 0b100111},
+		{1<<32 - 1, 0, 0x5555555555555555},
+		{0, 1<<32 - 1, 0xaaaaaaaaaaaaaaaa},
+		{1<<32 - 1, 1<<32 - 1, 1<<64 - 1},
+	}
+	for _, tt := range tests {
+		if d := Encode2(tt.x, tt.y); d != tt.d {
+			t.Errorf("Encode2(%d, %d) = %#x, want %#x", tt.x, tt.y, d, tt.d)
+		}
+		if x, y := Decode2(tt.d); x != tt.x || y != tt.y {
+			t.Errorf("Decode2(%#x) = (%d, %d), want (%d, %d)", tt.d, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestEncode3(t *testing.T) {
+	tests := []struct {
+		x, y, z uint32
+		d       uint64
+	}{
+		{0, 0, 0, 0},
+		{1, 0, 0, 1},
+		{0, 1, 0, 2},
+		{0, 0, 1, 4},
+		{1, 2, 4, 0b100010001},
+		{1<<21 - 1, 1<<21 - 1, 1<<21 - 1, 1<<63 - 1},
+	}
+	for _, tt := range tests {
+		if d := Encode3(tt.x, tt.y, tt.z); d != tt.d {
+			t.Errorf("Encode3(%d, %d, %d) = %#x, want %#x", tt.x, tt.y, tt.z, d, tt.d)
+		}
+		if x, y, z := Decode3(tt.d); x != tt.x || y != tt.y || z != tt.z {
+			t.Errorf("Decode3(%#x) = (%d, %d, %d), want (%d, %d, %d)", tt.d, x, y, z, tt.x, tt.y, tt.z)
+		}
+	}
+	// Bits above the 21st are ignored.
+	if d := Encode3(1<<21|1, 0, 0); d != 1 {
+		t.Errorf("Encode3(1<<21|1, 0, 0) = %#x, want 1", d)
+	}
+}
+
+// interleave2 and interleave3 interleave bits one at a time.
+func interleave2(x, y uint32) (d uint64) {
+	for i := range 32 {
+		d |= uint64(x>>i&1)<<(2*i) | uint64(y>>i&1)<<(2*i+1)
+	}
+	return d
+}
+
+func interleave3(x, y, z uint32) (d uint64) {
+	for i := range 21 {
+		d |= uint64(x>>i&1)<<(3*i) | uint64(y>>i&1)<<(3*i+1) | uint64(z>>i&1)<<(3*i+2)
+	}
+	return d
+}
+
+func TestEncodeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	for range 10000 {
+		x, y, z := r.Uint32(), r.Uint32(), r.Uint32()
+		if d, want := Encode2(x, y), interleave2(x, y); d != want {
+			t.Fatalf("Encode2(%d, %d) = %#x, want %#x", x, y, d, want)
+		}
+		if x2, y2 := Decode2(Encode2(x, y)); x2 != x || y2 != y {
+			t.Fatalf("Decode2(Encode2(%d, %d)) = (%d, %d)", x, y, x2, y2)
+		}
+		if d, want := Encode3(x, y, z), interleave3(x, y, z); d != want {
+			t.Fatalf("Encode3(%d, %d, %d) = %#x, want %#x", x, y, z, d, want)
+		}
+		if x2, y2, z2 := Decode3(Encode3(x, y, z)); x2 != x&(1<<21-1) || y2 != y&(1<<21-1) || z2 != z&(1<<21-1) {
+			t.Fatalf("Decode3(Encode3(%d, %d, %d)) = (%d, %d, %d)", x, y, z, x2, y2, z2)
+		}
+	}
+}
+
+func TestXYToD(t *testing.T) {
+	if d := XYToD(4, 3, 3); d != 15 {
+		t.Errorf("XYToD(4, 3, 3) = %d, want 15", d)
+	}
+	if x, y := DToXY(4, 9); x != 1 || y != 2 {
+		t.Errorf("DToXY(4, 9) = (%d, %d), want (1, 2)", x, y)
+	}
+	if d := XYZToD(2, 1, 1, 1); d != 7 {
+		t.Errorf("XYZToD(2, 1, 1, 1) = %d, want 7", d)
+	}
+	if x, y, z := DToXYZ(4, 0b100010); x != 0 || y != 1 || z != 2 {
+		t.Errorf("DToXYZ(4, 0b100010) = (%d, %d, %d), want (0, 1, 2)", x, y, z)
+	}
+	if d := XYToD64(1<<32, 1<<32-1, 0); d != 0x5555555555555555 {
+		t.Errorf("XYToD64(1<<32, 1<<32-1, 0) = %#x", d)
+	}
+	if x, y := DToXY64(1<<32, 1<<64-1); x != 1<<32-1 || y != 1<<32-1 {
+		t.Errorf("DToXY64(1<<32, 1<<64-1) = (%d, %d)", x, y)
+	}
+}
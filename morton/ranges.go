@@ -0,0 +1,139 @@
+package morton
+
+import "github.com/gogama/geospat/internal/keyrange"
+
+// Rect is an axis-aligned rectangle of cells. It contains every cell
+// (x, y) with XMin <= x <= XMax and YMin <= y <= YMax.
+type Rect struct {
+	XMin, YMin, XMax, YMax int
+}
+
+// Range is an inclusive interval [Lo, Hi] of distances along a Morton
+// curve.
+type Range struct {
+	Lo, Hi int
+}
+
+// BigMin returns the smallest Morton code greater than d that lies
+// inside the rectangle whose lower left-hand and upper right-hand
+// corners have the Morton codes lo and hi. The code d must lie in the
+// range [lo, hi] but outside the rectangle itself.
+//
+// When a range scan over Morton keys between lo and hi encounters a key
+// d outside the query rectangle, BigMin gives the key to seek to next,
+// skipping every key in between which is also outside the rectangle.
+// The algorithm is due to H. Tropf and H. Herzog, "Multidimensional
+// Range Search in Dynamically Balanced Trees", Angewandte Informatik 2
+// (1981).
+func BigMin(d, lo, hi uint64) (bigMin uint64) {
+	for bit := 63; bit >= 0; bit-- {
+		m := uint64(1) << uint(bit)
+		switch {
+		case d&m == 0 && lo&m == 0 && hi&m != 0:
+			bigMin = loadOnes(lo, bit)
+			hi = loadZeros(hi, bit)
+		case d&m == 0 && lo&m != 0:
+			return lo
+		case d&m != 0 && hi&m == 0:
+			return bigMin
+		case d&m != 0 && lo&m == 0 && hi&m != 0:
+			lo = loadOnes(lo, bit)
+		}
+	}
+	return
+}
+
+// LitMax returns the largest Morton code less than d that lies inside
+// the rectangle whose lower left-hand and upper right-hand corners have
+// the Morton codes lo and hi. The code d must lie in the range [lo, hi]
+// but outside the rectangle itself.
+//
+// LitMax is the counterpart of BigMin, giving the last key inside the
+// rectangle before d.
+func LitMax(d, lo, hi uint64) (litMax uint64) {
+	for bit := 63; bit >= 0; bit-- {
+		m := uint64(1) << uint(bit)
+		switch {
+		case d&m == 0 && lo&m == 0 && hi&m != 0:
+			hi = loadZeros(hi, bit)
+		case d&m == 0 && lo&m != 0:
+			return litMax
+		case d&m != 0 && hi&m == 0:
+			return hi
+		case d&m != 0 && lo&m == 0 && hi&m != 0:
+			litMax = loadZeros(hi, bit)
+			lo = loadOnes(lo, bit)
+		}
+	}
+	return
+}
+
+// dimMask returns a mask of the bits of a two-dimensional Morton code
+// that belong to the same dimension as bit and are less significant.
+func dimMask(bit int) uint64 {
+	all := uint64(0x5555555555555555) << uint(bit%2)
+	return all & (uint64(1)<<uint(bit) - 1)
+}
+
+// loadOnes sets the given bit of the Morton code d and clears the less
+// significant bits of the same dimension, giving the smallest code
+// whose coordinate in that dimension has the bit set.
+func loadOnes(d uint64, bit int) uint64 {
+	return d&^dimMask(bit) | uint64(1)<<uint(bit)
+}
+
+// loadZeros clears the given bit of the Morton code d and sets the less
+// significant bits of the same dimension, giving the largest code whose
+// coordinate in that dimension has the bit clear.
+func loadZeros(d uint64, bit int) uint64 {
+	return d&^(uint64(1)<<uint(bit)) | dimMask(bit)
+}
+
+// QueryRanges decomposes a rectangle of cells into the sorted,
+// non-overlapping, non-adjacent ranges of Morton distances that cover
+// it on a curve constructed by dividing a square into n X n cells. It
+// is the Morton equivalent of hilbert.QueryRanges. The cell count n
+// must be a power of 2. The parts of r which lie outside the square are
+// ignored, and if r does not intersect the square at all the return
+// value is nil.
+//
+// If maxRanges is zero or negative, the decomposition is exact: every
+// distance in the returned ranges belongs to a cell of r. Otherwise at
+// most maxRanges ranges are returned, obtained by merging neighboring
+// ranges across the smallest gaps, so the ranges still cover every cell
+// of r but may also cover some cells outside it.
+func QueryRanges(n int, r Rect, maxRanges int) []Range {
+	r.XMin, r.YMin = max(r.XMin, 0), max(r.YMin, 0)
+	r.XMax, r.YMax = min(r.XMax, n-1), min(r.YMax, n-1)
+	if r.XMin > r.XMax || r.YMin > r.YMax {
+		return nil
+	}
+	var ranges []Range
+	var visit func(x0, y0, s int)
+	visit = func(x0, y0, s int) {
+		if x0 > r.XMax || x0+s-1 < r.XMin || y0 > r.YMax || y0+s-1 < r.YMin {
+			return
+		}
+		if x0 >= r.XMin && x0+s-1 <= r.XMax && y0 >= r.YMin && y0+s-1 <= r.YMax {
+			lo := XYToD(n, x0, y0)
+			hi := lo + s*s - 1
+			if k := len(ranges) - 1; k >= 0 && ranges[k].Hi+1 == lo {
+				ranges[k].Hi = hi
+			} else {
+				ranges = append(ranges, Range{lo, hi})
+			}
+			return
+		}
+		// Visiting the quadrants in Z order yields the ranges sorted.
+		h := s / 2
+		visit(x0, y0, h)
+		visit(x0+h, y0, h)
+		visit(x0, y0+h, h)
+		visit(x0+h, y0+h, h)
+	}
+	visit(0, 0, n)
+	if maxRanges > 0 && len(ranges) > maxRanges {
+		ranges = keyrange.Coarsen(ranges, maxRanges)
+	}
+	return ranges
+}
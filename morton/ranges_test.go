@@ -0,0 +1,106 @@
+package morton
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBigMinLitMax(t *testing.T) {
+	// The example of the rectangle from (2, 2) to (3, 6), which a scan
+	// from its lower left-hand corner leaves at 19.
+	lo, hi := Encode2(2, 2), Encode2(3, 6)
+	if lo != 12 || hi != 45 {
+		t.Fatalf("corners = %d, %d, want 12, 45", lo, hi)
+	}
+	if d := BigMin(19, lo, hi); d != 36 {
+		t.Errorf("BigMin(19, 12, 45) = %d, want 36", d)
+	}
+	if d := LitMax(19, lo, hi); d != 15 {
+		t.Errorf("LitMax(19, 12, 45) = %d, want 15", d)
+	}
+}
+
+func TestBigMinLitMaxScan(t *testing.T) {
+	// BigMin and LitMax agree with a scan of the codes between the
+	// corners.
+	const n = 32
+	r := rand.New(rand.NewSource(17))
+	for range 300 {
+		x0, y0 := r.Intn(n), r.Intn(n)
+		x1, y1 := x0+r.Intn(n-x0), y0+r.Intn(n-y0)
+		lo, hi := Encode2(uint32(x0), uint32(y0)), Encode2(uint32(x1), uint32(y1))
+		in := func(d uint64) bool {
+			x, y := Decode2(d)
+			return int(x) >= x0 && int(x) <= x1 && int(y) >= y0 && int(y) <= y1
+		}
+		for d := lo; d <= hi; d++ {
+			if in(d) {
+				continue
+			}
+			var bigMin, litMax uint64
+			for e := d + 1; e <= hi; e++ {
+				if in(e) {
+					bigMin = e
+					break
+				}
+			}
+			for e := d - 1; e >= lo; e-- {
+				if in(e) {
+					litMax = e
+					break
+				}
+			}
+			if got := BigMin(d, lo, hi); got != bigMin {
+				t.Fatalf("BigMin(%d, %d, %d) = %d, want %d", d, lo, hi, got, bigMin)
+			}
+			if got := LitMax(d, lo, hi); got != litMax {
+				t.Fatalf("LitMax(%d, %d, %d) = %d, want %d", d, lo, hi, got, litMax)
+			}
+		}
+	}
+}
+
+func TestQueryRanges(t *testing.T) {
+	tests := []struct {
+		n         int
+		r         Rect
+		maxRanges int
+		want      []Range
+	}{
+		{4, Rect{0, 0, 3, 3}, 0, []Range{{0, 15}}},
+		{4, Rect{0, 0, 3, 0}, 0, []Range{{0, 1}, {4, 5}}},
+		{4, Rect{1, 1, 2, 2}, 0, []Range{{3, 3}, {6, 6}, {9, 9}, {12, 12}}},
+		{4, Rect{1, 1, 2, 2}, 2, []Range{{3, 9}, {12, 12}}},
+		{4, Rect{1, 1, 2, 2}, 1, []Range{{3, 12}}},
+		{4, Rect{-1, -1, 0, 0}, 0, []Range{{0, 0}}},
+		{4, Rect{5, 5, 9, 9}, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := QueryRanges(tt.n, tt.r, tt.maxRanges); !slices.Equal(got, tt.want) {
+			t.Errorf("QueryRanges(%d, %v, %d) = %v, want %v", tt.n, tt.r, tt.maxRanges, got, tt.want)
+		}
+	}
+}
+
+func TestQueryRangesCover(t *testing.T) {
+	const n = 32
+	r := rand.New(rand.NewSource(19))
+	for range 200 {
+		x0, y0 := r.Intn(n), r.Intn(n)
+		q := Rect{x0, y0, x0 + r.Intn(n-x0), y0 + r.Intn(n-y0)}
+		maxRanges := r.Intn(4)
+		ranges := QueryRanges(n, q, maxRanges)
+		if maxRanges > 0 && len(ranges) > maxRanges {
+			t.Fatalf("QueryRanges(%v, %d) = %d ranges", q, maxRanges, len(ranges))
+		}
+		for d := range n * n {
+			x, y := DToXY(n, d)
+			in := q.XMin <= x && x <= q.XMax && q.YMin <= y && y <= q.YMax
+			covered := slices.ContainsFunc(ranges, func(rg Range) bool { return rg.Lo <= d && d <= rg.Hi })
+			if in && !covered || maxRanges == 0 && covered && !in {
+				t.Fatalf("QueryRanges(%v, %d): cell (%d, %d) in %t, covered %t", q, maxRanges, x, y, in, covered)
+			}
+		}
+	}
+}
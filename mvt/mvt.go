@@ -0,0 +1,413 @@
+// Package mvt encodes Mapbox Vector Tiles, the protocol buffers in which
+// vector map tiles are served to MapLibre, Mapbox GL, OpenLayers and
+// most other web maps.
+//
+// A tile holds named layers of features, each a geometry and a set of
+// properties. Encode clips the geometries of the features to the bounds
+// of a tile, with a buffer around it so that the lines and polygons
+// which cross the edges of neighboring tiles are drawn without seams,
+// and quantizes their coordinates onto the grid of the tile, dropping
+// the parts which become too small to draw.
+//
+// The format is described at https://github.com/mapbox/vector-tile-spec.
+package mvt
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/tile"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, by Encode when the layers cannot
+	// make a valid tile.
+	ErrInvalid = errors.New("mvt: invalid layers")
+
+	// ErrUnsupported is returned, wrapped, by Encode when a feature has
+	// a property of a type which vector tiles cannot hold.
+	ErrUnsupported = errors.New("mvt: unsupported value")
+)
+
+// Layer is a layer of a tile.
+type Layer struct {
+	// Name is the name of the layer, which must be unique in the tile.
+	Name string
+
+	// Features are the features of the layer.
+	Features []*Feature
+}
+
+// Feature is a feature of a layer.
+type Feature struct {
+	// ID is the ID of the feature, which is not written if it is 0, or
+	// if the feature is written as more than one.
+	ID uint64
+
+	// Geometry is the geometry of the feature, in Web Mercator
+	// (EPSG:3857) meters, as proj.WebMercator projects it. A geometry
+	// collection is written as a feature for each dimension of its
+	// members, with the same properties; as the IDs of the features of
+	// a layer should be unique, the features are then written without
+	// the ID.
+	Geometry geom.Geometry
+
+	// Properties are the properties of the feature: strings, booleans,
+	// integers, and floats, which are written as floats or doubles by
+	// their size. Properties whose values are nil are not written.
+	Properties map[string]any
+}
+
+// Format is a format of tiles. The zero value is the usual format, an
+// extent of 4096 without a buffer.
+type Format struct {
+	// Extent is the size of the grid of the tile onto which coordinates
+	// are quantized, or 0 for 4096.
+	Extent int
+
+	// Buffer is the width of the buffer around the tile to which
+	// geometries are clipped, in units of the grid, such as 64 for a
+	// tile of extent 4096.
+	Buffer int
+}
+
+// Encode encodes a tile of layers in the zero Format.
+func Encode(t tile.Tile, layers []Layer) ([]byte, error) {
+	return Format{}.Encode(t, layers)
+}
+
+// Encode encodes a tile of layers. Layers with no features in the tile
+// are not written, and neither are the keys and values of the
+// properties of features outside the tile. It returns an error wrapping
+// ErrInvalid if two layers have the same name, and one wrapping
+// ErrUnsupported if a property of a feature in the tile has a value of
+// a type which vector tiles cannot hold.
+func (f Format) Encode(t tile.Tile, layers []Layer) ([]byte, error) {
+	extent := f.Extent
+	if extent <= 0 {
+		extent = 4096
+	}
+	minX, minY, maxX, maxY := t.MercatorBounds()
+	scale := float64(extent) / (maxX - minX)
+	buffer := float64(f.Buffer) / scale
+	e := encoder{
+		clip:  geom.Rect{MinX: minX - buffer, MinY: minY - buffer, MaxX: maxX + buffer, MaxY: maxY + buffer},
+		minX:  minX,
+		maxY:  maxY,
+		scale: scale,
+	}
+	var b []byte
+	names := make(map[string]bool, len(layers))
+	for _, l := range layers {
+		if names[l.Name] {
+			return nil, fmt.Errorf("%w: layer %s is repeated", ErrInvalid, l.Name)
+		}
+		names[l.Name] = true
+		lb, err := e.layer(l, extent)
+		if err != nil {
+			return nil, fmt.Errorf("%w: layer %s", err, l.Name)
+		}
+		if lb != nil {
+			b = appendBytes(b, 3, lb)
+		}
+	}
+	return b, nil
+}
+
+// The geometry types of features.
+const (
+	typePoint = iota + 1
+	typeLineString
+	typePolygon
+)
+
+// The commands of geometries.
+const (
+	moveTo    = 1
+	lineTo    = 2
+	closePath = 7
+)
+
+// encoder encodes the layers of a tile.
+type encoder struct {
+	// clip is the rectangle to which geometries are clipped, in meters.
+	clip geom.Rect
+	// minX, maxY and scale map meters onto the grid of the tile, whose
+	// origin is the north-west corner.
+	minX, maxY, scale float64
+}
+
+// layer returns the encoding of a layer, or nil if none of its features
+// are in the tile.
+func (e *encoder) layer(l Layer, extent int) ([]byte, error) {
+	var features []byte
+	var keys []string
+	var values [][]byte
+	keyIndex := map[string]int{}
+	valueIndex := map[string]int{}
+	for _, f := range l.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		// The properties of features outside the tile are not added to
+		// the keys and values.
+		geometries := e.geometries(e.clip.Clip(f.Geometry))
+		n := 0
+		for _, cmds := range geometries {
+			if len(cmds) > 0 {
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		var tags []uint32
+		for _, k := range slices.Sorted(maps.Keys(f.Properties)) {
+			v := f.Properties[k]
+			if v == nil {
+				continue
+			}
+			vb, err := value(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: property %s", err, k)
+			}
+			ki, ok := keyIndex[k]
+			if !ok {
+				ki = len(keys)
+				keyIndex[k] = ki
+				keys = append(keys, k)
+			}
+			vi, ok := valueIndex[string(vb)]
+			if !ok {
+				vi = len(values)
+				valueIndex[string(vb)] = vi
+				values = append(values, vb)
+			}
+			tags = append(tags, uint32(ki), uint32(vi))
+		}
+		for typ, cmds := range geometries {
+			if len(cmds) == 0 {
+				continue
+			}
+			var fb []byte
+			if f.ID != 0 && n == 1 {
+				fb = appendVarintField(fb, 1, f.ID)
+			}
+			fb = appendPacked(fb, 2, tags)
+			fb = appendVarintField(fb, 3, uint64(typ))
+			fb = appendPacked(fb, 4, cmds)
+			features = appendBytes(features, 2, fb)
+		}
+	}
+	if features == nil {
+		return nil, nil
+	}
+	b := appendVarintField(nil, 15, 2) // The version
+	b = appendBytes(b, 1, []byte(l.Name))
+	b = append(b, features...)
+	for _, k := range keys {
+		b = appendBytes(b, 3, []byte(k))
+	}
+	for _, v := range values {
+		b = appendBytes(b, 4, v)
+	}
+	return appendVarintField(b, 5, uint64(extent)), nil
+}
+
+// value returns the encoding of a value.
+func value(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return appendBytes(nil, 1, []byte(v)), nil
+	case float32:
+		return appendFixed32(appendTag(nil, 2, 5), math.Float32bits(v)), nil
+	case float64:
+		return appendFixed64(appendTag(nil, 3, 1), math.Float64bits(v)), nil
+	case int:
+		return intValue(int64(v)), nil
+	case int8:
+		return intValue(int64(v)), nil
+	case int16:
+		return intValue(int64(v)), nil
+	case int32:
+		return intValue(int64(v)), nil
+	case int64:
+		return intValue(v), nil
+	case uint:
+		return appendVarintField(nil, 5, uint64(v)), nil
+	case uint8:
+		return appendVarintField(nil, 5, uint64(v)), nil
+	case uint16:
+		return appendVarintField(nil, 5, uint64(v)), nil
+	case uint32:
+		return appendVarintField(nil, 5, uint64(v)), nil
+	case uint64:
+		return appendVarintField(nil, 5, v), nil
+	case bool:
+		if v {
+			return appendVarintField(nil, 7, 1), nil
+		}
+		return appendVarintField(nil, 7, 0), nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupported, v)
+}
+
+// intValue returns the encoding of an integer: as an sint if it is
+// negative, which takes fewer bytes, and otherwise as a uint, as the
+// specification recommends.
+func intValue(v int64) []byte {
+	if v < 0 {
+		return appendVarintField(nil, 6, zigzag(v))
+	}
+	return appendVarintField(nil, 5, uint64(v))
+}
+
+// geometries returns the commands of a clipped geometry by type, point,
+// line string or polygon, for the features of the geometry.
+func (e *encoder) geometries(g geom.Geometry) [typePolygon + 1][]uint32 {
+	var c [typePolygon + 1]cursor
+	// The points are written at once, as a point geometry must be a
+	// single command.
+	var points [][2]int64
+	var add func(g geom.Geometry)
+	add = func(g geom.Geometry) {
+		switch g := g.(type) {
+		case geom.Point:
+			if !g.IsEmpty() {
+				points = append(points, e.point(g))
+			}
+		case geom.MultiPoint:
+			for _, p := range g {
+				if !p.IsEmpty() {
+					points = append(points, e.point(p))
+				}
+			}
+		case geom.LineString:
+			c[typeLineString].line(e.line(g, false))
+		case geom.MultiLineString:
+			for _, l := range g {
+				c[typeLineString].line(e.line(l, false))
+			}
+		case geom.Polygon:
+			c[typePolygon].polygon(e, g)
+		case geom.MultiPolygon:
+			for _, p := range g {
+				c[typePolygon].polygon(e, p)
+			}
+		case geom.GeometryCollection:
+			for _, m := range g {
+				add(m)
+			}
+		}
+	}
+	add(g)
+	c[typePoint].points(points)
+	var cmds [typePolygon + 1][]uint32
+	for typ := range c {
+		cmds[typ] = c[typ].cmds
+	}
+	return cmds
+}
+
+// point returns the position of a point on the grid of the tile.
+func (e *encoder) point(p geom.Point) [2]int64 {
+	return [2]int64{int64(math.Round((p.X - e.minX) * e.scale)), int64(math.Round((e.maxY - p.Y) * e.scale))}
+}
+
+// line returns the positions of the points of a line string or ring on
+// the grid, without repeated positions, or nil if there are fewer than
+// two, or for a ring fewer than three or no area. The last point of a
+// ring, the same as the first, is dropped.
+func (e *encoder) line(l []geom.Point, ring bool) [][2]int64 {
+	var ps [][2]int64
+	for _, p := range l {
+		if q := e.point(p); len(ps) == 0 || q != ps[len(ps)-1] {
+			ps = append(ps, q)
+		}
+	}
+	if ring {
+		if len(ps) > 1 && ps[0] == ps[len(ps)-1] {
+			ps = ps[:len(ps)-1]
+		}
+		if len(ps) < 3 || area(ps) == 0 {
+			return nil
+		}
+	}
+	if len(ps) < 2 {
+		return nil
+	}
+	return ps
+}
+
+// area returns twice the signed area of a ring on the grid, positive if
+// it runs clockwise on the screen, with y increasing downward.
+func area(ps [][2]int64) int64 {
+	var a int64
+	for i, p := range ps {
+		q := ps[(i+1)%len(ps)]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+	return a
+}
+
+// cursor writes the commands of a geometry, the position of each point
+// being relative to the last.
+type cursor struct {
+	x, y int64
+	cmds []uint32
+}
+
+// command writes a command of count repetitions.
+func (c *cursor) command(id, count int) {
+	c.cmds = append(c.cmds, uint32(id&7|count<<3))
+}
+
+// to writes the positions of points.
+func (c *cursor) to(ps [][2]int64) {
+	for _, p := range ps {
+		c.cmds = append(c.cmds, uint32(zigzag(p[0]-c.x)), uint32(zigzag(p[1]-c.y)))
+		c.x, c.y = p[0], p[1]
+	}
+}
+
+// points writes points.
+func (c *cursor) points(ps [][2]int64) {
+	if len(ps) > 0 {
+		c.command(moveTo, len(ps))
+		c.to(ps)
+	}
+}
+
+// line writes a line string.
+func (c *cursor) line(ps [][2]int64) {
+	if len(ps) > 0 {
+		c.command(moveTo, 1)
+		c.to(ps[:1])
+		c.command(lineTo, len(ps)-1)
+		c.to(ps[1:])
+	}
+}
+
+// polygon writes a polygon, its exterior ring running clockwise on the
+// screen and its holes counterclockwise, dropping it if its exterior
+// ring is too small.
+func (c *cursor) polygon(e *encoder, p geom.Polygon) {
+	for i, r := range p {
+		ps := e.line(r, true)
+		if ps == nil {
+			if i == 0 {
+				return
+			}
+			continue
+		}
+		if exterior := i == 0; area(ps) > 0 != exterior {
+			slices.Reverse(ps[1:])
+		}
+		c.line(ps)
+		c.command(closePath, 1)
+	}
+}
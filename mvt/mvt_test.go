@@ -0,0 +1,539 @@
+package mvt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/tile"
+)
+
+// decodedLayer is a layer decoded from a tile.
+type decodedLayer struct {
+	version, extent uint64
+	name            string
+	keys            []string
+	values          []string // The encodings of the values, in hex
+	features        []decodedFeature
+}
+
+// decodedFeature is a feature decoded from a layer.
+type decodedFeature struct {
+	id       uint64
+	tags     []uint32
+	typ      uint64
+	geometry []uint32
+}
+
+// protoField is a field of a protocol buffer, of a varint, a fixed
+// value, or bytes.
+type protoField struct {
+	num int
+	v   uint64
+	b   []byte
+}
+
+// protoFields decodes the fields of a protocol buffer.
+func protoFields(t *testing.T, b []byte) []protoField {
+	t.Helper()
+	var fs []protoField
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad tag at %x", b)
+		}
+		b = b[n:]
+		f := protoField{num: int(tag >> 3)}
+		switch tag & 7 {
+		case 0:
+			f.v, n = binary.Uvarint(b)
+		case 1:
+			f.v, n = binary.LittleEndian.Uint64(b), 8
+		case 2:
+			var l uint64
+			l, n = binary.Uvarint(b)
+			f.b = b[n : n+int(l)]
+			n += int(l)
+		case 5:
+			f.v, n = uint64(binary.LittleEndian.Uint32(b)), 4
+		default:
+			t.Fatalf("wire type %d", tag&7)
+		}
+		b = b[n:]
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+// packed decodes a packed field of integers.
+func packed(b []byte) []uint32 {
+	var vs []uint32
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		vs = append(vs, uint32(v))
+		b = b[n:]
+	}
+	return vs
+}
+
+// decode decodes the layers of a tile.
+func decode(t *testing.T, b []byte) []decodedLayer {
+	t.Helper()
+	var ls []decodedLayer
+	for _, lf := range protoFields(t, b) {
+		if lf.num != 3 {
+			t.Fatalf("tile field %d", lf.num)
+		}
+		var l decodedLayer
+		for _, f := range protoFields(t, lf.b) {
+			switch f.num {
+			case 15:
+				l.version = f.v
+			case 1:
+				l.name = string(f.b)
+			case 2:
+				var df decodedFeature
+				for _, ff := range protoFields(t, f.b) {
+					switch ff.num {
+					case 1:
+						df.id = ff.v
+					case 2:
+						df.tags = packed(ff.b)
+					case 3:
+						df.typ = ff.v
+					case 4:
+						df.geometry = packed(ff.b)
+					}
+				}
+				l.features = append(l.features, df)
+			case 3:
+				l.keys = append(l.keys, string(f.b))
+			case 4:
+				l.values = append(l.values, hex.EncodeToString(f.b))
+			case 5:
+				l.extent = f.v
+			}
+		}
+		ls = append(ls, l)
+	}
+	return ls
+}
+
+// at returns the point at a position on the grid of a tile of an
+// extent.
+func at(t tile.Tile, extent int, x, y float64) geom.Point {
+	minX, _, maxX, maxY := t.MercatorBounds()
+	scale := float64(extent) / (maxX - minX)
+	return geom.Point{X: minX + x/scale, Y: maxY - y/scale}
+}
+
+// ats returns the points at positions on the grid of tile 0/0/0 of the
+// usual extent.
+func ats(xys ...float64) []geom.Point {
+	ps := make([]geom.Point, len(xys)/2)
+	for i := range ps {
+		ps[i] = at(tile.Tile{}, 4096, xys[2*i], xys[2*i+1])
+	}
+	return ps
+}
+
+func TestEncodeSpecification(t *testing.T) {
+	// The examples of the geometries of the specification.
+	tests := []struct {
+		name string
+		g    geom.Geometry
+		typ  uint64
+		want []uint32
+	}{
+		{"Point", ats(25, 17)[0], typePoint, []uint32{9, 50, 34}},
+		{"MultiPoint", geom.MultiPoint(ats(5, 7, 3, 2)), typePoint, []uint32{17, 10, 14, 3, 9}},
+		{"LineString", geom.LineString(ats(2, 2, 2, 10, 10, 10)), typeLineString, []uint32{9, 4, 4, 18, 0, 16, 16, 0}},
+		{
+			"MultiLineString",
+			geom.MultiLineString{ats(2, 2, 2, 10, 10, 10), ats(1, 1, 3, 5)},
+			typeLineString, []uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{"Polygon", geom.Polygon{ats(3, 6, 8, 12, 20, 34, 3, 6)}, typePolygon, []uint32{9, 6, 12, 18, 10, 12, 24, 44, 15}},
+		{
+			"MultiPolygon",
+			geom.MultiPolygon{
+				{ats(0, 0, 10, 0, 10, 10, 0, 10, 0, 0)},
+				{ats(11, 11, 20, 11, 20, 20, 11, 20, 11, 11), ats(13, 13, 13, 17, 17, 17, 17, 13, 13, 13)},
+			},
+			typePolygon,
+			[]uint32{
+				9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+				9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+				9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+			},
+		},
+	}
+	for _, tt := range tests {
+		b, err := Encode(tile.Tile{}, []Layer{{Name: "l", Features: []*Feature{{Geometry: tt.g}}}})
+		if err != nil {
+			t.Errorf("%s: Encode: %v", tt.name, err)
+			continue
+		}
+		ls := decode(t, b)
+		if len(ls) != 1 || len(ls[0].features) != 1 {
+			t.Errorf("%s: Encode = %+v, want a feature", tt.name, ls)
+			continue
+		}
+		if f := ls[0].features[0]; f.typ != tt.typ || !reflect.DeepEqual(f.geometry, tt.want) {
+			t.Errorf("%s: Encode = type %d, %v, want %d, %v", tt.name, f.typ, f.geometry, tt.typ, tt.want)
+		}
+	}
+}
+
+func TestEncodeLayers(t *testing.T) {
+	p := ats(25, 17)[0]
+	layers := []Layer{
+		{Name: "empty"},
+		{Name: "roads", Features: []*Feature{
+			{ID: 7, Geometry: p, Properties: map[string]any{"name": "A1", "lanes": 2, "toll": nil}},
+			{Geometry: nil, Properties: map[string]any{"name": "none"}},
+			{Geometry: p, Properties: map[string]any{"lanes": 2, "speed": 80.5}},
+			{Geometry: at(tile.Tile{}, 4096, -10, 17), Properties: map[string]any{"name": "B2", "width": 3}}, // Outside
+		}},
+	}
+	b, err := Encode(tile.Tile{}, layers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []decodedLayer{{
+		version: 2, extent: 4096, name: "roads",
+		keys:   []string{"lanes", "name", "speed"},
+		values: []string{"2802", "0a024131", "190000000000205440"},
+		features: []decodedFeature{
+			{id: 7, tags: []uint32{0, 0, 1, 1}, typ: typePoint, geometry: []uint32{9, 50, 34}},
+			{tags: []uint32{0, 0, 2, 2}, typ: typePoint, geometry: []uint32{9, 50, 34}},
+		},
+	}}
+	if got := decode(t, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Encode = %+v, want %+v", got, want)
+	}
+	// A tile of nothing is empty.
+	if b, err := Encode(tile.Tile{}, layers[:1]); len(b) != 0 || err != nil {
+		t.Errorf("Encode of an empty layer = %x, %v, want nothing", b, err)
+	}
+}
+
+func TestEncodeOutside(t *testing.T) {
+	// The properties of features outside the tile add no keys or values.
+	r := rand.New(rand.NewSource(91))
+	tl := tile.Tile{Z: 14, X: 8185, Y: 5448}
+	minX, minY, maxX, maxY := tl.MercatorBounds()
+	var fs []*Feature
+	in := map[string]bool{}
+	for i := range 1000 {
+		p := geom.Point{X: minX + (r.Float64()*20-10)*(maxX-minX), Y: minY + (r.Float64()*20-10)*(maxY-minY)}
+		if i%100 == 0 {
+			p = geom.Point{X: minX + r.Float64()*(maxX-minX), Y: minY + r.Float64()*(maxY-minY)}
+		}
+		name := fmt.Sprint("feature ", i)
+		if p.X >= minX && p.X <= maxX && p.Y >= minY && p.Y <= maxY {
+			in[name] = true
+		}
+		fs = append(fs, &Feature{Geometry: p, Properties: map[string]any{"name": name, fmt.Sprint("key ", i): i}})
+	}
+	b, err := Encode(tl, []Layer{{Name: "l", Features: fs}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls := decode(t, b)
+	if len(ls) != 1 || len(ls[0].features) != len(in) {
+		t.Fatalf("Encode = %+v, want %d features", ls, len(in))
+	}
+	if l := ls[0]; len(l.keys) != 1+len(in) || len(l.values) != 2*len(in) {
+		t.Errorf("Encode of %d features in the tile has %d keys and %d values, want %d and %d", len(in), len(l.keys), len(l.values), 1+len(in), 2*len(in))
+	}
+	for _, v := range ls[0].values {
+		if vb, _ := hex.DecodeString(v); vb[0] == 0x0a && !in[string(vb[2:])] {
+			t.Errorf("Encode has the value %q of a feature outside the tile", vb[2:])
+		}
+	}
+	// Nor does a property of an unsupported type.
+	f := &Feature{Geometry: at(tl, 4096, -1000, 0), Properties: map[string]any{"p": []int{1}}}
+	if b, err := Encode(tl, []Layer{{Name: "l", Features: []*Feature{f}}}); len(b) != 0 || err != nil {
+		t.Errorf("Encode of an unsupported value outside the tile = %x, %v, want nothing", b, err)
+	}
+}
+
+func TestEncodeRepeatedName(t *testing.T) {
+	p := ats(25, 17)[0]
+	layers := []Layer{
+		{Name: "roads", Features: []*Feature{{Geometry: p}}},
+		{Name: "water"},
+		{Name: "roads", Features: []*Feature{{Geometry: p}}},
+	}
+	const msg = "mvt: invalid layers: layer roads is repeated"
+	if b, err := Encode(tile.Tile{}, layers); err == nil || err.Error() != msg || !errors.Is(err, ErrInvalid) {
+		t.Errorf("Encode of a repeated name = %x, %v, want error %q", b, err, msg)
+	}
+	if _, err := Encode(tile.Tile{}, layers[:2]); err != nil {
+		t.Errorf("Encode of distinct names: %v", err)
+	}
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{"", "0a00"},
+		{"héllo", "0a0668c3a96c6c6f"},
+		{float32(1.5), "150000c03f"},
+		{-0.5, "19000000000000e0bf"},
+		{0, "2800"},
+		{int8(-1), "3001"},
+		{int16(300), "28ac02"},
+		{int32(-300), "30d704"},
+		{int64(math.MinInt64), "30ffffffffffffffffff01"},
+		{uint(1), "2801"},
+		{uint8(255), "28ff01"},
+		{uint16(65535), "28ffff03"},
+		{uint32(math.MaxUint32), "28ffffffff0f"},
+		{uint64(math.MaxUint64), "28ffffffffffffffffff01"},
+		{true, "3801"},
+		{false, "3800"},
+	}
+	for _, tt := range tests {
+		b, err := value(tt.v)
+		if got := hex.EncodeToString(b); err != nil || got != tt.want {
+			t.Errorf("value(%T %v) = %s, %v, want %s", tt.v, tt.v, got, err, tt.want)
+		}
+	}
+}
+
+func TestEncodeUnsupported(t *testing.T) {
+	tests := []struct {
+		v   any
+		msg string
+	}{
+		{[]int{1}, "mvt: unsupported value: []int: property p: layer l"},
+		{map[string]any{}, "mvt: unsupported value: map[string]interface {}: property p: layer l"},
+		{struct{}{}, "mvt: unsupported value: struct {}: property p: layer l"},
+	}
+	for _, tt := range tests {
+		f := &Feature{Geometry: geom.Point{}, Properties: map[string]any{"p": tt.v}}
+		b, err := Encode(tile.Tile{}, []Layer{{Name: "l", Features: []*Feature{f}}})
+		if err == nil || err.Error() != tt.msg || !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Encode of %T = %x, %v, want error %q", tt.v, b, err, tt.msg)
+		}
+	}
+}
+
+// encodeOne encodes a feature of a geometry in a tile and returns the
+// features decoded from it.
+func encodeOne(t *testing.T, f Format, tl tile.Tile, g geom.Geometry) []decodedFeature {
+	t.Helper()
+	b, err := f.Encode(tl, []Layer{{Name: "l", Features: []*Feature{{ID: 1, Geometry: g}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls := decode(t, b)
+	if len(ls) == 0 {
+		return nil
+	}
+	extent := uint64(f.Extent)
+	if f.Extent <= 0 {
+		extent = 4096
+	}
+	if ls[0].extent != extent {
+		t.Errorf("extent = %d, want %d", ls[0].extent, extent)
+	}
+	return ls[0].features
+}
+
+func TestEncodeGeometries(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		g      geom.Geometry
+		want   []decodedFeature
+	}{
+		{
+			"clipped to the buffer",
+			Format{Buffer: 64},
+			geom.LineString(ats(-1000, 100, 5000, 100)),
+			[]decodedFeature{{id: 1, typ: typeLineString, geometry: []uint32{9, 127, 200, 10, 8448, 0}}},
+		},
+		{
+			"clipped to the tile",
+			Format{},
+			geom.LineString(ats(-1000, 100, 5000, 100)),
+			[]decodedFeature{{id: 1, typ: typeLineString, geometry: []uint32{9, 0, 200, 10, 8192, 0}}},
+		},
+		{
+			"point in the buffer",
+			Format{Buffer: 64},
+			ats(-10, 4100)[0],
+			[]decodedFeature{{id: 1, typ: typePoint, geometry: []uint32{9, 19, 8200}}},
+		},
+		{"point outside", Format{}, ats(-10, 100)[0], nil},
+		{"empty point", Format{}, geom.EmptyPoint(), nil},
+		{
+			"multipoint partly outside",
+			Format{},
+			geom.MultiPoint(ats(1, 1, -1, 1, 2, 3)),
+			[]decodedFeature{{id: 1, typ: typePoint, geometry: []uint32{17, 2, 2, 2, 4}}},
+		},
+		{
+			"repeated positions",
+			Format{},
+			geom.LineString(ats(1, 1, 1.2, 1.1, 2, 1, 2, 1, 2, 2)),
+			[]decodedFeature{{id: 1, typ: typeLineString, geometry: []uint32{9, 2, 2, 18, 2, 0, 0, 2}}},
+		},
+		{"line of one position", Format{}, geom.LineString(ats(1, 1, 1.2, 1.3)), nil},
+		{
+			"counterclockwise exterior",
+			Format{},
+			geom.Polygon{ats(0, 0, 0, 10, 10, 10, 10, 0, 0, 0)},
+			[]decodedFeature{{id: 1, typ: typePolygon, geometry: []uint32{9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15}}},
+		},
+		{
+			"clockwise hole",
+			Format{},
+			geom.Polygon{ats(0, 0, 10, 0, 10, 10, 0, 10, 0, 0), ats(2, 2, 4, 2, 4, 4, 2, 2)},
+			[]decodedFeature{{id: 1, typ: typePolygon, geometry: []uint32{
+				9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+				9, 4, 15, 18, 4, 4, 0, 3, 15,
+			}}},
+		},
+		{"polygon smaller than a cell", Format{}, geom.Polygon{ats(1, 1, 1.2, 1, 1.2, 1.2, 1, 1)}, nil},
+		{"collinear polygon", Format{}, geom.Polygon{ats(1, 1, 2, 2, 3, 3, 1, 1)}, nil},
+		{
+			"hole smaller than a cell",
+			Format{},
+			geom.Polygon{ats(0, 0, 10, 0, 10, 10, 0, 10, 0, 0), ats(2, 2, 2.1, 2, 2.1, 2.1, 2, 2)},
+			[]decodedFeature{{id: 1, typ: typePolygon, geometry: []uint32{9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15}}},
+		},
+		{
+			"collection",
+			Format{},
+			geom.GeometryCollection{
+				geom.LineString(ats(2, 2, 2, 10)), ats(1, 1)[0],
+				geom.GeometryCollection{ats(3, 3)[0], geom.MultiLineString{ats(5, 5, 6, 6)}},
+			},
+			[]decodedFeature{
+				{typ: typePoint, geometry: []uint32{17, 2, 2, 4, 4}},
+				{typ: typeLineString, geometry: []uint32{9, 4, 4, 10, 0, 16, 9, 6, 9, 10, 2, 2}},
+			},
+		},
+		{
+			"collection of one dimension",
+			Format{},
+			geom.GeometryCollection{ats(1, 1)[0], geom.LineString(ats(-100, 2, -100, 10)), ats(3, 3)[0]},
+			[]decodedFeature{{id: 1, typ: typePoint, geometry: []uint32{17, 2, 2, 4, 4}}},
+		},
+		{
+			"extent 256",
+			Format{Extent: 256},
+			at(tile.Tile{}, 256, 25, 17),
+			[]decodedFeature{{id: 1, typ: typePoint, geometry: []uint32{9, 50, 34}}},
+		},
+	}
+	for _, tt := range tests {
+		if got := encodeOne(t, tt.format, tile.Tile{}, tt.g); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Encode = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeTile(t *testing.T) {
+	// A point is at its position in the tile which holds it, and in no
+	// other.
+	tl := tile.Tile{Z: 3, X: 5, Y: 2}
+	p := at(tl, 4096, 100, 4000)
+	want := []decodedFeature{{id: 1, typ: typePoint, geometry: []uint32{9, 200, 8000}}}
+	if got := encodeOne(t, Format{}, tl, p); !reflect.DeepEqual(got, want) {
+		t.Errorf("Encode in %v = %+v, want %+v", tl, got, want)
+	}
+	for _, o := range []tile.Tile{{Z: 3, X: 4, Y: 2}, {Z: 3, X: 5, Y: 3}, {Z: 3, X: 6, Y: 2}} {
+		if got := encodeOne(t, Format{}, o, p); got != nil {
+			t.Errorf("Encode in %v = %+v, want nothing", o, got)
+		}
+	}
+	// With a buffer, the point is also in the tile to the south.
+	want = []decodedFeature{{id: 1, typ: typePoint, geometry: []uint32{9, 200, 191}}}
+	if got := encodeOne(t, Format{Buffer: 128}, tile.Tile{Z: 3, X: 5, Y: 3}, p); !reflect.DeepEqual(got, want) {
+		t.Errorf("Encode in the buffer = %+v, want %+v", got, want)
+	}
+}
+
+// positions decodes the positions of the commands of a line string.
+func positions(cmds []uint32) [][2]int64 {
+	var ps [][2]int64
+	var x, y int64
+	unzigzag := func(u uint32) int64 { return int64(u>>1) ^ -int64(u&1) }
+	for i := 0; i < len(cmds); {
+		id, count := cmds[i]&7, int(cmds[i]>>3)
+		i++
+		if id == closePath {
+			continue
+		}
+		for range count {
+			x += unzigzag(cmds[i])
+			y += unzigzag(cmds[i+1])
+			ps = append(ps, [2]int64{x, y})
+			i += 2
+		}
+	}
+	return ps
+}
+
+func TestEncodeRandom(t *testing.T) {
+	// Random lines in the tile are written at their rounded positions.
+	rnd := rand.New(rand.NewSource(91))
+	tl := tile.Tile{Z: 10, X: 163, Y: 395}
+	for range 200 {
+		var l geom.LineString
+		var want [][2]int64
+		for range 2 + rnd.Intn(20) {
+			x, y := rnd.Float64()*4096, rnd.Float64()*4096
+			l = append(l, at(tl, 4096, x, y))
+			q := [2]int64{int64(math.Round(x)), int64(math.Round(y))}
+			if len(want) == 0 || q != want[len(want)-1] {
+				want = append(want, q)
+			}
+		}
+		fs := encodeOne(t, Format{}, tl, l)
+		if len(fs) != 1 {
+			t.Fatalf("Encode(%v) = %d features, want 1", l, len(fs))
+		}
+		if got := positions(fs[0].geometry); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Encode(%v) = %v, want %v", l, got, want)
+		}
+	}
+}
+
+func TestZigzag(t *testing.T) {
+	tests := []struct {
+		v    int64
+		want uint64
+	}{
+		{0, 0}, {-1, 1}, {1, 2}, {-2, 3}, {2147483647, 4294967294}, {-2147483648, 4294967295},
+		{math.MinInt64, math.MaxUint64},
+	}
+	for _, tt := range tests {
+		if got := zigzag(tt.v); got != tt.want {
+			t.Errorf("zigzag(%d) = %d, want %d", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestAppendPacked(t *testing.T) {
+	if b := appendPacked([]byte{1}, 4, nil); !bytes.Equal(b, []byte{1}) {
+		t.Errorf("appendPacked of nothing = %x, want 01", b)
+	}
+	if b, want := appendPacked(nil, 4, []uint32{9, 300}), []byte{0x22, 3, 9, 0xac, 2}; !bytes.Equal(b, want) {
+		t.Errorf("appendPacked = %x, want %x", b, want)
+	}
+}
@@ -0,0 +1,51 @@
+package mvt
+
+import "encoding/binary"
+
+// This file writes the few wire types of protocol buffers which vector
+// tiles use. See https://protobuf.dev/programming-guides/encoding/.
+
+// appendTag appends the tag of a field of a wire type.
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wire))
+}
+
+// appendVarintField appends a field of a varint.
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	return binary.AppendUvarint(appendTag(b, field, 0), v)
+}
+
+// appendFixed32 appends a 32-bit value.
+func appendFixed32(b []byte, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(b, v)
+}
+
+// appendFixed64 appends a 64-bit value.
+func appendFixed64(b []byte, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+// appendBytes appends a field of bytes, such as a string or a message.
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(appendTag(b, field, 2), uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendPacked appends a packed field of integers, or nothing if there
+// are none.
+func appendPacked(b []byte, field int, vs []uint32) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	var p []byte
+	for _, v := range vs {
+		p = binary.AppendUvarint(p, uint64(v))
+	}
+	return appendBytes(b, field, p)
+}
+
+// zigzag returns the zigzag encoding of a signed integer, in which
+// small negative integers are small.
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
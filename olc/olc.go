@@ -0,0 +1,325 @@
+// Package olc encodes and decodes Open Location Codes, also known as
+// Plus Codes, the short alphanumeric codes such as "849VCWC8+R9" which
+// Google Maps uses to identify places that have no street address.
+//
+// A full code identifies a rectangle on the Earth. Its first ten digits
+// are pairs of latitude and longitude digits in base 20, each pair
+// dividing the rectangle of the previous pair into 20 X 20, and any
+// further digits divide their rectangle into a grid of 5 rows and 4
+// columns. A plus sign always follows the eighth digit, and codes with
+// fewer than eight digits are padded with zeros up to it. A short code
+// omits some of the leading digits of a full code, which can be
+// recovered from a reference location nearby.
+//
+// The implementation follows the Open Location Code specification at
+// https://github.com/google/open-location-code.
+package olc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// Separator is the character which follows the eighth digit of a
+	// code.
+	Separator = '+'
+
+	// Padding is the character which pads codes with fewer than eight
+	// digits.
+	Padding = '0'
+
+	// MaxCodeLen is the largest number of digits in a code.
+	MaxCodeLen = 15
+
+	// PairCodeLen is the number of digits encoded as latitude and
+	// longitude pairs. A code of this length identifies a rectangle of
+	// roughly 14 X 14 meters.
+	PairCodeLen = 10
+
+	alphabet     = "23456789CFGHJMPQRVWX"
+	base         = 20
+	sepPos       = 8
+	gridRows     = 5
+	gridCols     = 4
+	firstDegrees = base * base // Degrees spanned by the first pair
+
+	// finalLatUnits and finalLngUnits are the number of units per degree
+	// of latitude and longitude at MaxCodeLen digits: base^3 for the
+	// pair digits times gridRows^5 or gridCols^5 for the grid digits.
+	// All encoding and decoding is done in exact integer arithmetic on
+	// these units.
+	finalLatUnits int64 = base * base * base * gridRows * gridRows * gridRows * gridRows * gridRows
+	finalLngUnits int64 = base * base * base * gridCols * gridCols * gridCols * gridCols * gridCols
+)
+
+// ErrInvalid is returned, wrapped, when a function is given a string
+// which is not a valid code, or a code of the wrong kind.
+var ErrInvalid = errors.New("olc: invalid code")
+
+// CodeArea is the rectangle identified by a full code. LatLo and LngLo
+// are its south-west corner and LatHi and LngHi its north-east corner, in
+// degrees, and Len is the number of digits in the code.
+type CodeArea struct {
+	LatLo, LngLo, LatHi, LngHi float64
+	Len                        int
+}
+
+// Center returns the latitude and longitude of the center of the area,
+// in degrees.
+func (a CodeArea) Center() (lat, lng float64) {
+	return min(90, (a.LatLo+a.LatHi)/2), min(180, (a.LngLo+a.LngHi)/2)
+}
+
+// Encode returns the full code with n digits for the rectangle which
+// contains the point with the given latitude and longitude, in degrees.
+// The digit count n must be 2, 4, 6, 8 or in the range [10, MaxCodeLen];
+// other values are rounded up to the next valid length or down to
+// MaxCodeLen. Latitudes beyond the poles are clamped and longitudes are
+// wrapped.
+//
+// The complementary function Decode performs the inverse mapping.
+func Encode(lat, lng float64, n int) string {
+	n = max(2, min(MaxCodeLen, n))
+	if n < PairCodeLen && n%2 == 1 {
+		n++
+	}
+	lat = max(-90, min(90, lat))
+	latVal := int64(math.Round((lat+90)*float64(finalLatUnits)*1e6) / 1e6)
+	lngVal := int64(math.Round((lng+180)*float64(finalLngUnits)*1e6) / 1e6)
+	latVal = min(latVal, 180*finalLatUnits-1)
+	lngVal %= 360 * finalLngUnits
+	if lngVal < 0 {
+		lngVal += 360 * finalLngUnits
+	}
+	var digits [MaxCodeLen]byte
+	for i := MaxCodeLen - 1; i >= PairCodeLen; i-- {
+		digits[i] = alphabet[latVal%gridRows*gridCols+lngVal%gridCols]
+		latVal /= gridRows
+		lngVal /= gridCols
+	}
+	for i := PairCodeLen - 2; i >= 0; i -= 2 {
+		digits[i] = alphabet[latVal%base]
+		digits[i+1] = alphabet[lngVal%base]
+		latVal /= base
+		lngVal /= base
+	}
+	var b strings.Builder
+	if n >= sepPos {
+		b.Write(digits[:sepPos])
+		b.WriteByte(Separator)
+		b.Write(digits[sepPos:n])
+	} else {
+		b.Write(digits[:n])
+		b.WriteString(strings.Repeat(string(Padding), sepPos-n))
+		b.WriteByte(Separator)
+	}
+	return b.String()
+}
+
+// Decode returns the area identified by a full code. It returns an
+// error wrapping ErrInvalid if the code is not a valid full code. Use
+// RecoverNearest to decode a short code.
+//
+// The complementary function Encode performs the inverse mapping.
+func Decode(code string) (CodeArea, error) {
+	if !IsFull(code) {
+		return CodeArea{}, fmt.Errorf("%w: %q is not a full code", ErrInvalid, code)
+	}
+	digits := stripCode(code)
+	digits = digits[:min(len(digits), MaxCodeLen)]
+	var latVal, lngVal int64
+	latSize, lngSize := finalLatUnits*firstDegrees, finalLngUnits*firstDegrees
+	for i := 0; i < len(digits); i++ {
+		d := int64(strings.IndexByte(alphabet, digits[i]))
+		switch {
+		case i >= PairCodeLen:
+			latSize /= gridRows
+			lngSize /= gridCols
+			latVal += d / gridCols * latSize
+			lngVal += d % gridCols * lngSize
+		case i%2 == 0:
+			latSize /= base
+			latVal += d * latSize
+		default:
+			lngSize /= base
+			lngVal += d * lngSize
+		}
+	}
+	return CodeArea{
+		LatLo: float64(latVal)/float64(finalLatUnits) - 90,
+		LngLo: float64(lngVal)/float64(finalLngUnits) - 180,
+		LatHi: float64(latVal+latSize)/float64(finalLatUnits) - 90,
+		LngHi: float64(lngVal+lngSize)/float64(finalLngUnits) - 180,
+		Len:   len(digits),
+	}, nil
+}
+
+// stripCode returns the digits of a valid code, in upper case, without
+// the separator and padding.
+func stripCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.Replace(code, string(Separator), "", 1)
+	if i := strings.IndexByte(code, Padding); i >= 0 {
+		code = code[:i]
+	}
+	return code
+}
+
+// IsValid reports whether code is a valid full or short code. Codes are
+// not case sensitive.
+func IsValid(code string) bool {
+	sep := strings.IndexByte(code, Separator)
+	if sep < 0 || sep != strings.LastIndexByte(code, Separator) || sep > sepPos || sep%2 == 1 {
+		return false
+	}
+	if len(code) == 1 || len(code)-sep-1 == 1 {
+		// Neither a lone separator nor a single digit after the
+		// separator is allowed.
+		return false
+	}
+	if pad := strings.IndexByte(code, Padding); pad >= 0 {
+		// Padding is only allowed in full codes which end at the
+		// separator, and must form a single run of even length
+		// starting at an even position.
+		if sep < sepPos || pad == 0 || pad%2 == 1 || sep != len(code)-1 {
+			return false
+		}
+		if strings.Trim(code[pad:sep], string(Padding)) != "" || (sep-pad)%2 == 1 {
+			return false
+		}
+	}
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		if c == Separator || c == Padding {
+			continue
+		}
+		if strings.IndexByte(alphabet, upper(c)) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// upper returns the upper case form of an ASCII letter.
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// IsShort reports whether code is a valid short code, one which omits
+// some leading digits, so that fewer than eight digits precede the
+// separator.
+func IsShort(code string) bool {
+	return IsValid(code) && strings.IndexByte(code, Separator) < sepPos
+}
+
+// IsFull reports whether code is a valid full code, one which
+// identifies an area on the Earth without a reference location.
+func IsFull(code string) bool {
+	if !IsValid(code) || IsShort(code) {
+		return false
+	}
+	// The first latitude digit must not exceed 90 degrees north and the
+	// first longitude digit must not exceed 180 degrees east.
+	lat := strings.IndexByte(alphabet, upper(code[0])) * base
+	if lat >= 180 {
+		return false
+	}
+	if len(code) > 1 {
+		lng := strings.IndexByte(alphabet, upper(code[1])) * base
+		if lng >= 360 {
+			return false
+		}
+	}
+	return true
+}
+
+// Shorten removes as many leading digits from a full code as can be
+// reliably recovered by RecoverNearest from the reference location with
+// the given latitude and longitude, in degrees. The reference location
+// must be well within the area of the shortened code, so at most eight
+// digits are removed and none at all if the reference is too far away.
+// It returns an error wrapping ErrInvalid if code is not a full code or
+// ends in padding.
+func Shorten(code string, lat, lng float64) (string, error) {
+	area, err := Decode(code)
+	if err != nil {
+		return "", err
+	}
+	if strings.IndexByte(code, Padding) >= 0 {
+		return "", fmt.Errorf("%w: %q is padded and cannot be shortened", ErrInvalid, code)
+	}
+	code = strings.ToUpper(code)
+	centerLat, centerLng := area.Center()
+	lat = max(-90, min(90, lat))
+	rng := max(math.Abs(centerLat-lat), math.Abs(wrapLng(centerLng-lng)))
+	for i := 4; i >= 1; i-- {
+		// Only shorten if the reference is within 30% of the size of
+		// the area which the removed digits identify, to be safe.
+		if rng < pairResolution(2*i)*0.3 {
+			return code[2*i:], nil
+		}
+	}
+	return code, nil
+}
+
+// RecoverNearest returns the full code nearest to the reference location
+// with the given latitude and longitude, in degrees, which ends in the
+// digits of the short code. If code is already a full code, it is
+// returned in upper case. It returns an error wrapping ErrInvalid if code
+// is neither a short nor a full code.
+func RecoverNearest(code string, lat, lng float64) (string, error) {
+	if IsFull(code) {
+		return strings.ToUpper(code), nil
+	}
+	if !IsShort(code) {
+		return "", fmt.Errorf("%w: %q is not a short code", ErrInvalid, code)
+	}
+	lat = max(-90, min(90, lat))
+	lng = wrapLng(lng)
+	missing := sepPos - strings.IndexByte(code, Separator)
+	resolution := pairResolution(missing)
+	half := resolution / 2
+	area, err := Decode(Encode(lat, lng, MaxCodeLen)[:missing] + strings.ToUpper(code))
+	if err != nil {
+		return "", err
+	}
+	// The recovered area is the one nearest to the reference in the grid
+	// of areas of the missing digits, so move it by one cell if the
+	// reference is nearer to a neighboring cell.
+	centerLat, centerLng := area.Center()
+	switch {
+	case lat+half < centerLat && centerLat-resolution >= -90:
+		centerLat -= resolution
+	case lat-half > centerLat && centerLat+resolution <= 90:
+		centerLat += resolution
+	}
+	switch {
+	case lng+half < centerLng:
+		centerLng -= resolution
+	case lng-half > centerLng:
+		centerLng += resolution
+	}
+	return Encode(centerLat, centerLng, area.Len), nil
+}
+
+// pairResolution returns the size in degrees of the area identified by
+// a code of n pair digits, where n is even.
+func pairResolution(n int) float64 {
+	return math.Pow(base, float64(2-n/2))
+}
+
+// wrapLng wraps a longitude, or a difference of longitudes, into the
+// range [-180, 180).
+func wrapLng(lng float64) float64 {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return lng - 180
+}
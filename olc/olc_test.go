@@ -0,0 +1,196 @@
+package olc
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// The test vectors are from the test data of the Open Location Code
+// specification.
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		n        int
+		code     string
+	}{
+		{20.375, 2.775, 6, "7FG49Q00+"},
+		{20.3700625, 2.7821875, 10, "7FG49QCJ+2V"},
+		{20.3701125, 2.782234375, 11, "7FG49QCJ+2VX"},
+		{47.0000625, 8.0000625, 10, "8FVC2222+22"},
+		{-41.2730625, 174.7859375, 10, "4VCPPQGP+Q9"},
+		{0.5, -179.5, 4, "62G20000+"},
+		{-89.5, -179.5, 4, "22220000+"},
+		{20.5, 2.5, 4, "7FG40000+"},
+		{-89.9999375, -179.9999375, 10, "22222222+22"},
+		{0.5, 179.5, 4, "6VGX0000+"},
+		{1, 1, 11, "6FH32222+222"},
+		{90, 1, 4, "CFX30000+"},
+		{92, 1, 4, "CFX30000+"},
+		{1, 180, 4, "62H20000+"},
+		{1, 181, 4, "62H30000+"},
+		// Odd lengths below the pair length are rounded up, and lengths
+		// outside the valid range are clamped.
+		{20.375, 2.775, 5, "7FG49Q00+"},
+		{20.375, 2.775, -3, "7F000000+"},
+		{47.365590, 8.524997, 10, "8FVC9G8F+6X"},
+	}
+	for _, tt := range tests {
+		if code := Encode(tt.lat, tt.lng, tt.n); code != tt.code {
+			t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.n, code, tt.code)
+		}
+	}
+	if code := Encode(20.3701135, 2.78223535156, 20); len(code) != MaxCodeLen+1 {
+		t.Errorf("Encode(..., 20) = %q, want %d digits", code, MaxCodeLen)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		code string
+		want CodeArea
+	}{
+		{"7FG49Q00+", CodeArea{20.35, 2.75, 20.4, 2.8, 6}},
+		{"7FG49QCJ+2V", CodeArea{20.37, 2.782125, 20.370125, 2.78225, 10}},
+		{"7fg49qcj+2vx", CodeArea{20.3701, 2.78221875, 20.370125, 2.78225, 11}},
+		{"CFX30000+", CodeArea{89, 1, 90, 2, 4}},
+		{"22222222+22", CodeArea{-90, -180, -89.999875, -179.999875, 10}},
+	}
+	for _, tt := range tests {
+		a, err := Decode(tt.code)
+		if err != nil || a.Len != tt.want.Len || math.Abs(a.LatLo-tt.want.LatLo) > 1e-10 || math.Abs(a.LngLo-tt.want.LngLo) > 1e-10 ||
+			math.Abs(a.LatHi-tt.want.LatHi) > 1e-10 || math.Abs(a.LngHi-tt.want.LngHi) > 1e-10 {
+			t.Errorf("Decode(%q) = (%+v, %v), want %+v", tt.code, a, err, tt.want)
+		}
+	}
+	for _, code := range []string{"", "+", "9G8F+6X", "8FWC2345+G", "X2000000+"} {
+		if _, err := Decode(code); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) error = %v, want %v", code, err, ErrInvalid)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(28))
+	for range 10000 {
+		lat, lng := r.Float64()*180-90, r.Float64()*360-180
+		n := []int{2, 4, 6, 8, 10, 11, 12, 13, 14, 15}[r.Intn(10)]
+		code := Encode(lat, lng, n)
+		a, err := Decode(code)
+		if err != nil || a.Len != n {
+			t.Fatalf("Decode(Encode(%v, %v, %d)) = (%+v, %v)", lat, lng, n, a, err)
+		}
+		if lat < a.LatLo-1e-9 || lat > a.LatHi+1e-9 || lng < a.LngLo-1e-9 || lng > a.LngHi+1e-9 {
+			t.Fatalf("Decode(%q) = %+v, which does not contain (%v, %v)", code, a, lat, lng)
+		}
+		if c := Encode(lat, lng, n); !IsFull(c) || IsShort(c) {
+			t.Fatalf("Encode(%v, %v, %d) = %q, not a full code", lat, lng, n, c)
+		}
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		code                 string
+		valid, short, isFull bool
+	}{
+		{"8FWC2345+G6", true, false, true},
+		{"8FWC2345+G6G", true, false, true},
+		{"8fwc2345+", true, false, true},
+		{"8FWCX400+", true, false, true},
+		{"WC2345+G6g", true, true, false},
+		{"2345+G6", true, true, false},
+		{"45+G6", true, true, false},
+		{"+G6", true, true, false},
+		{"849VGJQF+VX7QR3J", true, false, true},
+		{"849VGJQF+VX7QR3JW", true, false, true},
+		{"G+", false, false, false},
+		{"+", false, false, false},
+		{"8FWC2345+G", false, false, false},
+		{"8FWC2_45+G6", false, false, false},
+		{"8FWC2η45+G6", false, false, false},
+		{"8FWC2345+G6+", false, false, false},
+		{"8FWC2345G6+", false, false, false},
+		{"8FWC2300+G6", false, false, false},
+		{"WC2300+G6g", false, false, false},
+		{"WC2345+G", false, false, false},
+		{"WC2300+", false, false, false},
+		{"849VGJQF+VX7QR3U", false, false, false},
+		{"X2000000+", true, false, false},
+	}
+	for _, tt := range tests {
+		if v, s, f := IsValid(tt.code), IsShort(tt.code), IsFull(tt.code); v != tt.valid || s != tt.short || f != tt.isFull {
+			t.Errorf("%q: IsValid, IsShort, IsFull = %v, %v, %v, want %v, %v, %v", tt.code, v, s, f, tt.valid, tt.short, tt.isFull)
+		}
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		code     string
+		lat, lng float64
+		short    string
+	}{
+		{"9C3W9QCJ+2VX", 51.3701125, -1.217765625, "+2VX"},
+		{"9C3W9QCJ+2VX", 51.3708675, -1.217765625, "CJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.3693575, -1.217765625, "CJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.3701125, -1.218520625, "CJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.3701125, -1.217010625, "CJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.3852125, -1.217765625, "9QCJ+2VX"},
+		{"9C3W9QCJ+2VX", 51.3550125, -1.217765625, "9QCJ+2VX"},
+		{"8FJFW222+", 42.899, 9.012, "22+"},
+		{"796RXG22+", 14.95125, -23.5001, "22+"},
+		{"8FVC9G8F+6X", 47.4, 8.6, "9G8F+6X"},
+		{"8FVC9G8F+6X", -47.4, 8.6, "8FVC9G8F+6X"},
+	}
+	for _, tt := range tests {
+		if tt.code[len(tt.code)-1] == Separator {
+			// Padded codes cannot be shortened, but their short forms
+			// can still be recovered; see TestRecoverNearest.
+			continue
+		}
+		short, err := Shorten(tt.code, tt.lat, tt.lng)
+		if short != tt.short || err != nil {
+			t.Errorf("Shorten(%q, %v, %v) = (%q, %v), want %q", tt.code, tt.lat, tt.lng, short, err, tt.short)
+		}
+	}
+	for _, tt := range tests {
+		full, err := RecoverNearest(tt.short, tt.lat, tt.lng)
+		if full != tt.code || err != nil {
+			t.Errorf("RecoverNearest(%q, %v, %v) = (%q, %v), want %q", tt.short, tt.lat, tt.lng, full, err, tt.code)
+		}
+	}
+	for _, code := range []string{"9G8F+6X", "8FWC0000+", "+"} {
+		if _, err := Shorten(code, 47, 8); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Shorten(%q) error = %v, want %v", code, err, ErrInvalid)
+		}
+	}
+}
+
+func TestRecoverNearest(t *testing.T) {
+	tests := []struct {
+		short    string
+		lat, lng float64
+		full     string
+	}{
+		{"9qcj+2vx", 51.3708675, -1.217765625, "9C3W9QCJ+2VX"},
+		{"8FVC9G8F+6x", 0, 0, "8FVC9G8F+6X"},
+		// The nearest match may be across the antimeridian or the prime
+		// meridian from the reference.
+		{"2222+22", 1.0001, 179.9999, "62H22222+22"},
+		{"XXXX+XX", 89.99, 0, "CCXXXXXX+XX"},
+	}
+	for _, tt := range tests {
+		full, err := RecoverNearest(tt.short, tt.lat, tt.lng)
+		if full != tt.full || err != nil {
+			t.Errorf("RecoverNearest(%q, %v, %v) = (%q, %v), want %q", tt.short, tt.lat, tt.lng, full, err, tt.full)
+		}
+	}
+	for _, code := range []string{"", "+", "qcj+2vx", "2345+G", "8FWC2345+G"} {
+		if _, err := RecoverNearest(code, 0, 0); !errors.Is(err, ErrInvalid) {
+			t.Errorf("RecoverNearest(%q) error = %v, want %v", code, err, ErrInvalid)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package peano maps between two-dimensional cell positions and
+// distances along a discrete Peano curve, the base-3 space-filling
+// curve which divides a square into 3 X 3 sub-squares at each level.
+package peano
+
+// XYToD converts a two-dimensional cell position with coordinates
+// (x, y) to a one-dimensional distance (d) along a discrete Peano
+// curve. The curve is constructed by dividing a square into n X n
+// cells.
+//
+// The cell count n must be a power of 3, and the coordinates x and y
+// must range from (0, 0) representing the cell at the lower left-hand
+// corner of the square, to (n-1, n-1) representing the cell at the
+// upper right-hand corner of the square.
+//
+// The return value d is a number in the range [0, n^2-1]. The curve
+// starts in the lower left-hand cell, ends in the upper right-hand
+// cell, and consecutive distances always map to cells which share an
+// edge.
+//
+// The complementary function DToXY performs the inverse mapping from
+// one-dimensional distance (d) back to two-dimensional position (x, y).
+func XYToD(n, x, y int) (d int) {
+	var odd, even int // Sums of the odd and even base-3 digits of d so far
+	for s := n / 3; s > 0; s /= 3 {
+		a, b := x/s%3, y/s%3
+		if even%2 == 1 {
+			a = 2 - a
+		}
+		odd += a
+		if odd%2 == 1 {
+			b = 2 - b
+		}
+		even += b
+		d = d*9 + a*3 + b
+	}
+	return
+}
+
+// DToXY converts a one-dimensional distance (d) along a discrete Peano
+// curve to a two-dimensional cell position with coordinates (x, y).
+//
+// The cell count n must be a power of 3, and the distance d must range
+// from 0, representing the cell in the lower left-hand corner of the
+// square, to n^2-1, representing the cell in the upper right-hand
+// corner of the square.
+//
+// The return value (x, y), where x and y are between 0 and n-1, is the
+// position of the cell to which d corresponds.
+//
+// The complementary function XYToD performs the inverse transformation.
+func DToXY(n, d int) (x, y int) {
+	var odd, even int // Sums of the odd and even base-3 digits of d so far
+	for s := n / 3; s > 0; s /= 3 {
+		q := d / (s * s) % 9
+		a, b := q/3, q%3
+		if even%2 == 1 {
+			a = 2 - a
+		}
+		odd += q / 3
+		if odd%2 == 1 {
+			b = 2 - b
+		}
+		even += q % 3
+		x, y = x*3+a, y*3+b
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package peano
+
+import "testing"
+
+func TestXYToD(t *testing.T) {
+	tests := []struct {
+		n, x, y, d int
+	}{
+		{1, 0, 0, 0},
+		// At order 1 the curve goes up, down and up the columns.
+		{3, 0, 0, 0},
+		{3, 0, 1, 1},
+		{3, 0, 2, 2},
+		{3, 1, 2, 3},
+		{3, 1, 1, 4},
+		{3, 1, 0, 5},
+		{3, 2, 0, 6},
+		{3, 2, 1, 7},
+		{3, 2, 2, 8},
+		{9, 2, 3, 9},
+		{9, 0, 5, 17},
+		{9, 0, 6, 18},
+		{9, 4, 4, 40},
+		{9, 8, 8, 80},
+	}
+	for _, tt := range tests {
+		if d := XYToD(tt.n, tt.x, tt.y); d != tt.d {
+			t.Errorf("XYToD(%d, %d, %d) = %d, want %d", tt.n, tt.x, tt.y, d, tt.d)
+		}
+		if x, y := DToXY(tt.n, tt.d); x != tt.x || y != tt.y {
+			t.Errorf("DToXY(%d, %d) = (%d, %d), want (%d, %d)", tt.n, tt.d, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestDToXYCurve(t *testing.T) {
+	// Every cell is visited once, consecutive cells share an edge, and the
+	// curve ends in the upper right-hand cell.
+	for n := 1; n <= 243; n *= 3 {
+		seen := make([]bool, n*n)
+		var px, py int
+		for d := range n * n {
+			x, y := DToXY(n, d)
+			if x < 0 || y < 0 || x >= n || y >= n || seen[y*n+x] {
+				t.Fatalf("DToXY(%d, %d) = (%d, %d), outside or visited twice", n, d, x, y)
+			}
+			seen[y*n+x] = true
+			if d > 0 && abs(x-px)+abs(y-py) != 1 {
+				t.Fatalf("DToXY(%d, %d) = (%d, %d), not adjacent to (%d, %d)", n, d, x, y, px, py)
+			}
+			if d2 := XYToD(n, x, y); d2 != d {
+				t.Fatalf("XYToD(DToXY(%d, %d)) = %d", n, d, d2)
+			}
+			px, py = x, y
+		}
+		if px != n-1 || py != n-1 {
+			t.Errorf("curve of %d ends at (%d, %d)", n, px, py)
+		}
+	}
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,106 @@
+// Package polyline encodes and decodes line strings in the Encoded
+// Polyline Algorithm Format of Google, in which the routing services of
+// Google, as well as OSRM and Valhalla, return routes.
+//
+// Each latitude and longitude, in that order, is rounded to a number of
+// decimal digits, 5 for Google and 6 for OSRM and Valhalla when asked
+// for polyline6, and written as the difference from the previous one, in
+// groups of five bits encoded as printable ASCII characters. The format
+// is described at
+// https://developers.google.com/maps/documentation/utilities/polylinealgorithm.
+package polyline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// ErrInvalid is returned, wrapped, by Decode when a string is not an
+// encoded polyline.
+var ErrInvalid = errors.New("polyline: invalid encoded polyline")
+
+// Encode returns the encoded polyline of a line string of longitudes X
+// and latitudes Y in degrees, rounded to precision decimal digits.
+// Empty points are skipped.
+func Encode(l geom.LineString, precision int) string {
+	scale := math.Pow10(precision)
+	var b []byte
+	var last [2]int64
+	for _, p := range l {
+		if p.IsEmpty() {
+			continue
+		}
+		for i, v := range [2]float64{p.Y, p.X} {
+			n := int64(math.Round(v * scale))
+			b = appendValue(b, n-last[i])
+			last[i] = n
+		}
+	}
+	return string(b)
+}
+
+// appendValue appends the encoding of a difference to b.
+func appendValue(b []byte, d int64) []byte {
+	u := uint64(d<<1) ^ uint64(d>>63)
+	for u >= 0x20 {
+		b = append(b, byte(0x20|u&0x1f)+63)
+		u >>= 5
+	}
+	return append(b, byte(u)+63)
+}
+
+// Decode returns the line string of an encoded polyline, of longitudes X
+// and latitudes Y in degrees, with the precision with which it was
+// encoded. It returns an error wrapping ErrInvalid if the string has a
+// character outside the range of the encoding, or ends in the middle of
+// a value or a point.
+func Decode(s string, precision int) (geom.LineString, error) {
+	var l geom.LineString
+	var last [2]int64
+	for i := 0; i < len(s); {
+		for j := range last {
+			if i == len(s) {
+				return nil, fmt.Errorf("%w: %q ends in the middle of a point", ErrInvalid, s)
+			}
+			d, n, err := value(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q %s at offset %d", ErrInvalid, s, err, i+n)
+			}
+			last[j] += d
+			i += n
+		}
+		// Divide by a power of ten which is exact, rather than multiply by
+		// its inexact inverse, for the nearest float64 to the decimal.
+		lat, lng := float64(last[0]), float64(last[1])
+		if precision >= 0 {
+			lat, lng = lat/math.Pow10(precision), lng/math.Pow10(precision)
+		} else {
+			lat, lng = lat*math.Pow10(-precision), lng*math.Pow10(-precision)
+		}
+		l = append(l, geom.Point{X: lng, Y: lat})
+	}
+	return l, nil
+}
+
+// value decodes the difference at the start of s, returning it and the
+// number of bytes it takes, or an error and the offset of the error.
+func value(s string) (int64, int, error) {
+	var u uint64
+	for i := 0; i < len(s); i++ {
+		c := uint64(s[i]) - 63
+		if c > 0x3f {
+			return 0, i, fmt.Errorf("has invalid character %q", s[i])
+		}
+		if i >= 13 {
+			return 0, i, errors.New("has a value too large")
+		}
+		u |= (c & 0x1f) << (5 * i)
+		if c < 0x20 {
+			return int64(u>>1) ^ -int64(u&1), i + 1, nil
+		}
+	}
+	return 0, len(s), errors.New("ends in the middle of a value")
+}
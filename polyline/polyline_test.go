@@ -0,0 +1,105 @@
+package polyline
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// The example of the documentation of the format, and the same points
+// encoded as polyline6 by the polyline library of Mapbox.
+var example = geom.LineString{{X: -120.2, Y: 38.5}, {X: -120.95, Y: 40.7}, {X: -126.453, Y: 43.252}}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		l         geom.LineString
+		precision int
+		s         string
+	}{
+		{example, 5, "_p~iF~ps|U_ulLnnqC_mqNvxq`@"},
+		{example, 6, "_izlhA~rlgdF_{geC~ywl@_kwzCn`{nI"},
+		{geom.LineString{{X: 0, Y: 0}}, 5, "??"},
+		{geom.LineString{{X: 0.00001, Y: -0.00001}}, 5, "@A"},
+		// The latitude and longitude of -179.9832104, from the
+		// documentation of the algorithm.
+		{geom.LineString{{X: 0, Y: -179.9832104}}, 5, "`~oia@?"},
+		{geom.LineString{{X: 1, Y: 2}, geom.EmptyPoint(), {X: 1, Y: 2}}, 5, "_seK_ibE??"},
+		{nil, 5, ""},
+	}
+	for _, tt := range tests {
+		if s := Encode(tt.l, tt.precision); s != tt.s {
+			t.Errorf("Encode(%v, %d) = %q, want %q", tt.l, tt.precision, s, tt.s)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		s         string
+		precision int
+		l         geom.LineString
+	}{
+		{"_p~iF~ps|U_ulLnnqC_mqNvxq`@", 5, example},
+		{"_izlhA~rlgdF_{geC~ywl@_kwzCn`{nI", 6, example},
+		// Read with the wrong precision, the coordinates are ten times
+		// too large.
+		{"_p~iF~ps|U", 4, geom.LineString{{X: -1202, Y: 385}}},
+		{"_p~iF~ps|U", -1, geom.LineString{{X: -120200000, Y: 38500000}}},
+		{"??", 5, geom.LineString{{X: 0, Y: 0}}},
+		{"", 5, nil},
+	}
+	for _, tt := range tests {
+		if l, err := Decode(tt.s, tt.precision); err != nil || !reflect.DeepEqual(l, tt.l) {
+			t.Errorf("Decode(%q, %d) = %v, %v, want %v", tt.s, tt.precision, l, err, tt.l)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		s   string
+		err string
+	}{
+		{"_p~iF~ps|U_ulLnnqC_mqN", `polyline: invalid encoded polyline: "_p~iF~ps|U_ulLnnqC_mqN" ends in the middle of a point`},
+		{"_p~iF~ps|U_ulLnnqC_mqNvxq", `polyline: invalid encoded polyline: "_p~iF~ps|U_ulLnnqC_mqNvxq" ends in the middle of a value at offset 25`},
+		{"_p~iF ", `polyline: invalid encoded polyline: "_p~iF " has invalid character ' ' at offset 5`},
+		{"\x7f?", `polyline: invalid encoded polyline: "\x7f?" has invalid character '\x7f' at offset 0`},
+		{"~~~~~~~~~~~~~~?", `polyline: invalid encoded polyline: "~~~~~~~~~~~~~~?" has a value too large at offset 13`},
+	}
+	for _, tt := range tests {
+		l, err := Decode(tt.s, 5)
+		if !errors.Is(err, ErrInvalid) || err.Error() != tt.err || l != nil {
+			t.Errorf("Decode(%q) = %v, %v, want %s", tt.s, l, err, tt.err)
+		}
+	}
+}
+
+func TestEncodeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(85))
+	for range 1000 {
+		precision := 5 + r.Intn(2)
+		l := make(geom.LineString, 1+r.Intn(20))
+		for i := range l {
+			l[i] = geom.Point{X: r.Float64()*360 - 180, Y: r.Float64()*180 - 90}
+		}
+		d, err := Decode(Encode(l, precision), precision)
+		if err != nil || len(d) != len(l) {
+			t.Fatalf("Decode(Encode(%v, %d)) = %v, %v", l, precision, d, err)
+		}
+		// The points are rounded to the precision, and read back as the
+		// nearest float64 to the rounded decimal.
+		scale := math.Pow10(precision)
+		for i, p := range d {
+			if q := (geom.Point{X: math.Round(l[i].X*scale) / scale, Y: math.Round(l[i].Y*scale) / scale}); p != q {
+				t.Fatalf("Decode(Encode(%v, %d)) point %d = %v, want %v", l, precision, i, p, q)
+			}
+		}
+		if s := Encode(d, precision); s != Encode(l, precision) {
+			t.Fatalf("Encode(Decode(%q)) = %q", Encode(l, precision), s)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package proj
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// AlbersEqualArea is the ellipsoidal Albers equal area conic projection,
+// EPSG method 9822, which preserves areas, so that areas computed in the
+// plane are true areas on the ellipsoid. It is used for statistical maps
+// of regions of large east-west extent, such as the contiguous United
+// States with standard parallels at 29.5 and 45.5 degrees north.
+//
+// The scale is true along the two standard parallels, and the
+// distortion of shapes grows away from them. The formulae are those of
+// John P. Snyder, "Map Projections: A Working Manual", USGS Professional
+// Paper 1395 (1987).
+type AlbersEqualArea struct {
+	// Ellipsoid is the ellipsoid projected. The zero value stands for
+	// WGS84.
+	Ellipsoid geodesy.Ellipsoid
+	// CentralMeridian and LatitudeOfOrigin are the longitude and
+	// latitude in degrees of the origin of the projection, which has
+	// planar coordinates (FalseEasting, FalseNorthing).
+	CentralMeridian, LatitudeOfOrigin float64
+	// StandardParallel1 and StandardParallel2 are the latitudes in
+	// degrees of the standard parallels, which must not be opposite. If
+	// they are equal the cone touches the ellipsoid along the single
+	// standard parallel.
+	StandardParallel1, StandardParallel2 float64
+	// FalseEasting and FalseNorthing are the planar coordinates of the
+	// origin in meters.
+	FalseEasting, FalseNorthing float64
+}
+
+// cone returns the constants of the projection: the semi-major axis a
+// and eccentricity e of the ellipsoid, the cone constant n, the constant
+// c, such that the radius of the parallel with area parameter q is
+// a*sqrt(c-n*q)/n, and the radius rho0 of the latitude of origin.
+func (l AlbersEqualArea) cone() (a, e, n, c, rho0 float64) {
+	ell := orWGS84(l.Ellipsoid)
+	a, e = ell.A, math.Sqrt(ell.E2())
+	phi1, phi2 := l.StandardParallel1*degToRad, l.StandardParallel2*degToRad
+	m1, q1 := msfn(phi1, e), qsfn(phi1, e)
+	if phi1 == phi2 {
+		n = math.Sin(phi1)
+	} else {
+		m2, q2 := msfn(phi2, e), qsfn(phi2, e)
+		n = (m1*m1 - m2*m2) / (q2 - q1)
+	}
+	c = m1*m1 + n*q1
+	rho0 = a * math.Sqrt(c-n*qsfn(l.LatitudeOfOrigin*degToRad, e)) / n
+	return
+}
+
+// Forward returns the coordinates of a position in the projection. The
+// longitude is taken relative to the central meridian, wrapped into the
+// range [-180, 180).
+func (l AlbersEqualArea) Forward(p geodesy.LatLng) (x, y float64) {
+	a, e, n, c, rho0 := l.cone()
+	rho := a * math.Sqrt(max(0, c-n*qsfn(p.Lat*degToRad, e))) / n
+	theta := n * wrapLng(p.Lng-l.CentralMeridian) * degToRad
+	sinTheta, cosTheta := math.Sincos(theta)
+	return l.FalseEasting + rho*sinTheta, l.FalseNorthing + rho0 - rho*cosTheta
+}
+
+// Inverse returns the position of the point with the given coordinates
+// in the projection. The longitude is wrapped into the range
+// [-180, 180).
+func (l AlbersEqualArea) Inverse(x, y float64) geodesy.LatLng {
+	a, e, n, c, rho0 := l.cone()
+	dx, dy := x-l.FalseEasting, rho0-(y-l.FalseNorthing)
+	if n < 0 {
+		dx, dy = -dx, -dy
+	}
+	rho := math.Hypot(dx, dy)
+	theta := math.Atan2(dx, dy)
+	r := rho * n / a
+	q := (c - r*r) / n
+	return geodesy.LatLng{
+		Lat: phiFromQs(q, e) / degToRad,
+		Lng: wrapLng(l.CentralMeridian + theta/n/degToRad),
+	}
+}
+
+// qsfn returns Snyder's function q of the latitude phi on an ellipsoid
+// with eccentricity e, which is proportional to the area between the
+// equator and the parallel, and is 2 sin(phi) on the sphere.
+func qsfn(phi, e float64) float64 {
+	sinPhi := math.Sin(phi)
+	if e == 0 {
+		return 2 * sinPhi
+	}
+	es := e * sinPhi
+	return (1 - e*e) * (sinPhi/(1-es*es) + math.Atanh(es)/e)
+}
+
+// phiFromQs is the inverse of qsfn, found by Newton's method. Values of
+// q beyond those of the poles give the poles.
+func phiFromQs(q, e float64) float64 {
+	qp := qsfn(math.Pi/2, e)
+	if math.Abs(q) >= qp {
+		return math.Copysign(math.Pi/2, q)
+	}
+	phi := math.Asin(q / 2)
+	if e == 0 {
+		return phi
+	}
+	e2 := e * e
+	for range 15 {
+		sinPhi, cosPhi := math.Sincos(phi)
+		es := e * sinPhi
+		d := (1 - es*es) * (1 - es*es) / (2 * cosPhi) *
+			(q/(1-e2) - sinPhi/(1-es*es) + math.Log((1-es)/(1+es))/(2*e))
+		phi += d
+		if math.Abs(d) < 1e-15 {
+			break
+		}
+	}
+	return phi
+}
@@ -0,0 +1,89 @@
+package proj
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+func TestAlbersEqualArea(t *testing.T) {
+	tests := []struct {
+		l    AlbersEqualArea
+		p    geodesy.LatLng
+		x, y float64
+	}{
+		// Snyder, "Map Projections: A Working Manual", page 292.
+		{AlbersEqualArea{
+			Ellipsoid: geodesy.Clarke1866, CentralMeridian: -96, LatitudeOfOrigin: 23,
+			StandardParallel1: 29.5, StandardParallel2: 45.5,
+		}, geodesy.LatLng{Lat: 35, Lng: -75}, 1885472.7, 1535925.0},
+		{AlbersEqualArea{StandardParallel1: 30, StandardParallel2: 60}, geodesy.LatLng{Lat: 0, Lng: 0}, 0, 0},
+		{AlbersEqualArea{CentralMeridian: 10, StandardParallel1: 30, StandardParallel2: 60, FalseEasting: 100, FalseNorthing: 200}, geodesy.LatLng{Lat: 0, Lng: 10}, 100, 200},
+	}
+	for _, tt := range tests {
+		x, y := tt.l.Forward(tt.p)
+		// Snyder gives the coordinates to a decimeter.
+		if math.Abs(x-tt.x) > 0.05 || math.Abs(y-tt.y) > 0.05 {
+			t.Errorf("%+v.Forward(%v) = (%.3f, %.3f), want (%.3f, %.3f)", tt.l, tt.p, x, y, tt.x, tt.y)
+		}
+		if p := tt.l.Inverse(tt.x, tt.y); math.Abs(p.Lat-tt.p.Lat) > 1e-6 || math.Abs(p.Lng-tt.p.Lng) > 1e-6 {
+			t.Errorf("%+v.Inverse(%.3f, %.3f) = %v, want %v", tt.l, tt.x, tt.y, p, tt.p)
+		}
+	}
+}
+
+func TestAlbersEqualAreaRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(54))
+	cones := []AlbersEqualArea{
+		{CentralMeridian: -96, LatitudeOfOrigin: 23, StandardParallel1: 29.5, StandardParallel2: 45.5},
+		{Ellipsoid: geodesy.GRS80, CentralMeridian: 132, StandardParallel1: -18, StandardParallel2: -36},
+		{CentralMeridian: 20, LatitudeOfOrigin: 50, StandardParallel1: 50, StandardParallel2: 50},
+	}
+	for _, l := range cones {
+		ell := orWGS84(l.Ellipsoid)
+		e := math.Sqrt(ell.E2())
+		for range 1000 {
+			p := geodesy.LatLng{Lat: math.Copysign(r.Float64()*85, l.StandardParallel1), Lng: l.CentralMeridian + r.Float64()*120 - 60}
+			x, y := l.Forward(p)
+			if q := l.Inverse(x, y); math.Abs(q.Lat-p.Lat) > 1e-9 || math.Abs(wrapLng(q.Lng-p.Lng)) > 1e-9 {
+				t.Fatalf("%+v: Inverse(Forward(%v)) = %v", l, p, q)
+			}
+			// A small cell has the same area in the plane as on the
+			// ellipsoid, where it is a²/2·Δλ·Δq.
+			const d = 0.01
+			corners := []geodesy.LatLng{p, {Lat: p.Lat, Lng: p.Lng + d}, {Lat: p.Lat + d, Lng: p.Lng + d}, {Lat: p.Lat + d, Lng: p.Lng}}
+			var plane float64
+			for k, c := range corners {
+				x1, y1 := l.Forward(c)
+				x2, y2 := l.Forward(corners[(k+1)%4])
+				plane += x1*y2 - x2*y1
+			}
+			plane /= 2
+			ground := ell.A * ell.A / 2 * d * degToRad * (qsfn((p.Lat+d)*degToRad, e) - qsfn(p.Lat*degToRad, e))
+			if math.Abs(plane-ground)/ground > 1e-6 {
+				t.Fatalf("%+v: area of cell at %v = %v, want %v", l, p, plane, ground)
+			}
+		}
+	}
+}
+
+func TestQsfn(t *testing.T) {
+	// phiFromQs is the inverse of qsfn, and gives the poles beyond them.
+	for _, e := range []float64{0, math.Sqrt(geodesy.WGS84.E2())} {
+		for lat := -90.0; lat <= 90; lat += 7.5 {
+			phi := lat * degToRad
+			if got := phiFromQs(qsfn(phi, e), e); math.Abs(got-phi) > 1e-12 {
+				t.Errorf("phiFromQs(qsfn(%v, %v)) = %v", lat, e, got/degToRad)
+			}
+		}
+		qp := qsfn(math.Pi/2, e)
+		if got := phiFromQs(2*qp, e); got != math.Pi/2 {
+			t.Errorf("phiFromQs(%v, %v) = %v, want π/2", 2*qp, e, got)
+		}
+		if got := phiFromQs(-2*qp, e); got != -math.Pi/2 {
+			t.Errorf("phiFromQs(%v, %v) = %v, want -π/2", -2*qp, e, got)
+		}
+	}
+}
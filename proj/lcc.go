@@ -0,0 +1,121 @@
+package proj
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// LambertConformalConic is the ellipsoidal Lambert conformal conic
+// projection, a conformal projection onto a cone, used for aeronautical
+// charts, for many of the State Plane zones of the United States and for
+// mid-latitude regions of large east-west extent generally.
+//
+// In the two standard parallel form, EPSG method 9802, the cone cuts the
+// ellipsoid along the two parallels, on which the scale is true. In the
+// one standard parallel form, EPSG method 9801, which is used when the
+// two standard parallels are equal, the cone touches the ellipsoid along
+// the standard parallel, where the scale is ScaleFactor. The formulae
+// are those of John P. Snyder, "Map Projections: A Working Manual", USGS
+// Professional Paper 1395 (1987).
+type LambertConformalConic struct {
+	// Ellipsoid is the ellipsoid projected. The zero value stands for
+	// WGS84.
+	Ellipsoid geodesy.Ellipsoid
+	// CentralMeridian and LatitudeOfOrigin are the longitude and
+	// latitude in degrees of the origin of the projection, which has
+	// planar coordinates (FalseEasting, FalseNorthing).
+	CentralMeridian, LatitudeOfOrigin float64
+	// StandardParallel1 and StandardParallel2 are the latitudes in
+	// degrees of the standard parallels, which must not be opposite,
+	// and neither of which may be a pole.
+	StandardParallel1, StandardParallel2 float64
+	// ScaleFactor is the scale factor on the standard parallel of the
+	// one standard parallel form. The zero value stands for 1. It is
+	// ignored in the two standard parallel form.
+	ScaleFactor float64
+	// FalseEasting and FalseNorthing are the planar coordinates of the
+	// origin in meters.
+	FalseEasting, FalseNorthing float64
+}
+
+// cone returns the constants of the projection: the eccentricity e of
+// the ellipsoid, the cone constant n, the radius scale af, such that the
+// radius of the parallel with isometric parameter t is af*t^n, and the
+// radius rho0 of the latitude of origin.
+func (l LambertConformalConic) cone() (e, n, af, rho0 float64) {
+	ell := orWGS84(l.Ellipsoid)
+	e = math.Sqrt(ell.E2())
+	phi1, phi2 := l.StandardParallel1*degToRad, l.StandardParallel2*degToRad
+	m1, t1 := msfn(phi1, e), tsfn(phi1, e)
+	k := 1.0
+	if phi1 == phi2 {
+		n = math.Sin(phi1)
+		k = orOne(l.ScaleFactor)
+	} else {
+		m2, t2 := msfn(phi2, e), tsfn(phi2, e)
+		n = math.Log(m1/m2) / math.Log(t1/t2)
+	}
+	af = ell.A * k * m1 / (n * math.Pow(t1, n))
+	rho0 = af * math.Pow(tsfn(l.LatitudeOfOrigin*degToRad, e), n)
+	return
+}
+
+// Forward returns the coordinates of a position in the projection. The
+// longitude is taken relative to the central meridian, wrapped into the
+// range [-180, 180). The pole on the far side of the cone from the
+// standard parallels projects to infinity.
+func (l LambertConformalConic) Forward(p geodesy.LatLng) (x, y float64) {
+	e, n, af, rho0 := l.cone()
+	rho := af * math.Pow(tsfn(p.Lat*degToRad, e), n)
+	theta := n * wrapLng(p.Lng-l.CentralMeridian) * degToRad
+	sinTheta, cosTheta := math.Sincos(theta)
+	return l.FalseEasting + rho*sinTheta, l.FalseNorthing + rho0 - rho*cosTheta
+}
+
+// Inverse returns the position of the point with the given coordinates
+// in the projection. The longitude is wrapped into the range
+// [-180, 180).
+func (l LambertConformalConic) Inverse(x, y float64) geodesy.LatLng {
+	e, n, af, rho0 := l.cone()
+	dx, dy := x-l.FalseEasting, rho0-(y-l.FalseNorthing)
+	if n < 0 {
+		dx, dy = -dx, -dy
+	}
+	rho := math.Copysign(math.Hypot(dx, dy), n)
+	theta := math.Atan2(dx, dy)
+	t := math.Pow(rho/af, 1/n)
+	return geodesy.LatLng{
+		Lat: phiFromTs(t, e) / degToRad,
+		Lng: wrapLng(l.CentralMeridian + theta/n/degToRad),
+	}
+}
+
+// msfn returns the ratio of the radius of the parallel at latitude phi
+// to the equatorial radius of an ellipsoid with eccentricity e.
+func msfn(phi, e float64) float64 {
+	sinPhi, cosPhi := math.Sincos(phi)
+	return cosPhi / math.Sqrt(1-e*e*sinPhi*sinPhi)
+}
+
+// tsfn returns Snyder's function t of the latitude phi on an ellipsoid
+// with eccentricity e, the exponential of minus the isometric latitude,
+// which is 0 at the north pole and infinite at the south pole.
+func tsfn(phi, e float64) float64 {
+	sinPhi := math.Sin(phi)
+	return math.Tan(math.Pi/4-phi/2) / math.Pow((1-e*sinPhi)/(1+e*sinPhi), e/2)
+}
+
+// phiFromTs is the inverse of tsfn, found by fixed point iteration.
+func phiFromTs(t, e float64) float64 {
+	phi := math.Pi/2 - 2*math.Atan(t)
+	for range 15 {
+		sinPhi := math.Sin(phi)
+		next := math.Pi/2 - 2*math.Atan(t*math.Pow((1-e*sinPhi)/(1+e*sinPhi), e/2))
+		if math.Abs(next-phi) < 1e-15 {
+			return next
+		}
+		phi = next
+	}
+	return phi
+}
@@ -0,0 +1,106 @@
+package proj
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// usFoot is the length of the US survey foot in meters.
+const usFoot = 1200.0 / 3937
+
+func TestLambertConformalConic(t *testing.T) {
+	tests := []struct {
+		l    LambertConformalConic
+		p    geodesy.LatLng
+		x, y float64
+	}{
+		// EPSG Guidance Note 7-2, method 9802: NAD27 / Texas South
+		// Central, 2963503.91 and 254759.80 US survey feet.
+		{LambertConformalConic{
+			Ellipsoid: geodesy.Clarke1866, CentralMeridian: -99, LatitudeOfOrigin: dms(27, 50, 0),
+			StandardParallel1: dms(28, 23, 0), StandardParallel2: dms(30, 17, 0),
+			FalseEasting: 2000000 * usFoot,
+		}, geodesy.LatLng{Lat: 28.5, Lng: -96}, 2963503.91 * usFoot, 254759.80 * usFoot},
+		// EPSG Guidance Note 7-2, method 9801: JAD69 / Jamaica National
+		// Grid.
+		{LambertConformalConic{
+			Ellipsoid: geodesy.Clarke1866, CentralMeridian: -77, LatitudeOfOrigin: 18,
+			StandardParallel1: 18, StandardParallel2: 18, ScaleFactor: 1,
+			FalseEasting: 250000, FalseNorthing: 150000,
+		}, geodesy.LatLng{Lat: dms(17, 55, 55.80), Lng: dms(-76, 56, 37.26)}, 255966.58, 142493.51},
+		// Snyder, "Map Projections: A Working Manual", page 296.
+		{LambertConformalConic{
+			Ellipsoid: geodesy.Clarke1866, CentralMeridian: -96, LatitudeOfOrigin: 23,
+			StandardParallel1: 33, StandardParallel2: 45,
+		}, geodesy.LatLng{Lat: 35, Lng: -75}, 1894410.9, 1564649.5},
+		{LambertConformalConic{StandardParallel1: 30, StandardParallel2: 60}, geodesy.LatLng{Lat: 0, Lng: 0}, 0, 0},
+	}
+	for _, tt := range tests {
+		x, y := tt.l.Forward(tt.p)
+		// Snyder gives the coordinates to a decimeter.
+		if math.Abs(x-tt.x) > 0.05 || math.Abs(y-tt.y) > 0.05 {
+			t.Errorf("%+v.Forward(%v) = (%.3f, %.3f), want (%.3f, %.3f)", tt.l, tt.p, x, y, tt.x, tt.y)
+		}
+		if p := tt.l.Inverse(tt.x, tt.y); math.Abs(p.Lat-tt.p.Lat) > 1e-6 || math.Abs(p.Lng-tt.p.Lng) > 1e-6 {
+			t.Errorf("%+v.Inverse(%.3f, %.3f) = %v, want %v", tt.l, tt.x, tt.y, p, tt.p)
+		}
+	}
+}
+
+func TestLambertConformalConicScaleFactor(t *testing.T) {
+	// The zero scale factor stands for 1 in the one standard parallel
+	// form, and is ignored in the two standard parallel form.
+	p := geodesy.LatLng{Lat: 50, Lng: 7}
+	one := LambertConformalConic{LatitudeOfOrigin: 46, StandardParallel1: 46, StandardParallel2: 46}
+	x0, y0 := one.Forward(p)
+	one.ScaleFactor = 1
+	if x1, y1 := one.Forward(p); x0 != x1 || y0 != y1 {
+		t.Errorf("Forward(%v) with ScaleFactor 0 = (%v, %v), want (%v, %v)", p, x0, y0, x1, y1)
+	}
+	one.ScaleFactor = 0.99987742
+	if x, y := one.Forward(p); math.Abs(x-0.99987742*x0) > 1e-6 || math.Abs(y-0.99987742*y0) > 1e-6 {
+		t.Errorf("Forward(%v) with ScaleFactor 0.99987742 = (%v, %v), want (%v, %v)", p, x, y, 0.99987742*x0, 0.99987742*y0)
+	}
+	two := LambertConformalConic{LatitudeOfOrigin: 46, StandardParallel1: 44, StandardParallel2: 49}
+	x0, y0 = two.Forward(p)
+	two.ScaleFactor = 0.5
+	if x, y := two.Forward(p); x != x0 || y != y0 {
+		t.Errorf("two parallel Forward(%v) with ScaleFactor 0.5 = (%v, %v), want (%v, %v)", p, x, y, x0, y0)
+	}
+}
+
+func TestLambertConformalConicRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(53))
+	cones := []LambertConformalConic{
+		{CentralMeridian: 3, LatitudeOfOrigin: 46.5, StandardParallel1: 44, StandardParallel2: 49, FalseEasting: 700000, FalseNorthing: 6600000},
+		{Ellipsoid: geodesy.GRS80, CentralMeridian: 135, LatitudeOfOrigin: -30, StandardParallel1: -20, StandardParallel2: -40},
+		{CentralMeridian: -100, LatitudeOfOrigin: 40, StandardParallel1: 40, StandardParallel2: 40, ScaleFactor: 0.9999},
+	}
+	for _, l := range cones {
+		for range 1000 {
+			p := geodesy.LatLng{Lat: math.Copysign(r.Float64()*80, l.StandardParallel1), Lng: l.CentralMeridian + r.Float64()*120 - 60}
+			x, y := l.Forward(p)
+			q := l.Inverse(x, y)
+			if math.Abs(q.Lat-p.Lat) > 1e-9 || math.Abs(wrapLng(q.Lng-p.Lng)) > 1e-9 {
+				t.Fatalf("%+v: Inverse(Forward(%v)) = %v", l, p, q)
+			}
+		}
+		// The scale is true on the standard parallels.
+		if l.ScaleFactor == 0 {
+			ell := orWGS84(l.Ellipsoid)
+			for _, lat := range []float64{l.StandardParallel1, l.StandardParallel2} {
+				const h = 1e-6
+				x1, y1 := l.Forward(geodesy.LatLng{Lat: lat, Lng: l.CentralMeridian})
+				x2, y2 := l.Forward(geodesy.LatLng{Lat: lat, Lng: l.CentralMeridian + h})
+				s, c := math.Sincos(lat * degToRad)
+				ground := ell.A * c / math.Sqrt(1-ell.E2()*s*s) * h * degToRad
+				if k := math.Hypot(x2-x1, y2-y1) / ground; math.Abs(k-1) > 1e-6 {
+					t.Errorf("%+v: scale on parallel %v = %v, want 1", l, lat, k)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Package proj implements map projections, which map positions on the
+// Earth, given as latitudes and longitudes in degrees, to coordinates in
+// meters on a plane, and back.
+//
+// Every projection distorts: conformal projections, such as Mercator
+// and Lambert Conformal Conic, preserve angles and the shapes of small
+// figures, while equal area projections, such as Albers, preserve
+// areas. Projections are values implementing the Projection interface,
+// configured by their fields with the conventional parameters, such as a
+// central meridian, a scale factor and a false easting and northing.
+package proj
+
+import "github.com/gogama/geospat/geodesy"
+
+// Projection is a map projection.
+type Projection interface {
+	// Forward returns the planar coordinates in meters of a position,
+	// x increasing eastward and y northward.
+	Forward(p geodesy.LatLng) (x, y float64)
+	// Inverse returns the position of the point with the given planar
+	// coordinates in meters. It performs the inverse mapping of
+	// Forward.
+	Inverse(x, y float64) geodesy.LatLng
+}
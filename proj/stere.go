@@ -0,0 +1,95 @@
+package proj
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// PolarStereographic is the ellipsoidal polar stereographic projection,
+// a conformal azimuthal projection centered on a pole, used for maps of
+// the Arctic and Antarctic, such as the NSIDC Sea Ice Polar
+// Stereographic North, EPSG:3413, and Antarctic Polar Stereographic,
+// EPSG:3031, and for the Universal Polar Stereographic system. The
+// formulae are those of John P. Snyder, "Map Projections: A Working
+// Manual", USGS Professional Paper 1395 (1987).
+//
+// When LatitudeOfTrueScale is a parallel other than the pole, EPSG
+// method 9829, the scale is true along that parallel. When it is the
+// pole, EPSG method 9810, the scale at the pole is ScaleFactor.
+type PolarStereographic struct {
+	// Ellipsoid is the ellipsoid projected. The zero value stands for
+	// WGS84.
+	Ellipsoid geodesy.Ellipsoid
+	// CentralMeridian is the longitude in degrees of the meridian which
+	// runs from the pole along the negative y axis in the north polar
+	// projection, and along the positive y axis in the south polar
+	// projection.
+	CentralMeridian float64
+	// LatitudeOfTrueScale is the latitude in degrees of the parallel
+	// along which the scale is true, or 90 or -90 for the pole. Its
+	// sign selects the north or the south polar projection, so it must
+	// not be 0.
+	LatitudeOfTrueScale float64
+	// ScaleFactor is the scale factor at the pole when
+	// LatitudeOfTrueScale is 90 or -90. The zero value stands for 1. It
+	// is ignored otherwise.
+	ScaleFactor float64
+	// FalseEasting and FalseNorthing are the planar coordinates of the
+	// pole in meters.
+	FalseEasting, FalseNorthing float64
+}
+
+// polar returns the constants of the projection: the eccentricity e of
+// the ellipsoid, the scale af, such that the distance from the pole of
+// a point with isometric parameter t is af*t, and whether the
+// projection is centered on the south pole.
+func (p PolarStereographic) polar() (e, af float64, south bool) {
+	ell := orWGS84(p.Ellipsoid)
+	e = math.Sqrt(ell.E2())
+	south = p.LatitudeOfTrueScale < 0
+	phic := math.Abs(p.LatitudeOfTrueScale) * degToRad
+	if phic == math.Pi/2 {
+		af = 2 * ell.A * orOne(p.ScaleFactor) / math.Sqrt(math.Pow(1+e, 1+e)*math.Pow(1-e, 1-e))
+	} else {
+		af = ell.A * msfn(phic, e) / tsfn(phic, e)
+	}
+	return
+}
+
+// Forward returns the coordinates of a position in the projection. The
+// opposite pole projects to infinity.
+func (p PolarStereographic) Forward(q geodesy.LatLng) (x, y float64) {
+	e, af, south := p.polar()
+	lat := q.Lat
+	if south {
+		lat = -lat
+	}
+	rho := af * tsfn(lat*degToRad, e)
+	sinLambda, cosLambda := math.Sincos(wrapLng(q.Lng-p.CentralMeridian) * degToRad)
+	if south {
+		return p.FalseEasting + rho*sinLambda, p.FalseNorthing + rho*cosLambda
+	}
+	return p.FalseEasting + rho*sinLambda, p.FalseNorthing - rho*cosLambda
+}
+
+// Inverse returns the position of the point with the given coordinates
+// in the projection. The longitude is wrapped into the range
+// [-180, 180), and is the central meridian at the pole.
+func (p PolarStereographic) Inverse(x, y float64) geodesy.LatLng {
+	e, af, south := p.polar()
+	dx, dy := x-p.FalseEasting, y-p.FalseNorthing
+	if !south {
+		dy = -dy
+	}
+	rho := math.Hypot(dx, dy)
+	lat := phiFromTs(rho/af, e) / degToRad
+	if south {
+		lat = -lat
+	}
+	lng := p.CentralMeridian
+	if rho != 0 {
+		lng += math.Atan2(dx, dy) / degToRad
+	}
+	return geodesy.LatLng{Lat: lat, Lng: wrapLng(lng)}
+}
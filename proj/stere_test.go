@@ -0,0 +1,99 @@
+package proj
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+func TestPolarStereographic(t *testing.T) {
+	tests := []struct {
+		s    PolarStereographic
+		p    geodesy.LatLng
+		x, y float64
+	}{
+		// EPSG Guidance Note 7-2, method 9810: WGS84 / UPS North.
+		{PolarStereographic{LatitudeOfTrueScale: 90, ScaleFactor: 0.994, FalseEasting: 2000000, FalseNorthing: 2000000},
+			geodesy.LatLng{Lat: 73, Lng: 44}, 3320416.75, 632668.43},
+		// EPSG Guidance Note 7-2, method 9829: WGS84 / Australian
+		// Antarctic Polar Stereographic.
+		{PolarStereographic{LatitudeOfTrueScale: -71, CentralMeridian: 70, FalseEasting: 6000000, FalseNorthing: 6000000},
+			geodesy.LatLng{Lat: -75, Lng: 120}, 7255380.79, 7053389.56},
+		// Snyder, "Map Projections: A Working Manual", page 317.
+		{PolarStereographic{Ellipsoid: geodesy.International1924, LatitudeOfTrueScale: -71, CentralMeridian: -100},
+			geodesy.LatLng{Lat: -75, Lng: 150}, -1540033.6, -560526.4},
+		{PolarStereographic{LatitudeOfTrueScale: 90, FalseEasting: 10, FalseNorthing: 20}, geodesy.LatLng{Lat: 90, Lng: 0}, 10, 20},
+		{PolarStereographic{LatitudeOfTrueScale: -71, CentralMeridian: 70}, geodesy.LatLng{Lat: -90, Lng: 70}, 0, 0},
+	}
+	for _, tt := range tests {
+		x, y := tt.s.Forward(tt.p)
+		// Snyder gives the coordinates to a decimeter.
+		if math.Abs(x-tt.x) > 0.05 || math.Abs(y-tt.y) > 0.05 {
+			t.Errorf("%+v.Forward(%v) = (%.3f, %.3f), want (%.3f, %.3f)", tt.s, tt.p, x, y, tt.x, tt.y)
+		}
+		if p := tt.s.Inverse(tt.x, tt.y); math.Abs(p.Lat-tt.p.Lat) > 1e-6 || math.Abs(p.Lng-tt.p.Lng) > 1e-6 {
+			t.Errorf("%+v.Inverse(%.3f, %.3f) = %v, want %v", tt.s, tt.x, tt.y, p, tt.p)
+		}
+	}
+}
+
+func TestPolarStereographicScaleFactor(t *testing.T) {
+	// The zero scale factor stands for 1 at the pole, and is ignored
+	// for a true scale parallel.
+	for _, lat := range []float64{90, -90} {
+		p := geodesy.LatLng{Lat: math.Copysign(80, lat), Lng: 30}
+		zero := PolarStereographic{LatitudeOfTrueScale: lat}
+		x0, y0 := zero.Forward(p)
+		one := PolarStereographic{LatitudeOfTrueScale: lat, ScaleFactor: 1}
+		if x1, y1 := one.Forward(p); x0 != x1 || y0 != y1 {
+			t.Errorf("Forward(%v) with ScaleFactor 0 = (%v, %v), want (%v, %v)", p, x0, y0, x1, y1)
+		}
+		if q := zero.Inverse(x0, y0); math.Abs(q.Lat-p.Lat) > 1e-9 || math.Abs(q.Lng-p.Lng) > 1e-9 {
+			t.Errorf("Inverse(%v, %v) with ScaleFactor 0 = %v, want %v", x0, y0, q, p)
+		}
+		ups := PolarStereographic{LatitudeOfTrueScale: lat, ScaleFactor: 0.994}
+		if x, y := ups.Forward(p); math.Abs(x-0.994*x0) > 1e-6 || math.Abs(y-0.994*y0) > 1e-6 {
+			t.Errorf("Forward(%v) with ScaleFactor 0.994 = (%v, %v), want (%v, %v)", p, x, y, 0.994*x0, 0.994*y0)
+		}
+	}
+	p := geodesy.LatLng{Lat: 75, Lng: 30}
+	x0, y0 := PolarStereographic{LatitudeOfTrueScale: 70}.Forward(p)
+	if x, y := (PolarStereographic{LatitudeOfTrueScale: 70, ScaleFactor: 0.5}).Forward(p); x != x0 || y != y0 {
+		t.Errorf("Forward(%v) at true scale 70 with ScaleFactor 0.5 = (%v, %v), want (%v, %v)", p, x, y, x0, y0)
+	}
+}
+
+func TestPolarStereographicRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(55))
+	for _, s := range []PolarStereographic{
+		{LatitudeOfTrueScale: 70, CentralMeridian: -45},
+		{LatitudeOfTrueScale: -71},
+		{Ellipsoid: geodesy.International1924, LatitudeOfTrueScale: -90, ScaleFactor: 0.994, FalseEasting: 2e6, FalseNorthing: 2e6},
+	} {
+		ell := orWGS84(s.Ellipsoid)
+		for range 1000 {
+			p := geodesy.LatLng{Lat: math.Copysign(r.Float64()*89+1, s.LatitudeOfTrueScale), Lng: r.Float64()*360 - 180}
+			x, y := s.Forward(p)
+			if q := s.Inverse(x, y); math.Abs(q.Lat-p.Lat) > 1e-9 || math.Abs(wrapLng(q.Lng-p.Lng))*math.Cos(p.Lat*degToRad) > 1e-9 {
+				t.Fatalf("%+v: Inverse(Forward(%v)) = %v", s, p, q)
+			}
+			// The meridians run straight out from the pole.
+			if lng := math.Atan2(x-s.FalseEasting, s.FalseNorthing-y) / degToRad; s.LatitudeOfTrueScale > 0 && math.Abs(wrapLng(lng+s.CentralMeridian-p.Lng)) > 1e-9 {
+				t.Fatalf("%+v: Forward(%v) = (%v, %v) at bearing %v", s, p, x, y, lng)
+			}
+		}
+		// The scale is true on the parallel of true scale.
+		if lat := s.LatitudeOfTrueScale; math.Abs(lat) != 90 {
+			const h = 1e-6
+			x1, y1 := s.Forward(geodesy.LatLng{Lat: lat, Lng: 0})
+			x2, y2 := s.Forward(geodesy.LatLng{Lat: lat, Lng: h})
+			sin, cos := math.Sincos(lat * degToRad)
+			ground := ell.A * cos / math.Sqrt(1-ell.E2()*sin*sin) * h * degToRad
+			if k := math.Hypot(x2-x1, y2-y1) / ground; math.Abs(k-1) > 1e-6 {
+				t.Errorf("%+v: scale on parallel %v = %v, want 1", s, lat, k)
+			}
+		}
+	}
+}
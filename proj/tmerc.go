@@ -0,0 +1,186 @@
+package proj
+
+import (
+	"math"
+	"sync"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// TransverseMercator is the ellipsoidal transverse Mercator projection,
+// a conformal projection onto a cylinder tangent to the ellipsoid along
+// the central meridian, which underlies UTM, the Gauss-Krüger grids and
+// most national grids, such as the Ordnance Survey National Grid of
+// Great Britain.
+//
+// The projection is computed with the Krüger series to sixth order in
+// the third flattening, as given by Charles F. F. Karney, "Transverse
+// Mercator with an accuracy of a few nanometers", Journal of Geodesy 85
+// (2011), which is accurate to well under a millimeter within 3500 km of
+// the central meridian.
+type TransverseMercator struct {
+	// Ellipsoid is the ellipsoid projected. The zero value stands for
+	// WGS84.
+	Ellipsoid geodesy.Ellipsoid
+	// CentralMeridian and LatitudeOfOrigin are the longitude and
+	// latitude in degrees of the origin of the projection, which has
+	// planar coordinates (FalseEasting, FalseNorthing).
+	CentralMeridian, LatitudeOfOrigin float64
+	// ScaleFactor is the scale factor on the central meridian, such as
+	// 0.9996 for UTM or 1 for Gauss-Krüger. The zero value stands for 1.
+	ScaleFactor float64
+	// FalseEasting and FalseNorthing are the planar coordinates of the
+	// origin in meters.
+	FalseEasting, FalseNorthing float64
+}
+
+// Forward returns the coordinates of a position in the projection. The
+// longitude is taken relative to the central meridian, wrapped into the
+// range [-180, 180), and the projection of points more than 90 degrees
+// from the central meridian is not useful.
+func (t TransverseMercator) Forward(p geodesy.LatLng) (x, y float64) {
+	k := kruegerOf(orWGS84(t.Ellipsoid))
+	xi, eta := k.forward(p.Lat*degToRad, wrapLng(p.Lng-t.CentralMeridian)*degToRad)
+	xi0, _ := k.forward(t.LatitudeOfOrigin*degToRad, 0)
+	scale := orOne(t.ScaleFactor) * k.radius
+	return t.FalseEasting + scale*eta, t.FalseNorthing + scale*(xi-xi0)
+}
+
+// Inverse returns the position of the point with the given coordinates
+// in the projection. The longitude is wrapped into the range
+// [-180, 180).
+func (t TransverseMercator) Inverse(x, y float64) geodesy.LatLng {
+	k := kruegerOf(orWGS84(t.Ellipsoid))
+	xi0, _ := k.forward(t.LatitudeOfOrigin*degToRad, 0)
+	scale := orOne(t.ScaleFactor) * k.radius
+	phi, lambda := k.inverse((y-t.FalseNorthing)/scale+xi0, (x-t.FalseEasting)/scale)
+	return geodesy.LatLng{Lat: phi / degToRad, Lng: wrapLng(t.CentralMeridian + lambda/degToRad)}
+}
+
+// krueger holds the constants of the Krüger series for an ellipsoid:
+// the rectifying radius, the coefficients alpha of the forward series
+// and beta of the inverse series, and the eccentricity.
+type krueger struct {
+	radius      float64
+	alpha, beta [7]float64
+	e           float64
+}
+
+// kruegers caches the Krüger series of the ellipsoids projected, by
+// ellipsoid, so that they are computed once for each.
+var kruegers sync.Map
+
+// kruegerOf returns the Krüger series of an ellipsoid.
+func kruegerOf(e geodesy.Ellipsoid) *krueger {
+	if k, ok := kruegers.Load(e); ok {
+		return k.(*krueger)
+	}
+	k, _ := kruegers.LoadOrStore(e, newKrueger(e))
+	return k.(*krueger)
+}
+
+func newKrueger(e geodesy.Ellipsoid) *krueger {
+	n := e.F / (2 - e.F)
+	n2 := n * n
+	n3, n4, n5, n6 := n2*n, n2*n2, n2*n2*n, n2*n2*n2
+	return &krueger{
+		radius: e.A / (1 + n) * (1 + n2/4 + n4/64 + n6/256),
+		alpha: [7]float64{0,
+			n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+			13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+			61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+			49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+			34729*n5/80640 - 3418889*n6/1995840,
+			212378941 * n6 / 319334400,
+		},
+		beta: [7]float64{0,
+			n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+			n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+			17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+			4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+			4583*n5/161280 - 108847*n6/3991680,
+			20648693 * n6 / 638668800,
+		},
+		e: math.Sqrt(e.E2()),
+	}
+}
+
+// forward returns the coordinates (xi, eta) of the transverse Mercator
+// projection of the ellipsoid onto a unit sphere, for a latitude phi and
+// a longitude lambda relative to the central meridian, in radians.
+func (k *krueger) forward(phi, lambda float64) (xi, eta float64) {
+	tau := conformalTan(math.Tan(phi), k.e)
+	sinLambda, cosLambda := math.Sincos(lambda)
+	xi0 := math.Atan2(tau, cosLambda)
+	eta0 := math.Asinh(sinLambda / math.Hypot(tau, cosLambda))
+	xi, eta = xi0, eta0
+	for j := 1; j <= 6; j++ {
+		fj := float64(2 * j)
+		xi += k.alpha[j] * math.Sin(fj*xi0) * math.Cosh(fj*eta0)
+		eta += k.alpha[j] * math.Cos(fj*xi0) * math.Sinh(fj*eta0)
+	}
+	return xi, eta
+}
+
+// inverse performs the inverse mapping of forward.
+func (k *krueger) inverse(xi, eta float64) (phi, lambda float64) {
+	xi0, eta0 := xi, eta
+	for j := 1; j <= 6; j++ {
+		fj := float64(2 * j)
+		xi0 -= k.beta[j] * math.Sin(fj*xi) * math.Cosh(fj*eta)
+		eta0 -= k.beta[j] * math.Cos(fj*xi) * math.Sinh(fj*eta)
+	}
+	tau0 := math.Sin(xi0) / math.Hypot(math.Sinh(eta0), math.Cos(xi0))
+	lambda = math.Atan2(math.Sinh(eta0), math.Cos(xi0))
+	return math.Atan(geographicTan(tau0, k.e)), lambda
+}
+
+// conformalTan returns the tangent of the conformal latitude for a
+// geodetic latitude with tangent tau on an ellipsoid with eccentricity
+// e.
+func conformalTan(tau, e float64) float64 {
+	sigma := math.Sinh(e * math.Atanh(e*tau/math.Hypot(1, tau)))
+	return tau*math.Hypot(1, sigma) - sigma*math.Hypot(1, tau)
+}
+
+// geographicTan is the inverse of conformalTan, found by Newton's
+// method.
+func geographicTan(tau0, e float64) float64 {
+	e2 := e * e
+	tau := tau0
+	for range 10 {
+		t0 := conformalTan(tau, e)
+		d := (tau0 - t0) / math.Hypot(1, t0) * (1 + (1-e2)*tau*tau) /
+			((1 - e2) * math.Hypot(1, tau))
+		tau += d
+		if math.Abs(d) <= 1e-14*max(1, math.Abs(tau)) {
+			break
+		}
+	}
+	return tau
+}
+
+// orWGS84 returns e, or WGS84 if e is the zero value.
+func orWGS84(e geodesy.Ellipsoid) geodesy.Ellipsoid {
+	if e.A == 0 {
+		return geodesy.WGS84
+	}
+	return e
+}
+
+// orOne returns k, or 1 if k is zero.
+func orOne(k float64) float64 {
+	if k == 0 {
+		return 1
+	}
+	return k
+}
+
+// wrapLng wraps a longitude in degrees into the range [-180, 180).
+func wrapLng(lng float64) float64 {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return lng - 180
+}
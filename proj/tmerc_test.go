@@ -0,0 +1,122 @@
+package proj
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// nationalGrid is the Ordnance Survey National Grid of Great Britain.
+var nationalGrid = TransverseMercator{
+	Ellipsoid:        geodesy.Airy1830,
+	CentralMeridian:  -2,
+	LatitudeOfOrigin: 49,
+	ScaleFactor:      0.9996012717,
+	FalseEasting:     400000,
+	FalseNorthing:    -100000,
+}
+
+func TestTransverseMercator(t *testing.T) {
+	tests := []struct {
+		tm   TransverseMercator
+		p    geodesy.LatLng
+		x, y float64
+	}{
+		// The worked example of the Ordnance Survey, "A guide to
+		// coordinate systems in Great Britain", appendix C.
+		{nationalGrid, geodesy.LatLng{Lat: dms(52, 39, 27.2531), Lng: dms(1, 43, 4.5177)}, 651409.903, 313177.270},
+		// UTM zone 38N, as in the GeographicLib GeoConvert example.
+		{TransverseMercator{CentralMeridian: 45, ScaleFactor: 0.9996, FalseEasting: 500000}, geodesy.LatLng{Lat: 33.3, Lng: 44.4}, 444140.545, 3684706.356},
+		// The length of a degree of the equator and of the quadrant of
+		// the WGS84 meridian.
+		{TransverseMercator{}, geodesy.LatLng{Lat: 0, Lng: 1}, 111325.181, 0},
+		{TransverseMercator{}, geodesy.LatLng{Lat: 90, Lng: 0}, 0, 10001965.729},
+		{TransverseMercator{LatitudeOfOrigin: 90, FalseNorthing: 1000}, geodesy.LatLng{Lat: 0, Lng: 0}, 0, 1000 - 10001965.729},
+	}
+	for _, tt := range tests {
+		x, y := tt.tm.Forward(tt.p)
+		if math.Abs(x-tt.x) > 1e-3 || math.Abs(y-tt.y) > 1e-3 {
+			t.Errorf("%+v.Forward(%v) = (%.3f, %.3f), want (%.3f, %.3f)", tt.tm, tt.p, x, y, tt.x, tt.y)
+		}
+		if p := tt.tm.Inverse(tt.x, tt.y); math.Abs(p.Lat-tt.p.Lat) > 1e-8 || math.Abs(p.Lng-tt.p.Lng) > 1e-8 {
+			t.Errorf("%+v.Inverse(%.3f, %.3f) = %v, want %v", tt.tm, tt.x, tt.y, p, tt.p)
+		}
+	}
+}
+
+func TestTransverseMercatorWrap(t *testing.T) {
+	// Longitudes are taken relative to the central meridian across the
+	// antimeridian.
+	x, y := TransverseMercator{CentralMeridian: 170}.Forward(geodesy.LatLng{Lat: 10, Lng: -179})
+	wx, wy := TransverseMercator{}.Forward(geodesy.LatLng{Lat: 10, Lng: 11})
+	if math.Abs(x-wx) > 1e-6 || math.Abs(y-wy) > 1e-6 {
+		t.Errorf("Forward(10, -179) about 170 = (%v, %v), want (%v, %v)", x, y, wx, wy)
+	}
+	if p := (TransverseMercator{CentralMeridian: 170}).Inverse(wx, wy); math.Abs(p.Lat-10) > 1e-9 || math.Abs(p.Lng+179) > 1e-9 {
+		t.Errorf("Inverse(%v, %v) about 170 = %v, want (10, -179)", wx, wy, p)
+	}
+}
+
+func TestTransverseMercatorScaleFactor(t *testing.T) {
+	// The zero scale factor stands for 1, and the coordinates relative
+	// to the origin are scaled by it.
+	p := geodesy.LatLng{Lat: 52, Lng: 3}
+	x0, y0 := TransverseMercator{}.Forward(p)
+	x1, y1 := TransverseMercator{ScaleFactor: 1}.Forward(p)
+	if x0 != x1 || y0 != y1 {
+		t.Errorf("Forward(%v) with ScaleFactor 0 = (%v, %v), want (%v, %v)", p, x0, y0, x1, y1)
+	}
+	if q := (TransverseMercator{}).Inverse(x1, y1); math.Abs(q.Lat-p.Lat) > 1e-9 || math.Abs(q.Lng-p.Lng) > 1e-9 {
+		t.Errorf("Inverse(%v, %v) with ScaleFactor 0 = %v, want %v", x1, y1, q, p)
+	}
+	x2, y2 := TransverseMercator{ScaleFactor: 0.9996}.Forward(p)
+	if math.Abs(x2-0.9996*x1) > 1e-6 || math.Abs(y2-0.9996*y1) > 1e-6 {
+		t.Errorf("Forward(%v) with ScaleFactor 0.9996 = (%v, %v), want (%v, %v)", p, x2, y2, 0.9996*x1, 0.9996*y1)
+	}
+}
+
+func TestTransverseMercatorRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(52))
+	for _, tm := range []TransverseMercator{nationalGrid, {CentralMeridian: -93, ScaleFactor: 0.9996, FalseEasting: 500000}} {
+		for range 1000 {
+			// Within 3500 km of the central meridian, the round trip is
+			// exact to well under a millimeter.
+			p := geodesy.LatLng{Lat: r.Float64()*170 - 85, Lng: tm.CentralMeridian + r.Float64()*60 - 30}
+			if math.Abs(p.Lng-tm.CentralMeridian)*math.Cos(p.Lat*degToRad) > 30 {
+				continue
+			}
+			x, y := tm.Forward(p)
+			q := tm.Inverse(x, y)
+			if d := geodesy.Haversine(p, q); d > 1e-4 {
+				t.Fatalf("Inverse(Forward(%v)) = %v, %v m away", p, q, d)
+			}
+			// The projection is conformal, so the scale is the same
+			// north and east.
+			const h = 1e-6
+			xn, yn := tm.Forward(geodesy.LatLng{Lat: p.Lat + h, Lng: p.Lng})
+			xe, ye := tm.Forward(geodesy.LatLng{Lat: p.Lat, Lng: p.Lng + h})
+			ell := orWGS84(tm.Ellipsoid)
+			s, c := math.Sincos(p.Lat * degToRad)
+			w := math.Sqrt(1 - ell.E2()*s*s)
+			kn := math.Hypot(xn-x, yn-y) / (ell.A * (1 - ell.E2()) / (w * w * w) * h * degToRad)
+			ke := math.Hypot(xe-x, ye-y) / (ell.A * c / w * h * degToRad)
+			if math.Abs(kn-ke)/kn > 1e-6 {
+				t.Fatalf("Forward(%v) has scales %v north and %v east", p, kn, ke)
+			}
+		}
+	}
+}
+
+func TestKruegerOf(t *testing.T) {
+	if a, b := kruegerOf(geodesy.Airy1830), kruegerOf(geodesy.Airy1830); a != b {
+		t.Errorf("kruegerOf(Airy1830) = %p, then %p, want the same", a, b)
+	}
+	if a, b := kruegerOf(geodesy.Airy1830), kruegerOf(geodesy.WGS84); a == b || a.radius == b.radius {
+		t.Errorf("kruegerOf(Airy1830) = kruegerOf(WGS84)")
+	}
+	if a := testing.AllocsPerRun(10, func() { nationalGrid.Forward(geodesy.LatLng{Lat: 52, Lng: -1}) }); a != 0 {
+		t.Errorf("Forward allocates %v times, want 0", a)
+	}
+}
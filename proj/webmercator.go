@@ -0,0 +1,64 @@
+package proj
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// WebMercatorRadius is the radius in meters of the sphere on which Web
+// Mercator coordinates are computed, the semi-major axis of WGS84.
+const WebMercatorRadius = 6378137.0
+
+// WebMercatorMaxLat is the latitude, in degrees, at which the Web
+// Mercator map is cut off, which makes the map a square. The southern
+// edge is at -WebMercatorMaxLat.
+const WebMercatorMaxLat = 85.05112877980659
+
+// WebMercatorHalfWidth is half the width of the Web Mercator square in
+// meters. Web Mercator coordinates range from -WebMercatorHalfWidth to
+// WebMercatorHalfWidth on both axes.
+const WebMercatorHalfWidth = math.Pi * WebMercatorRadius
+
+// WebMercator is the Web Mercator projection, EPSG:3857, used by
+// OpenStreetMap, Google Maps and most other web maps. It applies the
+// spherical form of the Mercator projection to WGS84 latitudes and
+// longitudes, so it is not quite conformal, and it is not suitable for
+// accurate measurement: distances at a latitude are magnified by the
+// ScaleFactor there, which grows without bound towards the poles.
+type WebMercator struct{}
+
+// Forward returns the Web Mercator coordinates of a position. Latitudes
+// beyond WebMercatorMaxLat are clamped. Longitudes are not wrapped, so
+// that a line crossing the antimeridian may be projected without a jump
+// by giving longitudes beyond 180 degrees.
+func (WebMercator) Forward(p geodesy.LatLng) (x, y float64) {
+	lat := max(-WebMercatorMaxLat, min(WebMercatorMaxLat, p.Lat))
+	return WebMercatorRadius * p.Lng * degToRad, WebMercatorRadius * math.Atanh(math.Sin(lat*degToRad))
+}
+
+// Inverse returns the position of the point with the given Web Mercator
+// coordinates.
+func (WebMercator) Inverse(x, y float64) geodesy.LatLng {
+	return geodesy.LatLng{
+		Lat: math.Atan(math.Sinh(y/WebMercatorRadius)) / degToRad,
+		Lng: x / WebMercatorRadius / degToRad,
+	}
+}
+
+// ScaleFactor returns the scale factor of the Web Mercator projection at
+// a latitude in degrees: the ratio of a short distance on the map to the
+// distance on the sphere it represents, which is the same in every
+// direction. The ratio of areas is its square.
+func (WebMercator) ScaleFactor(lat float64) float64 {
+	return 1 / math.Cos(lat*degToRad)
+}
+
+// GroundDistance returns the distance in meters on the sphere
+// represented by the given distance in Web Mercator meters at a latitude
+// in degrees.
+func (m WebMercator) GroundDistance(lat, d float64) float64 {
+	return d / m.ScaleFactor(lat)
+}
+
+const degToRad = math.Pi / 180
@@ -0,0 +1,86 @@
+package proj
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geodesy"
+)
+
+// dms returns an angle in degrees given in degrees, minutes and seconds.
+func dms(d, m, s float64) float64 {
+	return math.Copysign(math.Abs(d)+m/60+s/3600, d)
+}
+
+func TestWebMercator(t *testing.T) {
+	tests := []struct {
+		p    geodesy.LatLng
+		x, y float64
+	}{
+		// EPSG Guidance Note 7-2, method 1024.
+		{geodesy.LatLng{Lat: dms(24, 22, 54.433), Lng: dms(-100, 20, 0)}, -11169055.58, 2800000.00},
+		{geodesy.LatLng{Lat: 0, Lng: 0}, 0, 0},
+		{geodesy.LatLng{Lat: WebMercatorMaxLat, Lng: 180}, WebMercatorHalfWidth, WebMercatorHalfWidth},
+		{geodesy.LatLng{Lat: -WebMercatorMaxLat, Lng: -180}, -WebMercatorHalfWidth, -WebMercatorHalfWidth},
+		{geodesy.LatLng{Lat: 0, Lng: 190}, WebMercatorHalfWidth * 190 / 180, 0},
+	}
+	var m WebMercator
+	for _, tt := range tests {
+		x, y := m.Forward(tt.p)
+		if math.Abs(x-tt.x) > 0.01 || math.Abs(y-tt.y) > 0.01 {
+			t.Errorf("Forward(%v) = (%.3f, %.3f), want (%.3f, %.3f)", tt.p, x, y, tt.x, tt.y)
+		}
+		if p := m.Inverse(tt.x, tt.y); math.Abs(p.Lat-tt.p.Lat) > 1e-7 || math.Abs(p.Lng-tt.p.Lng) > 1e-7 {
+			t.Errorf("Inverse(%.3f, %.3f) = %v, want %v", tt.x, tt.y, p, tt.p)
+		}
+	}
+}
+
+func TestWebMercatorClamp(t *testing.T) {
+	var m WebMercator
+	for _, lat := range []float64{86, 90} {
+		if _, y := m.Forward(geodesy.LatLng{Lat: lat}); math.Abs(y-WebMercatorHalfWidth) > 1e-6 {
+			t.Errorf("Forward(%v, 0) y = %v, want %v", lat, y, WebMercatorHalfWidth)
+		}
+		if _, y := m.Forward(geodesy.LatLng{Lat: -lat}); math.Abs(y+WebMercatorHalfWidth) > 1e-6 {
+			t.Errorf("Forward(%v, 0) y = %v, want %v", -lat, y, -WebMercatorHalfWidth)
+		}
+	}
+}
+
+func TestWebMercatorRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(51))
+	var m WebMercator
+	for range 1000 {
+		p := geodesy.LatLng{Lat: (r.Float64()*2 - 1) * WebMercatorMaxLat, Lng: r.Float64()*360 - 180}
+		x, y := m.Forward(p)
+		if q := m.Inverse(x, y); math.Abs(q.Lat-p.Lat) > 1e-9 || math.Abs(q.Lng-p.Lng) > 1e-9 {
+			t.Fatalf("Inverse(Forward(%v)) = %v", p, q)
+		}
+		// A small step north on the map is the scale factor times the
+		// step on the sphere.
+		const dLat = 1e-5
+		_, y2 := m.Forward(geodesy.LatLng{Lat: p.Lat + dLat, Lng: p.Lng})
+		ground := WebMercatorRadius * dLat * degToRad
+		if k := (y2 - y) / ground; math.Abs(k-m.ScaleFactor(p.Lat+dLat/2))/k > 1e-6 {
+			t.Fatalf("ScaleFactor(%v) = %v, want %v", p.Lat, m.ScaleFactor(p.Lat), k)
+		}
+		if d := m.GroundDistance(p.Lat+dLat/2, y2-y); math.Abs(d-ground) > 1e-6 {
+			t.Fatalf("GroundDistance(%v, %v) = %v, want %v", p.Lat, y2-y, d, ground)
+		}
+	}
+}
+
+func TestWebMercatorScaleFactor(t *testing.T) {
+	var m WebMercator
+	tests := []struct{ lat, k float64 }{{0, 1}, {60, 2}, {-60, 2}, {45, math.Sqrt2}}
+	for _, tt := range tests {
+		if k := m.ScaleFactor(tt.lat); math.Abs(k-tt.k) > 1e-12 {
+			t.Errorf("ScaleFactor(%v) = %v, want %v", tt.lat, k, tt.k)
+		}
+	}
+	if d := m.GroundDistance(60, 1000); math.Abs(d-500) > 1e-9 {
+		t.Errorf("GroundDistance(60, 1000) = %v, want 500", d)
+	}
+}
@@ -0,0 +1,96 @@
+// Package quadkey converts between Bing Maps tile coordinates and
+// quadkeys, the strings of base-4 digits which Bing Maps and many tile
+// caches use to name map tiles.
+//
+// At zoom level z the Web Mercator square is divided into 2^z X 2^z
+// tiles, with tile (0, 0) in the upper left-hand (north-west) corner. The
+// quadkey of a tile has exactly z digits, and the quadkey of a tile's
+// parent is the quadkey of the tile with its last digit removed, so
+// tiles which are near each other usually have quadkeys with a long
+// common prefix. The single tile at zoom level 0 has the empty quadkey.
+//
+// The tile coordinates are those of package tile, and the digits of a
+// quadkey are the base-4 digits of the Morton code of the tile
+// coordinates, as produced by morton.Encode2.
+package quadkey
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gogama/geospat/morton"
+	"github.com/gogama/geospat/tile"
+)
+
+// MaxZoom is the largest supported zoom level. Bing Maps itself only
+// uses zoom levels up to 23.
+const MaxZoom = tile.MaxZoom
+
+// ErrInvalid is returned, wrapped, by ToTile when its argument is not a
+// valid quadkey.
+var ErrInvalid = errors.New("quadkey: invalid quadkey")
+
+// FromTile returns the quadkey of the tile with coordinates (x, y) at
+// the given zoom level. The zoom level must be in the range
+// [0, MaxZoom], and x and y must be in the range [0, 2^zoom-1].
+//
+// The complementary function ToTile performs the inverse mapping.
+func FromTile(x, y uint32, zoom int) string {
+	d := morton.Encode2(x, y)
+	b := make([]byte, zoom)
+	for i := range b {
+		b[i] = '0' + byte(d>>uint(2*(zoom-1-i))&3)
+	}
+	return string(b)
+}
+
+// ToTile returns the coordinates and zoom level of the tile with the
+// given quadkey. It returns an error wrapping ErrInvalid if the quadkey
+// contains a character other than the digits 0 to 3 or has more than
+// MaxZoom digits.
+//
+// The complementary function FromTile performs the inverse mapping.
+func ToTile(key string) (x, y uint32, zoom int, err error) {
+	if len(key) > MaxZoom {
+		return 0, 0, 0, fmt.Errorf("%w: %d digits is more than %d", ErrInvalid, len(key), MaxZoom)
+	}
+	var d uint64
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c < '0' || c > '3' {
+			return 0, 0, 0, fmt.Errorf("%w: %q has invalid digit %q", ErrInvalid, key, c)
+		}
+		d = d<<2 | uint64(c-'0')
+	}
+	x, y = morton.Decode2(d)
+	return x, y, len(key), nil
+}
+
+// Parent returns the quadkey of the tile at the next lower zoom level
+// which contains the tile with the given quadkey. The quadkey must not
+// be empty.
+func Parent(key string) string {
+	return key[:len(key)-1]
+}
+
+// Children returns the quadkeys of the four tiles at the next higher
+// zoom level which make up the tile with the given quadkey, in the
+// order upper left, upper right, lower left, lower right. The quadkey
+// must have fewer than MaxZoom digits.
+func Children(key string) [4]string {
+	var children [4]string
+	for k := range children {
+		children[k] = key + string(rune('0'+k))
+	}
+	return children
+}
+
+// FromLatLng returns the quadkey of the tile at the given zoom level
+// which contains the point with the given latitude and longitude, in
+// degrees. The zoom level must be in the range [0, MaxZoom]. Latitudes
+// beyond the edges of the Web Mercator square, about 85.05 degrees
+// north and south, are clamped and longitudes are wrapped.
+func FromLatLng(lat, lng float64, zoom int) string {
+	t := tile.FromLatLng(lat, lng, zoom)
+	return FromTile(t.X, t.Y, zoom)
+}
@@ -0,0 +1,87 @@
+package quadkey
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestFromTile(t *testing.T) {
+	tests := []struct {
+		x, y uint32
+		zoom int
+		key  string
+	}{
+		{0, 0, 0, ""},
+		{1, 0, 1, "1"},
+		{0, 1, 1, "2"},
+		{3, 5, 3, "213"}, // The Bing Maps Tile System example
+		{1<<MaxZoom - 1, 0, MaxZoom, "1111111111111111111111111111111"},
+		{1<<MaxZoom - 1, 1<<MaxZoom - 1, MaxZoom, "3333333333333333333333333333333"},
+	}
+	for _, tt := range tests {
+		if key := FromTile(tt.x, tt.y, tt.zoom); key != tt.key {
+			t.Errorf("FromTile(%d, %d, %d) = %q, want %q", tt.x, tt.y, tt.zoom, key, tt.key)
+		}
+		x, y, zoom, err := ToTile(tt.key)
+		if x != tt.x || y != tt.y || zoom != tt.zoom || err != nil {
+			t.Errorf("ToTile(%q) = (%d, %d, %d, %v), want (%d, %d, %d, nil)", tt.key, x, y, zoom, err, tt.x, tt.y, tt.zoom)
+		}
+	}
+}
+
+func TestToTileInvalid(t *testing.T) {
+	for _, key := range []string{"124", "a", "0 1", "-1", "00000000000000000000000000000000"} {
+		if _, _, _, err := ToTile(key); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ToTile(%q) error = %v, want %v", key, err, ErrInvalid)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(25))
+	for range 10000 {
+		zoom := r.Intn(MaxZoom + 1)
+		x, y := uint32(r.Int63n(1<<zoom)), uint32(r.Int63n(1<<zoom))
+		key := FromTile(x, y, zoom)
+		if x2, y2, zoom2, err := ToTile(key); x2 != x || y2 != y || zoom2 != zoom || err != nil {
+			t.Fatalf("ToTile(FromTile(%d, %d, %d)) = (%d, %d, %d, %v)", x, y, zoom, x2, y2, zoom2, err)
+		}
+		if zoom > 0 && Parent(key) != FromTile(x/2, y/2, zoom-1) {
+			t.Fatalf("Parent(%q) = %q, want the tile (%d, %d)", key, Parent(key), x/2, y/2)
+		}
+	}
+}
+
+func TestChildren(t *testing.T) {
+	want := [4]string{"210", "211", "212", "213"}
+	if got := Children("21"); got != want {
+		t.Errorf("Children(%q) = %v, want %v", "21", got, want)
+	}
+	x, y, _, _ := ToTile("21")
+	for k, c := range want {
+		cx, cy, _, _ := ToTile(c)
+		if cx != 2*x+uint32(k%2) || cy != 2*y+uint32(k/2) || Parent(c) != "21" {
+			t.Errorf("child %q of %q is tile (%d, %d)", c, "21", cx, cy)
+		}
+	}
+}
+
+func TestFromLatLng(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		zoom     int
+		key      string
+	}{
+		{47.61, -122.33, 12, "021230030220"},
+		{0.1, 0.1, 4, "1222"},
+		{-0.1, 0.1, 4, "3000"},
+		{-90, -180, 2, "22"},
+		{90, 180, 3, "000"},
+	}
+	for _, tt := range tests {
+		if key := FromLatLng(tt.lat, tt.lng, tt.zoom); key != tt.key {
+			t.Errorf("FromLatLng(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.zoom, key, tt.key)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package quadtree
+
+import (
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/internal/bestfirst"
+)
+
+// KNN returns the k items of the tree nearest to a point, or all of
+// them if it has fewer, in order of their distances from the point,
+// measured by a metric. The distance to an item is that to the point of
+// its rectangle nearest in the plane, as geom.Rect.Clamp returns, which
+// is the item's point itself for an item at a point. Of items at the
+// same distance, it returns any. Items with empty rectangles are not
+// found.
+//
+// KNN searches the tree best first, dividing the node whose rectangle is
+// nearest into its items and children until an item is the nearest.
+func (t *Tree[T]) KNN(p geom.Point, k int, m geom.Metric) []geom.Neighbor[T] {
+	if k <= 0 {
+		return nil
+	}
+	var q bestfirst.Queue[*Node[T], T]
+	// The root is reached whatever its bounds, as it may hold items
+	// outside them.
+	q.PushNode(0, t.root)
+	return q.Nearest(k, func(n *Node[T]) {
+		for _, e := range n.items {
+			if !e.rect.IsEmpty() {
+				near := e.rect.Clamp(p)
+				q.PushItem(geom.Neighbor[T]{Item: e.item, Rect: e.rect, Point: near, Distance: m.Distance(p, near)})
+			}
+		}
+		for _, c := range n.children {
+			if c.count > 0 {
+				q.PushNode(m.Bound(p, c.bounds), c)
+			}
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/internal/indextest"
+)
+
+func TestKNN(t *testing.T) {
+	tr := New[string](geom.Rect{MinX: 0, MinY: 0, MaxX: 4, MaxY: 4}, Options{Capacity: 1})
+	for s, r := range indextest.Stores {
+		tr.Insert(r, s)
+	}
+	empty := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, Options{})
+	indextest.CheckStores(t, tr.KNN, empty.KNN)
+}
+
+func TestKNNRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(100))
+	for _, o := range []Options{{Capacity: 4}, {Capacity: 8, Median: true}} {
+		tr := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1000, MaxY: 1000}, o)
+		rects := make([]geom.Rect, 3000)
+		for i := range rects {
+			rects[i] = randomRect(r)
+			rects[i].MinX -= 100 // Some outside the root.
+			if i%100 == 0 {
+				rects[i] = geom.EmptyRect()
+			}
+			tr.Insert(rects[i], i)
+		}
+		indextest.CheckRandom(t, r, tr.KNN, rects, geom.Planar)
+	}
+	// Longitudes and latitudes, on the sphere.
+	tr := New[int](geom.Rect{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}, Options{})
+	rects := indextest.LngLatRects(r, 3000)
+	for i, rect := range rects {
+		tr.Insert(rect, i)
+	}
+	indextest.CheckRandom(t, r, tr.KNN, rects, geom.Haversine)
+}
@@ -0,0 +1,306 @@
+// Package quadtree is a quadtree, a spatial index of items with
+// rectangles which divides a square, or any rectangle, into four
+// quadrants, and each of them into four, wherever it holds many items,
+// so that the nodes of the tree form a hierarchy of regions of the plane.
+//
+// As a region quadtree, the tree divides each node into its four equal
+// quadrants: over the Web Mercator square, its nodes at a depth are the
+// tiles of that zoom level, so that counting or aggregating the items of
+// the nodes of a depth aggregates them by tile, and the nodes to draw
+// at a level of detail are those found by Walk. Each item is kept in the
+// smallest node which contains its rectangle, so that an item which
+// crosses the line between quadrants stays in the node above them. As a
+// point quadtree, the tree instead divides each node at the median of
+// the centers of its items, adapting to items which are not evenly
+// spread.
+package quadtree
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Options are the options of a tree. The zero value is a region
+// quadtree of up to 16 items a node and a depth of up to 20.
+type Options struct {
+	// MaxDepth is the depth beyond which nodes are not divided, the root
+	// being of depth 0, or 0 for 20.
+	MaxDepth int
+
+	// Capacity is the number of items of a node beyond which it is
+	// divided, or 0 for 16.
+	Capacity int
+
+	// Median, if true, divides each node at the medians of the X and Y
+	// of the centers of its items, as a point quadtree does, rather than
+	// at its center.
+	Median bool
+}
+
+// Tree is a quadtree of items of type T with rectangles.
+type Tree[T comparable] struct {
+	root *Node[T]
+	o    Options
+}
+
+// Node is a node of a tree, a rectangle of the plane and the items which
+// it holds.
+type Node[T comparable] struct {
+	bounds geom.Rect
+	depth  int
+	// center is where the node is divided, if it has children.
+	center geom.Point
+	// children are the quadrants of the node, in the order of the digits
+	// of quadkeys, or nil for a leaf.
+	children []*Node[T]
+	items    []entry[T]
+	// count is the number of items of the node and its descendants.
+	count int
+}
+
+// entry is an item and its rectangle.
+type entry[T comparable] struct {
+	rect geom.Rect
+	item T
+}
+
+// New returns an empty tree of a rectangle with options. Items outside
+// the rectangle may be inserted, and are kept in the root.
+func New[T comparable](bounds geom.Rect, o Options) *Tree[T] {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = 20
+	}
+	if o.Capacity <= 0 {
+		o.Capacity = 16
+	}
+	return &Tree[T]{root: &Node[T]{bounds: bounds}, o: o}
+}
+
+// Len returns the number of items of the tree.
+func (t *Tree[T]) Len() int {
+	return t.root.count
+}
+
+// Root returns the root of the tree.
+func (t *Tree[T]) Root() *Node[T] {
+	return t.root
+}
+
+// Insert inserts an item with a rectangle. An item may be inserted more
+// than once, even with the same rectangle.
+func (t *Tree[T]) Insert(r geom.Rect, item T) {
+	n := t.root
+	for {
+		n.count++
+		c := n.child(r)
+		if c == nil {
+			break
+		}
+		n = c
+	}
+	n.items = append(n.items, entry[T]{r, item})
+	if n.children == nil && len(n.items) > t.o.Capacity && n.depth < t.o.MaxDepth {
+		t.divide(n)
+	}
+}
+
+// child returns the child of a node which contains a rectangle, or nil
+// if it has none.
+func (n *Node[T]) child(r geom.Rect) *Node[T] {
+	for _, c := range n.children {
+		if c.bounds.ContainsRect(r) {
+			return c
+		}
+	}
+	return nil
+}
+
+// divide divides a leaf into quadrants, and moves the items which they
+// contain into them, dividing any of them which then have too many.
+func (t *Tree[T]) divide(n *Node[T]) {
+	b := n.bounds
+	n.center = b.Center()
+	if t.o.Median {
+		xs := make([]float64, len(n.items))
+		ys := make([]float64, len(n.items))
+		for i, e := range n.items {
+			c := e.rect.Center()
+			xs[i], ys[i] = c.X, c.Y
+		}
+		slices.Sort(xs)
+		slices.Sort(ys)
+		// The medians are clamped to the bounds, which the centers of
+		// items kept in the root may be outside.
+		n.center = geom.Point{X: max(b.MinX, min(b.MaxX, xs[len(xs)/2])), Y: max(b.MinY, min(b.MaxY, ys[len(ys)/2]))}
+	}
+	c := n.center
+	n.children = []*Node[T]{
+		{bounds: geom.Rect{MinX: b.MinX, MinY: c.Y, MaxX: c.X, MaxY: b.MaxY}, depth: n.depth + 1},
+		{bounds: geom.Rect{MinX: c.X, MinY: c.Y, MaxX: b.MaxX, MaxY: b.MaxY}, depth: n.depth + 1},
+		{bounds: geom.Rect{MinX: b.MinX, MinY: b.MinY, MaxX: c.X, MaxY: c.Y}, depth: n.depth + 1},
+		{bounds: geom.Rect{MinX: c.X, MinY: b.MinY, MaxX: b.MaxX, MaxY: c.Y}, depth: n.depth + 1},
+	}
+	kept := n.items[:0]
+	for _, e := range n.items {
+		if ch := n.child(e.rect); ch != nil {
+			ch.items = append(ch.items, e)
+			ch.count++
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	clear(n.items[len(kept):])
+	n.items = kept
+	for _, ch := range n.children {
+		if len(ch.items) > t.o.Capacity && ch.depth < t.o.MaxDepth {
+			t.divide(ch)
+		}
+	}
+}
+
+// Remove removes an item with a rectangle, reporting whether the tree
+// had it. If it was inserted more than once with the rectangle, it
+// removes only one of them. The nodes which then have no more items
+// than a node may hold are merged back into leaves.
+func (t *Tree[T]) Remove(r geom.Rect, item T) bool {
+	path := []*Node[T]{t.root}
+	for c := t.root.child(r); c != nil; c = c.child(r) {
+		path = append(path, c)
+	}
+	n := path[len(path)-1]
+	i := slices.Index(n.items, entry[T]{r, item})
+	if i < 0 {
+		return false
+	}
+	n.items = slices.Delete(n.items, i, i+1)
+	for _, p := range path {
+		p.count--
+	}
+	// Merge the highest node which may hold all of its items.
+	for _, p := range path {
+		if p.children != nil && p.count <= t.o.Capacity {
+			p.merge()
+			break
+		}
+	}
+	return true
+}
+
+// merge moves the items of the descendants of a node into it, making it
+// a leaf.
+func (n *Node[T]) merge() {
+	for _, c := range n.children {
+		c.merge()
+		n.items = append(n.items, c.items...)
+	}
+	n.children = nil
+}
+
+// Search returns an iterator over the items whose rectangles intersect
+// a rectangle, with their rectangles, in no particular order.
+func (t *Tree[T]) Search(r geom.Rect) iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		t.root.search(r, true, yield)
+	}
+}
+
+// search yields the items of a node and its descendants which intersect a
+// rectangle, reporting whether to go on. The root is searched whatever
+// its bounds, as it may hold items outside them.
+func (n *Node[T]) search(r geom.Rect, root bool, yield func(geom.Rect, T) bool) bool {
+	if !root && !n.bounds.Intersects(r) {
+		return true
+	}
+	for _, e := range n.items {
+		if e.rect.Intersects(r) && !yield(e.rect, e.item) {
+			return false
+		}
+	}
+	for _, c := range n.children {
+		if c.count > 0 && !c.search(r, false, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Walk walks the nodes of the tree from the root, depth first, calling
+// visit with each node, and descending into the children of those for
+// which it returns true, so that it may stop at the depth of a level of
+// detail, or at the nodes which are not in view. Nodes without items
+// are skipped.
+func (t *Tree[T]) Walk(visit func(*Node[T]) bool) {
+	t.root.walk(visit)
+}
+
+// walk walks a node and its descendants.
+func (n *Node[T]) walk(visit func(*Node[T]) bool) {
+	if n.count == 0 && n.depth > 0 || !visit(n) {
+		return
+	}
+	for _, c := range n.children {
+		c.walk(visit)
+	}
+}
+
+// Bounds returns the rectangle of the node. The rectangle of the root is
+// that of the tree, and those of the other nodes are quadrants of their
+// parents.
+func (n *Node[T]) Bounds() geom.Rect {
+	return n.bounds
+}
+
+// Depth returns the depth of the node, 0 for the root.
+func (n *Node[T]) Depth() int {
+	return n.depth
+}
+
+// Children returns the children of the node, the quadrants into which it
+// is divided, north-west, north-east, south-west and south-east, in the
+// order of the digits of quadkeys, or nil if it is a leaf.
+func (n *Node[T]) Children() []*Node[T] {
+	return n.children
+}
+
+// Count returns the number of items of the node and its descendants.
+func (n *Node[T]) Count() int {
+	return n.count
+}
+
+// Items returns an iterator over the items kept in the node, and not in
+// its descendants, with their rectangles.
+func (n *Node[T]) Items() iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		for _, e := range n.items {
+			if !yield(e.rect, e.item) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the items of the node and its
+// descendants, with their rectangles.
+func (n *Node[T]) All() iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		n.all(yield)
+	}
+}
+
+// all yields the items of a node and its descendants, reporting whether
+// to go on.
+func (n *Node[T]) all(yield func(geom.Rect, T) bool) bool {
+	for _, e := range n.items {
+		if !yield(e.rect, e.item) {
+			return false
+		}
+	}
+	for _, c := range n.children {
+		if !c.all(yield) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+package quadtree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// check checks the invariants of a tree: that the count of each node is
+// that of its items and its descendants', that its items are in no child,
+// that the items of the other nodes are in their bounds, that the
+// children are the quadrants of their parents, and that the leaves above
+// the maximum depth hold no more than the capacity.
+func check[T comparable](t *testing.T, tr *Tree[T]) {
+	t.Helper()
+	var walk func(n *Node[T]) int
+	walk = func(n *Node[T]) int {
+		count := len(n.items)
+		for _, e := range n.items {
+			if n.depth > 0 && !n.bounds.ContainsRect(e.rect) {
+				t.Fatalf("node %v holds an item of %v", n.bounds, e.rect)
+			}
+			if c := n.child(e.rect); c != nil {
+				t.Fatalf("node %v holds an item of %v in its child %v", n.bounds, e.rect, c.bounds)
+			}
+		}
+		if n.children == nil && len(n.items) > tr.o.Capacity && n.depth < tr.o.MaxDepth {
+			t.Fatalf("leaf %v of depth %d holds %d items", n.bounds, n.depth, len(n.items))
+		}
+		if n.children != nil {
+			b, c := n.bounds, n.center
+			want := []geom.Rect{
+				{MinX: b.MinX, MinY: c.Y, MaxX: c.X, MaxY: b.MaxY},
+				{MinX: c.X, MinY: c.Y, MaxX: b.MaxX, MaxY: b.MaxY},
+				{MinX: b.MinX, MinY: b.MinY, MaxX: c.X, MaxY: c.Y},
+				{MinX: c.X, MinY: b.MinY, MaxX: b.MaxX, MaxY: c.Y},
+			}
+			for i, ch := range n.children {
+				if ch.bounds != want[i] || ch.depth != n.depth+1 {
+					t.Fatalf("child %d of %v is %v of depth %d", i, b, ch.bounds, ch.depth)
+				}
+				count += walk(ch)
+			}
+		}
+		if count != n.count {
+			t.Fatalf("node %v has %d items, but a count of %d", n.bounds, count, n.count)
+		}
+		return count
+	}
+	walk(tr.root)
+}
+
+// randomRect returns a random rectangle in [0, 1000)², mostly small.
+func randomRect(r *rand.Rand) geom.Rect {
+	x, y := r.Float64()*1000, r.Float64()*1000
+	s := r.Float64() * 5
+	if r.Intn(20) == 0 {
+		s *= 50
+	}
+	return geom.Rect{MinX: x, MinY: y, MaxX: x + s, MaxY: y + s}
+}
+
+// search returns the items of a search, sorted.
+func search(tr *Tree[int], r geom.Rect) []int {
+	var items []int
+	for _, item := range tr.Search(r) {
+		items = append(items, item)
+	}
+	slices.Sort(items)
+	return items
+}
+
+// point returns the rectangle of a point.
+func point(x, y float64) geom.Rect {
+	return geom.Rect{MinX: x, MinY: y, MaxX: x, MaxY: y}
+}
+
+func TestQuadrants(t *testing.T) {
+	tr := New[string](geom.Rect{MinX: 0, MinY: 0, MaxX: 4, MaxY: 4}, Options{Capacity: 1})
+	tr.Insert(point(0.5, 3.5), "nw")
+	tr.Insert(point(3.5, 3.5), "ne")
+	tr.Insert(point(0.5, 0.5), "sw")
+	tr.Insert(point(3.5, 0.5), "se")
+	tr.Insert(geom.Rect{MinX: 1, MinY: 1, MaxX: 3, MaxY: 3}, "middle")
+	tr.Insert(point(9, 9), "outside")
+	check(t, tr)
+	root := tr.Root()
+	if root.Depth() != 0 || root.Count() != 6 || tr.Len() != 6 || len(root.Children()) != 4 {
+		t.Fatalf("root has depth %d, count %d and %d children", root.Depth(), root.Count(), len(root.Children()))
+	}
+	var kept []string
+	for _, item := range root.Items() {
+		kept = append(kept, item)
+	}
+	if want := []string{"middle", "outside"}; !slices.Equal(kept, want) {
+		t.Errorf("root holds %q, want %q", kept, want)
+	}
+	for i, want := range []struct {
+		bounds geom.Rect
+		item   string
+	}{
+		{geom.Rect{MinX: 0, MinY: 2, MaxX: 2, MaxY: 4}, "nw"},
+		{geom.Rect{MinX: 2, MinY: 2, MaxX: 4, MaxY: 4}, "ne"},
+		{geom.Rect{MinX: 0, MinY: 0, MaxX: 2, MaxY: 2}, "sw"},
+		{geom.Rect{MinX: 2, MinY: 0, MaxX: 4, MaxY: 2}, "se"},
+	} {
+		c := root.Children()[i]
+		var items []string
+		for _, item := range c.All() {
+			items = append(items, item)
+		}
+		if c.Bounds() != want.bounds || c.Depth() != 1 || !slices.Equal(items, []string{want.item}) {
+			t.Errorf("child %d is %v of depth %d holding %q, want %v holding %q", i, c.Bounds(), c.Depth(), items, want.bounds, want.item)
+		}
+	}
+	var found []string
+	for _, item := range tr.Search(geom.Rect{MinX: 3, MinY: 3, MaxX: 10, MaxY: 10}) {
+		found = append(found, item)
+	}
+	slices.Sort(found)
+	if want := []string{"middle", "ne", "outside"}; !slices.Equal(found, want) {
+		t.Errorf("Search = %q, want %q", found, want)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	tr := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, Options{MaxDepth: 3, Capacity: 2})
+	for i := range 100 {
+		tr.Insert(point(0.1, 0.1), i)
+	}
+	check(t, tr)
+	deepest := tr.Root()
+	for deepest.Children() != nil {
+		deepest = deepest.Children()[2]
+	}
+	if deepest.Depth() != 3 || deepest.Count() != 100 {
+		t.Errorf("deepest node has depth %d and count %d, want 3 and 100", deepest.Depth(), deepest.Count())
+	}
+	// The default options.
+	tr = New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, Options{})
+	if tr.o.MaxDepth != 20 || tr.o.Capacity != 16 {
+		t.Errorf("default options are %+v", tr.o)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	// Items crowded into a corner are divided where they are.
+	tr := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100}, Options{Capacity: 4, Median: true})
+	for i := range 5 {
+		tr.Insert(point(float64(i), float64(10-i)), i)
+	}
+	check(t, tr)
+	if c := tr.Root().center; c != (geom.Point{X: 2, Y: 8}) {
+		t.Errorf("root is divided at %v, want (2, 8)", c)
+	}
+	// A median outside the bounds is clamped to them.
+	tr = New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100}, Options{Capacity: 2, Median: true})
+	for i := range 3 {
+		tr.Insert(point(200, 50), i)
+	}
+	check(t, tr)
+	if c := tr.Root().center; c != (geom.Point{X: 100, Y: 50}) {
+		t.Errorf("root is divided at %v, want (100, 50)", c)
+	}
+}
+
+func TestInsertRemove(t *testing.T) {
+	for _, o := range []Options{{}, {Capacity: 4, MaxDepth: 6}, {Capacity: 8, Median: true}} {
+		r := rand.New(rand.NewSource(98))
+		tr := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1000, MaxY: 1000}, o)
+		rects := make([]geom.Rect, 3000)
+		for i := range rects {
+			rects[i] = randomRect(r)
+			tr.Insert(rects[i], i)
+		}
+		check(t, tr)
+		removed := map[int]bool{}
+		for i := 0; i < len(rects); i += 2 {
+			if !tr.Remove(rects[i], i) {
+				t.Fatalf("%+v: Remove(%v, %d) = false", o, rects[i], i)
+			}
+			removed[i] = true
+			if i%500 == 0 {
+				check(t, tr)
+			}
+		}
+		check(t, tr)
+		if tr.Len() != 1500 || tr.Remove(rects[0], 0) || tr.Remove(rects[1], 2) {
+			t.Errorf("%+v: Len = %d, or a removed item was removed again", o, tr.Len())
+		}
+		for range 100 {
+			q := randomRect(r)
+			q.MaxX += 50
+			var want []int
+			for i, s := range rects {
+				if !removed[i] && q.Intersects(s) {
+					want = append(want, i)
+				}
+			}
+			if got := search(tr, q); !slices.Equal(got, want) {
+				t.Fatalf("%+v: Search(%v) = %v, want %v", o, q, got, want)
+			}
+		}
+		for i := 1; i < len(rects); i += 2 {
+			tr.Remove(rects[i], i)
+		}
+		check(t, tr)
+		if tr.Len() != 0 || tr.Root().Children() != nil {
+			t.Errorf("%+v: tree of all items removed has Len %d and children", o, tr.Len())
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	r := rand.New(rand.NewSource(98))
+	tr := New[int](geom.Rect{MinX: 0, MinY: 0, MaxX: 1000, MaxY: 1000}, Options{Capacity: 4})
+	for i := range 500 {
+		// Items of the south-west quadrant only, so that the others are
+		// empty.
+		x, y := r.Float64()*500, r.Float64()*500
+		tr.Insert(point(x, y), i)
+	}
+	// The counts of the nodes of depth 2, the tiles of zoom level 2, sum
+	// to those of the items which they contain.
+	var tiles, total int
+	tr.Walk(func(n *Node[int]) bool {
+		if n.Count() == 0 && n.Depth() > 0 {
+			t.Errorf("Walk visited the empty node %v", n.Bounds())
+		}
+		if n.Depth() == 2 {
+			tiles++
+			total += n.Count()
+		}
+		return n.Depth() < 2
+	})
+	kept := 0
+	tr.Walk(func(n *Node[int]) bool {
+		if n.Depth() < 2 {
+			for range n.Items() {
+				kept++
+			}
+		}
+		return n.Depth() < 2
+	})
+	if tiles != 4 || total+kept != 500 {
+		t.Errorf("Walk visited %d tiles of %d items, and %d above, want 4 tiles of 500", tiles, total, kept)
+	}
+	n := 0
+	for range tr.Root().All() {
+		if n++; n == 10 {
+			break
+		}
+	}
+	for range tr.Search(tr.Root().Bounds()) {
+		if n++; n == 20 {
+			break
+		}
+	}
+	if n != 20 {
+		t.Errorf("All and Search yielded %d items before the breaks, want 20", n)
+	}
+}
@@ -0,0 +1,36 @@
+package rtree
+
+import (
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/internal/bestfirst"
+)
+
+// KNN returns the k items of the tree nearest to a point, or all of
+// them if it has fewer, in order of their distances from the point,
+// measured by a metric. The distance to an item is that to the point of
+// its rectangle nearest in the plane, as geom.Rect.Clamp returns, which
+// is the item's point itself for an item at a point. Of items at the
+// same distance, it returns any. Items with empty rectangles are not
+// found.
+//
+// KNN searches the tree best first, descending into the node whose
+// rectangle is nearest until an item is the nearest, so that it looks
+// at no node farther than the kth item.
+func (t *Tree[T]) KNN(p geom.Point, k int, m geom.Metric) []geom.Neighbor[T] {
+	if t.root == nil || k <= 0 {
+		return nil
+	}
+	var q bestfirst.Queue[*node[T], T]
+	q.PushNode(0, t.root)
+	return q.Nearest(k, func(n *node[T]) {
+		for _, e := range n.entries {
+			switch {
+			case n.level > 0:
+				q.PushNode(m.Bound(p, e.rect), e.child)
+			case !e.rect.IsEmpty():
+				near := e.rect.Clamp(p)
+				q.PushItem(geom.Neighbor[T]{Item: e.item, Rect: e.rect, Point: near, Distance: m.Distance(p, near)})
+			}
+		}
+	})
+}
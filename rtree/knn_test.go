@@ -0,0 +1,39 @@
+package rtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/internal/indextest"
+)
+
+func TestKNN(t *testing.T) {
+	var tr Tree[string]
+	for s, r := range indextest.Stores {
+		tr.Insert(r, s)
+	}
+	indextest.CheckStores(t, tr.KNN, new(Tree[int]).KNN)
+}
+
+func TestKNNRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(100))
+	var tr Tree[int]
+	rects := make([]geom.Rect, 3000)
+	for i := range rects {
+		rects[i] = randomRect(r)
+		if i%100 == 0 {
+			rects[i] = geom.EmptyRect()
+		}
+		tr.Insert(rects[i], i)
+	}
+	indextest.CheckRandom(t, r, tr.KNN, rects, geom.Planar)
+	// Longitudes and latitudes, on the sphere, of a loaded tree.
+	rects = indextest.LngLatRects(r, 3000)
+	items := make([]int, len(rects))
+	for i := range items {
+		items[i] = i
+	}
+	lt := Load(items, func(i int) geom.Rect { return rects[i] })
+	indextest.CheckRandom(t, r, lt.KNN, rects, geom.Haversine)
+}
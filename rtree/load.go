@@ -0,0 +1,70 @@
+package rtree
+
+import (
+	"math"
+
+	"github.com/gogama/geospat/geom"
+	"github.com/gogama/geospat/hilbert"
+)
+
+// hilbertOrder is the order of the Hilbert curve along which Load sorts
+// items.
+const hilbertOrder = 16
+
+// Load returns a tree of items with the rectangles which rect returns,
+// which it builds at once, much faster than by inserting the items one
+// at a time, and with less overlap between its nodes, so that it is
+// faster to search. It sorts the items along a Hilbert curve through
+// their extent, by the centers of their rectangles, packs them in that
+// order into leaves, and packs the leaves, level by level, into the
+// nodes above. Items may be inserted into and deleted from the tree
+// afterward.
+func Load[T comparable](items []T, rect func(T) geom.Rect) *Tree[T] {
+	t := &Tree[T]{len: len(items)}
+	if len(items) == 0 {
+		return t
+	}
+	entries := make([]entry[T], len(items))
+	extent := geom.EmptyRect()
+	for i, it := range items {
+		entries[i] = entry[T]{rect: rect(it), item: it}
+		extent = extent.Union(entries[i].rect)
+	}
+	c, _ := hilbert.New(hilbertOrder)
+	scale := func(v, min, width float64) uint32 {
+		if width == 0 {
+			return 0
+		}
+		return uint32(math.Floor(float64(c.N()-1) * (v - min) / width))
+	}
+	hilbert.Sort(c, entries, func(e entry[T]) (uint32, uint32) {
+		if e.rect.IsEmpty() {
+			return 0, 0
+		}
+		p := e.rect.Center()
+		return scale(p.X, extent.MinX, extent.Width()), scale(p.Y, extent.MinY, extent.Height())
+	})
+	for level := 0; ; level++ {
+		nodes := pack(entries, level)
+		if len(nodes) == 1 {
+			t.root = nodes[0]
+			return t
+		}
+		entries = make([]entry[T], len(nodes))
+		for i, n := range nodes {
+			entries[i] = entry[T]{rect: n.bounds(), child: n}
+		}
+	}
+}
+
+// pack packs entries in order into the nodes of a level, as few as
+// hold them, dividing them evenly so that each node has at least
+// minEntries.
+func pack[T comparable](entries []entry[T], level int) []*node[T] {
+	k := (len(entries) + maxEntries - 1) / maxEntries
+	nodes := make([]*node[T], k)
+	for i := range nodes {
+		nodes[i] = &node[T]{level: level, entries: entries[i*len(entries)/k : (i+1)*len(entries)/k : (i+1)*len(entries)/k]}
+	}
+	return nodes
+}
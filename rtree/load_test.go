@@ -0,0 +1,129 @@
+package rtree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestLoad(t *testing.T) {
+	r := rand.New(rand.NewSource(96))
+	for _, n := range []int{0, 1, 16, 17, 33, 256, 257, 5000} {
+		rects := make([]geom.Rect, n)
+		items := make([]int, n)
+		for i := range rects {
+			rects[i], items[i] = randomRect(r), i
+		}
+		tr := Load(items, func(i int) geom.Rect { return rects[i] })
+		check(t, tr)
+		if tr.Len() != n {
+			t.Errorf("Load of %d items has Len %d", n, tr.Len())
+		}
+		if n == 0 {
+			if tr.root != nil {
+				t.Errorf("Load of no items has a root")
+			}
+			continue
+		}
+		// The leaves are as few as hold the items, and the tree as low.
+		leaves, height := 0, 0
+		for m := n; m > 1; m = (m + maxEntries - 1) / maxEntries {
+			height++
+		}
+		var count func(n *node[int])
+		count = func(n *node[int]) {
+			if n.level == 0 {
+				leaves++
+			}
+			for _, e := range n.entries {
+				if e.child != nil {
+					count(e.child)
+				}
+			}
+		}
+		count(tr.root)
+		if want := (n + maxEntries - 1) / maxEntries; leaves != want || tr.root.level != max(height-1, 0) {
+			t.Errorf("Load of %d items has %d leaves and height %d, want %d and %d", n, leaves, tr.root.level, want, max(height-1, 0))
+		}
+		for range 50 {
+			q := randomRect(r)
+			q.MaxX += 100
+			if got, want := search(tr, q), brute(rects, nil, q); !slices.Equal(got, want) {
+				t.Fatalf("Search(%v) of a loaded tree = %v, want %v", q, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadThenEdit(t *testing.T) {
+	r := rand.New(rand.NewSource(96))
+	rects := make([]geom.Rect, 2000)
+	items := make([]int, len(rects))
+	for i := range rects {
+		rects[i], items[i] = randomRect(r), i
+	}
+	tr := Load(items[:1000], func(i int) geom.Rect { return rects[i] })
+	for i := 1000; i < 2000; i++ {
+		tr.Insert(rects[i], i)
+	}
+	deleted := map[int]bool{}
+	for i := 0; i < 2000; i += 3 {
+		if !tr.Delete(rects[i], i) {
+			t.Fatalf("Delete(%v, %d) of a loaded tree = false", rects[i], i)
+		}
+		deleted[i] = true
+	}
+	check(t, tr)
+	for range 50 {
+		q := randomRect(r)
+		q.MaxY += 100
+		if got, want := search(tr, q), brute(rects, deleted, q); !slices.Equal(got, want) {
+			t.Fatalf("Search(%v) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+func TestLoadDegenerate(t *testing.T) {
+	// Items at one point, whose extent has no width or height, and items
+	// of empty rectangles.
+	rects := make([]geom.Rect, 100)
+	items := make([]int, len(rects))
+	for i := range rects {
+		rects[i], items[i] = geom.Rect{MinX: 3, MinY: 4, MaxX: 3, MaxY: 4}, i
+		if i%10 == 0 {
+			rects[i] = geom.EmptyRect()
+		}
+	}
+	tr := Load(items, func(i int) geom.Rect { return rects[i] })
+	check(t, tr)
+	if got := search(tr, geom.Rect{MinX: 3, MinY: 4, MaxX: 3, MaxY: 4}); len(got) != 90 {
+		t.Errorf("Search of the point found %d items, want 90", len(got))
+	}
+	if got, want := tr.Bounds(), (geom.Rect{MinX: 3, MinY: 4, MaxX: 3, MaxY: 4}); got != want {
+		t.Errorf("Bounds = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	r := rand.New(rand.NewSource(96))
+	rects := make([]geom.Rect, 100000)
+	items := make([]int, len(rects))
+	for i := range rects {
+		rects[i], items[i] = randomRect(r), i
+	}
+	b.Run("Load", func(b *testing.B) {
+		for range b.N {
+			Load(items, func(i int) geom.Rect { return rects[i] })
+		}
+	})
+	b.Run("Insert", func(b *testing.B) {
+		for range b.N {
+			var tr Tree[int]
+			for i, r := range rects {
+				tr.Insert(r, i)
+			}
+		}
+	})
+}
@@ -0,0 +1,389 @@
+// Package rtree is an in-memory R-tree, a spatial index of items with
+// rectangles, such as the bounding boxes of geometries, which finds the
+// items whose rectangles intersect a rectangle, or those nearest to a
+// point, without looking at most of the others.
+//
+// An R-tree is a balanced tree whose nodes each hold up to a fixed
+// number of entries, a leaf's being the items and another node's being
+// its children, each with the rectangle bounding it. A search descends
+// only into the children whose rectangles intersect its own. The tree
+// is built as an R*-tree: an item is inserted where it least enlarges
+// the overlap of the leaves, or the area of the other nodes, a node
+// which overflows first has the entries farthest from its center
+// reinserted, so that the tree adapts as it grows, and a node is split
+// where the two halves have the smallest margins and overlap. Load
+// instead builds a tree of many items at once, packing them into nodes
+// in their order along a Hilbert curve.
+//
+// The R*-tree is described by Beckmann, Kriegel, Schneider and Seeger,
+// "The R*-tree: An Efficient and Robust Access Method for Points and
+// Rectangles", SIGMOD 1990.
+package rtree
+
+import (
+	"iter"
+	"math"
+	"slices"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// The numbers of entries of a node.
+const (
+	// maxEntries is the number of entries of a node above which it is
+	// split.
+	maxEntries = 16
+
+	// minEntries is the number of entries of a node other than the root
+	// below which it is removed and its entries reinserted, 40% of
+	// maxEntries as the R*-tree recommends.
+	minEntries = maxEntries * 2 / 5
+
+	// reinsertEntries is the number of entries of a node which are
+	// reinserted when it first overflows, 30% of maxEntries.
+	reinsertEntries = maxEntries * 3 / 10
+)
+
+// Tree is an R-tree of items of type T with rectangles. The zero value
+// is an empty tree.
+type Tree[T comparable] struct {
+	root *node[T]
+	len  int
+}
+
+// node is a node of a tree.
+type node[T comparable] struct {
+	// level is the height of the node above the leaves, whose level is
+	// 0.
+	level   int
+	entries []entry[T]
+}
+
+// entry is an entry of a node, a child or, in a leaf, an item.
+type entry[T comparable] struct {
+	rect  geom.Rect
+	child *node[T]
+	item  T
+}
+
+// bounds returns the rectangle bounding the entries of a node.
+func (n *node[T]) bounds() geom.Rect {
+	r := geom.EmptyRect()
+	for _, e := range n.entries {
+		r = r.Union(e.rect)
+	}
+	return r
+}
+
+// Len returns the number of items of the tree.
+func (t *Tree[T]) Len() int {
+	return t.len
+}
+
+// Bounds returns the rectangle bounding the items of the tree, which is
+// empty if it has none.
+func (t *Tree[T]) Bounds() geom.Rect {
+	if t.root == nil {
+		return geom.EmptyRect()
+	}
+	return t.root.bounds()
+}
+
+// Insert inserts an item with a rectangle. An item may be inserted more
+// than once, even with the same rectangle. An item with an empty
+// rectangle is found by no search.
+func (t *Tree[T]) Insert(r geom.Rect, item T) {
+	if t.root == nil {
+		t.root = &node[T]{}
+	}
+	var reinserted uint64
+	t.insert(entry[T]{rect: r, item: item}, 0, &reinserted)
+	t.len++
+}
+
+// insert inserts an entry into a node of a level, treating the overflow
+// of each level by reinsertion only once, for which reinserted holds a
+// bit by level.
+func (t *Tree[T]) insert(e entry[T], level int, reinserted *uint64) {
+	// path holds the nodes from the root to that of the level, and
+	// index the indexes of the entries of each of the nodes which are
+	// the next.
+	path := []*node[T]{t.root}
+	var index []int
+	for n := t.root; n.level > level; {
+		i := chooseSubtree(n, e.rect)
+		index = append(index, i)
+		n = n.entries[i].child
+		path = append(path, n)
+	}
+	n := path[len(path)-1]
+	n.entries = append(n.entries, e)
+	for i := len(index) - 1; i >= 0; i-- {
+		pe := &path[i].entries[index[i]]
+		pe.rect = pe.rect.Union(e.rect)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		n := path[i]
+		if len(n.entries) <= maxEntries {
+			return
+		}
+		if bit := uint64(1) << n.level; i > 0 && *reinserted&bit == 0 {
+			*reinserted |= bit
+			removed := farthest(n)
+			for j := i - 1; j >= 0; j-- {
+				path[j].entries[index[j]].rect = path[j+1].bounds()
+			}
+			// Reinsert the closest first, as the R*-tree recommends.
+			for j := len(removed) - 1; j >= 0; j-- {
+				t.insert(removed[j], n.level, reinserted)
+			}
+			return
+		}
+		m := split(n)
+		if i == 0 {
+			t.root = &node[T]{level: n.level + 1, entries: []entry[T]{
+				{rect: n.bounds(), child: n},
+				{rect: m.bounds(), child: m},
+			}}
+			return
+		}
+		p := path[i-1]
+		p.entries[index[i-1]].rect = n.bounds()
+		p.entries = append(p.entries, entry[T]{rect: m.bounds(), child: m})
+	}
+}
+
+// chooseSubtree returns the index of the entry of a node into which to
+// insert a rectangle: of the children of the leaves, that whose overlap
+// with the others it enlarges least, and otherwise that whose area it
+// enlarges least, ties being broken by the least area.
+func chooseSubtree[T comparable](n *node[T], r geom.Rect) int {
+	best := -1
+	var bestOverlap, bestEnlargement, bestArea float64
+	for i, e := range n.entries {
+		u := e.rect.Union(r)
+		area := e.rect.Area()
+		enlargement := u.Area() - area
+		var overlap float64
+		if n.level == 1 {
+			for j, o := range n.entries {
+				if j != i {
+					overlap += u.Intersection(o.rect).Area() - e.rect.Intersection(o.rect).Area()
+				}
+			}
+		}
+		if best < 0 || overlap < bestOverlap ||
+			overlap == bestOverlap && (enlargement < bestEnlargement || enlargement == bestEnlargement && area < bestArea) {
+			best, bestOverlap, bestEnlargement, bestArea = i, overlap, enlargement, area
+		}
+	}
+	return best
+}
+
+// farthest removes from a node the entries whose centers are farthest
+// from its center, and returns them from the farthest.
+func farthest[T comparable](n *node[T]) []entry[T] {
+	c := n.bounds().Center()
+	dist := func(e entry[T]) float64 {
+		p := e.rect.Center()
+		return (p.X-c.X)*(p.X-c.X) + (p.Y-c.Y)*(p.Y-c.Y)
+	}
+	slices.SortStableFunc(n.entries, func(a, b entry[T]) int {
+		return -cmpFloat(dist(a), dist(b))
+	})
+	removed := slices.Clone(n.entries[:reinsertEntries])
+	n.entries = slices.Delete(n.entries, 0, reinsertEntries)
+	return removed
+}
+
+// cmpFloat compares two floats.
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// split splits a node which has overflowed, leaving it some of its
+// entries and returning a new node of the others: along the axis for
+// which the distributions of the entries, sorted by their lower and
+// upper bounds, have the least sum of margins, the distribution of least
+// overlap, ties being broken by the least area.
+func split[T comparable](n *node[T]) *node[T] {
+	sorts := func(axis int) [2][]entry[T] {
+		lo := func(e entry[T]) float64 { return [2]float64{e.rect.MinX, e.rect.MinY}[axis] }
+		hi := func(e entry[T]) float64 { return [2]float64{e.rect.MaxX, e.rect.MaxY}[axis] }
+		byLo := slices.Clone(n.entries)
+		slices.SortStableFunc(byLo, func(a, b entry[T]) int {
+			if c := cmpFloat(lo(a), lo(b)); c != 0 {
+				return c
+			}
+			return cmpFloat(hi(a), hi(b))
+		})
+		byHi := slices.Clone(n.entries)
+		slices.SortStableFunc(byHi, func(a, b entry[T]) int {
+			if c := cmpFloat(hi(a), hi(b)); c != 0 {
+				return c
+			}
+			return cmpFloat(lo(a), lo(b))
+		})
+		return [2][]entry[T]{byLo, byHi}
+	}
+	bestAxis, bestMargin := 0, math.Inf(1)
+	var candidates [2][2][]entry[T]
+	for axis := range 2 {
+		candidates[axis] = sorts(axis)
+		var margin float64
+		for _, s := range candidates[axis] {
+			forDistributions(s, func(k int, a, b geom.Rect) {
+				margin += a.Width() + a.Height() + b.Width() + b.Height()
+			})
+		}
+		if margin < bestMargin {
+			bestAxis, bestMargin = axis, margin
+		}
+	}
+	var best []entry[T]
+	bestK := 0
+	bestOverlap, bestArea := math.Inf(1), math.Inf(1)
+	for _, s := range candidates[bestAxis] {
+		forDistributions(s, func(k int, a, b geom.Rect) {
+			overlap, area := a.Intersection(b).Area(), a.Area()+b.Area()
+			if overlap < bestOverlap || overlap == bestOverlap && area < bestArea {
+				best, bestK, bestOverlap, bestArea = s, k, overlap, area
+			}
+		})
+	}
+	n.entries = slices.Clone(best[:bestK])
+	return &node[T]{level: n.level, entries: slices.Clone(best[bestK:])}
+}
+
+// forDistributions calls f for each distribution of sorted entries into
+// the first k and the others, each of at least minEntries, with the
+// rectangles bounding either group.
+func forDistributions[T comparable](s []entry[T], f func(k int, a, b geom.Rect)) {
+	// suffix[i] bounds the entries from i.
+	suffix := make([]geom.Rect, len(s)+1)
+	suffix[len(s)] = geom.EmptyRect()
+	for i := len(s) - 1; i >= 0; i-- {
+		suffix[i] = suffix[i+1].Union(s[i].rect)
+	}
+	prefix := geom.EmptyRect()
+	for k := 1; k <= len(s)-minEntries; k++ {
+		prefix = prefix.Union(s[k-1].rect)
+		if k >= minEntries {
+			f(k, prefix, suffix[k])
+		}
+	}
+}
+
+// Delete deletes an item with a rectangle, reporting whether the tree
+// had it. If it was inserted more than once with the rectangle, it
+// deletes only one of them.
+func (t *Tree[T]) Delete(r geom.Rect, item T) bool {
+	if t.root == nil {
+		return false
+	}
+	path, index := t.find(t.root, r, item, nil, nil)
+	if path == nil {
+		return false
+	}
+	leaf := path[len(path)-1]
+	leaf.entries = slices.Delete(leaf.entries, index[len(index)-1], index[len(index)-1]+1)
+	t.len--
+	// Remove the nodes which have too few entries, and reinsert their
+	// entries.
+	var orphans []*node[T]
+	for i := len(path) - 1; i > 0; i-- {
+		n, p, j := path[i], path[i-1], index[i-1]
+		if len(n.entries) < minEntries {
+			p.entries = slices.Delete(p.entries, j, j+1)
+			orphans = append(orphans, n)
+		} else {
+			p.entries[j].rect = n.bounds()
+		}
+	}
+	var reinserted uint64
+	for _, n := range orphans {
+		for _, e := range n.entries {
+			t.insert(e, n.level, &reinserted)
+		}
+	}
+	for t.root.level > 0 && len(t.root.entries) == 1 {
+		t.root = t.root.entries[0].child
+	}
+	if t.len == 0 {
+		t.root = nil
+	}
+	return true
+}
+
+// find returns the path from a node to the leaf which holds an item with
+// a rectangle, and the indexes of the entries of each node of the path
+// which are the next, the last being that of the item, or nil if it is
+// not found.
+func (t *Tree[T]) find(n *node[T], r geom.Rect, item T, path []*node[T], index []int) ([]*node[T], []int) {
+	path = append(path, n)
+	for i, e := range n.entries {
+		if n.level == 0 {
+			if e.item == item && e.rect == r {
+				return path, append(index, i)
+			}
+			continue
+		}
+		if !e.rect.ContainsRect(r) && !r.IsEmpty() {
+			continue
+		}
+		if p, x := t.find(e.child, r, item, path, append(index, i)); p != nil {
+			return p, x
+		}
+	}
+	return nil, nil
+}
+
+// Search returns an iterator over the items whose rectangles intersect
+// a rectangle, with their rectangles, in no particular order.
+func (t *Tree[T]) Search(r geom.Rect) iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		t.Traverse(r.Intersects, func(s geom.Rect, item T) bool {
+			return !r.Intersects(s) || yield(s, item)
+		})
+	}
+}
+
+// All returns an iterator over the items of the tree, with their
+// rectangles, in no particular order.
+func (t *Tree[T]) All() iter.Seq2[geom.Rect, T] {
+	return func(yield func(geom.Rect, T) bool) {
+		t.Traverse(func(geom.Rect) bool { return true }, yield)
+	}
+}
+
+// Traverse traverses the tree from the root, calling node with the
+// rectangle of each node other than the root, and item with each item
+// of the leaves which it reaches, with its rectangle. It descends only
+// into the nodes for which node returns true, and stops when item
+// returns false, so that it may search the tree for what other than a
+// rectangle, such as the items within a distance of a point.
+func (t *Tree[T]) Traverse(node func(geom.Rect) bool, item func(geom.Rect, T) bool) {
+	if t.root != nil {
+		t.root.traverse(node, item)
+	}
+}
+
+// traverse traverses a node, reporting whether to go on.
+func (n *node[T]) traverse(node func(geom.Rect) bool, item func(geom.Rect, T) bool) bool {
+	for _, e := range n.entries {
+		if n.level == 0 {
+			if !item(e.rect, e.item) {
+				return false
+			}
+		} else if node(e.rect) && !e.child.traverse(node, item) {
+			return false
+		}
+	}
+	return true
+}
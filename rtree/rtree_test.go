@@ -0,0 +1,243 @@
+package rtree
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// check checks the invariants of a tree: that its leaves are all of level
+// 0, that the rectangle of each child bounds its entries, that each node
+// other than the root has from minEntries to maxEntries entries, and that
+// it has Len items.
+func check[T comparable](t *testing.T, tr *Tree[T]) {
+	t.Helper()
+	if tr.root == nil {
+		if tr.len != 0 {
+			t.Fatalf("tree of no root has Len %d", tr.len)
+		}
+		return
+	}
+	items := 0
+	var walk func(n *node[T], root bool)
+	walk = func(n *node[T], root bool) {
+		if len(n.entries) > maxEntries || !root && len(n.entries) < minEntries {
+			t.Fatalf("node of level %d has %d entries", n.level, len(n.entries))
+		}
+		for _, e := range n.entries {
+			if n.level == 0 {
+				items++
+				continue
+			}
+			if e.child.level != n.level-1 {
+				t.Fatalf("child of level %d of a node of level %d", e.child.level, n.level)
+			}
+			if b := e.child.bounds(); e.rect != b {
+				t.Fatalf("rectangle of a child is %v, not its bounds %v", e.rect, b)
+			}
+			walk(e.child, false)
+		}
+	}
+	walk(tr.root, true)
+	if items != tr.len {
+		t.Fatalf("tree has %d items, but Len %d", items, tr.len)
+	}
+}
+
+// randomRect returns a random rectangle in [0, 1000)², of sides up to 20.
+func randomRect(r *rand.Rand) geom.Rect {
+	x, y := r.Float64()*1000, r.Float64()*1000
+	return geom.Rect{MinX: x, MinY: y, MaxX: x + r.Float64()*20, MaxY: y + r.Float64()*20}
+}
+
+// search returns the items of a search, sorted.
+func search(tr *Tree[int], r geom.Rect) []int {
+	var items []int
+	for _, item := range tr.Search(r) {
+		items = append(items, item)
+	}
+	slices.Sort(items)
+	return items
+}
+
+// brute returns the indexes of the rectangles which intersect a
+// rectangle, that are not deleted.
+func brute(rects []geom.Rect, deleted map[int]bool, r geom.Rect) []int {
+	var items []int
+	for i, s := range rects {
+		if !deleted[i] && r.Intersects(s) {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+func TestSearch(t *testing.T) {
+	var tr Tree[string]
+	tr.Insert(geom.Rect{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}, "a")
+	tr.Insert(geom.Rect{MinX: 2, MinY: 2, MaxX: 3, MaxY: 4}, "b")
+	tr.Insert(geom.Rect{MinX: 5, MinY: -1, MaxX: 5, MaxY: -1}, "c")
+	tr.Insert(geom.EmptyRect(), "d")
+	tests := []struct {
+		r    geom.Rect
+		want []string
+	}{
+		{geom.Rect{MinX: 0.5, MinY: 0.5, MaxX: 2, MaxY: 2}, []string{"a", "b"}},
+		{geom.Rect{MinX: 1, MinY: 1, MaxX: 1, MaxY: 1}, []string{"a"}},
+		{geom.Rect{MinX: 4, MinY: -2, MaxX: 6, MaxY: 0}, []string{"c"}},
+		{geom.Rect{MinX: 1.5, MinY: 0, MaxX: 1.9, MaxY: 10}, nil},
+		{geom.Rect{MinX: -10, MinY: -10, MaxX: 10, MaxY: 10}, []string{"a", "b", "c"}},
+		{geom.EmptyRect(), nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, item := range tr.Search(tt.r) {
+			got = append(got, item)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%v) = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+	if got, want := tr.Bounds(), (geom.Rect{MinX: 0, MinY: -1, MaxX: 5, MaxY: 4}); got != want {
+		t.Errorf("Bounds = %v, want %v", got, want)
+	}
+	n := 0
+	for range tr.All() {
+		n++
+	}
+	if n != 4 || tr.Len() != 4 {
+		t.Errorf("All yielded %d items and Len is %d, want 4", n, tr.Len())
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	var tr Tree[int]
+	if tr.Len() != 0 || !tr.Bounds().IsEmpty() || tr.Delete(geom.Rect{}, 0) {
+		t.Errorf("zero tree has Len %d, Bounds %v, or deleted an item", tr.Len(), tr.Bounds())
+	}
+	for range tr.All() {
+		t.Error("All of a zero tree yielded an item")
+	}
+}
+
+func TestInsertDelete(t *testing.T) {
+	r := rand.New(rand.NewSource(95))
+	var tr Tree[int]
+	var rects []geom.Rect
+	deleted := map[int]bool{}
+	for i := range 3000 {
+		rects = append(rects, randomRect(r))
+		tr.Insert(rects[i], i)
+		if i%250 == 0 {
+			check(t, &tr)
+		}
+	}
+	check(t, &tr)
+	if tr.root.level < 2 {
+		t.Errorf("tree of %d items has height %d", tr.Len(), tr.root.level)
+	}
+	for range 100 {
+		q := randomRect(r)
+		q.MaxX += 50
+		if got, want := search(&tr, q), brute(rects, deleted, q); !slices.Equal(got, want) {
+			t.Fatalf("Search(%v) = %v, want %v", q, got, want)
+		}
+	}
+	for i := range 2000 {
+		if !tr.Delete(rects[i], i) {
+			t.Fatalf("Delete(%v, %d) = false", rects[i], i)
+		}
+		deleted[i] = true
+		if i%250 == 0 {
+			check(t, &tr)
+		}
+	}
+	check(t, &tr)
+	if tr.Len() != 1000 || tr.Delete(rects[0], 0) || tr.Delete(rects[2500], 2499) {
+		t.Errorf("Len = %d, or a deleted item was deleted again", tr.Len())
+	}
+	for range 100 {
+		q := randomRect(r)
+		q.MaxY += 50
+		if got, want := search(&tr, q), brute(rects, deleted, q); !slices.Equal(got, want) {
+			t.Fatalf("after deletion, Search(%v) = %v, want %v", q, got, want)
+		}
+	}
+	for i := 2000; i < 3000; i++ {
+		tr.Delete(rects[i], i)
+	}
+	if tr.root != nil || tr.Len() != 0 || !tr.Bounds().IsEmpty() {
+		t.Errorf("tree of all items deleted has Len %d and Bounds %v", tr.Len(), tr.Bounds())
+	}
+}
+
+func TestDeleteDuplicates(t *testing.T) {
+	var tr Tree[int]
+	r := geom.Rect{MinX: 1, MinY: 1, MaxX: 2, MaxY: 2}
+	for range 40 {
+		tr.Insert(r, 7)
+	}
+	tr.Insert(geom.EmptyRect(), 8)
+	if !tr.Delete(r, 7) || tr.Len() != 40 || len(search(&tr, r)) != 39 {
+		t.Errorf("Delete of a duplicate left %d items", tr.Len())
+	}
+	if tr.Delete(r, 8) || tr.Delete(geom.Rect{MinX: 1, MinY: 1, MaxX: 2, MaxY: 3}, 7) {
+		t.Error("Delete of an item with another rectangle = true")
+	}
+	if !tr.Delete(geom.EmptyRect(), 8) || tr.Len() != 39 {
+		t.Errorf("Delete of an item of an empty rectangle left %d items", tr.Len())
+	}
+	check(t, &tr)
+}
+
+func TestTraverse(t *testing.T) {
+	r := rand.New(rand.NewSource(95))
+	var tr Tree[int]
+	for i := range 500 {
+		tr.Insert(randomRect(r), i)
+	}
+	// The items within a distance of a point, which descends into fewer
+	// nodes than the whole tree.
+	p := geom.Point{X: 500, Y: 500}
+	within := func(s geom.Rect) bool { c := s.Clamp(p); return math.Hypot(c.X-p.X, c.Y-p.Y) <= 50 }
+	var nodes, items int
+	tr.Traverse(func(s geom.Rect) bool {
+		nodes++
+		return within(s)
+	}, func(s geom.Rect, item int) bool {
+		if within(s) {
+			items++
+		}
+		return true
+	})
+	total := 0
+	tr.Traverse(func(geom.Rect) bool { total++; return true }, func(geom.Rect, int) bool { return true })
+	var want int
+	for s := range tr.All() {
+		if within(s) {
+			want++
+		}
+	}
+	if items != want || nodes >= total {
+		t.Errorf("Traverse found %d items in %d of %d nodes, want %d items", items, nodes, total, want)
+	}
+	// Traverse stops when item returns false.
+	n := 0
+	tr.Traverse(func(geom.Rect) bool { return true }, func(geom.Rect, int) bool { n++; return n < 10 })
+	if n != 10 {
+		t.Errorf("Traverse called item %d times, want 10", n)
+	}
+	n = 0
+	for range tr.Search(tr.Bounds()) {
+		if n++; n == 5 {
+			break
+		}
+	}
+	if n != 5 {
+		t.Errorf("Search yielded %d items before the break, want 5", n)
+	}
+}
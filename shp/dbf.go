@@ -0,0 +1,165 @@
+package shp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Field is a field of the attribute table of a shapefile, as described
+// in the header of its .dbf file.
+type Field struct {
+	// Name is the name of the field, of at most 10 characters.
+	Name string
+
+	// Type is the dBASE type of the field: 'C' for characters, 'N' and
+	// 'F' for numbers, 'L' for logical values, 'D' for dates, and others
+	// which are read as strings.
+	Type byte
+
+	// Length and Decimals are the width of the field in bytes and, for
+	// numbers, the number of digits after the decimal point.
+	Length, Decimals int
+}
+
+// dbf reads the records of a .dbf file.
+type dbf struct {
+	r *bufio.Reader
+	// rs is the file read, if it can seek.
+	rs io.ReadSeeker
+	// fields are the fields of the records.
+	fields []Field
+	// n is the number of records.
+	n int
+	// headerLen and recordLen are the lengths of the header and each
+	// record in bytes.
+	headerLen, recordLen int
+	buf                  []byte
+}
+
+// newDBF reads the header of a .dbf file.
+func newDBF(r io.Reader) (*dbf, error) {
+	d := &dbf{r: bufio.NewReader(r)}
+	d.rs, _ = r.(io.ReadSeeker)
+	var h [32]byte
+	if _, err := io.ReadFull(d.r, h[:]); err != nil {
+		return nil, fmt.Errorf("%w: .dbf header: %w", ErrInvalid, err)
+	}
+	d.n = int(binary.LittleEndian.Uint32(h[4:]))
+	d.headerLen = int(binary.LittleEndian.Uint16(h[8:]))
+	d.recordLen = int(binary.LittleEndian.Uint16(h[10:]))
+	read, width := 32, 1 // The deletion flag
+	for {
+		c, err := d.r.Peek(1)
+		if err != nil {
+			return nil, fmt.Errorf("%w: .dbf field descriptors: %w", ErrInvalid, err)
+		}
+		if c[0] == 0x0d {
+			break
+		}
+		var f [32]byte
+		if _, err := io.ReadFull(d.r, f[:]); err != nil {
+			return nil, fmt.Errorf("%w: .dbf field descriptors: %w", ErrInvalid, err)
+		}
+		read += 32
+		name, _, _ := bytes.Cut(f[:11], []byte{0})
+		field := Field{Name: decodeText(name), Type: f[11], Length: int(f[16]), Decimals: int(f[17])}
+		d.fields = append(d.fields, field)
+		width += field.Length
+	}
+	if width > d.recordLen || read >= d.headerLen {
+		return nil, fmt.Errorf("%w: .dbf fields of %d bytes in records of %d bytes", ErrInvalid, width, d.recordLen)
+	}
+	if _, err := d.r.Discard(d.headerLen - read); err != nil {
+		return nil, fmt.Errorf("%w: .dbf header: %w", ErrInvalid, err)
+	}
+	d.buf = make([]byte, d.recordLen)
+	return d, nil
+}
+
+// seek positions the reader at a record.
+func (d *dbf) seek(i int) error {
+	if _, err := d.rs.Seek(int64(d.headerLen)+int64(i)*int64(d.recordLen), io.SeekStart); err != nil {
+		return err
+	}
+	d.r.Reset(d.rs)
+	return nil
+}
+
+// next reads the attributes of the next record, and whether it is
+// deleted.
+func (d *dbf) next() (map[string]any, bool, error) {
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = fmt.Errorf("%w: .dbf has fewer records than .shp", ErrInvalid)
+		}
+		return nil, false, err
+	}
+	if d.buf[0] == 0x1a {
+		return nil, false, fmt.Errorf("%w: .dbf has fewer records than .shp", ErrInvalid)
+	}
+	attrs := make(map[string]any, len(d.fields))
+	b := d.buf[1:]
+	for _, f := range d.fields {
+		attrs[f.Name] = value(f, b[:f.Length])
+		b = b[f.Length:]
+	}
+	return attrs, d.buf[0] == '*', nil
+}
+
+// value returns the value of a field, or nil if it is blank or
+// malformed: a string, an int64 for a number without decimals which
+// fits, a float64 for other numbers, a bool or a time.Time.
+func value(f Field, b []byte) any {
+	s := strings.TrimSpace(decodeText(bytes.TrimRight(b, "\x00")))
+	switch f.Type {
+	case 'C':
+		return s
+	case 'N', 'F':
+		if s == "" || strings.Trim(s, "*") == "" {
+			return nil // Blank, or overflowed
+		}
+		if f.Decimals == 0 {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+		}
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+		return nil
+	case 'L':
+		switch s {
+		case "T", "t", "Y", "y":
+			return true
+		case "F", "f", "N", "n":
+			return false
+		}
+		return nil
+	case 'D':
+		if t, err := time.Parse("20060102", s); err == nil {
+			return t
+		}
+		return nil
+	}
+	return s
+}
+
+// decodeText returns text which is UTF-8 as it is, and otherwise decodes
+// it as Latin-1, the most common of the code pages of .dbf files.
+func decodeText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	rs := make([]rune, len(b))
+	for i, c := range b {
+		rs[i] = rune(c)
+	}
+	return string(rs)
+}
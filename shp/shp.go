@@ -0,0 +1,568 @@
+// Package shp reads Esri shapefiles: the geometries of a .shp file,
+// the attributes of the matching .dbf file, the record offsets of the
+// .shx file, and the coordinate system of the .prj file.
+//
+// A Reader streams the records of a shapefile one at a time, so that
+// files of any size may be read in constant memory, and may also seek to
+// a record by its index if the .shx file is present. The format is
+// described in the "ESRI Shapefile Technical Description" of 1998.
+package shp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/geospat/geom"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, when a file is not a valid
+	// shapefile, or its files do not agree.
+	ErrInvalid = errors.New("shp: invalid shapefile")
+
+	// ErrUnsupported is returned, wrapped, for a MultiPatch record,
+	// which this package does not read, or a seek without a .shx file.
+	ErrUnsupported = errors.New("shp: unsupported shapefile")
+)
+
+// ShapeType is the type of the shapes of a shapefile.
+type ShapeType int
+
+// The shape types.
+const (
+	Null        ShapeType = 0
+	Point       ShapeType = 1
+	PolyLine    ShapeType = 3
+	Polygon     ShapeType = 5
+	MultiPoint  ShapeType = 8
+	PointZ      ShapeType = 11
+	PolyLineZ   ShapeType = 13
+	PolygonZ    ShapeType = 15
+	MultiPointZ ShapeType = 18
+	PointM      ShapeType = 21
+	PolyLineM   ShapeType = 23
+	PolygonM    ShapeType = 25
+	MultiPointM ShapeType = 28
+	MultiPatch  ShapeType = 31
+)
+
+// shapeTypeNames holds the names of the shape types, by type.
+var shapeTypeNames = map[ShapeType]string{
+	Null: "Null", Point: "Point", PolyLine: "PolyLine", Polygon: "Polygon",
+	MultiPoint: "MultiPoint", PointZ: "PointZ", PolyLineZ: "PolyLineZ",
+	PolygonZ: "PolygonZ", MultiPointZ: "MultiPointZ", PointM: "PointM",
+	PolyLineM: "PolyLineM", PolygonM: "PolygonM", MultiPointM: "MultiPointM",
+	MultiPatch: "MultiPatch",
+}
+
+// String returns the name of the shape type, such as "PolygonZ", or
+// "ShapeType(n)" for an invalid type.
+func (t ShapeType) String() string {
+	if s, ok := shapeTypeNames[t]; ok {
+		return s
+	}
+	return "ShapeType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Layout returns the layout of the geometries of the shape type: XYZM
+// for the Z types, which have optional measures, XYM for the M types,
+// and XY otherwise.
+func (t ShapeType) Layout() geom.Layout {
+	switch t {
+	case PointZ, PolyLineZ, PolygonZ, MultiPointZ, MultiPatch:
+		return geom.XYZM
+	case PointM, PolyLineM, PolygonM, MultiPointM:
+		return geom.XYM
+	}
+	return geom.XY
+}
+
+// Record is a record of a shapefile.
+type Record struct {
+	// Number is the number of the record, from 1.
+	Number int
+
+	// Geometry is the shape of the record, or nil for a Null shape. A
+	// Point or PolyLine of one part is a Point or LineString, and a
+	// MultiPoint is a MultiPoint. A PolyLine of several parts is a
+	// MultiLineString, and a Polygon a Polygon or, if it has several
+	// exterior rings, a MultiPolygon, each hole going to the exterior
+	// ring which contains it. The rings keep the windings of the file,
+	// exterior rings clockwise, and geom.Orient may be used to reverse
+	// them. Measures which are missing, or less than -10^38 as the
+	// specification has no data, are NaN.
+	Geometry geom.Geometry
+
+	// Attributes are the values of the fields of the record in the .dbf
+	// file, by name, or nil if there is no .dbf file: a string, an int64
+	// or a float64 for a number, a bool, a time.Time for a date, or nil
+	// for a value which is blank.
+	Attributes map[string]any
+}
+
+// Reader reads the records of a shapefile.
+type Reader struct {
+	shp *bufio.Reader
+	// shpSeeker is the .shp file, if the reader may seek.
+	shpSeeker io.ReadSeeker
+	dbf       *dbf
+	// offsets are the offsets in bytes of the records in the .shp file,
+	// from its .shx file, or nil.
+	offsets    []int64
+	typ        ShapeType
+	bounds     geom.Rect
+	projection string
+	closers    []io.Closer
+	// pos and end are the offsets in bytes in the .shp file of the next
+	// record and of the end of the file.
+	pos, end int64
+	buf      []byte
+	err      error
+}
+
+// NewReader returns a reader of the records of a .shp file and the
+// attributes of its .dbf file, which may be nil to read the geometries
+// alone. The reader buffers its reads from the files. It returns an
+// error wrapping ErrInvalid if the header of either file is invalid.
+func NewReader(shp, dbf io.Reader) (*Reader, error) {
+	r := &Reader{shp: bufio.NewReader(shp), pos: 100}
+	var h [100]byte
+	if _, err := io.ReadFull(r.shp, h[:]); err != nil {
+		return nil, fmt.Errorf("%w: .shp header: %w", ErrInvalid, err)
+	}
+	if code := binary.BigEndian.Uint32(h[0:]); code != 9994 {
+		return nil, fmt.Errorf("%w: .shp file code %d is not 9994", ErrInvalid, code)
+	}
+	r.end = 2 * int64(binary.BigEndian.Uint32(h[24:]))
+	r.typ = ShapeType(binary.LittleEndian.Uint32(h[32:]))
+	if _, ok := shapeTypeNames[r.typ]; !ok {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, r.typ)
+	}
+	le := func(i int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(h[i:])) }
+	r.bounds = geom.Rect{MinX: le(36), MinY: le(44), MaxX: le(52), MaxY: le(60)}
+	if dbf != nil {
+		var err error
+		if r.dbf, err = newDBF(dbf); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Open opens the shapefile whose .shp file has the given name, with or
+// without its extension, together with the .dbf, .shx and .prj files of
+// the same name in the same directory, those which are present, with
+// extensions in lower or upper case. The reader may seek if the .shx
+// file is present, and must be closed.
+func Open(name string) (*Reader, error) {
+	base := name
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".shp") {
+		base = name[:len(name)-len(ext)]
+	}
+	open := func(ext string) (*os.File, error) {
+		f, err := os.Open(base + "." + ext)
+		if errors.Is(err, os.ErrNotExist) {
+			f, err = os.Open(base + "." + strings.ToUpper(ext))
+		}
+		return f, err
+	}
+	read := func(ext string) ([]byte, error) {
+		f, err := open(ext)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+	var closers []io.Closer
+	fail := func(err error) (*Reader, error) {
+		for _, c := range closers {
+			c.Close()
+		}
+		return nil, err
+	}
+	shpFile, err := open("shp")
+	if err != nil {
+		return nil, err
+	}
+	closers = append(closers, shpFile)
+	var dbf io.Reader
+	switch f, err := open("dbf"); {
+	case err == nil:
+		closers = append(closers, f)
+		dbf = f
+	case !errors.Is(err, os.ErrNotExist):
+		return fail(err)
+	}
+	r, err := NewReader(shpFile, dbf)
+	if err != nil {
+		return fail(err)
+	}
+	r.shpSeeker, r.closers = shpFile, closers
+	switch prj, err := read("prj"); {
+	case err == nil:
+		r.projection = strings.TrimSpace(decodeText(prj))
+	case !errors.Is(err, os.ErrNotExist):
+		return fail(err)
+	}
+	switch shx, err := read("shx"); {
+	case err == nil:
+		if r.offsets, err = readSHX(shx); err != nil {
+			return fail(err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fail(err)
+	}
+	return r, nil
+}
+
+// readSHX returns the offsets of the records of a .shx file.
+func readSHX(b []byte) ([]int64, error) {
+	if len(b) < 100 || binary.BigEndian.Uint32(b) != 9994 || (len(b)-100)%8 != 0 {
+		return nil, fmt.Errorf("%w: invalid .shx file", ErrInvalid)
+	}
+	offsets := make([]int64, (len(b)-100)/8)
+	for i := range offsets {
+		offsets[i] = 2 * int64(binary.BigEndian.Uint32(b[100+8*i:]))
+	}
+	return offsets, nil
+}
+
+// Close closes the files opened by Open.
+func (r *Reader) Close() error {
+	var errs []error
+	for _, c := range r.closers {
+		errs = append(errs, c.Close())
+	}
+	r.closers = nil
+	return errors.Join(errs...)
+}
+
+// Type returns the shape type of the shapefile, from its header. Every
+// record has this type or Null.
+func (r *Reader) Type() ShapeType {
+	return r.typ
+}
+
+// Bounds returns the bounds of the shapes of the shapefile, from its
+// header.
+func (r *Reader) Bounds() geom.Rect {
+	return r.bounds
+}
+
+// Fields returns the fields of the attributes of the records, or nil if
+// there is no .dbf file.
+func (r *Reader) Fields() []Field {
+	if r.dbf == nil {
+		return nil
+	}
+	return r.dbf.fields
+}
+
+// Projection returns the text of the .prj file, the WKT of the
+// coordinate system of the shapefile in the dialect of Esri, or "" if
+// there is none or the reader was not made by Open.
+func (r *Reader) Projection() string {
+	return r.projection
+}
+
+// Len returns the number of records of the shapefile, from its .shx or
+// .dbf file, or -1 if it has neither.
+func (r *Reader) Len() int {
+	switch {
+	case r.offsets != nil:
+		return len(r.offsets)
+	case r.dbf != nil:
+		return r.dbf.n
+	}
+	return -1
+}
+
+// Seek positions the reader so that Next reads the record of index i,
+// from 0. It returns an error wrapping ErrUnsupported if the reader was
+// not made by Open, or the shapefile has no .shx file.
+func (r *Reader) Seek(i int) error {
+	if r.offsets == nil || r.shpSeeker == nil || (r.dbf != nil && r.dbf.rs == nil) {
+		return fmt.Errorf("%w: seek without a .shx file", ErrUnsupported)
+	}
+	if i < 0 || i > len(r.offsets) {
+		return fmt.Errorf("shp: seek to record %d of %d", i, len(r.offsets))
+	}
+	pos := r.end
+	if i < len(r.offsets) {
+		pos = r.offsets[i]
+	}
+	if _, err := r.shpSeeker.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	r.shp.Reset(r.shpSeeker)
+	if r.dbf != nil {
+		if err := r.dbf.seek(i); err != nil {
+			return err
+		}
+	}
+	r.pos, r.err = pos, nil
+	return nil
+}
+
+// Next returns the next record, or io.EOF after the last. Records
+// deleted from the .dbf file are skipped. It returns an error wrapping
+// ErrInvalid if a record is malformed, and returns the same error on
+// every call after an error, until a Seek.
+func (r *Reader) Next() (*Record, error) {
+	for r.err == nil {
+		rec, deleted, err := r.next()
+		if err != nil {
+			r.err = err
+			break
+		}
+		if !deleted {
+			return rec, nil
+		}
+	}
+	return nil, r.err
+}
+
+// next reads the next record, and whether it was deleted.
+func (r *Reader) next() (*Record, bool, error) {
+	if r.pos >= r.end {
+		return nil, false, io.EOF
+	}
+	var h [8]byte
+	if _, err := io.ReadFull(r.shp, h[:]); err != nil {
+		return nil, false, fmt.Errorf("%w: record header at offset %d: %w", ErrInvalid, r.pos, err)
+	}
+	number := int(binary.BigEndian.Uint32(h[0:]))
+	n := 2 * int64(binary.BigEndian.Uint32(h[4:]))
+	if n < 4 || r.pos+8+n > r.end {
+		return nil, false, fmt.Errorf("%w: record %d of %d bytes at offset %d exceeds the file", ErrInvalid, number, n, r.pos)
+	}
+	if int64(cap(r.buf)) < n {
+		r.buf = make([]byte, n)
+	}
+	b := r.buf[:n]
+	if _, err := io.ReadFull(r.shp, b); err != nil {
+		return nil, false, fmt.Errorf("%w: record %d: %w", ErrInvalid, number, err)
+	}
+	r.pos += 8 + n
+	g, err := shape(b)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: record %d", err, number)
+	}
+	rec := &Record{Number: number, Geometry: g}
+	deleted := false
+	if r.dbf != nil {
+		if rec.Attributes, deleted, err = r.dbf.next(); err != nil {
+			return nil, false, err
+		}
+	}
+	return rec, deleted, nil
+}
+
+// content reads the little endian content of a record, noting whether
+// it ends too soon.
+type content struct {
+	b     []byte
+	short bool
+}
+
+// int reads a 32-bit integer.
+func (c *content) int() int {
+	if len(c.b) < 4 {
+		c.short, c.b = true, nil
+		return 0
+	}
+	v := int32(binary.LittleEndian.Uint32(c.b))
+	c.b = c.b[4:]
+	return int(v)
+}
+
+// float reads a 64-bit float.
+func (c *content) float() float64 {
+	if len(c.b) < 8 {
+		c.short, c.b = true, nil
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(c.b))
+	c.b = c.b[8:]
+	return v
+}
+
+// count reads a count of elements of size bytes, which must fit in the
+// rest of the content.
+func (c *content) count(size int) int {
+	n := c.int()
+	if n < 0 || n > len(c.b)/size {
+		c.short, c.b = true, nil
+		return 0
+	}
+	return n
+}
+
+// measure returns a measure, NaN if it is no data.
+func measure(m float64) float64 {
+	if m < -1e38 {
+		return math.NaN()
+	}
+	return m
+}
+
+// shape returns the geometry of the content of a record.
+func shape(b []byte) (geom.Geometry, error) {
+	c := &content{b: b}
+	t := ShapeType(c.int())
+	layout := t.Layout()
+	var ps []geom.Point
+	var parts []int
+	switch t {
+	case Null:
+		return nil, nil
+	case Point, PointZ, PointM:
+		p := geom.Point{X: c.float(), Y: c.float(), M: math.NaN()}
+		if t == PointZ {
+			p.Z = c.float()
+		}
+		if t != Point && len(c.b) >= 8 {
+			p.M = measure(c.float())
+		}
+		if c.short {
+			break
+		}
+		if t == Point {
+			p.M = 0
+		}
+		return p, nil
+	case MultiPoint, MultiPointZ, MultiPointM, PolyLine, PolyLineZ, PolyLineM, Polygon, PolygonZ, PolygonM:
+		for range 4 {
+			c.float() // The bounding box
+		}
+		nParts := 1
+		multi := t == MultiPoint || t == MultiPointZ || t == MultiPointM
+		if !multi {
+			nParts = c.count(4)
+		}
+		nPoints := c.count(16)
+		parts = make([]int, nParts)
+		if !multi {
+			for i := range parts {
+				if parts[i] = c.int(); parts[i] < 0 || parts[i] > nPoints || (i > 0 && parts[i] < parts[i-1]) {
+					return nil, fmt.Errorf("%w: part %d starts at invalid point %d", ErrInvalid, i, parts[i])
+				}
+			}
+		}
+		ps = make([]geom.Point, nPoints)
+		for i := range ps {
+			ps[i] = geom.Point{X: c.float(), Y: c.float()}
+		}
+		if layout.HasZ() {
+			c.float()
+			c.float() // The range of Z
+			for i := range ps {
+				ps[i].Z = c.float()
+			}
+		}
+		if layout.HasM() {
+			hasM := len(c.b) >= 16+8*len(ps)
+			if hasM {
+				c.float()
+				c.float() // The range of M
+			}
+			for i := range ps {
+				ps[i].M = math.NaN()
+				if hasM {
+					ps[i].M = measure(c.float())
+				}
+			}
+		}
+		if c.short {
+			break
+		}
+		switch t {
+		case MultiPoint, MultiPointZ, MultiPointM:
+			return geom.MultiPoint(ps), nil
+		case PolyLine, PolyLineZ, PolyLineM:
+			m := make(geom.MultiLineString, len(parts))
+			for i := range parts {
+				m[i] = geom.LineString(part(ps, parts, i))
+			}
+			if len(m) == 1 {
+				return m[0], nil
+			}
+			return m, nil
+		}
+		rings := make([]geom.Ring, len(parts))
+		for i := range parts {
+			rings[i] = geom.Ring(part(ps, parts, i))
+		}
+		return polygons(rings), nil
+	case MultiPatch:
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, t)
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, t)
+	}
+	return nil, fmt.Errorf("%w: %v of %d bytes is too short", ErrInvalid, t, len(b))
+}
+
+// part returns the points of a part.
+func part(ps []geom.Point, parts []int, i int) []geom.Point {
+	if i+1 < len(parts) {
+		return ps[parts[i]:parts[i+1]]
+	}
+	return ps[parts[i]:]
+}
+
+// polygons returns the polygons of rings, each clockwise ring starting a
+// polygon and each counterclockwise ring being a hole of the smallest
+// polygon that contains its points, or a polygon of its own if none
+// does, as when the rings are wound the wrong way.
+func polygons(rings []geom.Ring) geom.Geometry {
+	var m geom.MultiPolygon
+	var holes []geom.Ring
+	for _, r := range rings {
+		if r.Winding() == geom.Counterclockwise {
+			holes = append(holes, r)
+		} else {
+			m = append(m, geom.Polygon{r})
+		}
+	}
+	for _, h := range holes {
+		best, area := -1, math.Inf(1)
+		for i, p := range m {
+			if a := math.Abs(p[0].SignedArea()); a < area && contains(p[0], h) {
+				best, area = i, a
+			}
+		}
+		if best < 0 {
+			m = append(m, geom.Polygon{h})
+		} else {
+			m[best] = append(m[best], h)
+		}
+	}
+	if len(m) == 1 {
+		return m[0]
+	}
+	return m
+}
+
+// contains reports whether a ring contains a hole, testing its first
+// point which is not on the boundary of the ring.
+func contains(shell, hole geom.Ring) bool {
+	s := geom.Polygon{shell}
+	for _, p := range hole {
+		switch geom.Locate(s, p) {
+		case geom.Interior:
+			return true
+		case geom.Exterior:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,646 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// le returns the little endian encoding of values.
+func le(vs ...any) []byte {
+	var b []byte
+	for _, v := range vs {
+		b, _ = binary.Append(b, binary.LittleEndian, v)
+	}
+	return b
+}
+
+// shapefile returns a .shp file of a shape type holding records of
+// contents, and its .shx file.
+func shapefile(typ ShapeType, contents ...[]byte) (shp, shx []byte) {
+	header := func(n int) []byte {
+		h := make([]byte, 100)
+		binary.BigEndian.PutUint32(h, 9994)
+		binary.BigEndian.PutUint32(h[24:], uint32(n/2))
+		binary.LittleEndian.PutUint32(h[28:], 1000)
+		binary.LittleEndian.PutUint32(h[32:], uint32(typ))
+		return h
+	}
+	n := 100
+	for _, c := range contents {
+		n += 8 + len(c)
+	}
+	shp = header(n)
+	shx = header(100 + 8*len(contents))
+	for i, c := range contents {
+		shx = binary.BigEndian.AppendUint32(shx, uint32(len(shp)/2))
+		shx = binary.BigEndian.AppendUint32(shx, uint32(len(c)/2))
+		shp = binary.BigEndian.AppendUint32(shp, uint32(i+1))
+		shp = binary.BigEndian.AppendUint32(shp, uint32(len(c)/2))
+		shp = append(shp, c...)
+	}
+	return shp, shx
+}
+
+// poly returns the content of a PolyLine or Polygon shape of a type, of
+// parts of points with the Z and M of the type.
+func poly(typ ShapeType, parts ...[]geom.Point) []byte {
+	var ps []geom.Point
+	b := le(int32(typ), 0.0, 0.0, 0.0, 0.0, int32(len(parts)))
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+		ps = append(ps, p...)
+	}
+	b = append(b, le(int32(n))...)
+	n = 0
+	for _, p := range parts {
+		b = append(b, le(int32(n))...)
+		n += len(p)
+	}
+	return append(b, coords(typ.Layout(), ps)...)
+}
+
+// multiPoint returns the content of a MultiPoint shape of a type.
+func multiPoint(typ ShapeType, ps ...geom.Point) []byte {
+	b := le(int32(typ), 0.0, 0.0, 0.0, 0.0, int32(len(ps)))
+	return append(b, coords(typ.Layout(), ps)...)
+}
+
+// coords returns the XY, Z and M arrays of points.
+func coords(layout geom.Layout, ps []geom.Point) []byte {
+	var b []byte
+	for _, p := range ps {
+		b = append(b, le(p.X, p.Y)...)
+	}
+	if layout.HasZ() {
+		b = append(b, le(0.0, 0.0)...)
+		for _, p := range ps {
+			b = append(b, le(p.Z)...)
+		}
+	}
+	if layout.HasM() {
+		b = append(b, le(0.0, 0.0)...)
+		for _, p := range ps {
+			b = append(b, le(p.M)...)
+		}
+	}
+	return b
+}
+
+// dbfFile returns a .dbf file of fields and records, each of a deletion
+// flag followed by the values of the fields.
+func dbfFile(fields []Field, records ...string) []byte {
+	length := 1
+	for _, f := range fields {
+		length += f.Length
+	}
+	h := make([]byte, 32)
+	h[0], h[1], h[2], h[3] = 3, 124, 5, 1
+	binary.LittleEndian.PutUint32(h[4:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(h[8:], uint16(32+32*len(fields)+1))
+	binary.LittleEndian.PutUint16(h[10:], uint16(length))
+	for _, f := range fields {
+		d := make([]byte, 32)
+		copy(d, f.Name)
+		d[11], d[16], d[17] = f.Type, byte(f.Length), byte(f.Decimals)
+		h = append(h, d...)
+	}
+	h = append(h, 0x0d)
+	for _, r := range records {
+		h = append(h, r...)
+	}
+	return append(h, 0x1a)
+}
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// readAll returns the records of a reader.
+func readAll(r *Reader) ([]*Record, error) {
+	var recs []*Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs, nil
+		}
+		if err != nil {
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func TestShapeType(t *testing.T) {
+	tests := []struct {
+		t      ShapeType
+		s      string
+		layout geom.Layout
+	}{
+		{Null, "Null", geom.XY},
+		{Point, "Point", geom.XY},
+		{PolygonZ, "PolygonZ", geom.XYZM},
+		{MultiPointM, "MultiPointM", geom.XYM},
+		{MultiPatch, "MultiPatch", geom.XYZM},
+		{ShapeType(7), "ShapeType(7)", geom.XY},
+	}
+	for _, tt := range tests {
+		if s := tt.t.String(); s != tt.s {
+			t.Errorf("ShapeType(%d).String() = %q, want %q", int(tt.t), s, tt.s)
+		}
+		if l := tt.t.Layout(); l != tt.layout {
+			t.Errorf("%v.Layout() = %v, want %v", tt.t, l, tt.layout)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	// A .shp file of the point (1, 2).
+	shp := unhex(`
+		0000270a 00000000 00000000 00000000 00000000 00000000 00000040 e8030000
+		01000000 000000000000f03f 0000000000000040 000000000000f03f 0000000000000040
+		0000000000000000 0000000000000000 0000000000000000 0000000000000000
+		00000001 0000000a 01000000 000000000000f03f 0000000000000040`)
+	r, err := NewReader(bytes.NewReader(shp), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Type() != Point || r.Bounds() != (geom.Rect{MinX: 1, MinY: 2, MaxX: 1, MaxY: 2}) {
+		t.Errorf("NewReader = type %v, bounds %v, want Point, (1 2, 1 2)", r.Type(), r.Bounds())
+	}
+	if r.Len() != -1 || r.Fields() != nil || r.Projection() != "" {
+		t.Errorf("NewReader = Len %d, Fields %v, Projection %q, want -1, nil, \"\"", r.Len(), r.Fields(), r.Projection())
+	}
+	recs, err := readAll(r)
+	want := []*Record{{Number: 1, Geometry: geom.Point{X: 1, Y: 2}}}
+	if err != nil || !reflect.DeepEqual(recs, want) {
+		t.Errorf("records = %v, %v, want %v", recs, err, want)
+	}
+	if err := r.Seek(0); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Seek of a reader of NewReader = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestNextShapes(t *testing.T) {
+	sq := func(x, y, s float64) []geom.Point { // Clockwise
+		return []geom.Point{{X: x, Y: y}, {X: x, Y: y + s}, {X: x + s, Y: y + s}, {X: x + s, Y: y}, {X: x, Y: y}}
+	}
+	hole := func(x, y, s float64) []geom.Point { // Counterclockwise
+		ps := sq(x, y, s)
+		slices.Reverse(ps)
+		return ps
+	}
+	tests := []struct {
+		name    string
+		typ     ShapeType
+		content []byte
+		want    geom.Geometry
+	}{
+		{"Null", Polygon, le(int32(Null)), nil},
+		{"Point", Point, le(int32(Point), 1.5, -2.0), geom.Point{X: 1.5, Y: -2}},
+		{"PointZ", PointZ, le(int32(PointZ), 1.0, 2.0, 3.0, 4.0), geom.Point{X: 1, Y: 2, Z: 3, M: 4}},
+		{"PointM", PointM, le(int32(PointM), 1.0, 2.0, 4.0), geom.Point{X: 1, Y: 2, M: 4}},
+		{"MultiPoint", MultiPoint, multiPoint(MultiPoint, geom.Point{X: 1, Y: 2}, geom.Point{X: 3, Y: 4}),
+			geom.MultiPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		{"MultiPointZ", MultiPointZ, multiPoint(MultiPointZ, geom.Point{X: 1, Y: 2, Z: 3, M: 4}),
+			geom.MultiPoint{{X: 1, Y: 2, Z: 3, M: 4}}},
+		{"PolyLine", PolyLine, poly(PolyLine, []geom.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}),
+			geom.LineString{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+		{"PolyLine of parts", PolyLine, poly(PolyLine, []geom.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, []geom.Point{{X: 2, Y: 2}, {X: 3, Y: 3}, {X: 4, Y: 2}}),
+			geom.MultiLineString{{{X: 0, Y: 0}, {X: 1, Y: 1}}, {{X: 2, Y: 2}, {X: 3, Y: 3}, {X: 4, Y: 2}}}},
+		{"PolyLineM", PolyLineM, poly(PolyLineM, []geom.Point{{X: 0, Y: 0, M: 10}, {X: 1, Y: 1, M: 20}}),
+			geom.LineString{{X: 0, Y: 0, M: 10}, {X: 1, Y: 1, M: 20}}},
+		{"PolyLineZ", PolyLineZ, poly(PolyLineZ, []geom.Point{{X: 0, Y: 0, Z: 5, M: 1}, {X: 1, Y: 1, Z: 6, M: 2}}),
+			geom.LineString{{X: 0, Y: 0, Z: 5, M: 1}, {X: 1, Y: 1, Z: 6, M: 2}}},
+		{"Polygon", Polygon, poly(Polygon, sq(0, 0, 10)), geom.Polygon{sq(0, 0, 10)}},
+		{"Polygon with hole", Polygon, poly(Polygon, sq(0, 0, 10), hole(2, 2, 2)), geom.Polygon{sq(0, 0, 10), hole(2, 2, 2)}},
+		{"Polygon with hole first", Polygon, poly(Polygon, hole(2, 2, 2), sq(0, 0, 10)), geom.Polygon{sq(0, 0, 10), hole(2, 2, 2)}},
+		{"Polygons", Polygon, poly(Polygon, sq(0, 0, 10), sq(20, 0, 10), hole(22, 2, 2), hole(2, 2, 2)),
+			geom.MultiPolygon{{sq(0, 0, 10), hole(2, 2, 2)}, {sq(20, 0, 10), hole(22, 2, 2)}}},
+		{"island in a hole", Polygon, poly(Polygon, sq(0, 0, 10), hole(1, 1, 8), sq(3, 3, 4), hole(4, 4, 2)),
+			geom.MultiPolygon{{sq(0, 0, 10), hole(1, 1, 8)}, {sq(3, 3, 4), hole(4, 4, 2)}}},
+		{"hole touching its shell", Polygon, poly(Polygon, sq(0, 0, 10), hole(0, 0, 5)), geom.Polygon{sq(0, 0, 10), hole(0, 0, 5)}},
+		{"counterclockwise", Polygon, poly(Polygon, hole(0, 0, 1)), geom.Polygon{hole(0, 0, 1)}},
+		{"PolygonZ", PolygonZ, poly(PolygonZ, []geom.Point{{Z: 1, M: 2}, {Y: 1, Z: 1, M: 2}, {X: 1, Z: 1, M: 2}, {Z: 1, M: 2}}),
+			geom.Polygon{{{Z: 1, M: 2}, {Y: 1, Z: 1, M: 2}, {X: 1, Z: 1, M: 2}, {Z: 1, M: 2}}}},
+		{"PolyLine of no parts", PolyLine, poly(PolyLine), geom.MultiLineString{}},
+		{"Polygon of no parts", Polygon, poly(Polygon), geom.MultiPolygon(nil)},
+	}
+	for _, tt := range tests {
+		shp, _ := shapefile(tt.typ, tt.content)
+		r, err := NewReader(bytes.NewReader(shp), nil)
+		if err != nil {
+			t.Fatalf("%s: NewReader: %v", tt.name, err)
+		}
+		rec, err := r.Next()
+		if err != nil {
+			t.Errorf("%s: Next: %v", tt.name, err)
+			continue
+		}
+		if rec.Number != 1 || !reflect.DeepEqual(rec.Geometry, tt.want) {
+			t.Errorf("%s: Next = %d, %v, want 1, %v", tt.name, rec.Number, rec.Geometry, tt.want)
+		}
+		if _, err := r.Next(); err != io.EOF {
+			t.Errorf("%s: Next after the last = %v, want EOF", tt.name, err)
+		}
+	}
+}
+
+func TestNextMeasures(t *testing.T) {
+	// Measures which are missing, or no data, are NaN.
+	tests := []struct {
+		name    string
+		typ     ShapeType
+		content []byte
+		m       []float64
+	}{
+		{"PointZ without M", PointZ, le(int32(PointZ), 1.0, 2.0, 3.0), []float64{math.NaN()}},
+		{"PointM of no data", PointM, le(int32(PointM), 1.0, 2.0, -1e39), []float64{math.NaN()}},
+		{"PolyLineZ without M", PolyLineZ, poly(PolyLineZ, []geom.Point{{}, {X: 1}})[:4+32+4+4+4+32+16+16], []float64{math.NaN(), math.NaN()}},
+		{"PolyLineM of no data", PolyLineM, poly(PolyLineM, []geom.Point{{M: -1.5e38}, {X: 1, M: 7}}), []float64{math.NaN(), 7}},
+		{"MultiPointM without M", MultiPointM, multiPoint(MultiPointM, geom.Point{X: 1})[:4+32+4+16], []float64{math.NaN()}},
+	}
+	for _, tt := range tests {
+		shp, _ := shapefile(tt.typ, tt.content)
+		r, _ := NewReader(bytes.NewReader(shp), nil)
+		rec, err := r.Next()
+		if err != nil {
+			t.Errorf("%s: Next: %v", tt.name, err)
+			continue
+		}
+		var ps []geom.Point
+		switch g := rec.Geometry.(type) {
+		case geom.Point:
+			ps = []geom.Point{g}
+		case geom.LineString:
+			ps = g
+		case geom.MultiPoint:
+			ps = g
+		}
+		var m []float64
+		for _, p := range ps {
+			m = append(m, p.M)
+		}
+		if len(m) != len(tt.m) {
+			t.Errorf("%s: measures = %v, want %v", tt.name, m, tt.m)
+			continue
+		}
+		for i := range m {
+			if m[i] != tt.m[i] && !(math.IsNaN(m[i]) && math.IsNaN(tt.m[i])) {
+				t.Errorf("%s: measures = %v, want %v", tt.name, m, tt.m)
+				break
+			}
+		}
+	}
+}
+
+func TestNewReaderInvalid(t *testing.T) {
+	good, _ := shapefile(Point)
+	code := bytes.Clone(good)
+	code[3] = 0
+	typ := bytes.Clone(good)
+	typ[32] = 2
+	tests := []struct {
+		name     string
+		shp, dbf []byte
+		err      string
+	}{
+		{"empty", nil, nil, "shp: invalid shapefile: .shp header: EOF"},
+		{"short", good[:50], nil, "shp: invalid shapefile: .shp header: unexpected EOF"},
+		{"code", code, nil, "shp: invalid shapefile: .shp file code 9984 is not 9994"},
+		{"type", typ, nil, "shp: invalid shapefile: ShapeType(2)"},
+		{"short .dbf", good, []byte{3, 0, 0}, "shp: invalid shapefile: .dbf header: unexpected EOF"},
+		{"unterminated .dbf", good, dbfFile([]Field{{Name: "A", Type: 'C', Length: 4}})[:64], "shp: invalid shapefile: .dbf field descriptors: EOF"},
+		{"wide .dbf", good, func() []byte {
+			b := dbfFile([]Field{{Name: "A", Type: 'C', Length: 4}})
+			b[10] = 2
+			return b
+		}(), "shp: invalid shapefile: .dbf fields of 5 bytes in records of 2 bytes"},
+	}
+	for _, tt := range tests {
+		var dbf io.Reader
+		if tt.dbf != nil {
+			dbf = bytes.NewReader(tt.dbf)
+		}
+		r, err := NewReader(bytes.NewReader(tt.shp), dbf)
+		if err == nil || err.Error() != tt.err || !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: NewReader = %v, %v, want error %q", tt.name, r, err, tt.err)
+		}
+	}
+}
+
+func TestNextInvalid(t *testing.T) {
+	point := le(int32(Point), 1.0, 2.0)
+	tests := []struct {
+		name string
+		shp  []byte
+		err  string
+	}{
+		{"truncated header", func() []byte {
+			b, _ := shapefile(Point, point)
+			return b[:104]
+		}(), "shp: invalid shapefile: record header at offset 100: unexpected EOF"},
+		{"truncated content", func() []byte {
+			b, _ := shapefile(Point, point)
+			return b[:120]
+		}(), "shp: invalid shapefile: record 1: unexpected EOF"},
+		{"exceeds the file", func() []byte {
+			b, _ := shapefile(Point, point)
+			b[24+3] -= 2
+			return b
+		}(), "shp: invalid shapefile: record 1 of 20 bytes at offset 100 exceeds the file"},
+		{"too short", func() []byte {
+			b, _ := shapefile(Point, point[:12])
+			return b
+		}(), "shp: invalid shapefile: Point of 12 bytes is too short: record 1"},
+		{"too many points", func() []byte {
+			c := poly(PolyLine, []geom.Point{{}, {X: 1}})
+			c[4+32+4] = 9
+			b, _ := shapefile(PolyLine, c)
+			return b
+		}(), "shp: invalid shapefile: PolyLine of 80 bytes is too short: record 1"},
+		{"bad part", func() []byte {
+			c := poly(PolyLine, []geom.Point{{}, {X: 1}}, []geom.Point{{}, {X: 1}})
+			c[4+32+8+4] = 5
+			b, _ := shapefile(PolyLine, c)
+			return b
+		}(), "shp: invalid shapefile: part 1 starts at invalid point 5: record 1"},
+		{"decreasing parts", func() []byte {
+			c := poly(PolyLine, []geom.Point{{}, {X: 1}}, []geom.Point{{}, {X: 1}})
+			c[4+32+8] = 3
+			b, _ := shapefile(PolyLine, c)
+			return b
+		}(), "shp: invalid shapefile: part 1 starts at invalid point 2: record 1"},
+		{"bad type", func() []byte {
+			b, _ := shapefile(Point, le(int32(4), 1.0, 2.0))
+			return b
+		}(), "shp: invalid shapefile: ShapeType(4): record 1"},
+	}
+	for _, tt := range tests {
+		r, err := NewReader(bytes.NewReader(tt.shp), nil)
+		if err != nil {
+			t.Fatalf("%s: NewReader: %v", tt.name, err)
+		}
+		for range 2 { // The error is sticky
+			rec, err := r.Next()
+			if err == nil || err.Error() != tt.err || !errors.Is(err, ErrInvalid) {
+				t.Errorf("%s: Next = %v, %v, want error %q", tt.name, rec, err, tt.err)
+			}
+		}
+	}
+}
+
+func TestNextMultiPatch(t *testing.T) {
+	shp, _ := shapefile(MultiPatch, le(int32(MultiPatch), 0.0, 0.0, 0.0, 0.0, int32(0), int32(0)))
+	r, err := NewReader(bytes.NewReader(shp), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := r.Next()
+	const want = "shp: unsupported shapefile: MultiPatch: record 1"
+	if err == nil || err.Error() != want || !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Next of a MultiPatch = %v, %v, want error %q", rec, err, want)
+	}
+}
+
+// attributes are the fields and records of a .dbf file of three
+// records, the second deleted.
+var (
+	attrFields = []Field{
+		{Name: "NAME", Type: 'C', Length: 8},
+		{Name: "POP", Type: 'N', Length: 6},
+		{Name: "AREA", Type: 'N', Length: 8, Decimals: 2},
+		{Name: "RATIO", Type: 'F', Length: 6, Decimals: 3},
+		{Name: "CAPITAL", Type: 'L', Length: 1},
+		{Name: "FOUNDED", Type: 'D', Length: 8},
+		{Name: "NOTE", Type: 'M', Length: 3},
+	}
+	attrRecords = []string{
+		" Paris     2161  105.40 0.125T17890714abc",
+		"*Gone    000001    1.00 0.000F20000101   ",
+		" Z\xfcrich  ******        ******?00000000\x00\x00\x00",
+	}
+)
+
+func TestNextAttributes(t *testing.T) {
+	var contents [][]byte
+	for i := range attrRecords {
+		contents = append(contents, le(int32(Point), float64(i), 0.0))
+	}
+	shp, _ := shapefile(Point, contents...)
+	r, err := NewReader(bytes.NewReader(shp), bytes.NewReader(dbfFile(attrFields, attrRecords...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Fields(), attrFields) || r.Len() != 3 {
+		t.Errorf("Fields, Len = %v, %d, want %v, 3", r.Fields(), r.Len(), attrFields)
+	}
+	recs, err := readAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Record{
+		{Number: 1, Geometry: geom.Point{}, Attributes: map[string]any{
+			"NAME": "Paris", "POP": int64(2161), "AREA": 105.4, "RATIO": 0.125, "CAPITAL": true,
+			"FOUNDED": time.Date(1789, 7, 14, 0, 0, 0, 0, time.UTC), "NOTE": "abc",
+		}},
+		{Number: 3, Geometry: geom.Point{X: 2}, Attributes: map[string]any{
+			"NAME": "Zürich", "POP": nil, "AREA": nil, "RATIO": nil, "CAPITAL": nil, "FOUNDED": nil, "NOTE": "",
+		}},
+	}
+	if !reflect.DeepEqual(recs, want) {
+		t.Errorf("records = %v, want %v", recs, want)
+	}
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		f    Field
+		s    string
+		want any
+	}{
+		{Field{Type: 'N', Length: 20}, "99999999999999999999", 1e20},
+		{Field{Type: 'N', Length: 6}, "  -42 ", int64(-42)},
+		{Field{Type: 'N', Length: 6}, "1e3", 1000.0},
+		{Field{Type: 'N', Length: 6}, "abc", nil},
+		{Field{Type: 'F', Length: 6}, "  7", int64(7)},
+		{Field{Type: 'L', Length: 1}, "y", true},
+		{Field{Type: 'L', Length: 1}, "n", false},
+		{Field{Type: 'L', Length: 1}, " ", nil},
+		{Field{Type: 'D', Length: 8}, "20240229", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{Field{Type: 'D', Length: 8}, "20230229", nil},
+		{Field{Type: 'C', Length: 4}, "ab\x00\x00", "ab"},
+		{Field{Type: 'C', Length: 4}, "\xe9t\xe9 ", "été"},
+	}
+	for _, tt := range tests {
+		if got := value(tt.f, []byte(tt.s)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("value(%c, %q) = %#v, want %#v", tt.f.Type, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestNextFewerAttributes(t *testing.T) {
+	shp, _ := shapefile(Point, le(int32(Point), 0.0, 0.0), le(int32(Point), 1.0, 0.0))
+	for _, dbf := range [][]byte{
+		dbfFile(attrFields, attrRecords[0]),
+		dbfFile(attrFields, attrRecords[0])[:len(dbfFile(attrFields, attrRecords[0]))-1],
+	} {
+		r, err := NewReader(bytes.NewReader(shp), bytes.NewReader(dbf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs, err := readAll(r)
+		const want = "shp: invalid shapefile: .dbf has fewer records than .shp"
+		if len(recs) != 1 || err == nil || err.Error() != want || !errors.Is(err, ErrInvalid) {
+			t.Errorf("records = %v, %v, want 1 record and error %q", recs, err, want)
+		}
+	}
+}
+
+func TestNextRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(88))
+	for range 200 {
+		var contents [][]byte
+		var want []geom.Geometry
+		for range 1 + rnd.Intn(10) {
+			var parts [][]geom.Point
+			for range 2 + rnd.Intn(3) {
+				var ps []geom.Point
+				for range 2 + rnd.Intn(20) {
+					ps = append(ps, geom.Point{X: rnd.NormFloat64() * 100, Y: rnd.NormFloat64() * 100, Z: rnd.Float64(), M: rnd.Float64()})
+				}
+				parts = append(parts, ps)
+			}
+			contents = append(contents, poly(PolyLineZ, parts...))
+			m := make(geom.MultiLineString, len(parts))
+			for i, p := range parts {
+				m[i] = p
+			}
+			want = append(want, m)
+		}
+		shp, _ := shapefile(PolyLineZ, contents...)
+		r, _ := NewReader(bytes.NewReader(shp), nil)
+		recs, err := readAll(r)
+		if err != nil || len(recs) != len(want) {
+			t.Fatalf("records = %d, %v, want %d", len(recs), err, len(want))
+		}
+		for i, rec := range recs {
+			if rec.Number != i+1 || !reflect.DeepEqual(rec.Geometry, want[i]) {
+				t.Fatalf("record %d = %d, %v, want %v", i, rec.Number, rec.Geometry, want[i])
+			}
+		}
+	}
+}
+
+// writeShapefile writes files of names and contents to a directory, and
+// returns the name of the shapefile "places" in it.
+func writeShapefile(t *testing.T, dir string, files map[string][]byte) string {
+	t.Helper()
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "places")
+}
+
+func TestOpen(t *testing.T) {
+	var contents [][]byte
+	for i := range attrRecords {
+		contents = append(contents, le(int32(Point), float64(i), 0.0))
+	}
+	shp, shx := shapefile(Point, contents...)
+	const prj = `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`
+	name := writeShapefile(t, t.TempDir(), map[string][]byte{
+		"places.shp": shp,
+		"places.SHX": shx,
+		"places.DBF": dbfFile(attrFields, attrRecords...),
+		"places.prj": []byte(prj + "\r\n"),
+	})
+	r, err := Open(name + ".shp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Projection() != prj || r.Len() != 3 || len(r.Fields()) != len(attrFields) {
+		t.Errorf("Open = Projection %q, Len %d, Fields %v", r.Projection(), r.Len(), r.Fields())
+	}
+	// Seeking to the deleted record reads the one after it.
+	for _, tt := range []struct{ i, number int }{{2, 3}, {0, 1}, {1, 3}, {3, 0}} {
+		if err := r.Seek(tt.i); err != nil {
+			t.Fatalf("Seek(%d): %v", tt.i, err)
+		}
+		rec, err := r.Next()
+		if tt.number == 0 {
+			if err != io.EOF {
+				t.Errorf("Next after Seek(%d) = %v, %v, want EOF", tt.i, rec, err)
+			}
+			continue
+		}
+		if err != nil || rec.Number != tt.number || rec.Geometry != (geom.Point{X: float64(tt.number - 1)}) || rec.Attributes["NAME"] == nil {
+			t.Errorf("Next after Seek(%d) = %v, %v, want record %d", tt.i, rec, err, tt.number)
+		}
+	}
+	for _, i := range []int{-1, 4} {
+		if err := r.Seek(i); err == nil {
+			t.Errorf("Seek(%d) = nil, want an error", i)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestOpenWithoutSHX(t *testing.T) {
+	shp, _ := shapefile(Point, le(int32(Point), 1.0, 2.0))
+	name := writeShapefile(t, t.TempDir(), map[string][]byte{"places.SHP": shp})
+	r, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Len() != -1 || r.Fields() != nil || r.Projection() != "" {
+		t.Errorf("Open = Len %d, Fields %v, Projection %q, want -1, nil, \"\"", r.Len(), r.Fields(), r.Projection())
+	}
+	if err := r.Seek(0); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Seek without a .shx file = %v, want ErrUnsupported", err)
+	}
+	recs, err := readAll(r)
+	if err != nil || len(recs) != 1 || recs[0].Geometry != (geom.Point{X: 1, Y: 2}) || recs[0].Attributes != nil {
+		t.Errorf("records = %v, %v, want POINT (1 2)", recs, err)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	shp, _ := shapefile(Point)
+	dir := t.TempDir()
+	if _, err := Open(filepath.Join(dir, "missing.shp")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open of a missing file = %v, want ErrNotExist", err)
+	}
+	name := writeShapefile(t, dir, map[string][]byte{"places.shp": shp, "places.shx": []byte("not an index")})
+	if r, err := Open(name); err == nil || err.Error() != "shp: invalid shapefile: invalid .shx file" {
+		t.Errorf("Open with an invalid .shx file = %v, %v", r, err)
+	}
+	name = writeShapefile(t, t.TempDir(), map[string][]byte{"places.shp": shp[:10]})
+	if r, err := Open(name); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Open of an invalid .shp file = %v, %v, want ErrInvalid", r, err)
+	}
+}
@@ -0,0 +1,193 @@
+package tile
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// CoverLine returns the tiles at zoom level z which intersect a line
+// string, given as a sequence of (latitude, longitude) pairs in degrees.
+// The tiles are sorted by row and then by column, and each appears only
+// once.
+//
+// Each segment of the line is taken to be straight in the Web Mercator
+// plane, and longitudes are not wrapped, so a segment from longitude 179
+// to longitude -179 crosses the whole map rather than the antimeridian.
+// Split lines which cross the antimeridian before covering them.
+// Latitudes beyond MaxLat are clamped.
+func CoverLine(line [][2]float64, z int) []Tile {
+	s := newTileSet(z)
+	s.addLine(line, false)
+	return s.tiles()
+}
+
+// CoverPolygon returns the tiles at zoom level z which intersect a
+// polygon, given as a list of rings of (latitude, longitude) pairs in
+// degrees. The first ring is the outer boundary and any others are
+// holes. A ring need not repeat its first point at the end. The tiles
+// are sorted by row and then by column.
+//
+// As with CoverLine, edges are straight in the Web Mercator plane and
+// longitudes are not wrapped.
+func CoverPolygon(rings [][][2]float64, z int) []Tile {
+	s := newTileSet(z)
+	for _, ring := range rings {
+		s.addLine(ring, true)
+	}
+	s.fill(rings)
+	return s.tiles()
+}
+
+// CoverPolygonInterior is like CoverPolygon but returns only the tiles
+// which lie entirely inside the polygon. Tiles which the boundary of the
+// polygon touches are excluded, even if it only runs along one of their
+// edges.
+func CoverPolygonInterior(rings [][][2]float64, z int) []Tile {
+	boundary := newTileSet(z)
+	for _, ring := range rings {
+		boundary.addLine(ring, true)
+	}
+	s := newTileSet(z)
+	s.exclude = boundary.set
+	s.fill(rings)
+	return s.tiles()
+}
+
+// tileSet accumulates the distinct tiles of a covering at one zoom
+// level.
+type tileSet struct {
+	z       int
+	n       float64
+	set     map[[2]uint32]bool
+	exclude map[[2]uint32]bool
+}
+
+func newTileSet(z int) *tileSet {
+	return &tileSet{z: z, n: float64(uint64(1) << uint(z)), set: map[[2]uint32]bool{}}
+}
+
+// add adds the tile with column x and row y, if it is not excluded.
+func (s *tileSet) add(x, y int) {
+	k := [2]uint32{uint32(x), uint32(y)}
+	if !s.exclude[k] {
+		s.set[k] = true
+	}
+}
+
+// point returns the position of a point in fractional tile coordinates,
+// clamped to the square so that its tile is always valid.
+func (s *tileSet) point(p [2]float64) (x, y float64) {
+	fx, fy := project(p[0], max(-180, min(180, p[1])))
+	limit := math.Nextafter(s.n, 0)
+	return max(0, min(limit, fx*s.n)), max(0, min(limit, fy*s.n))
+}
+
+// addLine adds the tiles which intersect a line string, or a ring if
+// closed is true.
+func (s *tileSet) addLine(line [][2]float64, closed bool) {
+	if len(line) == 0 {
+		return
+	}
+	x0, y0 := s.point(line[0])
+	s.add(int(x0), int(y0))
+	for k := 1; k <= len(line); k++ {
+		if k == len(line) && !closed {
+			break
+		}
+		x1, y1 := s.point(line[k%len(line)])
+		s.addSegment(x0, y0, x1, y1)
+		x0, y0 = x1, y1
+	}
+}
+
+// addSegment adds the tiles which a segment between two points in
+// fractional tile coordinates passes through, by stepping from tile to
+// tile across whichever grid line the segment meets next.
+func (s *tileSet) addSegment(x0, y0, x1, y1 float64) {
+	tx, ty := int(x0), int(y0)
+	ex, ey := int(x1), int(y1)
+	stepX, nextX, deltaX := gridStep(x0, x1)
+	stepY, nextY, deltaY := gridStep(y0, y1)
+	for tx != ex || ty != ey {
+		if ty == ey || tx != ex && nextX < nextY {
+			tx += stepX
+			nextX += deltaX
+		} else {
+			ty += stepY
+			nextY += deltaY
+		}
+		s.add(tx, ty)
+	}
+}
+
+// gridStep returns, for one axis of a segment from a to b, the direction
+// in which the segment crosses grid lines, the fraction of the segment
+// at which it crosses the first, and the fraction between consecutive
+// crossings.
+func gridStep(a, b float64) (step int, next, delta float64) {
+	d := b - a
+	switch {
+	case d > 0:
+		return 1, (math.Floor(a) + 1 - a) / d, 1 / d
+	case d < 0:
+		return -1, (a - math.Floor(a)) / -d, 1 / -d
+	default:
+		return 0, math.Inf(1), math.Inf(1)
+	}
+}
+
+// fill adds the tiles whose centers lie inside the polygon, under the
+// even-odd rule. Together with the tiles on the boundary, these are all
+// the tiles which intersect the polygon.
+func (s *tileSet) fill(rings [][][2]float64) {
+	var pts [][][2]float64
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, ring := range rings {
+		ps := make([][2]float64, len(ring))
+		for k, p := range ring {
+			ps[k][0], ps[k][1] = s.point(p)
+			minY, maxY = min(minY, ps[k][1]), max(maxY, ps[k][1])
+		}
+		pts = append(pts, ps)
+	}
+	if minY > maxY {
+		return
+	}
+	var xs []float64
+	for ty := int(minY); ty <= int(maxY); ty++ {
+		yc := float64(ty) + 0.5
+		xs = xs[:0]
+		for _, ps := range pts {
+			for k := range ps {
+				a, b := ps[k], ps[(k+1)%len(ps)]
+				if (a[1] <= yc) != (b[1] <= yc) {
+					xs = append(xs, a[0]+(yc-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+				}
+			}
+		}
+		slices.Sort(xs)
+		for k := 0; k+1 < len(xs); k += 2 {
+			lo := int(math.Ceil(xs[k] - 0.5))
+			hi := int(math.Floor(xs[k+1] - 0.5))
+			for tx := lo; tx <= hi; tx++ {
+				s.add(tx, ty)
+			}
+		}
+	}
+}
+
+// tiles returns the accumulated tiles, sorted by row then column.
+func (s *tileSet) tiles() []Tile {
+	ts := make([]Tile, 0, len(s.set))
+	for k := range s.set {
+		ts = append(ts, Tile{Z: s.z, X: k[0], Y: k[1]})
+	}
+	slices.SortFunc(ts, func(a, b Tile) int {
+		if c := cmp.Compare(a.Y, b.Y); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.X, b.X)
+	})
+	return ts
+}
@@ -0,0 +1,136 @@
+package tile
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// rect returns the tiles at zoom level z from column x0 to x1 and row y0
+// to y1 inclusive, in the order of a covering.
+func rect(z int, x0, y0, x1, y1 uint32) []Tile {
+	var ts []Tile
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			ts = append(ts, Tile{z, x, y})
+		}
+	}
+	return ts
+}
+
+func TestCoverLine(t *testing.T) {
+	tests := []struct {
+		line [][2]float64
+		z    int
+		want []Tile
+	}{
+		{nil, 4, []Tile{}},
+		{[][2]float64{{10, 10}}, 4, []Tile{FromLatLng(10, 10, 4)}},
+		{[][2]float64{{1, -100}, {1, 100}}, 2, rect(2, 0, 1, 3, 1)},
+		{[][2]float64{{80, 1}, {-80, 1}}, 2, rect(2, 2, 0, 2, 3)},
+		{[][2]float64{{1, 1}, {-1, 1}}, 3, rect(3, 4, 3, 4, 4)},
+		{[][2]float64{{1, 1}, {-1, 1}, {-1, -1}}, 3, rect(3, 3, 3, 4, 4)[1:]},
+	}
+	for _, tt := range tests {
+		if got := CoverLine(tt.line, tt.z); !slices.Equal(got, tt.want) {
+			t.Errorf("CoverLine(%v, %d) = %v, want %v", tt.line, tt.z, got, tt.want)
+		}
+	}
+}
+
+func TestCoverLineRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(27))
+	for range 1000 {
+		z := 2 + r.Intn(10)
+		a := [2]float64{r.Float64()*160 - 80, r.Float64()*360 - 180}
+		b := [2]float64{r.Float64()*160 - 80, r.Float64()*360 - 180}
+		ts := CoverLine([][2]float64{a, b}, z)
+		// The segment crosses one grid line at a time between its end
+		// tiles, so the cover has one tile per crossing and one more.
+		ta, tb := FromLatLng(a[0], a[1], z), FromLatLng(b[0], b[1], z)
+		dx, dy := int(ta.X)-int(tb.X), int(ta.Y)-int(tb.Y)
+		if len(ts) != abs(dx)+abs(dy)+1 || !slices.Contains(ts, ta) || !slices.Contains(ts, tb) {
+			t.Fatalf("CoverLine(%v, %v) at zoom %d has %d tiles, want %d", a, b, z, len(ts), abs(dx)+abs(dy)+1)
+		}
+		// Every point along the segment, which is straight in the Web
+		// Mercator plane, is in one of the tiles.
+		ax, ay := project(a[0], a[1])
+		bx, by := project(b[0], b[1])
+		n := float64(uint64(1) << z)
+		for k := range 1000 {
+			f := (float64(k) + 0.5) / 1000
+			tl := Tile{z, uint32((ax + f*(bx-ax)) * n), uint32((ay + f*(by-ay)) * n)}
+			if !slices.Contains(ts, tl) {
+				t.Fatalf("CoverLine(%v, %v) at zoom %d is missing %v", a, b, z, tl)
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestCoverPolygon(t *testing.T) {
+	full := [][2]float64{{90, -180}, {90, 180}, {-90, 180}, {-90, -180}}
+	box := [][2]float64{{60, -90}, {60, 90}, {-60, 90}, {-60, -90}}
+	if got := CoverPolygon([][][2]float64{full}, 4); !slices.Equal(got, rect(4, 0, 0, 15, 15)) {
+		t.Errorf("CoverPolygon(world, 4) = %d tiles, want 256", len(got))
+	}
+	if got := CoverPolygonInterior([][][2]float64{full}, 4); !slices.Equal(got, rect(4, 1, 1, 14, 14)) {
+		t.Errorf("CoverPolygonInterior(world, 4) = %v", got)
+	}
+	// The box runs along the edges of tiles, so the tiles outside its
+	// edges touch it and those inside them are inside it.
+	nw, se := FromLatLng(60, -90, 4), FromLatLng(-60, 90, 4)
+	if got, want := CoverPolygon([][][2]float64{box}, 4), rect(4, nw.X, nw.Y, se.X, se.Y); !slices.Equal(got, want) {
+		t.Errorf("CoverPolygon(box, 4) = %v, want %v", got, want)
+	}
+	inner := rect(4, nw.X+1, nw.Y+1, se.X-1, se.Y-1)
+	if got := CoverPolygonInterior([][][2]float64{box}, 4); !slices.Equal(got, inner) {
+		t.Errorf("CoverPolygonInterior(box, 4) = %v, want %v", got, inner)
+	}
+	// With the box as a hole, none of its interior is covered.
+	holed := CoverPolygon([][][2]float64{full, box}, 4)
+	if len(holed) != 256-len(inner) {
+		t.Errorf("CoverPolygon(world less box, 4) = %d tiles, want %d", len(holed), 256-len(inner))
+	}
+	for _, tl := range inner {
+		if slices.Contains(holed, tl) {
+			t.Errorf("CoverPolygon(world less box, 4) has %v, in the hole", tl)
+		}
+	}
+	if got := CoverPolygon(nil, 4); len(got) != 0 {
+		t.Errorf("CoverPolygon(nil, 4) = %v", got)
+	}
+}
+
+func TestCoverPolygonRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(27))
+	for range 200 {
+		z := 3 + r.Intn(5)
+		var ring [][2]float64
+		for k := range 3 + r.Intn(5) {
+			// A star-shaped polygon around a random center.
+			a := 2 * math.Pi * float64(k) / 8
+			d := 5 + 20*r.Float64()
+			ring = append(ring, [2]float64{10 + d*math.Sin(a), 20 + d*math.Cos(a)})
+		}
+		all := CoverPolygon([][][2]float64{ring}, z)
+		interior := CoverPolygonInterior([][][2]float64{ring}, z)
+		for _, tl := range interior {
+			if !slices.Contains(all, tl) {
+				t.Fatalf("interior tile %v is not in the cover", tl)
+			}
+		}
+		for _, tl := range CoverLine(append(ring, ring[0]), z) {
+			if !slices.Contains(all, tl) || slices.Contains(interior, tl) {
+				t.Fatalf("boundary tile %v is missing from the cover or in the interior", tl)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package tile implements the arithmetic of slippy map tiles: the
+// square tiles of the Web Mercator projection, addressed as z/x/y, which
+// are used by OpenStreetMap, Google Maps, Bing Maps and most other web
+// maps.
+//
+// At zoom level z the Web Mercator square is divided into 2^z X 2^z
+// tiles. In the XYZ scheme used throughout this package, tile (0, 0) is
+// in the upper left-hand (north-west) corner and y increases southward.
+// The TMS scheme numbers rows from the south instead; FlipY converts
+// between the two.
+package tile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/geospat/geodesy"
+	"github.com/gogama/geospat/proj"
+)
+
+// MaxZoom is the largest supported zoom level.
+const MaxZoom = 31
+
+// MaxLat is the latitude, in degrees, of the northern edge of the Web
+// Mercator square. The southern edge is at -MaxLat.
+const MaxLat = proj.WebMercatorMaxLat
+
+// Radius is the radius in meters of the sphere on which Web Mercator
+// coordinates are computed, the semi-major axis of WGS84.
+const Radius = proj.WebMercatorRadius
+
+// HalfWidth is half the width of the Web Mercator square in meters. Web
+// Mercator coordinates range from -HalfWidth to HalfWidth on both axes.
+const HalfWidth = proj.WebMercatorHalfWidth
+
+// ErrInvalid is returned, wrapped, by Parse when its argument is not a
+// valid tile.
+var ErrInvalid = errors.New("tile: invalid tile")
+
+// Tile identifies a map tile in the XYZ scheme. Z is the zoom level and
+// X and Y are the column and row of the tile, counted from the
+// north-west corner. The zero value is the single tile at zoom level 0.
+type Tile struct {
+	Z    int
+	X, Y uint32
+}
+
+// FromLatLng returns the tile at zoom level z which contains the point
+// with the given latitude and longitude, in degrees. The zoom level must
+// be in the range [0, MaxZoom]. Latitudes beyond MaxLat are clamped and
+// longitudes are wrapped, so every point maps to a valid tile.
+func FromLatLng(lat, lng float64, z int) Tile {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	fx, fy := project(lat, lng-180)
+	n := float64(uint64(1) << uint(z))
+	last := n - 1
+	return Tile{
+		Z: z,
+		X: uint32(max(0, min(last, math.Floor(fx*n)))),
+		Y: uint32(max(0, min(last, math.Floor(fy*n)))),
+	}
+}
+
+// project returns the position of a point as fractions of the width and
+// height of the Web Mercator square, measured from its north-west
+// corner. Latitudes beyond MaxLat are clamped.
+func project(lat, lng float64) (fx, fy float64) {
+	x, y := proj.WebMercator{}.Forward(geodesy.LatLng{Lat: lat, Lng: lng})
+	return (x + HalfWidth) / (2 * HalfWidth), (HalfWidth - y) / (2 * HalfWidth)
+}
+
+// n returns the number of tiles along each side of the square at the
+// zoom level of t.
+func (t Tile) n() uint64 {
+	return uint64(1) << uint(t.Z)
+}
+
+// IsValid reports whether the zoom level of t is in the range
+// [0, MaxZoom] and its column and row are within the square at that
+// zoom level.
+func (t Tile) IsValid() bool {
+	return t.Z >= 0 && t.Z <= MaxZoom && uint64(t.X) < t.n() && uint64(t.Y) < t.n()
+}
+
+// Clamp returns the valid tile nearest to t, by clamping the zoom level
+// to [0, MaxZoom] and then the column and row to [0, 2^Z-1].
+func (t Tile) Clamp() Tile {
+	t.Z = max(0, min(MaxZoom, t.Z))
+	last := uint32(t.n() - 1)
+	t.X, t.Y = min(last, t.X), min(last, t.Y)
+	return t
+}
+
+// FlipY converts a tile between the XYZ and TMS schemes, which differ
+// only in the direction in which rows are counted. Since the conversion
+// is its own inverse, FlipY works in either direction. The tile must be
+// valid.
+func (t Tile) FlipY() Tile {
+	t.Y = uint32(t.n() - 1 - uint64(t.Y))
+	return t
+}
+
+// Parent returns the tile at the next lower zoom level which contains
+// t. The zoom level of t must be greater than 0.
+func (t Tile) Parent() Tile {
+	return Tile{Z: t.Z - 1, X: t.X / 2, Y: t.Y / 2}
+}
+
+// Children returns the four tiles at the next higher zoom level which
+// make up t, in the order upper left, upper right, lower left, lower
+// right. The zoom level of t must be less than MaxZoom.
+func (t Tile) Children() [4]Tile {
+	z, x, y := t.Z+1, 2*t.X, 2*t.Y
+	return [4]Tile{{z, x, y}, {z, x + 1, y}, {z, x, y + 1}, {z, x + 1, y + 1}}
+}
+
+// Bounds returns the longitudes of the western and eastern edges of the
+// tile, and the latitudes of its southern and northern edges, in
+// degrees.
+func (t Tile) Bounds() (west, south, east, north float64) {
+	n := float64(t.n())
+	west = float64(t.X)/n*360 - 180
+	east = float64(t.X+1)/n*360 - 180
+	north = yToLat(float64(t.Y) / n)
+	south = yToLat(float64(t.Y+1) / n)
+	return
+}
+
+// yToLat returns the latitude in degrees of a fraction of the height of
+// the Web Mercator square, measured from its northern edge.
+func yToLat(f float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*f))) * 180 / math.Pi
+}
+
+// MercatorBounds returns the bounds of the tile in Web Mercator
+// (EPSG:3857) meters.
+func (t Tile) MercatorBounds() (minX, minY, maxX, maxY float64) {
+	size := 2 * HalfWidth / float64(t.n())
+	minX = float64(t.X)*size - HalfWidth
+	maxX = float64(t.X+1)*size - HalfWidth
+	maxY = HalfWidth - float64(t.Y)*size
+	minY = HalfWidth - float64(t.Y+1)*size
+	return
+}
+
+// String returns the tile in the conventional z/x/y form, for example
+// "12/654/1583".
+func (t Tile) String() string {
+	return fmt.Sprintf("%d/%d/%d", t.Z, t.X, t.Y)
+}
+
+// Parse parses a tile in the z/x/y form produced by String. Only that
+// canonical form is accepted: exactly three decimal integers separated
+// by slashes, without signs, spaces or leading zeros, so that
+// Parse(t.String()) returns t for every valid tile t and every other
+// string is an error. The error wraps ErrInvalid if s is not of that
+// form or the resulting tile is not valid.
+func Parse(s string) (Tile, error) {
+	fields := strings.Split(s, "/")
+	if len(fields) != 3 {
+		return Tile{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	var v [3]int
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || strconv.Itoa(n) != f {
+			return Tile{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		v[i] = n
+	}
+	z, x, y := v[0], v[1], v[2]
+	if z < 0 || z > MaxZoom || x < 0 || x>>z != 0 || y < 0 || y>>z != 0 {
+		return Tile{}, fmt.Errorf("%w: %q is outside the square at its zoom level", ErrInvalid, s)
+	}
+	return Tile{Z: z, X: uint32(x), Y: uint32(y)}, nil
+}
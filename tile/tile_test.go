@@ -0,0 +1,117 @@
+package tile
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFromLatLng(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		z        int
+		want     Tile
+	}{
+		{47.61, -122.33, 12, Tile{12, 656, 1430}},
+		{0, 0, 0, Tile{}},
+		{0, 0, 1, Tile{1, 1, 1}},
+		{90, 0, 3, Tile{3, 4, 0}},
+		{-90, -180, 3, Tile{3, 0, 7}},
+		{0, 180, 3, Tile{3, 0, 4}},
+		{0, 540.5, 3, Tile{3, 0, 4}},
+		{-1e-9, 179.9999999999, MaxZoom, Tile{MaxZoom, 1<<MaxZoom - 1, 1 << (MaxZoom - 1)}},
+	}
+	for _, tt := range tests {
+		if got := FromLatLng(tt.lat, tt.lng, tt.z); got != tt.want {
+			t.Errorf("FromLatLng(%v, %v, %d) = %v, want %v", tt.lat, tt.lng, tt.z, got, tt.want)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		t                        Tile
+		west, south, east, north float64
+	}{
+		{Tile{}, -180, -MaxLat, 180, MaxLat},
+		{Tile{1, 1, 0}, 0, 0, 180, MaxLat},
+		{Tile{12, 656, 1430}, -122.34375, 47.57652571374621, -122.255859375, 47.635783590864854},
+	}
+	for _, tt := range tests {
+		w, s, e, n := tt.t.Bounds()
+		if math.Abs(w-tt.west) > 1e-9 || math.Abs(s-tt.south) > 1e-9 || math.Abs(e-tt.east) > 1e-9 || math.Abs(n-tt.north) > 1e-9 {
+			t.Errorf("%v.Bounds() = (%v, %v, %v, %v), want (%v, %v, %v, %v)", tt.t, w, s, e, n, tt.west, tt.south, tt.east, tt.north)
+		}
+	}
+	minX, minY, maxX, maxY := Tile{}.MercatorBounds()
+	if minX != -HalfWidth || minY != -HalfWidth || maxX != HalfWidth || maxY != HalfWidth {
+		t.Errorf("MercatorBounds() = (%v, %v, %v, %v), want ±%v", minX, minY, maxX, maxY, HalfWidth)
+	}
+	minX, minY, maxX, maxY = Tile{12, 656, 1430}.MercatorBounds()
+	if math.Abs(maxX-minX-2*HalfWidth/4096) > 1e-6 || math.Abs(maxY-minY-2*HalfWidth/4096) > 1e-6 || math.Abs(minX+13619243.951739565) > 1e-6 {
+		t.Errorf("MercatorBounds() = (%v, %v, %v, %v)", minX, minY, maxX, maxY)
+	}
+}
+
+func TestHierarchy(t *testing.T) {
+	r := rand.New(rand.NewSource(26))
+	for range 10000 {
+		z := r.Intn(MaxZoom)
+		tl := Tile{z, uint32(r.Int63n(1 << z)), uint32(r.Int63n(1 << z))}
+		for k, c := range tl.Children() {
+			if !c.IsValid() || c.Parent() != tl || c.Z != z+1 || c.X != 2*tl.X+uint32(k%2) || c.Y != 2*tl.Y+uint32(k/2) {
+				t.Fatalf("%v.Children()[%d] = %v", tl, k, c)
+			}
+		}
+		if f := tl.FlipY(); !f.IsValid() || f.FlipY() != tl || uint64(f.Y)+uint64(tl.Y) != tl.n()-1 {
+			t.Fatalf("%v.FlipY() = %v", tl, f)
+		}
+		w, s, e, n := tl.Bounds()
+		if c := FromLatLng((s+n)/2, (w+e)/2, z); c != tl {
+			t.Fatalf("FromLatLng of the middle of %v = %v", tl, c)
+		}
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		t     Tile
+		valid bool
+		clamp Tile
+	}{
+		{Tile{}, true, Tile{}},
+		{Tile{3, 7, 7}, true, Tile{3, 7, 7}},
+		{Tile{3, 8, 0}, false, Tile{3, 7, 0}},
+		{Tile{-1, 1, 1}, false, Tile{}},
+		{Tile{40, 5, 1<<32 - 1}, false, Tile{MaxZoom, 5, 1<<MaxZoom - 1}},
+	}
+	for _, tt := range tests {
+		if v := tt.t.IsValid(); v != tt.valid {
+			t.Errorf("%v.IsValid() = %v, want %v", tt.t, v, tt.valid)
+		}
+		if c := tt.t.Clamp(); c != tt.clamp {
+			t.Errorf("%v.Clamp() = %v, want %v", tt.t, c, tt.clamp)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tl := range []Tile{{}, {12, 656, 1430}, {MaxZoom, 1<<MaxZoom - 1, 0}} {
+		s := tl.String()
+		if got, err := Parse(s); got != tl || err != nil {
+			t.Errorf("Parse(%q) = (%v, %v), want %v", s, got, err, tl)
+		}
+	}
+	if s := (Tile{12, 656, 1430}).String(); s != "12/656/1430" {
+		t.Errorf("String() = %q", s)
+	}
+	for _, s := range []string{
+		"", "1/2", "1/2/0", "1/1/0x", "1/1/0/", "1/1/0/0", "01/1/0", "1/+1/0", "1/-0/0",
+		" 1/1/0", "1/1/ 0", "-1/0/0", "32/0/0", "2/4294967296/0", "1/1/99999999999999999999",
+	} {
+		if tl, err := Parse(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) = (%v, %v), want %v", s, tl, err, ErrInvalid)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package twkb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Unmarshal returns the geometry of TWKB data, its layout, and the ID
+// list of its members, or nil if it has none. The optional headers are
+// read if they are present, but the bounding box is not checked against
+// the coordinates, and every member of a GeometryCollection must have
+// the same layout. An empty geometry is read as an empty point, or as a
+// nil slice of its type.
+//
+// It returns an error wrapping ErrInvalid, telling what is wrong and at
+// which byte offset, if the data is not a single valid geometry.
+func Unmarshal(data []byte) (geom.Geometry, geom.Layout, []int64, error) {
+	r := reader{b: data, layout: -1}
+	g, ids, err := r.geometry()
+	if err == nil && r.pos < len(r.b) {
+		err = r.errorf("%d bytes after the geometry", len(r.b)-r.pos)
+	}
+	if err != nil {
+		return nil, geom.XY, nil, err
+	}
+	return g, r.layout, ids, nil
+}
+
+// reader reads TWKB.
+type reader struct {
+	b   []byte
+	pos int
+	// layout is the layout of the geometry, or -1 until it is known.
+	layout geom.Layout
+	// precisions are the precisions of the coordinates of the current
+	// geometry.
+	precisions []int
+	// last holds the rounded coordinates of the previous point.
+	last [4]int64
+}
+
+// errorf returns an error wrapping ErrInvalid, telling what is wrong at
+// the position.
+func (r *reader) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalid, fmt.Sprintf(format, args...), r.pos)
+}
+
+// byte reads a byte.
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, r.errorf("unexpected end of data")
+	}
+	r.pos++
+	return r.b[r.pos-1], nil
+}
+
+// uvarint reads an unsigned variable-length integer.
+func (r *reader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	if n <= 0 {
+		return 0, r.errorf("invalid varint")
+	}
+	r.pos += n
+	return v, nil
+}
+
+// varint reads a signed variable-length integer.
+func (r *reader) varint() (int64, error) {
+	v, n := binary.Varint(r.b[r.pos:])
+	if n <= 0 {
+		return 0, r.errorf("invalid varint")
+	}
+	r.pos += n
+	return v, nil
+}
+
+// count reads a count of elements, each of which takes at least size
+// bytes, so that a count too large for the data is found before
+// anything is allocated.
+func (r *reader) count(size int) (int, error) {
+	start := r.pos
+	n, err := r.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(r.b)-r.pos)/uint64(size) {
+		r.pos = start
+		return 0, r.errorf("count %d exceeds the data", n)
+	}
+	return int(n), nil
+}
+
+// geometry reads a geometry and its ID list.
+func (r *reader) geometry() (geom.Geometry, []int64, error) {
+	start := r.pos
+	tp, err := r.byte()
+	if err != nil {
+		return nil, nil, err
+	}
+	t := geom.Type(tp & 0x0f)
+	if t < geom.TypePoint || t > geom.TypeGeometryCollection {
+		r.pos = start
+		return nil, nil, r.errorf("invalid type %d", t)
+	}
+	u := uint64(tp >> 4)
+	precision := int(u>>1) ^ -int(u&1)
+	meta, err := r.byte()
+	if err != nil {
+		return nil, nil, err
+	}
+	layout, zPrecision, mPrecision := geom.XY, 0, 0
+	if meta&hasExtended != 0 {
+		ext, err := r.byte()
+		if err != nil {
+			return nil, nil, err
+		}
+		if ext&1 != 0 {
+			layout, zPrecision = geom.XYZ, int(ext>>2&7)
+		}
+		if ext&2 != 0 {
+			layout, mPrecision = layout|geom.XYM, int(ext>>5) // XYM, or XYZM with Z
+		}
+	}
+	if r.layout >= 0 && layout != r.layout {
+		r.pos = start
+		return nil, nil, r.errorf("%v of layout %v in a geometry of layout %v", t, layout, r.layout)
+	}
+	r.layout = layout
+	end := len(r.b)
+	var size uint64
+	if meta&hasSize != 0 {
+		if size, err = r.uvarint(); err != nil {
+			return nil, nil, err
+		}
+		if size > uint64(len(r.b)-r.pos) {
+			return nil, nil, r.errorf("size %d exceeds the data", size)
+		}
+		end = r.pos + int(size)
+	}
+	if meta&isEmpty != 0 {
+		if meta&hasSize != 0 && r.pos != end {
+			return nil, nil, r.errorf("empty %v of size %d", t, end-r.pos)
+		}
+		switch t {
+		case geom.TypePoint:
+			return geom.EmptyPoint(), nil, nil
+		case geom.TypeLineString:
+			return geom.LineString(nil), nil, nil
+		case geom.TypePolygon:
+			return geom.Polygon(nil), nil, nil
+		case geom.TypeMultiPoint:
+			return geom.MultiPoint(nil), nil, nil
+		case geom.TypeMultiLineString:
+			return geom.MultiLineString(nil), nil, nil
+		case geom.TypeMultiPolygon:
+			return geom.MultiPolygon(nil), nil, nil
+		}
+		return geom.GeometryCollection(nil), nil, nil
+	}
+	r.precisions = []int{precision, precision}
+	if layout.HasZ() {
+		r.precisions = append(r.precisions, zPrecision)
+	}
+	if layout.HasM() {
+		r.precisions = append(r.precisions, mPrecision)
+	}
+	if meta&hasBBox != 0 {
+		for range 2 * len(r.precisions) {
+			if _, err := r.varint(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	r.last = [4]int64{}
+	var g geom.Geometry
+	var ids []int64
+	switch t {
+	case geom.TypePoint:
+		g, err = r.position()
+	case geom.TypeLineString:
+		g, err = r.positions()
+	case geom.TypePolygon:
+		g, err = r.polygon()
+	default:
+		g, ids, err = r.members(t, meta&hasIDs != 0)
+	}
+	if err == nil && meta&hasSize != 0 && r.pos != end {
+		err = r.errorf("size %d of %v does not match its %d bytes", size, t, r.pos-end+int(size))
+	}
+	return g, ids, err
+}
+
+// members reads the members of a multipart geometry or collection, and
+// their IDs if they have them.
+func (r *reader) members(t geom.Type, hasIDs bool) (geom.Geometry, []int64, error) {
+	n, err := r.count(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ids []int64
+	if hasIDs {
+		ids = make([]int64, n)
+		for i := range ids {
+			if ids[i], err = r.varint(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	switch t {
+	case geom.TypeMultiPoint:
+		m := make(geom.MultiPoint, n)
+		for i := range m {
+			if m[i], err = r.position(); err != nil {
+				return nil, nil, err
+			}
+		}
+		return m, ids, nil
+	case geom.TypeMultiLineString:
+		m := make(geom.MultiLineString, n)
+		for i := range m {
+			if m[i], err = r.positions(); err != nil {
+				return nil, nil, err
+			}
+		}
+		return m, ids, nil
+	case geom.TypeMultiPolygon:
+		m := make(geom.MultiPolygon, n)
+		for i := range m {
+			if m[i], err = r.polygon(); err != nil {
+				return nil, nil, err
+			}
+		}
+		return m, ids, nil
+	}
+	c := make(geom.GeometryCollection, n)
+	for i := range c {
+		if c[i], _, err = r.geometry(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return c, ids, nil
+}
+
+// polygon reads a count of rings and the rings.
+func (r *reader) polygon() (geom.Polygon, error) {
+	n, err := r.count(1)
+	if err != nil {
+		return nil, err
+	}
+	p := make(geom.Polygon, n)
+	for i := range p {
+		l, err := r.positions()
+		if err != nil {
+			return nil, err
+		}
+		p[i] = geom.Ring(l)
+	}
+	return p, nil
+}
+
+// positions reads a count of positions and the positions.
+func (r *reader) positions() (geom.LineString, error) {
+	n, err := r.count(len(r.precisions))
+	if err != nil {
+		return nil, err
+	}
+	l := make(geom.LineString, n)
+	for i := range l {
+		if l[i], err = r.position(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// position reads the deltas of the rounded coordinates of a point.
+func (r *reader) position() (geom.Point, error) {
+	var cs [4]float64
+	for i, prec := range r.precisions {
+		d, err := r.varint()
+		if err != nil {
+			return geom.Point{}, err
+		}
+		r.last[i] += d
+		// Divide by a power of ten which is exact, rather than multiply by
+		// its inexact inverse, for the nearest float64 to the decimal.
+		if prec >= 0 {
+			cs[i] = float64(r.last[i]) / math.Pow10(prec)
+		} else {
+			cs[i] = float64(r.last[i]) * math.Pow10(-prec)
+		}
+	}
+	p := geom.Point{X: cs[0], Y: cs[1]}
+	switch r.layout {
+	case geom.XYZ:
+		p.Z = cs[2]
+	case geom.XYM:
+		p.M = cs[2]
+	case geom.XYZM:
+		p.Z, p.M = cs[2], cs[3]
+	}
+	return p, nil
+}
@@ -0,0 +1,78 @@
+package twkb
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		twkb   string
+		g      geom.Geometry
+		layout geom.Layout
+	}{
+		// The bounding box is skipped, whatever its values.
+		{"01 01 00 00 00 00 02 04", geom.Point{X: 1, Y: 2}, geom.XY},
+		// The ID list of a member of a collection is read and dropped.
+		{"07 00 01 04 04 01 02 02 04", geom.GeometryCollection{geom.MultiPoint{{X: 1, Y: 2}}}, geom.XY},
+		// Extended precision with neither Z nor M.
+		{"01 08 00 02 04", geom.Point{X: 1, Y: 2}, geom.XY},
+		{"07 08 01 01 01 08 01 02 04 06", geom.GeometryCollection{geom.Point{X: 1, Y: 2, Z: 3}}, geom.XYZ},
+	}
+	for _, tt := range tests {
+		g, layout, _, err := Unmarshal(unhex(tt.twkb))
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != tt.layout {
+			t.Errorf("Unmarshal(%s) = %v, %v, %v, want %v, %v", tt.twkb, g, layout, err, tt.g, tt.layout)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		twkb string
+		err  string
+	}{
+		{"", "twkb: invalid TWKB: unexpected end of data at offset 0"},
+		{"00 00", "twkb: invalid TWKB: invalid type 0 at offset 0"},
+		{"08 00", "twkb: invalid TWKB: invalid type 8 at offset 0"},
+		{"01", "twkb: invalid TWKB: unexpected end of data at offset 1"},
+		{"01 08", "twkb: invalid TWKB: unexpected end of data at offset 2"},
+		{"01 00 02", "twkb: invalid TWKB: invalid varint at offset 3"},
+		{"01 00 82", "twkb: invalid TWKB: invalid varint at offset 2"},
+		{"01 00 02 04 00", "twkb: invalid TWKB: 1 bytes after the geometry at offset 4"},
+		{"02 00 05 02 04", "twkb: invalid TWKB: count 5 exceeds the data at offset 2"},
+		{"01 02 05 02 04", "twkb: invalid TWKB: size 5 exceeds the data at offset 3"},
+		{"01 02 01 02 04", "twkb: invalid TWKB: size 1 of Point does not match its 2 bytes at offset 5"},
+		{"01 12 01 00", "twkb: invalid TWKB: empty Point of size 1 at offset 3"},
+		{"07 00 01 01 08 01 02 04 06", "twkb: invalid TWKB: Point of layout XYZ in a geometry of layout XY at offset 3"},
+		{"04 04 01 82", "twkb: invalid TWKB: invalid varint at offset 3"},
+		{"03 00 01 01 00 80", "twkb: invalid TWKB: invalid varint at offset 5"},
+		{"03 00 01 02 00 00", "twkb: invalid TWKB: count 2 exceeds the data at offset 3"},
+	}
+	for _, tt := range tests {
+		g, _, _, err := Unmarshal(unhex(tt.twkb))
+		if !errors.Is(err, ErrInvalid) || err.Error() != tt.err {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %s", tt.twkb, g, err, tt.err)
+		}
+	}
+}
+
+func TestUnmarshalCorrupt(t *testing.T) {
+	// Data with bytes changed or cut off is read, or found invalid,
+	// without a panic.
+	r := rand.New(rand.NewSource(84))
+	for range 20000 {
+		b := unhex(marshalTests[r.Intn(len(marshalTests))].twkb)
+		for range 1 + r.Intn(3) {
+			b[r.Intn(len(b))] = byte(r.Intn(256))
+		}
+		b = b[:r.Intn(len(b)+1)]
+		if _, _, _, err := Unmarshal(b); err != nil && !errors.Is(err, ErrInvalid) {
+			t.Fatalf("Unmarshal(%X) error = %v, want %v", b, err, ErrInvalid)
+		}
+	}
+}
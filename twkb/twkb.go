@@ -0,0 +1,324 @@
+// Package twkb reads and writes geometries as Tiny Well-Known Binary,
+// a compact binary format in which coordinates are rounded to a number
+// of decimal digits and written as variable-length deltas from the
+// previous point, typically a quarter of the size of WKB.
+//
+// Each geometry has a header of at least two bytes, giving its type and
+// precision and which of the optional parts follow: the extended
+// precision of Z and M, the size in bytes of the rest of the geometry,
+// so that a reader may skip it, its bounding box, and a list of integer
+// identifiers of the members of a multipart geometry or collection.
+// The specification is at https://github.com/TWKB/Specification.
+package twkb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, by Unmarshal when data is not
+	// valid TWKB.
+	ErrInvalid = errors.New("twkb: invalid TWKB")
+
+	// ErrUnsupported is returned, wrapped, by Format.Marshal when a
+	// geometry cannot be written as TWKB in a format.
+	ErrUnsupported = errors.New("twkb: unsupported geometry")
+)
+
+// The bits of the metadata byte of the header.
+const (
+	hasBBox     = 1 << 0
+	hasSize     = 1 << 1
+	hasIDs      = 1 << 2
+	hasExtended = 1 << 3
+	isEmpty     = 1 << 4
+)
+
+// Format is the way geometries are written as TWKB. The zero value
+// rounds coordinates to integers and writes no optional headers.
+type Format struct {
+	// Precision is the number of decimal digits to which X and Y are
+	// rounded, from -8 to 7, a negative precision rounding to tens,
+	// hundreds and so on. For longitudes and latitudes, 5 rounds to
+	// about a meter and 7 to about a centimeter.
+	Precision int
+
+	// ZPrecision and MPrecision are the numbers of decimal digits, from
+	// 0 to 7, to which Z and M are rounded, if the layout has them.
+	ZPrecision, MPrecision int
+
+	// Size, if true, writes the size of each geometry in its header.
+	Size bool
+
+	// BBox, if true, writes the bounding box of each geometry which is
+	// not empty in its header, in the rounded coordinates.
+	BBox bool
+}
+
+// Marshal returns the TWKB of a geometry with the coordinates of the
+// given layout, and with the ID list ids if it is not nil, which is only
+// allowed for the multipart geometries and collections and must have an
+// ID for each member. The members of a GeometryCollection are written in
+// the same format, with headers of their own.
+//
+// It returns an error wrapping ErrUnsupported if a precision is out of
+// range, the IDs do not match the geometry, a coordinate is not finite
+// or too large to round, or the geometry has an empty point other than
+// an empty Point on its own.
+func (f Format) Marshal(g geom.Geometry, layout geom.Layout, ids []int64) ([]byte, error) {
+	if f.Precision < -8 || f.Precision > 7 || f.ZPrecision < 0 || f.ZPrecision > 7 || f.MPrecision < 0 || f.MPrecision > 7 {
+		return nil, fmt.Errorf("%w: precision out of range in %+v", ErrUnsupported, f)
+	}
+	scales := []float64{math.Pow10(f.Precision), math.Pow10(f.Precision)}
+	if layout.HasZ() {
+		scales = append(scales, math.Pow10(f.ZPrecision))
+	}
+	if layout.HasM() {
+		scales = append(scales, math.Pow10(f.MPrecision))
+	}
+	w := writer{Format: f, layout: layout, scales: scales}
+	return w.geometry(nil, g, ids)
+}
+
+// writer writes geometries in a format.
+type writer struct {
+	Format
+	layout geom.Layout
+	// scales are the factors by which the coordinates of the layout, X,
+	// Y and then Z and M if it has them, are multiplied before rounding.
+	scales []float64
+	// last holds the rounded coordinates of the previous point.
+	last [4]int64
+}
+
+// dims returns the number of coordinates of a position.
+func (w *writer) dims() int {
+	return len(w.scales)
+}
+
+// round returns the rounded coordinates of a point of the layout.
+func (w *writer) round(p geom.Point) ([4]int64, error) {
+	cs := []float64{p.X, p.Y}
+	if w.layout.HasZ() {
+		cs = append(cs, p.Z)
+	}
+	if w.layout.HasM() {
+		cs = append(cs, p.M)
+	}
+	var r [4]int64
+	for i, c := range cs {
+		v := math.Round(c * w.scales[i])
+		if !(math.Abs(v) < 1<<62) {
+			return r, fmt.Errorf("%w: coordinate %g cannot be rounded to an integer", ErrUnsupported, c)
+		}
+		r[i] = int64(v)
+	}
+	return r, nil
+}
+
+// geometry appends the TWKB of a geometry to b.
+func (w *writer) geometry(b []byte, g geom.Geometry, ids []int64) ([]byte, error) {
+	// n is the number of members of a multipart geometry or collection,
+	// or -1 for other geometries, and size the number of elements of any
+	// geometry, which is empty if it has none.
+	n, size := -1, 0
+	switch g := g.(type) {
+	case geom.Point:
+		if !g.IsEmpty() {
+			size = 1
+		}
+	case geom.LineString:
+		size = len(g)
+	case geom.Polygon:
+		size = len(g)
+	case geom.MultiPoint:
+		n = len(g)
+	case geom.MultiLineString:
+		n = len(g)
+	case geom.MultiPolygon:
+		n = len(g)
+	case geom.GeometryCollection:
+		n = len(g)
+	default:
+		return nil, fmt.Errorf("%w: geometry of type %T", ErrUnsupported, g)
+	}
+	if n >= 0 {
+		size = n
+	}
+	if ids != nil && len(ids) != n {
+		if n < 0 {
+			return nil, fmt.Errorf("%w: IDs for a %v", ErrUnsupported, g.Type())
+		}
+		return nil, fmt.Errorf("%w: %d IDs for %d members", ErrUnsupported, len(ids), n)
+	}
+	meta := byte(0)
+	if w.layout != geom.XY {
+		meta |= hasExtended
+	}
+	if w.Size {
+		meta |= hasSize
+	}
+	var rest []byte // The bounding box, ID list and body
+	if size == 0 {
+		meta |= isEmpty
+	} else {
+		if w.BBox {
+			meta |= hasBBox
+			bbox, err := w.bbox(g)
+			if err != nil {
+				return nil, err
+			}
+			rest = bbox
+		}
+		if ids != nil {
+			meta |= hasIDs
+		}
+		if n >= 0 {
+			rest = binary.AppendUvarint(rest, uint64(n))
+			for _, id := range ids {
+				rest = binary.AppendVarint(rest, id)
+			}
+		}
+		var err error
+		if rest, err = w.body(rest, g); err != nil {
+			return nil, err
+		}
+	}
+	b = append(b, byte(g.Type())|byte(zigzag(w.Precision))<<4, meta)
+	if meta&hasExtended != 0 {
+		ext := byte(0)
+		if w.layout.HasZ() {
+			ext |= 1 | byte(w.ZPrecision)<<2
+		}
+		if w.layout.HasM() {
+			ext |= 2 | byte(w.MPrecision)<<5
+		}
+		b = append(b, ext)
+	}
+	if w.Size {
+		b = binary.AppendUvarint(b, uint64(len(rest)))
+	}
+	return append(b, rest...), nil
+}
+
+// zigzag returns the zigzag encoding of a signed integer.
+func zigzag(v int) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// bbox returns the bounding box of a geometry in rounded coordinates,
+// the minimum and the difference to the maximum of each.
+func (w *writer) bbox(g geom.Geometry) ([]byte, error) {
+	var lo, hi [4]int64
+	first := true
+	for p := range geom.Points(g) {
+		r, err := w.round(p)
+		if err != nil {
+			return nil, err
+		}
+		for i := range w.dims() {
+			if first || r[i] < lo[i] {
+				lo[i] = r[i]
+			}
+			if first || r[i] > hi[i] {
+				hi[i] = r[i]
+			}
+		}
+		first = false
+	}
+	var b []byte
+	for i := range w.dims() {
+		b = binary.AppendVarint(b, lo[i])
+		b = binary.AppendVarint(b, hi[i]-lo[i])
+	}
+	return b, nil
+}
+
+// body appends the coordinates and counts of a geometry which is not
+// empty to b, or the members of a collection, with the deltas of the
+// coordinates running from the first point to the last.
+func (w *writer) body(b []byte, g geom.Geometry) ([]byte, error) {
+	w.last = [4]int64{}
+	var err error
+	switch g := g.(type) {
+	case geom.Point:
+		return w.position(b, g)
+	case geom.LineString:
+		return w.positions(b, g)
+	case geom.Polygon:
+		return w.polygon(b, g)
+	case geom.MultiPoint:
+		for _, p := range g {
+			if b, err = w.position(b, p); err != nil {
+				return nil, err
+			}
+		}
+	case geom.MultiLineString:
+		for _, l := range g {
+			if b, err = w.positions(b, l); err != nil {
+				return nil, err
+			}
+		}
+	case geom.MultiPolygon:
+		for _, p := range g {
+			if b, err = w.polygon(b, p); err != nil {
+				return nil, err
+			}
+		}
+	case geom.GeometryCollection:
+		for i, m := range g {
+			if m == nil {
+				return nil, fmt.Errorf("%w: member %d of a GeometryCollection is nil", ErrUnsupported, i)
+			}
+			if b, err = w.geometry(b, m, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// polygon appends the count of rings of a polygon and the rings.
+func (w *writer) polygon(b []byte, p geom.Polygon) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(p)))
+	for _, r := range p {
+		var err error
+		if b, err = w.positions(b, r); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// positions appends a count of positions and the positions.
+func (w *writer) positions(b []byte, ps []geom.Point) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(ps)))
+	for _, p := range ps {
+		var err error
+		if b, err = w.position(b, p); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// position appends the deltas of the rounded coordinates of a point.
+func (w *writer) position(b []byte, p geom.Point) ([]byte, error) {
+	if p.IsEmpty() {
+		return nil, fmt.Errorf("%w: empty point which is not a Point on its own", ErrUnsupported)
+	}
+	r, err := w.round(p)
+	if err != nil {
+		return nil, err
+	}
+	for i := range w.dims() {
+		b = binary.AppendVarint(b, r[i]-w.last[i])
+	}
+	w.last = r
+	return b, nil
+}
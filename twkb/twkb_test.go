@@ -0,0 +1,193 @@
+package twkb
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func pts(c ...float64) []geom.Point {
+	ps := make([]geom.Point, len(c)/2)
+	for i := range ps {
+		ps[i] = geom.Point{X: c[2*i], Y: c[2*i+1]}
+	}
+	return ps
+}
+
+// unhex decodes hexadecimal with spaces between the fields for reading.
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// The TWKB of geometries, as written by PostGIS ST_AsTWKB. Each geometry
+// reads back as it is, its coordinates already rounded.
+var marshalTests = []struct {
+	f      Format
+	g      geom.Geometry
+	layout geom.Layout
+	ids    []int64
+	twkb   string
+}{
+	{Format{}, geom.Point{X: 1, Y: 2}, geom.XY, nil, "01 00 02 04"},
+	{Format{}, geom.LineString(pts(1, 1, 5, 5)), geom.XY, nil, "02 00 02 02 02 08 08"},
+	{Format{Precision: 2}, geom.Point{X: 1.23, Y: -5.68}, geom.XY, nil, "41 00 F601 EF08"},
+	{Format{Precision: -1}, geom.Point{X: 1230, Y: 5680}, geom.XY, nil, "11 00 F601 F008"},
+	{Format{}, geom.EmptyPoint(), geom.XY, nil, "01 10"},
+	{Format{}, geom.LineString(nil), geom.XY, nil, "02 10"},
+	{Format{Size: true}, geom.Point{X: 1, Y: 2}, geom.XY, nil, "01 02 02 02 04"},
+	{Format{Size: true}, geom.MultiPolygon(nil), geom.XY, nil, "06 12 00"},
+	{Format{BBox: true}, geom.LineString(pts(1, 1, 5, 5)), geom.XY, nil, "02 01 02 08 02 08 02 02 02 08 08"},
+	{Format{ZPrecision: 1}, geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, nil, "01 08 05 02 04 3C"},
+	{Format{MPrecision: 2}, geom.Point{X: 1, Y: 2, M: -1}, geom.XYM, nil, "01 08 42 02 04 C701"},
+	{Format{ZPrecision: 7, MPrecision: 7}, geom.Point{X: 1, Y: 2}, geom.XYZM, nil, "01 08 FF 02 04 00 00"},
+	{Format{}, geom.Polygon{pts(0, 0, 2, 0, 0, 2, 0, 0)}, geom.XY, nil, "03 00 01 04 00 00 04 00 03 04 00 03"},
+	{Format{}, geom.MultiPoint(pts(1, 1, 3, 3)), geom.XY, []int64{2, 4}, "04 04 02 04 08 02 02 04 04"},
+	{Format{}, geom.MultiLineString{pts(1, 1, 2, 2), pts(3, 3, 4, 4)}, geom.XY, nil, "05 00 02 02 02 02 02 02 02 02 02 02 02"},
+	{Format{}, geom.MultiPolygon{{pts(0, 0, 1, 0, 0, 0)}}, geom.XY, []int64{-1}, "06 04 01 01 01 03 00 00 02 00 01 00"},
+	{Format{Size: true}, geom.GeometryCollection{geom.Point{X: 1, Y: 2}, geom.LineString(nil)}, geom.XY, []int64{7, 8},
+		"07 06 0B 02 0E 10 01 02 02 02 04 02 12 00"},
+}
+
+func TestMarshal(t *testing.T) {
+	for _, tt := range marshalTests {
+		b, err := tt.f.Marshal(tt.g, tt.layout, tt.ids)
+		if want := unhex(tt.twkb); err != nil || !reflect.DeepEqual(b, want) {
+			t.Errorf("%+v.Marshal(%v, %v, %v) = %X, %v, want %X", tt.f, tt.g, tt.layout, tt.ids, b, err, want)
+			continue
+		}
+		g, layout, ids, err := Unmarshal(b)
+		if tt.g.Type() == geom.TypePoint && tt.g.IsEmpty() {
+			if err != nil || !g.IsEmpty() || g.Type() != geom.TypePoint {
+				t.Errorf("Unmarshal(%X) = %v, %v, want an empty point", b, g, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != tt.layout || !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("Unmarshal(%X) = %v, %v, %v, %v, want %v, %v, %v", b, g, layout, ids, err, tt.g, tt.layout, tt.ids)
+		}
+	}
+}
+
+func TestMarshalRounds(t *testing.T) {
+	// Coordinates are rounded to the precision, and read back as the
+	// nearest float64 to the rounded decimal.
+	tests := []struct {
+		f    Format
+		p, q geom.Point
+	}{
+		{Format{Precision: 5}, geom.Point{X: -122.4194155, Y: 37.7749295}, geom.Point{X: -122.41942, Y: 37.77493}},
+		{Format{Precision: 7}, geom.Point{X: 0.1 + 0.2, Y: 1e-8}, geom.Point{X: 0.3, Y: 0}},
+		{Format{Precision: -3}, geom.Point{X: 1499, Y: -2500.1}, geom.Point{X: 1000, Y: -3000}},
+		{Format{}, geom.Point{X: 0.5, Y: -0.5}, geom.Point{X: 1, Y: -1}},
+	}
+	for _, tt := range tests {
+		b, err := tt.f.Marshal(tt.p, geom.XY, nil)
+		if err != nil {
+			t.Fatalf("%+v.Marshal(%v) error = %v", tt.f, tt.p, err)
+		}
+		if g, _, _, err := Unmarshal(b); err != nil || g != tt.q {
+			t.Errorf("Unmarshal(%+v.Marshal(%v)) = %v, %v, want %v", tt.f, tt.p, g, err, tt.q)
+		}
+	}
+}
+
+type otherGeometry struct{ geom.Point }
+
+func TestMarshalErrors(t *testing.T) {
+	tests := []struct {
+		f   Format
+		g   geom.Geometry
+		ids []int64
+	}{
+		{Format{Precision: 8}, geom.Point{}, nil},
+		{Format{Precision: -9}, geom.Point{}, nil},
+		{Format{ZPrecision: -1}, geom.Point{}, nil},
+		{Format{MPrecision: 8}, geom.Point{}, nil},
+		{Format{}, nil, nil},
+		{Format{}, otherGeometry{}, nil},
+		{Format{}, geom.Point{X: 1}, []int64{1}},
+		{Format{}, geom.MultiPoint(pts(1, 1)), []int64{1, 2}},
+		{Format{}, geom.MultiPoint(pts(1, 1)), []int64{}},
+		{Format{}, geom.Point{X: math.Inf(1)}, nil},
+		{Format{Precision: 7}, geom.Point{X: 1e12}, nil},
+		{Format{BBox: true}, geom.LineString{{X: 1e300}}, nil},
+		{Format{}, geom.MultiPoint{geom.EmptyPoint()}, nil},
+		{Format{}, geom.LineString{{}, geom.EmptyPoint()}, nil},
+		{Format{}, geom.GeometryCollection{geom.Point{}, nil}, nil},
+		{Format{}, geom.GeometryCollection{otherGeometry{}}, nil},
+	}
+	for _, tt := range tests {
+		if b, err := tt.f.Marshal(tt.g, geom.XY, tt.ids); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%+v.Marshal(%v, %v) = %X, %v, want %v", tt.f, tt.g, tt.ids, b, err, ErrUnsupported)
+		}
+	}
+}
+
+func TestMarshalRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(84))
+	for range 300 {
+		f := Format{Precision: r.Intn(16) - 8, ZPrecision: r.Intn(8), MPrecision: r.Intn(8), Size: r.Intn(2) == 0, BBox: r.Intn(2) == 0}
+		layout := geom.Layout(r.Intn(4))
+		point := func() geom.Point {
+			p := geom.Point{X: r.NormFloat64() * 1000, Y: r.NormFloat64() * 1000}
+			if layout.HasZ() {
+				p.Z = r.NormFloat64() * 100
+			}
+			if layout.HasM() {
+				p.M = r.NormFloat64() * 100
+			}
+			return p
+		}
+		line := func() []geom.Point {
+			ps := make([]geom.Point, 1+r.Intn(5))
+			for i := range ps {
+				ps[i] = point()
+			}
+			return ps
+		}
+		g := geom.GeometryCollection{
+			point(), geom.LineString(line()), geom.Polygon{line(), line()},
+			geom.MultiPoint(line()), geom.MultiLineString{line()}, geom.MultiPolygon{{line()}}, geom.LineString(nil),
+		}
+		b, err := f.Marshal(g, layout, nil)
+		if err != nil {
+			t.Fatalf("%+v.Marshal(%v) error = %v", f, g, err)
+		}
+		got, l, _, err := Unmarshal(b)
+		if err != nil || l != layout {
+			t.Fatalf("Unmarshal(%+v.Marshal(%v)) = %v, %v, %v", f, g, got, l, err)
+		}
+		// Each coordinate is within half a unit of the precision.
+		tol := []float64{0.5 * math.Pow10(-f.Precision), 0.5 * math.Pow10(-f.ZPrecision), 0.5 * math.Pow10(-f.MPrecision)}
+		var want []geom.Point
+		for p := range geom.Points(g) {
+			want = append(want, p)
+		}
+		i := 0
+		for q := range geom.Points(got) {
+			p := want[i]
+			if math.Abs(p.X-q.X) > tol[0]*(1+1e-9) || math.Abs(p.Y-q.Y) > tol[0]*(1+1e-9) ||
+				math.Abs(p.Z-q.Z) > tol[1]*(1+1e-9) || math.Abs(p.M-q.M) > tol[2]*(1+1e-9) {
+				t.Fatalf("%+v: point %v read back as %v", f, p, q)
+			}
+			i++
+		}
+		if i != len(want) {
+			t.Fatalf("%+v: %d points read back, want %d", f, i, len(want))
+		}
+		// Writing what was read gives the same bytes.
+		if c, err := f.Marshal(got, layout, nil); err != nil || !reflect.DeepEqual(c, b) {
+			t.Fatalf("%+v.Marshal(%v) = %X, %v, want %X", f, got, c, err, b)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package utm converts between latitude and longitude on the WGS84
+// ellipsoid and Universal Transverse Mercator (UTM) coordinates.
+//
+// UTM divides the Earth between 80 degrees south and 84 degrees north
+// into 60 zones, each 6 degrees of longitude wide, and projects each
+// zone with a transverse Mercator projection centered on its central
+// meridian. An easting of 500 km is assigned to the central meridian
+// and northings are measured from the equator in the northern hemisphere
+// and from 10000 km south of it in the southern hemisphere, so that all
+// coordinates are positive.
+//
+// The projection is computed by proj.TransverseMercator, which is
+// accurate to well under a millimeter everywhere within a zone and
+// remains accurate to a few millimeters several zones away from the
+// central meridian.
+package utm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gogama/geospat/geodesy"
+	"github.com/gogama/geospat/proj"
+)
+
+const (
+	// MinLat and MaxLat bound the latitudes, in degrees, covered by the
+	// UTM system. The polar regions beyond are covered by the Universal
+	// Polar Stereographic system instead.
+	MinLat = -80.0
+	MaxLat = 84.0
+
+	// ScaleFactor is the scale factor on the central meridian of each
+	// zone.
+	ScaleFactor = 0.9996
+
+	// FalseEasting is the easting of the central meridian of each zone.
+	FalseEasting = 500000.0
+
+	// FalseNorthing is the northing of the equator in the southern
+	// hemisphere. In the northern hemisphere the equator has northing 0.
+	FalseNorthing = 10000000.0
+)
+
+var (
+	// ErrOutOfRange is returned, wrapped, by FromLatLng when a point lies
+	// outside the latitudes covered by UTM.
+	ErrOutOfRange = errors.New("utm: latitude out of range")
+
+	// ErrZone is returned, wrapped, when a zone number is not in the
+	// range [1, 60].
+	ErrZone = errors.New("utm: invalid zone")
+)
+
+// Coord is a position in UTM coordinates: a zone number in the range
+// [1, 60], the hemisphere, which determines the origin of the northing,
+// and the easting and northing in meters.
+type Coord struct {
+	Zone              int
+	North             bool
+	Easting, Northing float64
+}
+
+// String returns the coordinates in the form "38N 444140.545 3684706.356",
+// with the hemisphere given as N or S and the easting and northing
+// rounded to millimeters.
+func (c Coord) String() string {
+	h := 'S'
+	if c.North {
+		h = 'N'
+	}
+	return fmt.Sprintf("%d%c %.3f %.3f", c.Zone, h, c.Easting, c.Northing)
+}
+
+// FromLatLng returns the UTM coordinates of the point with the given
+// latitude and longitude, in degrees, in the zone containing the point
+// as chosen by Zone. Longitudes are wrapped. It returns an error
+// wrapping ErrOutOfRange if the latitude is not in the range
+// [MinLat, MaxLat].
+//
+// The complementary method Coord.LatLng performs the inverse mapping.
+func FromLatLng(lat, lng float64) (Coord, error) {
+	if !(lat >= MinLat && lat <= MaxLat) {
+		return Coord{}, fmt.Errorf("%w: %g is not in [%g, %g]", ErrOutOfRange, lat, MinLat, MaxLat)
+	}
+	return project(lat, lng, Zone(lat, lng)), nil
+}
+
+// FromLatLngZone returns the UTM coordinates of the point with the given
+// latitude and longitude, in degrees, in an explicitly chosen zone. This
+// is useful to express points just outside a zone in the coordinates of
+// that zone, for example so that a survey which straddles a zone
+// boundary can use a single zone. The accuracy of the projection
+// degrades slowly with distance from the zone. Points are not
+// restricted to the latitudes covered by UTM, but the projection of the
+// poles is not useful. It returns an error wrapping ErrZone if zone is
+// not in the range [1, 60].
+func FromLatLngZone(lat, lng float64, zone int) (Coord, error) {
+	if zone < 1 || zone > 60 {
+		return Coord{}, fmt.Errorf("%w: %d", ErrZone, zone)
+	}
+	return project(max(-90, min(90, lat)), lng, zone), nil
+}
+
+// Zone returns the number of the UTM zone containing the point with the
+// given latitude and longitude, in degrees. Longitudes are wrapped.
+// Zones are normally 6 degrees wide starting from 180 degrees west, but
+// zone 32 is widened to cover south-western Norway between 56 and 64
+// degrees north, and north of 72 degrees the zones 31, 33, 35 and 37 are
+// widened to cover Svalbard, leaving no room for the even zones between
+// them.
+func Zone(lat, lng float64) int {
+	lng = wrapLng(lng)
+	switch {
+	case lat >= 56 && lat < 64 && lng >= 3 && lng < 12:
+		return 32
+	case lat >= 72 && lng >= 0 && lng < 42:
+		switch {
+		case lng < 9:
+			return 31
+		case lng < 21:
+			return 33
+		case lng < 33:
+			return 35
+		default:
+			return 37
+		}
+	}
+	return min(int((lng+180)/6)+1, 60)
+}
+
+// CentralMeridian returns the longitude, in degrees, of the central
+// meridian of a zone.
+func CentralMeridian(zone int) float64 {
+	return float64(6*zone - 183)
+}
+
+// wrapLng wraps a longitude into the range [-180, 180).
+func wrapLng(lng float64) float64 {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return lng - 180
+}
+
+// projection returns the transverse Mercator projection of a zone, in
+// the northern hemisphere or the southern.
+func projection(zone int, north bool) proj.TransverseMercator {
+	t := proj.TransverseMercator{
+		Ellipsoid:       geodesy.WGS84,
+		CentralMeridian: CentralMeridian(zone),
+		ScaleFactor:     ScaleFactor,
+		FalseEasting:    FalseEasting,
+	}
+	if !north {
+		t.FalseNorthing = FalseNorthing
+	}
+	return t
+}
+
+// project returns the coordinates of a point in the given zone, in the
+// hemisphere of the point.
+func project(lat, lng float64, zone int) Coord {
+	north := lat >= 0
+	e, n := projection(zone, north).Forward(geodesy.LatLng{Lat: lat, Lng: lng})
+	return Coord{Zone: zone, North: north, Easting: e, Northing: n}
+}
+
+// LatLng returns the latitude and longitude, in degrees, of the point
+// with the UTM coordinates c. The zone of c must be in the range
+// [1, 60]. The longitude is wrapped into the range [-180, 180).
+//
+// The complementary function FromLatLng performs the inverse mapping.
+func (c Coord) LatLng() (lat, lng float64) {
+	p := projection(c.Zone, c.North).Inverse(c.Easting, c.Northing)
+	return p.Lat, p.Lng
+}
@@ -0,0 +1,93 @@
+package utm
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFromLatLng(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		want     string
+	}{
+		{33.3, 44.4, "38N 444140.545 3684706.356"},
+		{-33.3, 44.4, "38S 444140.545 6315293.644"},
+		{0, 3, "31N 500000.000 0.000"},
+		{0, -177, "1N 500000.000 0.000"},
+		{84, 180, "1N 465005.345 9329005.182"},
+	}
+	for _, tt := range tests {
+		c, err := FromLatLng(tt.lat, tt.lng)
+		if err != nil || c.String() != tt.want {
+			t.Errorf("FromLatLng(%v, %v) = (%v, %v), want %s", tt.lat, tt.lng, c, err, tt.want)
+		}
+	}
+	for _, lat := range []float64{84.001, -80.001, 90, math.NaN()} {
+		if _, err := FromLatLng(lat, 0); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("FromLatLng(%v, 0) error = %v, want %v", lat, err, ErrOutOfRange)
+		}
+	}
+}
+
+func TestFromLatLngZone(t *testing.T) {
+	c, err := FromLatLngZone(33.3, 44.4, 37)
+	if err != nil || c.Zone != 37 || !c.North || c.Easting < 990e3 || c.Easting > 1010e3 {
+		t.Errorf("FromLatLngZone(33.3, 44.4, 37) = (%v, %v)", c, err)
+	}
+	if lat, lng := c.LatLng(); math.Abs(lat-33.3) > 1e-9 || math.Abs(lng-44.4) > 1e-9 {
+		t.Errorf("FromLatLngZone(33.3, 44.4, 37).LatLng() = (%v, %v)", lat, lng)
+	}
+	for _, zone := range []int{0, -1, 61} {
+		if _, err := FromLatLngZone(0, 0, zone); !errors.Is(err, ErrZone) {
+			t.Errorf("FromLatLngZone(0, 0, %d) error = %v, want %v", zone, err, ErrZone)
+		}
+	}
+}
+
+func TestZone(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		want     int
+	}{
+		{0, -180, 1},
+		{0, 180, 1},
+		{0, 179.999, 60},
+		{0, 0, 31},
+		{55.9, 5, 31},
+		{60, 5, 32},
+		{60, 2.9, 31},
+		{72, 5, 31},
+		{78, 15, 33},
+		{78, 25, 35},
+		{78, 45, 38},
+		{71.9, 15, 33},
+		{71.9, 10, 32},
+	}
+	for _, tt := range tests {
+		if z := Zone(tt.lat, tt.lng); z != tt.want {
+			t.Errorf("Zone(%v, %v) = %d, want %d", tt.lat, tt.lng, z, tt.want)
+		}
+	}
+	if m := CentralMeridian(1); m != -177 {
+		t.Errorf("CentralMeridian(1) = %v, want -177", m)
+	}
+	if m := CentralMeridian(60); m != 177 {
+		t.Errorf("CentralMeridian(60) = %v, want 177", m)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(34))
+	for range 100000 {
+		lat, lng := r.Float64()*(MaxLat-MinLat)+MinLat, r.Float64()*360-180
+		c, err := FromLatLng(lat, lng)
+		if err != nil || c.North != (lat >= 0) || c.Easting < 100e3 || c.Easting > 900e3 {
+			t.Fatalf("FromLatLng(%v, %v) = (%v, %v)", lat, lng, c, err)
+		}
+		if la, lo := c.LatLng(); math.Abs(la-lat) > 1e-10 || math.Abs(lo-lng) > 1e-10 {
+			t.Fatalf("FromLatLng(%v, %v).LatLng() = (%v, %v)", lat, lng, la, lo)
+		}
+	}
+}
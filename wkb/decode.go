@@ -0,0 +1,252 @@
+package wkb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Unmarshal returns the geometry of WKB or EWKB data, its layout, and
+// the SRID of its coordinate system, or 0 if it has none. Either byte
+// order may be used, and may differ between the members of a multipart
+// geometry or collection. The type codes of ISO WKB and the flags of
+// EWKB, which are those of OGC WKB 1.1 for Z, are all accepted, but
+// every member must have the same layout. A point whose X or Y is NaN is
+// read as an empty point.
+//
+// It returns an error wrapping ErrInvalid, telling what is wrong and at
+// which byte offset, if the data is not a single valid geometry.
+func Unmarshal(data []byte) (geom.Geometry, geom.Layout, int, error) {
+	r := reader{b: data, layout: -1}
+	g, srid, err := r.geometry(0)
+	if err == nil && r.pos < len(r.b) {
+		err = r.errorf("%d bytes after the geometry", len(r.b)-r.pos)
+	}
+	if err != nil {
+		return nil, geom.XY, 0, err
+	}
+	return g, r.layout, srid, nil
+}
+
+// reader reads WKB.
+type reader struct {
+	b     []byte
+	pos   int
+	order binary.ByteOrder
+	// layout is the layout of the geometry, or -1 until it is known.
+	layout geom.Layout
+}
+
+// errorf returns an error wrapping ErrInvalid, telling what is wrong at
+// the position.
+func (r *reader) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalid, fmt.Sprintf(format, args...), r.pos)
+}
+
+// uint32 reads an unsigned integer.
+func (r *reader) uint32() (uint32, error) {
+	if len(r.b)-r.pos < 4 {
+		return 0, r.errorf("unexpected end of data")
+	}
+	v := r.order.Uint32(r.b[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// count reads a count of elements, each of which takes at least size
+// bytes, so that a count too large for the data is found before
+// anything is allocated.
+func (r *reader) count(size int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(size) > uint64(len(r.b)-r.pos) {
+		r.pos -= 4
+		return 0, r.errorf("count %d exceeds the data", n)
+	}
+	return int(n), nil
+}
+
+// dims returns the number of coordinates of a position.
+func (r *reader) dims() int {
+	n := 2
+	if r.layout.HasZ() {
+		n++
+	}
+	if r.layout.HasM() {
+		n++
+	}
+	return n
+}
+
+// geometry reads a geometry and its SRID, which must be of type want if
+// it is not zero.
+func (r *reader) geometry(want geom.Type) (geom.Geometry, int, error) {
+	start := r.pos
+	if r.pos >= len(r.b) {
+		return nil, 0, r.errorf("unexpected end of data")
+	}
+	switch r.b[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return nil, 0, r.errorf("invalid byte order %d", r.b[r.pos])
+	}
+	r.pos++
+	code, err := r.uint32()
+	if err != nil {
+		return nil, 0, err
+	}
+	z, m := code&ewkbZ != 0, code&ewkbM != 0
+	base := code &^ (ewkbZ | ewkbM | ewkbSRID)
+	switch base / 1000 {
+	case 0:
+	case 1:
+		z = true
+	case 2:
+		m = true
+	case 3:
+		z, m = true, true
+	default:
+		r.pos = start + 1
+		return nil, 0, r.errorf("invalid type code %#x", code)
+	}
+	t := geom.Type(base % 1000)
+	if t < geom.TypePoint || t > geom.TypeGeometryCollection {
+		r.pos = start + 1
+		return nil, 0, r.errorf("invalid type code %#x", code)
+	}
+	if want != 0 && t != want {
+		r.pos = start
+		return nil, 0, r.errorf("%v where %v was expected", t, want)
+	}
+	layout := geom.XY
+	if z {
+		layout = geom.XYZ
+	}
+	if m {
+		layout |= geom.XYM // XYM, or XYZM with Z
+	}
+	if r.layout >= 0 && layout != r.layout {
+		r.pos = start
+		return nil, 0, r.errorf("%v of layout %v in a geometry of layout %v", t, layout, r.layout)
+	}
+	r.layout = layout
+	srid := 0
+	if code&ewkbSRID != 0 {
+		s, err := r.uint32()
+		if err != nil {
+			return nil, 0, err
+		}
+		srid = int(int32(s))
+	}
+	g, err := r.body(t)
+	return g, srid, err
+}
+
+// body reads the body of a geometry of a type, after its header.
+func (r *reader) body(t geom.Type) (geom.Geometry, error) {
+	switch t {
+	case geom.TypePoint:
+		p, err := r.position()
+		if p.IsEmpty() {
+			p = geom.EmptyPoint()
+		}
+		return p, err
+	case geom.TypeLineString:
+		return r.positions()
+	case geom.TypePolygon:
+		return r.polygon()
+	}
+	n, err := r.count(9)
+	if err != nil {
+		return nil, err
+	}
+	member := map[geom.Type]geom.Type{
+		geom.TypeMultiPoint:      geom.TypePoint,
+		geom.TypeMultiLineString: geom.TypeLineString,
+		geom.TypeMultiPolygon:    geom.TypePolygon,
+	}[t]
+	members := make([]geom.Geometry, n)
+	for i := range members {
+		if members[i], _, err = r.geometry(member); err != nil {
+			return nil, err
+		}
+	}
+	switch t {
+	case geom.TypeMultiPoint:
+		mp := make(geom.MultiPoint, n)
+		for i, g := range members {
+			mp[i] = g.(geom.Point)
+		}
+		return mp, nil
+	case geom.TypeMultiLineString:
+		ml := make(geom.MultiLineString, n)
+		for i, g := range members {
+			ml[i] = g.(geom.LineString)
+		}
+		return ml, nil
+	case geom.TypeMultiPolygon:
+		mp := make(geom.MultiPolygon, n)
+		for i, g := range members {
+			mp[i] = g.(geom.Polygon)
+		}
+		return mp, nil
+	}
+	return geom.GeometryCollection(members), nil
+}
+
+// polygon reads a count of rings and the rings.
+func (r *reader) polygon() (geom.Polygon, error) {
+	n, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+	p := make(geom.Polygon, n)
+	for i := range p {
+		l, err := r.positions()
+		if err != nil {
+			return nil, err
+		}
+		p[i] = geom.Ring(l)
+	}
+	return p, nil
+}
+
+// positions reads a count of positions and the positions.
+func (r *reader) positions() (geom.LineString, error) {
+	n, err := r.count(8 * r.dims())
+	if err != nil {
+		return nil, err
+	}
+	l := make(geom.LineString, n)
+	for i := range l {
+		l[i], _ = r.position()
+	}
+	return l, nil
+}
+
+// position reads the coordinates of a point.
+func (r *reader) position() (geom.Point, error) {
+	if len(r.b)-r.pos < 8*r.dims() {
+		return geom.Point{}, r.errorf("unexpected end of data")
+	}
+	float := func() float64 {
+		v := math.Float64frombits(r.order.Uint64(r.b[r.pos:]))
+		r.pos += 8
+		return v
+	}
+	p := geom.Point{X: float(), Y: float()}
+	if r.layout.HasZ() {
+		p.Z = float()
+	}
+	if r.layout.HasM() {
+		p.M = float()
+	}
+	return p, nil
+}
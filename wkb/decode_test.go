@@ -0,0 +1,92 @@
+package wkb
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		wkb    string
+		g      geom.Geometry
+		layout geom.Layout
+		srid   int
+	}{
+		// The members of a collection may be of either byte order.
+		{"00 00000004 00000002 01 01000000 000000000000F03F 0000000000000040 00 00000001 3FF0000000000000 4000000000000000",
+			geom.MultiPoint(pts(1, 2, 1, 2)), geom.XY, 0},
+		// The Z flag of OGC WKB 1.1, which EWKB shares.
+		{"00 80000001 3FF0000000000000 4000000000000000 4008000000000000", geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, 0},
+		// The flags of EWKB on an ISO type code.
+		{"01 E9030080 000000000000F03F 0000000000000040 0000000000000840", geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, 0},
+		// An SRID of a member is allowed, and ignored.
+		{"01 07000000 01000000 01 01000020 E6100000 000000000000F03F 0000000000000040", geom.GeometryCollection{geom.Point{X: 1, Y: 2}}, geom.XY, 0},
+		{"01 01000020 FFFFFFFF 000000000000F03F 0000000000000040", geom.Point{X: 1, Y: 2}, geom.XY, -1},
+		{"01 07000000 01000000 01 07000000 00000000", geom.GeometryCollection{geom.GeometryCollection{}}, geom.XY, 0},
+	}
+	for _, tt := range tests {
+		g, layout, srid, err := Unmarshal(unhex(tt.wkb))
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != tt.layout || srid != tt.srid {
+			t.Errorf("Unmarshal(%s) = %v, %v, %d, %v, want %v, %v, %d", tt.wkb, g, layout, srid, err, tt.g, tt.layout, tt.srid)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		wkb string
+		err string
+	}{
+		{"", "wkb: invalid WKB: unexpected end of data at offset 0"},
+		{"02 01000000", "wkb: invalid WKB: invalid byte order 2 at offset 0"},
+		{"01 010000", "wkb: invalid WKB: unexpected end of data at offset 1"},
+		{"01 01000000 000000000000F03F", "wkb: invalid WKB: unexpected end of data at offset 5"},
+		{"01 08000000", "wkb: invalid WKB: invalid type code 0x8 at offset 1"},
+		{"01 00000000", "wkb: invalid WKB: invalid type code 0x0 at offset 1"},
+		{"01 A10F0000", "wkb: invalid WKB: invalid type code 0xfa1 at offset 1"},
+		{"01 01000020 E610", "wkb: invalid WKB: unexpected end of data at offset 5"},
+		{"01 01000000 000000000000F03F 0000000000000040 00", "wkb: invalid WKB: 1 bytes after the geometry at offset 21"},
+		{"01 02000000 FFFFFFFF", "wkb: invalid WKB: count 4294967295 exceeds the data at offset 5"},
+		{"01 02000000 02000000 000000000000F03F 0000000000000040", "wkb: invalid WKB: count 2 exceeds the data at offset 5"},
+		{"01 03000000 02000000 00000000", "wkb: invalid WKB: count 2 exceeds the data at offset 5"},
+		{"01 03000000 01000000 01000000", "wkb: invalid WKB: count 1 exceeds the data at offset 9"},
+		{"01 04000000 01000000 01 02000000 00000000", "wkb: invalid WKB: LineString where Point was expected at offset 9"},
+		{"01 07000000 01000000 01 E9030000 000000000000F03F 0000000000000040 0000000000000840",
+			"wkb: invalid WKB: Point of layout XYZ in a geometry of layout XY at offset 9"},
+		{"01 07000000 02000000 01 07000000 00000000", "wkb: invalid WKB: count 2 exceeds the data at offset 5"},
+		{"01 07000000 01000000 01 07000000 01000000 01", "wkb: invalid WKB: count 1 exceeds the data at offset 14"},
+	}
+	for _, tt := range tests {
+		g, _, _, err := Unmarshal(unhex(tt.wkb))
+		if !errors.Is(err, ErrInvalid) || err.Error() != tt.err {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %s", tt.wkb, g, err, tt.err)
+		}
+	}
+}
+
+func TestUnmarshalCorrupt(t *testing.T) {
+	// Data with bytes changed or cut off is read, or found invalid,
+	// without a panic.
+	r := rand.New(rand.NewSource(83))
+	for range 20000 {
+		b := unhex(marshalTests[r.Intn(len(marshalTests))].wkb)
+		for range 1 + r.Intn(3) {
+			b[r.Intn(len(b))] = byte(r.Intn(256))
+		}
+		b = b[:r.Intn(len(b)+1)]
+		g, layout, srid, err := Unmarshal(b)
+		if err != nil {
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("Unmarshal(%X) error = %v, want %v", b, err, ErrInvalid)
+			}
+			continue
+		}
+		if _, err := MarshalEWKB(g, layout, srid, le); err != nil {
+			t.Fatalf("MarshalEWKB(Unmarshal(%X)) error = %v", b, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package wkb
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Geometry is a geometry of type G, which may be geom.Geometry for one
+// of any type, in a column of a database, such as a PostGIS geometry. It
+// implements sql.Scanner and driver.Valuer, so that a query scans a
+// column into it and a statement writes it as an argument:
+//
+//	var g wkb.Geometry[geom.Polygon]
+//	err := db.QueryRow("SELECT geom FROM parcels WHERE id = $1", id).Scan(&g)
+//
+// A geometry is read from hex-encoded EWKB, which PostGIS returns for a
+// geometry in the text protocol, or from binary WKB or EWKB, such as
+// that of the binary protocol or of ST_AsBinary, and written as
+// hex-encoded EWKB, which PostGIS reads as it reads the text of a
+// geometry.
+type Geometry[G geom.Geometry] struct {
+	// Geometry is the geometry.
+	Geometry G
+
+	// Layout is the layout of the geometry.
+	Layout geom.Layout
+
+	// SRID is the SRID of the coordinate system of the geometry, or 0 if
+	// it has none.
+	SRID int
+
+	// Valid reports whether the geometry is not NULL.
+	Valid bool
+}
+
+// Scan sets the geometry from the value of a column, a string or []byte
+// of EWKB in hex or []byte of binary EWKB, or nil for NULL. It returns
+// an error wrapping ErrInvalid if the value is not valid EWKB, and an
+// error if it is a geometry of a type other than G.
+func (g *Geometry[G]) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		*g = Geometry[G]{}
+		return nil
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return fmt.Errorf("wkb: cannot scan %T into a geometry", src)
+	}
+	// Binary WKB starts with its byte order, 0 or 1, and hex with a digit.
+	if len(data) > 0 && data[0] > 1 {
+		b, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return fmt.Errorf("%w: hex: %w", ErrInvalid, err)
+		}
+		data = b
+	}
+	v, layout, srid, err := Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	t, ok := v.(G)
+	if !ok {
+		return fmt.Errorf("wkb: cannot scan %v into a geometry of %T", v.Type(), g.Geometry)
+	}
+	*g = Geometry[G]{t, layout, srid, true}
+	return nil
+}
+
+// Value returns the hex-encoded EWKB of the geometry, in little endian
+// byte order, or nil if it is not valid. It returns an error wrapping
+// ErrUnsupported if the geometry cannot be written as WKB.
+func (g Geometry[G]) Value() (driver.Value, error) {
+	if !g.Valid {
+		return nil, nil
+	}
+	b, err := MarshalEWKB(g.Geometry, g.Layout, g.SRID, binary.LittleEndian)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
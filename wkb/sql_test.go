@@ -0,0 +1,112 @@
+package wkb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+var (
+	_ sql.Scanner   = (*Geometry[geom.Polygon])(nil)
+	_ driver.Valuer = Geometry[geom.Geometry]{}
+)
+
+func TestGeometryScan(t *testing.T) {
+	// SELECT 'SRID=4326;POINT(1 2)'::geometry, as PostGIS returns it in
+	// the text and binary protocols.
+	point := Geometry[geom.Point]{geom.Point{X: 1, Y: 2}, geom.XY, 4326, true}
+	tests := []struct {
+		src  any
+		want Geometry[geom.Point]
+	}{
+		{"0101000020E6100000000000000000F03F0000000000000040", point},
+		{[]byte("0101000020e6100000000000000000f03f0000000000000040\n"), point},
+		{unhex("01 01000020 E6100000 000000000000F03F 0000000000000040"), point},
+		{unhex("00 00000001 3FF0000000000000 4000000000000000"), Geometry[geom.Point]{geom.Point{X: 1, Y: 2}, geom.XY, 0, true}},
+		{"01010000A0E6100000000000000000F03F00000000000000400000000000000840", Geometry[geom.Point]{geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, 4326, true}},
+		{nil, Geometry[geom.Point]{}},
+	}
+	for _, tt := range tests {
+		g := Geometry[geom.Point]{geom.Point{X: 5}, geom.XYZ, 3857, true}
+		if err := g.Scan(tt.src); err != nil || !reflect.DeepEqual(g, tt.want) {
+			t.Errorf("Scan(%v) = %+v, %v, want %+v", tt.src, g, err, tt.want)
+		}
+	}
+	// A geometry of any type.
+	var g Geometry[geom.Geometry]
+	ring := pts(0, 0, 1, 0, 0, 1, 0, 0)
+	src := "0103000020E6100000010000000400000000000000000000000000000000000000000000000000F03F00000000000000000000000000000000000000000000F03F00000000000000000000000000000000"
+	if err := g.Scan(src); err != nil || !reflect.DeepEqual(g.Geometry, geom.Polygon{ring}) || g.SRID != 4326 || !g.Valid {
+		t.Errorf("Scan(%s) = %+v, %v, want a polygon", src, g, err)
+	}
+}
+
+func TestGeometryScanErrors(t *testing.T) {
+	tests := []struct {
+		src     any
+		invalid bool
+		msg     string
+	}{
+		{42, false, "wkb: cannot scan int into a geometry"},
+		{"0101000000000000000000F03F000000000000004", true, "wkb: invalid WKB: hex: encoding/hex: odd length hex string"},
+		{"zz", true, "wkb: invalid WKB: hex: encoding/hex: invalid byte: U+007A 'z'"},
+		{"", true, "wkb: invalid WKB: unexpected end of data at offset 0"},
+		{unhex("01 01000000 000000000000F03F"), true, "wkb: invalid WKB: unexpected end of data at offset 5"},
+		{"0102000000000000000", true, "wkb: invalid WKB: hex: encoding/hex: odd length hex string"},
+		{"010200000000000000", false, "wkb: cannot scan LineString into a geometry of geom.Point"},
+	}
+	for _, tt := range tests {
+		var g Geometry[geom.Point]
+		err := g.Scan(tt.src)
+		if err == nil || err.Error() != tt.msg || errors.Is(err, ErrInvalid) != tt.invalid {
+			t.Errorf("Scan(%v) = %v, want error %q", tt.src, err, tt.msg)
+		}
+		if g.Valid {
+			t.Errorf("Scan(%v) set a valid geometry %+v", tt.src, g)
+		}
+	}
+}
+
+func TestGeometryValue(t *testing.T) {
+	tests := []struct {
+		g    Geometry[geom.Geometry]
+		want driver.Value
+	}{
+		{Geometry[geom.Geometry]{geom.Point{X: 1, Y: 2}, geom.XY, 4326, true}, "0101000020E6100000000000000000F03F0000000000000040"},
+		{Geometry[geom.Geometry]{geom.Point{X: 1, Y: 2}, geom.XY, 0, true}, "0101000000000000000000F03F0000000000000040"},
+		{Geometry[geom.Geometry]{geom.Point{X: 1, Y: 2}, geom.XY, 4326, false}, nil},
+		{Geometry[geom.Geometry]{}, nil},
+	}
+	for _, tt := range tests {
+		if v, err := tt.g.Value(); err != nil || v != tt.want {
+			t.Errorf("%+v.Value() = %v, %v, want %v", tt.g, v, err, tt.want)
+		}
+	}
+	g := Geometry[geom.Geometry]{otherGeometry{}, geom.XY, 0, true}
+	if v, err := g.Value(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("%+v.Value() = %v, %v, want %v", g, v, err, ErrUnsupported)
+	}
+}
+
+func TestGeometryRoundTrip(t *testing.T) {
+	// Value writes what Scan reads, in the text protocol and, decoded,
+	// the binary.
+	for _, tt := range marshalTests {
+		g := Geometry[geom.Geometry]{tt.g, tt.layout, tt.srid, true}
+		v, err := g.Value()
+		if err != nil {
+			t.Fatalf("%+v.Value(): %v", g, err)
+		}
+		var got Geometry[geom.Geometry]
+		if err := got.Scan(v); err != nil || !reflect.DeepEqual(got, g) {
+			t.Errorf("Scan(%v) = %+v, %v, want %+v", v, got, err, g)
+		}
+		if err := got.Scan(unhex(v.(string))); err != nil || !reflect.DeepEqual(got, g) {
+			t.Errorf("Scan(%X) = %+v, %v, want %+v", unhex(v.(string)), got, err, g)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package wkb reads and writes geometries as Well-Known Binary, the
+// binary format of the OGC Simple Features standard and ISO 13249-3,
+// and as the Extended WKB of PostGIS, which adds the SRID of the
+// coordinate system.
+//
+// Each geometry is written as a byte giving its byte order, 0 for big
+// endian and 1 for little endian, a 32-bit type code, and its
+// coordinates as 64-bit floats, preceded by counts of points, rings and
+// parts. The type codes of ISO WKB add 1000 for Z, 2000 for M and 3000
+// for ZM to the code of the type, while EWKB sets the high bits
+// 0x80000000 for Z and 0x40000000 for M, and 0x20000000 if a 32-bit SRID
+// follows the type code. Empty points are written with NaN coordinates,
+// as PostGIS and GEOS write them.
+//
+// Geometry reads and writes geometries in the columns of databases
+// through package database/sql, as the hex-encoded EWKB of PostGIS.
+package wkb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gogama/geospat/geom"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, by Unmarshal when data is not
+	// valid WKB.
+	ErrInvalid = errors.New("wkb: invalid WKB")
+
+	// ErrUnsupported is returned, wrapped, when a geometry cannot be
+	// written as WKB.
+	ErrUnsupported = errors.New("wkb: unsupported geometry")
+)
+
+// The flags of EWKB type codes.
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// Marshal returns the ISO WKB of a geometry with the coordinates of the
+// given layout, in the byte order binary.BigEndian, or otherwise little
+// endian. It returns an error wrapping ErrUnsupported if the geometry,
+// or a member of a GeometryCollection, is nil or of a type other than
+// those of package geom.
+func Marshal(g geom.Geometry, layout geom.Layout, order binary.ByteOrder) ([]byte, error) {
+	w := writer{order: appendOrder(order), layout: layout}
+	if err := w.geometry(g, 0); err != nil {
+		return nil, err
+	}
+	return w.b, nil
+}
+
+// MarshalEWKB returns the EWKB of a geometry, as for Marshal, with the
+// SRID of its coordinate system if it is not zero, such as 4326 for
+// WGS84 longitudes and latitudes, written in the outermost geometry
+// only, as PostGIS writes it.
+func MarshalEWKB(g geom.Geometry, layout geom.Layout, srid int, order binary.ByteOrder) ([]byte, error) {
+	w := writer{order: appendOrder(order), layout: layout, ewkb: true}
+	if err := w.geometry(g, srid); err != nil {
+		return nil, err
+	}
+	return w.b, nil
+}
+
+// appendOrder returns binary.BigEndian if it is the byte order, and
+// otherwise binary.LittleEndian.
+func appendOrder(order binary.ByteOrder) binary.AppendByteOrder {
+	if order == binary.BigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// writer accumulates WKB with coordinates of a layout.
+type writer struct {
+	order  binary.AppendByteOrder
+	layout geom.Layout
+	ewkb   bool
+	b      []byte
+}
+
+// uint32 writes an unsigned integer.
+func (w *writer) uint32(v uint32) {
+	w.b = w.order.AppendUint32(w.b, v)
+}
+
+// header writes the byte order and type code of a geometry, and its
+// SRID if it is not zero.
+func (w *writer) header(t geom.Type, srid int) {
+	if w.order == binary.AppendByteOrder(binary.BigEndian) {
+		w.b = append(w.b, 0)
+	} else {
+		w.b = append(w.b, 1)
+	}
+	code := uint32(t)
+	switch {
+	case w.ewkb:
+		if w.layout.HasZ() {
+			code |= ewkbZ
+		}
+		if w.layout.HasM() {
+			code |= ewkbM
+		}
+		if srid != 0 {
+			code |= ewkbSRID
+		}
+	case w.layout.HasZ() && w.layout.HasM():
+		code += 3000
+	case w.layout.HasZ():
+		code += 1000
+	case w.layout.HasM():
+		code += 2000
+	}
+	w.uint32(code)
+	if w.ewkb && srid != 0 {
+		w.uint32(uint32(srid))
+	}
+}
+
+// geometry writes a geometry, with an SRID if it is not zero.
+func (w *writer) geometry(g geom.Geometry, srid int) error {
+	switch g := g.(type) {
+	case geom.Point:
+		w.header(geom.TypePoint, srid)
+		if g.IsEmpty() {
+			// The quiet NaN of PostGIS and GEOS, rather than that of
+			// math.NaN, so that the bytes written are the same.
+			nan := math.Float64frombits(0x7ff8000000000000)
+			g = geom.Point{X: nan, Y: nan, Z: nan, M: nan}
+		}
+		w.position(g)
+	case geom.LineString:
+		w.header(geom.TypeLineString, srid)
+		w.positions(g)
+	case geom.Polygon:
+		w.header(geom.TypePolygon, srid)
+		w.polygon(g)
+	case geom.MultiPoint:
+		w.header(geom.TypeMultiPoint, srid)
+		w.uint32(uint32(len(g)))
+		for _, p := range g {
+			w.geometry(p, 0)
+		}
+	case geom.MultiLineString:
+		w.header(geom.TypeMultiLineString, srid)
+		w.uint32(uint32(len(g)))
+		for _, l := range g {
+			w.geometry(l, 0)
+		}
+	case geom.MultiPolygon:
+		w.header(geom.TypeMultiPolygon, srid)
+		w.uint32(uint32(len(g)))
+		for _, p := range g {
+			w.geometry(p, 0)
+		}
+	case geom.GeometryCollection:
+		w.header(geom.TypeGeometryCollection, srid)
+		w.uint32(uint32(len(g)))
+		for i, m := range g {
+			if err := w.geometry(m, 0); err != nil {
+				if m == nil {
+					return fmt.Errorf("%w: member %d of a GeometryCollection is nil", ErrUnsupported, i)
+				}
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: geometry of type %T", ErrUnsupported, g)
+	}
+	return nil
+}
+
+// polygon writes the rings of a polygon.
+func (w *writer) polygon(p geom.Polygon) {
+	w.uint32(uint32(len(p)))
+	for _, r := range p {
+		w.positions(r)
+	}
+}
+
+// positions writes a count of positions and the positions.
+func (w *writer) positions(ps []geom.Point) {
+	w.uint32(uint32(len(ps)))
+	for _, p := range ps {
+		w.position(p)
+	}
+}
+
+// position writes the coordinates of a point.
+func (w *writer) position(p geom.Point) {
+	w.b = w.order.AppendUint64(w.b, math.Float64bits(p.X))
+	w.b = w.order.AppendUint64(w.b, math.Float64bits(p.Y))
+	if w.layout.HasZ() {
+		w.b = w.order.AppendUint64(w.b, math.Float64bits(p.Z))
+	}
+	if w.layout.HasM() {
+		w.b = w.order.AppendUint64(w.b, math.Float64bits(p.M))
+	}
+}
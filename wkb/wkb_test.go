@@ -0,0 +1,160 @@
+package wkb
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func pts(c ...float64) []geom.Point {
+	ps := make([]geom.Point, len(c)/2)
+	for i := range ps {
+		ps[i] = geom.Point{X: c[2*i], Y: c[2*i+1]}
+	}
+	return ps
+}
+
+// unhex decodes hexadecimal with spaces between the fields for reading.
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var (
+	le = binary.LittleEndian
+	be = binary.BigEndian
+)
+
+// The WKB and EWKB of geometries, as written by PostGIS ST_AsBinary and
+// ST_AsEWKB.
+var marshalTests = []struct {
+	g      geom.Geometry
+	layout geom.Layout
+	order  binary.ByteOrder
+	srid   int
+	wkb    string // ISO WKB, or EWKB if ewkb is set
+	ewkb   bool
+}{
+	{geom.Point{X: 1, Y: 2}, geom.XY, le, 0, "01 01000000 000000000000F03F 0000000000000040", false},
+	{geom.Point{X: 1, Y: 2}, geom.XY, be, 0, "00 00000001 3FF0000000000000 4000000000000000", false},
+	{geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, le, 0, "01 E9030000 000000000000F03F 0000000000000040 0000000000000840", false},
+	{geom.Point{X: 1, Y: 2, M: 4}, geom.XYM, le, 0, "01 D1070000 000000000000F03F 0000000000000040 0000000000001040", false},
+	{geom.Point{X: 1, Y: 2, Z: 3, M: 4}, geom.XYZM, be, 0, "00 00000BB9 3FF0000000000000 4000000000000000 4008000000000000 4010000000000000", false},
+	{geom.Point{X: 1, Y: 2}, geom.XY, le, 4326, "01 01000020 E6100000 000000000000F03F 0000000000000040", true},
+	{geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, le, 0, "01 01000080 000000000000F03F 0000000000000040 0000000000000840", true},
+	{geom.Point{X: 1, Y: 2, Z: 3, M: 4}, geom.XYZM, le, 3857, "01 010000E0 110F0000 000000000000F03F 0000000000000040 0000000000000840 0000000000001040", true},
+	{geom.Point{X: 1, Y: 2, M: 4}, geom.XYM, be, 0, "00 40000001 3FF0000000000000 4000000000000000 4010000000000000", true},
+	{geom.LineString(pts(1, 2, 3, 4)), geom.XY, le, 0,
+		"01 02000000 02000000 000000000000F03F 0000000000000040 0000000000000840 0000000000001040", false},
+	{geom.Polygon{pts(0, 0, 1, 0, 0, 1, 0, 0)}, geom.XY, le, 0,
+		"01 03000000 01000000 04000000 0000000000000000 0000000000000000 000000000000F03F 0000000000000000" +
+			" 0000000000000000 000000000000F03F 0000000000000000 0000000000000000", false},
+	{geom.MultiPoint(pts(1, 2)), geom.XY, le, 4326,
+		"01 04000020 E6100000 01000000 01 01000000 000000000000F03F 0000000000000040", true},
+	{geom.MultiLineString{pts(1, 2, 3, 4)}, geom.XY, be, 0,
+		"00 00000005 00000001 00 00000002 00000002 3FF0000000000000 4000000000000000 4008000000000000 4010000000000000", false},
+	{geom.MultiPolygon{{}}, geom.XYZ, le, 0, "01 EE030000 01000000 01 EB030000 00000000", false},
+	{geom.GeometryCollection{geom.Point{X: 1, Y: 2}, geom.LineString{}}, geom.XY, le, 0,
+		"01 07000000 02000000 01 01000000 000000000000F03F 0000000000000040 01 02000000 00000000", false},
+	{geom.LineString{}, geom.XY, le, 0, "01 02000000 00000000", false},
+	{geom.GeometryCollection{}, geom.XY, le, 4326, "01 07000020 E6100000 00000000", true},
+}
+
+func TestMarshal(t *testing.T) {
+	for _, tt := range marshalTests {
+		var b []byte
+		var err error
+		if tt.ewkb {
+			b, err = MarshalEWKB(tt.g, tt.layout, tt.srid, tt.order)
+		} else {
+			b, err = Marshal(tt.g, tt.layout, tt.order)
+		}
+		if want := unhex(tt.wkb); err != nil || !reflect.DeepEqual(b, want) {
+			t.Errorf("Marshal(%v, %v, %v) = %X, %v, want %X", tt.g, tt.layout, tt.srid, b, err, want)
+		}
+		g, layout, srid, err := Unmarshal(b)
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != tt.layout || srid != tt.srid {
+			t.Errorf("Unmarshal(%X) = %v, %v, %d, %v, want %v, %v, %d", b, g, layout, srid, err, tt.g, tt.layout, tt.srid)
+		}
+	}
+}
+
+func TestMarshalEmptyPoint(t *testing.T) {
+	// PostGIS writes POINT EMPTY with NaN coordinates.
+	b, err := Marshal(geom.EmptyPoint(), geom.XY, le)
+	if want := unhex("01 01000000 000000000000F87F 000000000000F87F"); err != nil || !reflect.DeepEqual(b, want) {
+		t.Errorf("Marshal(POINT EMPTY) = %X, %v, want %X", b, err, want)
+	}
+	// An empty point with other coordinates is written as NaN too.
+	b, _ = Marshal(geom.Point{X: math.NaN(), Y: 2, Z: 3}, geom.XYZ, be)
+	g, layout, _, err := Unmarshal(b)
+	if p, ok := g.(geom.Point); err != nil || !ok || !p.IsEmpty() || !math.IsNaN(p.Z) || layout != geom.XYZ {
+		t.Errorf("Unmarshal(%X) = %v, %v, %v, want an empty point", b, g, layout, err)
+	}
+	// A point with only one NaN coordinate is read as empty.
+	g, _, _, err = Unmarshal(unhex("01 01000000 000000000000F87F 0000000000000040"))
+	if p, ok := g.(geom.Point); err != nil || !ok || !p.IsEmpty() || !math.IsNaN(p.Y) {
+		t.Errorf("Unmarshal(NaN X) = %v, %v, want an empty point", g, err)
+	}
+}
+
+type otherGeometry struct{ geom.Point }
+
+func TestMarshalErrors(t *testing.T) {
+	for _, g := range []geom.Geometry{nil, otherGeometry{}, geom.GeometryCollection{geom.Point{}, nil}, geom.GeometryCollection{otherGeometry{}}} {
+		if b, err := Marshal(g, geom.XY, le); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Marshal(%v) = %X, %v, want %v", g, b, err, ErrUnsupported)
+		}
+		if b, err := MarshalEWKB(g, geom.XY, 4326, le); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("MarshalEWKB(%v) = %X, %v, want %v", g, b, err, ErrUnsupported)
+		}
+	}
+}
+
+func TestMarshalRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(83))
+	for range 200 {
+		layout := geom.Layout(r.Intn(4))
+		point := func() geom.Point {
+			p := geom.Point{X: r.NormFloat64(), Y: r.NormFloat64()}
+			if layout.HasZ() {
+				p.Z = r.NormFloat64()
+			}
+			if layout.HasM() {
+				p.M = r.NormFloat64()
+			}
+			return p
+		}
+		line := func() []geom.Point {
+			ps := make([]geom.Point, r.Intn(5))
+			for i := range ps {
+				ps[i] = point()
+			}
+			return ps
+		}
+		g := geom.GeometryCollection{
+			point(), geom.LineString(line()), geom.Polygon{line(), line()},
+			geom.MultiPoint(line()), geom.MultiLineString{line()}, geom.MultiPolygon{{line()}},
+		}
+		order := []binary.ByteOrder{le, be}[r.Intn(2)]
+		srid := r.Intn(3) * 4326
+		b, err := MarshalEWKB(g, layout, srid, order)
+		if err != nil {
+			t.Fatalf("MarshalEWKB(%v) error = %v", g, err)
+		}
+		got, l, s, err := Unmarshal(b)
+		if err != nil || !reflect.DeepEqual(got, g) || l != layout || s != srid {
+			t.Fatalf("Unmarshal(MarshalEWKB(%v, %v, %d)) = %v, %v, %d, %v", g, layout, srid, got, l, s, err)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package wkt
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gogama/geospat/geom"
+)
+
+// Parse returns the geometry of a WKT text, and its layout. Type names
+// and keywords are case-insensitive, and the Z, M or ZM tag may be
+// separate from the name of the type or suffixed to it, as in "POINTZ".
+// Without a tag, the layout is given by the number of coordinates of the
+// positions: XY for two, XYZ for three and XYZM for four. Every position
+// must have the same number of coordinates, and the tags of the members
+// of a GeometryCollection must agree with the tag of the collection.
+//
+// The points of a MultiPoint may be written with or without parentheses
+// of their own, as in "MULTIPOINT ((1 2), (3 4))" or "MULTIPOINT (1 2,
+// 3 4)". EMPTY geometries are read as empty points, or as nil slices of
+// their types, and an EMPTY member of a multipart geometry as an empty
+// point, line string or polygon.
+//
+// It returns an error wrapping ErrInvalid, which tells what was found
+// and at which byte offset, if the text is not valid WKT.
+func Parse(s string) (geom.Geometry, geom.Layout, error) {
+	p := parser{s: s, layout: -1}
+	g, err := p.geometry()
+	if err != nil {
+		return nil, geom.XY, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, geom.XY, p.errorf("end of text")
+	}
+	if p.layout < 0 {
+		p.layout = geom.XY
+	}
+	return g, p.layout, nil
+}
+
+// wktTypes maps the upper case names of the geometry types to the types.
+var wktTypes = map[string]geom.Type{
+	"POINT":              geom.TypePoint,
+	"LINESTRING":         geom.TypeLineString,
+	"POLYGON":            geom.TypePolygon,
+	"MULTIPOINT":         geom.TypeMultiPoint,
+	"MULTILINESTRING":    geom.TypeMultiLineString,
+	"MULTIPOLYGON":       geom.TypeMultiPolygon,
+	"GEOMETRYCOLLECTION": geom.TypeGeometryCollection,
+}
+
+// tagLayouts maps the dimension tags to the layouts.
+var tagLayouts = map[string]geom.Layout{
+	"Z":  geom.XYZ,
+	"M":  geom.XYM,
+	"ZM": geom.XYZM,
+}
+
+// parser reads a WKT text.
+type parser struct {
+	s   string
+	pos int
+	// layout is the layout of the geometry, or -1 until it is known from
+	// a tag or a position.
+	layout geom.Layout
+}
+
+// errorf returns an error wrapping ErrInvalid, telling what was
+// expected, what was found and where.
+func (p *parser) errorf(expected string) error {
+	p.skipSpace()
+	found := "end of text"
+	if p.pos < len(p.s) {
+		end := p.pos + 1
+		for end < len(p.s) && isWordByte(p.s[p.pos]) && isWordByte(p.s[end]) {
+			end++
+		}
+		found = strconv.Quote(p.s[p.pos:end])
+	}
+	return fmt.Errorf("%w: expected %s, found %s at offset %d", ErrInvalid, expected, found, p.pos)
+}
+
+// skipSpace advances past white space.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// isWordByte reports whether a byte may be part of a word or a number.
+func isWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '.' || c == '-' || c == '+'
+}
+
+// word returns the upper case word at the position, without advancing,
+// and the position after it.
+func (p *parser) word() (string, int) {
+	p.skipSpace()
+	end := p.pos
+	for end < len(p.s) && ('a' <= p.s[end] && p.s[end] <= 'z' || 'A' <= p.s[end] && p.s[end] <= 'Z') {
+		end++
+	}
+	return strings.ToUpper(p.s[p.pos:end]), end
+}
+
+// keyword advances past a word if it is the given keyword, reporting
+// whether it was.
+func (p *parser) keyword(k string) bool {
+	if w, end := p.word(); w == k {
+		p.pos = end
+		return true
+	}
+	return false
+}
+
+// punct advances past a punctuation character if it is next, reporting
+// whether it was.
+func (p *parser) punct(c byte) bool {
+	if p.skipSpace(); p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// setLayout sets the layout of the geometry, which must agree with that
+// already set, if any.
+func (p *parser) setLayout(l geom.Layout, what string) error {
+	if p.layout >= 0 && p.layout != l {
+		return fmt.Errorf("%w: %s of layout %v in a geometry of layout %v at offset %d", ErrInvalid, what, l, p.layout, p.pos)
+	}
+	p.layout = l
+	return nil
+}
+
+// geometry reads a tagged geometry.
+func (p *parser) geometry() (geom.Geometry, error) {
+	p.skipSpace()
+	start := p.pos
+	w, end := p.word()
+	t, ok := wktTypes[w]
+	tag := ""
+	for _, suffix := range []string{"ZM", "Z", "M"} {
+		if !ok && strings.HasSuffix(w, suffix) {
+			t, ok = wktTypes[strings.TrimSuffix(w, suffix)]
+			tag = suffix
+		}
+	}
+	if !ok {
+		return nil, p.errorf("a geometry type")
+	}
+	p.pos = end
+	if tag == "" {
+		if w, end := p.word(); tagLayouts[w] != geom.XY { // XY if w is not a tag
+			tag, p.pos = w, end
+		}
+	}
+	if tag != "" {
+		// A tag which disagrees is reported at the start of its geometry.
+		end := p.pos
+		p.pos = start
+		if err := p.setLayout(tagLayouts[tag], "tag "+tag); err != nil {
+			return nil, err
+		}
+		p.pos = end
+	}
+	if p.keyword("EMPTY") {
+		return empty(t), nil
+	}
+	switch t {
+	case geom.TypePoint:
+		if !p.punct('(') {
+			return nil, p.errorf(`"(" or EMPTY`)
+		}
+		q, err := p.position()
+		if err != nil {
+			return nil, err
+		}
+		if !p.punct(')') {
+			return nil, p.errorf(`")"`)
+		}
+		return q, nil
+	case geom.TypeLineString:
+		return p.line()
+	case geom.TypePolygon:
+		return p.polygon()
+	case geom.TypeMultiPoint:
+		var m geom.MultiPoint
+		err := p.list(func() error {
+			var q geom.Point
+			var err error
+			switch {
+			case p.keyword("EMPTY"):
+				q = geom.EmptyPoint()
+			case p.punct('('):
+				if q, err = p.position(); err == nil && !p.punct(')') {
+					err = p.errorf(`")"`)
+				}
+			default:
+				q, err = p.position()
+			}
+			m = append(m, q)
+			return err
+		})
+		return m, err
+	case geom.TypeMultiLineString:
+		var m geom.MultiLineString
+		err := p.list(func() error {
+			l, err := p.line()
+			m = append(m, l)
+			return err
+		})
+		return m, err
+	case geom.TypeMultiPolygon:
+		var m geom.MultiPolygon
+		err := p.list(func() error {
+			q, err := p.polygon()
+			m = append(m, q)
+			return err
+		})
+		return m, err
+	}
+	var c geom.GeometryCollection
+	err := p.list(func() error {
+		g, err := p.geometry()
+		c = append(c, g)
+		return err
+	})
+	return c, err
+}
+
+// empty returns an empty geometry of a type.
+func empty(t geom.Type) geom.Geometry {
+	switch t {
+	case geom.TypePoint:
+		return geom.EmptyPoint()
+	case geom.TypeLineString:
+		return geom.LineString(nil)
+	case geom.TypePolygon:
+		return geom.Polygon(nil)
+	case geom.TypeMultiPoint:
+		return geom.MultiPoint(nil)
+	case geom.TypeMultiLineString:
+		return geom.MultiLineString(nil)
+	case geom.TypeMultiPolygon:
+		return geom.MultiPolygon(nil)
+	}
+	return geom.GeometryCollection(nil)
+}
+
+// list reads a list of elements in parentheses, each read by elem.
+func (p *parser) list(elem func() error) error {
+	if !p.punct('(') {
+		return p.errorf(`"(" or EMPTY`)
+	}
+	for {
+		if err := elem(); err != nil {
+			return err
+		}
+		if p.punct(')') {
+			return nil
+		}
+		if !p.punct(',') {
+			return p.errorf(`"," or ")"`)
+		}
+	}
+}
+
+// line reads a list of positions, or EMPTY.
+func (p *parser) line() (geom.LineString, error) {
+	if p.keyword("EMPTY") {
+		return nil, nil
+	}
+	var l geom.LineString
+	err := p.list(func() error {
+		q, err := p.position()
+		l = append(l, q)
+		return err
+	})
+	return l, err
+}
+
+// polygon reads a list of rings, or EMPTY.
+func (p *parser) polygon() (geom.Polygon, error) {
+	if p.keyword("EMPTY") {
+		return nil, nil
+	}
+	var q geom.Polygon
+	err := p.list(func() error {
+		l, err := p.line()
+		q = append(q, geom.Ring(l))
+		return err
+	})
+	return q, err
+}
+
+// position reads the coordinates of a point, which set the layout if it
+// is not yet known.
+func (p *parser) position() (geom.Point, error) {
+	p.skipSpace()
+	start := p.pos
+	var cs []float64
+	for len(cs) < 4 {
+		p.skipSpace()
+		end := p.pos
+		for end < len(p.s) && isWordByte(p.s[end]) {
+			end++
+		}
+		v, err := strconv.ParseFloat(p.s[p.pos:end], 64)
+		if err != nil || p.pos == end || math.IsNaN(v) || math.IsInf(v, 0) {
+			if len(cs) < 2 {
+				return geom.Point{}, p.errorf("a coordinate")
+			}
+			break
+		}
+		cs = append(cs, v)
+		p.pos = end
+	}
+	if p.layout < 0 {
+		p.layout = [...]geom.Layout{2: geom.XY, 3: geom.XYZ, 4: geom.XYZM}[len(cs)]
+	}
+	n := 2
+	if p.layout.HasZ() {
+		n++
+	}
+	if p.layout.HasM() {
+		n++
+	}
+	if len(cs) != n {
+		p.pos = start
+		return geom.Point{}, fmt.Errorf("%w: position of %d coordinates in a geometry of layout %v at offset %d", ErrInvalid, len(cs), p.layout, p.pos)
+	}
+	q := geom.Point{X: cs[0], Y: cs[1]}
+	switch p.layout {
+	case geom.XYZ:
+		q.Z = cs[2]
+	case geom.XYM:
+		q.M = cs[2]
+	case geom.XYZM:
+		q.Z, q.M = cs[2], cs[3]
+	}
+	return q, nil
+}
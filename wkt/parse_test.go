@@ -0,0 +1,89 @@
+package wkt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		wkt    string
+		g      geom.Geometry
+		layout geom.Layout
+	}{
+		// The points of a MultiPoint without parentheses of their own.
+		{"MULTIPOINT (10 40, 40 30, 20 20, 30 10)", geom.MultiPoint(pts(10, 40, 40, 30, 20, 20, 30, 10)), geom.XY},
+		{"MULTIPOINT ((10 40), 40 30)", geom.MultiPoint(pts(10, 40, 40, 30)), geom.XY},
+		{"point(1 2)", geom.Point{X: 1, Y: 2}, geom.XY},
+		{"  Point\t(\n1.5e2   -2E-1 )  ", geom.Point{X: 150, Y: -0.2}, geom.XY},
+		{"POINT (+1 -.5)", geom.Point{X: 1, Y: -0.5}, geom.XY},
+		// Layouts from tags, joined or not, and from the positions.
+		{"POINTZ (1 2 3)", geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ},
+		{"POINTM(1 2 3)", geom.Point{X: 1, Y: 2, M: 3}, geom.XYM},
+		{"pointzm (1 2 3 4)", geom.Point{X: 1, Y: 2, Z: 3, M: 4}, geom.XYZM},
+		{"POINT (1 2 3)", geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ},
+		{"POINT (1 2 3 4)", geom.Point{X: 1, Y: 2, Z: 3, M: 4}, geom.XYZM},
+		{"LINESTRING M (0 0 1, 1 1 2)", geom.LineString{{X: 0, Y: 0, M: 1}, {X: 1, Y: 1, M: 2}}, geom.XYM},
+		{"GEOMETRYCOLLECTION (POINT Z (1 2 3), POINT (4 5 6))", geom.GeometryCollection{geom.Point{X: 1, Y: 2, Z: 3}, geom.Point{X: 4, Y: 5, Z: 6}}, geom.XYZ},
+		{"GEOMETRYCOLLECTION Z (LINESTRING EMPTY, POINT Z (1 2 3))", geom.GeometryCollection{geom.LineString(nil), geom.Point{X: 1, Y: 2, Z: 3}}, geom.XYZ},
+		{"GEOMETRYCOLLECTION (GEOMETRYCOLLECTION (POINT (1 2)))", geom.GeometryCollection{geom.GeometryCollection{geom.Point{X: 1, Y: 2}}}, geom.XY},
+		{"POLYGON (EMPTY, (0 0, 1 0, 0 1, 0 0))", geom.Polygon{nil, geom.Ring(pts(0, 0, 1, 0, 0, 1, 0, 0))}, geom.XY},
+		{"LINESTRING ZM EMPTY", geom.LineString(nil), geom.XYZM},
+		{"multipolygon empty", geom.MultiPolygon(nil), geom.XY},
+	}
+	for _, tt := range tests {
+		g, layout, err := Parse(tt.wkt)
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != tt.layout {
+			t.Errorf("Parse(%q) = %v, %v, %v, want %v, %v", tt.wkt, g, layout, err, tt.g, tt.layout)
+		}
+	}
+	g, layout, err := Parse("POINT Z EMPTY")
+	if p, ok := g.(geom.Point); err != nil || !ok || !p.IsEmpty() || layout != geom.XYZ {
+		t.Errorf("Parse(POINT Z EMPTY) = %v, %v, %v", g, layout, err)
+	}
+	g, _, err = Parse("MULTIPOINT (EMPTY, 1 2)")
+	if m, ok := g.(geom.MultiPoint); err != nil || !ok || len(m) != 2 || !m[0].IsEmpty() || m[1] != (geom.Point{X: 1, Y: 2}) {
+		t.Errorf("Parse(MULTIPOINT (EMPTY, 1 2)) = %v, %v", g, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		wkt string
+		err string
+	}{
+		{"", `wkt: invalid WKT: expected a geometry type, found end of text at offset 0`},
+		{"CIRCLE (1 2)", `wkt: invalid WKT: expected a geometry type, found "CIRCLE" at offset 0`},
+		{"POINT", `wkt: invalid WKT: expected "(" or EMPTY, found end of text at offset 5`},
+		{"POINT (1)", `wkt: invalid WKT: expected a coordinate, found ")" at offset 8`},
+		{"POINT (1 2", `wkt: invalid WKT: expected ")", found end of text at offset 10`},
+		{"POINT (1 2 3 4 5)", `wkt: invalid WKT: expected ")", found "5" at offset 15`},
+		{"POINT (NaN 1)", `wkt: invalid WKT: expected a coordinate, found "NaN" at offset 7`},
+		{"POINT (1e999 1)", `wkt: invalid WKT: expected a coordinate, found "1e999" at offset 7`},
+		{"POINT (1 2) x", `wkt: invalid WKT: expected end of text, found "x" at offset 12`},
+		{"POINT EMPTY EMPTY", `wkt: invalid WKT: expected end of text, found "EMPTY" at offset 12`},
+		{"POINT Q (1 2)", `wkt: invalid WKT: expected "(" or EMPTY, found "Q" at offset 6`},
+		{"LINESTRING (1 2, 3 4 5)", `wkt: invalid WKT: position of 3 coordinates in a geometry of layout XY at offset 17`},
+		{"LINESTRING (1 2 3 4, 5 6)", `wkt: invalid WKT: position of 2 coordinates in a geometry of layout XYZM at offset 21`},
+		{"LINESTRING (1 2; 3 4)", `wkt: invalid WKT: expected "," or ")", found ";" at offset 15`},
+		{"LINESTRING ()", `wkt: invalid WKT: expected a coordinate, found ")" at offset 12`},
+		{"POLYGON ((1 2, 3 4) (5 6))", `wkt: invalid WKT: expected "," or ")", found "(" at offset 20`},
+		{"POLYGON (1 2)", `wkt: invalid WKT: expected "(" or EMPTY, found "1" at offset 9`},
+		{"MULTIPOINT Z (1 2)", `wkt: invalid WKT: position of 2 coordinates in a geometry of layout XYZ at offset 14`},
+		{"MULTIPOINT ((1 2)", `wkt: invalid WKT: expected "," or ")", found end of text at offset 17`},
+		{"MULTIPOINT ((1 2, 3 4))", `wkt: invalid WKT: expected ")", found "," at offset 16`},
+		{"GEOMETRYCOLLECTION Z (POINT M (1 2 3))", `wkt: invalid WKT: tag M of layout XYM in a geometry of layout XYZ at offset 22`},
+		{"GEOMETRYCOLLECTION (POINT Z (1 2 3), POINT M (1 2 3))", `wkt: invalid WKT: tag M of layout XYM in a geometry of layout XYZ at offset 37`},
+		{"GEOMETRYCOLLECTION (POINT (1 2), LINESTRING)", `wkt: invalid WKT: expected "(" or EMPTY, found ")" at offset 43`},
+		{"GEOMETRYCOLLECTION (1 2)", `wkt: invalid WKT: expected a geometry type, found "1" at offset 20`},
+	}
+	for _, tt := range tests {
+		g, _, err := Parse(tt.wkt)
+		if !errors.Is(err, ErrInvalid) || err.Error() != tt.err {
+			t.Errorf("Parse(%q) = %v, %v, want %s", tt.wkt, g, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package wkt reads and writes geometries as Well-Known Text, the
+// textual format of the OGC Simple Features standard and ISO 13249-3,
+// such as
+//
+//	POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 2 4, 4 4, 2 2))
+//
+// Every geometry type of package geom is supported, together with EMPTY
+// geometries and the Z, M and ZM forms of the coordinates, as in
+// "POINT ZM (1 2 3 4)", which set the Layout of the geometry read.
+package wkt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gogama/geospat/geom"
+)
+
+var (
+	// ErrInvalid is returned, wrapped, by Parse when a text is not valid
+	// WKT. The error tells what was found, and where.
+	ErrInvalid = errors.New("wkt: invalid WKT")
+
+	// ErrUnsupported is returned, wrapped, by Format when a geometry
+	// cannot be written as WKT, such as one with an empty point in a
+	// line string.
+	ErrUnsupported = errors.New("wkt: unsupported geometry")
+)
+
+// Format returns the WKT of a geometry with the coordinates of the given
+// layout, written with the fewest digits that read back as the same
+// float64. The Z, M or ZM tag of the layout follows the name of the
+// type, as in "LINESTRING Z (0 0 1, 1 1 2)", of every geometry including
+// the members of a GeometryCollection, and the points of a MultiPoint
+// are written in parentheses of their own, as ISO 13249-3 requires.
+//
+// It returns an error wrapping ErrUnsupported if the geometry is nil or
+// a coordinate written is not finite, including one of an empty point
+// in a line string or polygon.
+func Format(g geom.Geometry, layout geom.Layout) (string, error) {
+	w := writer{layout: layout}
+	if err := w.geometry(g); err != nil {
+		return "", err
+	}
+	return string(w.b), nil
+}
+
+// writer accumulates WKT with coordinates of a layout.
+type writer struct {
+	layout geom.Layout
+	b      []byte
+}
+
+// geometry writes a tagged geometry.
+func (w *writer) geometry(g geom.Geometry) error {
+	switch g.(type) {
+	case geom.Point, geom.LineString, geom.Polygon, geom.MultiPoint,
+		geom.MultiLineString, geom.MultiPolygon, geom.GeometryCollection:
+	default:
+		return fmt.Errorf("%w: geometry of type %T", ErrUnsupported, g)
+	}
+	for _, c := range g.Type().String() {
+		w.b = append(w.b, byte(c)&^0x20) // Upper case
+	}
+	if w.layout != geom.XY {
+		w.b = append(w.b, ' ')
+		w.b = append(w.b, w.layout.String()[2:]...)
+	}
+	w.b = append(w.b, ' ')
+	if c, ok := g.(geom.GeometryCollection); ok {
+		return w.list(len(c), func(i int) error {
+			if c[i] == nil {
+				return fmt.Errorf("%w: member %d of a GeometryCollection is nil", ErrUnsupported, i)
+			}
+			return w.geometry(c[i])
+		})
+	}
+	return w.coordinates(g)
+}
+
+// coordinates writes the coordinates of a geometry other than a
+// GeometryCollection, in parentheses, or EMPTY.
+func (w *writer) coordinates(g geom.Geometry) error {
+	switch g := g.(type) {
+	case geom.Point:
+		if g.IsEmpty() {
+			w.b = append(w.b, "EMPTY"...)
+			return nil
+		}
+		w.b = append(w.b, '(')
+		if err := w.position(g); err != nil {
+			return err
+		}
+		w.b = append(w.b, ')')
+	case geom.LineString:
+		return w.positions(g)
+	case geom.Polygon:
+		return w.list(len(g), func(i int) error { return w.positions(g[i]) })
+	case geom.MultiPoint:
+		return w.list(len(g), func(i int) error { return w.coordinates(g[i]) })
+	case geom.MultiLineString:
+		return w.list(len(g), func(i int) error { return w.positions(g[i]) })
+	case geom.MultiPolygon:
+		return w.list(len(g), func(i int) error { return w.coordinates(g[i]) })
+	}
+	return nil
+}
+
+// list writes a list of n elements written by elem, in parentheses, or
+// EMPTY if n is zero.
+func (w *writer) list(n int, elem func(i int) error) error {
+	if n == 0 {
+		w.b = append(w.b, "EMPTY"...)
+		return nil
+	}
+	w.b = append(w.b, '(')
+	for i := range n {
+		if i > 0 {
+			w.b = append(w.b, ", "...)
+		}
+		if err := elem(i); err != nil {
+			return err
+		}
+	}
+	w.b = append(w.b, ')')
+	return nil
+}
+
+// positions writes a list of positions.
+func (w *writer) positions(ps []geom.Point) error {
+	return w.list(len(ps), func(i int) error { return w.position(ps[i]) })
+}
+
+// position writes the coordinates of a point separated by spaces.
+func (w *writer) position(p geom.Point) error {
+	if err := w.number(p.X); err != nil {
+		return err
+	}
+	w.b = append(w.b, ' ')
+	if err := w.number(p.Y); err != nil {
+		return err
+	}
+	if w.layout.HasZ() {
+		w.b = append(w.b, ' ')
+		if err := w.number(p.Z); err != nil {
+			return err
+		}
+	}
+	if w.layout.HasM() {
+		w.b = append(w.b, ' ')
+		if err := w.number(p.M); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// number writes a coordinate.
+func (w *writer) number(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("%w: coordinate %g is not finite", ErrUnsupported, v)
+	}
+	// Use exponents for the numbers which would otherwise have many
+	// zeros.
+	if a := math.Abs(v); a != 0 && (a < 1e-6 || a >= 1e21) {
+		w.b = strconv.AppendFloat(w.b, v, 'e', -1, 64)
+	} else {
+		w.b = strconv.AppendFloat(w.b, v, 'f', -1, 64)
+	}
+	return nil
+}
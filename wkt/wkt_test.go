@@ -0,0 +1,132 @@
+package wkt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gogama/geospat/geom"
+)
+
+func pts(c ...float64) []geom.Point {
+	ps := make([]geom.Point, len(c)/2)
+	for i := range ps {
+		ps[i] = geom.Point{X: c[2*i], Y: c[2*i+1]}
+	}
+	return ps
+}
+
+// The examples of WKT of the OGC Simple Features standard, as written by
+// Format.
+var formatTests = []struct {
+	g      geom.Geometry
+	layout geom.Layout
+	wkt    string
+}{
+	{geom.Point{X: 30, Y: 10}, geom.XY, "POINT (30 10)"},
+	{geom.LineString(pts(30, 10, 10, 30, 40, 40)), geom.XY, "LINESTRING (30 10, 10 30, 40 40)"},
+	{geom.Polygon{pts(30, 10, 40, 40, 20, 40, 10, 20, 30, 10)}, geom.XY, "POLYGON ((30 10, 40 40, 20 40, 10 20, 30 10))"},
+	{geom.Polygon{pts(35, 10, 45, 45, 15, 40, 10, 20, 35, 10), pts(20, 30, 35, 35, 30, 20, 20, 30)}, geom.XY,
+		"POLYGON ((35 10, 45 45, 15 40, 10 20, 35 10), (20 30, 35 35, 30 20, 20 30))"},
+	{geom.MultiPoint(pts(10, 40, 40, 30, 20, 20, 30, 10)), geom.XY, "MULTIPOINT ((10 40), (40 30), (20 20), (30 10))"},
+	{geom.MultiLineString{pts(10, 10, 20, 20, 10, 40), pts(40, 40, 30, 30, 40, 20, 30, 10)}, geom.XY,
+		"MULTILINESTRING ((10 10, 20 20, 10 40), (40 40, 30 30, 40 20, 30 10))"},
+	{geom.MultiPolygon{{pts(30, 20, 45, 40, 10, 40, 30, 20)}, {pts(15, 5, 40, 10, 10, 20, 5, 10, 15, 5)}}, geom.XY,
+		"MULTIPOLYGON (((30 20, 45 40, 10 40, 30 20)), ((15 5, 40 10, 10 20, 5 10, 15 5)))"},
+	{geom.MultiPolygon{{pts(40, 40, 20, 45, 45, 30, 40, 40)}, {pts(20, 35, 10, 30, 10, 10, 30, 5, 45, 20, 20, 35), pts(30, 20, 20, 15, 20, 25, 30, 20)}}, geom.XY,
+		"MULTIPOLYGON (((40 40, 20 45, 45 30, 40 40)), ((20 35, 10 30, 10 10, 30 5, 45 20, 20 35), (30 20, 20 15, 20 25, 30 20)))"},
+	{geom.GeometryCollection{geom.Point{X: 40, Y: 10}, geom.LineString(pts(10, 10, 20, 20, 10, 40)), geom.Polygon{pts(40, 40, 20, 45, 45, 30, 40, 40)}}, geom.XY,
+		"GEOMETRYCOLLECTION (POINT (40 10), LINESTRING (10 10, 20 20, 10 40), POLYGON ((40 40, 20 45, 45 30, 40 40)))"},
+	{geom.Point{X: 1, Y: 2, Z: 3}, geom.XYZ, "POINT Z (1 2 3)"},
+	{geom.Point{X: 1, Y: 2, M: 4}, geom.XYM, "POINT M (1 2 4)"},
+	{geom.Point{X: 1, Y: 2, Z: 3, M: 4}, geom.XYZM, "POINT ZM (1 2 3 4)"},
+	{geom.LineString{{X: 0, Y: 0, Z: 1}, {X: 1, Y: 1, Z: 2}}, geom.XYZ, "LINESTRING Z (0 0 1, 1 1 2)"},
+	{geom.GeometryCollection{geom.Point{X: 1, Y: 2, M: 3}}, geom.XYM, "GEOMETRYCOLLECTION M (POINT M (1 2 3))"},
+	{geom.Point{X: 0.1, Y: -1.0 / 3}, geom.XY, "POINT (0.1 -0.3333333333333333)"},
+	{geom.Point{X: 1e-7, Y: 1e21}, geom.XY, "POINT (1e-07 1e+21)"},
+	{geom.Point{X: 123456789012, Y: -0.000001}, geom.XY, "POINT (123456789012 -0.000001)"},
+	{geom.LineString(nil), geom.XY, "LINESTRING EMPTY"},
+	{geom.Polygon(nil), geom.XYZ, "POLYGON Z EMPTY"},
+	{geom.MultiPoint(nil), geom.XY, "MULTIPOINT EMPTY"},
+	{geom.MultiLineString(nil), geom.XY, "MULTILINESTRING EMPTY"},
+	{geom.MultiPolygon(nil), geom.XY, "MULTIPOLYGON EMPTY"},
+	{geom.GeometryCollection(nil), geom.XY, "GEOMETRYCOLLECTION EMPTY"},
+	{geom.MultiLineString{nil, pts(1, 2, 3, 4)}, geom.XY, "MULTILINESTRING (EMPTY, (1 2, 3 4))"},
+	{geom.MultiPolygon{nil}, geom.XY, "MULTIPOLYGON (EMPTY)"},
+	{geom.GeometryCollection{geom.LineString(nil)}, geom.XY, "GEOMETRYCOLLECTION (LINESTRING EMPTY)"},
+}
+
+func TestFormat(t *testing.T) {
+	for _, tt := range formatTests {
+		s, err := Format(tt.g, tt.layout)
+		if err != nil || s != tt.wkt {
+			t.Errorf("Format(%v, %v) = %q, %v, want %q", tt.g, tt.layout, s, err, tt.wkt)
+		}
+		// And back again.
+		g, layout, err := Parse(s)
+		if err != nil || !reflect.DeepEqual(g, tt.g) || layout != tt.layout {
+			t.Errorf("Parse(%q) = %v, %v, %v, want %v, %v", s, g, layout, err, tt.g, tt.layout)
+		}
+	}
+}
+
+func TestFormatEmptyPoints(t *testing.T) {
+	tests := []struct {
+		g   geom.Geometry
+		wkt string
+	}{
+		{geom.EmptyPoint(), "POINT EMPTY"},
+		{geom.MultiPoint{geom.EmptyPoint(), {X: 1, Y: 2}}, "MULTIPOINT (EMPTY, (1 2))"},
+		{geom.GeometryCollection{geom.EmptyPoint()}, "GEOMETRYCOLLECTION (POINT EMPTY)"},
+	}
+	for _, tt := range tests {
+		if s, err := Format(tt.g, geom.XY); err != nil || s != tt.wkt {
+			t.Errorf("Format(%v) = %q, %v, want %q", tt.g, s, err, tt.wkt)
+		}
+	}
+}
+
+type otherGeometry struct{ geom.Point }
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		g      geom.Geometry
+		layout geom.Layout
+	}{
+		{nil, geom.XY},
+		{otherGeometry{}, geom.XY},
+		{geom.Point{X: math.Inf(1)}, geom.XY},
+		{geom.Point{Z: math.NaN()}, geom.XYZ},
+		{geom.Point{M: math.Inf(-1)}, geom.XYM},
+		{geom.LineString{{X: 0, Y: 0}, geom.EmptyPoint()}, geom.XY},
+		{geom.GeometryCollection{geom.Point{}, nil}, geom.XY},
+	}
+	for _, tt := range tests {
+		if s, err := Format(tt.g, tt.layout); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Format(%v, %v) = %q, %v, want %v", tt.g, tt.layout, s, err, ErrUnsupported)
+		}
+	}
+	// Z and M not in the layout are not written, and so need not be
+	// finite.
+	if s, err := Format(geom.Point{X: 1, Y: 2, Z: math.NaN()}, geom.XY); err != nil || s != "POINT (1 2)" {
+		t.Errorf("Format(NaN Z, XY) = %q, %v", s, err)
+	}
+}
+
+func TestFormatRandom(t *testing.T) {
+	// Every float64 is written with the digits to read it back exactly.
+	r := rand.New(rand.NewSource(82))
+	for range 1000 {
+		v := func() float64 { return math.Float64frombits(r.Uint64()&^(0x7ff<<52) | uint64(r.Intn(2046)+1)<<52) }
+		l := geom.LineString{{X: v(), Y: v(), Z: v(), M: v()}, {X: v(), Y: v(), Z: v(), M: v()}}
+		s, err := Format(l, geom.XYZM)
+		if err != nil {
+			t.Fatalf("Format(%v) error = %v", l, err)
+		}
+		if g, _, err := Parse(s); err != nil || !reflect.DeepEqual(g, l) {
+			t.Fatalf("Parse(%q) = %v, %v, want %v", s, g, err, l)
+		}
+	}
+}